@@ -0,0 +1,472 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// aggregate identifies the function of an AggregateExpression.
+type aggregate int
+
+const (
+	aggregateJSONAgg aggregate = iota
+	aggregateJSONObjectAgg
+	aggregateStringAgg
+	aggregateArrayAgg
+	aggregatePercentileCont
+	aggregatePercentileDisc
+	aggregateStddev
+	aggregateVariance
+	aggregateCorr
+)
+
+// String returns the name of the sq function that builds the aggregate, for
+// use in error messages.
+func (agg aggregate) String() string {
+	switch agg {
+	case aggregateJSONAgg:
+		return "JSONAgg"
+	case aggregateJSONObjectAgg:
+		return "JSONObjectAgg"
+	case aggregateStringAgg:
+		return "StringAgg"
+	case aggregateArrayAgg:
+		return "ArrayAgg"
+	case aggregatePercentileCont:
+		return "PercentileCont"
+	case aggregatePercentileDisc:
+		return "PercentileDisc"
+	case aggregateStddev:
+		return "Stddev"
+	case aggregateVariance:
+		return "Variance"
+	case aggregateCorr:
+		return "Corr"
+	}
+	return "aggregate"
+}
+
+// AggregateExpression represents an SQL aggregate function whose syntax
+// differs between dialects e.g. json_agg (Postgres), JSON_ARRAYAGG (MySQL)
+// and json_group_array (SQLite). It is rendered in the dialect of the query
+// it is used in, or returns an error if the dialect does not support it.
+type AggregateExpression struct {
+	function  aggregate
+	args      []any
+	separator string
+	orderBy   []Field
+	alias     string
+}
+
+var _ interface {
+	Field
+	Any
+} = (*AggregateExpression)(nil)
+
+// JSONAgg represents an aggregate function that collects values into a JSON
+// array: json_agg (Postgres), JSON_ARRAYAGG (MySQL) or json_group_array
+// (SQLite). SQL Server is not supported. Use Row.JSONField to scan the array
+// into a Go slice, which makes it possible to fetch parents and their
+// children in a single query.
+//
+//	// SELECT actor.actor_id, json_agg(film_actor.film_id) FROM actor JOIN film_actor ... GROUP BY actor.actor_id
+//	sq.Postgres.
+//	    Select(a.ACTOR_ID, sq.JSONAgg(fa.FILM_ID)).
+//	    From(a).
+//	    Join(fa, fa.ACTOR_ID.Eq(a.ACTOR_ID)).
+//	    GroupBy(a.ACTOR_ID)
+func JSONAgg(value any) AggregateExpression {
+	return AggregateExpression{function: aggregateJSONAgg, args: []any{value}}
+}
+
+// JSONObjectAgg represents an aggregate function that collects key-value
+// pairs into a JSON object: json_object_agg (Postgres), JSON_OBJECTAGG
+// (MySQL) or json_group_object (SQLite). SQL Server is not supported. Use
+// Row.JSONField to scan the object into a Go map.
+func JSONObjectAgg(key, value any) AggregateExpression {
+	return AggregateExpression{function: aggregateJSONObjectAgg, args: []any{key, value}}
+}
+
+// StringAgg represents an aggregate function that concatenates strings,
+// separated by sep: string_agg (Postgres), GROUP_CONCAT (MySQL),
+// group_concat (SQLite) or STRING_AGG (SQL Server). The separator is written
+// into the query as a string literal.
+//
+//	// string_agg(actor.first_name, ', ' ORDER BY actor.first_name)
+//	sq.StringAgg(a.FIRST_NAME, ", ").OrderBy(a.FIRST_NAME)
+func StringAgg(value any, sep string) AggregateExpression {
+	return AggregateExpression{function: aggregateStringAgg, args: []any{value}, separator: sep}
+}
+
+// ArrayAgg represents an aggregate function that collects values into an
+// array: array_agg (Postgres). Dialects without arrays collect the values into
+// a JSON array instead: JSON_ARRAYAGG (MySQL) or json_group_array (SQLite).
+// SQL Server is not supported. Either way, use Row.ArrayField to scan the
+// array into a []string, []int, []int64, []int32, []float64, []float32 or
+// []bool.
+//
+//	// SELECT user.user_id, array_agg(user_role.role) FROM user JOIN user_role ... GROUP BY user.user_id
+//	func(row *sq.Row) User {
+//	    user := User{UserID: row.IntField(u.USER_ID)}
+//	    row.ArrayField(&user.Roles, sq.ArrayAgg(ur.ROLE))
+//	    return user
+//	}
+func ArrayAgg(value any) AggregateExpression {
+	return AggregateExpression{function: aggregateArrayAgg, args: []any{value}}
+}
+
+// PercentileCont represents the percentile_cont(fraction) WITHIN GROUP (ORDER
+// BY field) aggregate function, which returns the value at the given fraction
+// (between 0 and 1) of the ordered values, interpolating between adjacent
+// values if needed. Only Postgres is supported (SQL Server only supports it
+// as a window function).
+//
+//	// percentile_cont(0.5) WITHIN GROUP (ORDER BY film.length)
+//	sq.PercentileCont(0.5, f.LENGTH)
+func PercentileCont(fraction float64, field Field) AggregateExpression {
+	return AggregateExpression{function: aggregatePercentileCont, args: []any{fraction}, orderBy: []Field{field}}
+}
+
+// PercentileDisc is like PercentileCont, except it returns the first value
+// whose position in the ordered values is at or after the given fraction
+// instead of interpolating.
+func PercentileDisc(fraction float64, field Field) AggregateExpression {
+	return AggregateExpression{function: aggregatePercentileDisc, args: []any{fraction}, orderBy: []Field{field}}
+}
+
+// Stddev represents an aggregate function that returns the sample standard
+// deviation of a number: stddev (Postgres), STDDEV_SAMP (MySQL) or STDEV (SQL
+// Server). SQLite does not have one, so it is calculated from the sum, the sum
+// of squares and the count instead (this requires SQLite to be compiled with
+// its math functions for sqrt).
+func Stddev(num Number) AggregateExpression {
+	return AggregateExpression{function: aggregateStddev, args: []any{num}}
+}
+
+// Variance represents an aggregate function that returns the sample variance
+// of a number: variance (Postgres), VAR_SAMP (MySQL) or VAR (SQL Server).
+// SQLite does not have one, so it is calculated from the sum, the sum of
+// squares and the count instead.
+func Variance(num Number) AggregateExpression {
+	return AggregateExpression{function: aggregateVariance, args: []any{num}}
+}
+
+// Corr represents the corr(y, x) aggregate function, which returns the
+// correlation coefficient of y and x. Only Postgres is supported.
+func Corr(y, x Number) AggregateExpression {
+	return AggregateExpression{function: aggregateCorr, args: []any{y, x}}
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (e AggregateExpression) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	var name string
+	switch e.function {
+	case aggregateJSONAgg:
+		switch dialect {
+		case DialectSQLite:
+			name = "json_group_array"
+		case DialectMySQL:
+			name = "JSON_ARRAYAGG"
+		case DialectSQLServer:
+			return fmt.Errorf("%s: sqlserver does not support JSON array aggregation", e.function)
+		default:
+			name = "json_agg"
+		}
+	case aggregateJSONObjectAgg:
+		switch dialect {
+		case DialectSQLite:
+			name = "json_group_object"
+		case DialectMySQL:
+			name = "JSON_OBJECTAGG"
+		case DialectSQLServer:
+			return fmt.Errorf("%s: sqlserver does not support JSON object aggregation", e.function)
+		default:
+			name = "json_object_agg"
+		}
+	case aggregateArrayAgg:
+		switch dialect {
+		case DialectSQLite:
+			name = "json_group_array"
+		case DialectMySQL:
+			name = "JSON_ARRAYAGG"
+		case DialectSQLServer:
+			return fmt.Errorf("%s: sqlserver does not support array aggregation", e.function)
+		default:
+			name = "array_agg"
+		}
+	case aggregateStringAgg:
+		return e.writeStringAgg(ctx, dialect, buf, args, params)
+	case aggregatePercentileCont, aggregatePercentileDisc:
+		return e.writePercentile(ctx, dialect, buf, args, params)
+	case aggregateStddev, aggregateVariance, aggregateCorr:
+		return e.writeStatistic(ctx, dialect, buf, args, params)
+	default:
+		return fmt.Errorf("unknown aggregate %d", e.function)
+	}
+	if len(e.orderBy) > 0 && dialect == DialectMySQL {
+		return fmt.Errorf("%s: mysql does not support ORDER BY inside %s", e.function, name)
+	}
+	buf.WriteString(name + "(")
+	for i, arg := range e.args {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		err := WriteValue(ctx, dialect, buf, args, params, arg)
+		if err != nil {
+			return fmt.Errorf("%s: argument #%d: %w", e.function, i+1, err)
+		}
+	}
+	if len(e.orderBy) > 0 {
+		buf.WriteString(" ORDER BY ")
+		err := writeFields(ctx, dialect, buf, args, params, e.orderBy, false)
+		if err != nil {
+			return fmt.Errorf("%s: ORDER BY: %w", e.function, err)
+		}
+	}
+	buf.WriteString(")")
+	return nil
+}
+
+// writeStringAgg writes a StringAgg. Unlike the other dialects, MySQL puts the
+// separator after the ORDER BY and SQL Server puts the ORDER BY in a WITHIN
+// GROUP clause.
+func (e AggregateExpression) writeStringAgg(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	switch dialect {
+	case DialectSQLite:
+		buf.WriteString("group_concat(")
+	case DialectMySQL:
+		buf.WriteString("GROUP_CONCAT(")
+	case DialectSQLServer:
+		buf.WriteString("STRING_AGG(")
+	default:
+		buf.WriteString("string_agg(")
+	}
+	err := WriteValue(ctx, dialect, buf, args, params, e.args[0])
+	if err != nil {
+		return fmt.Errorf("%s: %w", e.function, err)
+	}
+	separator := EscapeQuote(e.separator, '\'')
+	if dialect == DialectMySQL {
+		// MySQL treats backslashes in string literals as escape characters.
+		separator = strings.ReplaceAll(separator, "\\", "\\\\")
+	}
+	separator = "'" + separator + "'"
+	if dialect != DialectMySQL {
+		buf.WriteString(", " + separator)
+	}
+	if len(e.orderBy) > 0 {
+		if dialect == DialectSQLServer {
+			buf.WriteString(") WITHIN GROUP (ORDER BY ")
+		} else {
+			buf.WriteString(" ORDER BY ")
+		}
+		err = writeFields(ctx, dialect, buf, args, params, e.orderBy, false)
+		if err != nil {
+			return fmt.Errorf("%s: ORDER BY: %w", e.function, err)
+		}
+	}
+	if dialect == DialectMySQL {
+		buf.WriteString(" SEPARATOR " + separator)
+	}
+	buf.WriteString(")")
+	return nil
+}
+
+// writePercentile writes a PercentileCont or PercentileDisc.
+func (e AggregateExpression) writePercentile(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	switch dialect {
+	case DialectSQLite, DialectMySQL:
+		return fmt.Errorf("%s: %s does not support percentiles", e.function, dialect)
+	case DialectSQLServer:
+		return fmt.Errorf("%s: sqlserver only supports percentiles as window functions", e.function)
+	}
+	fraction := e.args[0].(float64)
+	if fraction < 0 || fraction > 1 {
+		return fmt.Errorf("%s: fraction %v is not between 0 and 1", e.function, fraction)
+	}
+	if len(e.orderBy) != 1 || e.orderBy[0] == nil {
+		return fmt.Errorf("%s: exactly one field is required", e.function)
+	}
+	if e.function == aggregatePercentileCont {
+		buf.WriteString("percentile_cont(")
+	} else {
+		buf.WriteString("percentile_disc(")
+	}
+	buf.WriteString(strconv.FormatFloat(fraction, 'f', -1, 64) + ") WITHIN GROUP (ORDER BY ")
+	err := writeFields(ctx, dialect, buf, args, params, e.orderBy, false)
+	if err != nil {
+		return fmt.Errorf("%s: %w", e.function, err)
+	}
+	buf.WriteString(")")
+	return nil
+}
+
+// writeStatistic writes a Stddev, Variance or Corr.
+func (e AggregateExpression) writeStatistic(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if len(e.orderBy) > 0 {
+		return fmt.Errorf("%s: ORDER BY is not supported", e.function)
+	}
+	if e.function == aggregateCorr && dialect != DialectPostgres && dialect != "" {
+		return fmt.Errorf("%s: %s does not support corr", e.function, dialect)
+	}
+	var format string
+	switch {
+	case e.function == aggregateCorr:
+		format = "corr({}, {})"
+	case dialect == DialectSQLite:
+		// Sample variance is (Σx² - (Σx)²/n) / (n - 1). The 1.0 avoids
+		// integer division.
+		format = "(sum({1} * {1}) - sum({1}) * sum({1}) * 1.0 / count({1})) / (count({1}) - 1)"
+		if e.function == aggregateStddev {
+			format = "sqrt(" + format + ")"
+		}
+	case dialect == DialectMySQL:
+		if e.function == aggregateStddev {
+			format = "STDDEV_SAMP({})"
+		} else {
+			format = "VAR_SAMP({})"
+		}
+	case dialect == DialectSQLServer:
+		if e.function == aggregateStddev {
+			format = "STDEV({})"
+		} else {
+			format = "VAR({})"
+		}
+	default:
+		if e.function == aggregateStddev {
+			format = "stddev({})"
+		} else {
+			format = "variance({})"
+		}
+	}
+	err := Writef(ctx, dialect, buf, args, params, format, e.args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", e.function, err)
+	}
+	return nil
+}
+
+// OrderBy returns a new AggregateExpression that aggregates its values in the
+// order of the given fields. Only StringAgg supports it for MySQL. SQLite
+// only supports it from version 3.44.0 onwards. For PercentileCont and
+// PercentileDisc it replaces the field in the WITHIN GROUP clause.
+func (e AggregateExpression) OrderBy(fields ...Field) AggregateExpression {
+	e.orderBy = fields
+	return e
+}
+
+// As returns a new AggregateExpression with the given alias.
+func (e AggregateExpression) As(alias string) AggregateExpression {
+	e.alias = alias
+	return e
+}
+
+// Eq returns a 'expr = val' Predicate.
+func (e AggregateExpression) Eq(val any) Predicate { return Eq(e, val) }
+
+// Ne returns a 'expr <> val' Predicate.
+func (e AggregateExpression) Ne(val any) Predicate { return Ne(e, val) }
+
+// Lt returns a 'expr < val' Predicate.
+func (e AggregateExpression) Lt(val any) Predicate { return Lt(e, val) }
+
+// Le returns a 'expr <= val' Predicate.
+func (e AggregateExpression) Le(val any) Predicate { return Le(e, val) }
+
+// Gt returns a 'expr > val' Predicate.
+func (e AggregateExpression) Gt(val any) Predicate { return Gt(e, val) }
+
+// Ge returns a 'expr >= val' Predicate.
+func (e AggregateExpression) Ge(val any) Predicate { return Ge(e, val) }
+
+// GetAlias returns the alias of the AggregateExpression.
+func (e AggregateExpression) GetAlias() string { return e.alias }
+
+// IsField implements the Field interface.
+func (e AggregateExpression) IsField() {}
+
+// IsArray implements the Array interface.
+func (e AggregateExpression) IsArray() {}
+
+// IsBinary implements the Binary interface.
+func (e AggregateExpression) IsBinary() {}
+
+// IsBoolean implements the Boolean interface.
+func (e AggregateExpression) IsBoolean() {}
+
+// IsEnum implements the Enum interface.
+func (e AggregateExpression) IsEnum() {}
+
+// IsJSON implements the JSON interface.
+func (e AggregateExpression) IsJSON() {}
+
+// IsNumber implements the Number interface.
+func (e AggregateExpression) IsNumber() {}
+
+// IsString implements the String interface.
+func (e AggregateExpression) IsString() {}
+
+// IsTime implements the Time interface.
+func (e AggregateExpression) IsTime() {}
+
+// IsUUID implements the UUID interface.
+func (e AggregateExpression) IsUUID() {}
+
+// JSONBuildObject represents an SQL expression that builds a JSON object out
+// of alternating keys and values: json_build_object (Postgres), JSON_OBJECT
+// (MySQL, SQL Server 2022) or json_object (SQLite). The keys must be strings,
+// they are written into the query as string literals. The values may be Go
+// values or SQL expressions such as fields. Combined with JSONAgg it builds
+// a JSON array of objects.
+//
+//	// json_agg(json_build_object('film_id', film.film_id, 'title', film.title))
+//	sq.JSONAgg(sq.JSONBuildObject("film_id", f.FILM_ID, "title", f.TITLE))
+func JSONBuildObject(pairs ...any) Expression {
+	return Expr("{}", jsonBuildObject(pairs))
+}
+
+type jsonBuildObject []any
+
+// WriteSQL implements the SQLWriter interface.
+func (pairs jsonBuildObject) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if len(pairs)%2 != 0 {
+		return fmt.Errorf("JSONBuildObject: odd number of arguments (%d), expected key-value pairs", len(pairs))
+	}
+	switch dialect {
+	case DialectSQLite:
+		buf.WriteString("json_object(")
+	case DialectMySQL, DialectSQLServer:
+		buf.WriteString("JSON_OBJECT(")
+	default:
+		buf.WriteString("json_build_object(")
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return fmt.Errorf("JSONBuildObject: key #%d (%#v) is not a string", i/2+1, pairs[i])
+		}
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		literal := EscapeQuote(key, '\'')
+		if dialect == DialectMySQL {
+			// MySQL treats backslashes in string literals as escape characters.
+			literal = strings.ReplaceAll(literal, "\\", "\\\\")
+		}
+		buf.WriteString("'" + literal + "'")
+		if dialect == DialectSQLServer {
+			buf.WriteString(": ")
+		} else {
+			buf.WriteString(", ")
+		}
+		err := WriteValue(ctx, dialect, buf, args, params, pairs[i+1])
+		if err != nil {
+			return fmt.Errorf("JSONBuildObject: value of %q: %w", key, err)
+		}
+	}
+	buf.WriteString(")")
+	return nil
+}
@@ -0,0 +1,436 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestJSONAggregates(t *testing.T) {
+	tests := []TestTable{{
+		description: "JSONAgg postgres",
+		dialect:     DialectPostgres,
+		item:        JSONAgg(ACTOR.FIRST_NAME).OrderBy(ACTOR.ACTOR_ID.Desc()),
+		wantQuery:   "json_agg(actor.first_name ORDER BY actor.actor_id DESC)",
+	}, {
+		description: "JSONAgg mysql",
+		dialect:     DialectMySQL,
+		item:        JSONAgg(ACTOR.FIRST_NAME),
+		wantQuery:   "JSON_ARRAYAGG(actor.first_name)",
+	}, {
+		description: "JSONAgg sqlite",
+		dialect:     DialectSQLite,
+		item:        JSONAgg(ACTOR.FIRST_NAME),
+		wantQuery:   "json_group_array(actor.first_name)",
+	}, {
+		description: "JSONObjectAgg postgres",
+		dialect:     DialectPostgres,
+		item:        JSONObjectAgg(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME),
+		wantQuery:   "json_object_agg(actor.actor_id, actor.first_name)",
+	}, {
+		description: "JSONObjectAgg mysql",
+		dialect:     DialectMySQL,
+		item:        JSONObjectAgg(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME),
+		wantQuery:   "JSON_OBJECTAGG(actor.actor_id, actor.first_name)",
+	}, {
+		description: "JSONObjectAgg sqlite",
+		dialect:     DialectSQLite,
+		item:        JSONObjectAgg(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME),
+		wantQuery:   "json_group_object(actor.actor_id, actor.first_name)",
+	}, {
+		description: "JSONBuildObject postgres",
+		dialect:     DialectPostgres,
+		item:        JSONAgg(JSONBuildObject("id", ACTOR.ACTOR_ID, "it's", 1)),
+		wantQuery:   "json_agg(json_build_object('id', actor.actor_id, 'it''s', $1))",
+		wantArgs:    []any{1},
+	}, {
+		description: "JSONBuildObject sqlserver",
+		dialect:     DialectSQLServer,
+		item:        JSONBuildObject("id", ACTOR.ACTOR_ID, "name", ACTOR.FIRST_NAME),
+		wantQuery:   "JSON_OBJECT('id': actor.actor_id, 'name': actor.first_name)",
+	}, {
+		description: "JSONBuildObject mysql",
+		dialect:     DialectMySQL,
+		item:        JSONBuildObject("id", ACTOR.ACTOR_ID, `it's a\b`, ACTOR.FIRST_NAME),
+		wantQuery:   `JSON_OBJECT('id', actor.actor_id, 'it''s a\\b', actor.first_name)`,
+	}, {
+		description: "JSONBuildObject backslash outside mysql",
+		dialect:     DialectSQLite,
+		item:        JSONBuildObject(`a\b`, ACTOR.ACTOR_ID),
+		wantQuery:   `json_object('a\b', actor.actor_id)`,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "JSONAgg sqlserver",
+		dialect:     DialectSQLServer,
+		item:        JSONAgg(ACTOR.FIRST_NAME),
+	}, {
+		description: "JSONObjectAgg sqlserver",
+		dialect:     DialectSQLServer,
+		item:        JSONObjectAgg(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME),
+	}, {
+		description: "JSONAgg mysql ORDER BY",
+		dialect:     DialectMySQL,
+		item:        JSONAgg(ACTOR.FIRST_NAME).OrderBy(ACTOR.ACTOR_ID),
+	}, {
+		description: "JSONBuildObject odd arguments",
+		dialect:     DialectPostgres,
+		item:        JSONBuildObject("id"),
+	}, {
+		description: "JSONBuildObject non-string key",
+		dialect:     DialectPostgres,
+		item:        JSONBuildObject(1, ACTOR.ACTOR_ID),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := Exec(db, SQLite.
+			InsertInto(ACTOR).
+			Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+			Values(1, "PENELOPE", "GUINESS").
+			Values(2, "NICK", "WAHLBERG").
+			Values(3, "ED", "GUINESS"),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		type Family struct {
+			LastName string
+			Members  []map[string]any
+			ByID     map[string]string
+		}
+		families, err := FetchAll(db, SQLite.
+			From(ACTOR).
+			GroupBy(ACTOR.LAST_NAME).
+			OrderBy(ACTOR.LAST_NAME),
+			func(row *Row) Family {
+				var family Family
+				family.LastName = row.StringField(ACTOR.LAST_NAME)
+				row.JSONField(&family.Members, JSONAgg(JSONBuildObject("id", ACTOR.ACTOR_ID, "name", ACTOR.FIRST_NAME)))
+				row.JSONField(&family.ByID, JSONObjectAgg(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME))
+				return family
+			},
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		wantFamilies := []Family{{
+			LastName: "GUINESS",
+			Members:  []map[string]any{{"id": 1.0, "name": "PENELOPE"}, {"id": 3.0, "name": "ED"}},
+			ByID:     map[string]string{"1": "PENELOPE", "3": "ED"},
+		}, {
+			LastName: "WAHLBERG",
+			Members:  []map[string]any{{"id": 2.0, "name": "NICK"}},
+			ByID:     map[string]string{"2": "NICK"},
+		}}
+		if diff := testutil.Diff(families, wantFamilies); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
+
+func TestStringAgg(t *testing.T) {
+	tests := []TestTable{{
+		description: "postgres",
+		dialect:     DialectPostgres,
+		item:        StringAgg(ACTOR.FIRST_NAME, ", ").OrderBy(ACTOR.ACTOR_ID.Desc()),
+		wantQuery:   "string_agg(actor.first_name, ', ' ORDER BY actor.actor_id DESC)",
+	}, {
+		description: "mysql",
+		dialect:     DialectMySQL,
+		item:        StringAgg(ACTOR.FIRST_NAME, `'\`).OrderBy(ACTOR.ACTOR_ID),
+		wantQuery:   `GROUP_CONCAT(actor.first_name ORDER BY actor.actor_id SEPARATOR '''\\')`,
+	}, {
+		description: "sqlite",
+		dialect:     DialectSQLite,
+		item:        StringAgg(ACTOR.FIRST_NAME, ","),
+		wantQuery:   "group_concat(actor.first_name, ',')",
+	}, {
+		description: "sqlserver",
+		dialect:     DialectSQLServer,
+		item:        StringAgg(ACTOR.FIRST_NAME, ", ").OrderBy(ACTOR.FIRST_NAME, ACTOR.ACTOR_ID),
+		wantQuery:   "STRING_AGG(actor.first_name, ', ') WITHIN GROUP (ORDER BY actor.first_name, actor.actor_id)",
+	}, {
+		description: "sqlserver without ORDER BY",
+		dialect:     DialectSQLServer,
+		item:        StringAgg(ACTOR.FIRST_NAME, ", ").As("names"),
+		wantQuery:   "STRING_AGG(actor.first_name, ', ')",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := Exec(db, SQLite.
+			InsertInto(ACTOR).
+			Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+			Values(1, "PENELOPE", "GUINESS").
+			Values(2, "NICK", "WAHLBERG").
+			Values(3, "ED", "GUINESS"),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		names, err := FetchAll(db, SQLite.
+			From(ACTOR).
+			GroupBy(ACTOR.LAST_NAME).
+			OrderBy(ACTOR.LAST_NAME),
+			func(row *Row) string {
+				return row.StringField(StringAgg(ACTOR.FIRST_NAME, "|"))
+			},
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		for i := range names {
+			// SQLite before 3.44.0 does not support ORDER BY inside
+			// group_concat, so the order of the names is not guaranteed.
+			if names[i] == "ED|PENELOPE" {
+				names[i] = "PENELOPE|ED"
+			}
+		}
+		if diff := testutil.Diff(names, []string{"PENELOPE|ED", "NICK"}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
+
+func TestArrayAgg(t *testing.T) {
+	tests := []TestTable{{
+		description: "postgres",
+		dialect:     DialectPostgres,
+		item:        ArrayAgg(ACTOR.ACTOR_ID).OrderBy(ACTOR.ACTOR_ID),
+		wantQuery:   "array_agg(actor.actor_id ORDER BY actor.actor_id)",
+	}, {
+		description: "mysql",
+		dialect:     DialectMySQL,
+		item:        ArrayAgg(ACTOR.ACTOR_ID),
+		wantQuery:   "JSON_ARRAYAGG(actor.actor_id)",
+	}, {
+		description: "sqlite",
+		dialect:     DialectSQLite,
+		item:        ArrayAgg(ACTOR.ACTOR_ID),
+		wantQuery:   "json_group_array(actor.actor_id)",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "sqlserver",
+		dialect:     DialectSQLServer,
+		item:        ArrayAgg(ACTOR.ACTOR_ID),
+	}, {
+		description: "mysql ORDER BY",
+		dialect:     DialectMySQL,
+		item:        ArrayAgg(ACTOR.ACTOR_ID).OrderBy(ACTOR.ACTOR_ID),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := Exec(db, SQLite.
+			InsertInto(ACTOR).
+			Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+			Values(1, "PENELOPE", "GUINESS").
+			Values(2, "NICK", "WAHLBERG").
+			Values(3, "ED", "GUINESS"),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		type Family struct {
+			LastName string
+			IDs      []int
+			Names    []string
+		}
+		families, err := FetchAll(db, SQLite.
+			From(ACTOR).
+			GroupBy(ACTOR.LAST_NAME).
+			OrderBy(ACTOR.LAST_NAME),
+			func(row *Row) Family {
+				family := Family{LastName: row.StringField(ACTOR.LAST_NAME)}
+				row.ArrayField(&family.IDs, ArrayAgg(ACTOR.ACTOR_ID))
+				row.ArrayField(&family.Names, ArrayAgg(ACTOR.FIRST_NAME))
+				return family
+			},
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		for i := range families {
+			// SQLite before 3.44.0 does not support ORDER BY inside
+			// json_group_array, so the order of the values is not guaranteed.
+			if len(families[i].IDs) == 2 && families[i].IDs[0] > families[i].IDs[1] {
+				families[i].IDs[0], families[i].IDs[1] = families[i].IDs[1], families[i].IDs[0]
+				families[i].Names[0], families[i].Names[1] = families[i].Names[1], families[i].Names[0]
+			}
+		}
+		wantFamilies := []Family{
+			{LastName: "GUINESS", IDs: []int{1, 3}, Names: []string{"PENELOPE", "ED"}},
+			{LastName: "WAHLBERG", IDs: []int{2}, Names: []string{"NICK"}},
+		}
+		if diff := testutil.Diff(families, wantFamilies); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
+
+func TestStatisticalAggregates(t *testing.T) {
+	tests := []TestTable{{
+		description: "PercentileCont",
+		dialect:     DialectPostgres,
+		item:        PercentileCont(0.5, ACTOR.ACTOR_ID),
+		wantQuery:   "percentile_cont(0.5) WITHIN GROUP (ORDER BY actor.actor_id)",
+	}, {
+		description: "PercentileDisc",
+		dialect:     DialectPostgres,
+		item:        PercentileDisc(0.95, ACTOR.ACTOR_ID.Desc()),
+		wantQuery:   "percentile_disc(0.95) WITHIN GROUP (ORDER BY actor.actor_id DESC)",
+	}, {
+		description: "Stddev postgres",
+		dialect:     DialectPostgres,
+		item:        Stddev(ACTOR.ACTOR_ID),
+		wantQuery:   "stddev(actor.actor_id)",
+	}, {
+		description: "Stddev mysql",
+		dialect:     DialectMySQL,
+		item:        Stddev(ACTOR.ACTOR_ID),
+		wantQuery:   "STDDEV_SAMP(actor.actor_id)",
+	}, {
+		description: "Stddev sqlserver",
+		dialect:     DialectSQLServer,
+		item:        Stddev(ACTOR.ACTOR_ID),
+		wantQuery:   "STDEV(actor.actor_id)",
+	}, {
+		description: "Stddev sqlite",
+		dialect:     DialectSQLite,
+		item:        Stddev(ACTOR.ACTOR_ID),
+		wantQuery: "sqrt((sum(actor.actor_id * actor.actor_id) - sum(actor.actor_id) * sum(actor.actor_id) * 1.0" +
+			" / count(actor.actor_id)) / (count(actor.actor_id) - 1))",
+	}, {
+		description: "Variance postgres",
+		dialect:     DialectPostgres,
+		item:        Variance(ACTOR.ACTOR_ID),
+		wantQuery:   "variance(actor.actor_id)",
+	}, {
+		description: "Variance mysql",
+		dialect:     DialectMySQL,
+		item:        Variance(ACTOR.ACTOR_ID),
+		wantQuery:   "VAR_SAMP(actor.actor_id)",
+	}, {
+		description: "Variance sqlserver",
+		dialect:     DialectSQLServer,
+		item:        Variance(ACTOR.ACTOR_ID),
+		wantQuery:   "VAR(actor.actor_id)",
+	}, {
+		description: "Corr",
+		dialect:     DialectPostgres,
+		item:        Corr(ACTOR.ACTOR_ID, Expr("length({})", ACTOR.FIRST_NAME)),
+		wantQuery:   "corr(actor.actor_id, length(actor.first_name))",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "PercentileCont mysql",
+		dialect:     DialectMySQL,
+		item:        PercentileCont(0.5, ACTOR.ACTOR_ID),
+	}, {
+		description: "PercentileCont sqlserver",
+		dialect:     DialectSQLServer,
+		item:        PercentileCont(0.5, ACTOR.ACTOR_ID),
+	}, {
+		description: "PercentileCont fraction out of range",
+		dialect:     DialectPostgres,
+		item:        PercentileCont(1.5, ACTOR.ACTOR_ID),
+	}, {
+		description: "PercentileDisc nil field",
+		dialect:     DialectPostgres,
+		item:        PercentileDisc(0.5, nil),
+	}, {
+		description: "Corr sqlite",
+		dialect:     DialectSQLite,
+		item:        Corr(ACTOR.ACTOR_ID, ACTOR.ACTOR_ID),
+	}, {
+		description: "Variance ORDER BY",
+		dialect:     DialectPostgres,
+		item:        Variance(ACTOR.ACTOR_ID).OrderBy(ACTOR.ACTOR_ID),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+
+	t.Run("sqlite variance", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := Exec(db, SQLite.
+			InsertInto(ACTOR).
+			Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+			Values(2, "PENELOPE", "GUINESS").
+			Values(4, "NICK", "WAHLBERG").
+			Values(9, "ED", "CHASE"),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		variance, err := FetchOne(db, SQLite.From(ACTOR), func(row *Row) float64 {
+			return row.Float64Field(Variance(ACTOR.ACTOR_ID))
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		// mean = 5, Σ(x - mean)² = 9 + 1 + 16 = 26, variance = 26 / (3 - 1)
+		if diff := testutil.Diff(variance, 13.0); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
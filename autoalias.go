@@ -0,0 +1,60 @@
+package sq
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// autoAliasPrefix starts every alias generated by AutoAlias. The NUL byte
+// makes sure it can never be mistaken for an alias chosen by the user.
+const autoAliasPrefix = "\x00sq_autoalias_"
+
+var autoAliasSeq atomic.Uint64
+
+// AutoAlias returns a new alias that is replaced by a generated alias (t1, t2,
+// t3...) when the query is written. Pass it anywhere an alias is expected,
+// such as sq.New() or the As() method of a subquery or CTE, to avoid having to
+// come up with a unique alias for every table that is joined more than once
+// and every subquery in a FROM or JOIN clause.
+//
+// The generated aliases are numbered in the order in which they first appear
+// in the query, so the same query always gets the same aliases. Fields
+// obtained from the table struct or through the Field() method of the
+// subquery refer to the generated alias too.
+//
+//	a1, a2 := sq.New[ACTOR](sq.AutoAlias()), sq.New[ACTOR](sq.AutoAlias())
+//	// SELECT t1.first_name, t2.first_name FROM actor AS t1 JOIN actor AS t2 ON t2.last_name = t1.last_name AND t2.actor_id <> t1.actor_id
+//	query := sq.
+//	    Select(a1.FIRST_NAME, a2.FIRST_NAME).
+//	    From(a1).
+//	    Join(a2, a2.LAST_NAME.Eq(a1.LAST_NAME), a2.ACTOR_ID.Ne(a1.ACTOR_ID))
+//
+// Don't mix generated aliases with explicit aliases of the form t<number> in
+// the same query, as they may collide.
+func AutoAlias() string {
+	return autoAliasPrefix + strconv.FormatUint(autoAliasSeq.Add(1), 10)
+}
+
+// resolveAutoAlias returns the generated alias of an alias returned by
+// AutoAlias. Any other alias is returned as-is.
+func resolveAutoAlias(ctx context.Context, alias string) (string, error) {
+	if !strings.HasPrefix(alias, autoAliasPrefix) {
+		return alias, nil
+	}
+	state := getWriteState(ctx)
+	if state == nil {
+		return "", fmt.Errorf("alias generated by AutoAlias can only be written as part of a query")
+	}
+	if generatedAlias, ok := state.autoAliases[alias]; ok {
+		return generatedAlias, nil
+	}
+	if state.autoAliases == nil {
+		state.autoAliases = make(map[string]string)
+	}
+	generatedAlias := "t" + strconv.Itoa(len(state.autoAliases)+1)
+	state.autoAliases[alias] = generatedAlias
+	return generatedAlias, nil
+}
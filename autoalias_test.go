@@ -0,0 +1,90 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+type AUTOALIAS_ACTOR struct {
+	TableStruct `sq:"actor"`
+	ACTOR_ID    NumberField
+	FIRST_NAME  StringField
+	LAST_NAME   StringField
+}
+
+func TestAutoAlias(t *testing.T) {
+	type TT struct {
+		description string
+		item        SQLWriter
+		wantQuery   string
+	}
+
+	a1, a2 := New[AUTOALIAS_ACTOR](AutoAlias()), New[AUTOALIAS_ACTOR](AutoAlias())
+	a3 := New[AUTOALIAS_ACTOR](AutoAlias())
+	counts := Postgres.
+		Select(a3.LAST_NAME, Expr("COUNT(*)").As("actor_count")).
+		From(a3).
+		GroupBy(a3.LAST_NAME).
+		As(AutoAlias())
+
+	tests := []TT{{
+		description: "self join",
+		item: Postgres.
+			Select(a1.FIRST_NAME, a2.FIRST_NAME).
+			From(a1).
+			Join(a2, a2.LAST_NAME.Eq(a1.LAST_NAME), a2.ACTOR_ID.Ne(a1.ACTOR_ID)),
+		wantQuery: "SELECT t1.first_name, t2.first_name" +
+			" FROM actor AS t1" +
+			" JOIN actor AS t2 ON t2.last_name = t1.last_name AND t2.actor_id <> t1.actor_id",
+	}, {
+		description: "subquery",
+		item: Postgres.
+			Select(a1.FIRST_NAME, counts.Field("actor_count")).
+			From(a1).
+			Join(counts, counts.Field("last_name").Eq(a1.LAST_NAME)),
+		wantQuery: "SELECT t1.first_name, t2.actor_count" +
+			" FROM actor AS t1" +
+			" JOIN (SELECT t3.last_name, COUNT(*) AS actor_count FROM actor AS t3 GROUP BY t3.last_name) AS t2 ON t2.last_name = t1.last_name",
+	}, {
+		description: "explicit aliases are untouched",
+		item: Postgres.
+			Select(a2.FIRST_NAME).
+			From(a2).
+			Join(New[AUTOALIAS_ACTOR]("a"), Expr("a.actor_id = {}", a2.ACTOR_ID)),
+		wantQuery: "SELECT t1.first_name FROM actor AS t1 JOIN actor AS a ON a.actor_id = t1.actor_id",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			gotQuery, _, err := ToSQL(DialectPostgres, tt.item, nil)
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(gotQuery, tt.wantQuery); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			// The same query always gets the same aliases.
+			gotQuery, _, err = ToSQL(DialectPostgres, tt.item, nil)
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(gotQuery, tt.wantQuery); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+
+	t.Run("outside of a query", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		err := a1.FIRST_NAME.WriteSQL(context.Background(), DialectPostgres, &buf, nil, nil)
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package sq
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BatchOptions are the options used by ExecInBatches.
+type BatchOptions struct {
+	// KeyField is the (primary) key of the table being updated or deleted
+	// from. Each batch is restricted to the rows whose keys are returned by a
+	// LIMIT-ed subquery instead of adding a LIMIT to the query itself. It is
+	// required for sqlite, postgres and sqlserver, which do not support LIMIT
+	// on UPDATE and DELETE (sqlite only does if compiled with
+	// SQLITE_ENABLE_UPDATE_DELETE_LIMIT). It is ignored for mysql, which does
+	// not support LIMIT inside an IN subquery. Unless the query has an ORDER
+	// BY, batches are ordered by the KeyField.
+	KeyField Field
+
+	// Sleep is how long to wait between batches.
+	Sleep time.Duration
+
+	// Progress (if provided) is called after every batch with the batch
+	// number (starting from 1), the rows affected by that batch and the
+	// total rows affected so far.
+	Progress func(batch int, rowsAffected int64, totalRowsAffected int64)
+}
+
+// ExecInBatches repeatedly executes a DELETE or UPDATE query, limited to
+// batchSize rows at a time, until a batch affects no rows. It returns the
+// total number of rows affected. This avoids holding locks for a long time
+// when purging or updating a large number of rows.
+//
+// An UPDATE query must change the rows it updates such that they no longer
+// match its WHERE clause, otherwise ExecInBatches will never terminate.
+func ExecInBatches(db DB, query Query, batchSize int, opts BatchOptions) (totalRowsAffected int64, err error) {
+	return execInBatches(context.Background(), db, query, batchSize, opts, 1)
+}
+
+// ExecInBatchesContext is like ExecInBatches but additionally requires a
+// context.Context.
+func ExecInBatchesContext(ctx context.Context, db DB, query Query, batchSize int, opts BatchOptions) (totalRowsAffected int64, err error) {
+	return execInBatches(ctx, db, query, batchSize, opts, 1)
+}
+
+func execInBatches(ctx context.Context, db DB, query Query, batchSize int, opts BatchOptions, skip int) (totalRowsAffected int64, err error) {
+	if db == nil {
+		return 0, fmt.Errorf("db is nil")
+	}
+	if query == nil {
+		return 0, fmt.Errorf("query is nil")
+	}
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("batch size must be greater than zero, got %d", batchSize)
+	}
+	dialect := query.GetDialect()
+	if dialect == "" {
+		dialect = dbDialect(db)
+	}
+	batchQuery, err := newBatchQuery(dialect, query, batchSize, opts.KeyField)
+	if err != nil {
+		return 0, err
+	}
+	for batch := 1; ; batch++ {
+		result, err := exec(ctx, db, batchQuery, skip+1)
+		if err != nil {
+			return totalRowsAffected, fmt.Errorf("batch #%d: %w", batch, err)
+		}
+		if result.RowsAffected == 0 {
+			return totalRowsAffected, nil
+		}
+		totalRowsAffected += result.RowsAffected
+		if opts.Progress != nil {
+			opts.Progress(batch, result.RowsAffected, totalRowsAffected)
+		}
+		if opts.Sleep > 0 {
+			timer := time.NewTimer(opts.Sleep)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return totalRowsAffected, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// newBatchQuery returns a copy of the DELETE or UPDATE query that only
+// affects up to batchSize rows.
+func newBatchQuery(dialect string, query Query, batchSize int, keyField Field) (Query, error) {
+	switch q := query.(type) {
+	case DeleteQuery:
+		return newBatchDeleteQuery(dialect, q, batchSize, keyField)
+	case SQLiteDeleteQuery:
+		return newBatchDeleteQuery(dialect, DeleteQuery(q), batchSize, keyField)
+	case PostgresDeleteQuery:
+		return newBatchDeleteQuery(dialect, DeleteQuery(q), batchSize, keyField)
+	case MySQLDeleteQuery:
+		return newBatchDeleteQuery(dialect, DeleteQuery(q), batchSize, keyField)
+	case SQLServerDeleteQuery:
+		return newBatchDeleteQuery(dialect, DeleteQuery(q), batchSize, keyField)
+	case UpdateQuery:
+		return newBatchUpdateQuery(dialect, q, batchSize, keyField)
+	case SQLiteUpdateQuery:
+		return newBatchUpdateQuery(dialect, UpdateQuery(q), batchSize, keyField)
+	case PostgresUpdateQuery:
+		return newBatchUpdateQuery(dialect, UpdateQuery(q), batchSize, keyField)
+	case MySQLUpdateQuery:
+		return newBatchUpdateQuery(dialect, UpdateQuery(q), batchSize, keyField)
+	case SQLServerUpdateQuery:
+		return newBatchUpdateQuery(dialect, UpdateQuery(q), batchSize, keyField)
+	default:
+		return nil, fmt.Errorf("ExecInBatches only supports DELETE and UPDATE queries, got %T", query)
+	}
+}
+
+func newBatchDeleteQuery(dialect string, q DeleteQuery, batchSize int, keyField Field) (Query, error) {
+	if q.LimitRows != nil {
+		return nil, fmt.Errorf("ExecInBatches: DELETE query already has a LIMIT")
+	}
+	q.Dialect = dialect
+	if dialect == DialectMySQL {
+		q.LimitRows = batchSize
+		return q, nil
+	}
+	if keyField == nil {
+		return nil, fmt.Errorf("ExecInBatches: %s DELETE does not support LIMIT, BatchOptions.KeyField must be provided", dialect)
+	}
+	if q.UsingTable != nil || len(q.JoinTables) > 0 || len(q.DeleteTables) > 0 {
+		return nil, fmt.Errorf("ExecInBatches: cannot batch a %s DELETE with joins", dialect)
+	}
+	q.WherePredicate = In(keyField, newBatchKeysQuery(dialect, q.DeleteTable, q.WherePredicate, q.OrderByFields, batchSize, keyField))
+	q.OrderByFields = nil
+	return q, nil
+}
+
+func newBatchUpdateQuery(dialect string, q UpdateQuery, batchSize int, keyField Field) (Query, error) {
+	if q.LimitRows != nil {
+		return nil, fmt.Errorf("ExecInBatches: UPDATE query already has a LIMIT")
+	}
+	q.Dialect = dialect
+	if dialect == DialectMySQL {
+		q.LimitRows = batchSize
+		return q, nil
+	}
+	if keyField == nil {
+		return nil, fmt.Errorf("ExecInBatches: %s UPDATE does not support LIMIT, BatchOptions.KeyField must be provided", dialect)
+	}
+	if q.FromTable != nil || len(q.JoinTables) > 0 {
+		return nil, fmt.Errorf("ExecInBatches: cannot batch a %s UPDATE with joins", dialect)
+	}
+	q.WherePredicate = In(keyField, newBatchKeysQuery(dialect, q.UpdateTable, q.WherePredicate, q.OrderByFields, batchSize, keyField))
+	q.OrderByFields = nil
+	return q, nil
+}
+
+// newBatchKeysQuery returns a query that selects up to batchSize keys
+// matching the predicate. If no ORDER BY fields are provided, the keys are
+// ordered by the keyField.
+func newBatchKeysQuery(dialect string, table Table, predicate Predicate, orderByFields []Field, batchSize int, keyField Field) SelectQuery {
+	q := SelectQuery{
+		Dialect:        dialect,
+		SelectFields:   []Field{keyField},
+		FromTable:      table,
+		WherePredicate: predicate,
+		OrderByFields:  orderByFields,
+	}
+	if len(q.OrderByFields) == 0 {
+		q.OrderByFields = []Field{keyField}
+	}
+	if dialect == DialectSQLServer {
+		q.LimitTop = batchSize
+	} else {
+		q.LimitRows = batchSize
+	}
+	return q
+}
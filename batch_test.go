@@ -0,0 +1,126 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestExecInBatches(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, SQLite.
+		InsertInto(ACTOR).
+		ColumnValues(func(col *Column) {
+			for i := 1; i <= 10; i++ {
+				col.SetInt(ACTOR.ACTOR_ID, i)
+				col.SetString(ACTOR.FIRST_NAME, "bob")
+				col.SetString(ACTOR.LAST_NAME, "the builder")
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	var gotProgress [][2]int64
+	totalRowsAffected, err := ExecInBatches(db, SQLite.
+		DeleteFrom(ACTOR).
+		Where(ACTOR.ACTOR_ID.GtInt(2)),
+		3,
+		BatchOptions{
+			KeyField: ACTOR.ACTOR_ID,
+			Progress: func(batch int, rowsAffected int64, totalRowsAffected int64) {
+				gotProgress = append(gotProgress, [2]int64{rowsAffected, totalRowsAffected})
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(totalRowsAffected, int64(8)); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if diff := testutil.Diff(gotProgress, [][2]int64{{3, 3}, {3, 6}, {2, 8}}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	exists, err := FetchExists(db, SQLite.SelectOne().From(ACTOR).Where(ACTOR.ACTOR_ID.GtInt(2)))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if exists {
+		t.Error(testutil.Callers(), "expected all actors with actor_id > 2 to be deleted")
+	}
+}
+
+func Test_newBatchQuery(t *testing.T) {
+	type ACTOR struct {
+		TableStruct
+		ACTOR_ID   NumberField
+		FIRST_NAME StringField
+	}
+	a := New[ACTOR]("")
+
+	type TT struct {
+		TestTable
+		keyField Field
+	}
+
+	tests := []TT{{
+		TestTable: TestTable{
+			description: "mysql DELETE",
+			item:        MySQL.DeleteFrom(a).Where(a.FIRST_NAME.IsNull()).OrderBy(a.ACTOR_ID),
+			wantQuery:   "DELETE FROM actor WHERE actor.first_name IS NULL ORDER BY actor.actor_id LIMIT 100",
+		},
+	}, {
+		TestTable: TestTable{
+			description: "sqlite UPDATE",
+			item:        SQLite.Update(a).Set(a.FIRST_NAME.SetString("bob")).Where(a.FIRST_NAME.IsNull()),
+			wantQuery: "UPDATE actor SET first_name = $1 WHERE actor.actor_id IN (" +
+				"SELECT actor.actor_id FROM actor WHERE actor.first_name IS NULL ORDER BY actor.actor_id LIMIT $2)",
+			wantArgs: []any{"bob", 100},
+		},
+		keyField: a.ACTOR_ID,
+	}, {
+		TestTable: TestTable{
+			description: "postgres DELETE",
+			item:        Postgres.DeleteFrom(a).Where(a.FIRST_NAME.IsNull()),
+			wantQuery: "DELETE FROM actor WHERE actor.actor_id IN (" +
+				"SELECT actor.actor_id FROM actor WHERE actor.first_name IS NULL ORDER BY actor.actor_id LIMIT $1)",
+			wantArgs: []any{100},
+		},
+		keyField: a.ACTOR_ID,
+	}, {
+		TestTable: TestTable{
+			description: "sqlserver UPDATE",
+			item:        SQLServer.Update(a).Set(a.FIRST_NAME.SetString("bob")).Where(a.FIRST_NAME.IsNull()),
+			wantQuery: "UPDATE actor SET first_name = @p1 WHERE actor.actor_id IN (" +
+				"SELECT TOP (@p2) actor.actor_id FROM actor WHERE actor.first_name IS NULL ORDER BY actor.actor_id)",
+			wantArgs: []any{"bob", 100},
+		},
+		keyField: a.ACTOR_ID,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			query, err := newBatchQuery(tt.item.(Query).GetDialect(), tt.item.(Query), 100, tt.keyField)
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			tt.item = query
+			tt.assert(t)
+		})
+	}
+
+	for _, dialect := range []string{DialectSQLite, DialectPostgres, DialectSQLServer} {
+		dialect := dialect
+		t.Run(dialect+" requires KeyField", func(t *testing.T) {
+			t.Parallel()
+			_, err := newBatchQuery(dialect, DeleteFrom(a), 100, nil)
+			if err == nil {
+				t.Error(testutil.Callers(), "expected error but got nil")
+			}
+		})
+	}
+}
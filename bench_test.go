@@ -0,0 +1,213 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The benchmarks in this file cover the hot paths of the library: building
+// queries, Writef, Sprintf, scanning rows and fetching results. To check a
+// change for performance regressions, run the benchmarks before and after the
+// change and compare the results with benchstat
+// (golang.org/x/perf/cmd/benchstat):
+//
+//	go test -run='^$' -bench=. -benchmem -count=10 > old.txt
+//	# apply the change
+//	go test -run='^$' -bench=. -benchmem -count=10 > new.txt
+//	benchstat old.txt new.txt
+
+var benchmarkQuery string
+
+func BenchmarkSelectQuery(b *testing.B) {
+	a := ACTOR
+	query := Postgres.
+		Select(a.ACTOR_ID, a.FIRST_NAME, a.LAST_NAME, a.LAST_UPDATE).
+		From(a).
+		Where(
+			a.FIRST_NAME.EqString("PENELOPE"),
+			a.ACTOR_ID.In([]int{1, 2, 3, 4, 5}),
+			a.LAST_UPDATE.LtTime(time.Unix(0, 0)),
+		).
+		OrderBy(a.LAST_NAME.Desc()).
+		Limit(10)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var err error
+		benchmarkQuery, _, err = ToSQL("", query, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInsertQuery(b *testing.B) {
+	a := ACTOR
+	query := Postgres.InsertInto(a).Columns(a.ACTOR_ID, a.FIRST_NAME, a.LAST_NAME)
+	for i := 0; i < 100; i++ {
+		query = query.Values(i, "PENELOPE", "GUINESS")
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var err error
+		benchmarkQuery, _, err = ToSQL("", query, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWritef(b *testing.B) {
+	a := ACTOR
+	format := "SELECT {} FROM {} WHERE {} = {first_name} AND {} IN ({})"
+	values := []any{
+		Fields{a.ACTOR_ID, a.FIRST_NAME, a.LAST_NAME},
+		a,
+		a.FIRST_NAME,
+		sql.Named("first_name", "PENELOPE"),
+		a.ACTOR_ID,
+		[]int{1, 2, 3, 4, 5},
+	}
+	buf := &bytes.Buffer{}
+	args := make([]any, 0, 8)
+	params := make(map[string][]int)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		args = args[:0]
+		for name := range params {
+			delete(params, name)
+		}
+		err := Writef(context.Background(), DialectPostgres, buf, &args, params, format, values)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSprintf(b *testing.B) {
+	query := "SELECT actor_id, first_name, last_name FROM actor WHERE first_name = $1 AND last_update < $2 AND actor_id IN ($3, $4, $5)"
+	args := []any{"PENELOPE", time.Unix(0, 0).UTC(), 1, 2, 3}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var err error
+		benchmarkQuery, err = Sprintf(DialectPostgres, query, args)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRowScan(b *testing.B) {
+	for _, numColumns := range []int{1, 10, 100} {
+		numColumns := numColumns
+		b.Run(strconv.Itoa(numColumns)+"Columns", func(b *testing.B) {
+			db := newWideTableDB(b, numColumns, 100)
+			tbl := NewTableStruct("", "wide", "")
+			fields := make([]NumberField, numColumns)
+			for i := range fields {
+				fields[i] = NewNumberField("c"+strconv.Itoa(i), tbl)
+			}
+			query := SQLite.From(tbl)
+			rowmapper := func(row *Row) int64 {
+				var sum int64
+				for _, field := range fields {
+					sum += row.Int64Field(field)
+				}
+				return sum
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := FetchAll(db, query, rowmapper)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFetchAll(b *testing.B) {
+	db := newDB(b)
+	db.SetMaxOpenConns(1)
+	const numRows = 10000
+	tx, err := db.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < numRows; i += 500 {
+		query := SQLite.InsertInto(ACTOR).Columns(ACTOR.FIRST_NAME, ACTOR.LAST_NAME)
+		for j := 0; j < 500; j++ {
+			query = query.Values("PENELOPE", "GUINESS")
+		}
+		_, err = Exec(tx, query)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		b.Fatal(err)
+	}
+	query := SQLite.From(ACTOR)
+	rowmapper := func(row *Row) Actor {
+		return Actor{
+			ActorID:    row.IntField(ACTOR.ACTOR_ID),
+			FirstName:  row.StringField(ACTOR.FIRST_NAME),
+			LastName:   row.StringField(ACTOR.LAST_NAME),
+			LastUpdate: row.TimeField(ACTOR.LAST_UPDATE),
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		actors, err := FetchAll(db, query, rowmapper)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(actors) != numRows {
+			b.Fatalf("expected %d actors, got %d", numRows, len(actors))
+		}
+	}
+}
+
+// newWideTableDB returns an SQLite in-memory database with a table called
+// "wide" with numColumns integer columns (c0, c1, c2...) and numRows rows.
+func newWideTableDB(b *testing.B, numColumns, numRows int) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		b.Fatal(err)
+	}
+	// Every connection to an in-memory database gets its own database.
+	db.SetMaxOpenConns(1)
+	b.Cleanup(func() { db.Close() })
+	columns := make([]string, numColumns)
+	for i := range columns {
+		columns[i] = "c" + strconv.Itoa(i)
+	}
+	_, err = db.Exec("CREATE TABLE wide (" + strings.Join(columns, " INTEGER, ") + " INTEGER)")
+	if err != nil {
+		b.Fatal(err)
+	}
+	values := strings.Repeat(", ?", numColumns)[2:]
+	args := make([]any, numColumns)
+	for i := range args {
+		args[i] = i
+	}
+	for i := 0; i < numRows; i++ {
+		_, err = db.Exec("INSERT INTO wide ("+strings.Join(columns, ", ")+") VALUES ("+values+")", args...)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	return db
+}
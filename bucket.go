@@ -0,0 +1,183 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Bucket returns the lower bound of the fixed-width bucket that the number
+// falls into, for grouping numbers into a histogram. For a width of 10, 0 to
+// 9.99 is bucket 0, 10 to 19.99 is bucket 10 and so on. The width must be
+// greater than zero.
+//
+//	// SELECT floor(film.length / 30.0) * 30 AS length_bucket, COUNT(*) FROM film GROUP BY 1
+//	bucket := sq.Bucket(f.LENGTH, 30)
+//	q := sq.Select(bucket.As("length_bucket"), sq.Expr("COUNT(*)")).From(f).GroupBy(bucket)
+func Bucket(num Number, width float64) Expression {
+	return Expr("{}", numberBucket{num: num, width: width})
+}
+
+type numberBucket struct {
+	num   Number
+	width float64
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (b numberBucket) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if !(b.width > 0) || math.IsInf(b.width, 0) {
+		return fmt.Errorf("Bucket: width must be greater than zero, got %v", b.width)
+	}
+	width := strconv.FormatFloat(b.width, 'f', -1, 64)
+	err := writeFloor(ctx, dialect, buf, args, params, "{} / "+decimalLiteral(width), b.num)
+	if err != nil {
+		return fmt.Errorf("Bucket: %w", err)
+	}
+	buf.WriteString(" * " + width)
+	return nil
+}
+
+// WidthBucket returns the number of the bucket that the number falls into,
+// when the range from low to high is split into count buckets of equal width.
+// Numbers below low are in bucket 0 and numbers greater than or equal to high
+// are in bucket count+1. It is Postgres' width_bucket, which is emulated for
+// the other dialects.
+//
+//	// width_bucket(film.rental_rate, 0, 5, 10)
+//	sq.WidthBucket(f.RENTAL_RATE, 0, 5, 10)
+func WidthBucket(num Number, low, high float64, count int) Expression {
+	return Expr("{}", widthBucket{num: num, low: low, high: high, count: count})
+}
+
+type widthBucket struct {
+	num       Number
+	low, high float64
+	count     int
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (b widthBucket) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if !(b.low < b.high) {
+		return fmt.Errorf("WidthBucket: low (%v) must be less than high (%v)", b.low, b.high)
+	}
+	if b.count <= 0 {
+		return fmt.Errorf("WidthBucket: count must be greater than zero, got %d", b.count)
+	}
+	low := strconv.FormatFloat(b.low, 'f', -1, 64)
+	high := strconv.FormatFloat(b.high, 'f', -1, 64)
+	count := strconv.Itoa(b.count)
+	if dialect == DialectPostgres {
+		return Writef(ctx, dialect, buf, args, params, "width_bucket({}, "+low+", "+high+", "+count+")", []any{b.num})
+	}
+	err := Writef(ctx, dialect, buf, args, params, "CASE WHEN {} < "+low+" THEN 0 WHEN {} >= "+high+" THEN "+strconv.Itoa(b.count+1)+" ELSE ", []any{b.num, b.num})
+	if err != nil {
+		return fmt.Errorf("WidthBucket: %w", err)
+	}
+	err = writeFloor(ctx, dialect, buf, args, params, "({} - "+low+") * "+count+" / "+decimalLiteral(strconv.FormatFloat(b.high-b.low, 'f', -1, 64)), b.num)
+	if err != nil {
+		return fmt.Errorf("WidthBucket: %w", err)
+	}
+	buf.WriteString(" + 1 END")
+	return nil
+}
+
+// decimalLiteral makes sure that a number literal has a decimal point, so
+// that dividing an integer by it is not an integer division.
+func decimalLiteral(num string) string {
+	if strings.ContainsAny(num, ".e") {
+		return num
+	}
+	return num + ".0"
+}
+
+// writeFloor writes floor(format). SQLite only has floor() if it was compiled
+// with math functions, so it is emulated with CAST AS INTEGER (which
+// truncates towards zero) instead.
+func writeFloor(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int, format string, num Number) error {
+	switch dialect {
+	case DialectSQLite:
+		return Writef(ctx, dialect, buf, args, params, "(CAST("+format+" AS INTEGER) - ("+format+" < CAST("+format+" AS INTEGER)))", []any{num, num, num})
+	case DialectMySQL, DialectSQLServer:
+		return Writef(ctx, dialect, buf, args, params, "FLOOR("+format+")", []any{num})
+	default:
+		return Writef(ctx, dialect, buf, args, params, "floor("+format+")", []any{num})
+	}
+}
+
+// DateBucket truncates the time to the start of the given interval, for
+// grouping times into a time series. The interval is one of minute, hour,
+// day, week (starting on Monday), month or year.
+//
+//   - Postgres: date_trunc('day', t)
+//   - MySQL: CAST(DATE_FORMAT(t, '%Y-%m-%d 00:00:00') AS DATETIME)
+//   - SQLite: strftime('%Y-%m-%d 00:00:00', t)
+//   - SQL Server: DATEADD(day, DATEDIFF(day, 0, t), 0)
+//
+// SQLite has no date type so the bucket is a string e.g. 2006-01-02 00:00:00.
+//
+//	// SELECT date_trunc('day', rental.rental_date) AS day, COUNT(*) FROM rental GROUP BY 1 ORDER BY 1
+//	day := sq.DateBucket(r.RENTAL_DATE, "day")
+//	q := sq.Select(day.As("day"), sq.Expr("COUNT(*)")).From(r).GroupBy(day).OrderBy(day)
+func DateBucket(t Time, interval string) Expression {
+	return Expr("{}", dateBucket{time: t, interval: strings.ToLower(interval)})
+}
+
+type dateBucket struct {
+	time     Time
+	interval string
+}
+
+var dateBucketFormats = map[string][2]string{
+	// interval: {mysql format, sqlite format}
+	"minute": {"%Y-%m-%d %H:%i:00", "%Y-%m-%d %H:%M:00"},
+	"hour":   {"%Y-%m-%d %H:00:00", "%Y-%m-%d %H:00:00"},
+	"day":    {"%Y-%m-%d 00:00:00", "%Y-%m-%d 00:00:00"},
+	"week":   {"", "%Y-%m-%d 00:00:00"},
+	"month":  {"%Y-%m-01 00:00:00", "%Y-%m-01 00:00:00"},
+	"year":   {"%Y-01-01 00:00:00", "%Y-01-01 00:00:00"},
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (b dateBucket) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	formats, ok := dateBucketFormats[b.interval]
+	if !ok {
+		return fmt.Errorf("DateBucket: invalid interval %q (must be minute, hour, day, week, month or year)", b.interval)
+	}
+	var format string
+	var values []any
+	switch dialect {
+	case DialectPostgres:
+		format, values = "date_trunc('"+b.interval+"', {})", []any{b.time}
+	case DialectMySQL:
+		if b.interval == "week" {
+			format, values = "CAST(DATE_SUB(DATE({}), INTERVAL WEEKDAY({}) DAY) AS DATETIME)", []any{b.time, b.time}
+		} else {
+			format, values = "CAST(DATE_FORMAT({}, '"+formats[0]+"') AS DATETIME)", []any{b.time}
+		}
+	case DialectSQLite:
+		if b.interval == "week" {
+			// Move forward to Sunday (unless it already is Sunday), then
+			// back to Monday.
+			format, values = "strftime('"+formats[1]+"', {}, 'weekday 0', '-6 days')", []any{b.time}
+		} else {
+			format, values = "strftime('"+formats[1]+"', {})", []any{b.time}
+		}
+	case DialectSQLServer:
+		if b.interval == "week" {
+			// Day 0 (1900-01-01) is a Monday.
+			format, values = "DATEADD(day, DATEDIFF(day, 0, {}) / 7 * 7, 0)", []any{b.time}
+		} else {
+			format, values = "DATEADD("+b.interval+", DATEDIFF("+b.interval+", 0, {}), 0)", []any{b.time}
+		}
+	default:
+		return fmt.Errorf("DateBucket: %s is not supported", dialect)
+	}
+	err := Writef(ctx, dialect, buf, args, params, format, values)
+	if err != nil {
+		return fmt.Errorf("DateBucket: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestBucket(t *testing.T) {
+	type RENTAL struct {
+		TableStruct
+		AMOUNT      NumberField
+		RENTAL_DATE TimeField
+	}
+	r := New[RENTAL]("r")
+
+	tests := []TestTable{{
+		description: "Bucket",
+		dialect:     DialectPostgres,
+		item:        Bucket(r.AMOUNT, 10),
+		wantQuery:   "floor(r.amount / 10.0) * 10",
+	}, {
+		description: "Bucket fractional width",
+		dialect:     DialectMySQL,
+		item:        Bucket(r.AMOUNT, 0.5),
+		wantQuery:   "FLOOR(r.amount / 0.5) * 0.5",
+	}, {
+		description: "sqlite Bucket",
+		dialect:     DialectSQLite,
+		item:        Bucket(r.AMOUNT, 10),
+		wantQuery:   "(CAST(r.amount / 10.0 AS INTEGER) - (r.amount / 10.0 < CAST(r.amount / 10.0 AS INTEGER))) * 10",
+	}, {
+		description: "postgres WidthBucket",
+		dialect:     DialectPostgres,
+		item:        WidthBucket(r.AMOUNT, 0, 5, 10),
+		wantQuery:   "width_bucket(r.amount, 0, 5, 10)",
+	}, {
+		description: "sqlserver WidthBucket",
+		dialect:     DialectSQLServer,
+		item:        WidthBucket(r.AMOUNT, 0, 5, 10),
+		wantQuery:   "CASE WHEN r.amount < 0 THEN 0 WHEN r.amount >= 5 THEN 11 ELSE FLOOR((r.amount - 0) * 10 / 5.0) + 1 END",
+	}, {
+		description: "postgres DateBucket",
+		dialect:     DialectPostgres,
+		item:        DateBucket(r.RENTAL_DATE, "Day"),
+		wantQuery:   "date_trunc('day', r.rental_date)",
+	}, {
+		description: "mysql DateBucket",
+		dialect:     DialectMySQL,
+		item:        DateBucket(r.RENTAL_DATE, "month"),
+		wantQuery:   "CAST(DATE_FORMAT(r.rental_date, '%Y-%m-01 00:00:00') AS DATETIME)",
+	}, {
+		description: "mysql DateBucket week",
+		dialect:     DialectMySQL,
+		item:        DateBucket(r.RENTAL_DATE, "week"),
+		wantQuery:   "CAST(DATE_SUB(DATE(r.rental_date), INTERVAL WEEKDAY(r.rental_date) DAY) AS DATETIME)",
+	}, {
+		description: "sqlite DateBucket",
+		dialect:     DialectSQLite,
+		item:        DateBucket(r.RENTAL_DATE, "hour"),
+		wantQuery:   "strftime('%Y-%m-%d %H:00:00', r.rental_date)",
+	}, {
+		description: "sqlserver DateBucket",
+		dialect:     DialectSQLServer,
+		item:        DateBucket(r.RENTAL_DATE, "year"),
+		wantQuery:   "DATEADD(year, DATEDIFF(year, 0, r.rental_date), 0)",
+	}, {
+		description: "sqlserver DateBucket week",
+		dialect:     DialectSQLServer,
+		item:        DateBucket(r.RENTAL_DATE, "week"),
+		wantQuery:   "DATEADD(day, DATEDIFF(day, 0, r.rental_date) / 7 * 7, 0)",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "zero width",
+		item:        Bucket(r.AMOUNT, 0),
+	}, {
+		description: "WidthBucket low >= high",
+		item:        WidthBucket(r.AMOUNT, 5, 5, 10),
+	}, {
+		description: "WidthBucket zero count",
+		item:        WidthBucket(r.AMOUNT, 0, 5, 0),
+	}, {
+		description: "invalid interval",
+		dialect:     DialectPostgres,
+		item:        DateBucket(r.RENTAL_DATE, "fortnight"),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+
+	t.Run("sqlite", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := db.Exec("CREATE TABLE rental (amount INT, rental_date DATETIME)")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = Exec(db, SQLite.
+			InsertInto(r).
+			Columns(r.AMOUNT, r.RENTAL_DATE).
+			Values(-15, "2023-01-01 10:30:00"). // Sunday
+			Values(15, "2023-01-02 00:00:00").  // Monday
+			Values(50, "2023-01-08 23:59:59"),  // Sunday
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		type Result struct {
+			Bucket      int
+			WidthBucket int
+			Week        string
+			Month       string
+		}
+		results, err := FetchAll(db, SQLite.From(r).OrderBy(r.AMOUNT), func(row *Row) Result {
+			return Result{
+				Bucket:      row.Int("{}", Bucket(r.AMOUNT, 10)),
+				WidthBucket: row.Int("{}", WidthBucket(r.AMOUNT, 0, 50, 5)),
+				Week:        row.String("{}", DateBucket(r.RENTAL_DATE, "week")),
+				Month:       row.String("{}", DateBucket(r.RENTAL_DATE, "month")),
+			}
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		wantResults := []Result{
+			{Bucket: -20, WidthBucket: 0, Week: "2022-12-26 00:00:00", Month: "2023-01-01 00:00:00"},
+			{Bucket: 10, WidthBucket: 2, Week: "2023-01-02 00:00:00", Month: "2023-01-01 00:00:00"},
+			{Bucket: 50, WidthBucket: 6, Week: "2023-01-02 00:00:00", Month: "2023-01-01 00:00:00"},
+		}
+		if diff := testutil.Diff(results, wantResults); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
@@ -3,7 +3,11 @@ package sq
 import (
 	"bytes"
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -150,6 +154,12 @@ func (q CustomQuery) Append(format string, values ...any) CustomQuery {
 
 // WriteSQL implements the SQLWriter interface.
 func (q CustomQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	ctx = withWriteState(ctx)
+	// Queries embedded in the CustomQuery are its subqueries, even though
+	// its own tables are unknown.
+	state := getWriteState(ctx)
+	state.pushScope(nil)
+	defer state.popScope()
 	var err error
 	format := q.Format
 	splitAt := -1
@@ -533,7 +543,7 @@ func ToSQLContext(ctx context.Context, dialect string, w SQLWriter, params map[s
 	buf := bufpool.Get().(*bytes.Buffer)
 	buf.Reset()
 	defer bufpool.Put(buf)
-	err = w.WriteSQL(ctx, dialect, buf, &args, params)
+	err = w.WriteSQL(withWriteState(ctx), dialect, buf, &args, params)
 	query = buf.String()
 	if err != nil {
 		return query, args, err
@@ -565,34 +575,88 @@ func Exists(query Query) Predicate { return Expr("EXISTS ({})", query) }
 // NotExists returns a 'NOT EXISTS (query)' Predicate.
 func NotExists(query Query) Predicate { return Expr("NOT EXISTS ({})", query) }
 
-// In returns an 'x IN (y)' Predicate.
-func In(x, y any) Predicate {
-	_, isQueryA := x.(Query)
-	_, isRowValueB := y.(RowValue)
+// In returns an 'x IN (y)' Predicate. If y is an empty slice or RowValue,
+// the Predicate is written as '1 = 0' instead because 'x IN ()' is not valid
+// SQL (use WithStrictEmptyIn to make it an error instead).
+func In(x, y any) Predicate { return inPredicate{x: x, y: y} }
+
+// NotIn returns an 'x NOT IN (y)' Predicate. If y is an empty slice or
+// RowValue, the Predicate is written as '1 = 1' instead because 'x NOT IN ()'
+// is not valid SQL (use WithStrictEmptyIn to make it an error instead).
+func NotIn(x, y any) Predicate { return inPredicate{x: x, y: y, negate: true} }
+
+// inPredicate is an 'x IN (y)' or 'x NOT IN (y)' Predicate.
+type inPredicate struct {
+	x, y   any
+	negate bool
+}
+
+var _ Predicate = (*inPredicate)(nil)
+
+// WriteSQL implements the SQLWriter interface.
+func (p inPredicate) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	operator := "IN"
+	if p.negate {
+		operator = "NOT IN"
+	}
+	if isEmptyList(p.y) {
+		if ctx != nil && ctx.Value(strictEmptyInKey{}) != nil {
+			return fmt.Errorf("%s: list is empty", operator)
+		}
+		if p.negate {
+			buf.WriteString("1 = 1")
+		} else {
+			buf.WriteString("1 = 0")
+		}
+		return nil
+	}
+	_, isQueryA := p.x.(Query)
+	_, isRowValueB := p.y.(RowValue)
+	var format string
 	if !isQueryA && !isRowValueB {
-		return Expr("{} IN ({})", x, y)
+		format = "{} " + operator + " ({})"
 	} else if !isQueryA && isRowValueB {
-		return Expr("{} IN {}", x, y)
+		format = "{} " + operator + " {}"
 	} else if isQueryA && !isRowValueB {
-		return Expr("({}) IN ({})", x, y)
+		format = "({}) " + operator + " ({})"
 	} else {
-		return Expr("({}) IN {}", x, y)
+		format = "({}) " + operator + " {}"
 	}
+	return Writef(ctx, dialect, buf, args, params, format, []any{p.x, p.y})
 }
 
-// NotIn returns an 'x NOT IN (y)' Predicate.
-func NotIn(x, y any) Predicate {
-	_, isQueryA := x.(Query)
-	_, isRowValueB := y.(RowValue)
-	if !isQueryA && !isRowValueB {
-		return Expr("{} NOT IN ({})", x, y)
-	} else if !isQueryA && isRowValueB {
-		return Expr("{} NOT IN {}", x, y)
-	} else if isQueryA && !isRowValueB {
-		return Expr("({}) NOT IN ({})", x, y)
-	} else {
-		return Expr("({}) NOT IN {}", x, y)
+// GetAlias returns the alias of the inPredicate (always an empty string).
+func (p inPredicate) GetAlias() string { return "" }
+
+// IsField implements the Field interface.
+func (p inPredicate) IsField() {}
+
+// IsBoolean implements the Boolean interface.
+func (p inPredicate) IsBoolean() {}
+
+// isEmptyList reports whether a value is an empty RowValue or an empty
+// expandable slice.
+func isEmptyList(value any) bool {
+	if rowValue, ok := value.(RowValue); ok {
+		return len(rowValue) == 0
+	}
+	if !isExpandableSlice(value) {
+		return false
 	}
+	return reflect.ValueOf(value).Len() == 0
+}
+
+type strictEmptyInKey struct{}
+
+// WithStrictEmptyIn returns a copy of ctx in which an IN or NOT IN Predicate
+// with an empty list returns an error when it is built, instead of being
+// written as '1 = 0' (IN) or '1 = 1' (NOT IN).
+//
+//	// error: IN: list is empty
+//	ctx = sq.WithStrictEmptyIn(ctx)
+//	actors, err := sq.FetchAllContext(ctx, db, sq.From(a).Where(a.ACTOR_ID.In([]int{})), ...)
+func WithStrictEmptyIn(ctx context.Context) context.Context {
+	return context.WithValue(ctx, strictEmptyInKey{}, true)
 }
 
 // cmp returns an 'x <operator> y' Predicate.
@@ -627,6 +691,24 @@ func appendPolicy(ctx context.Context, dialect string, policies []Predicate, tab
 	return policies, nil
 }
 
+// appendDefaultFilter will append the default filter from a Table (if it
+// implements DefaultFilterTable) to a slice of predicates. The resultant slice
+// is returned.
+func appendDefaultFilter(ctx context.Context, dialect string, predicates []Predicate, table Table) ([]Predicate, error) {
+	filterTable, ok := table.(DefaultFilterTable)
+	if !ok {
+		return predicates, nil
+	}
+	filter, err := filterTable.DefaultFilter(ctx, dialect)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil {
+		predicates = append(predicates, filter)
+	}
+	return predicates, nil
+}
+
 // appendPredicates will append a slices of predicates into a predicate.
 func appendPredicates(predicate Predicate, predicates []Predicate) VariadicPredicate {
 	if predicate == nil {
@@ -646,7 +728,7 @@ func writeTop(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]an
 	var err error
 	if topLimit != nil {
 		buf.WriteString("TOP (")
-		err = WriteValue(ctx, dialect, buf, args, params, topLimit)
+		err = writeRowCount(ctx, dialect, buf, args, params, topLimit)
 		if err != nil {
 			return fmt.Errorf("TOP: %w", err)
 		}
@@ -664,3 +746,75 @@ func writeTop(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]an
 	}
 	return nil
 }
+
+// writeRowCount writes the row count of a LIMIT, OFFSET, FETCH NEXT or TOP
+// clause. The row count must be a non-negative integer (or -1, which means no
+// limit in SQLite), a pointer to one, a parameter whose value is one or an
+// SQLWriter (e.g. a subquery). MySQL only allows placeholders in LIMIT inside
+// server-side prepared statements, so integers are written into MySQL queries
+// as literals instead. Parameters are always bound so that they can be
+// rebound by compiled queries.
+func writeRowCount(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int, value any) error {
+	var paramValue any
+	switch v := value.(type) {
+	case sql.NamedArg:
+		paramValue = v.Value
+	case Parameter:
+		paramValue = v.Value
+	case NumberParameter:
+		paramValue = v.Value
+	case SQLWriter:
+		return v.WriteSQL(ctx, dialect, buf, args, params)
+	default:
+		literal, err := rowCountLiteral(dialect, value)
+		if err != nil {
+			return err
+		}
+		if dialect == DialectMySQL {
+			buf.WriteString(literal)
+			return nil
+		}
+		return WriteValue(ctx, dialect, buf, args, params, value)
+	}
+	if paramValue != nil {
+		_, err := rowCountLiteral(dialect, paramValue)
+		if err != nil {
+			return err
+		}
+	}
+	return WriteValue(ctx, dialect, buf, args, params, value)
+}
+
+// rowCountLiteral returns the integer literal of a row count, or an error if
+// the row count is not a non-negative integer (or -1 for SQLite).
+func rowCountLiteral(dialect string, value any) (string, error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		if _, ok := v.Interface().(driver.Valuer); ok {
+			break
+		}
+		v = v.Elem()
+	}
+	if v.IsValid() && !(v.Kind() == reflect.Pointer && v.IsNil()) {
+		if valuer, ok := v.Interface().(driver.Valuer); ok {
+			driverValue, err := valuer.Value()
+			if err != nil {
+				return "", err
+			}
+			v = reflect.ValueOf(driverValue)
+		}
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() == -1 && dialect == DialectSQLite {
+			return "-1", nil
+		}
+		if v.Int() < 0 {
+			return "", fmt.Errorf("row count %d is negative", v.Int())
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("row count %#v is not an integer", value)
+}
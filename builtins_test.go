@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/bokwoon95/sq/internal/testutil"
@@ -484,6 +487,34 @@ func Test_in_cmp(t *testing.T) {
 	}, {
 		description: "Query = Query", item: cmp("=", Queryf("SELECT 1"), Queryf("SELECT 2")),
 		wantQuery: "(SELECT 1) = (SELECT 2)",
+	}, {
+		description: "IN driver.Valuer slice", item: In(Expr("id"), []driverValuer{{int64(1)}, {int64(2)}}),
+		wantQuery: "id IN (?, ?)", wantArgs: []any{int64(1), int64(2)},
+	}, {
+		description: "IN nil driver.Valuer", item: In(Expr("id"), []*driverValuer{{int64(1)}, nil}),
+		wantQuery: "id IN (?, ?)", wantArgs: []any{int64(1), nil},
+	}, {
+		description: "IN DialectValuer slice", dialect: DialectMySQL,
+		item:      In(Expr("id"), []dialectValuer{{mysqlValuer: driverValuer{"mysql"}, valuer: driverValuer{"other"}}}),
+		wantQuery: "id IN (?)", wantArgs: []any{"mysql"},
+	}, {
+		description: "IN array", item: In(Expr("id"), [3]int{1, 2, 3}),
+		wantQuery: "id IN (?, ?, ?)", wantArgs: []any{1, 2, 3},
+	}, {
+		description: "= driver.Valuer slice", item: Eq(Expr("ids"), valuerSlice{1, 2}),
+		wantQuery: "ids = ?", wantArgs: []any{"1,2"},
+	}, {
+		description: "= named byte slice", item: Eq(Expr("hash"), hashBytes{0xab, 0xcd}),
+		wantQuery: "hash = ?", wantArgs: []any{hashBytes{0xab, 0xcd}},
+	}, {
+		description: "IN empty slice", item: In(Expr("id"), []int{}),
+		wantQuery: "1 = 0",
+	}, {
+		description: "NOT IN nil slice", item: NotIn(Expr("id"), []int(nil)),
+		wantQuery: "1 = 1",
+	}, {
+		description: "IN empty RowValue", item: In(Expr("id"), RowValue{}),
+		wantQuery: "1 = 0",
 	}}
 
 	for _, tt := range tests {
@@ -495,6 +526,34 @@ func Test_in_cmp(t *testing.T) {
 	}
 }
 
+func TestWithStrictEmptyIn(t *testing.T) {
+	ctx := WithStrictEmptyIn(context.Background())
+	_, _, err := ToSQLContext(ctx, "", In(Expr("id"), []int{}), nil)
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error but got nil")
+	}
+	query, _, err := ToSQLContext(ctx, "", In(Expr("id"), []int{1}), nil)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(query, "id IN (?)"); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
+
+// valuerSlice is a slice that converts itself into a single value.
+type valuerSlice []int
+
+func (s valuerSlice) Value() (driver.Value, error) {
+	strs := make([]string, len(s))
+	for i, n := range s {
+		strs[i] = strconv.Itoa(n)
+	}
+	return strings.Join(strs, ","), nil
+}
+
+type hashBytes []byte
+
 type policyTableStub struct {
 	policy Predicate
 	err    error
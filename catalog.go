@@ -0,0 +1,127 @@
+package sq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CatalogEntry is a named query registered with RegisterQuery.
+type CatalogEntry struct {
+	Name  string
+	Query Query
+}
+
+var catalog = struct {
+	mu      sync.RWMutex
+	queries map[string]Query
+}{queries: make(map[string]Query)}
+
+// RegisterQuery adds a named query to the query catalog. It is meant to be
+// called from package-level variable declarations or init functions, so that
+// every query used by an application can be compiled up front with
+// CompileAll. RegisterQuery panics if the name is empty, the query is nil or
+// the name has already been registered.
+func RegisterQuery(name string, query Query) {
+	if name == "" {
+		panic("sq: RegisterQuery name is empty")
+	}
+	if query == nil {
+		panic(fmt.Sprintf("sq: RegisterQuery %q query is nil", name))
+	}
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+	if _, ok := catalog.queries[name]; ok {
+		panic(fmt.Sprintf("sq: RegisterQuery called twice for %q", name))
+	}
+	catalog.queries[name] = query
+}
+
+// Catalog returns every query registered with RegisterQuery, sorted by name.
+func Catalog() []CatalogEntry {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+	entries := make([]CatalogEntry, 0, len(catalog.queries))
+	for name, query := range catalog.queries {
+		entries = append(entries, CatalogEntry{Name: name, Query: query})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// CompileAll compiles every query registered with RegisterQuery for the given
+// dialect with CompileExec and returns the results keyed by name. If dialect
+// is empty, each query's own dialect (or DefaultDialect) is used. Queries that
+// fail to compile do not stop the rest from being compiled: every failure is
+// reported in the returned error, which makes CompileAll suitable to be
+// called once at startup so that query building errors fail fast. Use
+// CompileRegisteredFetch to fetch the results of a registered SELECT query.
+func CompileAll(dialect string) (map[string]*CompiledExec, error) {
+	entries := Catalog()
+	compiledExecs := make(map[string]*CompiledExec, len(entries))
+	var failures []string
+	for _, entry := range entries {
+		compiledExec, err := CompileExecContext(context.Background(), withQueryDialect(entry.Query, dialect))
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", entry.Name, err))
+			continue
+		}
+		compiledExecs[entry.Name] = compiledExec
+	}
+	if len(failures) > 0 {
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("%d of %d registered queries failed to compile:", len(failures), len(entries)))
+		for i, failure := range failures {
+			b.WriteString(fmt.Sprintf("\n %02d. %s", i+1, failure))
+		}
+		return compiledExecs, errors.New(b.String())
+	}
+	return compiledExecs, nil
+}
+
+// CompileRegisteredFetch compiles the query registered with RegisterQuery
+// under the given name into a CompiledFetch (see CompileFetch). If dialect is
+// empty, the query's own dialect (or DefaultDialect) is used.
+func CompileRegisteredFetch[T any](name string, dialect string, rowmapper func(*Row) T) (*CompiledFetch[T], error) {
+	return CompileRegisteredFetchContext(context.Background(), name, dialect, rowmapper)
+}
+
+// CompileRegisteredFetchContext is like CompileRegisteredFetch but
+// additionally requires a context.Context.
+func CompileRegisteredFetchContext[T any](ctx context.Context, name string, dialect string, rowmapper func(*Row) T) (*CompiledFetch[T], error) {
+	catalog.mu.RLock()
+	query, ok := catalog.queries[name]
+	catalog.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no query registered as %q", name)
+	}
+	return CompileFetchContext(ctx, withQueryDialect(query, dialect), rowmapper)
+}
+
+// catalogQuery is a registered query compiled for a dialect other than its
+// own.
+type catalogQuery struct {
+	Query
+	dialect string
+}
+
+// withQueryDialect returns query with its dialect replaced by dialect, or
+// query itself if dialect is empty.
+func withQueryDialect(query Query, dialect string) Query {
+	if dialect == "" {
+		return query
+	}
+	return catalogQuery{Query: query, dialect: dialect}
+}
+
+// SetFetchableFields implements the Query interface.
+func (q catalogQuery) SetFetchableFields(fields []Field) (query Query, ok bool) {
+	query, ok = q.Query.SetFetchableFields(fields)
+	return catalogQuery{Query: query, dialect: q.dialect}, ok
+}
+
+// GetDialect implements the Query interface.
+func (q catalogQuery) GetDialect() string { return q.dialect }
@@ -0,0 +1,96 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestCatalog(t *testing.T) {
+	type ACTOR struct {
+		TableStruct
+		ACTOR_ID   NumberField
+		FIRST_NAME StringField
+	}
+	a := New[ACTOR]("a")
+	RegisterQuery("catalog.actor_by_id", Select(a.FIRST_NAME).From(a).Where(a.ACTOR_ID.Eq(IntParam("actor_id", 0))))
+	RegisterQuery("catalog.broken", Postgres.Select(a.FIRST_NAME).From(Postgres.SelectOne()))
+	RegisterQuery("catalog.rename_actor", Queryf("UPDATE actor SET first_name = {first_name}", StringParam("first_name", "")))
+
+	t.Run("Catalog", func(t *testing.T) {
+		var names []string
+		for _, entry := range Catalog() {
+			names = append(names, entry.Name)
+		}
+		wantNames := []string{"catalog.actor_by_id", "catalog.broken", "catalog.rename_actor"}
+		if diff := testutil.Diff(names, wantNames); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("RegisterQuery panics on duplicate name", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error(testutil.Callers(), "expected panic")
+			}
+		}()
+		RegisterQuery("catalog.actor_by_id", SelectOne())
+	})
+
+	t.Run("CompileAll", func(t *testing.T) {
+		compiledExecs, err := CompileAll(DialectPostgres)
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error")
+		}
+		wantErr := "1 of 3 registered queries failed to compile:" +
+			"\n 01. catalog.broken: postgres FROM subquery must have alias"
+		if diff := testutil.Diff(err.Error(), wantErr); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if _, ok := compiledExecs["catalog.broken"]; ok {
+			t.Error(testutil.Callers(), "catalog.broken should not have compiled")
+		}
+		_, query, args, params := compiledExecs["catalog.actor_by_id"].GetSQL()
+		if diff := testutil.Diff(query, "SELECT a.first_name FROM actor AS a WHERE a.actor_id = $1"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(args, []any{0}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(params, map[string][]int{"actor_id": {0}}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		_, query, _, _ = compiledExecs["catalog.rename_actor"].GetSQL()
+		if diff := testutil.Diff(query, "UPDATE actor SET first_name = $1"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(compiledExecs["catalog.rename_actor"].Stats().Query, "UPDATE actor SET first_name = $1"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("CompileRegisteredFetch", func(t *testing.T) {
+		db := newDB(t)
+		_, err := db.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'PENELOPE', 'GUINESS')")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		compiledFetch, err := CompileRegisteredFetch("catalog.actor_by_id", DialectSQLite, func(row *Row) string {
+			return row.String("first_name")
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		firstName, err := compiledFetch.FetchOne(db, Params{"actor_id": 1})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(firstName, "PENELOPE"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		_, err = CompileRegisteredFetch("catalog.nonexistent", "", func(row *Row) string { return "" })
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error for an unregistered query but got nil")
+		}
+	})
+}
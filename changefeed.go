@@ -0,0 +1,219 @@
+package sq
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChangeFeed describes the rows of a table that are synced incrementally by
+// SyncChanges: every time it is synced, only the rows that changed since the
+// last sync are fetched.
+type ChangeFeed struct {
+	Dialect string
+
+	// Name identifies the checkpoint of the ChangeFeed in the
+	// CheckpointStore.
+	Name string
+
+	// Table is the table whose changes are fetched.
+	Table Table
+
+	// CursorField is a timestamp (e.g. updated_at) or sequence number that
+	// increases every time a row is inserted or updated. Changes are fetched
+	// in CursorField order.
+	CursorField Field
+
+	// KeyField (usually the primary key) breaks ties between rows with the
+	// same CursorField, so that a page boundary never falls between them. It
+	// should be provided unless the CursorField is unique.
+	KeyField Field
+
+	// Where (optional) restricts the rows that are synced.
+	Where Predicate
+
+	// Store is where checkpoints are loaded from and saved to.
+	Store CheckpointStore
+
+	// CheckpointDB is the database the transactions of SyncChanges are run
+	// on. Defaults to the db passed to SyncChanges. It should be the database
+	// the changes are written to, so that they are committed together with
+	// the checkpoint.
+	CheckpointDB DB
+}
+
+// Checkpoint is the position of a ChangeFeed: the CursorField and KeyField
+// values of the last row that was synced.
+type Checkpoint struct {
+	Cursor any
+	Key    any
+}
+
+// CheckpointStore loads and saves the checkpoints of ChangeFeeds.
+type CheckpointStore interface {
+	// LoadCheckpoint returns the checkpoint with the given name. ok is false
+	// if there is no such checkpoint, in which case the sync starts from the
+	// beginning.
+	LoadCheckpoint(ctx context.Context, tx DB, name string) (checkpoint Checkpoint, ok bool, err error)
+
+	// SaveCheckpoint saves the checkpoint with the given name. It must use
+	// tx, so that the checkpoint is only saved if the changes are handled
+	// successfully.
+	SaveCheckpoint(ctx context.Context, tx DB, name string, checkpoint Checkpoint) error
+}
+
+// SyncChanges fetches the rows of a ChangeFeed that changed since its last
+// checkpoint, pageSize rows at a time, and passes each page to handle. Every
+// page is handled in its own transaction (begun on ChangeFeed.CheckpointDB)
+// in which the checkpoint is loaded, handle is called and the new checkpoint
+// is saved. If handle returns an error, the transaction is rolled back and
+// the page will be fetched again by the next sync. SyncChanges returns once
+// there are no more changes, along with the number of rows synced.
+//
+//	// SELECT ... FROM actor
+//	// WHERE actor.last_update > $1 OR (actor.last_update = $2 AND actor.actor_id > $3)
+//	// ORDER BY actor.last_update, actor.actor_id
+//	// LIMIT 500
+//	n, err := sq.SyncChanges(db, sq.ChangeFeed{
+//	    Name:        "actor_search_index",
+//	    Table:       a,
+//	    CursorField: a.LAST_UPDATE,
+//	    KeyField:    a.ACTOR_ID,
+//	    Store:       store,
+//	}, 500, actorRowMapper, func(tx sq.DB, actors []Actor) error {
+//	    return searchIndex.Upsert(actors)
+//	})
+func SyncChanges[T any](db DB, feed ChangeFeed, pageSize int, rowmapper func(*Row) T, handle func(tx DB, rows []T) error) (rowsSynced int, err error) {
+	return syncChanges(context.Background(), db, feed, pageSize, rowmapper, handle, 1)
+}
+
+// SyncChangesContext is like SyncChanges but additionally requires a
+// context.Context.
+func SyncChangesContext[T any](ctx context.Context, db DB, feed ChangeFeed, pageSize int, rowmapper func(*Row) T, handle func(tx DB, rows []T) error) (rowsSynced int, err error) {
+	return syncChanges(ctx, db, feed, pageSize, rowmapper, handle, 1)
+}
+
+func syncChanges[T any](ctx context.Context, db DB, feed ChangeFeed, pageSize int, rowmapper func(*Row) T, handle func(tx DB, rows []T) error, skip int) (rowsSynced int, err error) {
+	if db == nil {
+		return 0, fmt.Errorf("db is nil")
+	}
+	if feed.Name == "" {
+		return 0, fmt.Errorf("SyncChanges: ChangeFeed.Name is empty")
+	}
+	if feed.Table == nil {
+		return 0, fmt.Errorf("SyncChanges: ChangeFeed.Table is nil")
+	}
+	if feed.CursorField == nil {
+		return 0, fmt.Errorf("SyncChanges: ChangeFeed.CursorField is nil")
+	}
+	if feed.Store == nil {
+		return 0, fmt.Errorf("SyncChanges: ChangeFeed.Store is nil")
+	}
+	if pageSize <= 0 {
+		return 0, fmt.Errorf("SyncChanges: page size must be greater than zero, got %d", pageSize)
+	}
+	if rowmapper == nil {
+		return 0, fmt.Errorf("rowmapper is nil")
+	}
+	if handle == nil {
+		return 0, fmt.Errorf("SyncChanges: handle is nil")
+	}
+	checkpointDB := feed.CheckpointDB
+	if checkpointDB == nil {
+		checkpointDB = db
+	}
+	dialect := feed.Dialect
+	if dialect == "" {
+		dialect = dbDialect(db)
+	}
+	for {
+		var pageRows int
+		err = withTx(ctx, checkpointDB, nil, func(tx DB) error {
+			// If the changes are fetched from the CheckpointDB, they are
+			// fetched inside the transaction as well.
+			fetchDB := db
+			if feed.CheckpointDB == nil {
+				fetchDB = tx
+			}
+			checkpoint, ok, err := feed.Store.LoadCheckpoint(ctx, tx, feed.Name)
+			if err != nil {
+				return fmt.Errorf("loading checkpoint %s: %w", feed.Name, err)
+			}
+			query := changesQuery(dialect, feed, pageSize, checkpoint, ok)
+			var rows []T
+			cursor, err := fetchCursor(ctx, fetchDB, query, func(row *Row) T {
+				checkpoint.Cursor = row.Value("{}", feed.CursorField)
+				if feed.KeyField != nil {
+					checkpoint.Key = row.Value("{}", feed.KeyField)
+				}
+				return rowmapper(row)
+			}, skip+3)
+			if err != nil {
+				return err
+			}
+			defer cursor.Close()
+			for cursor.Next() {
+				result, err := cursor.Result()
+				if err != nil {
+					return err
+				}
+				rows = append(rows, result)
+			}
+			if err := cursor.Close(); err != nil {
+				return err
+			}
+			pageRows = len(rows)
+			if pageRows == 0 {
+				return nil
+			}
+			err = handle(tx, rows)
+			if err != nil {
+				return err
+			}
+			// checkpoint now holds the values of the last row.
+			err = feed.Store.SaveCheckpoint(ctx, tx, feed.Name, checkpoint)
+			if err != nil {
+				return fmt.Errorf("saving checkpoint %s: %w", feed.Name, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return rowsSynced, err
+		}
+		rowsSynced += pageRows
+		if pageRows < pageSize {
+			return rowsSynced, nil
+		}
+	}
+}
+
+// changesQuery returns the query that fetches the next page of changes after
+// the checkpoint.
+func changesQuery(dialect string, feed ChangeFeed, pageSize int, checkpoint Checkpoint, hasCheckpoint bool) SelectQuery {
+	query := SelectQuery{
+		Dialect:       dialect,
+		FromTable:     feed.Table,
+		OrderByFields: []Field{feed.CursorField},
+		LimitRows:     pageSize,
+	}
+	if feed.KeyField != nil {
+		query.OrderByFields = append(query.OrderByFields, feed.KeyField)
+	}
+	var predicates []Predicate
+	if feed.Where != nil {
+		predicates = append(predicates, feed.Where)
+	}
+	if hasCheckpoint {
+		if feed.KeyField == nil {
+			predicates = append(predicates, Gt(feed.CursorField, checkpoint.Cursor))
+		} else {
+			predicates = append(predicates, Or(
+				Gt(feed.CursorField, checkpoint.Cursor),
+				And(Eq(feed.CursorField, checkpoint.Cursor), Gt(feed.KeyField, checkpoint.Key)),
+			))
+		}
+	}
+	if len(predicates) > 0 {
+		query.WherePredicate = And(predicates...)
+	}
+	return query
+}
@@ -0,0 +1,162 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+// tableCheckpointStore stores checkpoints in the checkpoint table.
+type tableCheckpointStore struct{}
+
+func (tableCheckpointStore) LoadCheckpoint(ctx context.Context, tx DB, name string) (checkpoint Checkpoint, ok bool, err error) {
+	checkpoint, err = FetchOneContext(ctx, tx, SQLite.Queryf("SELECT {*} FROM checkpoint WHERE name = {}", name), func(row *Row) Checkpoint {
+		return Checkpoint{
+			Cursor: row.Value("cursor"),
+			Key:    row.Value("key"),
+		}
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	return checkpoint, true, nil
+}
+
+func (tableCheckpointStore) SaveCheckpoint(ctx context.Context, tx DB, name string, checkpoint Checkpoint) error {
+	_, err := ExecContext(ctx, tx, SQLite.Queryf(
+		"INSERT INTO checkpoint (name, cursor, key) VALUES ({}, {}, {})"+
+			" ON CONFLICT (name) DO UPDATE SET cursor = EXCLUDED.cursor, key = EXCLUDED.key",
+		name, checkpoint.Cursor, checkpoint.Key,
+	))
+	return err
+}
+
+func TestSyncChanges(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := db.Exec("CREATE TABLE checkpoint (name TEXT PRIMARY KEY, cursor, key)")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	insertActors := func(actors ...Actor) {
+		_, err := Exec(db, SQLite.
+			InsertInto(ACTOR).
+			ColumnValues(func(col *Column) {
+				for _, actor := range actors {
+					col.SetInt(ACTOR.ACTOR_ID, actor.ActorID)
+					col.SetString(ACTOR.FIRST_NAME, actor.FirstName)
+					col.SetString(ACTOR.LAST_NAME, actor.LastName)
+				}
+			}),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	}
+	// The last names are the cursor. Several actors share a last name, so
+	// the actor_id is needed to break ties.
+	insertActors(
+		Actor{ActorID: 1, FirstName: "PENELOPE", LastName: "a"},
+		Actor{ActorID: 2, FirstName: "NICK", LastName: "b"},
+		Actor{ActorID: 3, FirstName: "ED", LastName: "b"},
+		Actor{ActorID: 4, FirstName: "JENNIFER", LastName: "b"},
+	)
+	feed := ChangeFeed{
+		Name:        "actor",
+		Table:       ACTOR,
+		CursorField: ACTOR.LAST_NAME,
+		KeyField:    ACTOR.ACTOR_ID,
+		Store:       tableCheckpointStore{},
+	}
+	rowmapper := func(row *Row) string {
+		return row.StringField(ACTOR.FIRST_NAME)
+	}
+	var synced [][]string
+	handle := func(tx DB, names []string) error {
+		synced = append(synced, names)
+		return nil
+	}
+
+	n, err := SyncChanges(db, feed, 2, rowmapper, handle)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(n, 4); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if diff := testutil.Diff(synced, [][]string{{"PENELOPE", "NICK"}, {"ED", "JENNIFER"}}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	// Only the new changes are synced.
+	synced = nil
+	insertActors(
+		Actor{ActorID: 5, FirstName: "JOHNNY", LastName: "b"},
+		Actor{ActorID: 6, FirstName: "BETTE", LastName: "c"},
+	)
+	n, err = SyncChanges(db, feed, 10, rowmapper, handle)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(n, 2); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if diff := testutil.Diff(synced, [][]string{{"JOHNNY", "BETTE"}}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	// If handle fails, the checkpoint is not saved and the changes are synced
+	// again next time.
+	insertActors(Actor{ActorID: 7, FirstName: "GRACE", LastName: "d"})
+	_, err = SyncChanges(db, feed, 10, rowmapper, func(tx DB, names []string) error {
+		return errors.New("handle failed")
+	})
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error but got nil")
+	}
+	synced = nil
+	n, err = SyncChanges(db, feed, 10, rowmapper, handle)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(synced, [][]string{{"GRACE"}}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	// No changes.
+	synced = nil
+	n, err = SyncChanges(db, feed, 10, rowmapper, handle)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(n, 0); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if len(synced) != 0 {
+		t.Error(testutil.Callers(), "expected no changes, got", synced)
+	}
+}
+
+func Test_changesQuery(t *testing.T) {
+	feed := ChangeFeed{
+		Table:       ACTOR,
+		CursorField: ACTOR.LAST_UPDATE,
+		KeyField:    ACTOR.ACTOR_ID,
+		Where:       ACTOR.LAST_NAME.NeString("x"),
+	}
+	TestTable{
+		dialect: DialectPostgres,
+		item: changesQuery(DialectPostgres, feed, 100, Checkpoint{Cursor: 10, Key: 5}, true).
+			Select(ACTOR.ACTOR_ID),
+		wantQuery: "SELECT actor.actor_id FROM actor" +
+			" WHERE actor.last_name <> $1 AND (actor.last_update > $2 OR (actor.last_update = $3 AND actor.actor_id > $4))" +
+			" ORDER BY actor.last_update, actor.actor_id LIMIT $5",
+		wantArgs: []any{"x", 10, 10, 5, 100},
+	}.assert(t)
+}
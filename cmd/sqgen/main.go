@@ -0,0 +1,144 @@
+// Command sqgen generates a Go file of compiled queries from the exported
+// sq.Query variables of a package. It is meant to be invoked with go:generate:
+//
+//	//go:generate go run github.com/bokwoon95/sq/cmd/sqgen -dialect postgres,sqlite
+//
+// sqgen parses the package in the current directory (or -dir), then builds
+// and runs a throwaway program that imports the package and passes every
+// exported variable implementing sq.Query to sq.GenerateCode. The generated
+// file (default sq_queries.go) contains the query string of each query for
+// each dialect, its compiled form and a typed struct of its params.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	var dir, dialects, output, naming string
+	flag.StringVar(&dir, "dir", ".", "directory of the package containing the queries")
+	flag.StringVar(&dialects, "dialect", "", "comma-separated list of dialects to generate (sqlite, postgres, mysql, sqlserver)")
+	flag.StringVar(&output, "o", "sq_queries.go", "name of the generated file, relative to -dir")
+	flag.StringVar(&naming, "naming", "lower", "naming strategy used at runtime (lower, snake, pascal), params struct fields matching it are left untagged")
+	flag.Parse()
+	err := run(dir, strings.Split(dialects, ","), output, naming)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sqgen:", err)
+		os.Exit(1)
+	}
+}
+
+// namingStrategies maps the -naming flag to the corresponding sq.NamingStrategy.
+var namingStrategies = map[string]string{
+	"lower":  "sq.LowerCase",
+	"snake":  "sq.SnakeCase",
+	"pascal": "sq.PascalCase",
+}
+
+func run(dir string, dialects []string, output string, naming string) error {
+	namingStrategy, ok := namingStrategies[naming]
+	if !ok {
+		return fmt.Errorf("invalid -naming %q (must be lower, snake or pascal)", naming)
+	}
+	for i := range dialects {
+		dialects[i] = strings.TrimSpace(dialects[i])
+		if dialects[i] == "" {
+			return fmt.Errorf("-dialect must be provided")
+		}
+	}
+	packageName, varNames, err := exportedVars(dir, output)
+	if err != nil {
+		return err
+	}
+	if packageName == "main" {
+		return fmt.Errorf("cannot generate queries for package main because it cannot be imported")
+	}
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}", ".")
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	b, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("go list: %w", err)
+	}
+	importPath := strings.TrimSpace(string(b))
+
+	// Directories starting with an underscore are ignored by the go tool's
+	// ./... pattern, so the throwaway program does not disturb the module.
+	tempDir, err := os.MkdirTemp(dir, "_sqgen")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	var src bytes.Buffer
+	src.WriteString("package main\n\n")
+	src.WriteString("import (\n\t\"fmt\"\n\t\"os\"\n\n\t\"github.com/bokwoon95/sq\"\n\tpkg " + strconv.Quote(importPath) + "\n)\n\n")
+	src.WriteString("func main() {\n\tstrategy := sq.NamingStrategy(" + namingStrategy + ")\n\tsq.DefaultNamingStrategy.Store(&strategy)\n")
+	src.WriteString("\tvar entries []sq.CatalogEntry\n\tvar v any\n")
+	for _, varName := range varNames {
+		src.WriteString("\tv = pkg." + varName + "\n")
+		src.WriteString("\tif query, ok := v.(sq.Query); ok {\n")
+		src.WriteString("\t\tentries = append(entries, sq.CatalogEntry{Name: " + strconv.Quote(varName) + ", Query: query})\n\t}\n")
+	}
+	src.WriteString("\t_ = v\n")
+	src.WriteString("\terr := sq.GenerateCode(os.Stdout, " + strconv.Quote(packageName) + ", entries, " + fmt.Sprintf("%#v", dialects) + ")\n")
+	src.WriteString("\tif err != nil {\n\t\tfmt.Fprintln(os.Stderr, err)\n\t\tos.Exit(1)\n\t}\n}\n")
+	err = os.WriteFile(filepath.Join(tempDir, "main.go"), src.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+	cmd = exec.Command("go", "run", ".")
+	cmd.Dir = tempDir
+	cmd.Stderr = os.Stderr
+	b, err = cmd.Output()
+	if err != nil {
+		return fmt.Errorf("generating queries: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, output), b, 0644)
+}
+
+// exportedVars returns the package name and the names of the exported
+// package-level variables of the non-test Go files in dir, skipping the
+// previously generated output file.
+func exportedVars(dir string, output string) (packageName string, varNames []string, err error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fileInfo os.FileInfo) bool {
+		return !strings.HasSuffix(fileInfo.Name(), "_test.go") && fileInfo.Name() != output
+	}, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf("expected 1 package in %s, found %d", dir, len(pkgs))
+	}
+	for name, pkg := range pkgs {
+		packageName = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range genDecl.Specs {
+					for _, ident := range spec.(*ast.ValueSpec).Names {
+						if ident.IsExported() {
+							varNames = append(varNames, ident.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(varNames)
+	return packageName, varNames, nil
+}
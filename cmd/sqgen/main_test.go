@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping sqgen test in short mode")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not found")
+	}
+	// The fixture is copied into a directory inside the module so that it
+	// can be imported by the program that sqgen builds.
+	dir, err := os.MkdirTemp("testdata", "queries")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	defer os.RemoveAll(dir)
+	src, err := os.ReadFile(filepath.Join("testdata", "queries", "queries.go"))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "queries.go"), src, 0644)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+
+	err = run(dir, []string{"postgres", "sqlite"}, "sq_queries.go", "lower")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "sq_queries.go"))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	code := string(b)
+	for _, want := range []string{
+		"package queries\n",
+		"const ActorByIDPostgres = `SELECT actor.first_name FROM actor WHERE actor.actor_id = $1 AND actor.last_update > $2`",
+		"const ActorByIDSQLite = `SELECT actor.first_name FROM actor WHERE actor.actor_id = $actor_id AND actor.last_update > $last_update`",
+		"type ActorByIDParams struct {",
+		"const DeleteActorPostgres = `DELETE FROM actor WHERE actor.actor_id = $1`",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("%s generated code does not contain %q:\n%s", testutil.Callers(), want, code)
+		}
+	}
+	for _, notWant := range []string{"ACTORPostgres", "notExported"} {
+		if strings.Contains(code, notWant) {
+			t.Errorf("%s generated code contains %q:\n%s", testutil.Callers(), notWant, code)
+		}
+	}
+
+	// The generated file must compile together with the package.
+	cmd := exec.Command("go", "vet", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatal(testutil.Callers(), "go vet:", err, "\n"+string(out))
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	err := run("testdata/queries", []string{""}, "sq_queries.go", "lower")
+	if err == nil {
+		t.Error(testutil.Callers(), "expected error for missing -dialect but got nil")
+	}
+	err = run("testdata/queries", []string{"postgres"}, "sq_queries.go", "camel")
+	if err == nil {
+		t.Error(testutil.Callers(), "expected error for invalid -naming but got nil")
+	}
+}
@@ -0,0 +1,29 @@
+// Package queries is the fixture package that sqgen is run on in tests.
+package queries
+
+import (
+	"time"
+
+	"github.com/bokwoon95/sq"
+)
+
+var ACTOR = sq.New[struct {
+	sq.TableStruct `sq:"actor"`
+	ACTOR_ID       sq.NumberField
+	FIRST_NAME     sq.StringField
+	LAST_UPDATE    sq.TimeField
+}]("")
+
+var ActorByID = sq.
+	Select(ACTOR.FIRST_NAME).
+	From(ACTOR).
+	Where(
+		ACTOR.ACTOR_ID.Eq(sq.IntParam("actor_id", 0)),
+		ACTOR.LAST_UPDATE.Gt(sq.TimeParam("last_update", time.Unix(1, 0).UTC())),
+	)
+
+var DeleteActor = sq.
+	DeleteFrom(ACTOR).
+	Where(ACTOR.ACTOR_ID.Eq(sq.IntParam("actor_id", 0)))
+
+var notExported = sq.Select(ACTOR.ACTOR_ID).From(ACTOR)
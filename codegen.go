@@ -0,0 +1,484 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// GenerateCode renders each query for each dialect and writes a Go source
+// file for the given package. For every query (say ActorByID) and dialect
+// (say postgres), it emits
+//
+//   - a constant ActorByIDPostgres containing the query string,
+//   - a variable ActorByIDPostgresCompiled containing the *CompiledExec,
+//
+// and if the query has named parameters, a struct type ActorByIDParams with a
+// field for each parameter. A field is tagged with the name of its parameter
+// unless the DefaultNamingStrategy already translates the field name into the
+// parameter name. Its Params method returns the sq.Params to pass
+// to the compiled query. The type of each field is taken from the default
+// value of its parameter, falling back to any.
+//
+// GenerateCode is used by the sqgen command (github.com/bokwoon95/sq/cmd/sqgen)
+// but can also be called directly.
+func GenerateCode(w io.Writer, packageName string, entries []CatalogEntry, dialects []string) error {
+	if packageName == "" {
+		return fmt.Errorf("GenerateCode: package name is empty")
+	}
+	if len(dialects) == 0 {
+		return fmt.Errorf("GenerateCode: no dialects provided")
+	}
+	for _, dialect := range dialects {
+		if dialectSuffix(dialect) == "" {
+			return fmt.Errorf("GenerateCode: unknown dialect %q", dialect)
+		}
+	}
+	g := &codeGenerator{imports: make(map[string]struct{})}
+	for _, entry := range entries {
+		err := g.writeEntry(entry, dialects)
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Name, err)
+		}
+	}
+	var src bytes.Buffer
+	src.WriteString("// Code generated by sqgen. DO NOT EDIT.\n\n")
+	src.WriteString("package " + packageName + "\n")
+	if len(entries) > 0 {
+		g.imports["github.com/bokwoon95/sq"] = struct{}{}
+	}
+	if len(g.imports) > 0 {
+		importPaths := make([]string, 0, len(g.imports))
+		for importPath := range g.imports {
+			importPaths = append(importPaths, importPath)
+		}
+		sort.Slice(importPaths, func(i, j int) bool {
+			iStd, jStd := !strings.Contains(importPaths[i], "."), !strings.Contains(importPaths[j], ".")
+			if iStd != jStd {
+				return iStd
+			}
+			return importPaths[i] < importPaths[j]
+		})
+		src.WriteString("\nimport (\n")
+		for i, importPath := range importPaths {
+			// Separate the standard library imports from the rest.
+			if i > 0 && !strings.Contains(importPaths[i-1], ".") && strings.Contains(importPath, ".") {
+				src.WriteString("\n")
+			}
+			src.WriteString(strconv.Quote(importPath) + "\n")
+		}
+		src.WriteString(")\n")
+	}
+	src.Write(g.buf.Bytes())
+	b, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("GenerateCode: formatting generated code: %w", err)
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+type codeGenerator struct {
+	buf     bytes.Buffer
+	imports map[string]struct{}
+}
+
+func (g *codeGenerator) writeEntry(entry CatalogEntry, dialects []string) error {
+	if !isExportedIdentifier(entry.Name) {
+		return fmt.Errorf("name is not an exported Go identifier")
+	}
+	if entry.Query == nil {
+		return fmt.Errorf("query is nil")
+	}
+	paramTypes := make(map[string]string)
+	for _, dialect := range dialects {
+		compiledExec, err := CompileExecContext(context.Background(), withQueryDialect(entry.Query, dialect))
+		if err != nil {
+			return err
+		}
+		name := entry.Name + dialectSuffix(dialect)
+		g.buf.WriteString("\n// " + name + " is the " + dialect + " query string of " + entry.Name + ".\n")
+		g.buf.WriteString("const " + name + " = " + goStringLiteral(compiledExec.query) + "\n")
+		g.buf.WriteString("\n// " + name + "Compiled is the " + dialect + " CompiledExec of " + entry.Name + ".\n")
+		g.buf.WriteString("var " + name + "Compiled = sq.NewCompiledExec(" + strconv.Quote(dialect) + ", " + name + ", []any{")
+		for i, arg := range compiledExec.args {
+			literal, err := g.goLiteral(arg)
+			if err != nil {
+				return fmt.Errorf("%s arg #%d: %w", dialect, i+1, err)
+			}
+			if i > 0 {
+				g.buf.WriteString(", ")
+			}
+			g.buf.WriteString(literal)
+		}
+		g.buf.WriteString("}, map[string][]int{")
+		paramNames := make([]string, 0, len(compiledExec.params))
+		for paramName := range compiledExec.params {
+			paramNames = append(paramNames, paramName)
+		}
+		sort.Strings(paramNames)
+		for i, paramName := range paramNames {
+			indexes := compiledExec.params[paramName]
+			if i > 0 {
+				g.buf.WriteString(", ")
+			}
+			g.buf.WriteString(strconv.Quote(paramName) + ": {")
+			for j, index := range indexes {
+				if j > 0 {
+					g.buf.WriteString(", ")
+				}
+				g.buf.WriteString(strconv.Itoa(index))
+			}
+			g.buf.WriteString("}")
+			arg := compiledExec.args[indexes[0]]
+			if namedArg, ok := arg.(sql.NamedArg); ok {
+				arg = namedArg.Value
+			}
+			typ := g.goType(arg)
+			if existing, ok := paramTypes[paramName]; ok && existing != typ {
+				typ = "any"
+			}
+			paramTypes[paramName] = typ
+		}
+		g.buf.WriteString("})\n")
+	}
+	if len(paramTypes) == 0 {
+		return nil
+	}
+	paramNames := make([]string, 0, len(paramTypes))
+	for paramName := range paramTypes {
+		paramNames = append(paramNames, paramName)
+	}
+	sort.Strings(paramNames)
+	fieldNames := make([]string, len(paramNames))
+	paramOfField := make(map[string]string)
+	for i, paramName := range paramNames {
+		fieldNames[i] = goFieldName(paramName)
+		if other, ok := paramOfField[fieldNames[i]]; ok {
+			return fmt.Errorf("params %q and %q both map to the field name %s", other, paramName, fieldNames[i])
+		}
+		paramOfField[fieldNames[i]] = paramName
+	}
+	typeName := entry.Name + "Params"
+	g.buf.WriteString("\n// " + typeName + " are the params of " + entry.Name + ".\n")
+	g.buf.WriteString("type " + typeName + " struct {\n")
+	for i, paramName := range paramNames {
+		g.buf.WriteString(fieldNames[i] + " " + paramTypes[paramName])
+		// The struct tag is only needed if the field name does not already
+		// match the param name.
+		if !strings.EqualFold(fieldNames[i], paramName) && translateName(fieldNames[i]) != paramName {
+			g.buf.WriteString(" `sq:" + strconv.Quote(paramName) + "`")
+		}
+		g.buf.WriteString("\n")
+	}
+	g.buf.WriteString("}\n")
+	g.buf.WriteString("\n// Params returns the params as an sq.Params.\n")
+	g.buf.WriteString("func (p " + typeName + ") Params() sq.Params {\n")
+	g.buf.WriteString("return sq.Params{\n")
+	for i, paramName := range paramNames {
+		g.buf.WriteString(strconv.Quote(paramName) + ": p." + fieldNames[i] + ",\n")
+	}
+	g.buf.WriteString("}\n}\n")
+	return nil
+}
+
+// goLiteral returns the Go literal of an arg.
+func (g *codeGenerator) goLiteral(arg any) (string, error) {
+	switch arg := arg.(type) {
+	case nil:
+		return "nil", nil
+	case sql.NamedArg:
+		literal, err := g.goLiteral(arg.Value)
+		if err != nil {
+			return "", err
+		}
+		g.imports["database/sql"] = struct{}{}
+		return "sql.Named(" + strconv.Quote(arg.Name) + ", " + literal + ")", nil
+	case time.Time:
+		g.imports["time"] = struct{}{}
+		return fmt.Sprintf("time.Unix(%d, %d).UTC()", arg.Unix(), arg.Nanosecond()), nil
+	case string:
+		return strconv.Quote(arg), nil
+	case []byte:
+		return fmt.Sprintf("%#v", arg), nil
+	case bool, int:
+		return fmt.Sprintf("%#v", arg), nil
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%T(%#v)", arg, arg), nil
+	}
+	return "", fmt.Errorf("cannot generate a Go literal for %T", arg)
+}
+
+// goType returns the Go type used for a params struct field.
+func (g *codeGenerator) goType(arg any) string {
+	switch arg.(type) {
+	case time.Time:
+		g.imports["time"] = struct{}{}
+		return "time.Time"
+	case []byte:
+		return "[]byte"
+	case bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return reflect.TypeOf(arg).String()
+	}
+	return "any"
+}
+
+func dialectSuffix(dialect string) string {
+	switch dialect {
+	case DialectSQLite:
+		return "SQLite"
+	case DialectPostgres:
+		return "Postgres"
+	case DialectMySQL:
+		return "MySQL"
+	case DialectSQLServer:
+		return "SQLServer"
+	}
+	return ""
+}
+
+func goStringLiteral(s string) string {
+	if strings.Contains(s, "`") || strings.Contains(s, "\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+var goInitialisms = map[string]string{
+	"api": "API", "http": "HTTP", "id": "ID", "ip": "IP", "json": "JSON",
+	"sql": "SQL", "url": "URL", "uuid": "UUID",
+}
+
+// goFieldName converts a param name (e.g. actor_id) into an exported Go
+// field name (e.g. ActorID).
+func goFieldName(paramName string) string {
+	words := strings.FieldsFunc(paramName, func(char rune) bool {
+		return char == '_' || (!unicode.IsLetter(char) && !unicode.IsDigit(char))
+	})
+	var b strings.Builder
+	for _, word := range words {
+		if initialism, ok := goInitialisms[strings.ToLower(word)]; ok {
+			b.WriteString(initialism)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "P" + name
+	}
+	return name
+}
+
+func isExportedIdentifier(name string) bool {
+	for i, char := range name {
+		if i == 0 && !unicode.IsUpper(char) {
+			return false
+		}
+		if char != '_' && !unicode.IsLetter(char) && !unicode.IsDigit(char) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// GenerateRowMapper writes the source code of a function that returns a
+// rowmapper mapping the fields of a table struct into a struct T, using the
+// same rules as StructRowMapper. The generated code calls the Row method
+// matching the type of each field of T (e.g. IntField for an int,
+// NullStringField for an sql.NullString), so it can be pasted into (or
+// generated into) the package that defines the table struct and T and edited
+// from there. Fields of T without a matching table field are listed in a
+// comment.
+//
+//	a := sq.New[ACTOR]("")
+//	err := sq.GenerateRowMapper[Actor](os.Stdout, a)
+//	// // ActorRowMapper returns a rowmapper that maps the fields of an ACTOR into an Actor.
+//	// func ActorRowMapper(a ACTOR) func(*sq.Row) Actor {
+//	//     return func(row *sq.Row) Actor {
+//	//         return Actor{
+//	//             ActorID:    row.IntField(a.ACTOR_ID),
+//	//             FirstName:  row.StringField(a.FIRST_NAME),
+//	//             LastName:   row.StringField(a.LAST_NAME),
+//	//             LastUpdate: row.TimeField(a.LAST_UPDATE),
+//	//         }
+//	//     }
+//	// }
+func GenerateRowMapper[T any](w io.Writer, table Table) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct || typ.Name() == "" {
+		return fmt.Errorf("GenerateRowMapper: %s is not a named struct", typ)
+	}
+	tableType := reflect.Indirect(reflect.ValueOf(table)).Type()
+	if tableType.Kind() != reflect.Struct || tableType.Name() == "" {
+		return fmt.Errorf("GenerateRowMapper: %s is not a table struct", tableType)
+	}
+	typeName, tableName := typ.Name(), tableType.Name()
+	tableVar := strings.ToLower(tableName[:1])
+	resultVar := strings.ToLower(typeName[:1]) + typeName[1:]
+	if resultVar == tableVar || resultVar == "row" {
+		resultVar = "result"
+	}
+	// Each field of T is either assigned the value returned by a Row method
+	// in the composite literal, or passed by pointer to a Row method in a
+	// statement after it.
+	var values, statements []string
+	matched := make([]bool, typ.NumField())
+	matchField := structFieldMatcher(typ)
+	fields, names, goNames := tableStructFields(table)
+	for i, field := range fields {
+		index, ok := matchField(names[i])
+		if !ok {
+			continue
+		}
+		matched[index] = true
+		structField := typ.Field(index)
+		fieldExpr := tableVar + "." + goNames[i]
+		if method := rowMapperMethod(structField.Type, field); method != "" {
+			values = append(values, structField.Name+": "+strings.ReplaceAll(method, "%", fieldExpr)+",")
+			continue
+		}
+		destPtr := "&" + resultVar + "." + structField.Name
+		statements = append(statements, rowMapperScanMethod(structField.Type, field)+"("+destPtr+", "+fieldExpr+")")
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		if !matched[i] && structField.IsExported() && structField.Tag.Get("sq") != "-" {
+			values = append(values, "// "+structField.Name+": no matching field in "+tableName)
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString("// " + typeName + "RowMapper returns a rowmapper that maps the fields of " + withArticle(tableName) + " into " + withArticle(typeName) + ".\n")
+	buf.WriteString("func " + typeName + "RowMapper(" + tableVar + " " + tableName + ") func(*sq.Row) " + typeName + " {\n")
+	buf.WriteString("return func(row *sq.Row) " + typeName + " {\n")
+	if len(statements) == 0 {
+		buf.WriteString("return " + typeName + "{\n")
+	} else {
+		buf.WriteString(resultVar + " := " + typeName + "{\n")
+	}
+	for _, value := range values {
+		buf.WriteString(value + "\n")
+	}
+	buf.WriteString("}\n")
+	if len(statements) > 0 {
+		for _, statement := range statements {
+			buf.WriteString(statement + "\n")
+		}
+		buf.WriteString("return " + resultVar + "\n")
+	}
+	buf.WriteString("}\n}\n")
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("GenerateRowMapper: formatting generated code: %w", err)
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+var (
+	byteSliceType   = reflect.TypeOf([]byte(nil))
+	boolSliceType   = reflect.TypeOf([]bool(nil))
+	timeType        = reflect.TypeOf(time.Time{})
+	nullBoolType    = reflect.TypeOf(sql.NullBool{})
+	nullFloat64Type = reflect.TypeOf(sql.NullFloat64{})
+	nullInt64Type   = reflect.TypeOf(sql.NullInt64{})
+	nullStringType  = reflect.TypeOf(sql.NullString{})
+	nullTimeType    = reflect.TypeOf(sql.NullTime{})
+)
+
+// rowMapperMethod returns the call (with % standing in for the field) of the
+// Row method that returns the value of the field as the type typ, or an empty
+// string if there is none.
+func rowMapperMethod(typ reflect.Type, field Field) string {
+	if col, ok := field.(genericColumn); ok {
+		if col.columnType() == typ {
+			return "sq.ColValue(row, %)"
+		}
+		return ""
+	}
+	if _, ok := field.(BitField); ok {
+		switch typ {
+		case boolSliceType:
+			return "row.BitsField(%)"
+		case reflect.TypeOf(uint64(0)):
+			return "row.Uint64BitsField(%)"
+		}
+		return ""
+	}
+	_, isBinary := field.(Binary)
+	_, isBoolean := field.(Boolean)
+	_, isNumber := field.(Number)
+	_, isString := field.(String)
+	_, isTime := field.(Time)
+	switch {
+	case typ == byteSliceType && isBinary:
+		return "row.BytesField(%)"
+	case typ == timeType && isTime:
+		return "row.TimeField(%)"
+	case typ == nullTimeType && isTime:
+		return "row.NullTimeField(%)"
+	case typ == nullBoolType && isBoolean:
+		return "row.NullBoolField(%)"
+	case typ == nullFloat64Type && isNumber:
+		return "row.NullFloat64Field(%)"
+	case typ == nullInt64Type && isNumber:
+		return "row.NullInt64Field(%)"
+	case typ == nullStringType && isString:
+		return "row.NullStringField(%)"
+	}
+	// Named types like type Status string are scanned instead, since the
+	// Row methods return the underlying type.
+	if typ.PkgPath() != "" {
+		return ""
+	}
+	switch {
+	case typ.Kind() == reflect.Bool && isBoolean:
+		return "row.BoolField(%)"
+	case typ.Kind() == reflect.Float64 && isNumber:
+		return "row.Float64Field(%)"
+	case typ.Kind() == reflect.Int && isNumber:
+		return "row.IntField(%)"
+	case typ.Kind() == reflect.Int64 && isNumber:
+		return "row.Int64Field(%)"
+	case typ.Kind() == reflect.String && isString:
+		return "row.StringField(%)"
+	}
+	return ""
+}
+
+// rowMapperScanMethod returns the Row method that scans the field into a
+// pointer to the type typ.
+func rowMapperScanMethod(typ reflect.Type, field Field) string {
+	switch field.(type) {
+	case ArrayField:
+		return "row.ArrayField"
+	case JSONField:
+		return "row.JSONField"
+	case UUIDField:
+		return "row.UUIDField"
+	case EnumField:
+		if reflect.PointerTo(typ).Implements(reflect.TypeOf((*Enumeration)(nil)).Elem()) {
+			return "row.EnumField"
+		}
+	}
+	return "row.ScanField"
+}
+
+// withArticle prefixes a name with "a" or "an".
+func withArticle(name string) string {
+	if strings.ContainsAny(name[:1], "AEIOUaeiou") {
+		return "an " + name
+	}
+	return "a " + name
+}
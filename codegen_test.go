@@ -0,0 +1,152 @@
+package sq
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestGenerateCode(t *testing.T) {
+	t.Run("basic", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := GenerateCode(&buf, "queries", []CatalogEntry{{
+			Name: "ActorByID",
+			Query: Select(ACTOR.FIRST_NAME).
+				From(ACTOR).
+				Where(
+					ACTOR.ACTOR_ID.Eq(IntParam("actor_id", 0)),
+					ACTOR.LAST_UPDATE.Gt(TimeParam("last_update", time.Unix(1, 0).UTC())),
+					ACTOR.LAST_NAME.NeString("`"),
+				),
+		}}, []string{DialectPostgres, DialectSQLite})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		wantCode := "// Code generated by sqgen. DO NOT EDIT.\n" +
+			"\n" +
+			"package queries\n" +
+			"\n" +
+			"import (\n" +
+			"\t\"database/sql\"\n" +
+			"\t\"time\"\n" +
+			"\n" +
+			"\t\"github.com/bokwoon95/sq\"\n" +
+			")\n" +
+			"\n" +
+			"// ActorByIDPostgres is the postgres query string of ActorByID.\n" +
+			"const ActorByIDPostgres = `SELECT actor.first_name FROM actor WHERE actor.actor_id = $1 AND actor.last_update > $2 AND actor.last_name <> $3`\n" +
+			"\n" +
+			"// ActorByIDPostgresCompiled is the postgres CompiledExec of ActorByID.\n" +
+			"var ActorByIDPostgresCompiled = sq.NewCompiledExec(\"postgres\", ActorByIDPostgres, []any{0, time.Unix(1, 0).UTC(), \"`\"}, map[string][]int{\"actor_id\": {0}, \"last_update\": {1}})\n" +
+			"\n" +
+			"// ActorByIDSQLite is the sqlite query string of ActorByID.\n" +
+			"const ActorByIDSQLite = `SELECT actor.first_name FROM actor WHERE actor.actor_id = $actor_id AND actor.last_update > $last_update AND actor.last_name <> $3`\n" +
+			"\n" +
+			"// ActorByIDSQLiteCompiled is the sqlite CompiledExec of ActorByID.\n" +
+			"var ActorByIDSQLiteCompiled = sq.NewCompiledExec(\"sqlite\", ActorByIDSQLite, []any{sql.Named(\"actor_id\", 0), sql.Named(\"last_update\", time.Unix(1, 0).UTC()), \"`\"}, map[string][]int{\"actor_id\": {0}, \"last_update\": {1}})\n" +
+			"\n" +
+			"// ActorByIDParams are the params of ActorByID.\n" +
+			"type ActorByIDParams struct {\n" +
+			"\tActorID    int       `sq:\"actor_id\"`\n" +
+			"\tLastUpdate time.Time `sq:\"last_update\"`\n" +
+			"}\n" +
+			"\n" +
+			"// Params returns the params as an sq.Params.\n" +
+			"func (p ActorByIDParams) Params() sq.Params {\n" +
+			"\treturn sq.Params{\n" +
+			"\t\t\"actor_id\":    p.ActorID,\n" +
+			"\t\t\"last_update\": p.LastUpdate,\n" +
+			"\t}\n" +
+			"}\n"
+		if diff := testutil.Diff(buf.String(), wantCode); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("unknown dialect", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := GenerateCode(&buf, "queries", nil, []string{"oracle"})
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+	})
+
+	t.Run("unexported name", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := GenerateCode(&buf, "queries", []CatalogEntry{{Name: "actorByID", Query: Queryf("SELECT 1")}}, []string{DialectPostgres})
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+	})
+
+	t.Run("goFieldName", func(t *testing.T) {
+		for paramName, wantFieldName := range map[string]string{
+			"actor_id":   "ActorID",
+			"firstName":  "FirstName",
+			"2fa_code":   "P2faCode",
+			"json_value": "JSONValue",
+		} {
+			if diff := testutil.Diff(goFieldName(paramName), wantFieldName); diff != "" {
+				t.Error(testutil.Callers(), paramName, diff)
+			}
+		}
+	})
+}
+
+func TestGenerateRowMapper(t *testing.T) {
+	type Status string
+	type FILM struct {
+		TableStruct
+		FILM_ID     Col[int64]
+		TITLE       StringField
+		DESCRIPTION StringField
+		RATING      StringField `sq:"rating"`
+		LENGTH      NumberField
+		SPECIAL     JSONField
+		LAST_UPDATE TimeField
+	}
+	type Film struct {
+		FilmID      int64
+		Title       string
+		Description sql.NullString
+		Status      Status `sq:"rating"`
+		Length      int32
+		Special     []string
+		LastUpdate  time.Time
+		Actors      []string
+	}
+	var buf bytes.Buffer
+	err := GenerateRowMapper[Film](&buf, New[FILM](""))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	wantCode := "// FilmRowMapper returns a rowmapper that maps the fields of a FILM into a Film.\n" +
+		"func FilmRowMapper(f FILM) func(*sq.Row) Film {\n" +
+		"\treturn func(row *sq.Row) Film {\n" +
+		"\t\tfilm := Film{\n" +
+		"\t\t\tFilmID:      sq.ColValue(row, f.FILM_ID),\n" +
+		"\t\t\tTitle:       row.StringField(f.TITLE),\n" +
+		"\t\t\tDescription: row.NullStringField(f.DESCRIPTION),\n" +
+		"\t\t\tLastUpdate:  row.TimeField(f.LAST_UPDATE),\n" +
+		"\t\t\t// Actors: no matching field in FILM\n" +
+		"\t\t}\n" +
+		"\t\trow.ScanField(&film.Status, f.RATING)\n" +
+		"\t\trow.ScanField(&film.Length, f.LENGTH)\n" +
+		"\t\trow.JSONField(&film.Special, f.SPECIAL)\n" +
+		"\t\treturn film\n" +
+		"\t}\n" +
+		"}\n"
+	if diff := testutil.Diff(buf.String(), wantCode); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	t.Run("not a struct", func(t *testing.T) {
+		err := GenerateRowMapper[int](&buf, New[FILM](""))
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// Col is a field whose values have the Go type T. Unlike the other field
+// types its Eq, In and Set methods only accept values of type T, so comparing
+// a string column with an int (or assigning an int to it) is caught at compile
+// time instead of by the database. Col can be used in a table struct like any
+// other field type and is read with ColValue.
+//
+//	type ACTOR struct {
+//	    sq.TableStruct
+//	    ACTOR_ID   sq.Col[int64]
+//	    FIRST_NAME sq.Col[string]
+//	}
+//
+//	a := sq.New[ACTOR]("a")
+//	a.ACTOR_ID.Eq(1)        // ok
+//	a.ACTOR_ID.Eq("1")      // does not compile
+//	a.FIRST_NAME.Set("bob") // ok
+//
+// To compare a Col with something other than a value of type T (e.g. an
+// expression or a named parameter), use the generic sq.Eq, sq.In etc.
+type Col[T any] struct {
+	table      TableStruct
+	name       string
+	alias      string
+	desc       sql.NullBool
+	nullsfirst sql.NullBool
+}
+
+var _ interface {
+	Field
+	Any
+	WithPrefix(string) Field
+} = (*Col[int])(nil)
+
+// NewCol returns a new Col.
+func NewCol[T any](name string, tbl TableStruct) Col[T] {
+	return Col[T]{table: tbl, name: name}
+}
+
+// genericColumn is implemented by Col[T] for every T, so that table structs
+// containing them can be populated (and their fields listed) without knowing
+// T.
+type genericColumn interface {
+	Field
+	newColumn(name string, tbl TableStruct) Field
+	columnName() string
+	columnType() reflect.Type
+}
+
+func (col Col[T]) newColumn(name string, tbl TableStruct) Field { return NewCol[T](name, tbl) }
+
+func (col Col[T]) columnName() string { return col.name }
+
+func (col Col[T]) columnType() reflect.Type { return reflect.TypeOf((*T)(nil)).Elem() }
+
+// WriteSQL implements the SQLWriter interface.
+func (col Col[T]) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	err := writeFieldIdentifier(ctx, dialect, buf, args, params, col.table, col.name)
+	if err != nil {
+		return err
+	}
+	writeFieldOrder(ctx, dialect, buf, args, params, col.desc, col.nullsfirst)
+	return nil
+}
+
+// As returns a new Col with the given alias.
+func (col Col[T]) As(alias string) Col[T] {
+	col.alias = alias
+	return col
+}
+
+// Asc returns a new Col indicating that it should be ordered in ascending
+// order i.e. 'ORDER BY field ASC'.
+func (col Col[T]) Asc() Col[T] {
+	col.desc.Valid = true
+	col.desc.Bool = false
+	return col
+}
+
+// Desc returns a new Col indicating that it should be ordered in descending
+// order i.e. 'ORDER BY field DESC'.
+func (col Col[T]) Desc() Col[T] {
+	col.desc.Valid = true
+	col.desc.Bool = true
+	return col
+}
+
+// NullsLast returns a new Col indicating that it should be ordered with nulls
+// last i.e. 'ORDER BY field NULLS LAST'.
+func (col Col[T]) NullsLast() Col[T] {
+	col.nullsfirst.Valid = true
+	col.nullsfirst.Bool = false
+	return col
+}
+
+// NullsFirst returns a new Col indicating that it should be ordered with
+// nulls first i.e. 'ORDER BY field NULLS FIRST'.
+func (col Col[T]) NullsFirst() Col[T] {
+	col.nullsfirst.Valid = true
+	col.nullsfirst.Bool = true
+	return col
+}
+
+// WithPrefix returns a new Field that with the given prefix.
+func (col Col[T]) WithPrefix(prefix string) Field {
+	col.table.alias = ""
+	col.table.name = prefix
+	return col
+}
+
+// IsNull returns a 'field IS NULL' Predicate.
+func (col Col[T]) IsNull() Predicate { return Expr("{} IS NULL", col) }
+
+// IsNotNull returns a 'field IS NOT NULL' Predicate.
+func (col Col[T]) IsNotNull() Predicate { return Expr("{} IS NOT NULL", col) }
+
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (col Col[T]) CastTo(typ string) CastExpression { return Cast(col, typ) }
+
+// In returns a 'field IN (x, y, z)' Predicate.
+func (col Col[T]) In(values []T) Predicate { return In(col, values) }
+
+// NotIn returns a 'field NOT IN (x, y, z)' Predicate.
+func (col Col[T]) NotIn(values []T) Predicate { return NotIn(col, values) }
+
+// Eq returns a 'field = value' Predicate.
+func (col Col[T]) Eq(value T) Predicate { return Eq(col, value) }
+
+// Ne returns a 'field <> value' Predicate.
+func (col Col[T]) Ne(value T) Predicate { return Ne(col, value) }
+
+// Lt returns a 'field < value' Predicate.
+func (col Col[T]) Lt(value T) Predicate { return Lt(col, value) }
+
+// Le returns a 'field <= value' Predicate.
+func (col Col[T]) Le(value T) Predicate { return Le(col, value) }
+
+// Gt returns a 'field > value' Predicate.
+func (col Col[T]) Gt(value T) Predicate { return Gt(col, value) }
+
+// Ge returns a 'field >= value' Predicate.
+func (col Col[T]) Ge(value T) Predicate { return Ge(col, value) }
+
+// EqCol returns a 'field = other' Predicate, where other is a Col of the same
+// type e.g. a foreign key in a JOIN condition.
+func (col Col[T]) EqCol(other Col[T]) Predicate { return Eq(col, other) }
+
+// Set returns an Assignment assigning the value to the field.
+func (col Col[T]) Set(value T) Assignment {
+	return Set(col, value)
+}
+
+// Setf returns an Assignment assigning an expression to the field.
+func (col Col[T]) Setf(format string, values ...any) Assignment {
+	return Setf(col, format, values...)
+}
+
+// GetAlias returns the alias of the Col.
+func (col Col[T]) GetAlias() string { return col.alias }
+
+// IsField implements the Field interface.
+func (col Col[T]) IsField() {}
+
+// IsArray implements the Array interface.
+func (col Col[T]) IsArray() {}
+
+// IsBinary implements the Binary interface.
+func (col Col[T]) IsBinary() {}
+
+// IsBoolean implements the Boolean interface.
+func (col Col[T]) IsBoolean() {}
+
+// IsEnum implements the Enum interface.
+func (col Col[T]) IsEnum() {}
+
+// IsJSON implements the JSON interface.
+func (col Col[T]) IsJSON() {}
+
+// IsNumber implements the Number interface.
+func (col Col[T]) IsNumber() {}
+
+// IsString implements the String interface.
+func (col Col[T]) IsString() {}
+
+// IsTime implements the Time interface.
+func (col Col[T]) IsTime() {}
+
+// IsUUID implements the UUID interface.
+func (col Col[T]) IsUUID() {}
+
+// ColValue returns the value of a Col in the row. If T is a pointer (e.g.
+// *string), it is nil if the value is NULL.
+//
+//	actors, err := sq.FetchAll(db, sq.From(a), func(row *sq.Row) Actor {
+//	    return Actor{
+//	        ActorID:   sq.ColValue(row, a.ACTOR_ID),
+//	        FirstName: sq.ColValue(row, a.FIRST_NAME),
+//	    }
+//	})
+func ColValue[T any](row *Row, col Col[T]) T {
+	var value T
+	if row.queryIsStatic {
+		if scanner, ok := any(&value).(sql.Scanner); ok {
+			row.staticScan(scanner, staticColumnName(row.dialect, col), 1)
+			return value
+		}
+		panic(fmt.Errorf(callsite(1)+"cannot call ColValue for static queries (unless *%T implements sql.Scanner)", value))
+	}
+	row.scan(&value, col, 1)
+	return value
+}
@@ -0,0 +1,99 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+type COL_ACTOR struct {
+	TableStruct `sq:"actor"`
+	ACTOR_ID    Col[int64]
+	FIRST_NAME  Col[string]
+	LAST_NAME   Col[*string] `sq:"last_name"`
+}
+
+func TestCol(t *testing.T) {
+	a := New[COL_ACTOR]("a")
+	tests := []TestTable{{
+		description: "Eq In",
+		item:        Select(a.FIRST_NAME).From(a).Where(a.ACTOR_ID.Eq(1), a.FIRST_NAME.In([]string{"PENELOPE", "NICK"})),
+		wantQuery:   "SELECT a.first_name FROM actor AS a WHERE a.actor_id = ? AND a.first_name IN (?, ?)",
+		wantArgs:    []any{int64(1), "PENELOPE", "NICK"},
+	}, {
+		description: "comparisons",
+		dialect:     DialectPostgres,
+		item:        Select(a.ACTOR_ID.As("id")).From(a).Where(a.ACTOR_ID.Gt(1), a.ACTOR_ID.Le(10), a.FIRST_NAME.Ne("ED")).OrderBy(a.ACTOR_ID.Desc()),
+		wantQuery:   "SELECT a.actor_id AS id FROM actor AS a WHERE a.actor_id > $1 AND a.actor_id <= $2 AND a.first_name <> $3 ORDER BY a.actor_id DESC",
+		wantArgs:    []any{int64(1), int64(10), "ED"},
+	}, {
+		description: "Set",
+		dialect:     DialectPostgres,
+		item:        Update(a).Set(a.FIRST_NAME.Set("BOB"), a.LAST_NAME.Set(nil)).Where(a.ACTOR_ID.Eq(1)),
+		wantQuery:   "UPDATE actor AS a SET first_name = $1, last_name = $2 WHERE a.actor_id = $3",
+		wantArgs:    []any{"BOB", (*string)(nil), int64(1)},
+	}, {
+		description: "EqCol",
+		dialect:     DialectPostgres,
+		item:        Select(a.ACTOR_ID).From(a).Join(New[COL_ACTOR]("b"), a.ACTOR_ID.EqCol(New[COL_ACTOR]("b").ACTOR_ID)),
+		wantQuery:   "SELECT a.actor_id FROM actor AS a JOIN actor AS b ON a.actor_id = b.actor_id",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	t.Run("StarOf", func(t *testing.T) {
+		gotQuery, _, err := ToSQL("", Select(StarOf(a)...).From(a), nil)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(gotQuery, "SELECT a.actor_id, a.first_name, a.last_name FROM actor AS a"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("ColValue", func(t *testing.T) {
+		type Actor struct {
+			ActorID   int64
+			FirstName string
+			LastName  *string
+		}
+		db := newDB(t)
+		_, err := db.Exec("DROP TABLE actor; CREATE TABLE actor (actor_id INT PRIMARY KEY, first_name TEXT NOT NULL, last_name TEXT)")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = Exec(db, SQLite.
+			InsertInto(a).
+			Columns(a.ACTOR_ID, a.FIRST_NAME, a.LAST_NAME).
+			Values(1, "PENELOPE", "GUINESS").
+			Values(2, "NICK", nil),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		actors, err := FetchAll(db, SQLite.From(a).OrderBy(a.ACTOR_ID), func(row *Row) Actor {
+			return Actor{
+				ActorID:   ColValue(row, a.ACTOR_ID),
+				FirstName: ColValue(row, a.FIRST_NAME),
+				LastName:  ColValue(row, a.LAST_NAME),
+			}
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		lastName := "GUINESS"
+		wantActors := []Actor{
+			{ActorID: 1, FirstName: "PENELOPE", LastName: &lastName},
+			{ActorID: 2, FirstName: "NICK"},
+		}
+		if diff := testutil.Diff(actors, wantActors); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CommentQuery represents an SQL statement that sets the comment of a table
+// or column.
+//
+//	postgres: COMMENT ON TABLE table IS 'comment'
+//	postgres: COMMENT ON COLUMN table.column IS 'comment'
+//	mysql:    ALTER TABLE table COMMENT = 'comment'
+//	mysql:    ALTER TABLE table MODIFY COLUMN column <definition> COMMENT 'comment'
+//
+// An empty comment removes the comment. MySQL can only change a column's
+// comment by redefining the column, so the column definition (e.g.
+// "VARCHAR(45) NOT NULL") must be provided with ColumnDefinition. SQLite and
+// SQL Server are not supported.
+type CommentQuery struct {
+	Dialect string
+	Table   Table
+	// Field is nil for a table comment.
+	Field            Field
+	Comment          string
+	ColumnDefinition string
+}
+
+var _ Query = (*CommentQuery)(nil)
+
+// CommentOnTable returns a CommentQuery that sets the comment of a table.
+func CommentOnTable(table Table, comment string) CommentQuery {
+	return CommentQuery{Table: table, Comment: comment}
+}
+
+// CommentOnColumn returns a CommentQuery that sets the comment of a column.
+//
+//	a := sq.New[ACTOR]("")
+//	q := sq.CommentOnColumn(a, a.LAST_UPDATE, "When the actor was last updated.")
+func CommentOnColumn(table Table, field Field, comment string) CommentQuery {
+	return CommentQuery{Table: table, Field: field, Comment: comment}
+}
+
+// WithColumnDefinition sets the column definition of the query, which is
+// required for setting the comment of a column in mysql.
+func (q CommentQuery) WithColumnDefinition(definition string) CommentQuery {
+	q.ColumnDefinition = definition
+	return q
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (q CommentQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if dialect != DialectPostgres && dialect != DialectMySQL {
+		return fmt.Errorf("Comment: dialect %q not supported, only postgres and mysql are supported", dialect)
+	}
+	if q.Table == nil {
+		return fmt.Errorf("Comment: table is nil")
+	}
+	if dialect == DialectMySQL && q.Field != nil && q.ColumnDefinition == "" {
+		return fmt.Errorf("Comment: mysql requires the column definition to set a column comment")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var err error
+	switch dialect {
+	case DialectPostgres:
+		if q.Field == nil {
+			buf.WriteString("COMMENT ON TABLE ")
+		} else {
+			buf.WriteString("COMMENT ON COLUMN ")
+		}
+		err = q.Table.WriteSQL(ctx, dialect, buf, args, params)
+		if err != nil {
+			return fmt.Errorf("Comment: table: %w", err)
+		}
+		if q.Field != nil {
+			buf.WriteString(".")
+			err = q.Field.WriteSQL(context.WithValue(ctx, unqualifiedFieldsKey{}, true), dialect, buf, args, params)
+			if err != nil {
+				return fmt.Errorf("Comment: field: %w", err)
+			}
+		}
+		buf.WriteString(" IS ")
+		if q.Comment == "" {
+			buf.WriteString("NULL")
+		} else {
+			buf.WriteString(commentLiteral(dialect, q.Comment))
+		}
+	case DialectMySQL:
+		buf.WriteString("ALTER TABLE ")
+		err = q.Table.WriteSQL(ctx, dialect, buf, args, params)
+		if err != nil {
+			return fmt.Errorf("Comment: table: %w", err)
+		}
+		if q.Field == nil {
+			buf.WriteString(" COMMENT = " + commentLiteral(dialect, q.Comment))
+			return nil
+		}
+		buf.WriteString(" MODIFY COLUMN ")
+		err = q.Field.WriteSQL(context.WithValue(ctx, unqualifiedFieldsKey{}, true), dialect, buf, args, params)
+		if err != nil {
+			return fmt.Errorf("Comment: field: %w", err)
+		}
+		buf.WriteString(" " + q.ColumnDefinition + " COMMENT " + commentLiteral(dialect, q.Comment))
+	}
+	return nil
+}
+
+// commentLiteral returns the string literal of a comment. Unlike Sprint,
+// newlines are kept as-is because comments must be plain string literals.
+func commentLiteral(dialect string, comment string) string {
+	comment = strings.ReplaceAll(comment, "'", "''")
+	if dialect == DialectMySQL {
+		comment = strings.ReplaceAll(comment, `\`, `\\`)
+	}
+	return "'" + comment + "'"
+}
+
+// SetFetchableFields implements the Query interface. It always returns false
+// as the second result.
+func (q CommentQuery) SetFetchableFields([]Field) (query Query, ok bool) {
+	return q, false
+}
+
+// GetDialect implements the Query interface.
+func (q CommentQuery) GetDialect() string { return q.Dialect }
+
+// SetDialect sets the dialect of the query.
+func (q CommentQuery) SetDialect(dialect string) CommentQuery {
+	q.Dialect = dialect
+	return q
+}
@@ -0,0 +1,58 @@
+package sq
+
+import "testing"
+
+func TestCommentQuery(t *testing.T) {
+	tests := []TestTable{{
+		description: "postgres table",
+		dialect:     DialectPostgres,
+		item:        CommentOnTable(ACTOR, "The actors' names."),
+		wantQuery:   "COMMENT ON TABLE actor IS 'The actors'' names.'",
+	}, {
+		description: "postgres column",
+		dialect:     DialectPostgres,
+		item:        CommentOnColumn(ACTOR, ACTOR.LAST_UPDATE, "When the actor\nwas last updated."),
+		wantQuery:   "COMMENT ON COLUMN actor.last_update IS 'When the actor\nwas last updated.'",
+	}, {
+		description: "postgres remove comment",
+		dialect:     DialectPostgres,
+		item:        CommentOnTable(ACTOR, ""),
+		wantQuery:   "COMMENT ON TABLE actor IS NULL",
+	}, {
+		description: "mysql table",
+		dialect:     DialectMySQL,
+		item:        CommentOnTable(ACTOR, `C:\actors`),
+		wantQuery:   `ALTER TABLE actor COMMENT = 'C:\\actors'`,
+	}, {
+		description: "mysql column",
+		dialect:     DialectMySQL,
+		item:        CommentOnColumn(ACTOR, ACTOR.FIRST_NAME, "The actor's first name.").WithColumnDefinition("VARCHAR(45) NOT NULL"),
+		wantQuery:   "ALTER TABLE actor MODIFY COLUMN first_name VARCHAR(45) NOT NULL COMMENT 'The actor''s first name.'",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "sqlite",
+		dialect:     DialectSQLite,
+		item:        CommentOnTable(ACTOR, "The actors."),
+	}, {
+		description: "mysql column without definition",
+		dialect:     DialectMySQL,
+		item:        CommentOnColumn(ACTOR, ACTOR.FIRST_NAME, "The actor's first name."),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+}
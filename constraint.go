@@ -0,0 +1,198 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Constraint is a table constraint declared in the struct tags of a table
+// struct. Foreign keys are declared with references (and optionally onDelete
+// and onUpdate), check constraints are declared with check:
+//
+//	type FILM_ACTOR struct {
+//	    sq.TableStruct
+//	    FILM_ID  sq.NumberField `sq:"film_id,references=film.film_id,onDelete=cascade"`
+//	    ACTOR_ID sq.NumberField `sq:"actor_id,references=actor.actor_id,onDelete=cascade,onUpdate=cascade"`
+//	    RATING   sq.NumberField `sq:"rating,check=rating BETWEEN 1 AND 5"`
+//	}
+//
+// Since a check expression may contain commas, check must be the last option
+// in the struct tag.
+type Constraint struct {
+	// Name is the name of the constraint, <table>_<column>_fkey for foreign
+	// keys and <table>_<column>_check for check constraints.
+	Name string
+	// ConstraintType is either "FOREIGN KEY" or "CHECK".
+	ConstraintType string
+	Columns        []string
+	// ReferencesTable is the referenced table of a foreign key, optionally
+	// qualified with a schema.
+	ReferencesTable   string
+	ReferencesColumns []string
+	// OnDelete and OnUpdate are the referential actions of a foreign key
+	// e.g. CASCADE, SET NULL.
+	OnDelete string
+	OnUpdate string
+	// CheckExpr is the (raw SQL) expression of a check constraint.
+	CheckExpr string
+}
+
+// TableConstraints returns the constraints declared in the struct tags of a
+// table struct, in field order.
+func TableConstraints(table Table) ([]Constraint, error) {
+	tableStruct, _, err := tableColumns(table)
+	if err != nil {
+		return nil, err
+	}
+	value := reflect.Indirect(reflect.ValueOf(table))
+	if value.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	typ := value.Type()
+	var constraints []Constraint
+	for i := 1; i < typ.NumField(); i++ {
+		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+		switch value.Field(i).Interface().(type) {
+		case AnyField, ArrayField, BinaryField, BitField, BooleanField, EnumField, JSONField, NumberField, StringField, TimeField, UUIDField, XMLField, genericColumn:
+		default:
+			continue
+		}
+		name, options, _ := strings.Cut(fieldType.Tag.Get("sq"), ",")
+		if name == "" {
+			name = translateName(fieldType.Name)
+		}
+		opts, err := parseColumnOptions(options)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", typ.Name(), fieldType.Name, err)
+		}
+		if opts.references != "" {
+			i := strings.LastIndexByte(opts.references, '.')
+			constraints = append(constraints, Constraint{
+				Name:              tableStruct.name + "_" + name + "_fkey",
+				ConstraintType:    "FOREIGN KEY",
+				Columns:           []string{name},
+				ReferencesTable:   opts.references[:i],
+				ReferencesColumns: []string{opts.references[i+1:]},
+				OnDelete:          opts.onDelete,
+				OnUpdate:          opts.onUpdate,
+			})
+		}
+		if opts.check != "" {
+			constraints = append(constraints, Constraint{
+				Name:           tableStruct.name + "_" + name + "_check",
+				ConstraintType: "CHECK",
+				Columns:        []string{name},
+				CheckExpr:      opts.check,
+			})
+		}
+	}
+	return constraints, nil
+}
+
+// AddConstraintQuery represents an SQL ALTER TABLE ... ADD CONSTRAINT
+// statement. SQLite does not support adding constraints to an existing table.
+//
+//	ALTER TABLE table ADD CONSTRAINT name FOREIGN KEY (columns) REFERENCES table (columns) [ON DELETE action] [ON UPDATE action]
+//	ALTER TABLE table ADD CONSTRAINT name CHECK (expr)
+type AddConstraintQuery struct {
+	Dialect    string
+	Table      Table
+	Constraint Constraint
+}
+
+var _ Query = (*AddConstraintQuery)(nil)
+
+// AddConstraint returns a new AddConstraintQuery.
+//
+//	constraints, err := sq.TableConstraints(fa)
+//	for _, constraint := range constraints {
+//	    _, err := sq.Exec(db, sq.AddConstraint(fa, constraint).SetDialect(sq.DialectPostgres))
+//	}
+func AddConstraint(table Table, constraint Constraint) AddConstraintQuery {
+	return AddConstraintQuery{Table: table, Constraint: constraint}
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (q AddConstraintQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if dialect == DialectSQLite {
+		return fmt.Errorf("AddConstraint: sqlite does not support ALTER TABLE ... ADD CONSTRAINT")
+	}
+	if q.Table == nil {
+		return fmt.Errorf("AddConstraint: table is nil")
+	}
+	c := q.Constraint
+	if c.Name == "" {
+		return fmt.Errorf("AddConstraint: constraint has no name")
+	}
+	buf.WriteString("ALTER TABLE ")
+	err := q.Table.WriteSQL(ctx, dialect, buf, args, params)
+	if err != nil {
+		return fmt.Errorf("AddConstraint: table: %w", err)
+	}
+	buf.WriteString(" ADD CONSTRAINT " + QuoteIdentifier(dialect, c.Name) + " ")
+	switch c.ConstraintType {
+	case "FOREIGN KEY":
+		if len(c.Columns) == 0 || len(c.Columns) != len(c.ReferencesColumns) {
+			return fmt.Errorf("AddConstraint: %s: got %d columns but %d referenced columns", c.Name, len(c.Columns), len(c.ReferencesColumns))
+		}
+		if c.ReferencesTable == "" {
+			return fmt.Errorf("AddConstraint: %s: no referenced table", c.Name)
+		}
+		buf.WriteString("FOREIGN KEY (")
+		writeQuotedIdentifiers(dialect, buf, c.Columns)
+		buf.WriteString(") REFERENCES ")
+		for i, part := range strings.Split(c.ReferencesTable, ".") {
+			if i > 0 {
+				buf.WriteString(".")
+			}
+			buf.WriteString(QuoteIdentifier(dialect, part))
+		}
+		buf.WriteString(" (")
+		writeQuotedIdentifiers(dialect, buf, c.ReferencesColumns)
+		buf.WriteString(")")
+		if c.OnDelete != "" {
+			buf.WriteString(" ON DELETE " + c.OnDelete)
+		}
+		if c.OnUpdate != "" {
+			buf.WriteString(" ON UPDATE " + c.OnUpdate)
+		}
+	case "CHECK":
+		if c.CheckExpr == "" {
+			return fmt.Errorf("AddConstraint: %s: empty check expression", c.Name)
+		}
+		buf.WriteString("CHECK (" + c.CheckExpr + ")")
+	default:
+		return fmt.Errorf("AddConstraint: %s: unsupported constraint type %q", c.Name, c.ConstraintType)
+	}
+	return nil
+}
+
+func writeQuotedIdentifiers(dialect string, buf *bytes.Buffer, identifiers []string) {
+	for i, identifier := range identifiers {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(QuoteIdentifier(dialect, identifier))
+	}
+}
+
+// SetFetchableFields implements the Query interface. It always returns false
+// as the second result.
+func (q AddConstraintQuery) SetFetchableFields([]Field) (query Query, ok bool) {
+	return q, false
+}
+
+// GetDialect implements the Query interface.
+func (q AddConstraintQuery) GetDialect() string { return q.Dialect }
+
+// SetDialect sets the dialect of the query.
+func (q AddConstraintQuery) SetDialect(dialect string) AddConstraintQuery {
+	q.Dialect = dialect
+	return q
+}
@@ -0,0 +1,84 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+type FILM_ACTOR struct {
+	TableStruct
+	FILM_ID  NumberField `sq:"film_id,references=public.film.film_id,onDelete=cascade,onUpdate=set null"`
+	ACTOR_ID NumberField `sq:"actor_id,references=actor.actor_id"`
+	RATING   NumberField `sq:"rating,check=rating BETWEEN 1 AND 5 OR rating IN (10, 20)"`
+}
+
+func TestTableConstraints(t *testing.T) {
+	fa := New[FILM_ACTOR]("")
+	constraints, err := TableConstraints(fa)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	wantConstraints := []Constraint{{
+		Name:              "film_actor_film_id_fkey",
+		ConstraintType:    "FOREIGN KEY",
+		Columns:           []string{"film_id"},
+		ReferencesTable:   "public.film",
+		ReferencesColumns: []string{"film_id"},
+		OnDelete:          "CASCADE",
+		OnUpdate:          "SET NULL",
+	}, {
+		Name:              "film_actor_actor_id_fkey",
+		ConstraintType:    "FOREIGN KEY",
+		Columns:           []string{"actor_id"},
+		ReferencesTable:   "actor",
+		ReferencesColumns: []string{"actor_id"},
+	}, {
+		Name:           "film_actor_rating_check",
+		ConstraintType: "CHECK",
+		Columns:        []string{"rating"},
+		CheckExpr:      "rating BETWEEN 1 AND 5 OR rating IN (10, 20)",
+	}}
+	if diff := testutil.Diff(constraints, wantConstraints); diff != "" {
+		t.Fatal(testutil.Callers(), diff)
+	}
+
+	tests := []TestTable{{
+		description: "postgres foreign key",
+		dialect:     DialectPostgres,
+		item:        AddConstraint(fa, constraints[0]),
+		wantQuery: "ALTER TABLE film_actor ADD CONSTRAINT film_actor_film_id_fkey" +
+			" FOREIGN KEY (film_id) REFERENCES public.film (film_id) ON DELETE CASCADE ON UPDATE SET NULL",
+	}, {
+		description: "mysql foreign key",
+		dialect:     DialectMySQL,
+		item:        AddConstraint(fa, constraints[1]),
+		wantQuery:   "ALTER TABLE film_actor ADD CONSTRAINT film_actor_actor_id_fkey FOREIGN KEY (actor_id) REFERENCES actor (actor_id)",
+	}, {
+		description: "sqlserver check",
+		dialect:     DialectSQLServer,
+		item:        AddConstraint(fa, constraints[2]),
+		wantQuery:   "ALTER TABLE film_actor ADD CONSTRAINT film_actor_rating_check CHECK (rating BETWEEN 1 AND 5 OR rating IN (10, 20))",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	t.Run("sqlite", func(t *testing.T) {
+		t.Parallel()
+		TestTable{dialect: DialectSQLite, item: AddConstraint(fa, constraints[0])}.assertNotOK(t)
+	})
+
+	t.Run("not a table struct", func(t *testing.T) {
+		t.Parallel()
+		_, err := TableConstraints(Expr("film_actor"))
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package sq
+
+import (
+	"context"
+	"fmt"
+)
+
+// FetchCount returns the number of rows that running the given Query on the
+// given DB would return. If the query is a SelectQuery (of any dialect), its
+// ORDER BY, LIMIT, OFFSET, FETCH NEXT and TOP clauses are stripped first, so
+// that the same query used to fetch a page of results can be used to count the
+// total number of results. Unless it has DISTINCT, GROUP BY, HAVING or an
+// aggregate function in its select list, its selected fields are then
+// replaced with COUNT(*). Any other query is wrapped in
+// 'SELECT COUNT(*) FROM (query) AS count_query'.
+//
+//	// SELECT COUNT(*) FROM actor AS a WHERE a.last_name = 'DAVIS'
+//	total, err := sq.FetchCount(db, sq.
+//	    Select(a.ACTOR_ID).
+//	    From(a).
+//	    Where(a.LAST_NAME.EqString("DAVIS")).
+//	    OrderBy(a.ACTOR_ID).
+//	    Limit(10),
+//	)
+func FetchCount(db DB, query Query) (count int64, err error) {
+	return fetchCount(context.Background(), db, query, 1)
+}
+
+// FetchCountContext is like FetchCount but additionally requires a
+// context.Context.
+func FetchCountContext(ctx context.Context, db DB, query Query) (count int64, err error) {
+	return fetchCount(ctx, db, query, 1)
+}
+
+func fetchCount(ctx context.Context, db DB, query Query, skip int) (count int64, err error) {
+	if query == nil {
+		return 0, fmt.Errorf("query is nil")
+	}
+	switch q := query.(type) {
+	case SelectQuery:
+		query = countSelectQuery(q)
+	case SQLiteSelectQuery:
+		query = countSelectQuery(SelectQuery(q))
+	case PostgresSelectQuery:
+		query = countSelectQuery(SelectQuery(q))
+	case MySQLSelectQuery:
+		query = countSelectQuery(SelectQuery(q))
+	case SQLServerSelectQuery:
+		query = countSelectQuery(SelectQuery(q))
+	default:
+		query = Queryf("SELECT {*} FROM ({}) AS count_query", query).SetDialect(query.GetDialect())
+	}
+	return fetchCountQuery(ctx, db, query, skip+1)
+}
+
+// countSelectQuery returns a query that counts the rows of a SelectQuery.
+// Wrapping the SelectQuery in a derived table is avoided where possible,
+// because MySQL and SQL Server reject derived tables with duplicate column
+// names (e.g. a.actor_id and fa.actor_id of a join) and SQL Server also
+// rejects unnamed expression columns.
+func countSelectQuery(q SelectQuery) Query {
+	q = stripPagination(q)
+	if q.Distinct || len(q.DistinctOnFields) > 0 || len(q.GroupByFields) > 0 || q.HavingPredicate != nil || hasAggregate(q.SelectFields) {
+		return Queryf("SELECT {*} FROM ({}) AS count_query", q).SetDialect(q.Dialect)
+	}
+	// The empty select list is filled in with COUNT(*) by the rowmapper.
+	q.SelectFields = nil
+	q.NamedWindows = nil
+	q.LockClause, q.LockValues = "", nil
+	q.LockStrength, q.LockOfTables, q.LockNoWait, q.LockSkipLocked = "", nil, false, false
+	q.Columns = nil
+	return q
+}
+
+// stripPagination removes the clauses of a SelectQuery that don't affect the
+// number of rows matched, or that limit the number of rows returned.
+func stripPagination(q SelectQuery) SelectQuery {
+	q.OrderByFields = nil
+	q.LimitRows = nil
+	q.OffsetRows = nil
+	q.FetchNextRows = nil
+	q.FetchWithTies = false
+	q.LimitTop = nil
+	q.LimitTopPercent = nil
+	q.Alias = ""
+	return q
+}
+
+// fetchCountQuery runs a query that selects a single count.
+func fetchCountQuery(ctx context.Context, db DB, query Query, skip int) (count int64, err error) {
+	cursor, err := fetchCursor(ctx, db, query, func(row *Row) int64 {
+		return row.Int64("COUNT(*)")
+	}, skip+1)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+	return cursorResult(cursor)
+}
+
+// EstimateCount returns an approximate number of rows in the table, read
+// from the statistics the database keeps about it instead of counting every
+// row. This is much faster than FetchCount on huge tables, but the estimate
+// is only as fresh as the statistics (which are updated by ANALYZE or its
+// equivalent).
+//
+//   - Postgres: pg_class.reltuples. If the table has never been analyzed, the
+//     rows are counted instead.
+//   - MySQL: information_schema.tables.table_rows.
+//   - SQL Server: the row count of the heap or clustered index in
+//     sys.partitions.
+//   - SQLite: SQLite keeps no row count statistics, so the rows are counted
+//     instead.
+//
+// The dialect is taken from the DB (see NewDB) or DefaultDialect.
+func EstimateCount(db DB, table Table) (count int64, err error) {
+	return estimateCount(context.Background(), db, table, 1)
+}
+
+// EstimateCountContext is like EstimateCount but additionally requires a
+// context.Context.
+func EstimateCountContext(ctx context.Context, db DB, table Table) (count int64, err error) {
+	return estimateCount(ctx, db, table, 1)
+}
+
+func estimateCount(ctx context.Context, db DB, table Table, skip int) (count int64, err error) {
+	if db == nil {
+		return 0, fmt.Errorf("db is nil")
+	}
+	tableStruct, _, err := tableColumns(table)
+	if err != nil {
+		return 0, err
+	}
+	tableStruct.alias = ""
+	var schema any
+	if tableStruct.schema != "" {
+		schema = tableStruct.schema
+	}
+	dialect := dbDialect(db)
+	var estimate func(row *Row) int64
+	var query CustomQuery
+	switch dialect {
+	case DialectPostgres:
+		query = Postgres.Queryf("SELECT {*} FROM pg_class WHERE oid = CAST({} AS regclass)", toString(dialect, tableStruct))
+		estimate = func(row *Row) int64 { return row.Int64("CAST(reltuples AS BIGINT)") }
+	case DialectMySQL:
+		query = MySQL.Queryf("SELECT {*} FROM information_schema.tables WHERE table_schema = COALESCE({}, DATABASE()) AND table_name = {}", schema, tableStruct.name)
+		estimate = func(row *Row) int64 { return row.Int64("COALESCE(table_rows, 0)") }
+	case DialectSQLServer:
+		query = SQLServer.Queryf("SELECT {*} FROM sys.partitions WHERE object_id = OBJECT_ID({}) AND index_id IN (0, 1)", toString(dialect, tableStruct))
+		estimate = func(row *Row) int64 { return row.Int64("COALESCE(SUM(rows), 0)") }
+	default:
+		return fetchCountQuery(ctx, db, Queryf("SELECT {*} FROM {}", tableStruct).SetDialect(dialect), skip+1)
+	}
+	cursor, err := fetchCursor(ctx, db, query, estimate, skip+1)
+	if err != nil {
+		return 0, err
+	}
+	count, err = cursorResult(cursor)
+	cursor.Close()
+	if err != nil {
+		return 0, err
+	}
+	// Postgres 14+ reports -1 for tables that have never been analyzed.
+	if count < 0 {
+		return fetchCountQuery(ctx, db, Queryf("SELECT {*} FROM {}", tableStruct).SetDialect(dialect), skip+1)
+	}
+	return count, nil
+}
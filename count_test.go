@@ -0,0 +1,102 @@
+package sq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestFetchCount(t *testing.T) {
+	t.Parallel()
+	recorder := &queryStatsRecorder{}
+	db := NewDB(struct {
+		DB
+		SqLogger
+	}{DB: newDB(t), SqLogger: recorder}, DialectSQLite)
+	_, err := Exec(db, SQLite.InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME, ACTOR.LAST_UPDATE).
+		Values(1, "PENELOPE", "GUINESS", time.Unix(1, 0).UTC()).
+		Values(2, "NICK", "WAHLBERG", time.Unix(1, 0).UTC()).
+		Values(3, "ED", "CHASE", time.Unix(1, 0).UTC()).
+		Values(4, "JENNIFER", "DAVIS", time.Unix(1, 0).UTC()).
+		Values(5, "JOHNNY", "LOLLOBRIGIDA", time.Unix(1, 0).UTC()),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+
+	count, err := FetchCount(db, SQLite.
+		Select(ACTOR.ACTOR_ID).
+		From(ACTOR).
+		Where(ACTOR.ACTOR_ID.GtInt(1)).
+		OrderBy(ACTOR.ACTOR_ID).
+		Limit(2).
+		Offset(1),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(count, int64(4)); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	wantQuery := "SELECT COUNT(*) FROM actor WHERE actor.actor_id > $1"
+	if diff := testutil.Diff(recorder.queryStats[len(recorder.queryStats)-1].Query, wantQuery); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	// Columns with the same name are not wrapped in a derived table.
+	type COUNT_ACTOR struct {
+		TableStruct `sq:"actor"`
+		ACTOR_ID    NumberField
+	}
+	a, b := New[COUNT_ACTOR]("a"), New[COUNT_ACTOR]("b")
+	count, err = FetchCount(db, SQLite.
+		Select(a.ACTOR_ID, b.ACTOR_ID).
+		From(a).
+		Join(b, b.ACTOR_ID.Eq(a.ACTOR_ID)).
+		Where(a.ACTOR_ID.GtInt(1)),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(count, int64(4)); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	wantQuery = "SELECT COUNT(*) FROM actor AS a JOIN actor AS b ON b.actor_id = a.actor_id WHERE a.actor_id > $1"
+	if diff := testutil.Diff(recorder.queryStats[len(recorder.queryStats)-1].Query, wantQuery); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	count, err = FetchCount(db, SQLite.
+		SelectDistinct(ACTOR.LAST_UPDATE).
+		From(ACTOR).
+		OrderBy(ACTOR.LAST_UPDATE),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(count, int64(1)); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	wantQuery = "SELECT COUNT(*) FROM (SELECT DISTINCT actor.last_update FROM actor) AS count_query"
+	if diff := testutil.Diff(recorder.queryStats[len(recorder.queryStats)-1].Query, wantQuery); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	count, err = FetchCount(db, SQLite.Queryf("SELECT * FROM actor WHERE last_name LIKE {}", "%A%"))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(count, int64(4)); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	count, err = EstimateCount(db, ACTOR)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(count, int64(5)); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
@@ -31,6 +31,9 @@ func NewRecursiveCTE(name string, columns []string, query Query) CTE {
 
 // WriteSQL implements the SQLWriter interface.
 func (cte CTE) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if err := checkIdentifierLength(dialect, cte.name); err != nil {
+		return err
+	}
 	buf.WriteString(QuoteIdentifier(dialect, cte.name))
 	return nil
 }
@@ -88,6 +91,9 @@ func writeCTEs(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]a
 		if cte.name == "" {
 			return fmt.Errorf("CTE #%d has no name", i+1)
 		}
+		if err := checkIdentifierLength(dialect, cte.name); err != nil {
+			return fmt.Errorf("CTE #%d: %w", i+1, err)
+		}
 		buf.WriteString(QuoteIdentifier(dialect, cte.name))
 		if len(cte.columns) > 0 {
 			buf.WriteString(" (")
@@ -183,6 +189,12 @@ func ExceptAll(queries ...Query) VariadicQuery {
 
 // WriteSQL implements the SQLWriter interface.
 func (q VariadicQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	ctx = withWriteState(ctx)
+	// The queries being combined are not the outermost query, so they must
+	// not be given a default ORDER BY (see DefaultOrderTable).
+	state := getWriteState(ctx)
+	state.pushScope(nil)
+	defer state.popScope()
 	var err error
 	if q.Operator == "" {
 		q.Operator = QueryUnion
@@ -33,6 +33,10 @@ var _ Query = (*DeleteQuery)(nil)
 
 // WriteSQL implements the SQLWriter interface.
 func (q DeleteQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	ctx = withWriteState(ctx)
+	state := getWriteState(ctx)
+	state.pushScope(q.JoinTables, append([]Table{q.DeleteTable, q.UsingTable}, q.DeleteTables...)...)
+	defer state.popScope()
 	var err error
 	// Table Policies
 	var policies []Predicate
@@ -93,7 +97,11 @@ func (q DeleteQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 		}
 		if dialect != DialectSQLServer {
 			if alias := getAlias(q.DeleteTable); alias != "" {
-				buf.WriteString(" AS " + QuoteIdentifier(dialect, alias))
+				quotedAlias, err := quoteAlias(ctx, dialect, alias)
+				if err != nil {
+					return fmt.Errorf("DELETE FROM: %w", err)
+				}
+				buf.WriteString(" AS " + quotedAlias)
 			}
 		}
 	}
@@ -127,7 +135,11 @@ func (q DeleteQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 			}
 		}
 		if alias := getAlias(q.UsingTable); alias != "" {
-			buf.WriteString(" AS " + QuoteIdentifier(dialect, alias))
+			quotedAlias, err := quoteAlias(ctx, dialect, alias)
+			if err != nil {
+				return fmt.Errorf("USING: %w", err)
+			}
+			buf.WriteString(" AS " + quotedAlias)
 		}
 	}
 	// JOIN
@@ -158,10 +170,25 @@ func (q DeleteQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 			}
 		}
 	}
+	// RETURNING (sqlite)
+	if len(q.ReturningFields) > 0 && dialect == DialectSQLite {
+		// sqlite's RETURNING clause comes before ORDER BY and LIMIT.
+		buf.WriteString(" RETURNING ")
+		err = writeFields(ctx, dialect, buf, args, params, q.ReturningFields, true)
+		if err != nil {
+			return fmt.Errorf("RETURNING: %w", err)
+		}
+	}
 	// ORDER BY
 	if len(q.OrderByFields) > 0 {
-		if dialect != DialectMySQL {
-			return fmt.Errorf("%s UPDATE does not support ORDER BY", dialect)
+		if dialect != DialectMySQL && dialect != DialectSQLite {
+			return fmt.Errorf("%s DELETE does not support ORDER BY", dialect)
+		}
+		if dialect == DialectMySQL && (len(q.DeleteTables) > 1 || q.UsingTable != nil) {
+			return fmt.Errorf("mysql multi-table DELETE does not support ORDER BY")
+		}
+		if dialect == DialectSQLite && q.LimitRows == nil {
+			return fmt.Errorf("sqlite DELETE does not support ORDER BY without LIMIT")
 		}
 		buf.WriteString(" ORDER BY ")
 		err = q.OrderByFields.WriteSQL(ctx, dialect, buf, args, params)
@@ -171,19 +198,36 @@ func (q DeleteQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 	}
 	// LIMIT
 	if q.LimitRows != nil {
-		if dialect != DialectMySQL {
-			return fmt.Errorf("%s UPDATE does not support LIMIT", dialect)
+		if dialect != DialectMySQL && dialect != DialectSQLite {
+			return fmt.Errorf("%s DELETE does not support LIMIT", dialect)
+		}
+		if dialect == DialectMySQL && (len(q.DeleteTables) > 1 || q.UsingTable != nil) {
+			return fmt.Errorf("mysql multi-table DELETE does not support LIMIT")
 		}
 		buf.WriteString(" LIMIT ")
-		err = WriteValue(ctx, dialect, buf, args, params, q.LimitRows)
+		err = writeRowCount(ctx, dialect, buf, args, params, q.LimitRows)
 		if err != nil {
 			return fmt.Errorf("LIMIT: %w", err)
 		}
 	}
+	// OFFSET
+	if q.OffsetRows != nil {
+		if dialect != DialectSQLite {
+			return fmt.Errorf("%s DELETE does not support OFFSET", dialect)
+		}
+		if q.LimitRows == nil {
+			return fmt.Errorf("sqlite DELETE does not support OFFSET without LIMIT")
+		}
+		buf.WriteString(" OFFSET ")
+		err = writeRowCount(ctx, dialect, buf, args, params, q.OffsetRows)
+		if err != nil {
+			return fmt.Errorf("OFFSET: %w", err)
+		}
+	}
 	// RETURNING
-	if len(q.ReturningFields) > 0 && dialect != DialectSQLServer {
-		if dialect != DialectPostgres && dialect != DialectSQLite && dialect != DialectMySQL {
-			return fmt.Errorf("%s UPDATE does not support RETURNING", dialect)
+	if len(q.ReturningFields) > 0 && dialect != DialectSQLServer && dialect != DialectSQLite {
+		if dialect != DialectPostgres && dialect != DialectMySQL {
+			return fmt.Errorf("%s DELETE does not support RETURNING", dialect)
 		}
 		buf.WriteString(" RETURNING ")
 		err = writeFields(ctx, dialect, buf, args, params, q.ReturningFields, true)
@@ -208,7 +252,7 @@ func (q DeleteQuery) Where(predicates ...Predicate) DeleteQuery {
 // SetFetchableFields implements the Query interface.
 func (q DeleteQuery) SetFetchableFields(fields []Field) (query Query, ok bool) {
 	switch q.Dialect {
-	case DialectPostgres, DialectSQLite:
+	case DialectPostgres, DialectSQLite, DialectSQLServer:
 		if len(q.ReturningFields) == 0 {
 			q.ReturningFields = fields
 			return q, true
@@ -222,7 +266,7 @@ func (q DeleteQuery) SetFetchableFields(fields []Field) (query Query, ok bool) {
 // GetFetchableFields returns the fetchable fields of the query.
 func (q DeleteQuery) GetFetchableFields() []Field {
 	switch q.Dialect {
-	case DialectPostgres, DialectSQLite:
+	case DialectPostgres, DialectSQLite, DialectSQLServer:
 		return q.ReturningFields
 	default:
 		return nil
@@ -263,6 +307,28 @@ func (q SQLiteDeleteQuery) Where(predicates ...Predicate) SQLiteDeleteQuery {
 	return q
 }
 
+// OrderBy sets the OrderByFields of the SQLiteDeleteQuery. Note that sqlite
+// only supports ORDER BY in a DELETE if it was compiled with
+// SQLITE_ENABLE_UPDATE_DELETE_LIMIT, and only together with LIMIT.
+func (q SQLiteDeleteQuery) OrderBy(fields ...Field) SQLiteDeleteQuery {
+	q.OrderByFields = append(q.OrderByFields, fields...)
+	return q
+}
+
+// Limit sets the LimitRows field of the SQLiteDeleteQuery. Note that sqlite
+// only supports LIMIT in a DELETE if it was compiled with
+// SQLITE_ENABLE_UPDATE_DELETE_LIMIT.
+func (q SQLiteDeleteQuery) Limit(limit any) SQLiteDeleteQuery {
+	q.LimitRows = limit
+	return q
+}
+
+// Offset sets the OffsetRows field of the SQLiteDeleteQuery.
+func (q SQLiteDeleteQuery) Offset(offset any) SQLiteDeleteQuery {
+	q.OffsetRows = offset
+	return q
+}
+
 // Returning appends fields to the RETURNING clause of the SQLiteDeleteQuery.
 func (q SQLiteDeleteQuery) Returning(fields ...Field) SQLiteDeleteQuery {
 	q.ReturningFields = append(q.ReturningFields, fields...)
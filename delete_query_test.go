@@ -53,6 +53,26 @@ func TestSQLiteDeleteQuery(t *testing.T) {
 		tt.wantArgs = []any{1}
 		tt.assert(t)
 	})
+
+	t.Run("OrderBy Limit Offset", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = SQLite.
+			DeleteFrom(a).
+			Where(a.LAST_NAME.EqString("the builder")).
+			Returning(a.ACTOR_ID).
+			OrderBy(a.ACTOR_ID).
+			Limit(5).
+			Offset(10)
+		tt.wantQuery = "DELETE FROM actor AS a" +
+			" WHERE a.last_name = $1" +
+			" RETURNING a.actor_id" +
+			" ORDER BY a.actor_id" +
+			" LIMIT $2" +
+			" OFFSET $3"
+		tt.wantArgs = []any{"the builder", 5, 10}
+		tt.assert(t)
+	})
 }
 
 func TestPostgresDeleteQuery(t *testing.T) {
@@ -182,8 +202,7 @@ func TestMySQLDeleteQuery(t *testing.T) {
 			Limit(5)
 		tt.wantQuery = "DELETE FROM actor" +
 			" ORDER BY actor.actor_id" +
-			" LIMIT ?"
-		tt.wantArgs = []any{5}
+			" LIMIT 5"
 		tt.assert(t)
 	})
 
@@ -260,6 +279,25 @@ func TestSQLServerDeleteQuery(t *testing.T) {
 		}
 	})
 
+	t.Run("SetFetchableFields", func(t *testing.T) {
+		t.Parallel()
+		query, ok := SQLServer.
+			DeleteFrom(a).
+			Where(a.ACTOR_ID.EqInt(1)).
+			SetFetchableFields([]Field{a.ACTOR_ID, a.FIRST_NAME})
+		if !ok {
+			t.Fatal(testutil.Callers(), "field should have been set")
+		}
+		gotQuery, _, err := ToSQL(DialectSQLServer, query, nil)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		wantQuery := "DELETE FROM actor OUTPUT DELETED.actor_id, DELETED.first_name WHERE actor.actor_id = @p1"
+		if diff := testutil.Diff(gotQuery, wantQuery); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
 	t.Run("Where", func(t *testing.T) {
 		t.Parallel()
 		var tt TestTable
@@ -354,6 +392,44 @@ func TestDeleteQuery(t *testing.T) {
 			DeleteTable: Expr("tbl"),
 			LimitRows:   5,
 		},
+	}, {
+		description: "dialect does not support OFFSET",
+		item: DeleteQuery{
+			Dialect:     DialectMySQL,
+			DeleteTable: Expr("tbl"),
+			LimitRows:   5,
+			OffsetRows:  10,
+		},
+	}, {
+		description: "sqlite does not support ORDER BY without LIMIT",
+		item: DeleteQuery{
+			Dialect:       DialectSQLite,
+			DeleteTable:   Expr("tbl"),
+			OrderByFields: Fields{Expr("f1")},
+		},
+	}, {
+		description: "sqlite does not support OFFSET without LIMIT",
+		item: DeleteQuery{
+			Dialect:     DialectSQLite,
+			DeleteTable: Expr("tbl"),
+			OffsetRows:  10,
+		},
+	}, {
+		description: "mysql multi-table DELETE does not support ORDER BY",
+		item: DeleteQuery{
+			Dialect:       DialectMySQL,
+			DeleteTables:  []Table{Expr("tbl")},
+			UsingTable:    Expr("tbl"),
+			OrderByFields: Fields{Expr("f1")},
+		},
+	}, {
+		description: "mysql multi-table DELETE does not support LIMIT",
+		item: DeleteQuery{
+			Dialect:      DialectMySQL,
+			DeleteTables: []Table{Expr("tbl1"), Expr("tbl2")},
+			UsingTable:   Expr("tbl1"),
+			LimitRows:    5,
+		},
 	}}
 
 	for _, tt := range notOKTests {
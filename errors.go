@@ -0,0 +1,286 @@
+package sq
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ErrUniqueViolation is matched (with errors.Is) by the errors of queries that
+// violate a unique constraint, once they have been translated by
+// TranslateErrors. Use errors.As with a *UniqueViolationError to get the name
+// of the violated constraint.
+//
+//	_, err := sq.Exec(db, sq.InsertInto(u).Columns(u.EMAIL).Values(email))
+//	if errors.Is(err, sq.ErrUniqueViolation) {
+//	    return fmt.Errorf("email %s is already taken", email)
+//	}
+var ErrUniqueViolation = errors.New("unique constraint violation")
+
+// UniqueViolationError is the translated error of a query that violated a
+// unique constraint.
+type UniqueViolationError struct {
+	// Constraint is the name of the violated constraint or unique index. For
+	// SQLite, which does not report constraint names, it is the
+	// comma-separated list of violated columns e.g. "actor.actor_id".
+	Constraint string
+
+	// Err is the original driver error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *UniqueViolationError) Error() string {
+	if e.Constraint == "" {
+		return ErrUniqueViolation.Error() + ": " + e.Err.Error()
+	}
+	return ErrUniqueViolation.Error() + " (" + e.Constraint + "): " + e.Err.Error()
+}
+
+// Unwrap returns the original driver error.
+func (e *UniqueViolationError) Unwrap() error { return e.Err }
+
+// Is reports whether the target is ErrUniqueViolation.
+func (e *UniqueViolationError) Is(target error) bool { return target == ErrUniqueViolation }
+
+// TranslateErrors returns an Interceptor that translates driver errors into
+// sq errors. Unique violations are translated into a *UniqueViolationError,
+// with the constraint name parsed from the error message of the query's
+// dialect.
+//
+//	db = sq.WithInterceptors(db, sq.TranslateErrors())
+func TranslateErrors() Interceptor { return errorTranslator{} }
+
+type errorTranslator struct{}
+
+// BeforeQuery implements the Interceptor interface.
+func (errorTranslator) BeforeQuery(ctx context.Context, queryStats QueryStats) (context.Context, error) {
+	return ctx, nil
+}
+
+// AfterQuery implements the Interceptor interface.
+func (errorTranslator) AfterQuery(ctx context.Context, queryStats QueryStats) error {
+	return translateError(queryStats.Dialect, queryStats.Err)
+}
+
+// translateError translates a driver error into an sq error. Errors that
+// cannot be translated are returned as-is.
+func translateError(dialect string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var uniqueViolationErr *UniqueViolationError
+	if errors.As(err, &uniqueViolationErr) {
+		return err
+	}
+	constraint, ok := parseUniqueViolation(dialect, err.Error())
+	if !ok && classifyViolation(err) == uniqueViolation {
+		ok = true
+		constraint = driverErrorConstraint(err)
+	}
+	if ok {
+		return &UniqueViolationError{Constraint: constraint, Err: err}
+	}
+	return err
+}
+
+// IsUniqueViolation reports whether an error is caused by a unique (or primary
+// key) constraint violation. It understands the error codes of the
+// lib/pq, pgx, go-sql-driver/mysql, mattn/go-sqlite3, modernc.org/sqlite and
+// go-mssqldb drivers, falling back on the error message for other drivers.
+//
+//	_, err := sq.Exec(db, sq.InsertInto(u).Columns(u.EMAIL).Values(email))
+//	if sq.IsUniqueViolation(err) {
+//	    return fmt.Errorf("email %s is already taken", email)
+//	}
+func IsUniqueViolation(err error) bool { return classifyViolation(err) == uniqueViolation }
+
+// IsForeignKeyViolation reports whether an error is caused by a foreign key
+// constraint violation. It understands the same drivers as IsUniqueViolation.
+func IsForeignKeyViolation(err error) bool { return classifyViolation(err) == foreignKeyViolation }
+
+// IsCheckViolation reports whether an error is caused by a check constraint
+// violation. It understands the same drivers as IsUniqueViolation.
+func IsCheckViolation(err error) bool { return classifyViolation(err) == checkViolation }
+
+type violation int
+
+const (
+	noViolation violation = iota
+	uniqueViolation
+	foreignKeyViolation
+	checkViolation
+)
+
+// classifyViolation returns the kind of constraint violation an error is
+// caused by, by looking at the error codes of every error in the error chain
+// and falling back on the error message.
+func classifyViolation(err error) violation {
+	if err == nil {
+		return noViolation
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if _, ok := e.(*UniqueViolationError); ok {
+			return uniqueViolation
+		}
+		if v := classifyDriverError(e); v != noViolation {
+			return v
+		}
+	}
+	msg := err.Error()
+	if _, ok := parseUniqueViolation("", msg); ok {
+		return uniqueViolation
+	}
+	for _, s := range []string{
+		"FOREIGN KEY constraint failed",              // sqlite
+		"violates foreign key constraint",            // postgres
+		"a foreign key constraint fails",             // mysql
+		"conflicted with the FOREIGN KEY constraint", // sqlserver
+		"conflicted with the REFERENCE constraint",   // sqlserver
+	} {
+		if strings.Contains(msg, s) {
+			return foreignKeyViolation
+		}
+	}
+	for _, s := range []string{
+		"CHECK constraint failed",              // sqlite
+		"violates check constraint",            // postgres
+		"Check constraint '",                   // mysql
+		"conflicted with the CHECK constraint", // sqlserver
+	} {
+		if strings.Contains(msg, s) {
+			return checkViolation
+		}
+	}
+	return noViolation
+}
+
+// classifyDriverError returns the kind of constraint violation a driver error
+// is caused by based on its error code.
+func classifyDriverError(err error) violation {
+	code := getDriverErrorCode(err)
+	if v := classifySQLState(code.sqlState); v != noViolation {
+		return v
+	}
+	if v := classifySQLiteCode(code.sqliteCode); v != noViolation {
+		return v
+	}
+	switch code.number {
+	case 1062, 2601, 2627: // mysql ER_DUP_ENTRY, sqlserver duplicate key
+		return uniqueViolation
+	case 1216, 1217, 1451, 1452: // mysql ER_NO_REFERENCED_ROW, ER_ROW_IS_REFERENCED
+		return foreignKeyViolation
+	case 3819: // mysql ER_CHECK_CONSTRAINT_VIOLATED
+		return checkViolation
+	case 547: // sqlserver uses the same error number for foreign key and check violations
+		if strings.Contains(err.Error(), "CHECK constraint") {
+			return checkViolation
+		}
+		return foreignKeyViolation
+	}
+	return noViolation
+}
+
+func classifySQLState(sqlState string) violation {
+	switch sqlState {
+	case "23505":
+		return uniqueViolation
+	case "23503":
+		return foreignKeyViolation
+	case "23514":
+		return checkViolation
+	}
+	return noViolation
+}
+
+func classifySQLiteCode(code int64) violation {
+	switch code {
+	case 1555, 2067: // SQLITE_CONSTRAINT_PRIMARYKEY, SQLITE_CONSTRAINT_UNIQUE
+		return uniqueViolation
+	case 787: // SQLITE_CONSTRAINT_FOREIGNKEY
+		return foreignKeyViolation
+	case 275: // SQLITE_CONSTRAINT_CHECK
+		return checkViolation
+	}
+	return noViolation
+}
+
+// driverErrorConstraint returns the constraint name reported by a driver error
+// (lib/pq's Constraint field or pgx's ConstraintName field) in the error
+// chain, if any.
+func driverErrorConstraint(err error) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		value := reflect.Indirect(reflect.ValueOf(e))
+		if value.Kind() != reflect.Struct {
+			continue
+		}
+		for _, name := range []string{"Constraint", "ConstraintName"} {
+			if field := value.FieldByName(name); field.IsValid() && field.Kind() == reflect.String && field.String() != "" {
+				return field.String()
+			}
+		}
+	}
+	return ""
+}
+
+// parseUniqueViolation reports whether an error message is a unique violation
+// and if so, returns the name of the violated constraint. If the dialect is
+// empty, the error message is checked against every dialect.
+func parseUniqueViolation(dialect string, msg string) (constraint string, ok bool) {
+	switch dialect {
+	case DialectSQLite:
+		// UNIQUE constraint failed: actor.first_name, actor.last_name
+		const prefix = "UNIQUE constraint failed: "
+		if i := strings.Index(msg, prefix); i >= 0 {
+			constraint, _, _ = strings.Cut(msg[i+len(prefix):], "\n")
+			return constraint, true
+		}
+	case DialectPostgres:
+		// duplicate key value violates unique constraint "actor_pkey"
+		if i := strings.Index(msg, "violates unique constraint "); i >= 0 {
+			return quoted(msg[i+len("violates unique constraint "):], '"'), true
+		}
+	case DialectMySQL:
+		// Error 1062 (23000): Duplicate entry 'PENELOPE' for key 'actor.first_name'
+		if strings.Contains(msg, "Duplicate entry ") {
+			if i := strings.LastIndex(msg, "for key "); i >= 0 {
+				return quoted(msg[i+len("for key "):], '\''), true
+			}
+			return "", true
+		}
+	case DialectSQLServer:
+		// Violation of UNIQUE KEY constraint 'UQ_actor_first_name'. Cannot insert duplicate key in object 'dbo.actor'.
+		// Cannot insert duplicate key row in object 'dbo.actor' with unique index 'ix_actor_first_name'.
+		if strings.HasPrefix(msg, "mssql: ") {
+			msg = msg[len("mssql: "):]
+		}
+		if strings.HasPrefix(msg, "Violation of PRIMARY KEY constraint ") || strings.HasPrefix(msg, "Violation of UNIQUE KEY constraint ") {
+			i := strings.Index(msg, "constraint ")
+			return quoted(msg[i+len("constraint "):], '\''), true
+		}
+		if i := strings.Index(msg, "with unique index "); i >= 0 && strings.Contains(msg, "Cannot insert duplicate key row") {
+			return quoted(msg[i+len("with unique index "):], '\''), true
+		}
+	case "":
+		for _, dialect := range []string{DialectSQLite, DialectPostgres, DialectMySQL, DialectSQLServer} {
+			if constraint, ok := parseUniqueViolation(dialect, msg); ok {
+				return constraint, true
+			}
+		}
+	}
+	return "", false
+}
+
+// quoted returns the contents of the quoted string at the start of s, or an
+// empty string if s does not start with a quoted string.
+func quoted(s string, quote byte) string {
+	if len(s) == 0 || s[0] != quote {
+		return ""
+	}
+	s = s[1:]
+	if i := strings.IndexByte(s, quote); i >= 0 {
+		return s[:i]
+	}
+	return ""
+}
@@ -0,0 +1,249 @@
+package sq
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func Test_parseUniqueViolation(t *testing.T) {
+	type TestTable struct {
+		description    string
+		dialect        string
+		msg            string
+		wantConstraint string
+		wantOk         bool
+	}
+
+	tests := []TestTable{{
+		description:    "sqlite",
+		dialect:        DialectSQLite,
+		msg:            "UNIQUE constraint failed: actor.first_name, actor.last_name",
+		wantConstraint: "actor.first_name, actor.last_name",
+		wantOk:         true,
+	}, {
+		description:    "postgres",
+		dialect:        DialectPostgres,
+		msg:            `pq: duplicate key value violates unique constraint "actor_pkey"`,
+		wantConstraint: "actor_pkey",
+		wantOk:         true,
+	}, {
+		description:    "mysql",
+		dialect:        DialectMySQL,
+		msg:            "Error 1062 (23000): Duplicate entry 'PENELOPE' for key 'actor.first_name'",
+		wantConstraint: "actor.first_name",
+		wantOk:         true,
+	}, {
+		description:    "sqlserver constraint",
+		dialect:        DialectSQLServer,
+		msg:            "mssql: Violation of UNIQUE KEY constraint 'UQ_actor_first_name'. Cannot insert duplicate key in object 'dbo.actor'. The duplicate key value is (PENELOPE).",
+		wantConstraint: "UQ_actor_first_name",
+		wantOk:         true,
+	}, {
+		description:    "sqlserver unique index",
+		dialect:        DialectSQLServer,
+		msg:            "mssql: Cannot insert duplicate key row in object 'dbo.actor' with unique index 'ix_actor_first_name'. The duplicate key value is (PENELOPE).",
+		wantConstraint: "ix_actor_first_name",
+		wantOk:         true,
+	}, {
+		description:    "no dialect",
+		msg:            `ERROR: duplicate key value violates unique constraint "actor_pkey" (SQLSTATE 23505)`,
+		wantConstraint: "actor_pkey",
+		wantOk:         true,
+	}, {
+		description: "not a unique violation",
+		dialect:     DialectPostgres,
+		msg:         `pq: relation "actor" does not exist`,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			gotConstraint, gotOk := parseUniqueViolation(tt.dialect, tt.msg)
+			if diff := testutil.Diff(gotConstraint, tt.wantConstraint); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			if diff := testutil.Diff(gotOk, tt.wantOk); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	t.Parallel()
+	db := WithInterceptors(newDB(t), TranslateErrors())
+	insertActor := InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS")
+	_, err := Exec(db, insertActor)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = Exec(db, insertActor)
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf(testutil.Callers()+" expected ErrUniqueViolation, got %v", err)
+	}
+	var uniqueViolationErr *UniqueViolationError
+	if !errors.As(err, &uniqueViolationErr) {
+		t.Fatalf(testutil.Callers()+" expected *UniqueViolationError, got %T", err)
+	}
+	if diff := testutil.Diff(uniqueViolationErr.Constraint, "actor.actor_id"); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
+
+type fakePQError struct {
+	Code       string
+	Constraint string
+}
+
+func (e *fakePQError) Error() string { return "pq: error " + e.Code }
+
+type fakePgxError struct{ code string }
+
+func (e *fakePgxError) Error() string    { return "ERROR: (SQLSTATE " + e.code + ")" }
+func (e *fakePgxError) SQLState() string { return e.code }
+
+type fakeMySQLError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *fakeMySQLError) Error() string { return e.Message }
+
+type fakeSQLiteError struct {
+	Code         int
+	ExtendedCode int
+}
+
+func (e fakeSQLiteError) Error() string { return "constraint failed" }
+
+type fakeModerncError struct{ code int }
+
+func (e *fakeModerncError) Error() string { return "constraint failed" }
+func (e *fakeModerncError) Code() int     { return e.code }
+
+type fakeMSSQLError struct {
+	Number  int32
+	Message string
+}
+
+func (e fakeMSSQLError) Error() string { return "mssql: " + e.Message }
+
+func TestConstraintViolations(t *testing.T) {
+	type TestTable struct {
+		description string
+		err         error
+		wantUnique  bool
+		wantFK      bool
+		wantCheck   bool
+	}
+
+	tests := []TestTable{{
+		description: "nil",
+	}, {
+		description: "not a violation",
+		err:         errors.New("connection refused"),
+	}, {
+		description: "pq unique",
+		err:         &fakePQError{Code: "23505"},
+		wantUnique:  true,
+	}, {
+		description: "pq foreign key",
+		err:         &fakePQError{Code: "23503"},
+		wantFK:      true,
+	}, {
+		description: "pgx check",
+		err:         &fakePgxError{code: "23514"},
+		wantCheck:   true,
+	}, {
+		description: "mysql unique",
+		err:         &fakeMySQLError{Number: 1062},
+		wantUnique:  true,
+	}, {
+		description: "mysql foreign key",
+		err:         &fakeMySQLError{Number: 1452},
+		wantFK:      true,
+	}, {
+		description: "mysql check",
+		err:         &fakeMySQLError{Number: 3819},
+		wantCheck:   true,
+	}, {
+		description: "mattn sqlite primary key",
+		err:         fakeSQLiteError{Code: 19, ExtendedCode: 1555},
+		wantUnique:  true,
+	}, {
+		description: "modernc sqlite foreign key",
+		err:         &fakeModerncError{code: 787},
+		wantFK:      true,
+	}, {
+		description: "mssql unique index",
+		err:         fakeMSSQLError{Number: 2601},
+		wantUnique:  true,
+	}, {
+		description: "mssql foreign key",
+		err:         fakeMSSQLError{Number: 547, Message: `The INSERT statement conflicted with the FOREIGN KEY constraint "fk_film_actor".`},
+		wantFK:      true,
+	}, {
+		description: "mssql check",
+		err:         fakeMSSQLError{Number: 547, Message: `The INSERT statement conflicted with the CHECK constraint "ck_rating".`},
+		wantCheck:   true,
+	}, {
+		description: "wrapped",
+		err:         fmt.Errorf("insert user: %w", &fakePQError{Code: "23505"}),
+		wantUnique:  true,
+	}, {
+		description: "message fallback",
+		err:         errors.New("CHECK constraint failed: rating BETWEEN 1 AND 5"),
+		wantCheck:   true,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			if diff := testutil.Diff(IsUniqueViolation(tt.err), tt.wantUnique); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			if diff := testutil.Diff(IsForeignKeyViolation(tt.err), tt.wantFK); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			if diff := testutil.Diff(IsCheckViolation(tt.err), tt.wantCheck); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+
+	t.Run("translate driver constraint", func(t *testing.T) {
+		t.Parallel()
+		err := translateError(DialectPostgres, &fakePQError{Code: "23505", Constraint: "users_email_key"})
+		var uniqueViolationErr *UniqueViolationError
+		if !errors.As(err, &uniqueViolationErr) {
+			t.Fatalf(testutil.Callers()+" expected *UniqueViolationError, got %T", err)
+		}
+		if diff := testutil.Diff(uniqueViolationErr.Constraint, "users_email_key"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := db.Exec("CREATE TABLE film (film_id INTEGER PRIMARY KEY, rating INT CHECK (rating BETWEEN 1 AND 5))")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = Exec(db, Queryf("INSERT INTO film (film_id, rating) VALUES (1, 6)"))
+		if !IsCheckViolation(err) {
+			t.Errorf(testutil.Callers()+" expected check violation, got %v", err)
+		}
+		_, err = Exec(db, Queryf("INSERT INTO film (film_id, rating) VALUES (1, 5), (1, 5)"))
+		if !IsUniqueViolation(err) {
+			t.Errorf(testutil.Callers()+" expected unique violation, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package sq
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// CSVOptions are the options used by Cursor.WriteCSV.
+type CSVOptions struct {
+	// Comma is the field delimiter. Defaults to ','.
+	Comma rune
+
+	// OmitHeader omits the header row of column names.
+	OmitHeader bool
+
+	// Null is written in place of NULL values. Defaults to an empty string.
+	Null string
+
+	// TimeFormat is the layout used to format time.Time values. Defaults to
+	// time.RFC3339Nano.
+	TimeFormat string
+}
+
+// WriteCSV writes the remaining rows of the cursor to w as CSV, preceded by a
+// header row of column names. Rows are written as they are read from the
+// database, so the result set is never held in memory. For static queries
+// every column of the result set is written, for dynamic queries the fields
+// used by the rowmapper are written (the rowmapper's results are discarded).
+// The cursor is closed once all rows have been written.
+//
+//	cursor, err := sq.FetchCursor(db, sq.Queryf("SELECT * FROM actor"), func(row *sq.Row) struct{} {
+//	    return struct{}{}
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer cursor.Close()
+//	w.Header().Set("Content-Type", "text/csv")
+//	err = cursor.WriteCSV(w, sq.CSVOptions{})
+func (cursor *Cursor[T]) WriteCSV(w io.Writer, opts CSVOptions) error {
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = time.RFC3339Nano
+	}
+	csvWriter := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		csvWriter.Comma = opts.Comma
+	}
+	columns, err := cursor.row.sqlRows.Columns()
+	if err != nil {
+		return err
+	}
+	if !opts.OmitHeader {
+		err = csvWriter.Write(columns)
+		if err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(columns))
+	var values []any
+	for cursor.Next() {
+		_, err = cursor.Result()
+		if err != nil {
+			return err
+		}
+		values = cursor.row.resultValues(values[:0])
+		for i, value := range values {
+			if i < len(record) {
+				record[i] = csvValue(value, opts)
+			}
+		}
+		err = csvWriter.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return err
+	}
+	return cursor.Close()
+}
+
+// WriteJSONLines writes the remaining rows of the cursor to w as newline
+// delimited JSON, one JSON object per row. The keys of each object are the
+// column names in result set order. Binary values that are valid UTF-8 are
+// written as strings, other binary values are base64 encoded. Like WriteCSV,
+// rows are written as they are read from the database and the cursor is
+// closed once all rows have been written.
+func (cursor *Cursor[T]) WriteJSONLines(w io.Writer) error {
+	buf := bufpool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufpool.Put(buf)
+	columns, err := cursor.row.sqlRows.Columns()
+	if err != nil {
+		return err
+	}
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		keys[i], err = json.Marshal(column)
+		if err != nil {
+			return err
+		}
+	}
+	var values []any
+	for cursor.Next() {
+		_, err = cursor.Result()
+		if err != nil {
+			return err
+		}
+		values = cursor.row.resultValues(values[:0])
+		buf.Reset()
+		buf.WriteByte('{')
+		for i, value := range values {
+			if i >= len(keys) {
+				break
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(keys[i])
+			buf.WriteByte(':')
+			if b, ok := value.([]byte); ok && utf8.Valid(b) {
+				value = string(b)
+			}
+			b, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("column %s: %w", columns[i], err)
+			}
+			buf.Write(b)
+		}
+		buf.WriteString("}\n")
+		_, err = w.Write(buf.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return cursor.Close()
+}
+
+// resultValues appends the values of the current row to dst. For static
+// queries they are the values of every column, for dynamic queries they are
+// the values scanned into row.scanDest.
+func (row *Row) resultValues(dst []any) []any {
+	if row.queryIsStatic {
+		return append(dst, row.values...)
+	}
+	for _, scanDest := range row.scanDest {
+		switch scanDest := scanDest.(type) {
+		case driver.Valuer:
+			// sql.NullString, sql.NullInt64, etc.
+			value, err := scanDest.Value()
+			if err != nil {
+				dst = append(dst, nil)
+				continue
+			}
+			dst = append(dst, value)
+		case *any:
+			dst = append(dst, *scanDest)
+		default:
+			value := reflect.ValueOf(scanDest)
+			for value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					break
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Ptr {
+				dst = append(dst, nil)
+				continue
+			}
+			dst = append(dst, value.Interface())
+		}
+	}
+	return dst
+}
+
+// csvValue formats a value as a CSV field.
+func csvValue(value any, opts CSVOptions) string {
+	switch value := value.(type) {
+	case nil:
+		return opts.Null
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	case time.Time:
+		return value.Format(opts.TimeFormat)
+	case bool:
+		return strconv.FormatBool(value)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
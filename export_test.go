@@ -0,0 +1,104 @@
+package sq
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestCursorExport(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS").
+		Values(2, "NICK", "WAHL,BERG"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	type Actor struct {
+		ActorID   int
+		FirstName string
+		LastName  *string
+	}
+	dynamicRowMapper := func(row *Row) Actor {
+		var actor Actor
+		actor.ActorID = row.IntField(ACTOR.ACTOR_ID)
+		actor.FirstName = row.StringField(ACTOR.FIRST_NAME)
+		row.ScanField(&actor.LastName, Expr("NULLIF({}, 'GUINESS')", ACTOR.LAST_NAME).As("last_name"))
+		return actor
+	}
+	staticRowMapper := func(row *Row) struct{} { return struct{}{} }
+
+	t.Run("WriteCSV dynamic", func(t *testing.T) {
+		cursor, err := FetchCursor(db, SQLite.From(ACTOR).OrderBy(ACTOR.ACTOR_ID), dynamicRowMapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer cursor.Close()
+		var buf bytes.Buffer
+		err = cursor.WriteCSV(&buf, CSVOptions{Null: "NULL"})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		wantCSV := "actor_id,first_name,last_name\n" +
+			"1,PENELOPE,NULL\n" +
+			"2,NICK,\"WAHL,BERG\"\n"
+		if diff := testutil.Diff(buf.String(), wantCSV); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("WriteCSV static", func(t *testing.T) {
+		cursor, err := FetchCursor(db, SQLite.Queryf("SELECT actor_id, last_name FROM actor ORDER BY actor_id"), staticRowMapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer cursor.Close()
+		var buf bytes.Buffer
+		err = cursor.WriteCSV(&buf, CSVOptions{Comma: ';', OmitHeader: true})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(buf.String(), "1;GUINESS\n2;WAHL,BERG\n"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("WriteJSONLines", func(t *testing.T) {
+		cursor, err := FetchCursor(db, SQLite.From(ACTOR).OrderBy(ACTOR.ACTOR_ID), dynamicRowMapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer cursor.Close()
+		var buf bytes.Buffer
+		err = cursor.WriteJSONLines(&buf)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		wantJSON := `{"actor_id":1,"first_name":"PENELOPE","last_name":null}` + "\n" +
+			`{"actor_id":2,"first_name":"NICK","last_name":"WAHL,BERG"}` + "\n"
+		if diff := testutil.Diff(buf.String(), wantJSON); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("WriteJSONLines no rows", func(t *testing.T) {
+		cursor, err := FetchCursor(db, SQLite.Queryf("SELECT * FROM actor WHERE 1 = 0"), staticRowMapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer cursor.Close()
+		var buf bytes.Buffer
+		err = cursor.WriteJSONLines(&buf)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(buf.String(), ""); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package sq
+
+import (
+	"context"
+	"fmt"
+)
+
+// FetchAllFederated joins across databases that cannot be queried with a
+// single SQL statement. It reads keys from keysCursor (usually a cursor over
+// another database) and, batchSize keys at a time, runs the query returned by
+// queryFor on db and fetches its results with the rowmapper. The results of
+// every batch are returned together, in batch order. keysCursor is closed by
+// FetchAllFederated.
+//
+// Keys are streamed from keysCursor, so only one batch of keys is held in
+// memory at a time.
+//
+//	keysCursor, err := sq.FetchCursor(accountsDB, sq.
+//	    From(a).
+//	    Where(a.PLAN.EqString("enterprise")),
+//	    func(row *sq.Row) int64 { return row.Int64Field(a.ACCOUNT_ID) },
+//	)
+//	if err != nil {
+//	    return err
+//	}
+//	invoices, err := sq.FetchAllFederated(keysCursor, billingDB, 500, func(accountIDs []int64) sq.Query {
+//	    return sq.From(i).Where(i.ACCOUNT_ID.In(accountIDs))
+//	}, invoiceRowMapper)
+func FetchAllFederated[K, T any](keysCursor *Cursor[K], db DB, batchSize int, queryFor func(keys []K) Query, rowmapper func(*Row) T) ([]T, error) {
+	return fetchAllFederated(context.Background(), keysCursor, db, batchSize, queryFor, rowmapper)
+}
+
+// FetchAllFederatedContext is like FetchAllFederated but additionally requires
+// a context.Context, which is used for the queries run on db.
+func FetchAllFederatedContext[K, T any](ctx context.Context, keysCursor *Cursor[K], db DB, batchSize int, queryFor func(keys []K) Query, rowmapper func(*Row) T) ([]T, error) {
+	return fetchAllFederated(ctx, keysCursor, db, batchSize, queryFor, rowmapper)
+}
+
+func fetchAllFederated[K, T any](ctx context.Context, keysCursor *Cursor[K], db DB, batchSize int, queryFor func(keys []K) Query, rowmapper func(*Row) T) (results []T, err error) {
+	if keysCursor == nil {
+		return nil, fmt.Errorf("keysCursor is nil")
+	}
+	defer keysCursor.Close()
+	if db == nil {
+		return nil, fmt.Errorf("db is nil")
+	}
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be greater than zero, got %d", batchSize)
+	}
+	if queryFor == nil {
+		return nil, fmt.Errorf("queryFor is nil")
+	}
+	if rowmapper == nil {
+		return nil, fmt.Errorf("rowmapper is nil")
+	}
+	keys := make([]K, 0, batchSize)
+	batch := 0
+	fetchBatch := func() error {
+		batch++
+		query := queryFor(keys)
+		if query == nil {
+			return fmt.Errorf("batch %d: query is nil", batch)
+		}
+		cursor, err := fetchCursor(ctx, db, query, rowmapper, 3)
+		if err != nil {
+			return fmt.Errorf("batch %d: %w", batch, err)
+		}
+		defer cursor.Close()
+		for cursor.Next() {
+			result, err := cursor.Result()
+			if err != nil {
+				return fmt.Errorf("batch %d: %w", batch, err)
+			}
+			results = append(results, result)
+		}
+		if err := cursor.Close(); err != nil {
+			return fmt.Errorf("batch %d: %w", batch, err)
+		}
+		// queryFor may hold on to the keys (e.g. in an In predicate), so
+		// the next batch gets a new slice instead of reusing this one.
+		keys = make([]K, 0, batchSize)
+		return nil
+	}
+	for keysCursor.Next() {
+		key, err := keysCursor.Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		if len(keys) == batchSize {
+			err = fetchBatch()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := keysCursor.Close(); err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		err = fetchBatch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
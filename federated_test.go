@@ -0,0 +1,62 @@
+package sq
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestFetchAllFederated(t *testing.T) {
+	t.Parallel()
+	keysDB, db := newDB(t), newDB(t)
+	_, err := Exec(keysDB, SQLite.
+		InsertInto(ACTOR).
+		ColumnValues(func(col *Column) {
+			for _, actorID := range []int{2, 3, 5, 7, 11} {
+				col.SetInt(ACTOR.ACTOR_ID, actorID)
+				col.SetString(ACTOR.FIRST_NAME, "bob")
+				col.SetString(ACTOR.LAST_NAME, "the builder")
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = Exec(db, SQLite.
+		InsertInto(ACTOR).
+		ColumnValues(func(col *Column) {
+			for actorID := 1; actorID <= 10; actorID++ {
+				col.SetInt(ACTOR.ACTOR_ID, actorID)
+				col.SetString(ACTOR.FIRST_NAME, "actor")
+				col.SetString(ACTOR.LAST_NAME, strings.Repeat("x", actorID))
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	keysCursor, err := FetchCursor(keysDB, SQLite.From(ACTOR).OrderBy(ACTOR.ACTOR_ID), func(row *Row) int {
+		return row.IntField(ACTOR.ACTOR_ID)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	var gotBatches [][]int
+	lastNames, err := FetchAllFederated(keysCursor, db, 2, func(actorIDs []int) Query {
+		gotBatches = append(gotBatches, actorIDs)
+		return SQLite.From(ACTOR).Where(ACTOR.ACTOR_ID.In(actorIDs)).OrderBy(ACTOR.ACTOR_ID)
+	}, func(row *Row) string {
+		return row.StringField(ACTOR.LAST_NAME)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(gotBatches, [][]int{{2, 3}, {5, 7}, {11}}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	// actor_id 11 is not in db.
+	if diff := testutil.Diff(lastNames, []string{"xx", "xxx", "xxxxx", "xxxxxxx"}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -16,6 +19,481 @@ import (
 // Default dialect used by all queries (if no dialect is explicitly provided).
 var DefaultDialect atomic.Pointer[string]
 
+// NewDB wraps a DB with a dialect. Queries without a dialect that are run on
+// the returned DB use that dialect instead of DefaultDialect, so that
+// applications using multiple databases don't have to rely on a process-wide
+// default.
+//
+//	pgDB := sq.NewDB(pgConn, sq.DialectPostgres)
+//	sqliteDB := sq.NewDB(sqliteConn, sq.DialectSQLite)
+//	actors, err := sq.FetchAll(pgDB, sq.From(a).Where(a.ACTOR_ID.EqInt(1)), actorRowMapper)
+//
+// If the DB is also a SqLogger (e.g. sq.Log(db)), the returned DB is too.
+func NewDB(db DB, dialect string) DB {
+	if logger, ok := db.(SqLogger); ok {
+		return &dialectLoggerDB{DB: db, SqLogger: logger, dialect: dialect}
+	}
+	return &dialectDB{DB: db, dialect: dialect}
+}
+
+type dialectDB struct {
+	DB
+	dialect string
+}
+
+func (db *dialectDB) SqDialect() string { return db.dialect }
+
+func (db *dialectDB) unwrap() DB { return db.DB }
+
+type dialectLoggerDB struct {
+	DB
+	SqLogger
+	dialect string
+}
+
+func (db *dialectLoggerDB) SqDialect() string { return db.dialect }
+
+func (db *dialectLoggerDB) unwrap() DB { return db.DB }
+
+// QueryHook rewrites a query and its args after it has been written out by
+// WriteSQL but before it is sent to the database. It can be used for things
+// like sharding, comment injection or compatibility shims. Returning an error
+// aborts the query.
+//
+// A QueryHook must not modify the args slice in place, it should return a new
+// slice instead. If the query is a prepared query, the hook is run once with
+// nil args when the query is prepared.
+type QueryHook func(ctx context.Context, dialect string, query string, args []any) (string, []any, error)
+
+// WithQueryHooks wraps a DB such that every query executed through it is
+// passed through the hooks, in order, before execution. Hooks of a DB that is
+// already wrapped by WithQueryHooks are run before the new hooks.
+//
+//	db = sq.WithQueryHooks(db, func(ctx context.Context, dialect string, query string, args []any) (string, []any, error) {
+//	    return "/* app=inventory */ " + query, args, nil
+//	})
+func WithQueryHooks(db DB, hooks ...QueryHook) DB {
+	if logger, ok := db.(SqLogger); ok {
+		return &hookLoggerDB{DB: db, SqLogger: logger, hooks: hooks}
+	}
+	return &hookDB{DB: db, hooks: hooks}
+}
+
+type hookDB struct {
+	DB
+	hooks        []QueryHook
+	interceptors []Interceptor
+}
+
+func (db *hookDB) SqDialect() string { return dbDialectOrEmpty(db.DB) }
+
+func (db *hookDB) unwrap() DB { return db.DB }
+
+type hookLoggerDB struct {
+	DB
+	SqLogger
+	hooks        []QueryHook
+	interceptors []Interceptor
+}
+
+func (db *hookLoggerDB) SqDialect() string { return dbDialectOrEmpty(db.DB) }
+
+func (db *hookLoggerDB) unwrap() DB { return db.DB }
+
+func dbDialectOrEmpty(db DB) string {
+	if db, ok := db.(interface{ SqDialect() string }); ok {
+		return db.SqDialect()
+	}
+	return ""
+}
+
+// unwrapSQLDB returns the *sql.DB that a DB is or wraps (e.g. with NewDB or
+// Log), following the wrappers all the way down. It returns false if the DB
+// does not wrap an *sql.DB e.g. it is an *sql.Tx or *sql.Conn.
+func unwrapSQLDB(db DB) (*sql.DB, bool) {
+	for db != nil {
+		if sqlDB, ok := db.(*sql.DB); ok {
+			return sqlDB, true
+		}
+		wrapper, ok := db.(interface{ unwrap() DB })
+		if !ok {
+			break
+		}
+		db = wrapper.unwrap()
+	}
+	return nil, false
+}
+
+// queryHooks returns the QueryHooks of a DB, innermost hooks first.
+func queryHooks(db DB) []QueryHook {
+	var hooks []QueryHook
+	for db != nil {
+		switch db := db.(type) {
+		case *hookDB:
+			hooks = append(append([]QueryHook{}, db.hooks...), hooks...)
+		case *hookLoggerDB:
+			hooks = append(append([]QueryHook{}, db.hooks...), hooks...)
+		}
+		wrapper, ok := db.(interface{ unwrap() DB })
+		if !ok {
+			break
+		}
+		db = wrapper.unwrap()
+	}
+	return hooks
+}
+
+// Interceptor intercepts the queries executed through a DB wrapped by
+// WithInterceptors. It can be used to veto queries, record custom metrics or
+// translate driver errors into sq errors (see TranslateErrors).
+type Interceptor interface {
+	// BeforeQuery is called before a query is executed, after the query hooks
+	// have run. The context it returns is used to execute the query and is
+	// passed to AfterQuery. Returning an error vetoes the query, in which
+	// case AfterQuery is not called.
+	BeforeQuery(ctx context.Context, queryStats QueryStats) (context.Context, error)
+
+	// AfterQuery is called after a query has been executed (for fetches, once
+	// the results have been exhausted or the cursor is closed) with
+	// queryStats.Err set to the error of the query, if any. The error it
+	// returns replaces the error of the query, so an Interceptor that does
+	// not translate errors should return queryStats.Err unchanged.
+	AfterQuery(ctx context.Context, queryStats QueryStats) error
+}
+
+// WithInterceptors wraps a DB such that every query executed through it is
+// intercepted by the interceptors. BeforeQuery is called in order and
+// AfterQuery is called in reverse order. Interceptors of a DB that is already
+// wrapped by WithInterceptors are called before the new interceptors.
+//
+//	db = sq.WithInterceptors(db, sq.TranslateErrors())
+func WithInterceptors(db DB, interceptors ...Interceptor) DB {
+	if logger, ok := db.(SqLogger); ok {
+		return &hookLoggerDB{DB: db, SqLogger: logger, interceptors: interceptors}
+	}
+	return &hookDB{DB: db, interceptors: interceptors}
+}
+
+// queryInterceptors returns the Interceptors of a DB, innermost interceptors
+// first.
+func queryInterceptors(db DB) []Interceptor {
+	var interceptors []Interceptor
+	for db != nil {
+		switch db := db.(type) {
+		case *hookDB:
+			interceptors = append(append([]Interceptor{}, db.interceptors...), interceptors...)
+		case *hookLoggerDB:
+			interceptors = append(append([]Interceptor{}, db.interceptors...), interceptors...)
+		}
+		wrapper, ok := db.(interface{ unwrap() DB })
+		if !ok {
+			break
+		}
+		db = wrapper.unwrap()
+	}
+	return interceptors
+}
+
+// beforeQuery calls BeforeQuery on each Interceptor in order.
+func beforeQuery(ctx context.Context, interceptors []Interceptor, queryStats QueryStats) (context.Context, error) {
+	var err error
+	for _, interceptor := range interceptors {
+		ctx, err = interceptor.BeforeQuery(ctx, queryStats)
+		if err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+// afterQuery calls AfterQuery on each Interceptor in reverse order, threading
+// the (possibly translated) error from one Interceptor to the next.
+func afterQuery(ctx context.Context, interceptors []Interceptor, queryStats QueryStats) error {
+	queryStats.setErrorKind(ctx)
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		queryStats.Err = interceptors[i].AfterQuery(ctx, queryStats)
+	}
+	return queryStats.Err
+}
+
+// runQueryHooks passes a query and its args through the QueryHooks of a DB.
+func runQueryHooks(ctx context.Context, db DB, dialect string, query string, args []any) (string, []any, error) {
+	newQuery, newArgs := query, args
+	for _, hook := range queryHooks(db) {
+		var err error
+		newQuery, newArgs, err = hook(ctx, dialect, newQuery, newArgs)
+		if err != nil {
+			// Return the original query and args so that they can be logged.
+			return query, args, fmt.Errorf("query hook: %w", err)
+		}
+	}
+	return newQuery, newArgs, nil
+}
+
+// argsCanBePooled reports whether nothing but the database/sql DB sees the
+// args of a query, so that they can be returned to the slicepool once the
+// query has run. Loggers (which may log asynchronously), interceptors and
+// query hooks may hold on to the args, as may DB implementations other than
+// *sql.DB, *sql.Conn and *sql.Tx.
+func argsCanBePooled(db DB, logger SqLogger, interceptors []Interceptor) bool {
+	if logger != nil || len(interceptors) > 0 {
+		return false
+	}
+	for db != nil {
+		switch db.(type) {
+		case *sql.DB, *sql.Conn, *sql.Tx:
+			return true
+		case *hookDB, *hookLoggerDB:
+			return false
+		}
+		wrapper, ok := db.(interface{ unwrap() DB })
+		if !ok {
+			break
+		}
+		db = wrapper.unwrap()
+	}
+	return false
+}
+
+// InferDialect makes a best-effort guess of the dialect of a DB. The dialect
+// of a DB created with NewDB is returned as-is. For an *sql.DB (or a DB
+// wrapping one, such as the DB returned by Log) the dialect is
+// inferred from the package of its driver (e.g. github.com/lib/pq,
+// github.com/mattn/go-sqlite3). Otherwise, version queries are run against
+// the database until one of them succeeds. If the dialect cannot be
+// inferred an error is returned and the dialect should be configured
+// explicitly.
+//
+//	db, err := sql.Open("postgres", dsn)
+//	dialect, err := sq.InferDialect(db)
+//	sqDB := sq.NewDB(db, dialect)
+//
+// The version queries that fail abort the current transaction in postgres,
+// so InferDialect should not be called with an *sql.Tx.
+func InferDialect(db DB) (string, error) {
+	return InferDialectContext(context.Background(), db)
+}
+
+// InferDialectContext is like InferDialect but additionally requires a
+// context.Context.
+func InferDialectContext(ctx context.Context, db DB) (string, error) {
+	if db == nil {
+		return "", fmt.Errorf("db is nil")
+	}
+	if db, ok := db.(interface{ SqDialect() string }); ok && db.SqDialect() != "" {
+		return db.SqDialect(), nil
+	}
+	if sqlDB, ok := unwrapSQLDB(db); ok {
+		if dialect := driverDialect(sqlDB.Driver()); dialect != "" {
+			return dialect, nil
+		}
+	}
+	queryVersion := func(query string) (version string, err error) {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return "", err
+		}
+		defer rows.Close()
+		if rows.Next() {
+			err = rows.Scan(&version)
+			if err != nil {
+				return "", err
+			}
+		}
+		return version, rows.Close()
+	}
+	// Only sqlite has sqlite_version().
+	_, err := queryVersion("SELECT sqlite_version()")
+	if err == nil {
+		return DialectSQLite, nil
+	}
+	// postgres and mysql have version(), sqlserver doesn't. The mysql
+	// version() is only a version number (with a -MariaDB suffix for
+	// mariadb), the server name is in @@version_comment.
+	version, err := queryVersion("SELECT version()")
+	if err == nil {
+		if strings.Contains(version, "PostgreSQL") {
+			return DialectPostgres, nil
+		}
+		if strings.Contains(version, "MariaDB") {
+			return DialectMySQL, nil
+		}
+		versionComment, err := queryVersion("SELECT @@version_comment")
+		if err == nil && (strings.Contains(versionComment, "MySQL") || strings.Contains(versionComment, "MariaDB")) {
+			return DialectMySQL, nil
+		}
+		return "", fmt.Errorf("unable to infer the dialect of %T from version %q, please set the dialect explicitly", db, version)
+	}
+	version, err = queryVersion("SELECT @@VERSION")
+	if err == nil && strings.Contains(version, "Microsoft SQL Server") {
+		return DialectSQLServer, nil
+	}
+	return "", fmt.Errorf("unable to infer the dialect of %T, please set the dialect explicitly", db)
+}
+
+// driverDialect returns the dialect of a database driver based on the package
+// path of the driver, or an empty string if it is not known.
+func driverDialect(d driver.Driver) string {
+	typ := reflect.TypeOf(d)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return ""
+	}
+	pkgPath := strings.ToLower(typ.PkgPath())
+	switch {
+	case strings.Contains(pkgPath, "sqlite"):
+		return DialectSQLite
+	case strings.Contains(pkgPath, "lib/pq"), strings.Contains(pkgPath, "pgx"), strings.Contains(pkgPath, "postgres"):
+		return DialectPostgres
+	case strings.Contains(pkgPath, "mysql"):
+		return DialectMySQL
+	case strings.Contains(pkgPath, "mssql"), strings.Contains(pkgPath, "sqlserver"):
+		return DialectSQLServer
+	}
+	return ""
+}
+
+// dbDialect returns the dialect of a DB created with NewDB, or the
+// DefaultDialect otherwise.
+func dbDialect(db DB) string {
+	if db, ok := db.(interface{ SqDialect() string }); ok && db.SqDialect() != "" {
+		return db.SqDialect()
+	}
+	defaultDialect := DefaultDialect.Load()
+	if defaultDialect != nil {
+		return *defaultDialect
+	}
+	return ""
+}
+
+type allowDialectMismatchKey struct{}
+
+// AllowDialectMismatch returns a copy of ctx in which queries may be run on a
+// DB whose dialect (see NewDB and DefaultDialect) differs from the dialect of
+// the query. Without it, running for example a Postgres query on a DB
+// configured as MySQL fails instead of sending SQL the database may
+// misinterpret.
+func AllowDialectMismatch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowDialectMismatchKey{}, true)
+}
+
+// checkDialect returns an error if the query has a dialect that differs from
+// the dialect of the DB, unless the mismatch is allowed by ctx.
+func checkDialect(ctx context.Context, db DB, queryDialect string) error {
+	if queryDialect == "" {
+		return nil
+	}
+	dialect := dbDialect(db)
+	if dialect == "" || dialect == queryDialect {
+		return nil
+	}
+	if ctx != nil && ctx.Value(allowDialectMismatchKey{}) != nil {
+		return nil
+	}
+	return fmt.Errorf("%s query cannot be run on a %s DB (use sq.AllowDialectMismatch to run it anyway)", queryDialect, dialect)
+}
+
+// checkRowMapperType returns an error if T (or what T points to) is a table
+// struct. A common mistake is to write a rowmapper that returns the table
+// struct itself (e.g. func(row *sq.Row) ACTOR), but table structs only
+// describe the fields of a table and cannot hold the values of a row.
+func checkRowMapperType[T any]() error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ.NumField() == 0 || typ.Field(0).Type != reflect.TypeOf(TableStruct{}) {
+		return nil
+	}
+	return fmt.Errorf("rowmapper returns the table struct %[1]s, which describes the fields of a table but cannot hold the values of a row;"+
+		" return a plain data struct instead (sq.GenerateRowMapper[T](w, sq.New[%[1]s](\"\")) can generate the rowmapper for it)", typ)
+}
+
+// Ping checks that the database is reachable. If the DB (or the DB wrapped by
+// NewDB) has a PingContext method, like *sql.DB and *sql.Conn, it is used.
+// Otherwise a SELECT 1 query is run.
+func Ping(db DB) error {
+	return PingContext(context.Background(), db)
+}
+
+// PingContext is like Ping but additionally requires a context.Context.
+func PingContext(ctx context.Context, db DB) error {
+	if db == nil {
+		return fmt.Errorf("db is nil")
+	}
+	for {
+		if pinger, ok := db.(interface{ PingContext(context.Context) error }); ok {
+			return pinger.PingContext(ctx)
+		}
+		wrapper, ok := db.(interface{ unwrap() DB })
+		if !ok {
+			break
+		}
+		db = wrapper.unwrap()
+	}
+	rows, err := db.QueryContext(ctx, "SELECT 1")
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// DBStats returns the connection pool statistics of the DB (or the DB wrapped
+// by NewDB). The second result is false if the DB does not keep statistics
+// e.g. it is an *sql.Tx or *sql.Conn.
+func DBStats(db DB) (sql.DBStats, bool) {
+	for db != nil {
+		if statser, ok := db.(interface{ Stats() sql.DBStats }); ok {
+			return statser.Stats(), true
+		}
+		wrapper, ok := db.(interface{ unwrap() DB })
+		if !ok {
+			break
+		}
+		db = wrapper.unwrap()
+	}
+	return sql.DBStats{}, false
+}
+
+// WaitForDB pings the database until it is reachable or the context is done,
+// sleeping backoff(attempt) between attempts (attempt starts at 1). It is
+// meant for service startup, where the database may still be starting up. If
+// backoff is nil, the wait starts at 100ms and doubles after every attempt up
+// to a maximum of 5s.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	defer cancel()
+//	err := sq.WaitForDB(ctx, db, nil)
+func WaitForDB(ctx context.Context, db DB, backoff func(attempt int) time.Duration) error {
+	if backoff == nil {
+		backoff = func(attempt int) time.Duration {
+			wait := 100 * time.Millisecond
+			for i := 1; i < attempt && wait < 5*time.Second; i++ {
+				wait *= 2
+			}
+			if wait > 5*time.Second {
+				wait = 5 * time.Second
+			}
+			return wait
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		err := PingContext(ctx, db)
+		if err == nil {
+			return nil
+		}
+		timer := time.NewTimer(backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("WaitForDB: gave up after %d attempts: %w (last error: %v)", attempt, ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
 // A Cursor represents a database cursor.
 type Cursor[T any] struct {
 	ctx           context.Context
@@ -27,6 +505,12 @@ type Cursor[T any] struct {
 	logged        int32
 	fieldNames    []string
 	resultsBuffer *bytes.Buffer
+	// usage and usageStartedAt are only set for compiled and prepared
+	// fetches.
+	usage          *queryUsage
+	usageStartedAt time.Time
+	interceptors   []Interceptor
+	release        func()
 }
 
 // FetchCursor returns a new cursor.
@@ -49,12 +533,17 @@ func fetchCursor[T any](ctx context.Context, db DB, query Query, rowmapper func(
 	if rowmapper == nil {
 		return nil, fmt.Errorf("rowmapper is nil")
 	}
+	err = checkRowMapperType[T]()
+	if err != nil {
+		return nil, err
+	}
+	err = checkDialect(ctx, db, query.GetDialect())
+	if err != nil {
+		return nil, err
+	}
 	dialect := query.GetDialect()
 	if dialect == "" {
-		defaultDialect := DefaultDialect.Load()
-		if defaultDialect != nil {
-			dialect = *defaultDialect
-		}
+		dialect = dbDialect(db)
 	}
 	// If we can't set the fetchable fields, the query is static.
 	_, ok := query.SetFetchableFields(nil)
@@ -71,11 +560,13 @@ func fetchCursor[T any](ctx context.Context, db DB, query Query, rowmapper func(
 			RowCount: sql.NullInt64{Valid: true},
 		},
 	}
+	cursor.queryStats.setContextIDs(ctx)
 
 	// If the query is dynamic, call the rowmapper to populate row.fields and
 	// row.scanDest. Then, insert those fields back into the query.
 	if !cursor.row.queryIsStatic {
 		defer mapperFunctionPanicked(&err)
+		cursor.row.initScanDest(0)
 		_ = cursor.rowmapper(cursor.row)
 		query, _ = query.SetFetchableFields(cursor.row.fields)
 	}
@@ -84,6 +575,8 @@ func fetchCursor[T any](ctx context.Context, db DB, query Query, rowmapper func(
 	buf := bufpool.Get().(*bytes.Buffer)
 	buf.Reset()
 	defer bufpool.Put(buf)
+	args := getSlice()
+	cursor.queryStats.Args = *args
 	err = query.WriteSQL(ctx, dialect, buf, &cursor.queryStats.Args, cursor.queryStats.Params)
 	cursor.queryStats.Query = buf.String()
 	if err != nil {
@@ -102,6 +595,7 @@ func fetchCursor[T any](ctx context.Context, db DB, query Query, rowmapper func(
 			}
 		}
 	}
+	cursor.logger = withQueryStatsDest(ctx, cursor.logger)
 	if cursor.logger != nil {
 		cursor.logger.SqLogSettings(ctx, &cursor.logSettings)
 		if cursor.logSettings.IncludeCaller {
@@ -109,14 +603,53 @@ func fetchCursor[T any](ctx context.Context, db DB, query Query, rowmapper func(
 		}
 	}
 
+	// Run query hooks.
+	cursor.queryStats.Query, cursor.queryStats.Args, cursor.queryStats.Err = runQueryHooks(ctx, db, cursor.queryStats.Dialect, cursor.queryStats.Query, cursor.queryStats.Args)
+	if cursor.queryStats.Err != nil {
+		cursor.log()
+		return nil, cursor.queryStats.Err
+	}
+
+	cursor.queryStats.setQuerySize()
+
+	// Run interceptors.
+	interceptors := queryInterceptors(db)
+	ctx, cursor.queryStats.Err = beforeQuery(ctx, interceptors, cursor.queryStats)
+	if cursor.queryStats.Err != nil {
+		cursor.log()
+		return nil, cursor.queryStats.Err
+	}
+	cursor.ctx, cursor.interceptors = ctx, interceptors
+
+	// Wait for a free slot if the DB is Limited.
+	cursor.release, cursor.queryStats.WaitTime, cursor.queryStats.Err = dbSemaphore(db).acquire(ctx)
+	if cursor.queryStats.Err != nil {
+		cursor.log()
+		return nil, cursor.queryStats.Err
+	}
+	release := cursor.release
+	defer func() {
+		if err != nil {
+			release()
+		}
+	}()
+
 	// Run query.
 	if cursor.logSettings.IncludeTime {
 		cursor.queryStats.StartedAt = time.Now()
 	}
-	cursor.row.sqlRows, cursor.queryStats.Err = db.QueryContext(ctx, cursor.queryStats.Query, cursor.queryStats.Args...)
+	cursor.queryStats.Err = dbRetryPolicy(db).run(ctx, cursor.queryStats.Query, &cursor.queryStats.Attempts, func() (err error) {
+		cursor.row.sqlRows, err = db.QueryContext(ctx, cursor.queryStats.Query, cursor.queryStats.Args...)
+		return err
+	})
 	if cursor.logSettings.IncludeTime {
 		cursor.queryStats.TimeTaken = time.Since(cursor.queryStats.StartedAt)
 	}
+	if argsCanBePooled(db, cursor.logger, interceptors) {
+		*args = cursor.queryStats.Args
+		putSlice(args)
+		cursor.queryStats.Args = nil
+	}
 	if cursor.queryStats.Err != nil {
 		cursor.log()
 		return nil, cursor.queryStats.Err
@@ -126,23 +659,10 @@ func fetchCursor[T any](ctx context.Context, db DB, query Query, rowmapper func(
 	// the query and can allocate the values slice and scanDest slice for
 	// scanning later.
 	if cursor.row.queryIsStatic {
-		cursor.row.columns, err = cursor.row.sqlRows.Columns()
-		if err != nil {
-			return nil, err
-		}
-		cursor.row.columnTypes, err = cursor.row.sqlRows.ColumnTypes()
+		err = cursor.row.initStaticColumns()
 		if err != nil {
 			return nil, err
 		}
-		cursor.row.columnIndex = make(map[string]int)
-		for index, column := range cursor.row.columns {
-			cursor.row.columnIndex[column] = index
-		}
-		cursor.row.values = make([]any, len(cursor.row.columns))
-		cursor.row.scanDest = make([]any, len(cursor.row.columns))
-		for index := range cursor.row.values {
-			cursor.row.scanDest[index] = &cursor.row.values[index]
-		}
 	}
 
 	// Allocate the resultsBuffer.
@@ -159,6 +679,9 @@ func (cursor *Cursor[T]) Next() bool {
 	if hasNext {
 		cursor.queryStats.RowCount.Int64++
 	} else {
+		if err := cursor.row.sqlRows.Err(); err != nil {
+			cursor.queryStats.Err = err
+		}
 		cursor.log()
 	}
 	return hasNext
@@ -172,7 +695,19 @@ func (cursor *Cursor[T]) Result() (result T, err error) {
 	err = cursor.row.sqlRows.Scan(cursor.row.scanDest...)
 	if err != nil {
 		cursor.log()
-		fieldMappings := getFieldMappings(cursor.queryStats.Dialect, cursor.row.fields, cursor.row.scanDest)
+		// The columns of dynamic queries are not fetched beforehand, fetch
+		// them now so that they can be shown next to the scan destinations.
+		columns, columnTypes := cursor.row.columns, cursor.row.columnTypes
+		if columns == nil {
+			columns, _ = cursor.row.sqlRows.Columns()
+		}
+		if columnTypes == nil {
+			columnTypes, _ = cursor.row.sqlRows.ColumnTypes()
+		}
+		fieldMappings := getFieldMappings(cursor.queryStats.Dialect, cursor.row.fields, cursor.row.scanDest, columns, columnTypes)
+		if columns != nil && len(columns) != len(cursor.row.scanDest) {
+			return result, fmt.Errorf("please check if your mapper function is correct (it scans %d fields but the query returns %d columns):%s\n%w", len(cursor.row.scanDest), len(columns), fieldMappings, err)
+		}
 		return result, fmt.Errorf("please check if your mapper function is correct:%s\n%w", fieldMappings, err)
 	}
 	// If results should be logged, write the row into the resultsBuffer.
@@ -206,6 +741,14 @@ func (cursor *Cursor[T]) log() {
 	if !atomic.CompareAndSwapInt32(&cursor.logged, 0, 1) {
 		return
 	}
+	if cursor.release != nil {
+		cursor.release()
+	}
+	cursor.queryStats.Err = afterQuery(cursor.ctx, cursor.interceptors, cursor.queryStats)
+	cursor.queryStats.setErrorKind(cursor.ctx)
+	if cursor.usage != nil {
+		cursor.usage.record(time.Since(cursor.usageStartedAt), cursor.queryStats.RowCount.Int64, cursor.queryStats.Err)
+	}
 	if cursor.resultsBuffer != nil {
 		cursor.queryStats.Results = cursor.resultsBuffer.String()
 		bufpool.Put(cursor.resultsBuffer)
@@ -220,13 +763,19 @@ func (cursor *Cursor[T]) log() {
 	}
 }
 
-// Close closes the cursor.
+// Close closes the cursor. The cursor's results must not be accessed after
+// it is closed.
 func (cursor *Cursor[T]) Close() error {
 	cursor.log()
+	cursor.row.releaseScanDest()
 	if err := cursor.row.sqlRows.Close(); err != nil {
 		return err
 	}
 	if err := cursor.row.sqlRows.Err(); err != nil {
+		// Return the error as translated by the interceptors, if any.
+		if cursor.queryStats.Err != nil {
+			return cursor.queryStats.Err
+		}
 		return err
 	}
 	return nil
@@ -273,6 +822,57 @@ func FetchAllContext[T any](ctx context.Context, db DB, query Query, rowmapper f
 	return cursorResults(cursor)
 }
 
+// FetchInsert runs the given INSERT query and returns the inserted rows. A
+// RETURNING clause containing exactly the fields used by the rowmapper is
+// appended to the query, so the inserted rows are read back in the same
+// round trip (for sqlserver, an OUTPUT clause is used instead). Only postgres,
+// sqlite and sqlserver are supported. Rows that were not inserted (e.g.
+// because of ON CONFLICT DO NOTHING) are not returned.
+func FetchInsert[T any](db DB, query Query, rowmapper func(*Row) T) ([]T, error) {
+	return fetchInsert(context.Background(), db, query, rowmapper, 1)
+}
+
+// FetchInsertContext is like FetchInsert but additionally requires a
+// context.Context.
+func FetchInsertContext[T any](ctx context.Context, db DB, query Query, rowmapper func(*Row) T) ([]T, error) {
+	return fetchInsert(ctx, db, query, rowmapper, 1)
+}
+
+func fetchInsert[T any](ctx context.Context, db DB, query Query, rowmapper func(*Row) T, skip int) ([]T, error) {
+	var insertQuery InsertQuery
+	switch q := query.(type) {
+	case InsertQuery:
+		insertQuery = q
+	case SQLiteInsertQuery:
+		insertQuery = InsertQuery(q)
+	case PostgresInsertQuery:
+		insertQuery = InsertQuery(q)
+	case MySQLInsertQuery:
+		insertQuery = InsertQuery(q)
+	case SQLServerInsertQuery:
+		insertQuery = InsertQuery(q)
+	default:
+		return nil, fmt.Errorf("FetchInsert only supports INSERT queries, got %T", query)
+	}
+	if insertQuery.Dialect == "" {
+		insertQuery.Dialect = dbDialect(db)
+	}
+	switch insertQuery.Dialect {
+	case DialectPostgres, DialectSQLite, DialectSQLServer:
+	default:
+		return nil, fmt.Errorf("FetchInsert: %s does not support RETURNING", insertQuery.Dialect)
+	}
+	if len(insertQuery.ReturningFields) > 0 {
+		return nil, fmt.Errorf("FetchInsert: INSERT query already has a RETURNING clause")
+	}
+	cursor, err := fetchCursor(ctx, db, insertQuery, rowmapper, skip+1)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+	return cursorResults(cursor)
+}
+
 // CompiledFetch is the result of compiling a Query down into a query string
 // and args slice. A CompiledFetch can be safely executed in parallel.
 type CompiledFetch[T any] struct {
@@ -285,6 +885,7 @@ type CompiledFetch[T any] struct {
 	// columns are in the query and it must be determined at runtime after
 	// running the query.
 	queryIsStatic bool
+	usage         *queryUsage
 }
 
 // NewCompiledFetch returns a new CompiledFetch.
@@ -295,6 +896,7 @@ func NewCompiledFetch[T any](dialect string, query string, args []any, params ma
 		args:      args,
 		params:    params,
 		rowmapper: rowmapper,
+		usage:     getQueryUsage(dialect, query),
 	}
 }
 
@@ -311,6 +913,10 @@ func CompileFetchContext[T any](ctx context.Context, query Query, rowmapper func
 	if rowmapper == nil {
 		return nil, fmt.Errorf("rowmapper is nil")
 	}
+	err = checkRowMapperType[T]()
+	if err != nil {
+		return nil, err
+	}
 	dialect := query.GetDialect()
 	if dialect == "" {
 		defaultDialect := DefaultDialect.Load()
@@ -348,6 +954,7 @@ func CompileFetchContext[T any](ctx context.Context, query Query, rowmapper func
 	if err != nil {
 		return nil, err
 	}
+	compiledFetch.usage = getQueryUsage(compiledFetch.dialect, compiledFetch.query)
 	return compiledFetch, nil
 }
 
@@ -365,6 +972,10 @@ func (compiledFetch *CompiledFetch[T]) fetchCursor(ctx context.Context, db DB, p
 	if db == nil {
 		return nil, fmt.Errorf("db is nil")
 	}
+	err = checkDialect(ctx, db, compiledFetch.dialect)
+	if err != nil {
+		return nil, err
+	}
 	cursor = &Cursor[T]{
 		ctx:       ctx,
 		rowmapper: compiledFetch.rowmapper,
@@ -379,10 +990,12 @@ func (compiledFetch *CompiledFetch[T]) fetchCursor(ctx context.Context, db DB, p
 			Params:  compiledFetch.params,
 		},
 	}
+	cursor.queryStats.setContextIDs(ctx)
 
 	// Call the rowmapper to populate row.scanDest.
 	if !cursor.row.queryIsStatic {
 		defer mapperFunctionPanicked(&err)
+		cursor.row.initScanDest(0)
 		_ = cursor.rowmapper(cursor.row)
 	}
 
@@ -405,6 +1018,7 @@ func (compiledFetch *CompiledFetch[T]) fetchCursor(ctx context.Context, db DB, p
 			}
 		}
 	}
+	cursor.logger = withQueryStatsDest(ctx, cursor.logger)
 	if cursor.logger != nil {
 		cursor.logger.SqLogSettings(ctx, &cursor.logSettings)
 		if cursor.logSettings.IncludeCaller {
@@ -412,15 +1026,54 @@ func (compiledFetch *CompiledFetch[T]) fetchCursor(ctx context.Context, db DB, p
 		}
 	}
 
+	// Run query hooks.
+	cursor.queryStats.Query, cursor.queryStats.Args, cursor.queryStats.Err = runQueryHooks(ctx, db, cursor.queryStats.Dialect, cursor.queryStats.Query, cursor.queryStats.Args)
+	if cursor.queryStats.Err != nil {
+		cursor.log()
+		return nil, cursor.queryStats.Err
+	}
+
+	cursor.queryStats.setQuerySize()
+
+	// Run interceptors.
+	interceptors := queryInterceptors(db)
+	ctx, cursor.queryStats.Err = beforeQuery(ctx, interceptors, cursor.queryStats)
+	if cursor.queryStats.Err != nil {
+		return nil, cursor.queryStats.Err
+	}
+	cursor.ctx, cursor.interceptors = ctx, interceptors
+
+	// Wait for a free slot if the DB is Limited.
+	cursor.release, cursor.queryStats.WaitTime, cursor.queryStats.Err = dbSemaphore(db).acquire(ctx)
+	if cursor.queryStats.Err != nil {
+		cursor.queryStats.Err = afterQuery(ctx, cursor.interceptors, cursor.queryStats)
+		return nil, cursor.queryStats.Err
+	}
+	release := cursor.release
+	defer func() {
+		if err != nil {
+			release()
+		}
+	}()
+
 	// Run query.
+	cursor.usage = compiledFetch.usage
+	cursor.usageStartedAt = time.Now()
 	if cursor.logSettings.IncludeTime {
 		cursor.queryStats.StartedAt = time.Now()
 	}
-	cursor.row.sqlRows, cursor.queryStats.Err = db.QueryContext(ctx, cursor.queryStats.Query, cursor.queryStats.Args...)
+	cursor.queryStats.Err = dbRetryPolicy(db).run(ctx, cursor.queryStats.Query, &cursor.queryStats.Attempts, func() (err error) {
+		cursor.row.sqlRows, err = db.QueryContext(ctx, cursor.queryStats.Query, cursor.queryStats.Args...)
+		return err
+	})
 	if cursor.logSettings.IncludeTime {
 		cursor.queryStats.TimeTaken = time.Since(cursor.queryStats.StartedAt)
 	}
 	if cursor.queryStats.Err != nil {
+		if cursor.usage != nil {
+			cursor.usage.record(time.Since(cursor.usageStartedAt), 0, cursor.queryStats.Err)
+		}
+		cursor.queryStats.Err = afterQuery(ctx, cursor.interceptors, cursor.queryStats)
 		return nil, cursor.queryStats.Err
 	}
 
@@ -428,23 +1081,10 @@ func (compiledFetch *CompiledFetch[T]) fetchCursor(ctx context.Context, db DB, p
 	// the query and can allocate the values slice and scanDest slice for
 	// scanning later.
 	if cursor.row.queryIsStatic {
-		cursor.row.columns, err = cursor.row.sqlRows.Columns()
+		err = cursor.row.initStaticColumns()
 		if err != nil {
 			return nil, err
 		}
-		cursor.row.columnTypes, err = cursor.row.sqlRows.ColumnTypes()
-		if err != nil {
-			return nil, err
-		}
-		cursor.row.columnIndex = make(map[string]int)
-		for index, column := range cursor.row.columns {
-			cursor.row.columnIndex[column] = index
-		}
-		cursor.row.values = make([]any, len(cursor.row.columns))
-		cursor.row.scanDest = make([]any, len(cursor.row.columns))
-		for index := range cursor.row.values {
-			cursor.row.scanDest[index] = &cursor.row.values[index]
-		}
 	}
 
 	// Allocate the resultsBuffer.
@@ -513,6 +1153,20 @@ func (compiledFetch *CompiledFetch[T]) GetSQL() (dialect string, query string, a
 	return dialect, query, args, params, compiledFetch.rowmapper
 }
 
+// Stats returns a snapshot of the usage counters of the CompiledFetch.
+func (compiledFetch *CompiledFetch[T]) Stats() QueryUsage {
+	return compiledFetch.usage.snapshot()
+}
+
+// CheckParams checks that params provides exactly the named parameters
+// expected by the CompiledFetch. Params omitted from FetchCursor, FetchOne or
+// FetchAll silently fall back to their default values and unknown params are
+// ignored, so CheckParams can be used to catch such mistakes before hitting
+// the database.
+func (compiledFetch *CompiledFetch[T]) CheckParams(params Params) error {
+	return checkParams(compiledFetch.params, params)
+}
+
 // Prepare creates a PreparedFetch from a CompiledFetch by preparing it on
 // the given DB.
 func (compiledFetch *CompiledFetch[T]) Prepare(db DB) (*PreparedFetch[T], error) {
@@ -529,11 +1183,22 @@ func (compiledFetch *CompiledFetch[T]) PrepareContext(ctx context.Context, db DB
 	if db == nil {
 		return nil, fmt.Errorf("db is nil")
 	}
-	preparedFetch.stmt, err = db.PrepareContext(ctx, compiledFetch.query)
+	err = checkDialect(ctx, db, compiledFetch.dialect)
+	if err != nil {
+		return nil, err
+	}
+	preparedFetch.compiledFetch.query, _, err = runQueryHooks(ctx, db, compiledFetch.dialect, compiledFetch.query, nil)
+	if err != nil {
+		return nil, err
+	}
+	preparedFetch.stmt, err = newPreparedStmt(ctx, db, preparedFetch.compiledFetch.query)
 	if err != nil {
 		return nil, err
 	}
 	preparedFetch.logger, _ = db.(SqLogger)
+	preparedFetch.interceptors = queryInterceptors(db)
+	preparedFetch.retryPolicy = dbRetryPolicy(db)
+	preparedFetch.sem = dbSemaphore(db)
 	if preparedFetch.logger == nil {
 		logQuery, _ := defaultLogQuery.Load().(func(context.Context, QueryStats))
 		if logQuery != nil {
@@ -548,10 +1213,18 @@ func (compiledFetch *CompiledFetch[T]) PrepareContext(ctx context.Context, db DB
 }
 
 // PreparedFetch is the result of preparing a CompiledFetch on a DB.
+//
+// If the database invalidates the prepared statement (e.g. after a failover,
+// or Postgres reporting "cached plan must not change result type" after a
+// schema change), the statement is automatically re-prepared and the query is
+// retried once.
 type PreparedFetch[T any] struct {
 	compiledFetch *CompiledFetch[T]
-	stmt          *sql.Stmt
+	stmt          *preparedStmt
 	logger        SqLogger
+	interceptors  []Interceptor
+	retryPolicy   *RetryPolicy
+	sem           semaphore
 }
 
 // PrepareFetch returns a new PreparedFetch.
@@ -595,10 +1268,12 @@ func (preparedFetch *PreparedFetch[T]) fetchCursor(ctx context.Context, params P
 		},
 		logger: preparedFetch.logger,
 	}
+	cursor.queryStats.setContextIDs(ctx)
 
 	// If the query is dynamic, call the rowmapper to populate row.scanDest.
 	if !cursor.row.queryIsStatic {
 		defer mapperFunctionPanicked(&err)
+		cursor.row.initScanDest(0)
 		_ = cursor.rowmapper(cursor.row)
 	}
 
@@ -609,6 +1284,7 @@ func (preparedFetch *PreparedFetch[T]) fetchCursor(ctx context.Context, params P
 	}
 
 	// Setup logger.
+	cursor.logger = withQueryStatsDest(ctx, cursor.logger)
 	if cursor.logger != nil {
 		cursor.logger.SqLogSettings(ctx, &cursor.logSettings)
 		if cursor.logSettings.IncludeCaller {
@@ -616,15 +1292,47 @@ func (preparedFetch *PreparedFetch[T]) fetchCursor(ctx context.Context, params P
 		}
 	}
 
+	cursor.queryStats.setQuerySize()
+
+	// Run interceptors.
+	interceptors := preparedFetch.interceptors
+	ctx, cursor.queryStats.Err = beforeQuery(ctx, interceptors, cursor.queryStats)
+	if cursor.queryStats.Err != nil {
+		return nil, cursor.queryStats.Err
+	}
+	cursor.ctx, cursor.interceptors = ctx, interceptors
+
+	// Wait for a free slot if the DB is Limited.
+	cursor.release, cursor.queryStats.WaitTime, cursor.queryStats.Err = preparedFetch.sem.acquire(ctx)
+	if cursor.queryStats.Err != nil {
+		cursor.queryStats.Err = afterQuery(ctx, cursor.interceptors, cursor.queryStats)
+		return nil, cursor.queryStats.Err
+	}
+	release := cursor.release
+	defer func() {
+		if err != nil {
+			release()
+		}
+	}()
+
 	// Run query.
+	cursor.usage = preparedFetch.compiledFetch.usage
+	cursor.usageStartedAt = time.Now()
 	if cursor.logSettings.IncludeTime {
 		cursor.queryStats.StartedAt = time.Now()
 	}
-	cursor.row.sqlRows, cursor.queryStats.Err = preparedFetch.stmt.QueryContext(ctx, cursor.queryStats.Args...)
+	cursor.queryStats.Err = preparedFetch.retryPolicy.run(ctx, cursor.queryStats.Query, &cursor.queryStats.Attempts, func() (err error) {
+		cursor.row.sqlRows, err = preparedFetch.stmt.queryContext(ctx, cursor.queryStats.Args)
+		return err
+	})
 	if cursor.logSettings.IncludeTime {
 		cursor.queryStats.TimeTaken = time.Since(cursor.queryStats.StartedAt)
 	}
 	if cursor.queryStats.Err != nil {
+		if cursor.usage != nil {
+			cursor.usage.record(time.Since(cursor.usageStartedAt), 0, cursor.queryStats.Err)
+		}
+		cursor.queryStats.Err = afterQuery(ctx, cursor.interceptors, cursor.queryStats)
 		return nil, cursor.queryStats.Err
 	}
 
@@ -632,23 +1340,10 @@ func (preparedFetch *PreparedFetch[T]) fetchCursor(ctx context.Context, params P
 	// the query and can allocate the values slice and scanDest slice for
 	// scanning later.
 	if cursor.row.queryIsStatic {
-		cursor.row.columns, err = cursor.row.sqlRows.Columns()
+		err = cursor.row.initStaticColumns()
 		if err != nil {
 			return nil, err
 		}
-		cursor.row.columnTypes, err = cursor.row.sqlRows.ColumnTypes()
-		if err != nil {
-			return nil, err
-		}
-		cursor.row.columnIndex = make(map[string]int)
-		for index, column := range cursor.row.columns {
-			cursor.row.columnIndex[column] = index
-		}
-		cursor.row.values = make([]any, len(cursor.row.columns))
-		cursor.row.scanDest = make([]any, len(cursor.row.columns))
-		for index := range cursor.row.values {
-			cursor.row.scanDest[index] = &cursor.row.values[index]
-		}
 	}
 
 	// Allocate the resultsBuffer.
@@ -708,12 +1403,224 @@ func (preparedFetch *PreparedFetch[T]) GetCompiled() *CompiledFetch[T] {
 	return compiledFetch
 }
 
+// Stats returns a snapshot of the usage counters of the PreparedFetch. The
+// counters are shared with the CompiledFetch it was prepared from.
+func (preparedFetch *PreparedFetch[T]) Stats() QueryUsage {
+	return preparedFetch.compiledFetch.usage.snapshot()
+}
+
+// Reprepare discards the prepared statement of the PreparedFetch and prepares
+// it again on the DB it was originally prepared on. It is called
+// automatically when the database reports that the prepared statement is no
+// longer valid, but it can also be called explicitly e.g. after running a
+// migration.
+func (preparedFetch *PreparedFetch[T]) Reprepare() error {
+	return preparedFetch.ReprepareContext(context.Background())
+}
+
+// ReprepareContext is like Reprepare but additionally requires a
+// context.Context.
+func (preparedFetch *PreparedFetch[T]) ReprepareContext(ctx context.Context) error {
+	if preparedFetch.stmt == nil {
+		return fmt.Errorf("PreparedFetch was not prepared")
+	}
+	return preparedFetch.stmt.reprepare(ctx, nil)
+}
+
 // Close closes the PreparedFetch.
 func (preparedFetch *PreparedFetch[T]) Close() error {
 	if preparedFetch.stmt == nil {
 		return nil
 	}
-	return preparedFetch.stmt.Close()
+	return preparedFetch.stmt.close()
+}
+
+// preparedStmt is an *sql.Stmt that can be re-prepared on the DB it was
+// prepared on. If the DB is wrapped by WithoutPreparedStatements, or a
+// connection pooler is detected, stmt is nil and the query is run directly on
+// the DB instead.
+type preparedStmt struct {
+	db     DB
+	query  string
+	mu     sync.RWMutex
+	stmt   *sql.Stmt
+	closed bool
+}
+
+func newPreparedStmt(ctx context.Context, db DB, query string) (*preparedStmt, error) {
+	ps := &preparedStmt{db: db, query: query}
+	if preparedStatementsDisabled(db) {
+		return ps, nil
+	}
+	var err error
+	ps.stmt, err = db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+// get returns the current *sql.Stmt, or nil if the query is not prepared.
+func (ps *preparedStmt) get() *sql.Stmt {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return ps.stmt
+}
+
+// reprepare replaces the *sql.Stmt with a freshly prepared one. If stale is
+// not nil and has already been replaced by another goroutine, reprepare does
+// nothing. Queries that are not prepared are left as they are.
+func (ps *preparedStmt) reprepare(ctx context.Context, stale *sql.Stmt) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.closed {
+		return fmt.Errorf("prepared statement is closed")
+	}
+	if ps.stmt == nil || (stale != nil && stale != ps.stmt) {
+		return nil
+	}
+	stmt, err := ps.db.PrepareContext(ctx, ps.query)
+	if err != nil {
+		return fmt.Errorf("re-preparing statement: %w", err)
+	}
+	_ = ps.stmt.Close()
+	ps.stmt = stmt
+	return nil
+}
+
+// unprepare closes the *sql.Stmt so that from now on the query is run
+// directly on the DB.
+func (ps *preparedStmt) unprepare() {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.stmt != nil {
+		_ = ps.stmt.Close()
+		ps.stmt = nil
+	}
+}
+
+// run calls fn with the current *sql.Stmt. If the statement has been
+// invalidated by the database, it is re-prepared and fn is called once more.
+// If a connection pooler that doesn't support prepared statements is
+// detected, the statement is discarded and fn is called with a nil
+// *sql.Stmt, which should run the query directly on the DB.
+func (ps *preparedStmt) run(ctx context.Context, fn func(stmt *sql.Stmt) error) error {
+	stmt := ps.get()
+	err := fn(stmt)
+	// Another goroutine may have replaced (and closed) the *sql.Stmt while
+	// fn was using it, in which case fn is called again with the
+	// replacement.
+	for err != nil && stmt != nil && isStmtClosedError(err) {
+		current := ps.get()
+		if current == stmt {
+			break
+		}
+		stmt = current
+		err = fn(stmt)
+	}
+	if err == nil || stmt == nil {
+		return err
+	}
+	if isPoolerError(err) {
+		ps.unprepare()
+		return fn(nil)
+	}
+	if !isStaleStatementError(err) {
+		return err
+	}
+	reprepareErr := ps.reprepare(ctx, stmt)
+	if reprepareErr != nil {
+		return fmt.Errorf("%w (%s)", err, reprepareErr.Error())
+	}
+	stmt = ps.get()
+	err = fn(stmt)
+	if err != nil && stmt != nil && isStaleStatementError(err) {
+		// A freshly prepared statement going missing means the statement
+		// was prepared on a different server connection than the one it is
+		// executed on, which is what a transaction pooler does.
+		ps.unprepare()
+		return fn(nil)
+	}
+	return err
+}
+
+// queryContext runs the query with the given args.
+func (ps *preparedStmt) queryContext(ctx context.Context, args []any) (rows *sql.Rows, err error) {
+	err = ps.run(ctx, func(stmt *sql.Stmt) (err error) {
+		if stmt == nil {
+			rows, err = ps.db.QueryContext(ctx, ps.query, args...)
+		} else {
+			rows, err = stmt.QueryContext(ctx, args...)
+		}
+		return err
+	})
+	return rows, err
+}
+
+// execContext executes the query with the given args.
+func (ps *preparedStmt) execContext(ctx context.Context, args []any) (result sql.Result, err error) {
+	err = ps.run(ctx, func(stmt *sql.Stmt) (err error) {
+		if stmt == nil {
+			result, err = ps.db.ExecContext(ctx, ps.query, args...)
+		} else {
+			result, err = stmt.ExecContext(ctx, args...)
+		}
+		return err
+	})
+	return result, err
+}
+
+func (ps *preparedStmt) close() error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.closed = true
+	if ps.stmt == nil {
+		return nil
+	}
+	return ps.stmt.Close()
+}
+
+// isStmtClosedError reports whether an error is the one returned by an
+// *sql.Stmt that has been closed.
+func isStmtClosedError(err error) bool {
+	return err != nil && err.Error() == "sql: statement is closed"
+}
+
+// isStaleStatementError reports whether an error indicates that a prepared
+// statement is no longer valid and has to be prepared again: the connection
+// it was prepared on is gone, or the database has invalidated it (usually
+// because of a schema change).
+func isStaleStatementError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		code := getDriverErrorCode(e)
+		switch code.sqlState {
+		case "26000": // postgres invalid_sql_statement_name
+			return true
+		}
+		switch code.number {
+		case 1243, // mysql ER_UNKNOWN_STMT_HANDLER
+			1615: // mysql ER_NEED_REPREPARE
+			return true
+		}
+		switch code.sqliteCode & 0xff {
+		case 17: // SQLITE_SCHEMA
+			return true
+		}
+	}
+	msg := err.Error()
+	for _, s := range []string{
+		"cached plan must not change result type", // postgres
+		"needs to be re-prepared",                 // mysql
+		"Unknown prepared statement handler",      // mysql
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
 }
 
 // Exec executes the given Query on the given DB.
@@ -733,22 +1640,26 @@ func exec(ctx context.Context, db DB, query Query, skip int) (result Result, err
 	if query == nil {
 		return result, fmt.Errorf("query is nil")
 	}
+	err = checkDialect(ctx, db, query.GetDialect())
+	if err != nil {
+		return result, err
+	}
 	dialect := query.GetDialect()
 	if dialect == "" {
-		defaultDialect := DefaultDialect.Load()
-		if defaultDialect != nil {
-			dialect = *defaultDialect
-		}
+		dialect = dbDialect(db)
 	}
 	queryStats := QueryStats{
 		Dialect: dialect,
 		Params:  make(map[string][]int),
 	}
+	queryStats.setContextIDs(ctx)
 
 	// Build query.
 	buf := bufpool.Get().(*bytes.Buffer)
 	buf.Reset()
 	defer bufpool.Put(buf)
+	args := getSlice()
+	queryStats.Args = *args
 	err = query.WriteSQL(ctx, dialect, buf, &queryStats.Args, queryStats.Params)
 	queryStats.Query = buf.String()
 	if err != nil {
@@ -768,12 +1679,14 @@ func exec(ctx context.Context, db DB, query Query, skip int) (result Result, err
 			}
 		}
 	}
+	logger = withQueryStatsDest(ctx, logger)
 	if logger != nil {
 		logger.SqLogSettings(ctx, &logSettings)
 		if logSettings.IncludeCaller {
 			queryStats.CallerFile, queryStats.CallerLine, queryStats.CallerFunction = caller(skip + 1)
 		}
 		defer func() {
+			queryStats.setErrorKind(ctx)
 			if logSettings.LogAsynchronously {
 				go logger.SqLogQuery(ctx, queryStats)
 			} else {
@@ -782,15 +1695,55 @@ func exec(ctx context.Context, db DB, query Query, skip int) (result Result, err
 		}()
 	}
 
+	// Run query hooks.
+	queryStats.Query, queryStats.Args, queryStats.Err = runQueryHooks(ctx, db, queryStats.Dialect, queryStats.Query, queryStats.Args)
+	if queryStats.Err != nil {
+		return result, queryStats.Err
+	}
+
+	queryStats.setQuerySize()
+
+	// Run interceptors.
+	interceptors := queryInterceptors(db)
+	ctx, queryStats.Err = beforeQuery(ctx, interceptors, queryStats)
+	if queryStats.Err != nil {
+		return result, queryStats.Err
+	}
+	if len(interceptors) > 0 {
+		defer func() {
+			if queryStats.Err == nil {
+				queryStats.Err = err
+			}
+			queryStats.Err = afterQuery(ctx, interceptors, queryStats)
+			err = queryStats.Err
+		}()
+	}
+
+	// Wait for a free slot if the DB is Limited.
+	var release func()
+	release, queryStats.WaitTime, queryStats.Err = dbSemaphore(db).acquire(ctx)
+	if queryStats.Err != nil {
+		return result, queryStats.Err
+	}
+	defer release()
+
 	// Run query.
 	if logSettings.IncludeTime {
 		queryStats.StartedAt = time.Now()
 	}
 	var sqlResult sql.Result
-	sqlResult, queryStats.Err = db.ExecContext(ctx, queryStats.Query, queryStats.Args...)
+	queryStats.Err = dbRetryPolicy(db).run(ctx, queryStats.Query, &queryStats.Attempts, func() (err error) {
+		sqlResult, err = db.ExecContext(ctx, queryStats.Query, queryStats.Args...)
+		return err
+	})
 	if logSettings.IncludeTime {
 		queryStats.TimeTaken = time.Since(queryStats.StartedAt)
 	}
+	if argsCanBePooled(db, logger, interceptors) {
+		*args = queryStats.Args
+		putSlice(args)
+		queryStats.Args = nil
+	}
 	if queryStats.Err != nil {
 		return result, queryStats.Err
 	}
@@ -804,6 +1757,7 @@ type CompiledExec struct {
 	query   string
 	args    []any
 	params  map[string][]int
+	usage   *queryUsage
 }
 
 // NewCompiledExec returns a new CompiledExec.
@@ -813,6 +1767,7 @@ func NewCompiledExec(dialect string, query string, args []any, params map[string
 		query:   query,
 		args:    args,
 		params:  params,
+		usage:   getQueryUsage(dialect, query),
 	}
 }
 
@@ -847,6 +1802,7 @@ func CompileExecContext(ctx context.Context, query Query) (*CompiledExec, error)
 	if err != nil {
 		return nil, err
 	}
+	compiledExec.usage = getQueryUsage(compiledExec.dialect, compiledExec.query)
 	return compiledExec, nil
 }
 
@@ -864,12 +1820,17 @@ func (compiledExec *CompiledExec) exec(ctx context.Context, db DB, params Params
 	if db == nil {
 		return result, fmt.Errorf("db is nil")
 	}
+	err = checkDialect(ctx, db, compiledExec.dialect)
+	if err != nil {
+		return result, err
+	}
 	queryStats := QueryStats{
 		Dialect: compiledExec.dialect,
 		Query:   compiledExec.query,
 		Args:    compiledExec.args,
 		Params:  compiledExec.params,
 	}
+	queryStats.setContextIDs(ctx)
 
 	// Setup logger.
 	var logSettings LogSettings
@@ -884,12 +1845,14 @@ func (compiledExec *CompiledExec) exec(ctx context.Context, db DB, params Params
 			}
 		}
 	}
+	logger = withQueryStatsDest(ctx, logger)
 	if logger != nil {
 		logger.SqLogSettings(ctx, &logSettings)
 		if logSettings.IncludeCaller {
 			queryStats.CallerFile, queryStats.CallerLine, queryStats.CallerFunction = caller(skip + 1)
 		}
 		defer func() {
+			queryStats.setErrorKind(ctx)
 			if logSettings.LogAsynchronously {
 				go logger.SqLogQuery(ctx, queryStats)
 			} else {
@@ -904,12 +1867,53 @@ func (compiledExec *CompiledExec) exec(ctx context.Context, db DB, params Params
 		return result, err
 	}
 
+	// Run query hooks.
+	queryStats.Query, queryStats.Args, queryStats.Err = runQueryHooks(ctx, db, queryStats.Dialect, queryStats.Query, queryStats.Args)
+	if queryStats.Err != nil {
+		return result, queryStats.Err
+	}
+
+	queryStats.setQuerySize()
+
+	// Run interceptors.
+	interceptors := queryInterceptors(db)
+	ctx, queryStats.Err = beforeQuery(ctx, interceptors, queryStats)
+	if queryStats.Err != nil {
+		return result, queryStats.Err
+	}
+	if len(interceptors) > 0 {
+		defer func() {
+			if queryStats.Err == nil {
+				queryStats.Err = err
+			}
+			queryStats.Err = afterQuery(ctx, interceptors, queryStats)
+			err = queryStats.Err
+		}()
+	}
+
+	// Wait for a free slot if the DB is Limited.
+	var release func()
+	release, queryStats.WaitTime, queryStats.Err = dbSemaphore(db).acquire(ctx)
+	if queryStats.Err != nil {
+		return result, queryStats.Err
+	}
+	defer release()
+
 	// Run query.
+	if compiledExec.usage != nil {
+		startedAt := time.Now()
+		defer func() {
+			compiledExec.usage.record(time.Since(startedAt), result.RowsAffected, err)
+		}()
+	}
 	if logSettings.IncludeTime {
 		queryStats.StartedAt = time.Now()
 	}
 	var sqlResult sql.Result
-	sqlResult, queryStats.Err = db.ExecContext(ctx, queryStats.Query, queryStats.Args...)
+	queryStats.Err = dbRetryPolicy(db).run(ctx, queryStats.Query, &queryStats.Attempts, func() (err error) {
+		sqlResult, err = db.ExecContext(ctx, queryStats.Query, queryStats.Args...)
+		return err
+	})
 	if logSettings.IncludeTime {
 		queryStats.TimeTaken = time.Since(queryStats.StartedAt)
 	}
@@ -935,6 +1939,19 @@ func (compiledExec *CompiledExec) GetSQL() (dialect string, query string, args [
 	return dialect, query, args, params
 }
 
+// Stats returns a snapshot of the usage counters of the CompiledExec.
+func (compiledExec *CompiledExec) Stats() QueryUsage {
+	return compiledExec.usage.snapshot()
+}
+
+// CheckParams checks that params provides exactly the named parameters
+// expected by the CompiledExec. Params omitted from Exec silently fall back to
+// their default values and unknown params are ignored, so CheckParams can be
+// used to catch such mistakes before hitting the database.
+func (compiledExec *CompiledExec) CheckParams(params Params) error {
+	return checkParams(compiledExec.params, params)
+}
+
 // Prepare creates a PreparedExec from a CompiledExec by preparing it on the
 // given DB.
 func (compiledExec *CompiledExec) Prepare(db DB) (*PreparedExec, error) {
@@ -947,11 +1964,22 @@ func (compiledExec *CompiledExec) PrepareContext(ctx context.Context, db DB) (*P
 	preparedExec := &PreparedExec{
 		compiledExec: NewCompiledExec(compiledExec.GetSQL()),
 	}
-	preparedExec.stmt, err = db.PrepareContext(ctx, compiledExec.query)
+	err = checkDialect(ctx, db, compiledExec.dialect)
+	if err != nil {
+		return nil, err
+	}
+	preparedExec.compiledExec.query, _, err = runQueryHooks(ctx, db, compiledExec.dialect, compiledExec.query, nil)
+	if err != nil {
+		return nil, err
+	}
+	preparedExec.stmt, err = newPreparedStmt(ctx, db, preparedExec.compiledExec.query)
 	if err != nil {
 		return nil, err
 	}
 	preparedExec.logger, _ = db.(SqLogger)
+	preparedExec.interceptors = queryInterceptors(db)
+	preparedExec.retryPolicy = dbRetryPolicy(db)
+	preparedExec.sem = dbSemaphore(db)
 	if preparedExec.logger == nil {
 		logQuery, _ := defaultLogQuery.Load().(func(context.Context, QueryStats))
 		if logQuery != nil {
@@ -968,8 +1996,11 @@ func (compiledExec *CompiledExec) PrepareContext(ctx context.Context, db DB) (*P
 // PrepareExec is the result of preparing a CompiledExec on a DB.
 type PreparedExec struct {
 	compiledExec *CompiledExec
-	stmt         *sql.Stmt
+	stmt         *preparedStmt
 	logger       SqLogger
+	interceptors []Interceptor
+	retryPolicy  *RetryPolicy
+	sem          semaphore
 }
 
 // PrepareExec returns a new PreparedExec.
@@ -987,12 +2018,18 @@ func PrepareExecContext(ctx context.Context, db DB, q Query) (*PreparedExec, err
 	return compiledExec.PrepareContext(ctx, db)
 }
 
+// Stats returns a snapshot of the usage counters of the PreparedExec. The
+// counters are shared with the CompiledExec it was prepared from.
+func (preparedExec *PreparedExec) Stats() QueryUsage {
+	return preparedExec.compiledExec.usage.snapshot()
+}
+
 // Close closes the PreparedExec.
 func (preparedExec *PreparedExec) Close() error {
 	if preparedExec.stmt == nil {
 		return nil
 	}
-	return preparedExec.stmt.Close()
+	return preparedExec.stmt.close()
 }
 
 // Exec executes the PreparedExec with the given params.
@@ -1012,19 +2049,22 @@ func (preparedExec *PreparedExec) exec(ctx context.Context, params Params, skip
 		Args:    preparedExec.compiledExec.args,
 		Params:  preparedExec.compiledExec.params,
 	}
+	queryStats.setContextIDs(ctx)
 
 	// Setup logger.
 	var logSettings LogSettings
-	if preparedExec.logger != nil {
-		preparedExec.logger.SqLogSettings(ctx, &logSettings)
+	logger := withQueryStatsDest(ctx, preparedExec.logger)
+	if logger != nil {
+		logger.SqLogSettings(ctx, &logSettings)
 		if logSettings.IncludeCaller {
 			queryStats.CallerFile, queryStats.CallerLine, queryStats.CallerFunction = caller(skip + 1)
 		}
 		defer func() {
+			queryStats.setErrorKind(ctx)
 			if logSettings.LogAsynchronously {
-				go preparedExec.logger.SqLogQuery(ctx, queryStats)
+				go logger.SqLogQuery(ctx, queryStats)
 			} else {
-				preparedExec.logger.SqLogQuery(ctx, queryStats)
+				logger.SqLogQuery(ctx, queryStats)
 			}
 		}()
 	}
@@ -1035,12 +2075,47 @@ func (preparedExec *PreparedExec) exec(ctx context.Context, params Params, skip
 		return result, err
 	}
 
+	queryStats.setQuerySize()
+
+	// Run interceptors.
+	interceptors := preparedExec.interceptors
+	ctx, queryStats.Err = beforeQuery(ctx, interceptors, queryStats)
+	if queryStats.Err != nil {
+		return result, queryStats.Err
+	}
+	if len(interceptors) > 0 {
+		defer func() {
+			if queryStats.Err == nil {
+				queryStats.Err = err
+			}
+			queryStats.Err = afterQuery(ctx, interceptors, queryStats)
+			err = queryStats.Err
+		}()
+	}
+
+	// Wait for a free slot if the DB is Limited.
+	var release func()
+	release, queryStats.WaitTime, queryStats.Err = preparedExec.sem.acquire(ctx)
+	if queryStats.Err != nil {
+		return result, queryStats.Err
+	}
+	defer release()
+
 	// Run query.
+	if preparedExec.compiledExec.usage != nil {
+		startedAt := time.Now()
+		defer func() {
+			preparedExec.compiledExec.usage.record(time.Since(startedAt), result.RowsAffected, err)
+		}()
+	}
 	if logSettings.IncludeTime {
 		queryStats.StartedAt = time.Now()
 	}
 	var sqlResult sql.Result
-	sqlResult, queryStats.Err = preparedExec.stmt.ExecContext(ctx, queryStats.Args...)
+	queryStats.Err = preparedExec.retryPolicy.run(ctx, queryStats.Query, &queryStats.Attempts, func() (err error) {
+		sqlResult, err = preparedExec.stmt.execContext(ctx, queryStats.Args)
+		return err
+	})
 	if logSettings.IncludeTime {
 		queryStats.TimeTaken = time.Since(queryStats.StartedAt)
 	}
@@ -1082,25 +2157,54 @@ func getFieldNames(ctx context.Context, row *Row) []string {
 	return fieldNames
 }
 
-func getFieldMappings(dialect string, fields []Field, scanDest []any) string {
+// getFieldMappings returns the mapping of each field to its scan destination,
+// one per line. If the columns (and column types) of the result set are
+// provided, each line also shows the result set column at the same position.
+func getFieldMappings(dialect string, fields []Field, scanDest []any, columns []string, columnTypes []*sql.ColumnType) string {
 	var buf bytes.Buffer
 	var args []any
 	var b strings.Builder
-	for i, field := range fields {
+	n := len(scanDest)
+	if len(fields) > n {
+		n = len(fields)
+	}
+	if len(columns) > n {
+		n = len(columns)
+	}
+	for i := 0; i < n; i++ {
 		b.WriteString(fmt.Sprintf("\n %02d. ", i+1))
-		buf.Reset()
-		args = args[:0]
-		err := field.WriteSQL(context.Background(), dialect, &buf, &args, nil)
-		if err != nil {
-			buf.WriteString("%!(error=" + err.Error() + ")")
+		if i < len(fields) {
+			buf.Reset()
+			args = args[:0]
+			err := fields[i].WriteSQL(context.Background(), dialect, &buf, &args, nil)
+			if err != nil {
+				b.WriteString("%!(error=" + err.Error() + ")")
+			} else if fieldName, err := Sprintf(dialect, buf.String(), args); err != nil {
+				b.WriteString("%!(error=" + err.Error() + ")")
+			} else {
+				b.WriteString(fieldName)
+			}
+			b.WriteString(" => ")
+		}
+		if i < len(scanDest) {
+			b.WriteString(reflect.TypeOf(scanDest[i]).String())
+		} else {
+			b.WriteString("<no scan destination>")
+		}
+		if columns == nil {
 			continue
 		}
-		fieldName, err := Sprintf(dialect, buf.String(), args)
-		if err != nil {
-			b.WriteString("%!(error=" + err.Error() + ")")
+		b.WriteString(" | ")
+		if i >= len(columns) {
+			b.WriteString("<no column>")
 			continue
 		}
-		b.WriteString(fieldName + " => " + reflect.TypeOf(scanDest[i]).String())
+		b.WriteString("column " + columns[i])
+		if i < len(columnTypes) && columnTypes[i] != nil {
+			if typeName := columnTypes[i].DatabaseTypeName(); typeName != "" {
+				b.WriteString(" " + typeName)
+			}
+		}
 	}
 	return b.String()
 }
@@ -1121,6 +2225,95 @@ func cursorResult[T any](cursor *Cursor[T]) (result T, err error) {
 	return result, cursor.Close()
 }
 
+// CheckFetchableFields checks that the fields used by a rowmapper match the
+// fetchable fields (e.g. the SELECT fields) of a query. If the query already
+// has fetchable fields, the rowmapper's fields are not added to the query and
+// the result columns are scanned into the rowmapper's fields by position, so
+// they must be the same fields in the same order. It returns nil if the query
+// has no fetchable fields (the rowmapper's fields will be used).
+func CheckFetchableFields[T any](query Query, rowmapper func(*Row) T) (err error) {
+	if query == nil {
+		return fmt.Errorf("query is nil")
+	}
+	if rowmapper == nil {
+		return fmt.Errorf("rowmapper is nil")
+	}
+	q, ok := query.(interface{ GetFetchableFields() []Field })
+	if !ok {
+		return nil
+	}
+	fetchableFields := q.GetFetchableFields()
+	if len(fetchableFields) == 0 {
+		return nil
+	}
+	dialect := query.GetDialect()
+	if dialect == "" {
+		defaultDialect := DefaultDialect.Load()
+		if defaultDialect != nil {
+			dialect = *defaultDialect
+		}
+	}
+	row := &Row{dialect: dialect}
+	defer mapperFunctionPanicked(&err)
+	_ = rowmapper(row)
+	selected := make([]string, len(fetchableFields))
+	for i, field := range fetchableFields {
+		selected[i] = toString(dialect, field)
+	}
+	used := make([]string, len(row.fields))
+	for i, field := range row.fields {
+		used[i] = toString(dialect, field)
+	}
+	if len(selected) == len(used) {
+		mismatch := false
+		for i := range selected {
+			if selected[i] != used[i] {
+				mismatch = true
+				break
+			}
+		}
+		if !mismatch {
+			return nil
+		}
+	}
+	var notSelected, notUsed []string
+	for _, name := range used {
+		if !containsString(selected, name) {
+			notSelected = append(notSelected, name)
+		}
+	}
+	for _, name := range selected {
+		if !containsString(used, name) {
+			notUsed = append(notUsed, name)
+		}
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("rowmapper uses %d fields (%s) but the query selects %d fields (%s)", len(used), strings.Join(used, ", "), len(selected), strings.Join(selected, ", ")))
+	if len(notSelected) > 0 {
+		b.WriteString("; not selected: " + strings.Join(notSelected, ", "))
+	}
+	if len(notUsed) > 0 {
+		b.WriteString("; not used by rowmapper: " + strings.Join(notUsed, ", "))
+	}
+	if len(notSelected) == 0 && len(notUsed) == 0 {
+		if len(selected) == len(used) {
+			b.WriteString("; the fields are in a different order")
+		} else {
+			b.WriteString("; some fields are duplicated")
+		}
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
 func cursorResults[T any](cursor *Cursor[T]) (results []T, err error) {
 	var result T
 	for cursor.Next() {
@@ -1166,18 +2359,20 @@ func FetchExistsContext(ctx context.Context, db DB, query Query) (exists bool, e
 }
 
 func fetchExists(ctx context.Context, db DB, query Query, skip int) (exists bool, err error) {
+	err = checkDialect(ctx, db, query.GetDialect())
+	if err != nil {
+		return false, err
+	}
 	dialect := query.GetDialect()
 	if dialect == "" {
-		defaultDialect := DefaultDialect.Load()
-		if defaultDialect != nil {
-			dialect = *defaultDialect
-		}
+		dialect = dbDialect(db)
 	}
 	queryStats := QueryStats{
 		Dialect: dialect,
 		Params:  make(map[string][]int),
 		Exists:  sql.NullBool{Valid: true},
 	}
+	queryStats.setContextIDs(ctx)
 
 	// Build query.
 	buf := bufpool.Get().(*bytes.Buffer)
@@ -1207,12 +2402,14 @@ func fetchExists(ctx context.Context, db DB, query Query, skip int) (exists bool
 			}
 		}
 	}
+	logger = withQueryStatsDest(ctx, logger)
 	if logger != nil {
 		logger.SqLogSettings(ctx, &logSettings)
 		if logSettings.IncludeCaller {
 			queryStats.CallerFile, queryStats.CallerLine, queryStats.CallerFunction = caller(skip + 1)
 		}
 		defer func() {
+			queryStats.setErrorKind(ctx)
 			if logSettings.LogAsynchronously {
 				go logger.SqLogQuery(ctx, queryStats)
 			} else {
@@ -1221,12 +2418,47 @@ func fetchExists(ctx context.Context, db DB, query Query, skip int) (exists bool
 		}()
 	}
 
+	// Run query hooks.
+	queryStats.Query, queryStats.Args, queryStats.Err = runQueryHooks(ctx, db, queryStats.Dialect, queryStats.Query, queryStats.Args)
+	if queryStats.Err != nil {
+		return false, queryStats.Err
+	}
+
+	queryStats.setQuerySize()
+
+	// Run interceptors.
+	interceptors := queryInterceptors(db)
+	ctx, queryStats.Err = beforeQuery(ctx, interceptors, queryStats)
+	if queryStats.Err != nil {
+		return false, queryStats.Err
+	}
+	if len(interceptors) > 0 {
+		defer func() {
+			if queryStats.Err == nil {
+				queryStats.Err = err
+			}
+			queryStats.Err = afterQuery(ctx, interceptors, queryStats)
+			err = queryStats.Err
+		}()
+	}
+
+	// Wait for a free slot if the DB is Limited.
+	var release func()
+	release, queryStats.WaitTime, queryStats.Err = dbSemaphore(db).acquire(ctx)
+	if queryStats.Err != nil {
+		return false, queryStats.Err
+	}
+	defer release()
+
 	// Run query.
 	if logSettings.IncludeTime {
 		queryStats.StartedAt = time.Now()
 	}
 	var sqlRows *sql.Rows
-	sqlRows, queryStats.Err = db.QueryContext(ctx, queryStats.Query, queryStats.Args...)
+	queryStats.Err = dbRetryPolicy(db).run(ctx, queryStats.Query, &queryStats.Attempts, func() (err error) {
+		sqlRows, err = db.QueryContext(ctx, queryStats.Query, queryStats.Args...)
+		return err
+	})
 	if logSettings.IncludeTime {
 		queryStats.TimeTaken = time.Since(queryStats.StartedAt)
 	}
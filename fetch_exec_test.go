@@ -1,12 +1,21 @@
 package sq
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/bokwoon95/sq/internal/testutil"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
 )
 
 var ACTOR = New[struct {
@@ -124,6 +133,7 @@ func Test_getFieldMappings(t *testing.T) {
 		dialect           string
 		fields            []Field
 		scanDest          []any
+		columns           []string
 		wantFieldMappings string
 	}
 
@@ -146,13 +156,38 @@ func Test_getFieldMappings(t *testing.T) {
 			"\n 01. actor_id => *sql.NullInt64" +
 			"\n 02. first_name || ' ' || last_name => *sql.NullString" +
 			"\n 03. last_update => *sql.NullTime",
+	}, {
+		description: "columns",
+		fields: []Field{
+			Expr("actor_id"),
+			Expr("first_name"),
+		},
+		scanDest: []any{
+			&sql.NullInt64{},
+			&sql.NullString{},
+		},
+		columns: []string{"actor_id", "first_name", "last_name"},
+		wantFieldMappings: "" +
+			"\n 01. actor_id => *sql.NullInt64 | column actor_id" +
+			"\n 02. first_name => *sql.NullString | column first_name" +
+			"\n 03. <no scan destination> | column last_name",
+	}, {
+		description: "static query",
+		scanDest: []any{
+			new(any),
+			new(any),
+		},
+		columns: []string{"actor_id"},
+		wantFieldMappings: "" +
+			"\n 01. *interface {} | column actor_id" +
+			"\n 02. *interface {} | <no column>",
 	}}
 
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.description, func(t *testing.T) {
 			t.Parallel()
-			gotFieldMappings := getFieldMappings(tt.dialect, tt.fields, tt.scanDest)
+			gotFieldMappings := getFieldMappings(tt.dialect, tt.fields, tt.scanDest, tt.columns, nil)
 			if diff := testutil.Diff(gotFieldMappings, tt.wantFieldMappings); diff != "" {
 				t.Error(testutil.Callers(), diff)
 			}
@@ -242,113 +277,400 @@ func TestFetchExec(t *testing.T) {
 	}
 }
 
-func TestCompiledFetchExec(t *testing.T) {
+func TestFetchInsert(t *testing.T) {
 	t.Parallel()
 	db := newDB(t)
-	var referenceActors = []Actor{
+	referenceActors := []Actor{
 		{ActorID: 1, FirstName: "PENELOPE", LastName: "GUINESS", LastUpdate: time.Unix(1, 0).UTC()},
 		{ActorID: 2, FirstName: "NICK", LastName: "WAHLBERG", LastUpdate: time.Unix(1, 0).UTC()},
-		{ActorID: 3, FirstName: "ED", LastName: "CHASE", LastUpdate: time.Unix(1, 0).UTC()},
-		{ActorID: 4, FirstName: "JENNIFER", LastName: "DAVIS", LastUpdate: time.Unix(1, 0).UTC()},
-		{ActorID: 5, FirstName: "JOHNNY", LastName: "LOLLOBRIGIDA", LastUpdate: time.Unix(1, 0).UTC()},
+	}
+	actors, err := FetchInsert(Log(db), InsertRows(ACTOR, referenceActors, func(col *Column, actor Actor) {
+		col.SetInt(ACTOR.ACTOR_ID, actor.ActorID)
+		col.SetString(ACTOR.FIRST_NAME, actor.FirstName)
+		col.SetString(ACTOR.LAST_NAME, actor.LastName)
+		col.SetTime(ACTOR.LAST_UPDATE, actor.LastUpdate)
+	}).SetDialect(DialectSQLite), actorRowMapper)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(actors, referenceActors); diff != "" {
+		t.Fatal(testutil.Callers(), diff)
 	}
 
-	// CompiledExec.
-	compiledExec, err := CompileExec(SQLite.
+	// Conflicting rows are not returned.
+	actors, err = FetchInsert(Log(db), SQLite.
 		InsertInto(ACTOR).
-		ColumnValues(func(col *Column) {
-			col.Set(ACTOR.ACTOR_ID, IntParam("actor_id", 0))
-			col.Set(ACTOR.FIRST_NAME, StringParam("first_name", ""))
-			col.Set(ACTOR.LAST_NAME, StringParam("last_name", ""))
-			col.Set(ACTOR.LAST_UPDATE, TimeParam("last_update", time.Time{}))
-		}),
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME, ACTOR.LAST_UPDATE).
+		Values(2, "NICK", "WAHLBERG", time.Unix(1, 0).UTC()).
+		Values(3, "ED", "CHASE", time.Unix(1, 0).UTC()).
+		OnConflict().DoNothing(),
+		actorRowMapper,
 	)
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	for _, actor := range referenceActors {
-		_, err = compiledExec.Exec(Log(db), Params{
-			"actor_id":    actor.ActorID,
-			"first_name":  actor.FirstName,
-			"last_name":   actor.LastName,
-			"last_update": actor.LastUpdate,
+	if diff := testutil.Diff(actors, []Actor{{ActorID: 3, FirstName: "ED", LastName: "CHASE", LastUpdate: time.Unix(1, 0).UTC()}}); diff != "" {
+		t.Fatal(testutil.Callers(), diff)
+	}
+
+	tests := []struct {
+		description string
+		query       Query
+	}{{
+		description: "not an INSERT query",
+		query:       SQLite.From(ACTOR),
+	}, {
+		description: "dialect does not support RETURNING",
+		query:       MySQL.InsertInto(ACTOR).Columns(ACTOR.ACTOR_ID).Values(4),
+	}, {
+		description: "RETURNING already present",
+		query:       SQLite.InsertInto(ACTOR).Columns(ACTOR.ACTOR_ID).Values(4).Returning(ACTOR.ACTOR_ID),
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			_, err := FetchInsert(db, tt.query, actorRowMapper)
+			if err == nil {
+				t.Fatal(testutil.Callers(), "expected error, got nil")
+			}
 		})
-		if err != nil {
-			t.Fatal(testutil.Callers(), err)
-		}
 	}
+}
 
-	// CompiledFetch FetchOne.
-	compiledFetch, err := CompileFetch(SQLite.
-		From(ACTOR).
-		Where(ACTOR.ACTOR_ID.Eq(IntParam("actor_id", 0))),
-		actorRowMapper,
-	)
+func TestRowJSONMap(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := db.Exec("CREATE TABLE event (event_id INT, payload JSON)")
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	actor, err := compiledFetch.FetchOne(Log(db), Params{"actor_id": 1})
+	_, err = db.Exec(`INSERT INTO event (event_id, payload) VALUES (1, '{"kind":"signup","tags":["a","b"],"count":3}'), (2, NULL)`)
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	if diff := testutil.Diff(actor, referenceActors[0]); diff != "" {
-		t.Fatal(testutil.Callers(), diff)
+	EVENT := struct {
+		EVENT_ID NumberField
+		PAYLOAD  JSONField
+	}{
+		EVENT_ID: NewNumberField("event_id", NewTableStruct("", "event", "")),
+		PAYLOAD:  NewJSONField("payload", NewTableStruct("", "event", "")),
+	}
+	type result struct {
+		Map map[string]any
+		Raw json.RawMessage
 	}
+	wantMap := map[string]any{"kind": "signup", "tags": []any{"a", "b"}, "count": float64(3)}
+	wantRaw := json.RawMessage(`{"kind":"signup","tags":["a","b"],"count":3}`)
+	want := []result{{Map: wantMap, Raw: wantRaw}, {}}
 
-	// CompiledFetch FetchOne (Raw SQL).
-	compiledFetch, err = CompileFetch(
-		SQLite.Queryf("SELECT * FROM actor WHERE actor_id = {actor_id}", IntParam("actor_id", 0)),
-		actorRowMapperRawSQL,
-	)
+	t.Run("dynamic", func(t *testing.T) {
+		t.Parallel()
+		got, err := FetchAll(db, SQLite.From(NewTableStruct("", "event", "")).OrderBy(EVENT.EVENT_ID), func(row *Row) result {
+			return result{
+				Map: row.JSONMapField(EVENT.PAYLOAD),
+				Raw: row.JSONRawField(EVENT.PAYLOAD),
+			}
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, want); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("static", func(t *testing.T) {
+		t.Parallel()
+		got, err := FetchAll(db, SQLite.Queryf("SELECT payload, payload AS raw FROM event ORDER BY event_id"), func(row *Row) result {
+			return result{
+				Map: row.JSONMapField(EVENT.PAYLOAD),
+				Raw: row.JSONRaw("raw"),
+			}
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, want); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		got, err = FetchAll(db, SQLite.Queryf("SELECT payload FROM event ORDER BY event_id"), func(row *Row) result {
+			return result{
+				Map: row.JSONMapAt(0),
+				Raw: row.JSONRawAt(0),
+			}
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, want); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		t.Parallel()
+		_, err := FetchAll(db, SQLite.Queryf("SELECT '[1, 2, 3]' AS payload"), func(row *Row) map[string]any {
+			return row.JSONMap("payload")
+		})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
+
+func TestRowStaticScanners(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := db.Exec("CREATE TABLE task (task_id INT, tags JSON, weekday TEXT, task_uuid BLOB, metadata JSON)")
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	actor, err = compiledFetch.FetchOne(Log(db), Params{"actor_id": 1})
+	_, err = db.Exec(`INSERT INTO task (task_id, tags, weekday, task_uuid, metadata) VALUES` +
+		` (1, '["a","b"]', 'Tuesday', X'a4f952f1437543a7a0e6d3a1f2bc6d5e', '{"priority":2}'),` +
+		` (2, NULL, NULL, NULL, NULL)`)
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	if diff := testutil.Diff(actor, referenceActors[0]); diff != "" {
-		t.Fatal(testutil.Callers(), diff)
+	tbl := NewTableStruct("", "task", "")
+	TASK := struct {
+		TASK_ID   NumberField
+		TAGS      ArrayField
+		WEEKDAY   EnumField
+		TASK_UUID UUIDField
+		METADATA  JSONField
+	}{
+		TASK_ID:   NewNumberField("task_id", tbl),
+		TAGS:      NewArrayField("tags", tbl),
+		WEEKDAY:   NewEnumField("weekday", tbl),
+		TASK_UUID: NewUUIDField("task_uuid", tbl),
+		METADATA:  NewJSONField("metadata", tbl),
+	}
+	type task struct {
+		Tags     []string
+		Weekday  Weekday
+		UUID     uuid.UUID
+		Metadata map[string]int
+	}
+	want := []task{{
+		Tags:     []string{"a", "b"},
+		Weekday:  Tuesday,
+		UUID:     uuid.MustParse("a4f952f1-4375-43a7-a0e6-d3a1f2bc6d5e"),
+		Metadata: map[string]int{"priority": 2},
+	}, {}}
+	fieldRowMapper := func(row *Row) task {
+		var tk task
+		row.ArrayField(&tk.Tags, TASK.TAGS)
+		row.EnumField(&tk.Weekday, TASK.WEEKDAY)
+		row.UUIDField(&tk.UUID, TASK.TASK_UUID)
+		row.JSONField(&tk.Metadata, TASK.METADATA)
+		return tk
 	}
 
-	// CompiledFetch FetchAll.
-	compiledFetch, err = CompileFetch(SQLite.
-		From(ACTOR).
-		OrderBy(ACTOR.ACTOR_ID),
-		actorRowMapper,
+	t.Run("dynamic", func(t *testing.T) {
+		t.Parallel()
+		got, err := FetchAll(db, SQLite.From(tbl).OrderBy(TASK.TASK_ID), fieldRowMapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, want); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("static", func(t *testing.T) {
+		t.Parallel()
+		got, err := FetchAll(db, SQLite.Queryf("SELECT tags, weekday, task_uuid, metadata FROM task ORDER BY task_id"), fieldRowMapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, want); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		got, err = FetchAll(db, SQLite.Queryf("SELECT tags, weekday, task_uuid AS id, metadata FROM task ORDER BY task_id"), func(row *Row) task {
+			var tk task
+			row.Array(&tk.Tags, "tags")
+			row.EnumAt(&tk.Weekday, 1)
+			row.UUID(&tk.UUID, "id")
+			row.JSONAt(&tk.Metadata, 3)
+			return tk
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, want); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("static string UUID", func(t *testing.T) {
+		t.Parallel()
+		got, err := FetchAll(db, SQLite.Queryf("SELECT 'a4f952f1-4375-43a7-a0e6-d3a1f2bc6d5e' AS id"), func(row *Row) uuid.UUID {
+			var id uuid.UUID
+			row.UUIDAt(&id, 0)
+			return id
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, []uuid.UUID{want[0].UUID}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("static mapping error", func(t *testing.T) {
+		t.Parallel()
+		_, err := FetchAll(db, SQLite.Queryf("SELECT 1 AS tags"), func(row *Row) []string {
+			var tags []string
+			row.Array(&tags, "tags")
+			return tags
+		})
+		var mappingErr *MappingError
+		if !errors.As(err, &mappingErr) {
+			t.Fatalf(testutil.Callers()+" expected *MappingError, got %v", err)
+		}
+		if diff := testutil.Diff(mappingErr.Expected, "*[]string"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("static invalid enum", func(t *testing.T) {
+		t.Parallel()
+		_, err := FetchAll(db, SQLite.Queryf("SELECT 'Someday' AS weekday"), func(row *Row) Weekday {
+			var weekday Weekday
+			row.Enum(&weekday, "weekday")
+			return weekday
+		})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
+
+func TestFetchStaticColumnIndex(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS"),
 	)
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	actors, err := compiledFetch.FetchAll(VerboseLog(db), nil)
+	type result struct {
+		ActorID   int
+		FirstName string
+		LastName  sql.NullString
+		Value     any
+	}
+	// Both name columns are called "name", so they can only be told apart by
+	// their index.
+	got, err := FetchOne(db, SQLite.Queryf("SELECT actor_id, first_name AS name, last_name AS name FROM actor"), func(row *Row) result {
+		return result{
+			ActorID:   row.IntAt(0),
+			FirstName: row.StringAt(1),
+			LastName:  row.NullStringAt(2),
+			Value:     row.ValueAt(0),
+		}
+	})
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	if diff := testutil.Diff(actors, referenceActors); diff != "" {
-		t.Fatal(testutil.Callers(), diff)
+	want := result{ActorID: 1, FirstName: "PENELOPE", LastName: sql.NullString{String: "GUINESS", Valid: true}, Value: int64(1)}
+	if diff := testutil.Diff(got, want); diff != "" {
+		t.Error(testutil.Callers(), diff)
 	}
 
-	// CompiledFetch FetchAll (Raw SQL).
-	compiledFetch, err = CompileFetch(
-		SQLite.Queryf("SELECT * FROM actor ORDER BY actor_id"),
-		actorRowMapperRawSQL,
+	t.Run("index out of range", func(t *testing.T) {
+		t.Parallel()
+		_, err := FetchOne(db, SQLite.Queryf("SELECT actor_id FROM actor"), func(row *Row) int {
+			return row.IntAt(1)
+		})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error, got nil")
+		}
+	})
+
+	t.Run("non-static query", func(t *testing.T) {
+		t.Parallel()
+		_, err := FetchOne(db, SQLite.From(ACTOR), func(row *Row) int {
+			return row.IntAt(0)
+		})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error, got nil")
+		}
+	})
+}
+
+func TestFetchStaticFields(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS"),
 	)
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	actors, err = compiledFetch.FetchAll(VerboseLog(db), nil)
+	type result struct {
+		ActorID   int
+		FirstName string
+		LastName  string
+		Values    []any
+	}
+	got, err := FetchOne(db, SQLite.Queryf("SELECT actor_id, first_name, last_name AS lname FROM actor"), func(row *Row) result {
+		return result{
+			ActorID:   row.IntField(ACTOR.ACTOR_ID),
+			FirstName: row.StringField(ACTOR.FIRST_NAME),
+			LastName:  row.StringField(ACTOR.LAST_NAME.As("lname")),
+			Values:    row.Values(),
+		}
+	})
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	if diff := testutil.Diff(actors, referenceActors); diff != "" {
-		t.Fatal(testutil.Callers(), diff)
+	want := result{ActorID: 1, FirstName: "PENELOPE", LastName: "GUINESS", Values: []any{int64(1), "PENELOPE", "GUINESS"}}
+	if diff := testutil.Diff(got, want); diff != "" {
+		t.Error(testutil.Callers(), diff)
 	}
+
+	t.Run("ambiguous column", func(t *testing.T) {
+		t.Parallel()
+		_, err := FetchOne(db, SQLite.Queryf("SELECT first_name AS name, last_name AS name FROM actor"), func(row *Row) string {
+			return row.String("name")
+		})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error, got nil")
+		}
+	})
+	t.Run("mapping error", func(t *testing.T) {
+		t.Parallel()
+		for _, rowmapper := range []func(*Row) int{
+			func(row *Row) int { return row.Int("first_name") },
+			func(row *Row) int { return row.IntAt(1) },
+		} {
+			_, err := FetchOne(db, SQLite.Queryf("SELECT actor_id, first_name FROM actor"), rowmapper)
+			var mappingErr *MappingError
+			if !errors.As(err, &mappingErr) {
+				t.Fatalf(testutil.Callers()+" expected *MappingError, got %#v", err)
+			}
+			mappingErr.Callsite = ""
+			wantErr := &MappingError{Column: "first_name", Expected: "int", Actual: "string", Value: "PENELOPE"}
+			if diff := testutil.Diff(mappingErr, wantErr); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			if diff := testutil.Diff(mappingErr.Error(), `column first_name: "PENELOPE" is string, not int`); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		}
+	})
 }
 
-func TestPreparedFetchExec(t *testing.T) {
+func TestCompiledFetchExec(t *testing.T) {
 	t.Parallel()
 	db := newDB(t)
-
 	var referenceActors = []Actor{
 		{ActorID: 1, FirstName: "PENELOPE", LastName: "GUINESS", LastUpdate: time.Unix(1, 0).UTC()},
 		{ActorID: 2, FirstName: "NICK", LastName: "WAHLBERG", LastUpdate: time.Unix(1, 0).UTC()},
@@ -357,8 +679,8 @@ func TestPreparedFetchExec(t *testing.T) {
 		{ActorID: 5, FirstName: "JOHNNY", LastName: "LOLLOBRIGIDA", LastUpdate: time.Unix(1, 0).UTC()},
 	}
 
-	// PreparedExec.
-	preparedExec, err := PrepareExec(Log(db), SQLite.
+	// CompiledExec.
+	compiledExec, err := CompileExec(SQLite.
 		InsertInto(ACTOR).
 		ColumnValues(func(col *Column) {
 			col.Set(ACTOR.ACTOR_ID, IntParam("actor_id", 0))
@@ -371,7 +693,7 @@ func TestPreparedFetchExec(t *testing.T) {
 		t.Fatal(testutil.Callers(), err)
 	}
 	for _, actor := range referenceActors {
-		_, err = preparedExec.Exec(Params{
+		_, err = compiledExec.Exec(Log(db), Params{
 			"actor_id":    actor.ActorID,
 			"first_name":  actor.FirstName,
 			"last_name":   actor.LastName,
@@ -382,8 +704,8 @@ func TestPreparedFetchExec(t *testing.T) {
 		}
 	}
 
-	// PreparedFetch FetchOne.
-	preparedFetch, err := PrepareFetch(Log(db), SQLite.
+	// CompiledFetch FetchOne.
+	compiledFetch, err := CompileFetch(SQLite.
 		From(ACTOR).
 		Where(ACTOR.ACTOR_ID.Eq(IntParam("actor_id", 0))),
 		actorRowMapper,
@@ -391,7 +713,7 @@ func TestPreparedFetchExec(t *testing.T) {
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	actor, err := preparedFetch.FetchOne(Params{"actor_id": 1})
+	actor, err := compiledFetch.FetchOne(Log(db), Params{"actor_id": 1})
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
@@ -399,16 +721,15 @@ func TestPreparedFetchExec(t *testing.T) {
 		t.Fatal(testutil.Callers(), diff)
 	}
 
-	// PreparedFetch FetchOne (Raw SQL).
-	preparedFetch, err = PrepareFetch(
-		Log(db),
+	// CompiledFetch FetchOne (Raw SQL).
+	compiledFetch, err = CompileFetch(
 		SQLite.Queryf("SELECT * FROM actor WHERE actor_id = {actor_id}", IntParam("actor_id", 0)),
 		actorRowMapperRawSQL,
 	)
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	actor, err = preparedFetch.FetchOne(Params{"actor_id": 1})
+	actor, err = compiledFetch.FetchOne(Log(db), Params{"actor_id": 1})
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
@@ -416,8 +737,8 @@ func TestPreparedFetchExec(t *testing.T) {
 		t.Fatal(testutil.Callers(), diff)
 	}
 
-	// PreparedFetch FetchAll.
-	preparedFetch, err = PrepareFetch(VerboseLog(db), SQLite.
+	// CompiledFetch FetchAll.
+	compiledFetch, err = CompileFetch(SQLite.
 		From(ACTOR).
 		OrderBy(ACTOR.ACTOR_ID),
 		actorRowMapper,
@@ -425,7 +746,7 @@ func TestPreparedFetchExec(t *testing.T) {
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	actors, err := preparedFetch.FetchAll(nil)
+	actors, err := compiledFetch.FetchAll(VerboseLog(db), nil)
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
@@ -433,16 +754,15 @@ func TestPreparedFetchExec(t *testing.T) {
 		t.Fatal(testutil.Callers(), diff)
 	}
 
-	// PreparedFetch FetchAll (Raw SQL).
-	preparedFetch, err = PrepareFetch(
-		VerboseLog(db),
+	// CompiledFetch FetchAll (Raw SQL).
+	compiledFetch, err = CompileFetch(
 		SQLite.Queryf("SELECT * FROM actor ORDER BY actor_id"),
 		actorRowMapperRawSQL,
 	)
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	actors, err = preparedFetch.FetchAll(nil)
+	actors, err = compiledFetch.FetchAll(VerboseLog(db), nil)
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
@@ -451,19 +771,1241 @@ func TestPreparedFetchExec(t *testing.T) {
 	}
 }
 
-func newDB(t *testing.T) *sql.DB {
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		t.Fatal(testutil.Callers(), err)
-	}
-	_, err = db.Exec(`CREATE TABLE actor (
-    actor_id INTEGER PRIMARY KEY AUTOINCREMENT
-    ,first_name TEXT NOT NULL
-    ,last_name TEXT NOT NULL
-    ,last_update DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-)`)
+func TestCheckParams(t *testing.T) {
+	compiledFetch, err := CompileFetch(SQLite.
+		From(ACTOR).
+		Where(
+			ACTOR.FIRST_NAME.Eq(StringParam("first_name", "")),
+			ACTOR.LAST_UPDATE.Gt(TimeParam("last_update", time.Time{})),
+		),
+		actorRowMapper,
+	)
 	if err != nil {
 		t.Fatal(testutil.Callers(), err)
 	}
-	return db
+
+	t.Run("ok", func(t *testing.T) {
+		params, err := NewParams().
+			String("first_name", "DAN").
+			Time("last_update", time.Unix(1, 0).UTC()).
+			Build()
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		wantParams := Params{"first_name": "DAN", "last_update": time.Unix(1, 0).UTC()}
+		if diff := testutil.Diff(params, wantParams); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		err = compiledFetch.CheckParams(params)
+		if err != nil {
+			t.Error(testutil.Callers(), err)
+		}
+	})
+
+	t.Run("missing and unexpected", func(t *testing.T) {
+		params, err := NewParams().String("firstname", "DAN").Build()
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		err = compiledFetch.CheckParams(params)
+		wantErr := "expected 2 params (first_name, last_update), got 1; missing: first_name, last_update; unexpected: firstname"
+		if err == nil || err.Error() != wantErr {
+			t.Errorf(testutil.Callers()+" expected error %q, got %v", wantErr, err)
+		}
+	})
+
+	t.Run("set twice", func(t *testing.T) {
+		_, err := NewParams().String("first_name", "DAN").Int("first_name", 1).Build()
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		_, err := NewParams().Int("", 1).Build()
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	// The stats are global, use query strings that no other test uses.
+	compiledExec, err := CompileExec(SQLite.Queryf("INSERT INTO actor (first_name, last_name) VALUES ({first_name}, 'STATS')", sql.Named("first_name", "")))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	for _, firstName := range []string{"PENELOPE", "NICK"} {
+		_, err = compiledExec.Exec(db, Params{"first_name": firstName})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	}
+	preparedFetch, err := PrepareFetch(db, SQLite.Queryf("SELECT {*} FROM actor WHERE last_name = 'STATS'"), func(row *Row) string {
+		return row.String("first_name")
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	defer preparedFetch.Close()
+	for i := 0; i < 3; i++ {
+		_, err = preparedFetch.FetchAll(nil)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	}
+
+	execStats := compiledExec.Stats()
+	if diff := testutil.Diff([]int64{execStats.Executions, execStats.Errors, execStats.Rows}, []int64{2, 0, 2}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	fetchStats := preparedFetch.Stats()
+	if diff := testutil.Diff([]int64{fetchStats.Executions, fetchStats.Errors, fetchStats.Rows}, []int64{3, 0, 6}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if fetchStats.TotalTime <= 0 {
+		t.Error(testutil.Callers(), "expected TotalTime to be positive, got", fetchStats.TotalTime)
+	}
+	var found int
+	for _, usage := range Stats() {
+		if usage.Query == execStats.Query || usage.Query == fetchStats.Query {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf(testutil.Callers()+" expected Stats() to contain 2 queries, found %d", found)
+	}
+
+	t.Run("eviction", func(t *testing.T) {
+		t.Parallel()
+		cache := &queryUsageCache{capacity: 2}
+		usage1 := cache.get(DialectSQLite, "SELECT 1")
+		cache.get(DialectSQLite, "SELECT 2")
+		cache.get(DialectSQLite, "SELECT 1")
+		cache.get(DialectSQLite, "SELECT 3")
+		cache.get(DialectSQLite, "SELECT 4")
+		// An evicted usage is added back once it is used again.
+		if usage1.element != nil {
+			t.Fatal(testutil.Callers(), "expected SELECT 1 to be evicted")
+		}
+		cache.touch(usage1)
+		var gotQueries []string
+		for element := cache.order.Front(); element != nil; element = element.Next() {
+			gotQueries = append(gotQueries, element.Value.(*queryUsage).query)
+		}
+		if diff := testutil.Diff(gotQueries, []string{"SELECT 1", "SELECT 4"}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(len(cache.entries), 2); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if cache.get(DialectSQLite, "SELECT 1") != usage1 {
+			t.Error(testutil.Callers(), "expected the same usage for SELECT 1")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		cache := &queryUsageCache{}
+		usage := cache.get(DialectSQLite, "SELECT 1")
+		cache.touch(usage)
+		if len(cache.entries) != 0 {
+			t.Error(testutil.Callers(), "expected no cached entries")
+		}
+	})
+}
+
+func TestPreparedFetchExec(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+
+	var referenceActors = []Actor{
+		{ActorID: 1, FirstName: "PENELOPE", LastName: "GUINESS", LastUpdate: time.Unix(1, 0).UTC()},
+		{ActorID: 2, FirstName: "NICK", LastName: "WAHLBERG", LastUpdate: time.Unix(1, 0).UTC()},
+		{ActorID: 3, FirstName: "ED", LastName: "CHASE", LastUpdate: time.Unix(1, 0).UTC()},
+		{ActorID: 4, FirstName: "JENNIFER", LastName: "DAVIS", LastUpdate: time.Unix(1, 0).UTC()},
+		{ActorID: 5, FirstName: "JOHNNY", LastName: "LOLLOBRIGIDA", LastUpdate: time.Unix(1, 0).UTC()},
+	}
+
+	// PreparedExec.
+	preparedExec, err := PrepareExec(Log(db), SQLite.
+		InsertInto(ACTOR).
+		ColumnValues(func(col *Column) {
+			col.Set(ACTOR.ACTOR_ID, IntParam("actor_id", 0))
+			col.Set(ACTOR.FIRST_NAME, StringParam("first_name", ""))
+			col.Set(ACTOR.LAST_NAME, StringParam("last_name", ""))
+			col.Set(ACTOR.LAST_UPDATE, TimeParam("last_update", time.Time{}))
+		}),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	for _, actor := range referenceActors {
+		_, err = preparedExec.Exec(Params{
+			"actor_id":    actor.ActorID,
+			"first_name":  actor.FirstName,
+			"last_name":   actor.LastName,
+			"last_update": actor.LastUpdate,
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	}
+
+	// PreparedFetch FetchOne.
+	preparedFetch, err := PrepareFetch(Log(db), SQLite.
+		From(ACTOR).
+		Where(ACTOR.ACTOR_ID.Eq(IntParam("actor_id", 0))),
+		actorRowMapper,
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	actor, err := preparedFetch.FetchOne(Params{"actor_id": 1})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(actor, referenceActors[0]); diff != "" {
+		t.Fatal(testutil.Callers(), diff)
+	}
+
+	// PreparedFetch FetchOne (Raw SQL).
+	preparedFetch, err = PrepareFetch(
+		Log(db),
+		SQLite.Queryf("SELECT * FROM actor WHERE actor_id = {actor_id}", IntParam("actor_id", 0)),
+		actorRowMapperRawSQL,
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	actor, err = preparedFetch.FetchOne(Params{"actor_id": 1})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(actor, referenceActors[0]); diff != "" {
+		t.Fatal(testutil.Callers(), diff)
+	}
+
+	// PreparedFetch FetchAll.
+	preparedFetch, err = PrepareFetch(VerboseLog(db), SQLite.
+		From(ACTOR).
+		OrderBy(ACTOR.ACTOR_ID),
+		actorRowMapper,
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	actors, err := preparedFetch.FetchAll(nil)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(actors, referenceActors); diff != "" {
+		t.Fatal(testutil.Callers(), diff)
+	}
+
+	// PreparedFetch FetchAll (Raw SQL).
+	preparedFetch, err = PrepareFetch(
+		VerboseLog(db),
+		SQLite.Queryf("SELECT * FROM actor ORDER BY actor_id"),
+		actorRowMapperRawSQL,
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	actors, err = preparedFetch.FetchAll(nil)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(actors, referenceActors); diff != "" {
+		t.Fatal(testutil.Callers(), diff)
+	}
+}
+
+func newDB(t testing.TB) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = db.Exec(`CREATE TABLE actor (
+    actor_id INTEGER PRIMARY KEY AUTOINCREMENT
+    ,first_name TEXT NOT NULL
+    ,last_name TEXT NOT NULL
+    ,last_update DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	return db
+}
+
+// txCounts records the transactions committed and rolled back on a DB
+// returned by newFileDB.
+type txCounts struct {
+	commits   atomic.Int32
+	rollbacks atomic.Int32
+}
+
+// newFileDB is like newDB but the database is a file, so that it is shared by
+// every connection in the pool. The pool keeps no idle connections, so
+// queries that are not pinned to the same connection never share its state
+// (e.g. temporary tables).
+func newFileDB(t testing.TB) (*sql.DB, *txCounts) {
+	counts := &txCounts{}
+	db := sql.OpenDB(txCountingConnector{
+		dsn:    filepath.Join(t.TempDir(), "test.db"),
+		counts: counts,
+	})
+	db.SetMaxIdleConns(0)
+	t.Cleanup(func() { db.Close() })
+	_, err := db.Exec(`CREATE TABLE actor (
+    actor_id INTEGER PRIMARY KEY AUTOINCREMENT
+    ,first_name TEXT NOT NULL
+    ,last_name TEXT NOT NULL
+    ,last_update DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	return db, counts
+}
+
+type txCountingConnector struct {
+	dsn    string
+	counts *txCounts
+}
+
+func (c txCountingConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return txCountingConn{SQLiteConn: conn.(*sqlite3.SQLiteConn), counts: c.counts}, nil
+}
+
+func (c txCountingConnector) Driver() driver.Driver { return &sqlite3.SQLiteDriver{} }
+
+type txCountingConn struct {
+	*sqlite3.SQLiteConn
+	counts *txCounts
+}
+
+func (conn txCountingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	tx, err := conn.SQLiteConn.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return txCountingTx{Tx: tx, counts: conn.counts}, nil
+}
+
+type txCountingTx struct {
+	driver.Tx
+	counts *txCounts
+}
+
+func (tx txCountingTx) Commit() error {
+	tx.counts.commits.Add(1)
+	return tx.Tx.Commit()
+}
+
+func (tx txCountingTx) Rollback() error {
+	tx.counts.rollbacks.Add(1)
+	return tx.Tx.Rollback()
+}
+
+func TestStructRowMapper(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME, ACTOR.LAST_UPDATE).
+		Values(1, "PENELOPE", "GUINESS", time.Unix(1, 0).UTC()),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	type ActorName struct {
+		ID        int `sq:"actor_id"`
+		FirstName string
+		LastName  *string
+		Unmapped  string
+	}
+	gotActors, err := FetchAll(db, From(ACTOR), StructRowMapper[ActorName](ACTOR))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	lastName := "GUINESS"
+	wantActors := []ActorName{{ID: 1, FirstName: "PENELOPE", LastName: &lastName}}
+	if diff := testutil.Diff(gotActors, wantActors); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	gotActors2, err := FetchAll(db, From(ACTOR), StructRowMapper[Actor](ACTOR))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	wantActors2 := []Actor{{ActorID: 1, FirstName: "PENELOPE", LastName: "GUINESS", LastUpdate: time.Unix(1, 0).UTC()}}
+	if diff := testutil.Diff(gotActors2, wantActors2); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
+
+func TestBitField(t *testing.T) {
+	t.Parallel()
+	type PERMISSIONS struct {
+		TableStruct
+		ID    NumberField
+		FLAGS BitField
+	}
+	type Permissions struct {
+		ID    int
+		Flags uint64
+	}
+	p := New[PERMISSIONS]("")
+	db := newDB(t)
+	_, err := db.Exec("CREATE TABLE permissions (id INTEGER PRIMARY KEY, flags INTEGER)")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = Exec(db, SQLite.InsertInto(p).
+		Columns(p.ID, p.FLAGS).
+		Values(1, BitValue([]bool{true, false, true})).
+		Values(2, BitValue(uint64(6))),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+
+	gotBits, err := FetchOne(db, SQLite.From(p).Where(p.FLAGS.EqBits(uint64(5))), func(row *Row) []bool {
+		return row.BitsField(p.FLAGS)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(gotBits, []bool{true, false, true}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	gotFlags, err := FetchAll(db, SQLite.Queryf("SELECT flags FROM permissions ORDER BY id"), func(row *Row) uint64 {
+		return row.Uint64BitsAt(0)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(gotFlags, []uint64{5, 6}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	gotPermissions, err := FetchAll(db, SQLite.From(p).OrderBy(p.ID), StructRowMapper[Permissions](p))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(gotPermissions, []Permissions{{ID: 1, Flags: 5}, {ID: 2, Flags: 6}}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
+
+func TestCheckFetchableFields(t *testing.T) {
+	actorRowMapper := func(row *Row) Actor {
+		var actor Actor
+		actor.ActorID = row.IntField(ACTOR.ACTOR_ID)
+		actor.FirstName = row.StringField(ACTOR.FIRST_NAME)
+		return actor
+	}
+
+	t.Run("no fetchable fields", func(t *testing.T) {
+		t.Parallel()
+		err := CheckFetchableFields(SQLite.From(ACTOR), actorRowMapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	})
+
+	t.Run("match", func(t *testing.T) {
+		t.Parallel()
+		err := CheckFetchableFields(SQLite.Select(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME).From(ACTOR), actorRowMapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	})
+
+	t.Run("field not selected", func(t *testing.T) {
+		t.Parallel()
+		err := CheckFetchableFields(SQLite.Select(ACTOR.ACTOR_ID, ACTOR.LAST_NAME).From(ACTOR), actorRowMapper)
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		for _, s := range []string{"not selected: actor.first_name", "not used by rowmapper: actor.last_name"} {
+			if !strings.Contains(err.Error(), s) {
+				t.Errorf(testutil.Callers()+" %q does not contain %q", err.Error(), s)
+			}
+		}
+	})
+
+	t.Run("different order", func(t *testing.T) {
+		t.Parallel()
+		err := CheckFetchableFields(SQLite.Select(ACTOR.FIRST_NAME, ACTOR.ACTOR_ID).From(ACTOR), actorRowMapper)
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		if !strings.Contains(err.Error(), "different order") {
+			t.Error(testutil.Callers(), err)
+		}
+	})
+}
+
+func TestScanErrorDiagnostics(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := db.Exec("INSERT INTO actor (first_name, last_name) VALUES ('PENELOPE', 'GUINESS')")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = FetchAll(db, SQLite.From(ACTOR).OrderBy(ACTOR.ACTOR_ID), func(row *Row) int {
+		return row.Int("{}", ACTOR.FIRST_NAME)
+	})
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error but got nil")
+	}
+	want := "actor.first_name => *sql.NullInt64 | column first_name TEXT"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf(testutil.Callers()+" %q does not contain %q", err.Error(), want)
+	}
+}
+
+func TestFetchPointers(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME, ACTOR.LAST_UPDATE).
+		Values(1, "PENELOPE", "GUINESS", time.Unix(1, 0).UTC()),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	type result struct {
+		ActorID    *int
+		FirstName  *string
+		LastUpdate *time.Time
+		Score      *float64
+		IsActive   *bool
+		NullID     *int64
+		NullName   *string
+	}
+	results, err := FetchAll(db, SQLite.From(ACTOR), func(row *Row) result {
+		var r result
+		row.ScanField(&r.ActorID, ACTOR.ACTOR_ID)
+		row.ScanField(&r.FirstName, ACTOR.FIRST_NAME)
+		row.ScanField(&r.LastUpdate, ACTOR.LAST_UPDATE)
+		row.Scan(&r.Score, "1.5")
+		row.Scan(&r.IsActive, "TRUE")
+		row.Scan(&r.NullID, "NULL")
+		row.Scan(&r.NullName, "NULL")
+		return r
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	actorID, firstName, lastUpdate, score, isActive := 1, "PENELOPE", time.Unix(1, 0).UTC(), 1.5, true
+	want := []result{{
+		ActorID:    &actorID,
+		FirstName:  &firstName,
+		LastUpdate: &lastUpdate,
+		Score:      &score,
+		IsActive:   &isActive,
+	}}
+	if diff := testutil.Diff(results, want); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
+
+// upperString is an sql.Scanner that uppercases the scanned string.
+type upperString struct {
+	value string
+}
+
+func (s *upperString) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		s.value = strings.ToUpper(src)
+	case []byte:
+		s.value = strings.ToUpper(string(src))
+	case nil:
+		s.value = ""
+	default:
+		return fmt.Errorf("cannot scan %T into upperString", src)
+	}
+	return nil
+}
+
+func TestFetchScanner(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "penelope", "guiness").
+		Values(2, "nick", "wahlberg"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	want := []string{"PENELOPE", "NICK"}
+
+	t.Run("dynamic query", func(t *testing.T) {
+		t.Parallel()
+		got, err := FetchAll(db, SQLite.From(ACTOR).OrderBy(ACTOR.ACTOR_ID), func(row *Row) string {
+			var s upperString
+			row.ScanField(&s, ACTOR.FIRST_NAME)
+			return s.value
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, want); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("static query", func(t *testing.T) {
+		t.Parallel()
+		got, err := FetchAll(db, SQLite.Queryf("SELECT first_name FROM actor ORDER BY actor_id"), func(row *Row) string {
+			var s upperString
+			row.ScanField(&s, ACTOR.FIRST_NAME)
+			return s.value
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, want); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		t.Parallel()
+		_, err := FetchAll(db, SQLite.From(ACTOR), func(row *Row) string {
+			var s upperString
+			row.ScanField(&s, ACTOR.ACTOR_ID)
+			return s.value
+		})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error, got nil")
+		}
+	})
+}
+
+// queryStatsRecorder is an SqLogger that records the QueryStats of every
+// query.
+type queryStatsRecorder struct {
+	queryStats []QueryStats
+}
+
+func (r *queryStatsRecorder) SqLogSettings(ctx context.Context, logSettings *LogSettings) {}
+
+func (r *queryStatsRecorder) SqLogQuery(ctx context.Context, queryStats QueryStats) {
+	r.queryStats = append(r.queryStats, queryStats)
+}
+
+func TestNewDB(t *testing.T) {
+	t.Parallel()
+	recorder := &queryStatsRecorder{}
+	db := NewDB(struct {
+		DB
+		SqLogger
+	}{DB: newDB(t), SqLogger: recorder}, DialectSQLite)
+	if _, ok := db.(SqLogger); !ok {
+		t.Fatal(testutil.Callers(), "NewDB did not preserve the SqLogger")
+	}
+	_, err := Exec(db, InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = FetchAll(db, From(ACTOR), func(row *Row) int {
+		return row.IntField(ACTOR.ACTOR_ID)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	// A query whose dialect differs from the DB's dialect is rejected.
+	_, err = FetchExists(db, Postgres.Select(ACTOR.ACTOR_ID).From(ACTOR))
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected dialect mismatch error but got nil")
+	}
+	// Unless the mismatch is allowed, in which case the query's own dialect
+	// takes precedence over the DB's dialect.
+	_, err = FetchExistsContext(AllowDialectMismatch(context.Background()), db, Postgres.Select(ACTOR.ACTOR_ID).From(ACTOR))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	var gotDialects []string
+	for _, queryStats := range recorder.queryStats {
+		gotDialects = append(gotDialects, queryStats.Dialect)
+	}
+	wantDialects := []string{DialectSQLite, DialectSQLite, DialectPostgres}
+	if diff := testutil.Diff(gotDialects, wantDialects); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
+
+func TestQuerySize(t *testing.T) {
+	t.Parallel()
+	recorder := &queryStatsRecorder{}
+	db := struct {
+		DB
+		SqLogger
+	}{DB: newDB(t), SqLogger: recorder}
+	_, err := Exec(db, SQLite.InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS").
+		Values(2, "NICK", "WAHLBERG"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = FetchAll(db, SQLite.From(ACTOR).Where(ACTOR.ACTOR_ID.In([]int{1, 2})), func(row *Row) int {
+		return row.IntField(ACTOR.ACTOR_ID)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	var gotArgCounts []int
+	for _, queryStats := range recorder.queryStats {
+		if diff := testutil.Diff(queryStats.QueryLength, len(queryStats.Query)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		gotArgCounts = append(gotArgCounts, queryStats.ArgCount)
+	}
+	if diff := testutil.Diff(gotArgCounts, []int{6, 2}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
+
+func TestSlicePool(t *testing.T) {
+	t.Run("putSlice", func(t *testing.T) {
+		t.Parallel()
+		s := getSlice()
+		*s = append(*s, 1, "two")
+		elems := (*s)[:2]
+		putSlice(s)
+		if diff := testutil.Diff(elems, []any{nil, nil}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(len(*s), 0); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		// Oversized slices are not cleared (or pooled).
+		big := make([]any, 1, maxPooledSliceCap+1)
+		big[0] = 1
+		putSlice(&big)
+		if diff := testutil.Diff(big, []any{1}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("argsCanBePooled", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		if !argsCanBePooled(WithRetry(NewDB(db, DialectSQLite), RetryPolicy{}), nil, nil) {
+			t.Error(testutil.Callers(), "expected args of *sql.DB to be poolable")
+		}
+		if argsCanBePooled(db, &queryStatsRecorder{}, nil) {
+			t.Error(testutil.Callers(), "expected args of a logged DB to not be poolable")
+		}
+		if argsCanBePooled(db, nil, []Interceptor{TranslateErrors()}) {
+			t.Error(testutil.Callers(), "expected args of an intercepted DB to not be poolable")
+		}
+		if argsCanBePooled(WithQueryHooks(db), nil, nil) {
+			t.Error(testutil.Callers(), "expected args of a hooked DB to not be poolable")
+		}
+		if argsCanBePooled(struct{ DB }{db}, nil, nil) {
+			t.Error(testutil.Callers(), "expected args of an unknown DB to not be poolable")
+		}
+	})
+
+	t.Run("repeated fetches", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := Exec(db, SQLite.InsertInto(ACTOR).
+			Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+			Values(1, "PENELOPE", "GUINESS").
+			Values(2, "NICK", "WAHLBERG"),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		for i := 0; i < 10; i++ {
+			actor, err := FetchOne(db, SQLite.From(ACTOR).Where(ACTOR.ACTOR_ID.EqInt(2)), func(row *Row) Actor {
+				return Actor{
+					ActorID:   row.IntField(ACTOR.ACTOR_ID),
+					FirstName: row.StringField(ACTOR.FIRST_NAME),
+				}
+			})
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(actor, Actor{ActorID: 2, FirstName: "NICK"}); diff != "" {
+				t.Fatal(testutil.Callers(), diff)
+			}
+			names, err := FetchAll(db, SQLite.Queryf("SELECT first_name, last_name FROM actor ORDER BY actor_id"), func(row *Row) string {
+				return row.String("first_name") + " " + row.String("last_name")
+			})
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(names, []string{"PENELOPE GUINESS", "NICK WAHLBERG"}); diff != "" {
+				t.Fatal(testutil.Callers(), diff)
+			}
+		}
+	})
+}
+
+func TestQueryHooks(t *testing.T) {
+	t.Parallel()
+	recorder := &queryStatsRecorder{}
+	db := WithQueryHooks(struct {
+		DB
+		SqLogger
+	}{DB: newDB(t), SqLogger: recorder}, func(ctx context.Context, dialect string, query string, args []any) (string, []any, error) {
+		return "/* app=test */ " + query, args, nil
+	})
+	db = WithQueryHooks(db, func(ctx context.Context, dialect string, query string, args []any) (string, []any, error) {
+		if strings.HasPrefix(query, "/* app=test */ DELETE") {
+			return "", nil, fmt.Errorf("deletes are not allowed")
+		}
+		return query, args, nil
+	})
+	_, err := Exec(db, InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = FetchAll(db, From(ACTOR), func(row *Row) int {
+		return row.IntField(ACTOR.ACTOR_ID)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = Exec(db, DeleteFrom(ACTOR).Where(ACTOR.ACTOR_ID.EqInt(1)))
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error but got nil")
+	}
+	exists, err := FetchExists(db, SelectOne().From(ACTOR))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if !exists {
+		t.Error(testutil.Callers(), "the DELETE should not have been executed")
+	}
+	var gotQueries []string
+	for _, queryStats := range recorder.queryStats {
+		gotQueries = append(gotQueries, queryStats.Query)
+	}
+	wantQueries := []string{
+		"/* app=test */ INSERT INTO actor (actor_id, first_name, last_name) VALUES (?, ?, ?)",
+		"/* app=test */ SELECT actor.actor_id FROM actor",
+		"DELETE FROM actor WHERE actor.actor_id = ?",
+		"/* app=test */ SELECT EXISTS (SELECT 1 FROM actor)",
+	}
+	if diff := testutil.Diff(gotQueries, wantQueries); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
+
+// countingInterceptor is an Interceptor that counts queries and vetoes
+// DELETEs.
+type countingInterceptor struct {
+	before, after int
+	errs          []error
+}
+
+func (i *countingInterceptor) BeforeQuery(ctx context.Context, queryStats QueryStats) (context.Context, error) {
+	if strings.HasPrefix(queryStats.Query, "DELETE") {
+		return ctx, fmt.Errorf("deletes are not allowed")
+	}
+	i.before++
+	return ctx, nil
+}
+
+func (i *countingInterceptor) AfterQuery(ctx context.Context, queryStats QueryStats) error {
+	i.after++
+	i.errs = append(i.errs, queryStats.Err)
+	return queryStats.Err
+}
+
+func TestInterceptors(t *testing.T) {
+	t.Parallel()
+	interceptor := &countingInterceptor{}
+	db := WithInterceptors(newDB(t), interceptor)
+	_, err := Exec(db, InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = FetchAll(db, From(ACTOR), func(row *Row) int {
+		return row.IntField(ACTOR.ACTOR_ID)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = Exec(db, DeleteFrom(ACTOR).Where(ACTOR.ACTOR_ID.EqInt(1)))
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error but got nil")
+	}
+	_, err = FetchAll(db, From(Expr("nonexistent_table")), func(row *Row) int {
+		return row.Int("1")
+	})
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error but got nil")
+	}
+	if diff := testutil.Diff(interceptor.before, 3); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if diff := testutil.Diff(interceptor.after, 3); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if len(interceptor.errs) == 3 && (interceptor.errs[0] != nil || interceptor.errs[1] != nil || interceptor.errs[2] == nil) {
+		t.Errorf(testutil.Callers()+" unexpected query errors: %v", interceptor.errs)
+	}
+}
+
+func TestInferDialect(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	defer conn.Close()
+	tests := []struct {
+		description string
+		db          DB
+		wantDialect string
+	}{
+		{"driver", db, DialectSQLite},
+		{"wrapped driver", Log(db), DialectSQLite},
+		{"version query", conn, DialectSQLite},
+		{"NewDB", NewDB(db, DialectPostgres), DialectPostgres},
+		{"postgres", versionDB{conn, map[string]string{
+			"SELECT version()": "PostgreSQL 15.4 on x86_64-pc-linux-gnu",
+		}}, DialectPostgres},
+		{"mysql", versionDB{conn, map[string]string{
+			"SELECT version()":         "8.0.34",
+			"SELECT @@version_comment": "MySQL Community Server - GPL",
+		}}, DialectMySQL},
+		{"mariadb", versionDB{conn, map[string]string{
+			"SELECT version()": "10.11.5-MariaDB-1:10.11.5+maria~ubu2204",
+		}}, DialectMySQL},
+		{"sqlserver", versionDB{conn, map[string]string{
+			"SELECT @@VERSION": "Microsoft SQL Server 2022 (RTM) - 16.0.1000.6 (X64)",
+		}}, DialectSQLServer},
+	}
+	for _, tt := range tests {
+		gotDialect, err := InferDialect(tt.db)
+		if err != nil {
+			t.Fatal(testutil.Callers(), tt.description, err)
+		}
+		if diff := testutil.Diff(gotDialect, tt.wantDialect); diff != "" {
+			t.Error(testutil.Callers(), tt.description, diff)
+		}
+	}
+	_, err = InferDialect(versionDB{conn, map[string]string{
+		"SELECT version()": "CockroachDB CCL v23.1.11",
+	}})
+	if err == nil {
+		t.Error(testutil.Callers(), "expected error for an unknown version but got nil")
+	}
+}
+
+// versionDB answers the version queries in versions with their version and
+// fails every other query.
+type versionDB struct {
+	*sql.Conn
+	versions map[string]string
+}
+
+func (db versionDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	version, ok := db.versions[query]
+	if !ok {
+		return nil, fmt.Errorf("unsupported query %q", query)
+	}
+	return db.Conn.QueryContext(ctx, "SELECT ?", version)
+}
+
+func TestPing(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	defer conn.Close()
+	for _, db := range []DB{db, conn, NewDB(db, DialectSQLite), Log(db)} {
+		err := Ping(db)
+		if err != nil {
+			t.Errorf(testutil.Callers()+" %T: %v", db, err)
+		}
+	}
+	if _, ok := DBStats(NewDB(db, DialectSQLite)); !ok {
+		t.Error(testutil.Callers(), "expected DBStats of an *sql.DB")
+	}
+	if _, ok := DBStats(conn); ok {
+		t.Error(testutil.Callers(), "expected no DBStats for an *sql.Conn")
+	}
+	err = WaitForDB(context.Background(), db, nil)
+	if err != nil {
+		t.Error(testutil.Callers(), err)
+	}
+
+	closedDB := newDB(t)
+	closedDB.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = WaitForDB(ctx, closedDB, func(attempt int) time.Duration { return time.Millisecond })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf(testutil.Callers()+" expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+type TENANT_ACTOR struct {
+	TableStruct `sq:"actor"`
+	ACTOR_ID    NumberField
+	FIRST_NAME  StringField
+}
+
+func (tbl TENANT_ACTOR) Policy(ctx context.Context, dialect string) (Predicate, error) {
+	tenantID, ok := TenantID(ctx)
+	if !ok {
+		return nil, fmt.Errorf("tenant ID not provided")
+	}
+	return tbl.FIRST_NAME.EqString(tenantID), nil
+}
+
+func TestContextIDs(t *testing.T) {
+	t.Parallel()
+	recorder := &queryStatsRecorder{}
+	db := struct {
+		DB
+		SqLogger
+	}{DB: newDB(t), SqLogger: recorder}
+	_, err := Exec(db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS").
+		Values(2, "NICK", "WAHLBERG"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	a := New[TENANT_ACTOR]("")
+	_, err = FetchAll(db, SQLite.From(a), func(row *Row) int {
+		return row.IntField(a.ACTOR_ID)
+	})
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error for missing tenant ID, got nil")
+	}
+	ctx := WithRequestID(WithUserID(WithTenantID(context.Background(), "NICK"), "user1"), "req1")
+	actorIDs, err := FetchAllContext(ctx, db, SQLite.From(a), func(row *Row) int {
+		return row.IntField(a.ACTOR_ID)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(actorIDs, []int{2}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	queryStats := recorder.queryStats[len(recorder.queryStats)-1]
+	gotIDs := []string{queryStats.TenantID, queryStats.UserID, queryStats.RequestID}
+	if diff := testutil.Diff(gotIDs, []string{"NICK", "user1", "req1"}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
+
+func TestRowMapperTableStruct(t *testing.T) {
+	type ACTOR struct {
+		TableStruct
+		ACTOR_ID   NumberField
+		FIRST_NAME StringField
+	}
+	db := newDB(t)
+	a := New[ACTOR]("a")
+	wantErr := `rowmapper returns the table struct sq.ACTOR, which describes the fields of a table but cannot hold the values of a row; return a plain data struct instead (sq.GenerateRowMapper[T](w, sq.New[sq.ACTOR]("")) can generate the rowmapper for it)`
+
+	t.Run("FetchAll", func(t *testing.T) {
+		_, err := FetchAll(db, SQLite.From(a), func(row *Row) ACTOR { return a })
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		if diff := testutil.Diff(err.Error(), wantErr); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		_, err := FetchOne(db, SQLite.From(a), func(row *Row) *ACTOR { return &a })
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+	})
+
+	t.Run("CompileFetch", func(t *testing.T) {
+		_, err := CompileFetch(SQLite.From(a), func(row *Row) ACTOR { return a })
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
+
+// connDoneDB is a DB whose first prepared statement is prepared on a
+// connection that is closed right after, so that using the statement fails
+// with sql.ErrConnDone.
+type connDoneDB struct {
+	*sql.DB
+	conn *sql.Conn
+}
+
+func (db *connDoneDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if db.conn == nil {
+		return db.DB.PrepareContext(ctx, query)
+	}
+	conn := db.conn
+	db.conn = nil
+	defer conn.Close()
+	return conn.PrepareContext(ctx, query)
+}
+
+func TestPreparedFetchReprepare(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME, ACTOR.LAST_UPDATE).
+		Values(1, "PENELOPE", "GUINESS", time.Unix(1, 0).UTC()),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	query := SQLite.From(ACTOR).Where(ACTOR.ACTOR_ID.Eq(IntParam("actor_id", 0)))
+	rowmapper := func(row *Row) string { return row.StringField(ACTOR.FIRST_NAME) }
+
+	t.Run("automatic", func(t *testing.T) {
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		preparedFetch, err := PrepareFetch(&connDoneDB{DB: db, conn: conn}, query, rowmapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer preparedFetch.Close()
+		firstName, err := preparedFetch.FetchOne(Params{"actor_id": 1})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(firstName, "PENELOPE"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("concurrently re-prepared", func(t *testing.T) {
+		ps, err := newPreparedStmt(context.Background(), db, "SELECT first_name FROM actor WHERE actor_id = $1")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer ps.close()
+		var calls int
+		var firstName string
+		err = ps.run(context.Background(), func(stmt *sql.Stmt) error {
+			calls++
+			if calls == 1 {
+				// Simulate another goroutine re-preparing the statement
+				// (which closes the old one) while it is being used.
+				err := ps.reprepare(context.Background(), stmt)
+				if err != nil {
+					return err
+				}
+			}
+			return stmt.QueryRowContext(context.Background(), 1).Scan(&firstName)
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(calls, 2); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(firstName, "PENELOPE"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("explicit", func(t *testing.T) {
+		preparedFetch, err := PrepareFetch(db, query, rowmapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		err = preparedFetch.Reprepare()
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		firstName, err := preparedFetch.FetchOne(Params{"actor_id": 1})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(firstName, "PENELOPE"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		err = preparedFetch.Close()
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		err = preparedFetch.Reprepare()
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
+
+func TestIsStaleStatementError(t *testing.T) {
+	type TestTable struct {
+		description string
+		err         error
+		want        bool
+	}
+
+	tests := []TestTable{{
+		description: "conn done",
+		err:         fmt.Errorf("query: %w", sql.ErrConnDone),
+		want:        true,
+	}, {
+		description: "postgres cached plan",
+		err:         errors.New("pq: cached plan must not change result type"),
+		want:        true,
+	}, {
+		description: "postgres prepared statement does not exist",
+		err:         &fakePQError{Code: "26000"},
+		want:        true,
+	}, {
+		description: "mysql need reprepare",
+		err:         &fakeMySQLError{Number: 1615, Message: "Prepared statement needs to be re-prepared"},
+		want:        true,
+	}, {
+		description: "unique violation",
+		err:         &fakePQError{Code: "23505"},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			if diff := testutil.Diff(isStaleStatementError(tt.err), tt.want); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
 }
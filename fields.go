@@ -49,7 +49,10 @@ func NewAnyField(name string, tbl TableStruct) AnyField {
 
 // WriteSQL implements the SQLWriter interface.
 func (field AnyField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	err := writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	if err != nil {
+		return err
+	}
 	writeFieldOrder(ctx, dialect, buf, args, params, field.desc, field.nullsfirst)
 	return nil
 }
@@ -105,6 +108,9 @@ func (field AnyField) IsNull() Predicate { return Expr("{} IS NULL", field) }
 // IsNotNull returns a 'field IS NOT NULL' Predicate.
 func (field AnyField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
 
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field AnyField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
 // In returns a 'field IN (value)' Predicate. The value can be a slice, which
 // corresponds to the expression 'field IN (x, y, z)'.
 func (field AnyField) In(value any) Predicate { return In(field, value) }
@@ -202,8 +208,7 @@ func NewArrayField(fieldName string, tableName TableStruct) ArrayField {
 
 // WriteSQL implements the SQLWriter interface.
 func (field ArrayField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
-	return nil
+	return writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
 }
 
 // As returns a new ArrayField with the given alias.
@@ -225,6 +230,9 @@ func (field ArrayField) IsNull() Predicate { return Expr("{} IS NULL", field) }
 // IsNull returns a 'field IS NOT NULL' Predicate.
 func (field ArrayField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
 
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field ArrayField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
 // Set returns an Assignment assigning the value to the field.
 func (field ArrayField) Set(value any) Assignment {
 	switch value.(type) {
@@ -278,7 +286,10 @@ func NewBinaryField(fieldName string, tableName TableStruct) BinaryField {
 
 // WriteSQL implements the SQLWriter interface.
 func (field BinaryField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	err := writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	if err != nil {
+		return err
+	}
 	writeFieldOrder(ctx, dialect, buf, args, params, field.desc, field.nullsfirst)
 	return nil
 }
@@ -334,6 +345,9 @@ func (field BinaryField) IsNull() Predicate { return Expr("{} IS NULL", field) }
 // IsNotNull returns a 'field IS NOT NULL' Predicate.
 func (field BinaryField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
 
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field BinaryField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
 // Eq returns a 'field = value' Predicate.
 func (field BinaryField) Eq(value Binary) Predicate { return Eq(field, value) }
 
@@ -368,6 +382,134 @@ func (field BinaryField) IsField() {}
 // IsBinary implements the Binary interface.
 func (field BinaryField) IsBinary() {}
 
+// BitField represents an SQL BIT or VARBIT field. Its values can be mapped to
+// a uint64 or a []bool (see BitValue, Row.Bits and Row.Uint64Bits).
+type BitField struct {
+	table      TableStruct
+	name       string
+	alias      string
+	desc       sql.NullBool
+	nullsfirst sql.NullBool
+}
+
+var _ interface {
+	Field
+	WithPrefix(string) Field
+} = (*BitField)(nil)
+
+// NewBitField returns a new BitField.
+func NewBitField(name string, tbl TableStruct) BitField {
+	return BitField{table: tbl, name: name}
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (field BitField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	err := writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	if err != nil {
+		return err
+	}
+	writeFieldOrder(ctx, dialect, buf, args, params, field.desc, field.nullsfirst)
+	return nil
+}
+
+// As returns a new BitField with the given alias.
+func (field BitField) As(alias string) BitField {
+	field.alias = alias
+	return field
+}
+
+// Asc returns a new BitField indicating that it should be ordered in ascending
+// order i.e. 'ORDER BY field ASC'.
+func (field BitField) Asc() BitField {
+	field.desc.Valid = true
+	field.desc.Bool = false
+	return field
+}
+
+// Desc returns a new BitField indicating that it should be ordered in ascending
+// order i.e. 'ORDER BY field DESC'.
+func (field BitField) Desc() BitField {
+	field.desc.Valid = true
+	field.desc.Bool = true
+	return field
+}
+
+// NullsLast returns a new BitField indicating that it should be ordered
+// with nulls last i.e. 'ORDER BY field NULLS LAST'.
+func (field BitField) NullsLast() BitField {
+	field.nullsfirst.Valid = true
+	field.nullsfirst.Bool = false
+	return field
+}
+
+// NullsFirst returns a new BitField indicating that it should be ordered
+// with nulls first i.e. 'ORDER BY field NULLS FIRST'.
+func (field BitField) NullsFirst() BitField {
+	field.nullsfirst.Valid = true
+	field.nullsfirst.Bool = true
+	return field
+}
+
+// WithPrefix returns a new Field that with the given prefix.
+func (field BitField) WithPrefix(prefix string) Field {
+	field.table.alias = ""
+	field.table.name = prefix
+	return field
+}
+
+// IsNull returns a 'field IS NULL' Predicate.
+func (field BitField) IsNull() Predicate { return Expr("{} IS NULL", field) }
+
+// IsNotNull returns a 'field IS NOT NULL' Predicate.
+func (field BitField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
+
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field BitField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
+// In returns a 'field IN (value)' Predicate. The value can be a slice, which
+// corresponds to the expression 'field IN (x, y, z)'.
+func (field BitField) In(value any) Predicate { return In(field, value) }
+
+// NotIn returns a 'field NOT IN (value)' Predicate. The value can be a slice,
+// which corresponds to the expression 'field NOT IN (x, y, z)'.
+func (field BitField) NotIn(value any) Predicate { return NotIn(field, value) }
+
+// Eq returns a 'field = value' Predicate.
+func (field BitField) Eq(value any) Predicate { return Eq(field, value) }
+
+// Ne returns a 'field <> value' Predicate.
+func (field BitField) Ne(value any) Predicate { return Ne(field, value) }
+
+// EqBits returns a 'field = value' Predicate. The value is wrapped in
+// BitValue().
+func (field BitField) EqBits(value any) Predicate { return Eq(field, BitValue(value)) }
+
+// NeBits returns a 'field <> value' Predicate. The value is wrapped in
+// BitValue().
+func (field BitField) NeBits(value any) Predicate { return Ne(field, BitValue(value)) }
+
+// Set returns an Assignment assigning the value to the field.
+func (field BitField) Set(value any) Assignment {
+	return Set(field, value)
+}
+
+// SetBits returns an Assignment assigning the value to the field. It wraps the
+// value in BitValue().
+func (field BitField) SetBits(value any) Assignment {
+	return Set(field, BitValue(value))
+}
+
+// Setf returns an Assignment assigning an expression to the field.
+func (field BitField) Setf(format string, values ...any) Assignment {
+	return Setf(field, format, values...)
+}
+
+// GetAlias returns the alias of the BitField.
+func (field BitField) GetAlias() string { return field.alias }
+
+// IsField implements the Field interface.
+func (field BitField) IsField() {}
+
 // BooleanField represents an SQL boolean field.
 type BooleanField struct {
 	table      TableStruct
@@ -391,7 +533,10 @@ func NewBooleanField(fieldName string, tableName TableStruct) BooleanField {
 
 // WriteSQL implements the SQLWriter interface.
 func (field BooleanField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	err := writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	if err != nil {
+		return err
+	}
 	writeFieldOrder(ctx, dialect, buf, args, params, field.desc, field.nullsfirst)
 	return nil
 }
@@ -447,6 +592,9 @@ func (field BooleanField) IsNull() Predicate { return Expr("{} IS NULL", field)
 // IsNotNull returns a 'field IS NOT NULL' Predicate.
 func (field BooleanField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
 
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field BooleanField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
 // Eq returns a 'field = value' Predicate.
 func (field BooleanField) Eq(value Boolean) Predicate { return Eq(field, value) }
 
@@ -502,8 +650,7 @@ func NewEnumField(name string, tbl TableStruct) EnumField {
 
 // WriteSQL implements the SQLWriter interface.
 func (field EnumField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
-	return nil
+	return writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
 }
 
 // As returns a new EnumField with the given alias.
@@ -525,6 +672,9 @@ func (field EnumField) IsNull() Predicate { return Expr("{} IS NULL", field) }
 // IsNotNull returns a 'field IS NOT NULL' Predicate.
 func (field EnumField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
 
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field EnumField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
 // In returns a 'field IN (value)' Predicate. The value can be a slice, which
 // corresponds to the expression 'field IN (x, y, z)'.
 func (field EnumField) In(value any) Predicate { return In(field, value) }
@@ -594,8 +744,7 @@ func NewJSONField(name string, tbl TableStruct) JSONField {
 
 // WriteSQL implements the SQLWriter interface.
 func (field JSONField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
-	return nil
+	return writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
 }
 
 // As returns a new JSONField with the given alias.
@@ -617,6 +766,9 @@ func (field JSONField) IsNull() Predicate { return Expr("{} IS NULL", field) }
 // IsNotNull returns a 'field IS NOT NULL' Predicate.
 func (field JSONField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
 
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field JSONField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
 // Set returns an Assignment assigning the value to the field.
 func (field JSONField) Set(value any) Assignment {
 	switch value.(type) {
@@ -678,7 +830,10 @@ func NewNumberField(name string, tbl TableStruct) NumberField {
 
 // WriteSQL implements the SQLWriter interface.
 func (field NumberField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	err := writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	if err != nil {
+		return err
+	}
 	writeFieldOrder(ctx, dialect, buf, args, params, field.desc, field.nullsfirst)
 	return nil
 }
@@ -734,6 +889,9 @@ func (field NumberField) IsNull() Predicate { return Expr("{} IS NULL", field) }
 // IsNotNull returns a 'field IS NOT NULL' Predicate.
 func (field NumberField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
 
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field NumberField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
 // In returns a 'field IN (value)' Predicate. The value can be a slice, which
 // corresponds to the expression 'field IN (x, y, z)'.
 func (field NumberField) In(value any) Predicate { return In(field, value) }
@@ -865,7 +1023,10 @@ func NewStringField(name string, tbl TableStruct) StringField {
 
 // WriteSQL implements the SQLWriter interface.
 func (field StringField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	err := writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	if err != nil {
+		return err
+	}
 	if field.collation != "" {
 		buf.WriteString(" COLLATE ")
 		if dialect == DialectPostgres {
@@ -935,6 +1096,9 @@ func (field StringField) IsNull() Predicate { return Expr("{} IS NULL", field) }
 // IsNotNull returns a 'field IS NOT NULL' Predicate.
 func (field StringField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
 
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field StringField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
 // In returns a 'field IN (value)' Predicate. The value can be a slice, which
 // corresponds to the expression 'field IN (x, y, z)'.
 func (field StringField) In(value any) Predicate { return In(field, value) }
@@ -1043,7 +1207,10 @@ func NewTimeField(name string, tbl TableStruct) TimeField {
 
 // WriteSQL implements the SQLWriter interface.
 func (field TimeField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	err := writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	if err != nil {
+		return err
+	}
 	writeFieldOrder(ctx, dialect, buf, args, params, field.desc, field.nullsfirst)
 	return nil
 }
@@ -1099,6 +1266,9 @@ func (field TimeField) IsNull() Predicate { return Expr("{} IS NULL", field) }
 // IsNotNull returns a 'field IS NOT NULL' Predicate.
 func (field TimeField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
 
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field TimeField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
 // In returns a 'field IN (value)' Predicate. The value can be a slice, which
 // corresponds to the expression 'field IN (x, y, z)'.
 func (field TimeField) In(value any) Predicate { return In(field, value) }
@@ -1326,7 +1496,10 @@ func NewUUIDField(name string, tbl TableStruct) UUIDField {
 
 // WriteSQL implements the SQLWriter interface.
 func (field UUIDField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	err := writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+	if err != nil {
+		return err
+	}
 	writeFieldOrder(ctx, dialect, buf, args, params, field.desc, field.nullsfirst)
 	return nil
 }
@@ -1382,6 +1555,9 @@ func (field UUIDField) IsNull() Predicate { return Expr("{} IS NULL", field) }
 // IsNotNull returns a 'field IS NOT NULL' Predicate.
 func (field UUIDField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
 
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field UUIDField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
 // In returns a 'field IN (value)' Predicate. The value can be a slice, which
 // corresponds to the expression 'field IN (x, y, z)'.
 func (field UUIDField) In(value any) Predicate { return In(field, value) }
@@ -1429,8 +1605,160 @@ func (field UUIDField) IsField() {}
 // IsUUID implements the UUID interface.
 func (field UUIDField) IsUUID() {}
 
+// XMLField represents an SQL XML field. Its values can be read with
+// Row.StringField or Row.BytesField.
+type XMLField struct {
+	table TableStruct
+	name  string
+	alias string
+}
+
+var _ interface {
+	Field
+	Binary
+	String
+	WithPrefix(string) Field
+} = (*XMLField)(nil)
+
+// NewXMLField returns a new XMLField.
+func NewXMLField(name string, tbl TableStruct) XMLField {
+	return XMLField{table: tbl, name: name}
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (field XMLField) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	return writeFieldIdentifier(ctx, dialect, buf, args, params, field.table, field.name)
+}
+
+// As returns a new XMLField with the given alias.
+func (field XMLField) As(alias string) XMLField {
+	field.alias = alias
+	return field
+}
+
+// WithPrefix returns a new Field that with the given prefix.
+func (field XMLField) WithPrefix(prefix string) Field {
+	field.table.alias = ""
+	field.table.name = prefix
+	return field
+}
+
+// IsNull returns a 'field IS NULL' Predicate.
+func (field XMLField) IsNull() Predicate { return Expr("{} IS NULL", field) }
+
+// IsNotNull returns a 'field IS NOT NULL' Predicate.
+func (field XMLField) IsNotNull() Predicate { return Expr("{} IS NOT NULL", field) }
+
+// CastTo returns a 'CAST(field AS typ)' expression.
+func (field XMLField) CastTo(typ string) CastExpression { return Cast(field, typ) }
+
+// Value returns the string value of the node selected by the XPath
+// expression. The xpath is passed through as-is, so it must select a single
+// node in SQL Server and a text node in Postgres (e.g. /film/title/text()).
+//
+//   - SQL Server: field.value('xpath', 'nvarchar(max)')
+//   - Postgres: (xpath('xpath', field))[1]::text
+//   - MySQL: ExtractValue(field, 'xpath')
+func (field XMLField) Value(xpath string) Expression {
+	return Expr("{}", xmlMethod{field: field, method: "value", xpath: xpath})
+}
+
+// Exist returns a Predicate that is true if the XPath expression selects at
+// least one node.
+//
+//   - SQL Server: field.exist('xpath') = 1
+//   - Postgres: xpath_exists('xpath', field)
+//   - MySQL: ExtractValue(field, 'count(xpath)') > 0
+func (field XMLField) Exist(xpath string) Predicate {
+	return Expr("{}", xmlMethod{field: field, method: "exist", xpath: xpath})
+}
+
+// Query returns the XML fragment selected by the XPath expression. MySQL is
+// not supported as its XML functions only return text.
+//
+//   - SQL Server: field.query('xpath')
+//   - Postgres: array_to_string(xpath('xpath', field), ”)
+func (field XMLField) Query(xpath string) Expression {
+	return Expr("{}", xmlMethod{field: field, method: "query", xpath: xpath})
+}
+
+// Set returns an Assignment assigning the value to the field.
+func (field XMLField) Set(value any) Assignment {
+	return Set(field, value)
+}
+
+// Setf returns an Assignment assigning an expression to the field.
+func (field XMLField) Setf(format string, values ...any) Assignment {
+	return Setf(field, format, values...)
+}
+
+// GetAlias returns the alias of the XMLField.
+func (field XMLField) GetAlias() string { return field.alias }
+
+// IsField implements the Field interface.
+func (field XMLField) IsField() {}
+
+// IsBinary implements the Binary interface.
+func (field XMLField) IsBinary() {}
+
+// IsString implements the String interface.
+func (field XMLField) IsString() {}
+
+type xmlMethod struct {
+	field  XMLField
+	method string
+	xpath  string
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (m xmlMethod) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	// XPath expressions must be string literals in SQL Server.
+	xpath := "'" + EscapeQuote(m.xpath, '\'') + "'"
+	var prefix, suffix string
+	switch dialect {
+	case DialectSQLServer:
+		switch m.method {
+		case "value":
+			suffix = ".value(" + xpath + ", 'nvarchar(max)')"
+		case "exist":
+			suffix = ".exist(" + xpath + ") = 1"
+		case "query":
+			suffix = ".query(" + xpath + ")"
+		}
+	case DialectPostgres:
+		switch m.method {
+		case "value":
+			prefix, suffix = "(xpath("+xpath+", ", "))[1]::text"
+		case "exist":
+			prefix, suffix = "xpath_exists("+xpath+", ", ")"
+		case "query":
+			prefix, suffix = "array_to_string(xpath("+xpath+", ", "), '')"
+		}
+	case DialectMySQL:
+		switch m.method {
+		case "value":
+			prefix, suffix = "ExtractValue(", ", "+xpath+")"
+		case "exist":
+			prefix, suffix = "ExtractValue(", ", 'count("+EscapeQuote(m.xpath, '\'')+")') > 0"
+		case "query":
+			return fmt.Errorf("mysql does not support XML queries, use Value instead")
+		}
+	default:
+		return fmt.Errorf("%s does not support XML", dialect)
+	}
+	buf.WriteString(prefix)
+	err := m.field.WriteSQL(ctx, dialect, buf, args, params)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(suffix)
+	return nil
+}
+
 // New instantiates a new table struct with the given alias. Passing in an
-// empty string is equivalent to giving no alias to the table.
+// empty string is equivalent to giving no alias to the table. New panics if
+// the table or column names (taken from the struct tags) contain quote or
+// control characters (see ValidateIdentifier).
 func New[T Table](alias string) T {
 	var tbl T
 	ptrvalue := reflect.ValueOf(&tbl)
@@ -1454,16 +1782,43 @@ func New[T Table](alias string) T {
 	if !firstfield.CanSet() {
 		return tbl
 	}
-	tag := firstfieldType.Tag.Get("sq")
-	tableSchema, tableName, ok := strings.Cut(tag, ".")
-	if !ok {
-		tableSchema, tableName = "", tableSchema
+	tag, tableOptions, _ := strings.Cut(firstfieldType.Tag.Get("sq"), ",")
+	var tableQuoted bool
+	for _, option := range strings.Split(tableOptions, ",") {
+		switch option {
+		case "":
+		case "quoted":
+			tableQuoted = true
+		default:
+			panic(fmt.Errorf("sq: %s: unknown struct tag option %q", typ.Name(), option))
+		}
+	}
+	// Everything before the last dot is the schema, which may itself be
+	// qualified with a database and server (SQL Server three-part and
+	// four-part names).
+	var tableSchema, tableName string
+	if i := strings.LastIndexByte(tag, '.'); i >= 0 {
+		tableSchema, tableName = tag[:i], tag[i+1:]
+	} else {
+		tableName = tag
 	}
 	if tableName == "" {
-		tableName = strings.ToLower(typ.Name())
+		tableName = translateName(typ.Name())
 	}
-	tableStruct := NewTableStruct(tableSchema, tableName, alias)
-	firstfield.Set(reflect.ValueOf(tableStruct))
+	for _, identifier := range []string{tableSchema, tableName} {
+		if identifier == "" {
+			continue
+		}
+		if err := ValidateIdentifier("", identifier); err != nil {
+			panic(fmt.Errorf("sq: %s: invalid table name: %w", typ.Name(), err))
+		}
+	}
+	// The column names and options are parsed before any field is created,
+	// because the quoted columns are recorded in the TableStruct that every
+	// field holds a copy of.
+	names := make([]string, value.NumField())
+	groupIndexes := make(map[string][]int)
+	var quotedColumns map[string]bool
 	for i := 1; i < value.NumField(); i++ {
 		v := value.Field(i)
 		if !v.CanInterface() {
@@ -1473,17 +1828,54 @@ func New[T Table](alias string) T {
 			continue
 		}
 		fieldType := typ.Field(i)
-		name := fieldType.Tag.Get("sq")
+		name, options, _ := strings.Cut(fieldType.Tag.Get("sq"), ",")
 		if name == "" {
-			name = strings.ToLower(fieldType.Name)
+			name = translateName(fieldType.Name)
 		}
+		names[i] = name
 		switch v.Interface().(type) {
+		case AnyField, ArrayField, BinaryField, BitField, BooleanField, EnumField, JSONField, NumberField, StringField, TimeField, UUIDField, XMLField, genericColumn:
+			if err := ValidateIdentifier("", name); err != nil {
+				panic(fmt.Errorf("sq: %s.%s: invalid column name: %w", typ.Name(), fieldType.Name, err))
+			}
+			opts, err := parseColumnOptions(options)
+			if err != nil {
+				panic(fmt.Errorf("sq: %s.%s: %w", typ.Name(), fieldType.Name, err))
+			}
+			for _, group := range opts.groups {
+				groupIndexes[group] = append(groupIndexes[group], i)
+			}
+			if opts.quoted {
+				if quotedColumns == nil {
+					quotedColumns = make(map[string]bool)
+				}
+				quotedColumns[name] = true
+			}
+		}
+	}
+	tableStruct := NewTableStruct(tableSchema, tableName, alias)
+	if tableQuoted || len(quotedColumns) > 0 {
+		tableStruct.quoted = &quotedIdentifiers{table: tableQuoted, columns: quotedColumns}
+	}
+	firstfield.Set(reflect.ValueOf(tableStruct))
+	for i := 1; i < value.NumField(); i++ {
+		v := value.Field(i)
+		if !v.CanInterface() {
+			continue
+		}
+		if !v.CanSet() {
+			continue
+		}
+		name := names[i]
+		switch field := v.Interface().(type) {
 		case AnyField:
 			v.Set(reflect.ValueOf(NewAnyField(name, tableStruct)))
 		case ArrayField:
 			v.Set(reflect.ValueOf(NewArrayField(name, tableStruct)))
 		case BinaryField:
 			v.Set(reflect.ValueOf(NewBinaryField(name, tableStruct)))
+		case BitField:
+			v.Set(reflect.ValueOf(NewBitField(name, tableStruct)))
 		case BooleanField:
 			v.Set(reflect.ValueOf(NewBooleanField(name, tableStruct)))
 		case EnumField:
@@ -1498,21 +1890,206 @@ func New[T Table](alias string) T {
 			v.Set(reflect.ValueOf(NewTimeField(name, tableStruct)))
 		case UUIDField:
 			v.Set(reflect.ValueOf(NewUUIDField(name, tableStruct)))
+		case XMLField:
+			v.Set(reflect.ValueOf(NewXMLField(name, tableStruct)))
+		case genericColumn:
+			v.Set(reflect.ValueOf(field.newColumn(name, tableStruct)))
+		}
+	}
+	// Column groups are only recorded if the table struct declares any, so
+	// that the TableStruct of tables without groups stays the same as one
+	// created by NewTableStruct.
+	if len(groupIndexes) > 0 {
+		tableStruct.groups = &columnGroups{fields: make(map[string][]Field)}
+		for group, indexes := range groupIndexes {
+			for _, i := range indexes {
+				tableStruct.groups.fields[group] = append(tableStruct.groups.fields[group], value.Field(i).Interface().(Field))
+			}
 		}
+		firstfield.Set(reflect.ValueOf(tableStruct))
 	}
 	return tbl
 }
 
-func writeFieldIdentifier(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int, table TableStruct, fieldName string) {
+// StarOf returns the fields of a table struct in struct field order. Use it
+// instead of SELECT * to keep "give me everything" queries explicit: the
+// columns are written out one by one, and a column removed from the table
+// struct stops being selected instead of silently changing the shape of the
+// result.
+//
+//	a := sq.New[ACTOR]("a")
+//	// SELECT a.actor_id, a.first_name, a.last_name, a.last_update FROM actor AS a
+//	q := sq.Select(sq.StarOf(a)...).From(a)
+func StarOf(table Table) []Field {
+	fields, _ := tableFields(table)
+	return fields
+}
+
+// tableFields returns the fields of a table struct (in struct field order)
+// together with their column names.
+func tableFields(table Table) (fields []Field, names []string) {
+	fields, names, _ = tableStructFields(table)
+	return fields, names
+}
+
+// tableStructFields is like tableFields but additionally returns the Go name
+// of each field in the table struct.
+func tableStructFields(table Table) (fields []Field, names []string, goNames []string) {
+	value := reflect.Indirect(reflect.ValueOf(table))
+	if value.Kind() != reflect.Struct {
+		return nil, nil, nil
+	}
+	typ := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		if !typ.Field(i).IsExported() {
+			continue
+		}
+		var name string
+		switch field := value.Field(i).Interface().(type) {
+		case AnyField:
+			fields, name = append(fields, field), field.name
+		case ArrayField:
+			fields, name = append(fields, field), field.name
+		case BinaryField:
+			fields, name = append(fields, field), field.name
+		case BitField:
+			fields, name = append(fields, field), field.name
+		case BooleanField:
+			fields, name = append(fields, field), field.name
+		case EnumField:
+			fields, name = append(fields, field), field.name
+		case JSONField:
+			fields, name = append(fields, field), field.name
+		case NumberField:
+			fields, name = append(fields, field), field.name
+		case StringField:
+			fields, name = append(fields, field), field.name
+		case TimeField:
+			fields, name = append(fields, field), field.name
+		case UUIDField:
+			fields, name = append(fields, field), field.name
+		case XMLField:
+			fields, name = append(fields, field), field.name
+		case genericColumn:
+			fields, name = append(fields, field), field.columnName()
+		default:
+			continue
+		}
+		names, goNames = append(names, name), append(goNames, typ.Field(i).Name)
+	}
+	return fields, names, goNames
+}
+
+// columnOptions are the options that follow the column name in the struct
+// tag of a table struct field e.g.
+// `sq:"actor_id,references=actor.actor_id,onDelete=cascade"`.
+type columnOptions struct {
+	references string // [schema.]table.column
+	onDelete   string
+	onUpdate   string
+	check      string
+	groups     []string
+	quoted     bool
+}
+
+// parseColumnOptions parses the comma-separated key=value options of a column
+// struct tag. Since a check expression may itself contain commas, check= must
+// be the last option and takes up the rest of the tag.
+func parseColumnOptions(options string) (columnOptions, error) {
+	var opts columnOptions
+	for options != "" {
+		if strings.HasPrefix(options, "check=") {
+			opts.check = strings.TrimPrefix(options, "check=")
+			if opts.check == "" {
+				return opts, fmt.Errorf("empty check expression")
+			}
+			break
+		}
+		var option string
+		option, options, _ = strings.Cut(options, ",")
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "references":
+			if strings.Count(value, ".") < 1 || strings.HasPrefix(value, ".") || strings.HasSuffix(value, ".") {
+				return opts, fmt.Errorf("invalid references=%s: must be of the form table.column", value)
+			}
+			opts.references = value
+		case "onDelete", "onUpdate":
+			action := strings.ToUpper(value)
+			switch action {
+			case "CASCADE", "RESTRICT", "NO ACTION", "SET NULL", "SET DEFAULT":
+			default:
+				return opts, fmt.Errorf("invalid %s=%s: must be one of cascade, restrict, no action, set null or set default", key, value)
+			}
+			if key == "onDelete" {
+				opts.onDelete = action
+			} else {
+				opts.onUpdate = action
+			}
+		case "groups":
+			for _, group := range strings.Split(value, "|") {
+				if group == "" {
+					return opts, fmt.Errorf("invalid groups=%s: empty group name", value)
+				}
+				opts.groups = append(opts.groups, group)
+			}
+		case "quoted":
+			if value != "" {
+				return opts, fmt.Errorf("invalid quoted=%s: quoted does not take a value", value)
+			}
+			opts.quoted = true
+		default:
+			return opts, fmt.Errorf("unknown struct tag option %q", option)
+		}
+	}
+	if opts.references == "" && (opts.onDelete != "" || opts.onUpdate != "") {
+		return opts, fmt.Errorf("onDelete and onUpdate require references")
+	}
+	return opts, nil
+}
+
+// unqualifiedFieldsKey is the context key which, if set, makes fields write
+// their names without a table qualifier. It is used by DDL statements (e.g.
+// CREATE INDEX) that do not allow qualified column names.
+type unqualifiedFieldsKey struct{}
+
+func writeFieldIdentifier(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int, table TableStruct, fieldName string) error {
 	tableQualifier, _, _ := strings.Cut(table.alias, "(")
 	tableQualifier = strings.TrimRight(tableQualifier, " ")
-	if tableQualifier == "" {
+	var alwaysQuoteQualifier, alwaysQuoteName bool
+	if table.quoted != nil {
+		alwaysQuoteName = table.quoted.table || table.quoted.columns[fieldName]
+		alwaysQuoteQualifier = table.quoted.table && tableQualifier == ""
+	}
+	var err error
+	if table.outer {
+		err = checkOuterField(ctx, table, fieldName)
+		if err != nil {
+			return err
+		}
+	}
+	if tableQualifier != "" {
+		tableQualifier, err = checkAlias(ctx, dialect, tableQualifier)
+	} else {
 		tableQualifier = table.name
+		err = checkIdentifierLength(dialect, tableQualifier)
+	}
+	if err != nil {
+		return err
+	}
+	err = checkIdentifierLength(dialect, fieldName)
+	if err != nil {
+		return err
+	}
+	if ctx != nil && ctx.Value(unqualifiedFieldsKey{}) != nil {
+		tableQualifier = ""
 	}
 	if tableQualifier != "" {
-		buf.WriteString(QuoteIdentifier(dialect, tableQualifier) + ".")
+		writeIdentifier(buf, dialect, tableQualifier, alwaysQuoteQualifier)
+		buf.WriteByte('.')
 	}
-	buf.WriteString(QuoteIdentifier(dialect, fieldName))
+	writeIdentifier(buf, dialect, fieldName, alwaysQuoteName)
+	return nil
 }
 
 func writeFieldOrder(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int, desc, nullsfirst sql.NullBool) {
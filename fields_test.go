@@ -27,6 +27,127 @@ func TestTableStruct(t *testing.T) {
 	}
 }
 
+func TestStarOf(t *testing.T) {
+	type ACTOR struct {
+		TableStruct
+		ACTOR_ID    NumberField
+		FIRST_NAME  StringField
+		LAST_NAME   StringField
+		last_update TimeField
+		LAST_UPDATE TimeField
+	}
+	a := New[ACTOR]("a")
+	TestTable{
+		item:      Select(StarOf(a)...).From(a),
+		wantQuery: "SELECT a.actor_id, a.first_name, a.last_name, a.last_update FROM actor AS a",
+	}.assert(t)
+	if diff := testutil.Diff(len(StarOf(NewTableStruct("", "actor", ""))), 0); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
+
+func TestColumnGroups(t *testing.T) {
+	type FILM struct {
+		TableStruct
+		FILM_ID     NumberField `sq:"film_id,groups=summary|detail"`
+		TITLE       StringField `sq:"title,groups=summary|detail"`
+		DESCRIPTION StringField `sq:"description,groups=detail"`
+		RATING      NumberField `sq:"rating,groups=detail,check=rating BETWEEN 1 AND 5"`
+	}
+	f := New[FILM]("f")
+
+	tests := []TestTable{{
+		description: "summary",
+		item:        Select(f.Fields("summary")...).From(f),
+		wantQuery:   "SELECT f.film_id, f.title FROM film AS f",
+	}, {
+		description: "detail",
+		item:        Select(f.Fields("detail")...).From(f),
+		wantQuery:   "SELECT f.film_id, f.title, f.description, f.rating FROM film AS f",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	t.Run("unknown group", func(t *testing.T) {
+		t.Parallel()
+		if fields := f.Fields("nonexistent"); fields != nil {
+			t.Errorf(testutil.Callers()+" expected nil, got %v", fields)
+		}
+		if fields := NewTableStruct("", "film", "").Fields("summary"); fields != nil {
+			t.Errorf(testutil.Callers()+" expected nil, got %v", fields)
+		}
+	})
+}
+
+func TestQuotedIdentifiers(t *testing.T) {
+	type FILM struct {
+		TableStruct `sq:"public.film,quoted"`
+		FILM_ID     NumberField
+		TITLE       StringField `sq:"Title"`
+	}
+	type ACTOR struct {
+		TableStruct
+		ACTOR_ID   NumberField
+		FIRST_NAME StringField `sq:"first_name,quoted,groups=name"`
+		LAST_NAME  StringField `sq:"last_name,groups=name"`
+	}
+	f, f2, a := New[FILM](""), New[FILM]("f"), New[ACTOR]("")
+
+	tests := []TestTable{{
+		description: "quoted table",
+		dialect:     DialectPostgres,
+		item:        Select(f.FILM_ID, f.TITLE).From(f),
+		wantQuery:   `SELECT "film"."film_id", "film"."Title" FROM "public"."film"`,
+	}, {
+		description: "quoted table with alias",
+		dialect:     DialectPostgres,
+		item:        Select(f2.FILM_ID, f2.TITLE).From(f2),
+		wantQuery:   `SELECT f."film_id", f."Title" FROM "public"."film" AS f`,
+	}, {
+		description: "quoted column",
+		dialect:     DialectPostgres,
+		item:        Select(a.ACTOR_ID, a.FIRST_NAME, a.LAST_NAME).From(a),
+		wantQuery:   `SELECT actor.actor_id, actor."first_name", actor.last_name FROM actor`,
+	}, {
+		description: "quoted column mysql",
+		dialect:     DialectMySQL,
+		item:        Select(a.FIRST_NAME).From(a),
+		wantQuery:   "SELECT actor.`first_name` FROM actor",
+	}, {
+		description: "quoted column sqlserver",
+		dialect:     DialectSQLServer,
+		item:        Select(a.FIRST_NAME).From(a),
+		wantQuery:   "SELECT actor.[first_name] FROM actor",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	t.Run("unknown table option", func(t *testing.T) {
+		t.Parallel()
+		type TBL struct {
+			TableStruct `sq:"tbl,unquoted"`
+		}
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error(testutil.Callers(), "expected panic")
+			}
+		}()
+		New[TBL]("")
+	})
+}
+
 func TestArrayField(t *testing.T) {
 	t.Run("basic", func(t *testing.T) {
 		tbl := NewTableStruct("", "tbl", "")
@@ -1031,6 +1152,126 @@ func (t DummyTable) GetAlias() string { return "" }
 
 func (t DummyTable) IsTable() {}
 
+func TestXMLField(t *testing.T) {
+	t.Run("basic", func(t *testing.T) {
+		tbl := NewTableStruct("", "tbl", "")
+		f1 := NewXMLField("field", tbl).As("f")
+		if diff := testutil.Diff(f1.GetAlias(), "f"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		type FILM struct {
+			TableStruct
+			SPECS XMLField
+		}
+		f := New[FILM]("f")
+		TestTable{
+			item:      Queryf("SELECT {} FROM {} AS f", StarOf(f), f),
+			wantQuery: "SELECT f.specs FROM film AS f",
+		}.assert(t)
+	})
+
+	field := NewXMLField("field", NewTableStruct("", "tbl", ""))
+	tests := []TestTable{{
+		description: "IsNull", item: field.IsNull(),
+		wantQuery: "tbl.field IS NULL",
+	}, {
+		description: "Set", item: field.Set("<a/>"),
+		wantQuery: "field = ?",
+		wantArgs:  []any{"<a/>"},
+	}, {
+		description: "sqlserver Value", dialect: DialectSQLServer,
+		item:      field.Value("(/film/title)[1]"),
+		wantQuery: "tbl.field.value('(/film/title)[1]', 'nvarchar(max)')",
+	}, {
+		description: "sqlserver Exist", dialect: DialectSQLServer,
+		item:      field.Exist("/film[@rating='PG']"),
+		wantQuery: "tbl.field.exist('/film[@rating=''PG'']') = 1",
+	}, {
+		description: "sqlserver Query", dialect: DialectSQLServer,
+		item:      field.Query("/film/actors"),
+		wantQuery: "tbl.field.query('/film/actors')",
+	}, {
+		description: "postgres Value", dialect: DialectPostgres,
+		item:      field.Value("/film/title/text()"),
+		wantQuery: "(xpath('/film/title/text()', tbl.field))[1]::text",
+	}, {
+		description: "postgres Exist", dialect: DialectPostgres,
+		item:      field.Exist("/film/actors"),
+		wantQuery: "xpath_exists('/film/actors', tbl.field)",
+	}, {
+		description: "postgres Query", dialect: DialectPostgres,
+		item:      field.Query("/film/actors"),
+		wantQuery: "array_to_string(xpath('/film/actors', tbl.field), '')",
+	}, {
+		description: "mysql Value", dialect: DialectMySQL,
+		item:      field.Value("/film/title"),
+		wantQuery: "ExtractValue(tbl.field, '/film/title')",
+	}, {
+		description: "mysql Exist", dialect: DialectMySQL,
+		item:      field.Exist("/film/actors"),
+		wantQuery: "ExtractValue(tbl.field, 'count(/film/actors)') > 0",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "mysql Query", dialect: DialectMySQL,
+		item: field.Query("/film/actors"),
+	}, {
+		description: "sqlite", dialect: DialectSQLite,
+		item: field.Value("/film/title"),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+
+	t.Run("scan", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := db.Exec("CREATE TABLE film (film_id INT, specs TEXT)")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		type FILM struct {
+			TableStruct
+			FILM_ID NumberField
+			SPECS   XMLField
+		}
+		f := New[FILM]("")
+		_, err = Exec(db, SQLite.InsertInto(f).Columns(f.FILM_ID, f.SPECS).Values(1, "<film/>"))
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		type Film struct {
+			Specs    string
+			RawSpecs []byte
+		}
+		film, err := FetchOne(db, SQLite.From(f), func(row *Row) Film {
+			return Film{
+				Specs:    row.StringField(f.SPECS),
+				RawSpecs: row.BytesField(f.SPECS),
+			}
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(film, Film{Specs: "<film/>", RawSpecs: []byte("<film/>")}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
+
 func TestNew(t *testing.T) {
 	t.Run("basic", func(t *testing.T) {
 		type USER struct {
@@ -1076,6 +1317,71 @@ func TestNew(t *testing.T) {
 		}.assert(t)
 	})
 
+	t.Run("three-part name", func(t *testing.T) {
+		type USER struct {
+			TableStruct `sq:"sales.dbo.user"`
+			USER_ID     NumberField
+		}
+		u := New[USER]("u")
+		TestTable{
+			dialect:   DialectSQLServer,
+			item:      Queryf("SELECT {} FROM {} AS {}", u.USER_ID, u, Expr(u.GetAlias())),
+			wantQuery: "SELECT u.user_id FROM sales.dbo.[user] AS u",
+		}.assert(t)
+	})
+
+	t.Run("four-part name with default schema", func(t *testing.T) {
+		type USER struct {
+			TableStruct `sq:"Linked Server.sales..user"`
+			USER_ID     NumberField
+		}
+		u := New[USER]("")
+		TestTable{
+			dialect:   DialectSQLServer,
+			item:      Queryf("SELECT {} FROM {}", u.USER_ID, u),
+			wantQuery: "SELECT [user].user_id FROM [Linked Server].sales..[user]",
+		}.assert(t)
+	})
+
+	t.Run("too many parts", func(t *testing.T) {
+		type USER struct {
+			TableStruct `sq:"a.b.c.d.user"`
+			USER_ID     NumberField
+		}
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error(testutil.Callers(), "expected panic but got none")
+			}
+		}()
+		_ = New[USER]("u")
+	})
+
+	t.Run("column options", func(t *testing.T) {
+		type FILM_ACTOR struct {
+			TableStruct
+			ACTOR_ID NumberField `sq:"actor_id,references=actor.actor_id,onDelete=cascade"`
+			RATING   NumberField `sq:",check=rating IN (1, 2, 3)"`
+		}
+		fa := New[FILM_ACTOR]("")
+		TestTable{
+			item:      Queryf("SELECT {}, {} FROM {}", fa.ACTOR_ID, fa.RATING, fa),
+			wantQuery: "SELECT film_actor.actor_id, film_actor.rating FROM film_actor",
+		}.assert(t)
+	})
+
+	t.Run("invalid column options", func(t *testing.T) {
+		type FILM_ACTOR struct {
+			TableStruct
+			ACTOR_ID NumberField `sq:"actor_id,onDelete=cascade"`
+		}
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error(testutil.Callers(), "expected panic but got none")
+			}
+		}()
+		_ = New[FILM_ACTOR]("")
+	})
+
 	t.Run("first field not a struct", func(t *testing.T) {
 		tbl := New[tmptable]("")
 		if diff := testutil.Diff(tbl, tmptable("")); diff != "" {
@@ -1103,4 +1409,33 @@ func TestNew(t *testing.T) {
 			t.Error(testutil.Callers(), diff)
 		}
 	})
+
+	t.Run("invalid table name", func(t *testing.T) {
+		type USER struct {
+			TableStruct `sq:"public.user\"; DROP TABLE user; --"`
+			USER_ID     NumberField
+		}
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error(testutil.Callers(), "expected panic but got none")
+			}
+		}()
+		_ = New[USER]("u")
+	})
+
+	t.Run("invalid column name", func(t *testing.T) {
+		type USER struct {
+			TableStruct
+			USER_ID NumberField `sq:"user_id\n"`
+		}
+		defer func() {
+			r := recover()
+			err, _ := r.(error)
+			wantErr := `sq: USER.USER_ID: invalid column name: identifier "user_id\n" contains control character U+000A at position 7`
+			if err == nil || err.Error() != wantErr {
+				t.Errorf(testutil.Callers()+" expected panic %q, got %v", wantErr, r)
+			}
+		}()
+		_ = New[USER]("u")
+	})
 }
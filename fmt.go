@@ -2,17 +2,21 @@ package sq
 
 import (
 	"bytes"
+	"container/list"
 	"context"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/hex"
 	"fmt"
+	"hash/fnv"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
 // Writef is a fmt.Sprintf-style function that will write a format string and
@@ -29,16 +33,75 @@ import (
 //
 // - Named placeholders refer to their corresponding sql.NamedArg value in the
 // values slice. If there are multiple sql.NamedArg values with the same name,
-// the last one wins.
+// the last one wins. If there is no sql.NamedArg with that name, it is looked
+// up in any map[string]any or struct value in the values slice instead. Map
+// values are looked up by key, struct values are looked up case-insensitively
+// by their `sq` struct tag or their field name.
 //
 // If a value is an SQLWriter, its WriteSQL method will be called. Else if a
 // value is a slice, it will undergo slice expansion
 // (https://bokwoon.neocities.org/sq.html#value-expansion). Otherwise, the
 // value is added to the query args slice.
+//
+// If a placeholder can't be written, the error is a *FormatError that records
+// where in the format string the placeholder is. Since Expressions are written
+// with Writef, the error of a deeply nested Expression names every format
+// string on the way down to the offending placeholder.
 func Writef(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int, format string, values []any) error {
 	return writef(ctx, dialect, buf, args, params, format, values, nil, nil)
 }
 
+// FormatError is the error returned by Writef when a placeholder of the format
+// string can't be written.
+type FormatError struct {
+	// Format is the format string.
+	Format string
+
+	// Offset is the byte offset of the placeholder in the format string.
+	Offset int
+
+	// Placeholder is the offending placeholder e.g. {}, {2} or {name}.
+	Placeholder string
+
+	// Index is the index of the placeholder's value in the values slice, or
+	// -1 if the placeholder does not refer to a value in the values slice.
+	Index int
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("%s at offset %d of %q: %v", e.Placeholder, e.Offset, formatFragment(e.Format, e.Offset), e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FormatError) Unwrap() error { return e.Err }
+
+// formatFragment returns the part of the format string surrounding the
+// offset, so that the offending placeholder of a long format string can be
+// spotted in an error message.
+func formatFragment(format string, offset int) string {
+	const width = 30
+	start, end := offset-width, offset+width
+	prefix, suffix := "...", "..."
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(format) {
+		end, suffix = len(format), ""
+	}
+	// Don't cut a multi-byte character in half.
+	for start > 0 && !utf8.RuneStart(format[start]) {
+		start--
+	}
+	for end < len(format) && !utf8.RuneStart(format[end]) {
+		end++
+	}
+	return prefix + format[start:end] + suffix
+}
+
 func writef(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int, format string, values []any, runningValuesIndex *int, ordinalIndex map[int]int) error {
 	// optimized case when the format string does not contain any '{}'
 	// placeholders
@@ -95,6 +158,7 @@ func writef(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any,
 	}
 
 	// jump to each '{' character in the format string
+	original := format
 	for i := strings.IndexByte(format, '{'); i >= 0; i = strings.IndexByte(format, '{') {
 		// Unescape '{{' to '{'
 		if i+1 <= len(format) && format[i+1] == '{' {
@@ -105,31 +169,33 @@ func writef(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any,
 		}
 		buf.WriteString(format[:i])
 		format = format[i:]
+		offset := len(original) - len(format)
 
 		// If we can't find the terminating '}' return an error
 		j := strings.IndexByte(format, '}')
 		if j < 0 {
-			return fmt.Errorf("no '}' found")
+			return &FormatError{Format: original, Offset: offset, Placeholder: "{", Index: -1, Err: fmt.Errorf("no '}' found")}
 		}
 
 		paramName := format[1:j]
+		placeholder := format[:j+1]
 		format = format[j+1:]
 		for _, char := range paramName {
 			if char != '_' && !unicode.IsLetter(char) && !unicode.IsDigit(char) {
-				return fmt.Errorf("%q is not a valid param name (only letters, digits and '_' are allowed)", paramName)
+				return &FormatError{Format: original, Offset: offset, Placeholder: placeholder, Index: -1, Err: fmt.Errorf("%q is not a valid param name (only letters, digits and '_' are allowed)", paramName)}
 			}
 		}
 
 		// is it an anonymous placeholder? e.g. {}
 		if paramName == "" {
 			if *runningValuesIndex >= len(values) {
-				return fmt.Errorf("too few values passed in to Writef, expected more than %d", runningValuesIndex)
+				return &FormatError{Format: original, Offset: offset, Placeholder: placeholder, Index: -1, Err: fmt.Errorf("too few values passed in to Writef, expected more than %d", *runningValuesIndex)}
 			}
-			value := values[*runningValuesIndex]
+			index := *runningValuesIndex
 			*runningValuesIndex++
-			err := WriteValue(ctx, dialect, buf, args, params, value)
+			err := WriteValue(ctx, dialect, buf, args, params, values[index])
 			if err != nil {
-				return err
+				return &FormatError{Format: original, Offset: offset, Placeholder: placeholder, Index: index, Err: err}
 			}
 			continue
 		}
@@ -139,7 +205,11 @@ func writef(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any,
 		if err == nil {
 			err = writeOrdinalValue(ctx, dialect, buf, args, params, values, ordinal, ordinalIndex)
 			if err != nil {
-				return err
+				index := ordinal - 1
+				if index < 0 || index >= len(values) {
+					index = -1
+				}
+				return &FormatError{Format: original, Offset: offset, Placeholder: placeholder, Index: index, Err: err}
 			}
 			continue
 		}
@@ -147,23 +217,124 @@ func writef(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any,
 		// is it a named placeholder? e.g. {name}, {age}, {email}
 		index, ok := namedIndex[paramName]
 		if !ok {
+			if value, ok := lookupNamedValue(values, paramName); ok {
+				err = writeNamedArg(ctx, dialect, buf, args, params, sql.Named(paramName, value))
+				if err != nil {
+					return &FormatError{Format: original, Offset: offset, Placeholder: placeholder, Index: -1, Err: err}
+				}
+				continue
+			}
 			availableParams := make([]string, 0, len(namedIndex))
 			for name := range namedIndex {
 				availableParams = append(availableParams, name)
 			}
+			availableParams = append(availableParams, namedValueNames(values)...)
 			sort.Strings(availableParams)
-			return fmt.Errorf("named parameter {%s} not provided (available params: %s)", paramName, strings.Join(availableParams, ", "))
+			return &FormatError{Format: original, Offset: offset, Placeholder: placeholder, Index: -1, Err: fmt.Errorf("named parameter {%s} not provided (available params: %s)", paramName, strings.Join(availableParams, ", "))}
 		}
-		value := values[index]
-		err = WriteValue(ctx, dialect, buf, args, params, value)
+		err = WriteValue(ctx, dialect, buf, args, params, values[index])
 		if err != nil {
-			return err
+			return &FormatError{Format: original, Offset: offset, Placeholder: placeholder, Index: index, Err: err}
 		}
 	}
 	buf.WriteString(format)
 	return nil
 }
 
+// lookupNamedValue looks up a named parameter in the map[string]any and
+// struct values of the values slice.
+func lookupNamedValue(values []any, name string) (value any, ok bool) {
+	for _, v := range values {
+		if m, ok := v.(map[string]any); ok {
+			if value, ok := m[name]; ok {
+				return value, true
+			}
+			continue
+		}
+		structValue, ok := namedValueStruct(v)
+		if !ok {
+			continue
+		}
+		structType := structValue.Type()
+		for i := 0; i < structType.NumField(); i++ {
+			fieldName, ok := namedValueFieldName(structType.Field(i))
+			if !ok {
+				continue
+			}
+			// An untagged field also matches its name translated by the
+			// DefaultNamingStrategy e.g. ActorID matches actor_id if the
+			// naming strategy is SnakeCase.
+			if strings.EqualFold(fieldName, name) || (structType.Field(i).Tag.Get("sq") == "" && translateName(fieldName) == name) {
+				return structValue.Field(i).Interface(), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// namedValueNames returns the names of the named parameters provided by the
+// map[string]any and struct values of the values slice.
+func namedValueNames(values []any) []string {
+	var names []string
+	for _, v := range values {
+		if m, ok := v.(map[string]any); ok {
+			for name := range m {
+				names = append(names, name)
+			}
+			continue
+		}
+		structValue, ok := namedValueStruct(v)
+		if !ok {
+			continue
+		}
+		structType := structValue.Type()
+		for i := 0; i < structType.NumField(); i++ {
+			if fieldName, ok := namedValueFieldName(structType.Field(i)); ok {
+				names = append(names, fieldName)
+			}
+		}
+	}
+	return names
+}
+
+// namedValueStruct returns the struct (or pointer to struct) value as a
+// reflect.Value if it can provide named parameters. Structs that represent
+// a single value, like time.Time or sql.NamedArg, are excluded.
+func namedValueStruct(v any) (reflect.Value, bool) {
+	switch v.(type) {
+	case nil, sql.NamedArg, time.Time, driver.Valuer, SQLWriter:
+		return reflect.Value{}, false
+	}
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return value, true
+}
+
+// namedValueFieldName returns the parameter name of a struct field: the part
+// of its `sq` struct tag before the first comma if present, otherwise its
+// field name. Unexported fields and fields tagged with `sq:"-"` have no name.
+func namedValueFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("sq")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
 // WriteValue is the equivalent of Writef but for writing a single value into
 // the Output.
 func WriteValue(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int, value any) error {
@@ -182,6 +353,10 @@ func WriteValue(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]
 	}
 	*args = append(*args, value)
 	index := len(*args) - 1
+	if driverNamedArgs(ctx, dialect) {
+		writeDriverNamedArg(dialect, buf, *args, index)
+		return nil
+	}
 	switch dialect {
 	case DialectPostgres, DialectSQLite:
 		buf.WriteString("$" + strconv.Itoa(index+1))
@@ -193,9 +368,155 @@ func WriteValue(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]
 	return nil
 }
 
+// ValidateIdentifier checks that an identifier (e.g. a table or column name)
+// can be safely quoted for the given dialect. It rejects empty names, control
+// characters and quote characters, which QuoteIdentifier would otherwise
+// quote as-is. If dialect is empty, the quote characters of every dialect are
+// rejected.
+func ValidateIdentifier(dialect string, name string) error {
+	if name == "" {
+		return fmt.Errorf("identifier is empty")
+	}
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("identifier %q is not valid UTF-8", name)
+	}
+	for i, char := range name {
+		if unicode.IsControl(char) {
+			return fmt.Errorf("identifier %q contains control character %U at position %d", name, char, i)
+		}
+		switch char {
+		case '"', '\'':
+		case '`':
+			if dialect != "" && dialect != DialectMySQL {
+				continue
+			}
+		case '[', ']':
+			if dialect != "" && dialect != DialectSQLServer {
+				continue
+			}
+		default:
+			continue
+		}
+		return fmt.Errorf("identifier %q contains quote character %q at position %d", name, char, i)
+	}
+	if (dialect == "" || dialect == DialectMySQL) && strings.HasSuffix(name, " ") {
+		return fmt.Errorf("identifier %q ends with a space", name)
+	}
+	return checkIdentifierLength(dialect, name)
+}
+
+// checkIdentifierLength checks that an identifier does not exceed the
+// maximum identifier length of the dialect: 63 bytes for Postgres, 64
+// characters for MySQL and 128 characters for SQL Server. Postgres silently
+// truncates longer identifiers (so that two long aliases sharing the same
+// first 63 bytes refer to the same thing), while MySQL and SQL Server reject
+// them.
+func checkIdentifierLength(dialect string, identifier string) error {
+	maxLength, unit := maxIdentifierLength(dialect)
+	if maxLength == 0 || len(identifier) <= maxLength {
+		return nil
+	}
+	length := len(identifier)
+	if unit == "characters" {
+		length = utf8.RuneCountInString(identifier)
+		if length <= maxLength {
+			return nil
+		}
+	}
+	return fmt.Errorf("identifier %q is %d %s long, which exceeds the %s limit of %d %s", identifier, length, unit, dialect, maxLength, unit)
+}
+
+// maxIdentifierLength returns the maximum identifier length of the dialect
+// and whether it is measured in bytes or characters. A maxLength of 0 means
+// there is no limit.
+func maxIdentifierLength(dialect string) (maxLength int, unit string) {
+	switch dialect {
+	case DialectPostgres:
+		return 63, "bytes"
+	case DialectMySQL:
+		return 64, "characters"
+	case DialectSQLServer:
+		return 128, "characters"
+	default:
+		return 0, ""
+	}
+}
+
+type shortenAliasesKey struct{}
+
+// WithShortenedAliases returns a copy of ctx in which table and field aliases
+// that exceed the maximum identifier length of the dialect are shortened,
+// instead of failing the query. A shortened alias keeps as much of its prefix
+// as fits and ends with an underscore and a hash of the full alias, so that
+// it is the same every time it is written and different aliases stay
+// distinct. Table, column and CTE names are never shortened because they
+// have to match the names in the database.
+//
+// Note that the columns returned by the query are named after the shortened
+// aliases, which matters for static queries that look up columns by name.
+func WithShortenedAliases(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shortenAliasesKey{}, true)
+}
+
+// checkAlias resolves an alias generated by AutoAlias, then returns the alias
+// if it does not exceed the maximum identifier length of the dialect.
+// Otherwise it returns the shortened alias if ctx was created by
+// WithShortenedAliases, or an error.
+func checkAlias(ctx context.Context, dialect string, alias string) (string, error) {
+	alias, err := resolveAutoAlias(ctx, alias)
+	if err != nil {
+		return "", err
+	}
+	err = checkIdentifierLength(dialect, alias)
+	if err == nil {
+		return alias, nil
+	}
+	if ctx == nil || ctx.Value(shortenAliasesKey{}) == nil {
+		return alias, err
+	}
+	return shortenIdentifier(dialect, alias), nil
+}
+
+// quoteAlias checks an alias with checkAlias and quotes it if necessary.
+func quoteAlias(ctx context.Context, dialect string, alias string) (string, error) {
+	alias, err := checkAlias(ctx, dialect, alias)
+	if err != nil {
+		return "", err
+	}
+	return QuoteIdentifier(dialect, alias), nil
+}
+
+// shortenIdentifier shortens an identifier to the maximum identifier length
+// of the dialect by replacing its tail with an underscore and an 8 character
+// hash of the full identifier.
+func shortenIdentifier(dialect string, identifier string) string {
+	maxLength, unit := maxIdentifierLength(dialect)
+	hash := fnv.New32a()
+	hash.Write([]byte(identifier))
+	suffix := fmt.Sprintf("_%08x", hash.Sum32())
+	length := func(s string) int { return len(s) }
+	if unit == "characters" {
+		length = utf8.RuneCountInString
+	}
+	prefix := identifier
+	for length(prefix) > maxLength-len(suffix) {
+		_, size := utf8.DecodeLastRuneInString(prefix)
+		prefix = prefix[:len(prefix)-size]
+	}
+	return prefix + suffix
+}
+
 // QuoteIdentifier quotes an identifier if necessary using dialect-specific
 // quoting rules.
 func QuoteIdentifier(dialect string, identifier string) string {
+	if !identifierNeedsQuoting(dialect, identifier) {
+		return identifier
+	}
+	return quoteIdentifier(dialect, identifier)
+}
+
+// identifierNeedsQuoting reports whether an identifier needs to be quoted.
+func identifierNeedsQuoting(dialect string, identifier string) bool {
 	var needsQuoting bool
 	switch identifier {
 	case "":
@@ -235,9 +556,12 @@ func QuoteIdentifier(dialect string, identifier string) string {
 			}
 		}
 	}
-	if !needsQuoting {
-		return identifier
-	}
+	return needsQuoting
+}
+
+// quoteIdentifier quotes an identifier unconditionally using dialect-specific
+// quoting rules.
+func quoteIdentifier(dialect string, identifier string) string {
 	switch dialect {
 	case DialectMySQL:
 		return "`" + EscapeQuote(identifier, '`') + "`"
@@ -248,6 +572,32 @@ func QuoteIdentifier(dialect string, identifier string) string {
 	}
 }
 
+// writeIdentifier writes an identifier into buf, quoting it if necessary (or
+// always, if alwaysQuote is true). Unlike QuoteIdentifier it does not allocate,
+// since it is called for every field of every query.
+func writeIdentifier(buf *bytes.Buffer, dialect string, identifier string, alwaysQuote bool) {
+	if !alwaysQuote && !identifierNeedsQuoting(dialect, identifier) {
+		buf.WriteString(identifier)
+		return
+	}
+	openingQuote, closingQuote := byte('"'), byte('"')
+	switch dialect {
+	case DialectMySQL:
+		openingQuote, closingQuote = '`', '`'
+	case DialectSQLServer:
+		openingQuote, closingQuote = '[', ']'
+	}
+	if strings.IndexByte(identifier, closingQuote) >= 0 {
+		// Rare enough (sq.New rejects such names) to not bother avoiding the
+		// allocations of EscapeQuote.
+		buf.WriteString(quoteIdentifier(dialect, identifier))
+		return
+	}
+	buf.WriteByte(openingQuote)
+	buf.WriteString(identifier)
+	buf.WriteByte(closingQuote)
+}
+
 // EscapeQuote will escape the relevant quote in a string by doubling up on it
 // (as per SQL rules).
 func EscapeQuote(str string, quote byte) string {
@@ -276,83 +626,114 @@ func EscapeQuote(str string, quote byte) string {
 // statement parameters. It returns an error if an argument cannot be properly
 // represented in SQL. This function may be vulnerable to SQL injection and
 // should be used for logging purposes only.
+//
+// The positions of the parameters in a query are cached (see
+// SetSprintfCacheSize), so interpolating the same query again only has to
+// convert the args.
 func Sprintf(dialect string, query string, args []any) (string, error) {
 	if len(args) == 0 {
 		return query, nil
 	}
+	template := sprintfTemplates.get(dialect, query)
 	buf := bufpool.Get().(*bytes.Buffer)
 	buf.Reset()
 	defer bufpool.Put(buf)
 	buf.Grow(len(query))
-	namedIndices := make(map[string]int)
+	var namedIndices map[string]int
 	for i, arg := range args {
 		switch arg := arg.(type) {
 		case sql.NamedArg:
+			if namedIndices == nil {
+				namedIndices = make(map[string]int)
+			}
 			namedIndices[arg.Name] = i
 		}
 	}
 	runningArgsIndex := 0
-	mustWriteCharAt := -1
-	insideStringOrIdentifier := false
-	var openingQuote rune
-	var paramName []rune
-	for i, char := range query {
-		// do we unconditionally write in the current char?
-		if mustWriteCharAt == i {
-			buf.WriteRune(char)
+	for _, token := range template.tokens {
+		buf.WriteString(token.text)
+		if token.paramName == "" {
 			continue
 		}
-		// are we currently inside a string or identifier?
-		if insideStringOrIdentifier {
-			buf.WriteRune(char)
-			switch openingQuote {
-			case '\'', '"', '`':
-				// does the current char terminate the current string or identifier?
-				if char == openingQuote {
-					// is the next char the same as the current char, which
-					// escapes it and prevents it from terminating the current
-					// string or identifier?
-					if i+1 < len(query) && rune(query[i+1]) == openingQuote {
-						mustWriteCharAt = i + 1
-					} else {
-						insideStringOrIdentifier = false
-					}
-				}
-			case '[':
-				// does the current char terminate the current string or identifier?
-				if char == ']' {
-					// is the next char the same as the current char, which
-					// escapes it and prevents it from terminating the current
-					// string or identifier?
-					if i+1 < len(query) && query[i+1] == ']' {
-						mustWriteCharAt = i + 1
-					} else {
-						insideStringOrIdentifier = false
-					}
-				}
+		if token.anonymous {
+			if runningArgsIndex >= len(args) {
+				return buf.String(), fmt.Errorf("too few args provided, expected more than %d", runningArgsIndex+1)
 			}
+			paramValue, err := Sprint(dialect, args[runningArgsIndex])
+			if err != nil {
+				return buf.String(), err
+			}
+			buf.WriteString(paramValue)
+			runningArgsIndex++
 			continue
 		}
-		// does the current char mark the start of a new string or identifier?
-		if char == '\'' || char == '"' || (char == '`' && dialect == DialectMySQL) || (char == '[' && dialect == DialectSQLServer) {
-			insideStringOrIdentifier = true
-			openingQuote = char
-			buf.WriteRune(char)
+		paramValue, err := lookupParam(dialect, args, token.paramName, namedIndices, runningArgsIndex)
+		if err != nil {
+			return buf.String(), err
+		}
+		buf.WriteString(paramValue)
+		if token.paramName == "?" {
+			runningArgsIndex++
+		}
+	}
+	if template.unclosed {
+		return buf.String(), fmt.Errorf("unclosed string or identifier")
+	}
+	return buf.String(), nil
+}
+
+// sprintfTemplate is a query lexed by Sprintf: the literal text of the query
+// broken up at its parameters.
+type sprintfTemplate struct {
+	tokens   []sprintfToken
+	unclosed bool // the query ends inside an unclosed string or identifier
+}
+
+// sprintfToken is a piece of literal text followed by a parameter.
+type sprintfToken struct {
+	text string
+	// paramName is the name of the parameter following the text (including
+	// its prefix e.g. $1, :name, ?). It is empty for the text at the end of
+	// the query.
+	paramName string
+	// anonymous is true for an anonymous '?' parameter (for dialects other
+	// than SQLite, where '?' cannot be followed by an ordinal or name).
+	anonymous bool
+}
+
+// lexSprintfQuery lexes a query into a sprintfTemplate.
+func lexSprintfQuery(dialect string, query string) *sprintfTemplate {
+	template := &sprintfTemplate{}
+	var text strings.Builder
+	emit := func(paramName string, anonymous bool) {
+		template.tokens = append(template.tokens, sprintfToken{
+			text:      text.String(),
+			paramName: paramName,
+			anonymous: anonymous,
+		})
+		text.Reset()
+	}
+	var paramName []rune
+	for i := 0; i < len(query); {
+		// is the current char the start of a string or identifier?
+		if end, closed, ok := quotedEnd(dialect, query, i); ok {
+			if len(paramName) > 0 {
+				emit(string(paramName), false)
+				paramName = paramName[:0]
+			}
+			text.WriteString(query[i:end])
+			template.unclosed = !closed
+			i = end
 			continue
 		}
+		char, size := utf8.DecodeRuneInString(query[i:])
+		i += size
 		// are we currently inside a parameter name?
 		if len(paramName) > 0 {
 			// does the current char terminate the current parameter name?
 			if char != '_' && !unicode.IsLetter(char) && !unicode.IsDigit(char) {
-				paramValue, err := lookupParam(dialect, args, paramName, namedIndices, runningArgsIndex)
-				if err != nil {
-					return buf.String(), err
-				}
-				buf.WriteString(paramValue)
-				buf.WriteRune(char)
-				if len(paramName) == 1 && paramName[0] == '?' {
-					runningArgsIndex++
-				}
+				emit(string(paramName), false)
+				text.WriteRune(char)
 				paramName = paramName[:0]
 			} else {
 				paramName = append(paramName, char)
@@ -377,33 +758,185 @@ func Sprintf(dialect string, query string, args []any) (string, error) {
 				paramName = append(paramName, char)
 				continue
 			}
-			if runningArgsIndex >= len(args) {
-				return buf.String(), fmt.Errorf("too few args provided, expected more than %d", runningArgsIndex+1)
-			}
-			paramValue, err := Sprint(dialect, args[runningArgsIndex])
-			if err != nil {
-				return buf.String(), err
-			}
-			buf.WriteString(paramValue)
-			runningArgsIndex++
+			emit("?", true)
 			continue
 		}
 		// if all the above questions answer false, we just write the current
 		// char in and continue
-		buf.WriteRune(char)
+		text.WriteRune(char)
 	}
 	// flush the paramName buffer (to handle edge case where the query ends with a parameter name)
 	if len(paramName) > 0 {
-		paramValue, err := lookupParam(dialect, args, paramName, namedIndices, runningArgsIndex)
-		if err != nil {
-			return buf.String(), err
+		emit(string(paramName), false)
+	}
+	emit("", false)
+	return template
+}
+
+// quotedEnd returns the index just past the string or quoted identifier that
+// starts at query[i] and whether it is closed. ok is false if query[i] does
+// not start a string or quoted identifier in the dialect.
+//
+// Strings and identifiers are quoted with ' and " in every dialect, ` in
+// mysql and [ ] in sqlserver. A quote is escaped by doubling it. In mysql
+// strings and postgres E'...' strings, a backslash also escapes the next
+// character.
+func quotedEnd(dialect string, query string, i int) (end int, closed bool, ok bool) {
+	var closingQuote byte
+	switch char := query[i]; {
+	case char == '\'' || char == '"':
+		closingQuote = char
+	case char == '`' && dialect == DialectMySQL:
+		closingQuote = '`'
+	case char == '[' && dialect == DialectSQLServer:
+		closingQuote = ']'
+	default:
+		return i, false, false
+	}
+	backslashEscapes := (dialect == DialectMySQL && closingQuote != '`') ||
+		(dialect == DialectPostgres && closingQuote == '\'' && i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') &&
+			(i == 1 || !isIdentifierChar(query[i-2])))
+	for j := i + 1; j < len(query); j++ {
+		if backslashEscapes && query[j] == '\\' {
+			j++
+			continue
 		}
-		buf.WriteString(paramValue)
+		if query[j] != closingQuote {
+			continue
+		}
+		// is the next char the same as the current char, which escapes it?
+		if j+1 < len(query) && query[j+1] == closingQuote {
+			j++
+			continue
+		}
+		return j + 1, true, true
 	}
-	if insideStringOrIdentifier {
-		return buf.String(), fmt.Errorf("unclosed string or identifier")
+	return len(query), false, true
+}
+
+// skipLiteral returns the index just past the string, quoted identifier,
+// comment or dollar-quoted string that starts at query[i], or i if none
+// starts there.
+//
+// Comments start with -- (or # in mysql) and end at the end of the line, or
+// are enclosed in /* */. Dollar-quoted strings ($$...$$ or $tag$...$tag$)
+// are postgres only.
+func skipLiteral(dialect string, query string, i int) int {
+	if end, _, ok := quotedEnd(dialect, query, i); ok {
+		return end
 	}
-	return buf.String(), nil
+	switch char := query[i]; {
+	case (char == '-' && strings.HasPrefix(query[i:], "--")) || (char == '#' && dialect == DialectMySQL):
+		end := strings.IndexByte(query[i:], '\n')
+		if end < 0 {
+			return len(query)
+		}
+		return i + end
+	case char == '/' && strings.HasPrefix(query[i:], "/*"):
+		end := strings.Index(query[i+2:], "*/")
+		if end < 0 {
+			return len(query)
+		}
+		return i + 2 + end + 2
+	case char == '$' && dialect == DialectPostgres && (i == 0 || !isIdentifierChar(query[i-1])):
+		j := i + 1
+		for j < len(query) && (query[j] == '_' || isASCIILetterOrDigit(query[j])) {
+			j++
+		}
+		// $1 is a parameter, not a tag.
+		if j >= len(query) || query[j] != '$' || (j > i+1 && '0' <= query[i+1] && query[i+1] <= '9') {
+			return i
+		}
+		tag := query[i : j+1]
+		end := strings.Index(query[j+1:], tag)
+		if end < 0 {
+			return len(query)
+		}
+		return j + 1 + end + len(tag)
+	}
+	return i
+}
+
+// maxSprintfCacheQueryLength is the length of the longest query whose
+// sprintfTemplate is cached. Longer queries (typically bulk inserts) are
+// rarely repeated verbatim and would take up too much of the cache.
+const maxSprintfCacheQueryLength = 16 * 1024
+
+// sprintfTemplates is the cache of the sprintfTemplates of recently
+// interpolated queries.
+var sprintfTemplates = &sprintfTemplateCache{capacity: 512}
+
+// SetSprintfCacheSize sets the maximum number of queries whose parameter
+// positions are cached by Sprintf (512 by default). The least recently used
+// queries are evicted first. A size of zero or less disables the cache.
+func SetSprintfCacheSize(size int) {
+	sprintfTemplates.mu.Lock()
+	defer sprintfTemplates.mu.Unlock()
+	sprintfTemplates.capacity = size
+	if sprintfTemplates.order == nil {
+		return
+	}
+	for sprintfTemplates.order.Len() > 0 && sprintfTemplates.order.Len() > size {
+		sprintfTemplates.removeOldest()
+	}
+}
+
+type sprintfCacheKey struct {
+	dialect string
+	query   string
+}
+
+type sprintfCacheEntry struct {
+	key      sprintfCacheKey
+	template *sprintfTemplate
+}
+
+// sprintfTemplateCache is an LRU cache of sprintfTemplates.
+type sprintfTemplateCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[sprintfCacheKey]*list.Element
+	order    *list.List // most recently used at the front
+}
+
+// get returns the sprintfTemplate of a query, lexing the query if it is not
+// in the cache.
+func (c *sprintfTemplateCache) get(dialect string, query string) *sprintfTemplate {
+	if len(query) > maxSprintfCacheQueryLength {
+		return lexSprintfQuery(dialect, query)
+	}
+	key := sprintfCacheKey{dialect: dialect, query: query}
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		c.mu.Unlock()
+		return element.Value.(*sprintfCacheEntry).template
+	}
+	c.mu.Unlock()
+	template := lexSprintfQuery(dialect, query)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.capacity <= 0 {
+		return template
+	}
+	if c.entries == nil {
+		c.entries = make(map[sprintfCacheKey]*list.Element)
+		c.order = list.New()
+	}
+	if _, ok := c.entries[key]; ok {
+		return template
+	}
+	c.entries[key] = c.order.PushFront(&sprintfCacheEntry{key: key, template: template})
+	for c.order.Len() > c.capacity {
+		c.removeOldest()
+	}
+	return template
+}
+
+func (c *sprintfTemplateCache) removeOldest() {
+	element := c.order.Back()
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*sprintfCacheEntry).key)
 }
 
 // Sprint is the equivalent of Sprintf but for converting a single value into
@@ -413,6 +946,16 @@ func Sprint(dialect string, v any) (string, error) {
 		timestamp             = "2006-01-02 15:04:05"
 		timestampWithTimezone = "2006-01-02 15:04:05.9999999-07:00"
 	)
+	if dialectValuer, ok := v.(DialectValuer); ok {
+		driverValuer, err := dialectValuer.DialectValuer(dialect)
+		if err != nil {
+			return "", fmt.Errorf("calling DialectValuer on %#v: %w", dialectValuer, err)
+		}
+		v = driverValuer
+	}
+	if value, ok := nullGenericValue(v); ok {
+		return Sprint(dialect, value)
+	}
 	switch v := v.(type) {
 	case nil:
 		return "NULL", nil
@@ -562,6 +1105,9 @@ func Sprint(dialect string, v any) (string, error) {
 		}
 		return `'` + v.Time.UTC().Format(timestamp) + `'`, nil
 	case driver.Valuer:
+		if isNilValuer(v) {
+			return "NULL", nil
+		}
 		vv, err := v.Value()
 		if err != nil {
 			return "", fmt.Errorf("error when calling Value(): %w", err)
@@ -580,6 +1126,11 @@ func Sprint(dialect string, v any) (string, error) {
 			return "NULL", nil
 		}
 	}
+	// Named byte slice types (e.g. type Hash []byte) are written as binary
+	// literals, like []byte.
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		return Sprint(dialect, rv.Bytes())
+	}
 	switch v := rv.Interface().(type) {
 	case bool, []byte, string, time.Time, int, int8, int16, int32, int64, uint,
 		uint8, uint16, uint32, uint64, float32, float64, sql.NamedArg,
@@ -591,21 +1142,40 @@ func Sprint(dialect string, v any) (string, error) {
 	}
 }
 
-// isExpandableSlice checks if a value is an expandable slice.
+var (
+	driverValuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	dialectValuerType = reflect.TypeOf((*DialectValuer)(nil)).Elem()
+)
+
+// isExpandableSlice checks if a value is an expandable slice or array. Each
+// element of an expandable slice is written as a separate value, so that it
+// can be used in an IN list.
 func isExpandableSlice(value any) bool {
-	// treat byte slices as a special case that we never want to expand
-	if _, ok := value.([]byte); ok {
-		return false
-	}
 	valueType := reflect.TypeOf(value)
 	if valueType == nil {
 		return false
 	}
-	return valueType.Kind() == reflect.Slice
+	if valueType.Kind() != reflect.Slice && valueType.Kind() != reflect.Array {
+		return false
+	}
+	// Byte slices and byte arrays (including named types like a [16]byte
+	// UUID) are single binary values and are never expanded.
+	if valueType.Elem().Kind() == reflect.Uint8 {
+		return false
+	}
+	// Neither are slices that convert themselves into a single value e.g.
+	// pq.StringArray.
+	if valueType.Implements(driverValuerType) || valueType.Implements(dialectValuerType) {
+		return false
+	}
+	return true
 }
 
 // expandSlice expands a slice value into Output. Make sure the value is an
-// expandable slice first by checking it with isExpandableSlice().
+// expandable slice first by checking it with isExpandableSlice(). Each
+// element is preprocessed individually, so elements that implement
+// driver.Valuer, DialectValuer or Enumeration are converted like any other
+// value.
 func expandSlice(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int, value any) error {
 	slice := reflect.ValueOf(value)
 	var err error
@@ -621,19 +1191,23 @@ func expandSlice(ctx context.Context, dialect string, buf *bytes.Buffer, args *[
 			}
 			continue
 		}
+		arg, err = preprocessValue(dialect, arg)
+		if err != nil {
+			return err
+		}
+		*args = append(*args, arg)
+		if driverNamedArgs(ctx, dialect) {
+			writeDriverNamedArg(dialect, buf, *args, len(*args)-1)
+			continue
+		}
 		switch dialect {
 		case DialectPostgres, DialectSQLite:
-			buf.WriteString("$" + strconv.Itoa(len(*args)+1))
+			buf.WriteString("$" + strconv.Itoa(len(*args)))
 		case DialectSQLServer:
-			buf.WriteString("@p" + strconv.Itoa(len(*args)+1))
+			buf.WriteString("@p" + strconv.Itoa(len(*args)))
 		default:
 			buf.WriteString("?")
 		}
-		arg, err = preprocessValue(dialect, arg)
-		if err != nil {
-			return err
-		}
-		*args = append(*args, arg)
 	}
 	return nil
 }
@@ -737,6 +1311,10 @@ func writeOrdinalValue(ctx context.Context, dialect string, buf *bytes.Buffer, a
 			index = len(*args) - 1
 			ordinalIndices[ordinal] = index
 		}
+		if driverNamedArgs(ctx, dialect) {
+			writeDriverNamedArg(dialect, buf, *args, index)
+			return nil
+		}
 		switch dialect {
 		case DialectSQLite, DialectPostgres:
 			buf.WriteString("$" + strconv.Itoa(index+1))
@@ -752,14 +1330,57 @@ func writeOrdinalValue(ctx context.Context, dialect string, buf *bytes.Buffer, a
 	return nil
 }
 
+type driverNamedArgsKey struct{}
+
+// WithDriverNamedArgs returns a copy of ctx in which every argument of an
+// SQLite or SQL Server query is passed to the driver as an sql.NamedArg, not
+// just the named parameters. The other arguments are named after their
+// position (p1, p2, p3, etc) and written as $p1 (SQLite) or @p1 (SQL Server),
+// so those names should not be used for named parameters. Postgres and MySQL
+// drivers don't support named arguments so their queries are unaffected.
+//
+//	// SELECT actor.first_name FROM actor WHERE actor.actor_id = @p1
+//	// args: []any{sql.Named("p1", 1)}
+//	ctx = sq.WithDriverNamedArgs(ctx)
+//	firstNames, err := sq.FetchAllContext(ctx, db, sq.SQLServer.From(a).Where(a.ACTOR_ID.EqInt(1)), ...)
+func WithDriverNamedArgs(ctx context.Context) context.Context {
+	return context.WithValue(ctx, driverNamedArgsKey{}, true)
+}
+
+// driverNamedArgs reports whether every argument should be passed to the
+// driver as an sql.NamedArg.
+func driverNamedArgs(ctx context.Context, dialect string) bool {
+	if dialect != DialectSQLite && dialect != DialectSQLServer {
+		return false
+	}
+	return ctx != nil && ctx.Value(driverNamedArgsKey{}) != nil
+}
+
+// writeDriverNamedArg turns the argument at the index into an sql.NamedArg
+// named after its position (if it isn't one already) and writes its
+// placeholder.
+func writeDriverNamedArg(dialect string, buf *bytes.Buffer, args []any, index int) {
+	name := "p" + strconv.Itoa(index+1)
+	if namedArg, ok := args[index].(sql.NamedArg); ok {
+		name = namedArg.Name
+	} else {
+		args[index] = sql.Named(name, args[index])
+	}
+	if dialect == DialectSQLServer {
+		buf.WriteString("@" + name)
+	} else {
+		buf.WriteString("$" + name)
+	}
+}
+
 // lookupParam returns the SQL representation of a paramName (inside the args
 // slice).
-func lookupParam(dialect string, args []any, paramName []rune, namedIndices map[string]int, runningArgsIndex int) (paramValue string, err error) {
+func lookupParam(dialect string, args []any, paramName string, namedIndices map[string]int, runningArgsIndex int) (paramValue string, err error) {
 	var maybeNum string
 	if paramName[0] == '@' && dialect == DialectSQLServer && len(paramName) >= 2 && (paramName[1] == 'p' || paramName[1] == 'P') {
-		maybeNum = string(paramName[2:])
+		maybeNum = paramName[2:]
 	} else {
-		maybeNum = string(paramName[1:])
+		maybeNum = paramName[1:]
 	}
 
 	// is paramName an anonymous parameter?
@@ -791,11 +1412,11 @@ func lookupParam(dialect string, args []any, paramName []rune, namedIndices map[
 	// if we reach here, we know that the paramName is not an ordinal parameter
 	// i.e. it is a named parameter
 	if dialect == DialectPostgres || dialect == DialectMySQL {
-		return "", fmt.Errorf("%s does not support %s named parameter", dialect, string(paramName))
+		return "", fmt.Errorf("%s does not support %s named parameter", dialect, paramName)
 	}
-	index, ok := namedIndices[string(paramName[1:])]
+	index, ok := namedIndices[paramName[1:]]
 	if !ok {
-		return "", fmt.Errorf("named parameter %s not provided", string(paramName))
+		return "", fmt.Errorf("named parameter %s not provided", paramName)
 	}
 	if index < 0 || index >= len(args) {
 		return "", fmt.Errorf("args index %d out of bounds", ordinal)
@@ -1057,6 +1678,143 @@ func (p UUIDParameter) IsField() {}
 // IsUUID implements the UUID interface.
 func (p UUIDParameter) IsUUID() {}
 
+// ParamsBuilder builds a Params map using typed setters that mirror the typed
+// parameter constructors (IntParam, StringParam, TimeParam etc). Mistakes such
+// as an empty name or setting the same name twice are reported by Build.
+type ParamsBuilder struct {
+	params Params
+	err    error
+}
+
+// NewParams returns a new ParamsBuilder.
+func NewParams() *ParamsBuilder {
+	return &ParamsBuilder{params: make(Params)}
+}
+
+func (b *ParamsBuilder) set(name string, value any) *ParamsBuilder {
+	if b.err != nil {
+		return b
+	}
+	if name == "" {
+		b.err = fmt.Errorf("param name is empty")
+		return b
+	}
+	if _, ok := b.params[name]; ok {
+		b.err = fmt.Errorf("param %q set more than once", name)
+		return b
+	}
+	b.params[name] = value
+	return b
+}
+
+// Set sets a param to an arbitrary value.
+func (b *ParamsBuilder) Set(name string, value any) *ParamsBuilder {
+	return b.set(name, value)
+}
+
+// Array sets a param to an array value. It wraps the value with ArrayValue().
+func (b *ParamsBuilder) Array(name string, value any) *ParamsBuilder {
+	return b.set(name, ArrayValue(value))
+}
+
+// Bytes sets a param to a []byte value.
+func (b *ParamsBuilder) Bytes(name string, value []byte) *ParamsBuilder {
+	return b.set(name, value)
+}
+
+// Bool sets a param to a bool value.
+func (b *ParamsBuilder) Bool(name string, value bool) *ParamsBuilder {
+	return b.set(name, value)
+}
+
+// Enum sets a param to an enum value. It wraps the value with EnumValue().
+func (b *ParamsBuilder) Enum(name string, value Enumeration) *ParamsBuilder {
+	return b.set(name, EnumValue(value))
+}
+
+// JSON sets a param to a JSON value. It wraps the value with JSONValue().
+func (b *ParamsBuilder) JSON(name string, value any) *ParamsBuilder {
+	return b.set(name, JSONValue(value))
+}
+
+// Int sets a param to an int value.
+func (b *ParamsBuilder) Int(name string, value int) *ParamsBuilder {
+	return b.set(name, value)
+}
+
+// Int64 sets a param to an int64 value.
+func (b *ParamsBuilder) Int64(name string, value int64) *ParamsBuilder {
+	return b.set(name, value)
+}
+
+// Float64 sets a param to a float64 value.
+func (b *ParamsBuilder) Float64(name string, value float64) *ParamsBuilder {
+	return b.set(name, value)
+}
+
+// String sets a param to a string value.
+func (b *ParamsBuilder) String(name string, value string) *ParamsBuilder {
+	return b.set(name, value)
+}
+
+// Time sets a param to a time.Time value.
+func (b *ParamsBuilder) Time(name string, value time.Time) *ParamsBuilder {
+	return b.set(name, value)
+}
+
+// UUID sets a param to a UUID value. It wraps the value with UUIDValue().
+func (b *ParamsBuilder) UUID(name string, value any) *ParamsBuilder {
+	return b.set(name, UUIDValue(value))
+}
+
+// Build returns the Params, or the first error encountered while setting
+// them.
+func (b *ParamsBuilder) Build() (Params, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	params := make(Params, len(b.params))
+	for name, value := range b.params {
+		params[name] = value
+	}
+	return params, nil
+}
+
+// checkParams checks that the param values match the param names expected by
+// a compiled query, reporting every missing and unexpected param.
+func checkParams(paramIndexes map[string][]int, paramValues Params) error {
+	var missing, unexpected []string
+	for name := range paramIndexes {
+		if _, ok := paramValues[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	for name := range paramValues {
+		if _, ok := paramIndexes[name]; !ok {
+			unexpected = append(unexpected, name)
+		}
+	}
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return nil
+	}
+	expected := make([]string, 0, len(paramIndexes))
+	for name := range paramIndexes {
+		expected = append(expected, name)
+	}
+	sort.Strings(expected)
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("expected %d params (%s), got %d", len(expected), strings.Join(expected, ", "), len(paramValues)))
+	if len(missing) > 0 {
+		b.WriteString("; missing: " + strings.Join(missing, ", "))
+	}
+	if len(unexpected) > 0 {
+		b.WriteString("; unexpected: " + strings.Join(unexpected, ", "))
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
 // SQLite keyword reference: https://www.sqlite.org/lang_keywords.html
 var sqliteKeywords = map[string]struct{}{
 	"abort": {}, "action": {}, "add": {}, "after": {}, "all": {}, "alter": {},
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/bokwoon95/sq/internal/testutil"
 )
@@ -372,6 +373,57 @@ func TestWritef(t *testing.T) {
 		assert(t, tt)
 	})
 
+	t.Run("struct and map named params", func(t *testing.T) {
+		t.Parallel()
+		type Filter struct {
+			Age      int
+			Email    string `sq:"email_address"`
+			Names    []string
+			Status   string `sq:"user_status,omitempty"`
+			Nickname string `sq:",omitempty"`
+			password string
+		}
+		var tt TT
+		tt.dialect = DialectPostgres
+		tt.format = "SELECT name FROM users" +
+			" WHERE age = {age}" +
+			" AND age > {AGE}" +
+			" AND email <> {email_address}" +
+			" AND name IN ({names})" +
+			" AND status = {status}" +
+			" AND user_status = {user_status}" +
+			" AND nickname = {nickname}"
+		tt.values = []any{
+			Filter{Age: 5, Email: "bob@email.com", Names: []string{"tom", "dick", "harry"}, Status: "new", Nickname: "bobby"},
+			map[string]any{"status": "active"},
+		}
+		tt.wantQuery = "SELECT name FROM users" +
+			" WHERE age = $1" +
+			" AND age > $2" +
+			" AND email <> $3" +
+			" AND name IN ($4, $5, $6)" +
+			" AND status = $7" +
+			" AND user_status = $8" +
+			" AND nickname = $9"
+		tt.wantArgs = []any{5, 5, "bob@email.com", "tom", "dick", "harry", "active", "new", "bobby"}
+		tt.wantParams = map[string][]int{"age": {0}, "AGE": {1}, "email_address": {2}, "status": {6}, "user_status": {7}, "nickname": {8}}
+		assert(t, tt)
+
+		// sql.NamedArg takes precedence, unexported fields are ignored.
+		tt.format = "SELECT {age}"
+		tt.values = []any{&Filter{Age: 5}, sql.Named("age", 6)}
+		tt.wantQuery = "SELECT $1"
+		tt.wantArgs = []any{6}
+		tt.wantParams = map[string][]int{"age": {0}}
+		assert(t, tt)
+
+		buf := new(bytes.Buffer)
+		err := Writef(context.Background(), DialectPostgres, buf, new([]any), nil, "SELECT {password}", []any{Filter{password: "secret"}})
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+	})
+
 	t.Run("mysql QuoteIdentifier", func(t *testing.T) {
 		t.Parallel()
 		var tt TT
@@ -754,6 +806,50 @@ func TestWritef(t *testing.T) {
 			t.Error(testutil.Callers(), "expected ErrFaultySQL but got %v", err)
 		}
 	})
+
+	t.Run("error position", func(t *testing.T) {
+		t.Parallel()
+		var tt TT
+		tt.format = "SELECT {}, {2} FROM actor"
+		tt.values = []any{tmpfield("name"), Expr("COALESCE({}, {})", tmpfield("age"), FaultySQL{})}
+		buf := new(bytes.Buffer)
+		args := new([]any)
+		params := make(map[string][]int)
+		err := Writef(tt.ctx, tt.dialect, buf, args, params, tt.format, tt.values)
+		if !errors.Is(err, ErrFaultySQL) {
+			t.Fatal(testutil.Callers(), "expected ErrFaultySQL but got %v", err)
+		}
+		var formatErr *FormatError
+		if !errors.As(err, &formatErr) {
+			t.Fatal(testutil.Callers(), "expected *FormatError but got %#v", err)
+		}
+		gotPosition := [3]any{formatErr.Placeholder, formatErr.Offset, formatErr.Index}
+		if diff := testutil.Diff(gotPosition, [3]any{"{2}", 11, 1}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		wantErr := `{2} at offset 11 of "SELECT {}, {2} FROM actor": {} at offset 13 of "COALESCE({}, {})": ` + ErrFaultySQL.Error()
+		if diff := testutil.Diff(err.Error(), wantErr); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("error position fragment", func(t *testing.T) {
+		t.Parallel()
+		var tt TT
+		tt.format = "SELECT actor_id, first_name, last_name, last_update FROM actor WHERE {bad-name} AND actor_id = 1 ORDER BY actor_id"
+		buf := new(bytes.Buffer)
+		args := new([]any)
+		params := make(map[string][]int)
+		err := Writef(tt.ctx, tt.dialect, buf, args, params, tt.format, tt.values)
+		var formatErr *FormatError
+		if !errors.As(err, &formatErr) {
+			t.Fatal(testutil.Callers(), "expected *FormatError but got %#v", err)
+		}
+		wantErr := `{bad-name} at offset 69 of "... last_update FROM actor WHERE {bad-name} AND actor_id = 1 OR...": "bad-name" is not a valid param name (only letters, digits and '_' are allowed)`
+		if diff := testutil.Diff(err.Error(), wantErr); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
 }
 
 func TestSprintf(t *testing.T) {
@@ -813,6 +909,24 @@ func TestSprintf(t *testing.T) {
 		assert(t, tt)
 	})
 
+	t.Run("backslash escapes inside strings", func(t *testing.T) {
+		t.Parallel()
+		tt := TT{
+			dialect:    DialectMySQL,
+			query:      `SELECT 'it\'s ?', ?`,
+			args:       []any{1},
+			wantString: `SELECT 'it\'s ?', 1`,
+		}
+		assert(t, tt)
+		tt = TT{
+			dialect:    DialectPostgres,
+			query:      `SELECT E'it\'s $1', $1`,
+			args:       []any{1},
+			wantString: `SELECT E'it\'s $1', 1`,
+		}
+		assert(t, tt)
+	})
+
 	t.Run("insideString, insideIdentifier and escaping single quotes (dialect == mysql)", func(t *testing.T) {
 		t.Parallel()
 		var tt TT
@@ -1083,6 +1197,114 @@ func TestSprintf(t *testing.T) {
 	})
 }
 
+func Test_writeIdentifier(t *testing.T) {
+	type TT struct {
+		dialect     string
+		identifier  string
+		alwaysQuote bool
+		wantString  string
+	}
+
+	tests := []TT{
+		{DialectPostgres, "actor_id", false, `actor_id`},
+		{DialectPostgres, "actor_id", true, `"actor_id"`},
+		{DialectPostgres, "ActorID", false, `"ActorID"`},
+		{DialectPostgres, "user", false, `"user"`},
+		{DialectMySQL, "ActorID", false, "`ActorID`"},
+		{DialectSQLServer, "ActorID", false, `[ActorID]`},
+		{DialectSQLServer, "Actor]ID", false, `[Actor]]ID]`},
+		{DialectPostgres, `Actor"ID`, false, `"Actor""ID"`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.identifier, func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			writeIdentifier(buf, tt.dialect, tt.identifier, tt.alwaysQuote)
+			if diff := testutil.Diff(buf.String(), tt.wantString); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			if !tt.alwaysQuote {
+				if diff := testutil.Diff(buf.String(), QuoteIdentifier(tt.dialect, tt.identifier)); diff != "" {
+					t.Error(testutil.Callers(), diff)
+				}
+			}
+		})
+	}
+
+	t.Run("no allocations", func(t *testing.T) {
+		tbl := NewTableStruct("public", "Film", "")
+		field := NewNumberField("FilmID", tbl)
+		buf := &bytes.Buffer{}
+		buf.Grow(64)
+		allocs := testing.AllocsPerRun(100, func() {
+			buf.Reset()
+			_ = tbl.WriteSQL(context.Background(), DialectPostgres, buf, nil, nil)
+			_ = field.WriteSQL(context.Background(), DialectPostgres, buf, nil, nil)
+		})
+		if allocs != 0 {
+			t.Errorf(testutil.Callers()+" expected no allocations, got %v", allocs)
+		}
+	})
+}
+
+func TestSprintfCache(t *testing.T) {
+	t.Run("cached query", func(t *testing.T) {
+		t.Parallel()
+		query := "SELECT name FROM users WHERE name = 'O''Brien?' AND id = ? AND age > ?"
+		for i := 0; i < 2; i++ {
+			gotString, err := Sprintf(DialectMySQL, query, []any{1, 18})
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			wantString := "SELECT name FROM users WHERE name = 'O''Brien?' AND id = 1 AND age > 18"
+			if diff := testutil.Diff(gotString, wantString); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		}
+		// The same query lexed for a different dialect is cached separately.
+		gotString, err := Sprintf(DialectPostgres, query, []any{1, 18})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(gotString, query); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("eviction", func(t *testing.T) {
+		t.Parallel()
+		cache := &sprintfTemplateCache{capacity: 2}
+		cache.get(DialectSQLite, "SELECT ?")
+		cache.get(DialectSQLite, "SELECT ?, ?")
+		cache.get(DialectSQLite, "SELECT ?")
+		cache.get(DialectSQLite, "SELECT ?, ?, ?")
+		var gotQueries []string
+		for element := cache.order.Front(); element != nil; element = element.Next() {
+			gotQueries = append(gotQueries, element.Value.(*sprintfCacheEntry).key.query)
+		}
+		if diff := testutil.Diff(gotQueries, []string{"SELECT ?, ?, ?", "SELECT ?"}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(len(cache.entries), 2); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		cache := &sprintfTemplateCache{}
+		template := cache.get(DialectPostgres, "SELECT $1")
+		if diff := testutil.Diff(template.tokens, []sprintfToken{{text: "SELECT ", paramName: "$1"}, {}}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if len(cache.entries) != 0 {
+			t.Error(testutil.Callers(), "expected no cached entries")
+		}
+	})
+}
+
 func TestSprint(t *testing.T) {
 	type TT struct {
 		description string
@@ -1090,6 +1312,7 @@ func TestSprint(t *testing.T) {
 		value       any
 		wantString  string
 	}
+	type hash []byte
 	singaporeLocation, _ := time.LoadLocation("Asia/Singapore")
 
 	tests := []TT{{
@@ -1123,6 +1346,26 @@ func TestSprint(t *testing.T) {
 		description: "[]byte",
 		value:       []byte{0xff, 0xff},
 		wantString:  `x'ffff'`,
+	}, {
+		description: "sqlserver []byte",
+		dialect:     DialectSQLServer,
+		value:       []byte{0xab, 0xcd},
+		wantString:  `0xabcd`,
+	}, {
+		description: "named []byte",
+		dialect:     DialectMySQL,
+		value:       hash{0xab, 0xcd},
+		wantString:  `x'abcd'`,
+	}, {
+		description: "postgres BitValue",
+		dialect:     DialectPostgres,
+		value:       BitValue([]bool{true, false, true, false}),
+		wantString:  `'1010'`,
+	}, {
+		description: "mysql BitValue",
+		dialect:     DialectMySQL,
+		value:       BitValue(uint64(0x1ff)),
+		wantString:  `x'01ff'`,
 	}, {
 		description: "string",
 		value:       "' OR ''test' = '; DROP TABLE users; -- ",
@@ -1507,3 +1750,174 @@ func (v dialectValuer) DialectValuer(dialect string) (driver.Valuer, error) {
 	}
 	return v.valuer, nil
 }
+
+func TestValidateIdentifier(t *testing.T) {
+	type TestTable struct {
+		dialect string
+		name    string
+		wantErr bool
+	}
+	tests := []TestTable{
+		{dialect: DialectPostgres, name: "user_id"},
+		{dialect: DialectPostgres, name: "User Id"},
+		{dialect: DialectPostgres, name: "[user_id]"},
+		{dialect: DialectPostgres, name: "", wantErr: true},
+		{dialect: DialectPostgres, name: `user"id`, wantErr: true},
+		{dialect: DialectSQLite, name: "user'id", wantErr: true},
+		{dialect: DialectSQLite, name: "user\x00id", wantErr: true},
+		{dialect: DialectSQLite, name: "user\xffid", wantErr: true},
+		{dialect: DialectMySQL, name: "user`id", wantErr: true},
+		{dialect: DialectMySQL, name: "user_id ", wantErr: true},
+		{dialect: DialectSQLServer, name: "user]id", wantErr: true},
+		{dialect: "", name: "[user_id]", wantErr: true},
+		{dialect: DialectPostgres, name: strings.Repeat("a", 63)},
+		{dialect: DialectPostgres, name: strings.Repeat("a", 64), wantErr: true},
+		{dialect: DialectPostgres, name: strings.Repeat("é", 32), wantErr: true},
+		{dialect: DialectMySQL, name: strings.Repeat("é", 64)},
+		{dialect: DialectMySQL, name: strings.Repeat("a", 65), wantErr: true},
+		{dialect: DialectSQLServer, name: strings.Repeat("a", 128)},
+		{dialect: DialectSQLServer, name: strings.Repeat("a", 129), wantErr: true},
+		{dialect: DialectSQLite, name: strings.Repeat("a", 1000)},
+	}
+	for _, tt := range tests {
+		err := ValidateIdentifier(tt.dialect, tt.name)
+		if tt.wantErr && err == nil {
+			t.Errorf(testutil.Callers()+" %s %q: expected error but got nil", tt.dialect, tt.name)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf(testutil.Callers()+" %s %q: %v", tt.dialect, tt.name, err)
+		}
+	}
+}
+
+func TestWithShortenedAliases(t *testing.T) {
+	type ACTOR_ALIAS struct {
+		TableStruct `sq:"actor"`
+		ACTOR_ID    NumberField
+	}
+	alias := "actor_with_an_alias_that_is_much_too_long_for_postgres_to_handle_correctly"
+	a := New[ACTOR_ALIAS](alias)
+	query := Postgres.
+		Select(a.ACTOR_ID.As(alias + "_id")).
+		From(a).
+		Where(a.ACTOR_ID.EqInt(1))
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := ToSQL(DialectPostgres, query, nil)
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		if !strings.Contains(err.Error(), "exceeds the postgres limit of 63 bytes") {
+			t.Error(testutil.Callers(), err)
+		}
+	})
+
+	t.Run("shortened", func(t *testing.T) {
+		t.Parallel()
+		ctx := WithShortenedAliases(context.Background())
+		gotQuery, _, err := ToSQLContext(ctx, DialectPostgres, query, nil)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		shortAlias := shortenIdentifier(DialectPostgres, alias)
+		shortFieldAlias := shortenIdentifier(DialectPostgres, alias+"_id")
+		if diff := testutil.Diff(len(shortAlias), 63); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if shortAlias[:54] != shortFieldAlias[:54] || shortAlias == shortFieldAlias {
+			t.Errorf(testutil.Callers()+" expected %q and %q to share a prefix but differ", shortAlias, shortFieldAlias)
+		}
+		wantQuery := "SELECT " + shortAlias + ".actor_id AS " + shortFieldAlias +
+			" FROM actor AS " + shortAlias +
+			" WHERE " + shortAlias + ".actor_id = $1"
+		if diff := testutil.Diff(gotQuery, wantQuery); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("characters", func(t *testing.T) {
+		t.Parallel()
+		got := shortenIdentifier(DialectMySQL, strings.Repeat("é", 100))
+		if diff := testutil.Diff(utf8.RuneCountInString(got), 64); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf(testutil.Callers()+" %q is not valid UTF-8", got)
+		}
+	})
+}
+
+func TestWithDriverNamedArgs(t *testing.T) {
+	ctx := WithDriverNamedArgs(context.Background())
+	type TT struct {
+		dialect   string
+		wantQuery string
+		wantArgs  []any
+	}
+	tests := []TT{{
+		dialect:   DialectSQLite,
+		wantQuery: "SELECT actor.first_name FROM actor WHERE actor.actor_id = $p1 AND actor.last_name = $last_name AND actor.first_name IN ($p3, $p4)",
+		wantArgs:  []any{sql.Named("p1", 1), sql.Named("last_name", "GUINESS"), sql.Named("p3", "PENELOPE"), sql.Named("p4", "NICK")},
+	}, {
+		dialect:   DialectSQLServer,
+		wantQuery: "SELECT actor.first_name FROM actor WHERE actor.actor_id = @p1 AND actor.last_name = @last_name AND actor.first_name IN (@p3, @p4)",
+		wantArgs:  []any{sql.Named("p1", 1), sql.Named("last_name", "GUINESS"), sql.Named("p3", "PENELOPE"), sql.Named("p4", "NICK")},
+	}, {
+		dialect:   DialectPostgres,
+		wantQuery: "SELECT actor.first_name FROM actor WHERE actor.actor_id = $1 AND actor.last_name = $2 AND actor.first_name IN ($3, $4)",
+		wantArgs:  []any{1, "GUINESS", "PENELOPE", "NICK"},
+	}}
+	for _, tt := range tests {
+		query := Select(ACTOR.FIRST_NAME).From(ACTOR).Where(
+			ACTOR.ACTOR_ID.EqInt(1),
+			ACTOR.LAST_NAME.Eq(StringParam("last_name", "GUINESS")),
+			ACTOR.FIRST_NAME.In([]string{"PENELOPE", "NICK"}),
+		)
+		gotQuery, gotArgs, err := ToSQLContext(ctx, tt.dialect, query, nil)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(gotQuery, tt.wantQuery); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(gotArgs, tt.wantArgs); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	}
+
+	t.Run("ordinal", func(t *testing.T) {
+		gotQuery, gotArgs, err := ToSQLContext(ctx, DialectSQLite, Expr("{1} + {1} + {2}", 1, 2), nil)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(gotQuery, "$p1 + $p1 + $p2"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(gotArgs, []any{sql.Named("p1", 1), sql.Named("p2", 2)}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("fetch", func(t *testing.T) {
+		db := newDB(t)
+		_, err := ExecContext(ctx, db, SQLite.
+			InsertInto(ACTOR).
+			Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+			Values(1, "PENELOPE", "GUINESS").
+			Values(2, "NICK", "WAHLBERG"),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		firstNames, err := FetchAllContext(ctx, db, SQLite.From(ACTOR).Where(ACTOR.ACTOR_ID.GtInt(1)), func(row *Row) string {
+			return row.String("{}", ACTOR.FIRST_NAME)
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(firstNames, []string{"NICK"}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
@@ -0,0 +1,434 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/googleuuid"
+)
+
+// ImportOptions are the options used by ImportCSV.
+type ImportOptions struct {
+	// BatchSize is the number of rows inserted per INSERT query. Defaults to
+	// 500. It is lowered if a batch would have more arguments than the
+	// dialect allows in a single query (999 for SQLite, 2100 for SQL Server
+	// and 65535 for Postgres and MySQL).
+	BatchSize int
+
+	// Comma is the field delimiter. Defaults to ','.
+	Comma rune
+
+	// Null is the value of a CSV field that is imported as NULL. Defaults to
+	// an empty string i.e. empty fields are imported as NULL.
+	Null string
+
+	// TimeFormats are the layouts tried (in order) when parsing the value of
+	// a TimeField. Defaults to time.RFC3339Nano, "2006-01-02 15:04:05" and
+	// "2006-01-02".
+	TimeFormats []string
+
+	// MaxErrors is the number of row errors after which the import is
+	// stopped. Zero means no limit.
+	MaxErrors int
+}
+
+// ImportResult is the result of ImportCSV.
+type ImportResult struct {
+	// RowsImported is the number of rows that were inserted.
+	RowsImported int64
+
+	// Errors are the errors of the rows that were not inserted, either
+	// because a value could not be coerced into its field's type or because
+	// the database rejected the row.
+	Errors []ImportRowError
+}
+
+// ImportRowError is the error of a CSV row that could not be imported.
+type ImportRowError struct {
+	// Line is the line number of the row in the CSV input (starting from 1).
+	Line int
+
+	// Err is the error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e ImportRowError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e ImportRowError) Unwrap() error { return e.Err }
+
+// ImportCSV reads CSV from r and inserts its rows into table, in batches. The
+// first CSV row is the header. mapping maps CSV header names to the fields
+// they are inserted into; CSV columns that are not in the mapping are
+// ignored. If mapping is nil, the CSV header names are matched against the
+// column names of the table.
+//
+// Every value is coerced into the type of its field (e.g. a NumberField value
+// must be a number, a TimeField value must be a time in one of the
+// ImportOptions.TimeFormats). Rows that cannot be coerced or that are
+// rejected by the database are skipped and reported in ImportResult.Errors,
+// the rest of the rows are still imported. When an INSERT fails, its rows
+// are retried one at a time to find out which rows were rejected. If db is a
+// transaction, every INSERT is wrapped in a savepoint so that a rejected
+// INSERT does not abort the transaction (as it does in Postgres).
+//
+// ImportCSV returns an error (along with the result so far) if the CSV cannot
+// be read, if the header does not match the mapping or if there are more
+// than ImportOptions.MaxErrors row errors. To import all rows or none, pass
+// in a transaction and roll it back if there are any errors.
+//
+//	a := sq.New[ACTOR]("")
+//	result, err := sq.ImportCSV(db, a, file, map[string]sq.Field{
+//	    "id":         a.ACTOR_ID,
+//	    "first_name": a.FIRST_NAME,
+//	    "last_name":  a.LAST_NAME,
+//	}, sq.ImportOptions{})
+func ImportCSV(db DB, table Table, r io.Reader, mapping map[string]Field, opts ImportOptions) (ImportResult, error) {
+	return importCSV(context.Background(), db, table, r, mapping, opts)
+}
+
+// ImportCSVContext is like ImportCSV but additionally requires a
+// context.Context.
+func ImportCSVContext(ctx context.Context, db DB, table Table, r io.Reader, mapping map[string]Field, opts ImportOptions) (ImportResult, error) {
+	return importCSV(ctx, db, table, r, mapping, opts)
+}
+
+// errTooManyImportErrors is returned by importCSV once MaxErrors is exceeded.
+var errTooManyImportErrors = errors.New("too many errors")
+
+func importCSV(ctx context.Context, db DB, table Table, r io.Reader, mapping map[string]Field, opts ImportOptions) (result ImportResult, err error) {
+	if db == nil {
+		return result, fmt.Errorf("db is nil")
+	}
+	if table == nil {
+		return result, fmt.Errorf("table is nil")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if len(opts.TimeFormats) == 0 {
+		opts.TimeFormats = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+	}
+	csvReader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		csvReader.Comma = opts.Comma
+	}
+	csvReader.ReuseRecord = true
+	header, err := csvReader.Read()
+	if err != nil {
+		return result, fmt.Errorf("reading CSV header: %w", err)
+	}
+	// The header is overwritten by the next Read because of ReuseRecord.
+	header = append([]string(nil), header...)
+	columns, fields, err := importColumns(table, header, mapping)
+	if err != nil {
+		return result, err
+	}
+	dialect := dbDialect(db)
+	if len(fields) > 0 && opts.BatchSize*len(fields) > maxArgCount(dialect) {
+		opts.BatchSize = maxArgCount(dialect) / len(fields)
+		if opts.BatchSize == 0 {
+			opts.BatchSize = 1
+		}
+	}
+	importer := &csvImporter{
+		ctx:     ctx,
+		db:      db,
+		dialect: dialect,
+		inTx:    isTx(db),
+		table:   table,
+		fields:  fields,
+		opts:    opts,
+		result:  &result,
+	}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// A row with the wrong number of fields is a row error, the
+			// rest of the CSV can still be read.
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) || parseErr.Err != csv.ErrFieldCount {
+				return result, err
+			}
+			err = importer.rowError(parseErr.StartLine, parseErr.Err)
+			if err != nil {
+				return result, err
+			}
+			continue
+		}
+		line, _ := csvReader.FieldPos(0)
+		values := make([]any, len(columns))
+		var rowErr error
+		for i, column := range columns {
+			values[i], rowErr = importer.coerce(fields[i], record[column])
+			if rowErr != nil {
+				rowErr = fmt.Errorf("%s: %w", header[column], rowErr)
+				break
+			}
+		}
+		if rowErr != nil {
+			err = importer.rowError(line, rowErr)
+			if err != nil {
+				return result, err
+			}
+			continue
+		}
+		importer.lines = append(importer.lines, line)
+		importer.rows = append(importer.rows, values)
+		if len(importer.rows) >= opts.BatchSize {
+			err = importer.flush()
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+	err = importer.flush()
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// maxArgCount returns the maximum number of arguments a query can have in a
+// dialect. SQLite's limit is 32766 since 3.32.0, the lower limit of older
+// versions is used.
+func maxArgCount(dialect string) int {
+	switch dialect {
+	case DialectSQLite:
+		return 999
+	case DialectSQLServer:
+		return 2100
+	default:
+		return 65535
+	}
+}
+
+// isTx reports whether db is a transaction, or wraps one.
+func isTx(db DB) bool {
+	for db != nil {
+		if _, ok := db.(*sql.Tx); ok {
+			return true
+		}
+		wrapper, ok := db.(interface{ unwrap() DB })
+		if !ok {
+			break
+		}
+		db = wrapper.unwrap()
+	}
+	return false
+}
+
+// importColumns returns the indexes of the CSV columns to be imported and the
+// fields they are imported into.
+func importColumns(table Table, header []string, mapping map[string]Field) (columns []int, fields []Field, err error) {
+	if mapping == nil {
+		tblFields, names := tableFields(table)
+		for i, name := range header {
+			found := false
+			for j := range names {
+				if strings.EqualFold(strings.TrimSpace(name), names[j]) {
+					columns, fields = append(columns, i), append(fields, tblFields[j])
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, nil, fmt.Errorf("CSV column %q does not match any column of the table", name)
+			}
+		}
+		return columns, fields, nil
+	}
+	for name, field := range mapping {
+		if field == nil {
+			return nil, nil, fmt.Errorf("field of CSV column %q is nil", name)
+		}
+		found := false
+		for i := range header {
+			if strings.TrimSpace(header[i]) == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("CSV column %q not found in header %q", name, header)
+		}
+	}
+	for i, name := range header {
+		if field, ok := mapping[strings.TrimSpace(name)]; ok {
+			columns, fields = append(columns, i), append(fields, field)
+		}
+	}
+	return columns, fields, nil
+}
+
+type csvImporter struct {
+	ctx     context.Context
+	db      DB
+	dialect string
+	inTx    bool
+	table   Table
+	fields  []Field
+	opts    ImportOptions
+	result  *ImportResult
+	// rows and lines are the coerced values and CSV line numbers of the rows
+	// waiting to be inserted.
+	rows  [][]any
+	lines []int
+}
+
+// rowError records the error of a row. It returns an error if there are now
+// more than MaxErrors row errors.
+func (importer *csvImporter) rowError(line int, err error) error {
+	importer.result.Errors = append(importer.result.Errors, ImportRowError{Line: line, Err: err})
+	if importer.opts.MaxErrors > 0 && len(importer.result.Errors) > importer.opts.MaxErrors {
+		return fmt.Errorf("%w (%d row errors)", errTooManyImportErrors, len(importer.result.Errors))
+	}
+	return nil
+}
+
+// flush inserts the pending rows. If the INSERT fails, the rows are inserted
+// one at a time so that only the rejected rows are skipped.
+func (importer *csvImporter) flush() error {
+	if len(importer.rows) == 0 {
+		return nil
+	}
+	defer func() {
+		importer.rows = importer.rows[:0]
+		importer.lines = importer.lines[:0]
+	}()
+	rejected, err := importer.insert(importer.rows)
+	if err != nil {
+		return err
+	}
+	if rejected == nil {
+		importer.result.RowsImported += int64(len(importer.rows))
+		return nil
+	}
+	if len(importer.rows) == 1 {
+		return importer.rowError(importer.lines[0], rejected)
+	}
+	for i, row := range importer.rows {
+		rejected, err := importer.insert([][]any{row})
+		if err != nil {
+			return err
+		}
+		if rejected != nil {
+			err = importer.rowError(importer.lines[i], rejected)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		importer.result.RowsImported++
+	}
+	return nil
+}
+
+// importSavepoint is the savepoint that every INSERT is wrapped in when
+// importing into a transaction.
+const importSavepoint = "sq_import"
+
+// insert inserts rows. rejected is the error of an INSERT rejected by the
+// database, err is an error that stops the import (a canceled context or a
+// failed savepoint).
+func (importer *csvImporter) insert(rows [][]any) (rejected error, err error) {
+	query := InsertInto(importer.table).Columns(importer.fields...)
+	query.Dialect = importer.dialect
+	query.RowValues = make([]RowValue, len(rows))
+	for i, row := range rows {
+		query.RowValues[i] = row
+	}
+	if importer.inTx {
+		err = savepoint(importer.ctx, importer.db, importer.dialect, "SAVEPOINT", importSavepoint, 4)
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, rejected = exec(importer.ctx, importer.db, query, 4)
+	if rejected != nil {
+		if err := importer.ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if importer.inTx {
+		if rejected != nil {
+			err = savepoint(importer.ctx, importer.db, importer.dialect, "ROLLBACK TO SAVEPOINT", importSavepoint, 4)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = savepoint(importer.ctx, importer.db, importer.dialect, "RELEASE SAVEPOINT", importSavepoint, 4)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rejected, nil
+}
+
+// coerce converts a CSV value into a value of the field's type.
+func (importer *csvImporter) coerce(field Field, value string) (any, error) {
+	if value == importer.opts.Null {
+		return nil, nil
+	}
+	switch field.(type) {
+	case NumberField:
+		value = strings.TrimSpace(value)
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return f, nil
+	case BooleanField:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return b, nil
+	case TimeField:
+		value = strings.TrimSpace(value)
+		for _, layout := range importer.opts.TimeFormats {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("%q does not match any of the time formats %q", value, importer.opts.TimeFormats)
+	case UUIDField:
+		uuid, err := googleuuid.Parse(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a UUID: %w", value, err)
+		}
+		return UUIDValue(uuid), nil
+	case JSONField:
+		if !json.Valid([]byte(value)) {
+			return nil, fmt.Errorf("%q is not valid JSON", value)
+		}
+		return JSONValue(json.RawMessage(value)), nil
+	case BitField:
+		bits, err := parseBits(DialectPostgres, strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		return BitValue(bits), nil
+	case BinaryField:
+		return []byte(value), nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,160 @@
+package sq
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestImportCSV(t *testing.T) {
+	t.Run("mapping", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		input := "id,first,last,updated,ignored\n" +
+			"1,PENELOPE,GUINESS,2006-02-15,x\n" +
+			"two,NICK,WAHLBERG,2006-02-15,x\n" + // not a number
+			"3,ED,CHASE,yesterday,x\n" + // not a time
+			"1,JENNIFER,DAVIS,2006-02-15 04:34:33,x\n" + // duplicate primary key
+			"4,JOHNNY,,,x\n" + // NULL last_name violates NOT NULL
+			"5,BETTE\n" + // wrong number of fields
+			"6,GRACE,MOSTEL,2006-02-15T04:34:33Z,x\n"
+		result, err := ImportCSV(db, ACTOR, strings.NewReader(input), map[string]Field{
+			"id":      ACTOR.ACTOR_ID,
+			"first":   ACTOR.FIRST_NAME,
+			"last":    ACTOR.LAST_NAME,
+			"updated": ACTOR.LAST_UPDATE,
+		}, ImportOptions{BatchSize: 2})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(result.RowsImported, int64(2)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		var gotLines []int
+		for _, rowErr := range result.Errors {
+			gotLines = append(gotLines, rowErr.Line)
+		}
+		if diff := testutil.Diff(gotLines, []int{3, 4, 5, 7, 6}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		actors, err := FetchAll(db, SQLite.From(ACTOR).OrderBy(ACTOR.ACTOR_ID), func(row *Row) Actor {
+			return Actor{
+				ActorID:    row.IntField(ACTOR.ACTOR_ID),
+				FirstName:  row.StringField(ACTOR.FIRST_NAME),
+				LastName:   row.StringField(ACTOR.LAST_NAME),
+				LastUpdate: row.TimeField(ACTOR.LAST_UPDATE),
+			}
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(len(actors), 2); diff != "" {
+			t.Fatal(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(actors[0], Actor{ActorID: 1, FirstName: "PENELOPE", LastName: "GUINESS", LastUpdate: time.Date(2006, 2, 15, 0, 0, 0, 0, time.UTC)}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(actors[1].FirstName, "GRACE"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("table columns", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		input := "ACTOR_ID;first_name;last_name\n1;PENELOPE;GUINESS\n2;NICK;WAHLBERG\n"
+		result, err := ImportCSV(db, ACTOR, strings.NewReader(input), nil, ImportOptions{Comma: ';'})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(result.RowsImported, int64(2)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if len(result.Errors) > 0 {
+			t.Error(testutil.Callers(), result.Errors)
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		t.Parallel()
+		_, err := ImportCSV(newDB(t), ACTOR, strings.NewReader("actor_id,nickname\n1,PENNY\n"), nil, ImportOptions{})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+	})
+
+	t.Run("max errors", func(t *testing.T) {
+		t.Parallel()
+		input := "actor_id,first_name,last_name\na,b,c\nd,e,f\n"
+		result, err := ImportCSV(newDB(t), ACTOR, strings.NewReader(input), nil, ImportOptions{MaxErrors: 1})
+		if !errors.Is(err, errTooManyImportErrors) {
+			t.Fatalf(testutil.Callers()+" expected errTooManyImportErrors, got %v", err)
+		}
+		if diff := testutil.Diff(len(result.Errors), 2); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("batch size capped by argument limit", func(t *testing.T) {
+		t.Parallel()
+		var b strings.Builder
+		b.WriteString("actor_id,first_name,last_name\n")
+		for i := 1; i <= 700; i++ {
+			b.WriteString(strconv.Itoa(i) + ",FIRST,LAST\n")
+		}
+		var argCounts []int
+		db := WithQueryHooks(NewDB(newDB(t), DialectSQLite), func(ctx context.Context, dialect string, query string, args []any) (string, []any, error) {
+			argCounts = append(argCounts, len(args))
+			return query, args, nil
+		})
+		result, err := ImportCSV(db, ACTOR, strings.NewReader(b.String()), nil, ImportOptions{BatchSize: 500})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(result.RowsImported, int64(700)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(argCounts, []int{999, 999, 102}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		t.Parallel()
+		var commands []string
+		db := WithQueryHooks(NewDB(newDB(t), DialectSQLite), func(ctx context.Context, dialect string, query string, args []any) (string, []any, error) {
+			command, _, _ := strings.Cut(query, " ")
+			commands = append(commands, command)
+			return query, args, nil
+		})
+		input := "actor_id,first_name,last_name\n1,PENELOPE,GUINESS\n1,NICK,WAHLBERG\n"
+		var result ImportResult
+		err := Tx(db, TxOptions{}, func(tx DB) error {
+			var err error
+			result, err = ImportCSV(tx, ACTOR, strings.NewReader(input), nil, ImportOptions{})
+			return err
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(result.RowsImported, int64(1)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(len(result.Errors), 1); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		wantCommands := []string{
+			"SAVEPOINT", "INSERT", "ROLLBACK", "RELEASE", // the batch is rejected
+			"SAVEPOINT", "INSERT", "RELEASE", // the first row is inserted
+			"SAVEPOINT", "INSERT", "ROLLBACK", "RELEASE", // the second row is rejected
+		}
+		if diff := testutil.Diff(commands, wantCommands); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// CreateIndexQuery represents an SQL CREATE INDEX statement.
+//
+//	postgres:  CREATE [UNIQUE] INDEX [IF NOT EXISTS] name ON table [USING method] (fields) [INCLUDE (fields)] [WHERE predicate]
+//	sqlite:    CREATE [UNIQUE] INDEX [IF NOT EXISTS] name ON table (fields) [WHERE predicate]
+//	mysql:     CREATE [UNIQUE] INDEX name ON table (fields) [USING method]
+//	sqlserver: CREATE [UNIQUE] INDEX name ON table (fields) [INCLUDE (fields)] [WHERE predicate]
+//
+// Fields that are not table columns (e.g. sq.Expr("lower({})", u.EMAIL)) are
+// written as expressions, wrapped in parentheses. Indexes are stored in the
+// database so the fields and the predicate cannot contain bind parameters,
+// use sq.Literal for literal values.
+type CreateIndexQuery struct {
+	Dialect        string
+	Name           string
+	CheckNotExists bool
+	IsUnique       bool
+	Table          Table
+	Fields         []Field
+	// USING method (postgres, mysql)
+	Method string
+	// INCLUDE (fields) (postgres, sqlserver)
+	IncludeFields []Field
+	// WHERE predicate (postgres, sqlite, sqlserver)
+	WherePredicate Predicate
+}
+
+var _ Query = (*CreateIndexQuery)(nil)
+
+// CreateIndex returns a new CreateIndexQuery.
+//
+//	u := sq.New[USERS]("")
+//	q := sq.CreateIndex("users_email_idx").
+//	    IfNotExists().
+//	    On(u, u.EMAIL).
+//	    Unique().
+//	    Where(u.DELETED_AT.IsNull())
+func CreateIndex(name string) CreateIndexQuery {
+	return CreateIndexQuery{Name: name}
+}
+
+// IfNotExists adds IF NOT EXISTS to the query.
+func (q CreateIndexQuery) IfNotExists() CreateIndexQuery {
+	q.CheckNotExists = true
+	return q
+}
+
+// On sets the table and the indexed fields of the query.
+func (q CreateIndexQuery) On(table Table, fields ...Field) CreateIndexQuery {
+	q.Table = table
+	q.Fields = fields
+	return q
+}
+
+// Unique makes the index a unique index.
+func (q CreateIndexQuery) Unique() CreateIndexQuery {
+	q.IsUnique = true
+	return q
+}
+
+// Using sets the index method (e.g. btree, hash, gin, gist) of the query.
+func (q CreateIndexQuery) Using(method string) CreateIndexQuery {
+	q.Method = method
+	return q
+}
+
+// Include sets the included (non-key) fields of the query.
+func (q CreateIndexQuery) Include(fields ...Field) CreateIndexQuery {
+	q.IncludeFields = fields
+	return q
+}
+
+// Where makes the index a partial index, indexing only the rows matching the
+// predicates.
+func (q CreateIndexQuery) Where(predicates ...Predicate) CreateIndexQuery {
+	if len(predicates) == 1 {
+		q.WherePredicate = predicates[0]
+	} else {
+		q.WherePredicate = And(predicates...)
+	}
+	return q
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (q CreateIndexQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if q.Name == "" {
+		return fmt.Errorf("CreateIndex: index has no name")
+	}
+	if q.Table == nil {
+		return fmt.Errorf("CreateIndex: table is nil")
+	}
+	if len(q.Fields) == 0 {
+		return fmt.Errorf("CreateIndex: no fields provided")
+	}
+	if q.CheckNotExists && (dialect == DialectMySQL || dialect == DialectSQLServer) {
+		return fmt.Errorf("CreateIndex: %s does not support CREATE INDEX IF NOT EXISTS", dialect)
+	}
+	if q.Method != "" && dialect != DialectPostgres && dialect != DialectMySQL {
+		return fmt.Errorf("CreateIndex: %s does not support USING", dialect)
+	}
+	if len(q.IncludeFields) > 0 && dialect != DialectPostgres && dialect != DialectSQLServer {
+		return fmt.Errorf("CreateIndex: %s does not support INCLUDE", dialect)
+	}
+	if q.WherePredicate != nil && dialect == DialectMySQL {
+		return fmt.Errorf("CreateIndex: %s does not support partial indexes", dialect)
+	}
+	// Indexes cannot contain bind parameters, anything written into
+	// indexArgs is an error.
+	var indexArgs []any
+	buf.WriteString("CREATE ")
+	if q.IsUnique {
+		buf.WriteString("UNIQUE ")
+	}
+	buf.WriteString("INDEX ")
+	if q.CheckNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	buf.WriteString(QuoteIdentifier(dialect, q.Name) + " ON ")
+	err := q.Table.WriteSQL(ctx, dialect, buf, &indexArgs, nil)
+	if err != nil {
+		return fmt.Errorf("CreateIndex: table: %w", err)
+	}
+	if q.Method != "" && dialect == DialectPostgres {
+		buf.WriteString(" USING " + q.Method)
+	}
+	// Qualified column names are not allowed in index expressions and
+	// predicates.
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = context.WithValue(ctx, unqualifiedFieldsKey{}, true)
+	buf.WriteString(" (")
+	err = writeIndexFields(ctx, dialect, buf, &indexArgs, q.Fields)
+	if err != nil {
+		return fmt.Errorf("CreateIndex: %w", err)
+	}
+	buf.WriteString(")")
+	if len(q.IncludeFields) > 0 {
+		buf.WriteString(" INCLUDE (")
+		err = writeIndexFields(ctx, dialect, buf, &indexArgs, q.IncludeFields)
+		if err != nil {
+			return fmt.Errorf("CreateIndex: INCLUDE: %w", err)
+		}
+		buf.WriteString(")")
+	}
+	if q.Method != "" && dialect == DialectMySQL {
+		buf.WriteString(" USING " + q.Method)
+	}
+	if q.WherePredicate != nil {
+		buf.WriteString(" WHERE ")
+		switch predicate := q.WherePredicate.(type) {
+		case VariadicPredicate:
+			predicate.Toplevel = true
+			err = predicate.WriteSQL(ctx, dialect, buf, &indexArgs, nil)
+		default:
+			err = predicate.WriteSQL(ctx, dialect, buf, &indexArgs, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("CreateIndex: WHERE: %w", err)
+		}
+	}
+	if len(indexArgs) > 0 {
+		return fmt.Errorf("CreateIndex: index cannot contain args (found %d), use sq.Literal for literal values", len(indexArgs))
+	}
+	return nil
+}
+
+// writeIndexFields writes the fields of an index. Anything that is not a table
+// column is written as a parenthesized expression.
+func writeIndexFields(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, fields []Field) error {
+	for i, field := range fields {
+		if field == nil {
+			return fmt.Errorf("field #%d is nil", i+1)
+		}
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		var err error
+		switch field.(type) {
+		case AnyField, ArrayField, BinaryField, BitField, BooleanField, EnumField, JSONField, NumberField, StringField, TimeField, UUIDField, XMLField, genericColumn:
+			err = field.WriteSQL(ctx, dialect, buf, args, nil)
+		default:
+			buf.WriteString("(")
+			err = field.WriteSQL(ctx, dialect, buf, args, nil)
+			buf.WriteString(")")
+		}
+		if err != nil {
+			return fmt.Errorf("field #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// SetFetchableFields implements the Query interface. It always returns false
+// as the second result.
+func (q CreateIndexQuery) SetFetchableFields([]Field) (query Query, ok bool) {
+	return q, false
+}
+
+// GetDialect implements the Query interface.
+func (q CreateIndexQuery) GetDialect() string { return q.Dialect }
+
+// SetDialect sets the dialect of the query.
+func (q CreateIndexQuery) SetDialect(dialect string) CreateIndexQuery {
+	q.Dialect = dialect
+	return q
+}
+
+// DropIndexQuery represents an SQL DROP INDEX statement.
+//
+//	postgres:  DROP INDEX [IF EXISTS] name
+//	sqlite:    DROP INDEX [IF EXISTS] name
+//	mysql:     DROP INDEX name ON table
+//	sqlserver: DROP INDEX [IF EXISTS] name ON table
+type DropIndexQuery struct {
+	Dialect     string
+	Name        string
+	CheckExists bool
+	// Table is required for mysql and sqlserver.
+	Table Table
+}
+
+var _ Query = (*DropIndexQuery)(nil)
+
+// DropIndex returns a new DropIndexQuery.
+func DropIndex(name string) DropIndexQuery {
+	return DropIndexQuery{Name: name}
+}
+
+// IfExists adds IF EXISTS to the query.
+func (q DropIndexQuery) IfExists() DropIndexQuery {
+	q.CheckExists = true
+	return q
+}
+
+// On sets the table of the index, which is required for mysql and sqlserver.
+func (q DropIndexQuery) On(table Table) DropIndexQuery {
+	q.Table = table
+	return q
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (q DropIndexQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if q.Name == "" {
+		return fmt.Errorf("DropIndex: index has no name")
+	}
+	if q.CheckExists && dialect == DialectMySQL {
+		return fmt.Errorf("DropIndex: %s does not support DROP INDEX IF EXISTS", dialect)
+	}
+	buf.WriteString("DROP INDEX ")
+	if q.CheckExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	buf.WriteString(QuoteIdentifier(dialect, q.Name))
+	if dialect == DialectMySQL || dialect == DialectSQLServer {
+		if q.Table == nil {
+			return fmt.Errorf("DropIndex: %s requires the table of the index", dialect)
+		}
+		buf.WriteString(" ON ")
+		err := q.Table.WriteSQL(ctx, dialect, buf, args, params)
+		if err != nil {
+			return fmt.Errorf("DropIndex: table: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetFetchableFields implements the Query interface. It always returns false
+// as the second result.
+func (q DropIndexQuery) SetFetchableFields([]Field) (query Query, ok bool) {
+	return q, false
+}
+
+// GetDialect implements the Query interface.
+func (q DropIndexQuery) GetDialect() string { return q.Dialect }
+
+// SetDialect sets the dialect of the query.
+func (q DropIndexQuery) SetDialect(dialect string) DropIndexQuery {
+	q.Dialect = dialect
+	return q
+}
@@ -0,0 +1,127 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestCreateIndex(t *testing.T) {
+	type TestTable struct {
+		description string
+		dialect     string
+		item        Query
+		wantQuery   string
+	}
+
+	tests := []TestTable{{
+		description: "sqlite partial unique index",
+		dialect:     DialectSQLite,
+		item:        CreateIndex("actor_name_idx").IfNotExists().On(ACTOR, ACTOR.LAST_NAME, ACTOR.FIRST_NAME.Desc()).Unique().Where(ACTOR.ACTOR_ID.Gt(Literal(0))),
+		wantQuery:   "CREATE UNIQUE INDEX IF NOT EXISTS actor_name_idx ON actor (last_name, first_name DESC) WHERE actor_id > 0",
+	}, {
+		description: "postgres expression index",
+		dialect:     DialectPostgres,
+		item:        CreateIndex("actor_lower_name_idx").On(ACTOR, Expr("lower({})", ACTOR.LAST_NAME)).Using("btree").Include(ACTOR.FIRST_NAME),
+		wantQuery:   "CREATE INDEX actor_lower_name_idx ON actor USING btree ((lower(last_name))) INCLUDE (first_name)",
+	}, {
+		description: "mysql",
+		dialect:     DialectMySQL,
+		item:        CreateIndex("actor_name_idx").On(ACTOR, ACTOR.LAST_NAME).Using("BTREE"),
+		wantQuery:   "CREATE INDEX actor_name_idx ON actor (last_name) USING BTREE",
+	}, {
+		description: "sqlserver filtered index",
+		dialect:     DialectSQLServer,
+		item: CreateIndex("actor_name_idx").On(ACTOR, ACTOR.LAST_NAME).Include(ACTOR.FIRST_NAME, ACTOR.LAST_UPDATE).
+			Where(ACTOR.FIRST_NAME.IsNotNull(), ACTOR.LAST_NAME.IsNotNull()),
+		wantQuery: "CREATE INDEX actor_name_idx ON actor (last_name) INCLUDE (first_name, last_update)" +
+			" WHERE first_name IS NOT NULL AND last_name IS NOT NULL",
+	}, {
+		description: "drop index",
+		dialect:     DialectPostgres,
+		item:        DropIndex("actor_name_idx").IfExists(),
+		wantQuery:   "DROP INDEX IF EXISTS actor_name_idx",
+	}, {
+		description: "mysql drop index",
+		dialect:     DialectMySQL,
+		item:        DropIndex("actor_name_idx").On(ACTOR),
+		wantQuery:   "DROP INDEX actor_name_idx ON actor",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			gotQuery, _, err := ToSQL(tt.dialect, tt.item, nil)
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(gotQuery, tt.wantQuery); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "mysql IF NOT EXISTS",
+		dialect:     DialectMySQL,
+		item:        CreateIndex("actor_name_idx").IfNotExists().On(ACTOR, ACTOR.LAST_NAME),
+	}, {
+		description: "sqlite USING",
+		dialect:     DialectSQLite,
+		item:        CreateIndex("actor_name_idx").On(ACTOR, ACTOR.LAST_NAME).Using("gin"),
+	}, {
+		description: "mysql partial index",
+		dialect:     DialectMySQL,
+		item:        CreateIndex("actor_name_idx").On(ACTOR, ACTOR.LAST_NAME).Where(ACTOR.LAST_NAME.IsNotNull()),
+	}, {
+		description: "args",
+		dialect:     DialectPostgres,
+		item:        CreateIndex("actor_name_idx").On(ACTOR, ACTOR.LAST_NAME).Where(ACTOR.LAST_NAME.NeString("")),
+	}, {
+		description: "no fields",
+		dialect:     DialectPostgres,
+		item:        CreateIndex("actor_name_idx").On(ACTOR),
+	}, {
+		description: "sqlserver drop index without table",
+		dialect:     DialectSQLServer,
+		item:        DropIndex("actor_name_idx"),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := ToSQL(tt.dialect, tt.item, nil)
+			if err == nil {
+				t.Fatal(testutil.Callers(), "expected error but got nil")
+			}
+		})
+	}
+
+	t.Run("sqlite", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := Exec(db, CreateIndex("actor_name_idx").On(ACTOR, ACTOR.LAST_NAME, Expr("lower({})", ACTOR.FIRST_NAME)).Unique().
+			Where(ACTOR.ACTOR_ID.Gt(Literal(0))).SetDialect(DialectSQLite))
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = db.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'Ed', 'CHASE')")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = db.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (2, 'ED', 'CHASE')")
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected unique constraint error but got nil")
+		}
+		_, err = Exec(db, DropIndex("actor_name_idx").IfExists().SetDialect(DialectSQLite))
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = db.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (2, 'ED', 'CHASE')")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	})
+}
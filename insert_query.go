@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 )
 
 // InsertQuery represents an SQL INSERT query.
@@ -31,6 +32,7 @@ var _ Query = (*InsertQuery)(nil)
 
 // WriteSQL implements the SQLWriter interface.
 func (q InsertQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) (err error) {
+	ctx = withWriteState(ctx)
 	if q.ColumnMapper != nil {
 		col := &Column{
 			dialect:  q.Dialect,
@@ -55,9 +57,15 @@ func (q InsertQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 	}
 	// INSERT INTO
 	if q.InsertIgnore {
-		if dialect != DialectMySQL {
+		switch dialect {
+		case DialectMySQL:
+			buf.WriteString("INSERT IGNORE INTO ")
+		case DialectSQLite:
+			buf.WriteString("INSERT OR IGNORE INTO ")
+		default:
 			return fmt.Errorf("%s does not support INSERT IGNORE", dialect)
 		}
+	} else if q.Conflict.DoNothing && dialect == DialectMySQL {
 		buf.WriteString("INSERT IGNORE INTO ")
 	} else {
 		buf.WriteString("INSERT INTO ")
@@ -73,7 +81,11 @@ func (q InsertQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 		if dialect == DialectMySQL || dialect == DialectSQLServer {
 			return fmt.Errorf("%s does not allow an alias for the INSERT table", dialect)
 		}
-		buf.WriteString(" AS " + QuoteIdentifier(dialect, alias))
+		quotedAlias, err := quoteAlias(ctx, dialect, alias)
+		if err != nil {
+			return fmt.Errorf("INSERT INTO: %w", err)
+		}
+		buf.WriteString(" AS " + quotedAlias)
 	}
 	// Columns
 	if len(q.InsertColumns) > 0 {
@@ -96,10 +108,18 @@ func (q InsertQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 				return err
 			}
 			if alias := getAlias(field); alias != "" {
-				buf.WriteString(" AS " + QuoteIdentifier(dialect, alias))
+				quotedAlias, err := quoteAlias(ctx, dialect, alias)
+				if err != nil {
+					return err
+				}
+				buf.WriteString(" AS " + quotedAlias)
 			}
 		}
 	}
+	// SELECT ... WHERE NOT EXISTS
+	if q.Conflict.DoNothing && dialect == DialectSQLServer {
+		return q.writeSQLServerInsertNotExists(ctx, dialect, buf, args, params)
+	}
 	// VALUES
 	if len(q.RowValues) > 0 {
 		buf.WriteString(" VALUES ")
@@ -114,8 +134,17 @@ func (q InsertQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 			buf.WriteString(" AS " + q.RowAlias)
 		}
 	} else if q.SelectQuery != nil { // SELECT
+		if len(q.InsertColumns) > 0 {
+			if count, ok := selectColumnCount(q.SelectQuery); ok && count != len(q.InsertColumns) {
+				return fmt.Errorf("INSERT has %d columns but SELECT returns %d columns", len(q.InsertColumns), count)
+			}
+		}
+		selectQuery := q.SelectQuery
+		if dialect == DialectSQLite && !q.Conflict.isEmpty() {
+			selectQuery = sqliteUpsertSelect(selectQuery)
+		}
 		buf.WriteString(" ")
-		err = q.SelectQuery.WriteSQL(ctx, dialect, buf, args, params)
+		err = selectQuery.WriteSQL(ctx, dialect, buf, args, params)
 		if err != nil {
 			return fmt.Errorf("SELECT: %w", err)
 		}
@@ -164,12 +193,220 @@ func (q InsertQuery) ColumnValues(colmapper func(*Column)) InsertQuery {
 	return q
 }
 
-// Select sets the SelectQuery field of the InsertQuery.
+// InsertRows creates a new InsertQuery that inserts one row for every item.
+// The colmapper is called once per item and must set the same columns for
+// every item, otherwise the InsertQuery fails to build. The columns may be
+// set in a different order for each item: the row values always follow the
+// column order of the first item.
+func InsertRows[T any](table Table, items []T, colmapper func(col *Column, item T)) InsertQuery {
+	return InsertQuery{
+		InsertTable: table,
+		ColumnMapper: func(col *Column) {
+			insertRows(col, items, colmapper)
+		},
+	}
+}
+
+func insertRows[T any](col *Column, items []T, colmapper func(*Column, T)) {
+	if colmapper == nil {
+		panic(fmt.Errorf("InsertRows: colmapper is nil"))
+	}
+	if len(items) == 0 {
+		panic(fmt.Errorf("InsertRows: no items to insert"))
+	}
+	var columnNames []string
+	columnIndex := make(map[string]int)
+	for i, item := range items {
+		itemCol := &Column{dialect: col.dialect}
+		colmapper(itemCol, item)
+		if len(itemCol.rowValues) == 0 {
+			panic(fmt.Errorf("InsertRows: item #%d did not set any columns", i+1))
+		}
+		if len(itemCol.rowValues) > 1 {
+			panic(fmt.Errorf("InsertRows: item #%d set column %s more than once", i+1, itemCol.firstField))
+		}
+		names := make([]string, len(itemCol.insertColumns))
+		seen := make(map[string]bool)
+		for j, field := range itemCol.insertColumns {
+			names[j] = toString(col.dialect, field)
+			if seen[names[j]] {
+				panic(fmt.Errorf("InsertRows: item #%d set column %s more than once", i+1, names[j]))
+			}
+			seen[names[j]] = true
+		}
+		if i == 0 {
+			columnNames = names
+			for j, name := range names {
+				columnIndex[name] = j
+			}
+			col.insertColumns = itemCol.insertColumns
+			col.rowValues = append(col.rowValues, itemCol.rowValues[0])
+			continue
+		}
+		rowValue := make(RowValue, len(columnNames))
+		for j, name := range names {
+			index, ok := columnIndex[name]
+			if !ok || len(names) != len(columnNames) {
+				panic(fmt.Errorf("InsertRows: item #%d sets columns (%s) but item #1 sets columns (%s)", i+1, strings.Join(names, ", "), strings.Join(columnNames, ", ")))
+			}
+			rowValue[index] = itemCol.rowValues[0][j]
+		}
+		col.rowValues = append(col.rowValues, rowValue)
+	}
+}
+
+// Select sets the SelectQuery field of the InsertQuery. If the InsertQuery has
+// columns, the number of columns returned by the SelectQuery must match.
 func (q InsertQuery) Select(query Query) InsertQuery {
 	q.SelectQuery = query
 	return q
 }
 
+// OnConflictDoNothing makes the InsertQuery skip rows that conflict with an
+// existing row instead of failing, for idempotent inserts. The conflictFields
+// are the columns of the unique constraint that identifies a duplicate row.
+//
+//	postgres, sqlite: ON CONFLICT (conflictFields...) DO NOTHING
+//	mysql:            INSERT IGNORE (conflictFields are not used)
+//	sqlserver:        INSERT INTO ... SELECT ... WHERE NOT EXISTS (...)
+//
+// sqlserver has no equivalent, so the InsertQuery is rewritten to only
+// insert the rows whose conflictFields do not match an existing row. The
+// conflictFields are required for sqlserver and must be part of the
+// InsertQuery's columns.
+func (q InsertQuery) OnConflictDoNothing(conflictFields ...Field) InsertQuery {
+	q.Conflict.Fields = conflictFields
+	q.Conflict.DoNothing = true
+	return q
+}
+
+// writeSQLServerInsertNotExists writes the rows of the InsertQuery as a
+// SELECT from a derived table that excludes the rows that already exist in
+// the InsertTable, which emulates ON CONFLICT DO NOTHING for sqlserver.
+//
+//	SELECT v.a, v.b FROM (VALUES ...) AS v (a, b)
+//	WHERE NOT EXISTS (SELECT 1 FROM tbl AS t WITH (UPDLOCK, HOLDLOCK) WHERE t.a = v.a)
+func (q InsertQuery) writeSQLServerInsertNotExists(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	var err error
+	if len(q.Conflict.Fields) == 0 {
+		return fmt.Errorf("sqlserver ON CONFLICT DO NOTHING requires conflict fields")
+	}
+	if len(q.InsertColumns) == 0 {
+		return fmt.Errorf("sqlserver ON CONFLICT DO NOTHING requires the INSERT columns to be specified")
+	}
+	columnNames := make([]string, len(q.InsertColumns))
+	isColumnName := make(map[string]bool)
+	for i, column := range q.InsertColumns {
+		if column == nil {
+			return fmt.Errorf("INSERT INTO: field #%d is nil", i+1)
+		}
+		columnNames[i] = toString(dialect, withPrefix(column, ""))
+		isColumnName[columnNames[i]] = true
+	}
+	conflictNames := make([]string, len(q.Conflict.Fields))
+	for i, field := range q.Conflict.Fields {
+		if field == nil {
+			return fmt.Errorf("ON CONFLICT: field #%d is nil", i+1)
+		}
+		conflictNames[i] = toString(dialect, withPrefix(field, ""))
+		if !isColumnName[conflictNames[i]] {
+			return fmt.Errorf("ON CONFLICT: field %s is not one of the INSERT columns", conflictNames[i])
+		}
+	}
+	buf.WriteString(" SELECT ")
+	for i, name := range columnNames {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("v." + name)
+	}
+	buf.WriteString(" FROM (")
+	if len(q.RowValues) > 0 {
+		buf.WriteString("VALUES ")
+		err = RowValues(q.RowValues).WriteSQL(ctx, dialect, buf, args, params)
+		if err != nil {
+			return fmt.Errorf("VALUES: %w", err)
+		}
+	} else if q.SelectQuery != nil {
+		if count, ok := selectColumnCount(q.SelectQuery); ok && count != len(q.InsertColumns) {
+			return fmt.Errorf("INSERT has %d columns but SELECT returns %d columns", len(q.InsertColumns), count)
+		}
+		err = q.SelectQuery.WriteSQL(ctx, dialect, buf, args, params)
+		if err != nil {
+			return fmt.Errorf("SELECT: %w", err)
+		}
+	} else {
+		return fmt.Errorf("InsertQuery missing RowValues and SelectQuery (either one is required)")
+	}
+	buf.WriteString(") AS v (" + strings.Join(columnNames, ", ") + ") WHERE NOT EXISTS (SELECT 1 FROM ")
+	err = q.InsertTable.WriteSQL(ctx, dialect, buf, args, params)
+	if err != nil {
+		return fmt.Errorf("NOT EXISTS: %w", err)
+	}
+	buf.WriteString(" AS t WITH (UPDLOCK, HOLDLOCK) WHERE ")
+	for i, name := range conflictNames {
+		if i > 0 {
+			buf.WriteString(" AND ")
+		}
+		buf.WriteString("t." + name + " = v." + name)
+	}
+	buf.WriteString(")")
+	return nil
+}
+
+// selectColumnCount returns the number of columns returned by a SELECT query.
+// If the number of columns cannot be determined (e.g. the query is a raw
+// query, it selects * or it selects an Expression that may expand into more
+// than one column like Expr("a, b")), ok is false.
+func selectColumnCount(query Query) (count int, ok bool) {
+	var fields []Field
+	switch q := query.(type) {
+	case SelectQuery:
+		fields = q.SelectFields
+	case SQLiteSelectQuery:
+		fields = q.SelectFields
+	case PostgresSelectQuery:
+		fields = q.SelectFields
+	case MySQLSelectQuery:
+		fields = q.SelectFields
+	case SQLServerSelectQuery:
+		fields = q.SelectFields
+	case VariadicQuery:
+		if len(q.Queries) == 0 {
+			return 0, false
+		}
+		return selectColumnCount(q.Queries[0])
+	default:
+		return 0, false
+	}
+	if len(fields) == 0 {
+		return 0, false
+	}
+	for _, field := range fields {
+		if !isSingleColumnField(field) {
+			return 0, false
+		}
+		count++
+	}
+	return count, true
+}
+
+// isSingleColumnField reports whether a field is known to be rendered as
+// exactly one column in a select list.
+func isSingleColumnField(field Field) bool {
+	switch field.(type) {
+	case AnyField, ArrayField, BinaryField, BitField, BooleanField, EnumField,
+		JSONField, NumberField, StringField, TimeField, UUIDField, XMLField,
+		Identifier, AggregateExpression, ValueExpression, LiteralValue,
+		CaseExpression, SimpleCaseExpression, CastExpression, VariadicPredicate,
+		SelectQuery, SQLiteSelectQuery, PostgresSelectQuery, MySQLSelectQuery,
+		SQLServerSelectQuery:
+		return true
+	default:
+		return false
+	}
+}
+
 // ConflictClause represents an SQL conflict clause e.g. ON CONFLICT DO
 // NOTHING/DO UPDATE or ON DUPLICATE KEY UPDATE.
 type ConflictClause struct {
@@ -181,10 +418,33 @@ type ConflictClause struct {
 	ResolutionPredicate Predicate
 }
 
+func (c ConflictClause) isEmpty() bool {
+	return c.ConstraintName == "" && len(c.Fields) == 0 && len(c.Resolution) == 0 && !c.DoNothing
+}
+
+// sqliteUpsertSelect adds WHERE true to a SELECT without a WHERE clause.
+// SQLite requires it for INSERT ... SELECT ... ON CONFLICT, otherwise the ON
+// of ON CONFLICT is parsed as the join constraint of the SELECT.
+func sqliteUpsertSelect(query Query) Query {
+	switch q := query.(type) {
+	case SelectQuery:
+		if q.WherePredicate == nil {
+			q.WherePredicate = Expr("true")
+		}
+		return q
+	case SQLiteSelectQuery:
+		if q.WherePredicate == nil {
+			q.WherePredicate = Expr("true")
+		}
+		return q
+	}
+	return query
+}
+
 // WriteSQL implements the SQLWriter interface.
 func (c ConflictClause) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
 	var err error
-	if c.ConstraintName == "" && len(c.Fields) == 0 && len(c.Resolution) == 0 && !c.DoNothing {
+	if c.isEmpty() {
 		return nil
 	}
 	if dialect != DialectSQLite && dialect != DialectPostgres && dialect != DialectMySQL {
@@ -200,6 +460,18 @@ func (c ConflictClause) WriteSQL(ctx context.Context, dialect string, buf *bytes
 		}
 		return nil
 	}
+	if c.ConstraintName != "" && dialect == DialectSQLite {
+		return fmt.Errorf("sqlite does not support ON CONFLICT ON CONSTRAINT, use the conflict target fields instead")
+	}
+	if c.Predicate != nil && len(c.Fields) == 0 {
+		if c.ConstraintName != "" {
+			return fmt.Errorf("ON CONFLICT ON CONSTRAINT does not support a WHERE clause, use the conflict target fields of the partial index instead")
+		}
+		return fmt.Errorf("ON CONFLICT ... WHERE requires conflict target fields")
+	}
+	if dialect == DialectPostgres && c.ConstraintName == "" && len(c.Fields) == 0 && len(c.Resolution) > 0 && !c.DoNothing {
+		return fmt.Errorf("postgres ON CONFLICT DO UPDATE requires conflict target fields or a constraint name")
+	}
 	buf.WriteString(" ON CONFLICT")
 	if c.ConstraintName != "" {
 		buf.WriteString(" ON CONSTRAINT " + QuoteIdentifier(dialect, c.ConstraintName))
@@ -258,7 +530,7 @@ func (c ConflictClause) WriteSQL(ctx context.Context, dialect string, buf *bytes
 // SetFetchableFields implements the Query interface.
 func (q InsertQuery) SetFetchableFields(fields []Field) (query Query, ok bool) {
 	switch q.Dialect {
-	case DialectPostgres, DialectSQLite:
+	case DialectPostgres, DialectSQLite, DialectSQLServer:
 		if len(q.ReturningFields) == 0 {
 			q.ReturningFields = fields
 			return q, true
@@ -272,7 +544,7 @@ func (q InsertQuery) SetFetchableFields(fields []Field) (query Query, ok bool) {
 // GetFetchableFields returns the fetchable fields of the query.
 func (q InsertQuery) GetFetchableFields() []Field {
 	switch q.Dialect {
-	case DialectPostgres, DialectSQLite:
+	case DialectPostgres, DialectSQLite, DialectSQLServer:
 		return q.ReturningFields
 	default:
 		return nil
@@ -307,6 +579,17 @@ func (b sqliteQueryBuilder) InsertInto(table Table) SQLiteInsertQuery {
 	}
 }
 
+// InsertOrIgnoreInto creates a new SQLiteInsertQuery that ignores rows that
+// fail a constraint instead of failing the whole INSERT.
+func (b sqliteQueryBuilder) InsertOrIgnoreInto(table Table) SQLiteInsertQuery {
+	return SQLiteInsertQuery{
+		Dialect:      DialectSQLite,
+		CTEs:         b.ctes,
+		InsertTable:  table,
+		InsertIgnore: true,
+	}
+}
+
 // Columns sets the InsertColumns field of the SQLiteInsertQuery.
 func (q SQLiteInsertQuery) Columns(fields ...Field) SQLiteInsertQuery {
 	q.InsertColumns = fields
@@ -440,13 +723,18 @@ func (q PostgresInsertQuery) OnConflict(fields ...Field) postgresInsertConflict
 	return postgresInsertConflict{q: &q}
 }
 
-// OnConflict starts the ON CONFLICT clause of the PostgresInsertQuery.
+// OnConflictOnConstraint starts the ON CONFLICT ON CONSTRAINT clause of the
+// PostgresInsertQuery, which uses a named unique or exclusion constraint as
+// the conflict target. It can't be combined with Where, to target a partial
+// unique index use OnConflict with the index's fields and predicate instead.
 func (q PostgresInsertQuery) OnConflictOnConstraint(constraintName string) postgresInsertConflict {
 	q.Conflict.ConstraintName = constraintName
 	return postgresInsertConflict{q: &q}
 }
 
 // Where adds predicates to the ON CONFLICT clause of the PostgresInsertQuery.
+// The predicates must match the predicate of a partial unique index on the
+// conflict target fields, for Postgres to use that index as the arbiter.
 func (c postgresInsertConflict) Where(predicates ...Predicate) postgresInsertConflict {
 	c.q.Conflict.Predicate = appendPredicates(c.q.Conflict.Predicate, predicates)
 	return c
@@ -630,6 +918,14 @@ func (q SQLServerInsertQuery) Select(query Query) SQLServerInsertQuery {
 	return q
 }
 
+// OnConflictDoNothing makes the SQLServerInsertQuery skip rows whose
+// conflictFields match an existing row instead of failing. It is emulated
+// with INSERT INTO ... SELECT ... WHERE NOT EXISTS (...), see
+// InsertQuery.OnConflictDoNothing.
+func (q SQLServerInsertQuery) OnConflictDoNothing(conflictFields ...Field) SQLServerInsertQuery {
+	return SQLServerInsertQuery(InsertQuery(q).OnConflictDoNothing(conflictFields...))
+}
+
 // SetFetchableFields implements the Query interface.
 func (q SQLServerInsertQuery) SetFetchableFields(fields []Field) (query Query, ok bool) {
 	return InsertQuery(q).SetFetchableFields(fields)
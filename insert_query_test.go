@@ -137,6 +137,32 @@ func TestSQLiteInsertQuery(t *testing.T) {
 		tt.wantArgs = []any{"bob", "the builder", "alice", "in wonderland"}
 		tt.assert(t)
 	})
+
+	t.Run("Select OnConflict", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = SQLite.
+			InsertInto(a).
+			Columns(a.FIRST_NAME, a.LAST_NAME).
+			Select(SQLite.Select(a.FIRST_NAME, a.LAST_NAME).From(a)).
+			OnConflict().DoNothing()
+		tt.wantQuery = "INSERT INTO actor AS a (first_name, last_name)" +
+			" SELECT a.first_name, a.last_name FROM actor AS a WHERE true" +
+			" ON CONFLICT DO NOTHING"
+		tt.assert(t)
+	})
+
+	t.Run("InsertOrIgnoreInto", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = SQLite.
+			InsertOrIgnoreInto(a).
+			Columns(a.FIRST_NAME, a.LAST_NAME).
+			Values("bob", "the builder")
+		tt.wantQuery = "INSERT OR IGNORE INTO actor AS a (first_name, last_name) VALUES ($1, $2)"
+		tt.wantArgs = []any{"bob", "the builder"}
+		tt.assert(t)
+	})
 }
 
 func TestPostgresInsertQuery(t *testing.T) {
@@ -422,10 +448,13 @@ func TestSQLServerInsertQuery(t *testing.T) {
 			t.Error(testutil.Callers(), "expected 0 fields but got %v", fields)
 		}
 		_, ok := q1.SetFetchableFields([]Field{a.LAST_NAME})
-		if ok {
-			t.Fatal(testutil.Callers(), "field should not have been set")
+		if !ok {
+			t.Fatal(testutil.Callers(), "field should have been set")
+		}
+		q1.ReturningFields = []Field{a.FIRST_NAME}
+		if diff := testutil.Diff(q1.GetFetchableFields(), []Field{a.FIRST_NAME}); diff != "" {
+			t.Error(testutil.Callers(), diff)
 		}
-		q1.ReturningFields = q1.ReturningFields[:0]
 		_, ok = q1.SetFetchableFields([]Field{a.LAST_NAME})
 		if ok {
 			t.Fatal(testutil.Callers(), "field should not have been set")
@@ -482,6 +511,39 @@ func TestSQLServerInsertQuery(t *testing.T) {
 			" SELECT actor.first_name, actor.last_name FROM actor"
 		tt.assert(t)
 	})
+
+	t.Run("OnConflictDoNothing", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = SQLServer.
+			InsertInto(a).
+			Columns(a.ACTOR_ID, a.FIRST_NAME, a.LAST_NAME).
+			Values(1, "bob", "the builder").
+			Values(2, "alice", "in wonderland").
+			OnConflictDoNothing(a.ACTOR_ID)
+		tt.wantQuery = "INSERT INTO actor (actor_id, first_name, last_name)" +
+			" SELECT v.actor_id, v.first_name, v.last_name" +
+			" FROM (VALUES (@p1, @p2, @p3), (@p4, @p5, @p6)) AS v (actor_id, first_name, last_name)" +
+			" WHERE NOT EXISTS (SELECT 1 FROM actor AS t WITH (UPDLOCK, HOLDLOCK) WHERE t.actor_id = v.actor_id)"
+		tt.wantArgs = []any{1, "bob", "the builder", 2, "alice", "in wonderland"}
+		tt.assert(t)
+	})
+
+	t.Run("OnConflictDoNothing Select", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = SQLServer.
+			InsertInto(a).
+			Columns(a.FIRST_NAME, a.LAST_NAME).
+			Select(SQLServer.Select(a.FIRST_NAME, a.LAST_NAME).From(a)).
+			OnConflictDoNothing(a.FIRST_NAME, a.LAST_NAME)
+		tt.wantQuery = "INSERT INTO actor (first_name, last_name)" +
+			" SELECT v.first_name, v.last_name" +
+			" FROM (SELECT actor.first_name, actor.last_name FROM actor) AS v (first_name, last_name)" +
+			" WHERE NOT EXISTS (SELECT 1 FROM actor AS t WITH (UPDLOCK, HOLDLOCK)" +
+			" WHERE t.first_name = v.first_name AND t.last_name = v.last_name)"
+		tt.assert(t)
+	})
 }
 
 func TestInsertQuery(t *testing.T) {
@@ -563,6 +625,71 @@ func TestInsertQuery(t *testing.T) {
 			ColumnMapper: colmapper,
 			Conflict:     ConflictClause{Fields: Fields{nil}},
 		},
+	}, {
+		description: "sqlite does not support ON CONFLICT ON CONSTRAINT",
+		item: InsertQuery{
+			Dialect:      DialectSQLite,
+			InsertTable:  Expr("tbl"),
+			ColumnMapper: colmapper,
+			Conflict:     ConflictClause{ConstraintName: "tbl_pkey", DoNothing: true},
+		},
+	}, {
+		description: "ON CONFLICT ON CONSTRAINT does not support WHERE",
+		item: InsertQuery{
+			Dialect:      DialectPostgres,
+			InsertTable:  Expr("tbl"),
+			ColumnMapper: colmapper,
+			Conflict:     ConflictClause{ConstraintName: "tbl_pkey", Predicate: Expr("f1 IS NOT NULL"), DoNothing: true},
+		},
+	}, {
+		description: "ON CONFLICT WHERE requires conflict fields",
+		item: InsertQuery{
+			Dialect:      DialectPostgres,
+			InsertTable:  Expr("tbl"),
+			ColumnMapper: colmapper,
+			Conflict:     ConflictClause{Predicate: Expr("f1 IS NOT NULL"), DoNothing: true},
+		},
+	}, {
+		description: "postgres ON CONFLICT DO UPDATE requires a conflict target",
+		item: InsertQuery{
+			Dialect:      DialectPostgres,
+			InsertTable:  Expr("tbl"),
+			ColumnMapper: colmapper,
+			Conflict:     ConflictClause{Resolution: Assignments{Set(Expr("f1"), 1)}},
+		},
+	}, {
+		description: "sqlserver ON CONFLICT DO NOTHING requires conflict fields",
+		item: InsertQuery{
+			Dialect:      DialectSQLServer,
+			InsertTable:  Expr("tbl"),
+			ColumnMapper: colmapper,
+			Conflict:     ConflictClause{DoNothing: true},
+		},
+	}, {
+		description: "sqlserver ON CONFLICT DO NOTHING conflict field must be an INSERT column",
+		item: InsertQuery{
+			Dialect:      DialectSQLServer,
+			InsertTable:  Expr("tbl"),
+			ColumnMapper: colmapper,
+			Conflict:     ConflictClause{Fields: Fields{Expr("f4")}, DoNothing: true},
+		},
+	}, {
+		description: "INSERT columns and SELECT columns count mismatch",
+		item: InsertQuery{
+			InsertTable:   Expr("tbl"),
+			InsertColumns: Fields{f1, f2, f3},
+			SelectQuery:   Select(NewAnyField("f1", TableStruct{}), NewAnyField("f2", TableStruct{})).From(Expr("tbl2")),
+		},
+	}, {
+		description: "INSERT columns and UNION columns count mismatch",
+		item: InsertQuery{
+			InsertTable:   Expr("tbl"),
+			InsertColumns: Fields{f1, f2},
+			SelectQuery: Union(
+				Select(NewAnyField("f1", TableStruct{}), NewAnyField("f2", TableStruct{}), NewAnyField("f3", TableStruct{})).From(Expr("tbl2")),
+				Select(NewAnyField("f1", TableStruct{}), NewAnyField("f2", TableStruct{}), NewAnyField("f3", TableStruct{})).From(Expr("tbl3")),
+			),
+		},
 	}}
 
 	for _, tt := range notOKTests {
@@ -573,6 +700,104 @@ func TestInsertQuery(t *testing.T) {
 		})
 	}
 
+	t.Run("OnConflictDoNothing", func(t *testing.T) {
+		t.Parallel()
+		q := InsertInto(Expr("tbl")).Columns(f1, f2).Values(1, 2).OnConflictDoNothing(f1)
+		tests := []TestTable{{
+			dialect:   DialectPostgres,
+			wantQuery: "INSERT INTO tbl (f1, f2) VALUES ($1, $2) ON CONFLICT (f1) DO NOTHING",
+		}, {
+			dialect:   DialectSQLite,
+			wantQuery: "INSERT INTO tbl (f1, f2) VALUES ($1, $2) ON CONFLICT (f1) DO NOTHING",
+		}, {
+			dialect:   DialectMySQL,
+			wantQuery: "INSERT IGNORE INTO tbl (f1, f2) VALUES (?, ?)",
+		}, {
+			dialect: DialectSQLServer,
+			wantQuery: "INSERT INTO tbl (f1, f2) SELECT v.f1, v.f2 FROM (VALUES (@p1, @p2)) AS v (f1, f2)" +
+				" WHERE NOT EXISTS (SELECT 1 FROM tbl AS t WITH (UPDLOCK, HOLDLOCK) WHERE t.f1 = v.f1)",
+		}}
+		for _, tt := range tests {
+			tt.item = q.SetDialect(tt.dialect)
+			tt.wantArgs = []any{1, 2}
+			tt.assert(t)
+		}
+	})
+
+	t.Run("InsertRows", func(t *testing.T) {
+		t.Parallel()
+		type item struct {
+			a, b int
+		}
+		var tt TestTable
+		tt.item = InsertRows(Expr("tbl"), []item{{1, 2}, {3, 4}}, func(col *Column, item item) {
+			if item.a == 1 {
+				col.Set(f1, item.a)
+				col.Set(f2, item.b)
+			} else {
+				col.Set(f2, item.b)
+				col.Set(f1, item.a)
+			}
+		}).SetDialect(DialectPostgres)
+		tt.wantQuery = "INSERT INTO tbl (f1, f2) VALUES ($1, $2), ($3, $4)"
+		tt.wantArgs = []any{1, 2, 3, 4}
+		tt.assert(t)
+	})
+
+	t.Run("InsertRows errors", func(t *testing.T) {
+		t.Parallel()
+		items := []int{1, 2}
+		tests := []TestTable{{
+			description: "different columns",
+			item: InsertRows(Expr("tbl"), items, func(col *Column, item int) {
+				col.Set(f1, item)
+				if item == 2 {
+					col.Set(f2, item)
+				}
+			}),
+		}, {
+			description: "column set twice",
+			item: InsertRows(Expr("tbl"), items, func(col *Column, item int) {
+				col.Set(f1, item)
+				col.Set(f2, item)
+				col.Set(f2, item)
+			}),
+		}, {
+			description: "no columns",
+			item:        InsertRows(Expr("tbl"), items, func(col *Column, item int) {}),
+		}, {
+			description: "no items",
+			item:        InsertRows(Expr("tbl"), []int{}, func(col *Column, item int) { col.Set(f1, item) }),
+		}}
+		for _, tt := range tests {
+			tt.assertNotOK(t)
+		}
+	})
+
+	t.Run("SELECT * skips column count check", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = InsertQuery{
+			InsertTable:   Expr("tbl"),
+			InsertColumns: Fields{f1, f2, f3},
+			SelectQuery:   Select(Expr("*")).From(Expr("tbl2")),
+		}
+		tt.wantQuery = "INSERT INTO tbl (f1, f2, f3) SELECT * FROM tbl2"
+		tt.assert(t)
+	})
+
+	t.Run("multi-column Expression skips column count check", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = InsertQuery{
+			InsertTable:   Expr("tbl"),
+			InsertColumns: Fields{f1, f2, f3},
+			SelectQuery:   Select(Expr("f1, f2"), f3).From(Expr("tbl2")),
+		}
+		tt.wantQuery = "INSERT INTO tbl (f1, f2, f3) SELECT f1, f2, f3 FROM tbl2"
+		tt.assert(t)
+	})
+
 	errTests := []TestTable{{
 		description: "ColumnMapper err",
 		item: InsertQuery{
@@ -100,7 +100,11 @@ func (join JoinTable) WriteSQL(ctx context.Context, dialect string, buf *bytes.B
 
 	// AS
 	if tableAlias := getAlias(join.Table); tableAlias != "" {
-		buf.WriteString(" AS " + QuoteIdentifier(dialect, tableAlias) + quoteTableColumns(dialect, join.Table))
+		quotedAlias, err := quoteAlias(ctx, dialect, tableAlias)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(" AS " + quotedAlias + quoteTableColumns(dialect, join.Table))
 	} else if isQuery && dialect != DialectSQLite {
 		return fmt.Errorf("%s %s subquery must have alias", dialect, join.JoinOperator)
 	}
@@ -0,0 +1,51 @@
+package sq
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limited wraps a DB such that at most maxConcurrent queries executed through
+// it are in flight at any one time, protecting small connection pools from
+// stampedes during traffic spikes. Queries that exceed the limit wait for a
+// free slot (or for their context to be done), the time spent waiting is
+// recorded in QueryStats.WaitTime. A fetch occupies its slot until its
+// results have been exhausted or its cursor is closed.
+//
+// Only queries executed by sq's Fetch and Exec functions are limited, calling
+// the DB's QueryContext or ExecContext methods directly bypasses the limit.
+//
+//	db = sq.Limited(db, 10)
+func Limited(db DB, maxConcurrent int) DB {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	return withOption(db, make(semaphore, maxConcurrent))
+}
+
+type semaphore chan struct{}
+
+// dbSemaphore returns the semaphore of the outermost Limited DB, or nil if the
+// DB is not wrapped by Limited.
+func dbSemaphore(db DB) semaphore {
+	sem, _ := dbOption[semaphore](db)
+	return sem
+}
+
+// acquire waits for a free slot in the semaphore. It returns a release
+// function (which is safe to call more than once) and the time spent waiting.
+// Acquiring a nil semaphore always succeeds immediately.
+func (sem semaphore) acquire(ctx context.Context) (release func(), waitTime time.Duration, err error) {
+	if sem == nil {
+		return func() {}, 0, nil
+	}
+	startedAt := time.Now()
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return func() {}, time.Since(startedAt), ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-sem }) }, time.Since(startedAt), nil
+}
@@ -0,0 +1,60 @@
+package sq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestLimited(t *testing.T) {
+	t.Parallel()
+	recorder := &queryStatsRecorder{}
+	db := Limited(struct {
+		DB
+		SqLogger
+	}{DB: newDB(t), SqLogger: recorder}, 1)
+	_, err := Exec(db, InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+
+	// The open cursor occupies the only slot.
+	cursor, err := FetchCursor(db, From(ACTOR), func(row *Row) int {
+		return row.IntField(ACTOR.ACTOR_ID)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = FetchExistsContext(ctx, db, SelectOne().From(ACTOR))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf(testutil.Callers()+" expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// Closing the cursor frees up the slot.
+	done := make(chan error)
+	go func() {
+		_, err := FetchExists(db, SelectOne().From(ACTOR))
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	err = cursor.Close()
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	err = <-done
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	last := recorder.queryStats[len(recorder.queryStats)-1]
+	if last.WaitTime < 10*time.Millisecond {
+		t.Errorf(testutil.Callers()+" expected WaitTime of at least 10ms, got %s", last.WaitTime)
+	}
+}
@@ -0,0 +1,116 @@
+package sq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LintError is the error returned by LintFetch. It lists the columns of a
+// query that the rowmapper doesn't read and the columns the rowmapper reads
+// that the query doesn't select.
+type LintError struct {
+	Dialect string
+	// Unused are the columns selected by the query but never read by the
+	// rowmapper. They are fetched for nothing.
+	Unused []string
+	// Missing are the columns read by the rowmapper but not selected by the
+	// query. Fetching the query would fail with a 'column does not exist'
+	// panic.
+	Missing []string
+}
+
+// Error implements the error interface.
+func (e *LintError) Error() string {
+	var problems []string
+	if len(e.Unused) > 0 {
+		problems = append(problems, "selected but never read: "+strings.Join(e.Unused, ", "))
+	}
+	if len(e.Missing) > 0 {
+		problems = append(problems, "read but not selected: "+strings.Join(e.Missing, ", "))
+	}
+	return strings.Join(problems, "; ")
+}
+
+// LintFetch compares the fields selected by a query (or returned by its
+// RETURNING clause) against the fields that the rowmapper reads, and returns
+// a *LintError listing the selected columns that are never read and the read
+// columns that are never selected. Columns are matched the way a static query
+// matches them: by their alias, or else by their unqualified name.
+//
+// The rowmapper is called once without any database, so it must not depend on
+// the values it reads. Queries without any fields of their own have their
+// fields filled in from the rowmapper when they are fetched, so they always
+// pass. LintFetch is meant to be called from tests.
+//
+//	func TestActorQuery(t *testing.T) {
+//	    err := sq.LintFetch(actorQuery, actorRowMapper)
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+func LintFetch[T any](query Query, rowmapper func(*Row) T) (err error) {
+	if query == nil {
+		return fmt.Errorf("query is nil")
+	}
+	fetchableQuery, ok := query.(interface{ GetFetchableFields() []Field })
+	if !ok {
+		return fmt.Errorf("%T does not have fetchable fields", query)
+	}
+	selectedFields := fetchableQuery.GetFetchableFields()
+	if len(selectedFields) == 0 {
+		return nil
+	}
+	dialect := query.GetDialect()
+	if dialect == "" {
+		defaultDialect := DefaultDialect.Load()
+		if defaultDialect != nil {
+			dialect = *defaultDialect
+		}
+	}
+	row := &Row{dialect: dialect}
+	row.initScanDest(0)
+	defer row.releaseScanDest()
+	err = func() (err error) {
+		defer mapperFunctionPanicked(&err)
+		_ = rowmapper(row)
+		return nil
+	}()
+	if err != nil {
+		return fmt.Errorf("rowmapper: %w", err)
+	}
+	selectedNames := make([]string, 0, len(selectedFields))
+	selected := make(map[string]bool)
+	var wildcard bool
+	for _, field := range selectedFields {
+		name := staticColumnName(dialect, field)
+		if name == "*" || strings.HasSuffix(name, ".*") {
+			wildcard = true
+			continue
+		}
+		if !selected[name] {
+			selected[name] = true
+			selectedNames = append(selectedNames, name)
+		}
+	}
+	read := make(map[string]bool)
+	lintErr := &LintError{Dialect: dialect}
+	for _, field := range row.fields {
+		name := staticColumnName(dialect, field)
+		if read[name] {
+			continue
+		}
+		read[name] = true
+		if !selected[name] && !wildcard {
+			lintErr.Missing = append(lintErr.Missing, name)
+		}
+	}
+	for _, name := range selectedNames {
+		if !read[name] {
+			lintErr.Unused = append(lintErr.Unused, name)
+		}
+	}
+	if len(lintErr.Unused) > 0 || len(lintErr.Missing) > 0 {
+		return lintErr
+	}
+	return nil
+}
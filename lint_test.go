@@ -0,0 +1,102 @@
+package sq
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestLintFetch(t *testing.T) {
+	type TT struct {
+		description string
+		query       Query
+		rowmapper   func(*Row) Actor
+		wantErr     *LintError
+	}
+
+	actorRowMapper := func(row *Row) Actor {
+		return Actor{
+			ActorID:   row.IntField(ACTOR.ACTOR_ID),
+			FirstName: row.StringField(ACTOR.FIRST_NAME),
+		}
+	}
+
+	tests := []TT{{
+		description: "exact",
+		query:       SQLite.Select(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME).From(ACTOR),
+		rowmapper:   actorRowMapper,
+	}, {
+		description: "dynamic",
+		query:       SQLite.From(ACTOR),
+		rowmapper:   actorRowMapper,
+	}, {
+		description: "unused and missing",
+		query:       SQLite.Select(ACTOR.ACTOR_ID, ACTOR.LAST_NAME, ACTOR.LAST_UPDATE).From(ACTOR),
+		rowmapper:   actorRowMapper,
+		wantErr: &LintError{
+			Dialect: DialectSQLite,
+			Unused:  []string{"last_name", "last_update"},
+			Missing: []string{"first_name"},
+		},
+	}, {
+		description: "aliases and expressions",
+		query: SQLite.
+			Select(ACTOR.ACTOR_ID.As("id"), Expr("COUNT(*)"), Expr("MAX({})", ACTOR.LAST_UPDATE).As("latest")).
+			From(ACTOR).
+			GroupBy(ACTOR.ACTOR_ID),
+		rowmapper: func(row *Row) Actor {
+			return Actor{
+				ActorID:   row.Int("id"),
+				FirstName: row.String("COUNT(*)"),
+			}
+		},
+		wantErr: &LintError{
+			Dialect: DialectSQLite,
+			Unused:  []string{"latest"},
+		},
+	}, {
+		description: "wildcard",
+		query:       SQLite.Select(Expr("*")).From(ACTOR),
+		rowmapper:   actorRowMapper,
+	}, {
+		description: "returning",
+		query:       SQLite.DeleteFrom(ACTOR).Returning(ACTOR.ACTOR_ID),
+		rowmapper:   actorRowMapper,
+		wantErr: &LintError{
+			Dialect: DialectSQLite,
+			Missing: []string{"first_name"},
+		},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			err := LintFetch(tt.query, tt.rowmapper)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatal(testutil.Callers(), err)
+				}
+				return
+			}
+			var lintErr *LintError
+			if !errors.As(err, &lintErr) {
+				t.Fatalf(testutil.Callers()+" expected *LintError, got %v", err)
+			}
+			if diff := testutil.Diff(lintErr, tt.wantErr); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+
+	t.Run("rowmapper panics", func(t *testing.T) {
+		t.Parallel()
+		err := LintFetch(SQLite.Select(ACTOR.ACTOR_ID).From(ACTOR), func(row *Row) int {
+			return row.IntAt(0)
+		})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
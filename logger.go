@@ -2,15 +2,19 @@ package sq
 
 import (
 	"bytes"
+	"container/list"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -62,6 +66,116 @@ type QueryStats struct {
 
 	// The results from running the query (if it was provided).
 	Results string
+
+	// Attempts is the number of times the query was attempted. It is only
+	// greater than 1 if the query was retried by a DB wrapped with WithRetry.
+	Attempts int
+
+	// WaitTime is the time the query spent waiting for a free slot of a DB
+	// wrapped with Limited.
+	WaitTime time.Duration
+
+	// QueryLength is the length of the query string in bytes.
+	QueryLength int
+
+	// ArgCount is the number of arguments passed in with the query.
+	ArgCount int
+
+	// TenantID, UserID and RequestID are the IDs stored in the context of
+	// the query by WithTenantID, WithUserID and WithRequestID.
+	TenantID  string
+	UserID    string
+	RequestID string
+
+	// ErrorKind classifies Err, separating queries given up on by the client
+	// (ErrorKindCanceled) from queries that ran out of time
+	// (ErrorKindTimeout) and other errors (ErrorKindDriver). It is empty if
+	// Err is nil.
+	ErrorKind QueryErrorKind
+
+	// ServerCanceled reports whether the database itself canceled the
+	// statement (e.g. Postgres' statement_timeout or a cancel request sent by
+	// the driver when the context was canceled), rather than the query
+	// failing before reaching the database or the client abandoning it
+	// midway.
+	ServerCanceled bool
+}
+
+// QueryErrorKind is the kind of error a query failed with.
+type QueryErrorKind string
+
+// Possible values of QueryStats.ErrorKind.
+const (
+	// ErrorKindCanceled means the context of the query was canceled (or the
+	// query was canceled by the database for no other reason).
+	ErrorKindCanceled QueryErrorKind = "canceled"
+
+	// ErrorKindTimeout means the context deadline of the query was exceeded
+	// or the database timed out the query.
+	ErrorKindTimeout QueryErrorKind = "timeout"
+
+	// ErrorKindDriver means any other error e.g. a syntax error, constraint
+	// violation or connection failure.
+	ErrorKindDriver QueryErrorKind = "driver"
+)
+
+// setErrorKind sets the ErrorKind and ServerCanceled of the QueryStats from
+// its Err and the context of the query.
+func (queryStats *QueryStats) setErrorKind(ctx context.Context) {
+	queryStats.ErrorKind, queryStats.ServerCanceled = "", false
+	err := queryStats.Err
+	if err == nil {
+		return
+	}
+	var serverTimeout bool
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		code := getDriverErrorCode(e)
+		switch {
+		case code.sqlState == "57014": // postgres query_canceled
+			queryStats.ServerCanceled = true
+			serverTimeout = strings.Contains(e.Error(), "timeout")
+		case code.number == 1317: // mysql ER_QUERY_INTERRUPTED
+			queryStats.ServerCanceled = true
+		case code.number == 3024: // mysql ER_QUERY_TIMEOUT (max_execution_time)
+			queryStats.ServerCanceled, serverTimeout = true, true
+		case code.sqliteCode&0xff == 9: // SQLITE_INTERRUPT
+			queryStats.ServerCanceled = true
+		}
+	}
+	var ctxErr error
+	if ctx != nil {
+		ctxErr = ctx.Err()
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || serverTimeout:
+		queryStats.ErrorKind = ErrorKindTimeout
+	case errors.Is(err, context.Canceled):
+		queryStats.ErrorKind = ErrorKindCanceled
+	case queryStats.ServerCanceled && errors.Is(ctxErr, context.DeadlineExceeded):
+		queryStats.ErrorKind = ErrorKindTimeout
+	case queryStats.ServerCanceled:
+		queryStats.ErrorKind = ErrorKindCanceled
+	default:
+		queryStats.ErrorKind = ErrorKindDriver
+	}
+}
+
+// setQuerySize sets the QueryLength and ArgCount of the QueryStats from its
+// Query and Args.
+func (queryStats *QueryStats) setQuerySize() {
+	queryStats.QueryLength = len(queryStats.Query)
+	queryStats.ArgCount = len(queryStats.Args)
+}
+
+// setContextIDs sets the TenantID, UserID and RequestID of the QueryStats from
+// the context.
+func (queryStats *QueryStats) setContextIDs(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	queryStats.TenantID, _ = TenantID(ctx)
+	queryStats.UserID, _ = UserID(ctx)
+	queryStats.RequestID, _ = RequestID(ctx)
 }
 
 // LogSettings are the various log settings taken into account when producing
@@ -122,6 +236,31 @@ type LoggerConfig struct {
 	// Explicitly hides arguments when logging the query (only the query
 	// placeholders will be shown).
 	HideArgs bool
+
+	// If true, logged queries are formatted like SprintfPretty, with every
+	// clause on its own line and subqueries indented.
+	PrettyPrint bool
+
+	// If true, the SQL keywords and literals (which include the interpolated
+	// arguments) of logged queries are colored. It has no effect if colors
+	// are disabled with NoColor or the NO_COLOR environment variable.
+	HighlightSQL bool
+
+	// If WarnTimeTaken is greater than zero, queries that take longer than
+	// WarnTimeTaken are logged with their time taken in red, even if
+	// ShowTimeTaken is false.
+	WarnTimeTaken time.Duration
+
+	// If WarnQueryLength is greater than zero, queries longer than
+	// WarnQueryLength bytes are logged with a warning.
+	WarnQueryLength int
+
+	// If WarnArgCount is greater than zero, queries with more than
+	// WarnArgCount arguments are logged with a warning. Use it to catch
+	// queries approaching the argument limit of the database before they
+	// fail e.g. 65535 for Postgres and MySQL, 32766 for SQLite and 2100 for
+	// SQL Server.
+	WarnArgCount int
 }
 
 var _ SqLogger = (*sqLogger)(nil)
@@ -136,6 +275,7 @@ var verboseLogger = NewLogger(os.Stdout, "", log.LstdFlags, LoggerConfig{
 	ShowCaller:         true,
 	ShowResults:        5,
 	InterpolateVerbose: true,
+	HighlightSQL:       true,
 })
 
 // NewLogger returns a new SqLogger.
@@ -156,14 +296,16 @@ func (l *sqLogger) SqLogSettings(ctx context.Context, settings *LogSettings) {
 
 // SqLogQuery implements the SqLogger interface.
 func (l *sqLogger) SqLogQuery(ctx context.Context, queryStats QueryStats) {
-	var reset, red, green, blue, purple string
+	var reset, red, green, yellow, blue, purple, cyan string
 	envNoColor, _ := strconv.ParseBool(os.Getenv("NO_COLOR"))
 	if !l.config.NoColor && !envNoColor {
 		reset = colorReset
 		red = colorRed
 		green = colorGreen
+		yellow = colorYellow
 		blue = colorBlue
 		purple = colorPurple
+		cyan = colorCyan
 	}
 	buf := bufpool.Get().(*bytes.Buffer)
 	buf.Reset()
@@ -173,11 +315,25 @@ func (l *sqLogger) SqLogQuery(ctx context.Context, queryStats QueryStats) {
 	} else {
 		buf.WriteString(red + "[FAIL]" + reset)
 	}
+	// A pretty printed query starts on a new line.
+	sep := " "
+	pretty := func(query string) string {
+		if l.config.PrettyPrint {
+			query = prettySQL(query)
+		}
+		if l.config.HighlightSQL && reset != "" {
+			query = highlightSQL(query, cyan, yellow, reset)
+		}
+		return query
+	}
+	if l.config.PrettyPrint {
+		sep = "\n"
+	}
 	if l.config.HideArgs {
-		buf.WriteString(" " + queryStats.Query + ";")
+		buf.WriteString(sep + pretty(queryStats.Query) + ";")
 	} else if !l.config.InterpolateVerbose {
 		if queryStats.Err != nil {
-			buf.WriteString(" " + queryStats.Query + ";")
+			buf.WriteString(sep + pretty(queryStats.Query) + ";")
 			if len(queryStats.Args) > 0 {
 				buf.WriteString(" [")
 			}
@@ -185,7 +341,11 @@ func (l *sqLogger) SqLogQuery(ctx context.Context, queryStats QueryStats) {
 				if i > 0 {
 					buf.WriteString(", ")
 				}
-				buf.WriteString(fmt.Sprintf("%#v", queryStats.Args[i]))
+				if l.config.HighlightSQL {
+					buf.WriteString(yellow + fmt.Sprintf("%#v", queryStats.Args[i]) + reset)
+				} else {
+					buf.WriteString(fmt.Sprintf("%#v", queryStats.Args[i]))
+				}
 			}
 			if len(queryStats.Args) > 0 {
 				buf.WriteString("]")
@@ -194,8 +354,10 @@ func (l *sqLogger) SqLogQuery(ctx context.Context, queryStats QueryStats) {
 			query, err := Sprintf(queryStats.Dialect, queryStats.Query, queryStats.Args)
 			if err != nil {
 				query += " " + err.Error()
+			} else {
+				query = pretty(query)
 			}
-			buf.WriteString(" " + query + ";")
+			buf.WriteString(sep + query + ";")
 		}
 	}
 	if queryStats.Err != nil {
@@ -203,8 +365,16 @@ func (l *sqLogger) SqLogQuery(ctx context.Context, queryStats QueryStats) {
 		if i := strings.IndexByte(errStr, '\n'); i < 0 {
 			buf.WriteString(blue + " err" + reset + "={" + queryStats.Err.Error() + "}")
 		}
+		if queryStats.ErrorKind == ErrorKindCanceled || queryStats.ErrorKind == ErrorKindTimeout {
+			buf.WriteString(blue + " errorKind" + reset + "=" + string(queryStats.ErrorKind))
+		}
+		if queryStats.ServerCanceled {
+			buf.WriteString(blue + " serverCanceled" + reset + "=true")
+		}
 	}
-	if l.config.ShowTimeTaken {
+	if l.config.WarnTimeTaken > 0 && queryStats.TimeTaken > l.config.WarnTimeTaken {
+		buf.WriteString(red + " timeTaken" + reset + "=" + queryStats.TimeTaken.String() + " (exceeds " + l.config.WarnTimeTaken.String() + ")")
+	} else if l.config.ShowTimeTaken {
 		buf.WriteString(blue + " timeTaken" + reset + "=" + queryStats.TimeTaken.String())
 	}
 	if queryStats.RowCount.Valid {
@@ -219,14 +389,38 @@ func (l *sqLogger) SqLogQuery(ctx context.Context, queryStats QueryStats) {
 	if queryStats.Exists.Valid {
 		buf.WriteString(blue + " exists" + reset + "=" + strconv.FormatBool(queryStats.Exists.Bool))
 	}
+	if l.config.WarnQueryLength > 0 && queryStats.QueryLength > l.config.WarnQueryLength {
+		buf.WriteString(red + " queryLength" + reset + "=" + strconv.Itoa(queryStats.QueryLength) + " (exceeds " + strconv.Itoa(l.config.WarnQueryLength) + ")")
+	}
+	if l.config.WarnArgCount > 0 && queryStats.ArgCount > l.config.WarnArgCount {
+		buf.WriteString(red + " argCount" + reset + "=" + strconv.Itoa(queryStats.ArgCount) + " (exceeds " + strconv.Itoa(l.config.WarnArgCount) + ")")
+	}
+	if queryStats.WaitTime > 0 {
+		buf.WriteString(blue + " waitTime" + reset + "=" + queryStats.WaitTime.String())
+	}
+	if queryStats.Attempts > 1 {
+		buf.WriteString(blue + " attempts" + reset + "=" + strconv.Itoa(queryStats.Attempts))
+	}
+	if queryStats.TenantID != "" {
+		buf.WriteString(blue + " tenantID" + reset + "=" + queryStats.TenantID)
+	}
+	if queryStats.UserID != "" {
+		buf.WriteString(blue + " userID" + reset + "=" + queryStats.UserID)
+	}
+	if queryStats.RequestID != "" {
+		buf.WriteString(blue + " requestID" + reset + "=" + queryStats.RequestID)
+	}
 	if l.config.ShowCaller {
 		buf.WriteString(blue + " caller" + reset + "=" + queryStats.CallerFile + ":" + strconv.Itoa(queryStats.CallerLine) + ":" + filepath.Base(queryStats.CallerFunction))
 	}
 	if !l.config.HideArgs && l.config.InterpolateVerbose {
 		buf.WriteString("\n" + purple + "----[ Executing query ]----" + reset)
-		buf.WriteString("\n" + queryStats.Query + "; " + fmt.Sprintf("%#v", queryStats.Args))
+		buf.WriteString("\n" + pretty(queryStats.Query) + "; " + fmt.Sprintf("%#v", queryStats.Args))
 		buf.WriteString("\n" + purple + "----[ with bind values ]----" + reset)
 		query, err := Sprintf(queryStats.Dialect, queryStats.Query, queryStats.Args)
+		if err == nil {
+			query = pretty(query)
+		}
 		query += ";"
 		if err != nil {
 			query += " " + err.Error()
@@ -250,10 +444,7 @@ func Log(db DB) interface {
 	DB
 	SqLogger
 } {
-	return struct {
-		DB
-		SqLogger
-	}{DB: db, SqLogger: defaultLogger}
+	return &loggerDB{DB: db, SqLogger: defaultLogger}
 }
 
 // VerboseLog wraps a DB and adds verbose logging to it.
@@ -261,12 +452,24 @@ func VerboseLog(db DB) interface {
 	DB
 	SqLogger
 } {
-	return struct {
-		DB
-		SqLogger
-	}{DB: db, SqLogger: verboseLogger}
+	return &loggerDB{DB: db, SqLogger: verboseLogger}
+}
+
+// loggerDB is the DB returned by Log and VerboseLog.
+type loggerDB struct {
+	DB
+	SqLogger
+}
+
+func (db *loggerDB) SqDialect() string {
+	if db, ok := db.DB.(interface{ SqDialect() string }); ok {
+		return db.SqDialect()
+	}
+	return ""
 }
 
+func (db *loggerDB) unwrap() DB { return db.DB }
+
 var defaultLogSettings atomic.Value
 
 // SetDefaultLogSettings sets the function to configure the default
@@ -305,6 +508,308 @@ func (l *sqLogStruct) SqLogQuery(ctx context.Context, queryStats QueryStats) {
 	l.logQuery(ctx, queryStats)
 }
 
+type queryStatsKey struct{}
+
+// WithQueryStats returns a copy of ctx that makes the Fetch and Exec functions
+// store the QueryStats of the query into dest once the query is done, so that
+// the caller can attach it to their own tracing without writing a logger. The
+// query's time is always measured, even if the DB has no logger. If the same
+// ctx is used for several queries, dest holds the QueryStats of the last one.
+// For FetchCursor, dest is filled in when the cursor is closed.
+//
+//	var stats sq.QueryStats
+//	actors, err := sq.FetchAllContext(sq.WithQueryStats(ctx, &stats), db, query, rowmapper)
+//	log.Printf("fetched %d actors in %s", stats.RowCount.Int64, stats.TimeTaken)
+func WithQueryStats(ctx context.Context, dest *QueryStats) context.Context {
+	return context.WithValue(ctx, queryStatsKey{}, dest)
+}
+
+// queryStatsLogger wraps the logger of a query (which may be nil) and copies
+// the QueryStats into the destination passed to WithQueryStats before logging
+// them.
+type queryStatsLogger struct {
+	logger            SqLogger
+	dest              *QueryStats
+	logAsynchronously bool
+}
+
+// withQueryStatsDest returns logger wrapped in a queryStatsLogger if ctx has a
+// destination for the QueryStats, otherwise it returns logger unchanged.
+func withQueryStatsDest(ctx context.Context, logger SqLogger) SqLogger {
+	dest, _ := ctx.Value(queryStatsKey{}).(*QueryStats)
+	if dest == nil {
+		return logger
+	}
+	return &queryStatsLogger{logger: logger, dest: dest}
+}
+
+func (l *queryStatsLogger) SqLogSettings(ctx context.Context, logSettings *LogSettings) {
+	if l.logger != nil {
+		l.logger.SqLogSettings(ctx, logSettings)
+	}
+	// The QueryStats must be copied into dest before the query function
+	// returns, so only the wrapped logger may run asynchronously.
+	l.logAsynchronously = logSettings.LogAsynchronously
+	logSettings.LogAsynchronously = false
+	logSettings.IncludeTime = true
+}
+
+func (l *queryStatsLogger) SqLogQuery(ctx context.Context, queryStats QueryStats) {
+	*l.dest = queryStats
+	if l.logger == nil {
+		return
+	}
+	if l.logAsynchronously {
+		go l.logger.SqLogQuery(ctx, queryStats)
+	} else {
+		l.logger.SqLogQuery(ctx, queryStats)
+	}
+}
+
+// QueryUsage is a snapshot of the usage counters of a compiled query. It is
+// shared by every CompiledFetch, CompiledExec, PreparedFetch and PreparedExec
+// with the same dialect and query string.
+type QueryUsage struct {
+	// Dialect of the query.
+	Dialect string
+
+	// Query string.
+	Query string
+
+	// Executions is the number of times the query was run, including the
+	// runs that failed.
+	Executions int64
+
+	// Errors is the number of times the query failed.
+	Errors int64
+
+	// Rows is the total number of rows fetched (for fetches) or affected (for
+	// execs).
+	Rows int64
+
+	// TotalTime is the cumulative time taken by the query. For fetches it is
+	// measured from running the query until the cursor is exhausted or
+	// closed, so it includes the time spent scanning rows.
+	TotalTime time.Duration
+}
+
+type queryUsage struct {
+	dialect    string
+	query      string
+	executions atomic.Int64
+	errors     atomic.Int64
+	rows       atomic.Int64
+	totalTime  atomic.Int64
+	// element is the usage's element in queryUsages.order, or nil if the
+	// usage is not in queryUsages. It is guarded by queryUsages.mu.
+	element *list.Element
+}
+
+type queryUsageKey struct {
+	dialect string
+	query   string
+}
+
+// queryUsages holds the usage counters of recently run compiled queries.
+var queryUsages = &queryUsageCache{capacity: 1024}
+
+// SetStatsCacheSize sets the maximum number of distinct queries whose usage
+// counters are listed by Stats (1024 by default). The least recently run
+// queries are evicted first, evicted queries keep counting and are listed
+// again once they run. A size of zero or less disables the listing, but
+// compiled and prepared queries still keep their own counters.
+func SetStatsCacheSize(size int) {
+	queryUsages.mu.Lock()
+	defer queryUsages.mu.Unlock()
+	queryUsages.capacity = size
+	if queryUsages.order == nil {
+		return
+	}
+	for queryUsages.order.Len() > 0 && queryUsages.order.Len() > size {
+		queryUsages.removeOldest()
+	}
+}
+
+// queryUsageCache is an LRU cache of queryUsages.
+type queryUsageCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[queryUsageKey]*queryUsage
+	order    *list.List // most recently used at the front
+}
+
+// getQueryUsage returns the usage counters for the dialect and query string.
+func getQueryUsage(dialect string, query string) *queryUsage {
+	return queryUsages.get(dialect, query)
+}
+
+// get returns the usage counters for the dialect and query string, creating
+// them if they are not in the cache.
+func (c *queryUsageCache) get(dialect string, query string) *queryUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if usage, ok := c.entries[queryUsageKey{dialect: dialect, query: query}]; ok {
+		c.order.MoveToFront(usage.element)
+		return usage
+	}
+	usage := &queryUsage{dialect: dialect, query: query}
+	c.add(usage)
+	return usage
+}
+
+// touch marks the usage as the most recently used, adding it back to the
+// cache if it was evicted (and no other usage with the same key has taken its
+// place).
+func (c *queryUsageCache) touch(usage *queryUsage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if usage.element != nil {
+		c.order.MoveToFront(usage.element)
+		return
+	}
+	if _, ok := c.entries[queryUsageKey{dialect: usage.dialect, query: usage.query}]; !ok {
+		c.add(usage)
+	}
+}
+
+// add adds a usage to the cache, evicting the least recently used usages if
+// the cache is full. The caller must hold c.mu.
+func (c *queryUsageCache) add(usage *queryUsage) {
+	if c.capacity <= 0 {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[queryUsageKey]*queryUsage)
+		c.order = list.New()
+	}
+	c.entries[queryUsageKey{dialect: usage.dialect, query: usage.query}] = usage
+	usage.element = c.order.PushFront(usage)
+	for c.order.Len() > c.capacity {
+		c.removeOldest()
+	}
+}
+
+func (c *queryUsageCache) removeOldest() {
+	element := c.order.Back()
+	c.order.Remove(element)
+	usage := element.Value.(*queryUsage)
+	usage.element = nil
+	delete(c.entries, queryUsageKey{dialect: usage.dialect, query: usage.query})
+}
+
+// all returns every usage in the cache.
+func (c *queryUsageCache) all() []*queryUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	usages := make([]*queryUsage, 0, len(c.entries))
+	for _, usage := range c.entries {
+		usages = append(usages, usage)
+	}
+	return usages
+}
+
+func (usage *queryUsage) record(timeTaken time.Duration, rows int64, err error) {
+	queryUsages.touch(usage)
+	usage.executions.Add(1)
+	if err != nil {
+		usage.errors.Add(1)
+	}
+	usage.rows.Add(rows)
+	usage.totalTime.Add(int64(timeTaken))
+}
+
+func (usage *queryUsage) snapshot() QueryUsage {
+	if usage == nil {
+		return QueryUsage{}
+	}
+	return QueryUsage{
+		Dialect:    usage.dialect,
+		Query:      usage.query,
+		Executions: usage.executions.Load(),
+		Errors:     usage.errors.Load(),
+		Rows:       usage.rows.Load(),
+		TotalTime:  time.Duration(usage.totalTime.Load()),
+	}
+}
+
+// Stats returns a snapshot of the usage counters of every compiled (or
+// prepared) query that has been run at least once, hottest first (by
+// TotalTime). Only the 1024 most recently run queries are listed, see
+// SetStatsCacheSize. Queries run with FetchOne, FetchAll, Exec etc are not compiled
+// and are not counted.
+func Stats() []QueryUsage {
+	var usages []QueryUsage
+	for _, usage := range queryUsages.all() {
+		usage := usage.snapshot()
+		if usage.Executions > 0 {
+			usages = append(usages, usage)
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].TotalTime != usages[j].TotalTime {
+			return usages[i].TotalTime > usages[j].TotalTime
+		}
+		if usages[i].Executions != usages[j].Executions {
+			return usages[i].Executions > usages[j].Executions
+		}
+		if usages[i].Query != usages[j].Query {
+			return usages[i].Query < usages[j].Query
+		}
+		return usages[i].Dialect < usages[j].Dialect
+	})
+	return usages
+}
+
+// ResetStats resets the usage counters of every query listed by Stats to
+// zero.
+func ResetStats() {
+	for _, usage := range queryUsages.all() {
+		usage.executions.Store(0)
+		usage.errors.Store(0)
+		usage.rows.Store(0)
+		usage.totalTime.Store(0)
+	}
+}
+
+// sqlKeywords are the keywords colored by highlightSQL.
+var sqlKeywords = map[string]bool{
+	"ALL": true, "AND": true, "AS": true, "ASC": true, "BETWEEN": true,
+	"BY": true, "CASE": true, "CAST": true, "CONFLICT": true, "CROSS": true,
+	"DELETE": true, "DESC": true, "DISTINCT": true, "DO": true, "DUPLICATE": true,
+	"ELSE": true, "END": true, "EXCEPT": true, "EXISTS": true, "FALSE": true,
+	"FETCH": true, "FIRST": true, "FOR": true, "FROM": true, "FULL": true,
+	"GROUP": true, "HAVING": true, "ILIKE": true, "IN": true, "INNER": true,
+	"INSERT": true, "INTERSECT": true, "INTO": true, "IS": true, "JOIN": true,
+	"KEY": true, "LATERAL": true, "LEFT": true, "LIKE": true, "LIMIT": true,
+	"MATCHED": true, "MERGE": true, "NATURAL": true, "NEXT": true, "NOT": true,
+	"NOTHING": true, "NULL": true, "OFFSET": true, "ON": true, "ONLY": true,
+	"OR": true, "ORDER": true, "OUTER": true, "OVER": true, "PARTITION": true,
+	"RECURSIVE": true, "RETURNING": true, "RIGHT": true, "ROWS": true,
+	"SELECT": true, "SET": true, "THEN": true, "TOP": true, "TRUE": true,
+	"UNION": true, "UPDATE": true, "USING": true, "VALUES": true, "WHEN": true,
+	"WHERE": true, "WINDOW": true, "WITH": true,
+}
+
+// highlightSQL colors the keywords and the string and number literals of a
+// query.
+func highlightSQL(query string, keywordColor, literalColor, reset string) string {
+	var buf strings.Builder
+	buf.Grow(len(query) + len(query)/4)
+	for _, token := range prettyTokenize(query) {
+		switch {
+		case token.kind == prettyWord && token.text[0] >= '0' && token.text[0] <= '9':
+			buf.WriteString(literalColor + token.text + reset)
+		case token.kind == prettyWord && sqlKeywords[strings.ToUpper(token.text)]:
+			buf.WriteString(keywordColor + token.text + reset)
+		case token.kind == prettyOther && token.text[0] == '\'':
+			buf.WriteString(literalColor + token.text + reset)
+		default:
+			buf.WriteString(token.text)
+		}
+	}
+	return buf.String()
+}
+
 const (
 	colorReset  = "\x1b[0m"
 	colorRed    = "\x1b[91m"
@@ -76,6 +76,15 @@ func TestLogger(t *testing.T) {
 			Err:   fmt.Errorf("lorem ipsum"),
 		},
 		wantOutput: "\x1b[91m[FAIL]\x1b[0m SELECT 1;\x1b[94m err\x1b[0m={lorem ipsum}\n",
+	}, {
+		description: "err timeout",
+		stats: QueryStats{
+			Query:          "SELECT 1",
+			Err:            fmt.Errorf("canceling statement due to statement timeout"),
+			ErrorKind:      ErrorKindTimeout,
+			ServerCanceled: true,
+		},
+		wantOutput: "\x1b[91m[FAIL]\x1b[0m SELECT 1;\x1b[94m err\x1b[0m={canceling statement due to statement timeout}\x1b[94m errorKind\x1b[0m=timeout\x1b[94m serverCanceled\x1b[0m=true\n",
 	}, {
 		description: "HideArgs",
 		config:      LoggerConfig{HideArgs: true},
@@ -83,6 +92,44 @@ func TestLogger(t *testing.T) {
 			Query: "SELECT ?", Args: []any{1},
 		},
 		wantOutput: "\x1b[92m[OK]\x1b[0m SELECT ?;\n",
+	}, {
+		description: "PrettyPrint",
+		config:      LoggerConfig{PrettyPrint: true},
+		stats: QueryStats{
+			Query: "SELECT name FROM actor WHERE actor_id = ?", Args: []any{1},
+		},
+		wantOutput: "\x1b[92m[OK]\x1b[0m\nSELECT name\nFROM actor\nWHERE actor_id = 1;\n",
+	}, {
+		description: "HighlightSQL",
+		config:      LoggerConfig{HighlightSQL: true},
+		stats: QueryStats{
+			Query: "SELECT name FROM actor WHERE actor_id = ? AND name <> ?", Args: []any{1, "it's"},
+		},
+		wantOutput: "\x1b[92m[OK]\x1b[0m \x1b[96mSELECT\x1b[0m name \x1b[96mFROM\x1b[0m actor" +
+			" \x1b[96mWHERE\x1b[0m actor_id = \x1b[93m1\x1b[0m \x1b[96mAND\x1b[0m name <> \x1b[93m'it''s'\x1b[0m;\n",
+	}, {
+		description: "HighlightSQL NoColor",
+		config:      LoggerConfig{HighlightSQL: true, NoColor: true},
+		stats: QueryStats{
+			Query: "SELECT ?", Args: []any{1},
+		},
+		wantOutput: "[OK] SELECT 1;\n",
+	}, {
+		description: "WarnTimeTaken",
+		config:      LoggerConfig{WarnTimeTaken: time.Second},
+		stats: QueryStats{
+			Query:     "SELECT 1",
+			TimeTaken: 2 * time.Second,
+		},
+		wantOutput: "\x1b[92m[OK]\x1b[0m SELECT 1;\x1b[91m timeTaken\x1b[0m=2s (exceeds 1s)\n",
+	}, {
+		description: "WarnTimeTaken not exceeded",
+		config:      LoggerConfig{ShowTimeTaken: true, WarnTimeTaken: time.Second},
+		stats: QueryStats{
+			Query:     "SELECT 1",
+			TimeTaken: time.Millisecond,
+		},
+		wantOutput: "\x1b[92m[OK]\x1b[0m SELECT 1;\x1b[94m timeTaken\x1b[0m=1ms\n",
 	}, {
 		description: "RowCount",
 		stats: QueryStats{
@@ -111,6 +158,23 @@ func TestLogger(t *testing.T) {
 			Exists: sql.NullBool{Valid: true, Bool: true},
 		},
 		wantOutput: "\x1b[92m[OK]\x1b[0m SELECT EXISTS (SELECT 1);\x1b[94m exists\x1b[0m=true\n",
+	}, {
+		description: "WarnQueryLength",
+		config:      LoggerConfig{WarnQueryLength: 5},
+		stats: QueryStats{
+			Query:       "SELECT 1",
+			QueryLength: 8,
+		},
+		wantOutput: "\x1b[92m[OK]\x1b[0m SELECT 1;\x1b[91m queryLength\x1b[0m=8 (exceeds 5)\n",
+	}, {
+		description: "WarnArgCount",
+		config:      LoggerConfig{WarnArgCount: 2, WarnQueryLength: 100},
+		stats: QueryStats{
+			Query: "SELECT ?, ?, ?", Args: []any{1, 2, 3},
+			QueryLength: 14,
+			ArgCount:    3,
+		},
+		wantOutput: "\x1b[92m[OK]\x1b[0m SELECT 1, 2, 3;\x1b[91m argCount\x1b[0m=3 (exceeds 2)\n",
 	}, {
 		description: "ShowCaller",
 		config:      LoggerConfig{ShowCaller: true},
@@ -152,3 +216,176 @@ func TestLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryStatsErrorKind(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	expiredCtx, cancel := context.WithDeadline(context.Background(), time.Unix(0, 0))
+	defer cancel()
+
+	type TestTable struct {
+		description        string
+		ctx                context.Context
+		err                error
+		wantErrorKind      QueryErrorKind
+		wantServerCanceled bool
+	}
+
+	tests := []TestTable{{
+		description: "nil",
+	}, {
+		description:   "context canceled",
+		ctx:           canceledCtx,
+		err:           fmt.Errorf("query: %w", context.Canceled),
+		wantErrorKind: ErrorKindCanceled,
+	}, {
+		description:   "context deadline exceeded",
+		ctx:           expiredCtx,
+		err:           context.DeadlineExceeded,
+		wantErrorKind: ErrorKindTimeout,
+	}, {
+		description:        "postgres cancel request",
+		ctx:                canceledCtx,
+		err:                &fakePQError{Code: "57014"},
+		wantErrorKind:      ErrorKindCanceled,
+		wantServerCanceled: true,
+	}, {
+		description:        "postgres cancel request after deadline",
+		ctx:                expiredCtx,
+		err:                &fakePQError{Code: "57014"},
+		wantErrorKind:      ErrorKindTimeout,
+		wantServerCanceled: true,
+	}, {
+		description:        "mysql max_execution_time",
+		err:                &fakeMySQLError{Number: 3024, Message: "Query execution was interrupted, maximum statement execution time exceeded"},
+		wantErrorKind:      ErrorKindTimeout,
+		wantServerCanceled: true,
+	}, {
+		description:        "sqlite interrupt",
+		ctx:                canceledCtx,
+		err:                fakeSQLiteError{Code: 9, ExtendedCode: 9},
+		wantErrorKind:      ErrorKindCanceled,
+		wantServerCanceled: true,
+	}, {
+		description:   "syntax error",
+		ctx:           canceledCtx,
+		err:           fmt.Errorf(`pq: syntax error at or near "SELEC"`),
+		wantErrorKind: ErrorKindDriver,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			queryStats := QueryStats{Err: tt.err}
+			queryStats.setErrorKind(tt.ctx)
+			if diff := testutil.Diff(queryStats.ErrorKind, tt.wantErrorKind); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			if diff := testutil.Diff(queryStats.ServerCanceled, tt.wantServerCanceled); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+
+	t.Run("Fetch", func(t *testing.T) {
+		t.Parallel()
+		recorder := &queryStatsRecorder{}
+		db := struct {
+			DB
+			SqLogger
+		}{DB: newDB(t), SqLogger: recorder}
+		_, err := FetchAllContext(canceledCtx, db, SQLite.From(ACTOR), func(row *Row) int {
+			return row.IntField(ACTOR.ACTOR_ID)
+		})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		_, err = ExecContext(expiredCtx, db, SQLite.DeleteFrom(ACTOR).Where(Expr("1 = 1")))
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		if diff := testutil.Diff(len(recorder.queryStats), 2); diff != "" {
+			t.Fatal(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(recorder.queryStats[0].ErrorKind, ErrorKindCanceled); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(recorder.queryStats[1].ErrorKind, ErrorKindTimeout); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
+
+func TestWithQueryStats(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	var stats QueryStats
+	ctx := WithQueryStats(context.Background(), &stats)
+
+	_, err := ExecContext(ctx, db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values("PENELOPE", "GUINESS").
+		Values("NICK", "WAHLBERG"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(stats.RowsAffected, sql.NullInt64{Int64: 2, Valid: true}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if stats.StartedAt.IsZero() {
+		t.Error(testutil.Callers(), "StartedAt was not recorded")
+	}
+
+	_, err = FetchAllContext(ctx, db, SQLite.From(ACTOR).Where(ACTOR.FIRST_NAME.EqString("NICK")), func(row *Row) string {
+		return row.StringField(ACTOR.LAST_NAME)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(stats.Query, "SELECT actor.last_name FROM actor WHERE actor.first_name = $1"); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if diff := testutil.Diff(stats.Args, []any{"NICK"}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if diff := testutil.Diff(stats.RowCount, sql.NullInt64{Int64: 1, Valid: true}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	t.Run("with an asynchronous logger", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		db := struct {
+			DB
+			SqLogger
+		}{DB: newDB(t), SqLogger: NewLogger(&buf, "", 0, LoggerConfig{LogAsynchronously: true})}
+		var stats QueryStats
+		exists, err := FetchExistsContext(WithQueryStats(context.Background(), &stats), db, SQLite.SelectOne().From(ACTOR))
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(stats.Exists, sql.NullBool{Bool: exists, Valid: true}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		t.Parallel()
+		var stats QueryStats
+		_, err := FetchOneContext(WithQueryStats(context.Background(), &stats), db, SQLite.Queryf("SELECT * FROM nonexistent"), func(row *Row) int {
+			return row.Int("id")
+		})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		if stats.Err == nil {
+			t.Error(testutil.Callers(), "expected stats.Err to be set")
+		}
+		if diff := testutil.Diff(stats.ErrorKind, ErrorKindDriver); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
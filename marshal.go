@@ -0,0 +1,218 @@
+package sq
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// compiledQueryJSON is the serialized form of a CompiledFetch or
+// CompiledExec.
+type compiledQueryJSON struct {
+	Dialect string            `json:"dialect"`
+	Query   string            `json:"query"`
+	Args    []compiledArgJSON `json:"args"`
+	Params  map[string][]int  `json:"params,omitempty"`
+	Static  bool              `json:"static,omitempty"`
+}
+
+// compiledArgJSON is the serialized form of an arg. Named args have a
+// non-empty Name.
+type compiledArgJSON struct {
+	Name  string          `json:"name,omitempty"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// argTypes maps the serialized type of an arg to its Go type.
+var argTypes = map[string]reflect.Type{
+	"bool":    reflect.TypeOf(false),
+	"int":     reflect.TypeOf(int(0)),
+	"int8":    reflect.TypeOf(int8(0)),
+	"int16":   reflect.TypeOf(int16(0)),
+	"int32":   reflect.TypeOf(int32(0)),
+	"int64":   reflect.TypeOf(int64(0)),
+	"uint":    reflect.TypeOf(uint(0)),
+	"uint8":   reflect.TypeOf(uint8(0)),
+	"uint16":  reflect.TypeOf(uint16(0)),
+	"uint32":  reflect.TypeOf(uint32(0)),
+	"uint64":  reflect.TypeOf(uint64(0)),
+	"float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)),
+	"string":  reflect.TypeOf(""),
+	"bytes":   reflect.TypeOf([]byte(nil)),
+	"time":    reflect.TypeOf(time.Time{}),
+}
+
+// MarshalJSON implements the json.Marshaler interface. The dialect, query
+// string, args and param indexes are serialized but the rowmapper is not, so
+// that a CompiledFetch can be compiled ahead of time (e.g. by a build step)
+// and loaded at runtime without the query builder. Args must be nil, a bool,
+// a number, a string, a []byte or a time.Time (or an sql.NamedArg of those).
+func (compiledFetch *CompiledFetch[T]) MarshalJSON() ([]byte, error) {
+	return marshalCompiledQuery(compiledFetch.dialect, compiledFetch.query, compiledFetch.args, compiledFetch.params, compiledFetch.queryIsStatic)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. It uses
+// the same encoding as MarshalJSON.
+func (compiledFetch *CompiledFetch[T]) MarshalBinary() ([]byte, error) {
+	return compiledFetch.MarshalJSON()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The rowmapper is
+// not part of the serialized form, the CompiledFetch's existing rowmapper is
+// kept instead.
+//
+//	compiledFetch := sq.NewCompiledFetch("", "", nil, nil, rowmapper)
+//	err := json.Unmarshal(data, compiledFetch)
+func (compiledFetch *CompiledFetch[T]) UnmarshalJSON(data []byte) error {
+	var v compiledQueryJSON
+	args, err := unmarshalCompiledQuery(data, &v)
+	if err != nil {
+		return err
+	}
+	compiledFetch.dialect = v.Dialect
+	compiledFetch.query = v.Query
+	compiledFetch.args = args
+	compiledFetch.params = v.Params
+	compiledFetch.queryIsStatic = v.Static
+	compiledFetch.usage = getQueryUsage(v.Dialect, v.Query)
+	return nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. It
+// decodes the output of MarshalBinary.
+func (compiledFetch *CompiledFetch[T]) UnmarshalBinary(data []byte) error {
+	return compiledFetch.UnmarshalJSON(data)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The dialect, query
+// string, args and param indexes are serialized so that a CompiledExec can be
+// compiled ahead of time (e.g. by a build step) and loaded at runtime without
+// the query builder. Args must be nil, a bool, a number, a string, a []byte or
+// a time.Time (or an sql.NamedArg of those).
+func (compiledExec *CompiledExec) MarshalJSON() ([]byte, error) {
+	return marshalCompiledQuery(compiledExec.dialect, compiledExec.query, compiledExec.args, compiledExec.params, false)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. It uses
+// the same encoding as MarshalJSON.
+func (compiledExec *CompiledExec) MarshalBinary() ([]byte, error) {
+	return compiledExec.MarshalJSON()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (compiledExec *CompiledExec) UnmarshalJSON(data []byte) error {
+	var v compiledQueryJSON
+	args, err := unmarshalCompiledQuery(data, &v)
+	if err != nil {
+		return err
+	}
+	compiledExec.dialect = v.Dialect
+	compiledExec.query = v.Query
+	compiledExec.args = args
+	compiledExec.params = v.Params
+	compiledExec.usage = getQueryUsage(v.Dialect, v.Query)
+	return nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. It
+// decodes the output of MarshalBinary.
+func (compiledExec *CompiledExec) UnmarshalBinary(data []byte) error {
+	return compiledExec.UnmarshalJSON(data)
+}
+
+func marshalCompiledQuery(dialect string, query string, args []any, params map[string][]int, static bool) ([]byte, error) {
+	v := compiledQueryJSON{
+		Dialect: dialect,
+		Query:   query,
+		Args:    make([]compiledArgJSON, len(args)),
+		Params:  params,
+		Static:  static,
+	}
+	for i, arg := range args {
+		if namedArg, ok := arg.(sql.NamedArg); ok {
+			v.Args[i].Name = namedArg.Name
+			arg = namedArg.Value
+		}
+		var err error
+		v.Args[i].Type, v.Args[i].Value, err = marshalArg(arg)
+		if err != nil {
+			return nil, fmt.Errorf("arg #%d: %w", i+1, err)
+		}
+	}
+	return json.Marshal(v)
+}
+
+func marshalArg(arg any) (typ string, value json.RawMessage, err error) {
+	if arg == nil {
+		return "null", nil, nil
+	}
+	if _, ok := arg.(time.Time); ok {
+		typ = "time"
+	} else {
+		argValue := reflect.ValueOf(arg)
+		switch argValue.Kind() {
+		case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			typ = argValue.Kind().String()
+		case reflect.Slice:
+			if argValue.Type().Elem().Kind() != reflect.Uint8 {
+				return "", nil, fmt.Errorf("cannot marshal %T", arg)
+			}
+			typ = "bytes"
+		default:
+			return "", nil, fmt.Errorf("cannot marshal %T", arg)
+		}
+		// Named types e.g. type Status string are marshaled as their
+		// underlying type.
+		arg = argValue.Convert(argTypes[typ]).Interface()
+	}
+	value, err = json.Marshal(arg)
+	if err != nil {
+		return "", nil, err
+	}
+	return typ, value, nil
+}
+
+func unmarshalCompiledQuery(data []byte, v *compiledQueryJSON) (args []any, err error) {
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return nil, err
+	}
+	if v.Params == nil {
+		v.Params = make(map[string][]int)
+	}
+	for name, indexes := range v.Params {
+		for _, index := range indexes {
+			if index < 0 || index >= len(v.Args) {
+				return nil, fmt.Errorf("param %q: index %d out of range (%d args)", name, index, len(v.Args))
+			}
+		}
+	}
+	args = make([]any, len(v.Args))
+	for i, arg := range v.Args {
+		var value any
+		if arg.Type != "null" {
+			argType, ok := argTypes[arg.Type]
+			if !ok {
+				return nil, fmt.Errorf("arg #%d: unknown type %q", i+1, arg.Type)
+			}
+			ptr := reflect.New(argType)
+			err = json.Unmarshal(arg.Value, ptr.Interface())
+			if err != nil {
+				return nil, fmt.Errorf("arg #%d: %w", i+1, err)
+			}
+			value = ptr.Elem().Interface()
+		}
+		if arg.Name != "" {
+			args[i] = sql.Named(arg.Name, value)
+		} else {
+			args[i] = value
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,100 @@
+package sq
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestMarshalCompiled(t *testing.T) {
+	t.Run("CompiledExec", func(t *testing.T) {
+		type Status string
+		compiledExec, err := CompileExec(Postgres.
+			Update(ACTOR).
+			Set(
+				ACTOR.FIRST_NAME.Set(StringParam("first_name", "DAN")),
+				ACTOR.LAST_NAME.Set(Status("TORN")),
+				ACTOR.LAST_UPDATE.Set(time.Unix(1, 0).UTC()),
+			).
+			Where(ACTOR.ACTOR_ID.In([]any{1, int64(2), 3.5, []byte{0xff}, nil, true})),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		data, err := json.Marshal(compiledExec)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		var gotExec CompiledExec
+		err = json.Unmarshal(data, &gotExec)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		wantDialect, wantQuery, wantArgs, wantParams := compiledExec.GetSQL()
+		wantArgs[1] = "TORN"
+		gotDialect, gotQuery, gotArgs, gotParams := gotExec.GetSQL()
+		if diff := testutil.Diff(gotDialect, wantDialect); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(gotQuery, wantQuery); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(gotArgs, wantArgs); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(gotParams, wantParams); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("CompiledFetch", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		compiledFetch, err := CompileFetch(SQLite.
+			From(ACTOR).
+			Where(ACTOR.ACTOR_ID.Eq(IntParam("actor_id", 0))),
+			actorRowMapper,
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		data, err := compiledFetch.MarshalBinary()
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		gotFetch := NewCompiledFetch("", "", nil, nil, actorRowMapper)
+		err = gotFetch.UnmarshalBinary(data)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = db.Exec("INSERT INTO actor (actor_id, first_name, last_name, last_update) VALUES (1, 'PENELOPE', 'GUINESS', '2006-02-15 04:34:33')")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		actor, err := gotFetch.FetchOne(db, Params{"actor_id": 1})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(actor.FirstName, "PENELOPE"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("unsupported arg", func(t *testing.T) {
+		compiledExec := NewCompiledExec(DialectPostgres, "SELECT $1", []any{struct{}{}}, nil)
+		_, err := compiledExec.MarshalJSON()
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+	})
+
+	t.Run("param index out of range", func(t *testing.T) {
+		var compiledExec CompiledExec
+		err := compiledExec.UnmarshalJSON([]byte(`{"dialect":"postgres","query":"SELECT $1","args":[],"params":{"id":[0]}}`))
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
@@ -481,6 +481,114 @@ func (e SimpleCaseExpression) IsTime() {}
 // IsUUID implements the UUID interface.
 func (e SimpleCaseExpression) IsUUID() {}
 
+// CastExpression represents an SQL CAST(<value> AS <type>) expression. Use it
+// to give a type hint to a value whose type the database (or the driver)
+// cannot infer e.g. an untyped parameter in Postgres.
+type CastExpression struct {
+	value any
+	typ   string
+	alias string
+}
+
+var _ interface {
+	Field
+	Predicate
+	Any
+} = (*CastExpression)(nil)
+
+// Cast returns a new CastExpression. The value may be a Go value (which is
+// passed in as an argument) or an SQL expression such as a field. The type is
+// written into the query as-is, so it must not come from user input.
+//
+//	// CAST($1 AS uuid)
+//	sq.Cast(id, "uuid")
+func Cast(value any, typ string) CastExpression {
+	return CastExpression{value: value, typ: typ}
+}
+
+// WriteSQL implements the SQLWriter interface. CAST is used for every dialect,
+// including Postgres: the :: shorthand binds tighter than most operators so it
+// would need parentheses around the value to be safe.
+func (e CastExpression) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if e.typ == "" {
+		return fmt.Errorf("CAST: type is empty")
+	}
+	for _, char := range e.typ {
+		if char == '_' || char == ' ' || char == '(' || char == ')' || char == ',' || char == '.' || char == '[' || char == ']' ||
+			(char >= '0' && char <= '9') || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') {
+			continue
+		}
+		return fmt.Errorf("CAST: invalid type %q: contains %q", e.typ, char)
+	}
+	buf.WriteString("CAST(")
+	err := WriteValue(ctx, dialect, buf, args, params, e.value)
+	if err != nil {
+		return fmt.Errorf("CAST: %w", err)
+	}
+	buf.WriteString(" AS " + e.typ + ")")
+	return nil
+}
+
+// As returns a new CastExpression with the given alias.
+func (e CastExpression) As(alias string) CastExpression {
+	e.alias = alias
+	return e
+}
+
+// In returns a 'expr IN (val)' Predicate.
+func (e CastExpression) In(val any) Predicate { return In(e, val) }
+
+// Eq returns a 'expr = val' Predicate.
+func (e CastExpression) Eq(val any) Predicate { return Eq(e, val) }
+
+// Ne returns a 'expr <> val' Predicate.
+func (e CastExpression) Ne(val any) Predicate { return Ne(e, val) }
+
+// Lt returns a 'expr < val' Predicate.
+func (e CastExpression) Lt(val any) Predicate { return Lt(e, val) }
+
+// Le returns a 'expr <= val' Predicate.
+func (e CastExpression) Le(val any) Predicate { return Le(e, val) }
+
+// Gt returns a 'expr > val' Predicate.
+func (e CastExpression) Gt(val any) Predicate { return Gt(e, val) }
+
+// Ge returns a 'expr >= val' Predicate.
+func (e CastExpression) Ge(val any) Predicate { return Ge(e, val) }
+
+// GetAlias returns the alias of the CastExpression.
+func (e CastExpression) GetAlias() string { return e.alias }
+
+// IsField implements the Field interface.
+func (e CastExpression) IsField() {}
+
+// IsArray implements the Array interface.
+func (e CastExpression) IsArray() {}
+
+// IsBinary implements the Binary interface.
+func (e CastExpression) IsBinary() {}
+
+// IsBoolean implements the Boolean interface.
+func (e CastExpression) IsBoolean() {}
+
+// IsEnum implements the Enum interface.
+func (e CastExpression) IsEnum() {}
+
+// IsJSON implements the JSON interface.
+func (e CastExpression) IsJSON() {}
+
+// IsNumber implements the Number interface.
+func (e CastExpression) IsNumber() {}
+
+// IsString implements the String interface.
+func (e CastExpression) IsString() {}
+
+// IsTime implements the Time interface.
+func (e CastExpression) IsTime() {}
+
+// IsUUID implements the UUID interface.
+func (e CastExpression) IsUUID() {}
+
 // Count represents an SQL COUNT(<field>) expression.
 func Count(field Field) Expression { return Expr("COUNT({})", field) }
 
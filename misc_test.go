@@ -202,6 +202,56 @@ func TestCaseExpressions(t *testing.T) {
 	}
 }
 
+func TestCastExpression(t *testing.T) {
+	t.Run("alias", func(t *testing.T) {
+		t.Parallel()
+		if diff := testutil.Diff(Cast(1, "int").As("num").GetAlias(), "num"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	tests := []TestTable{{
+		description: "value",
+		dialect:     DialectPostgres,
+		item:        Cast("a4f952f1-4c45-4e63-bd4e-159ca33c8e20", "uuid"),
+		wantQuery:   "CAST($1 AS uuid)",
+		wantArgs:    []any{"a4f952f1-4c45-4e63-bd4e-159ca33c8e20"},
+	}, {
+		description: "field",
+		item:        NewNumberField("rental_rate", NewTableStruct("", "film", "f")).CastTo("numeric(10,2)"),
+		wantQuery:   "CAST(f.rental_rate AS numeric(10,2))",
+	}, {
+		description: "predicate",
+		dialect:     DialectPostgres,
+		item:        Cast(Expr("x"), "text[]").Eq(Cast("{a}", "text[]")),
+		wantQuery:   "CAST(x AS text[]) = CAST($1 AS text[])",
+		wantArgs:    []any{"{a}"},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	t.Run("empty type", func(t *testing.T) {
+		t.Parallel()
+		TestTable{item: Cast(1, "")}.assertNotOK(t)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		t.Parallel()
+		TestTable{item: Cast(1, "int); DROP TABLE actor; --")}.assertNotOK(t)
+	})
+
+	t.Run("value err", func(t *testing.T) {
+		t.Parallel()
+		TestTable{item: Cast(FaultySQL{}, "int")}.assertErr(t, ErrFaultySQL)
+	})
+}
+
 func TestSelectValues(t *testing.T) {
 	type TestTable struct {
 		description string
@@ -0,0 +1,105 @@
+package sq
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCurrencyMismatch is returned when amounts of money in different
+// currencies are added or subtracted.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an amount of money stored as an integer number of minor units of
+// its currency (e.g. cents for USD, yen for JPY), which avoids the rounding
+// errors of floating point amounts.
+type Money struct {
+	// Amount is the amount in minor units e.g. 1050 is $10.50.
+	Amount int64
+
+	// Currency is the currency code e.g. USD.
+	Currency string
+}
+
+// Add returns the sum of m and n. It returns ErrCurrencyMismatch if they are
+// in different currencies.
+func (m Money) Add(n Money) (Money, error) {
+	if m.Currency != n.Currency {
+		return Money{}, fmt.Errorf("%w: cannot add %s to %s", ErrCurrencyMismatch, n.Currency, m.Currency)
+	}
+	return Money{Amount: m.Amount + n.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of m and n. It returns ErrCurrencyMismatch if
+// they are in different currencies.
+func (m Money) Sub(n Money) (Money, error) {
+	if m.Currency != n.Currency {
+		return Money{}, fmt.Errorf("%w: cannot subtract %s from %s", ErrCurrencyMismatch, n.Currency, m.Currency)
+	}
+	return Money{Amount: m.Amount - n.Amount, Currency: m.Currency}, nil
+}
+
+// MoneyField is a Money stored in two columns: an integer amount in minor
+// units and a currency code. It is not a Field itself, use Amount and
+// Currency to get the underlying fields.
+//
+//	type ACCOUNT struct {
+//	    sq.TableStruct
+//	    ACCOUNT_ID       sq.NumberField
+//	    BALANCE          sq.NumberField
+//	    BALANCE_CURRENCY sq.StringField
+//	}
+//
+//	a := sq.New[ACCOUNT]("")
+//	balance := sq.NewMoneyField(a.BALANCE, a.BALANCE_CURRENCY)
+type MoneyField struct {
+	amount   NumberField
+	currency StringField
+}
+
+// NewMoneyField returns a new MoneyField.
+func NewMoneyField(amount NumberField, currency StringField) MoneyField {
+	return MoneyField{amount: amount, currency: currency}
+}
+
+// Amount returns the field of the amount column.
+func (field MoneyField) Amount() NumberField { return field.amount }
+
+// Currency returns the field of the currency column.
+func (field MoneyField) Currency() StringField { return field.currency }
+
+// Eq returns an 'amount = value.Amount AND currency = value.Currency'
+// Predicate.
+func (field MoneyField) Eq(value Money) Predicate {
+	return And(field.amount.EqInt64(value.Amount), field.currency.EqString(value.Currency))
+}
+
+// Set returns the Assignments assigning the value to the amount and currency
+// columns.
+//
+//	// UPDATE account SET balance = $1, balance_currency = $2
+//	sq.Update(a).Set(balance.Set(sq.Money{Amount: 1050, Currency: "USD"})...)
+func (field MoneyField) Set(value Money) Assignments {
+	return Assignments{
+		field.amount.SetInt64(value.Amount),
+		field.currency.SetString(value.Currency),
+	}
+}
+
+// Add returns an Assignment that adds the value to the amount column, but
+// only if the currency column has the same currency as the value:
+//
+//	amount = CASE WHEN currency = $1 THEN amount + $2 END
+//
+// If the currencies differ the amount is set to NULL, so the amount column
+// should be NOT NULL in order for the UPDATE to fail instead of mixing
+// currencies.
+func (field MoneyField) Add(value Money) Assignment {
+	return Set(field.amount, Expr("CASE WHEN {} = {} THEN {} + {} END", field.currency, value.Currency, field.amount, value.Amount))
+}
+
+// Sub returns an Assignment that subtracts the value from the amount column,
+// but only if the currency column has the same currency as the value. Like
+// Add, it sets the amount to NULL if the currencies differ.
+func (field MoneyField) Sub(value Money) Assignment {
+	return Set(field.amount, Expr("CASE WHEN {} = {} THEN {} - {} END", field.currency, value.Currency, field.amount, value.Amount))
+}
@@ -0,0 +1,109 @@
+package sq
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+var ACCOUNT = New[struct {
+	TableStruct      `sq:"account"`
+	ACCOUNT_ID       NumberField
+	BALANCE          NumberField
+	BALANCE_CURRENCY StringField
+}]("")
+
+func TestMoney(t *testing.T) {
+	t.Run("Add Sub", func(t *testing.T) {
+		t.Parallel()
+		m, err := Money{Amount: 1050, Currency: "USD"}.Add(Money{Amount: 25, Currency: "USD"})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		m, err = m.Sub(Money{Amount: 75, Currency: "USD"})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(m, Money{Amount: 1000, Currency: "USD"}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		_, err = m.Add(Money{Amount: 1, Currency: "EUR"})
+		if !errors.Is(err, ErrCurrencyMismatch) {
+			t.Errorf(testutil.Callers()+" expected ErrCurrencyMismatch, got %v", err)
+		}
+	})
+
+	balance := NewMoneyField(ACCOUNT.BALANCE, ACCOUNT.BALANCE_CURRENCY)
+	tests := []TestTable{{
+		description: "Set",
+		dialect:     DialectPostgres,
+		item:        Update(ACCOUNT).Set(balance.Set(Money{Amount: 1050, Currency: "USD"})...).Where(ACCOUNT.ACCOUNT_ID.EqInt(1)),
+		wantQuery:   "UPDATE account SET balance = $1, balance_currency = $2 WHERE account.account_id = $3",
+		wantArgs:    []any{int64(1050), "USD", 1},
+	}, {
+		description: "Add",
+		dialect:     DialectPostgres,
+		item:        Update(ACCOUNT).Set(balance.Add(Money{Amount: 25, Currency: "USD"})).Where(ACCOUNT.ACCOUNT_ID.EqInt(1)),
+		wantQuery: "UPDATE account SET balance = CASE WHEN account.balance_currency = $1 THEN account.balance + $2 END" +
+			" WHERE account.account_id = $3",
+		wantArgs: []any{"USD", int64(25), 1},
+	}, {
+		description: "Eq",
+		dialect:     DialectPostgres,
+		item:        Select(ACCOUNT.ACCOUNT_ID).From(ACCOUNT).Where(balance.Eq(Money{Amount: 0, Currency: "JPY"})),
+		wantQuery:   "SELECT account.account_id FROM account WHERE account.balance = $1 AND account.balance_currency = $2",
+		wantArgs:    []any{int64(0), "JPY"},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := db.Exec("CREATE TABLE account (account_id INT PRIMARY KEY, balance INT NOT NULL, balance_currency TEXT NOT NULL)")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = Exec(db, SQLite.InsertInto(ACCOUNT).ColumnValues(func(col *Column) {
+			col.SetInt(ACCOUNT.ACCOUNT_ID, 1)
+			col.SetMoney(balance, Money{Amount: 1050, Currency: "USD"})
+		}))
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = Exec(db, SQLite.Update(ACCOUNT).Set(balance.Sub(Money{Amount: 50, Currency: "USD"})))
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		// Adding a different currency fails the NOT NULL constraint.
+		_, err = Exec(db, SQLite.Update(ACCOUNT).Set(balance.Add(Money{Amount: 50, Currency: "EUR"})))
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error, got nil")
+		}
+		m, err := FetchOne(db, SQLite.From(ACCOUNT), func(row *Row) Money {
+			return row.MoneyField(balance)
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(m, Money{Amount: 1000, Currency: "USD"}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		m, err = FetchOne(db, SQLite.Queryf("SELECT balance, balance_currency FROM account"), func(row *Row) Money {
+			return row.MoneyField(balance)
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(m, Money{Amount: 1000, Currency: "USD"}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+//go:build go1.22
+
+package sq
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+// sql.Null[T] was added in Go 1.22, so its tests are kept apart from the
+// other tests which only need Go 1.19.
+
+func Test_preprocessValueNullGeneric(t *testing.T) {
+	type TestTable struct {
+		description string
+		dialect     string
+		input       any
+		wantOutput  any
+	}
+
+	tests := []TestTable{{
+		description: "sql.Null[T] NULL",
+		input:       sql.Null[string]{V: "lorem ipsum dolor sit amet"},
+		wantOutput:  nil,
+	}, {
+		description: "sql.Null[T] Enumeration",
+		input:       sql.Null[Weekday]{V: Monday, Valid: true},
+		wantOutput:  "Monday",
+	}, {
+		description: "MySQL sql.Null[[16]byte]",
+		dialect:     DialectMySQL,
+		input:       sql.Null[[16]byte]{V: [16]byte{0xa4, 0xf9, 0x52, 0xf1, 0x4c, 0x45, 0x4e, 0x63, 0xbd, 0x4e, 0x15, 0x9c, 0xa3, 0x3c, 0x8e, 0x20}, Valid: true},
+		wantOutput:  []byte{0xa4, 0xf9, 0x52, 0xf1, 0x4c, 0x45, 0x4e, 0x63, 0xbd, 0x4e, 0x15, 0x9c, 0xa3, 0x3c, 0x8e, 0x20},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			gotOutput, err := preprocessValue(tt.dialect, tt.input)
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(gotOutput, tt.wantOutput); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
+
+func TestSprintNullGeneric(t *testing.T) {
+	type TT struct {
+		description string
+		dialect     string
+		value       any
+		wantString  string
+	}
+
+	tests := []TT{{
+		description: "sql.Null[int] NULL",
+		value:       sql.Null[int]{V: 5},
+		wantString:  `NULL`,
+	}, {
+		description: "sql.Null[int]",
+		value:       sql.Null[int]{V: 5, Valid: true},
+		wantString:  `5`,
+	}, {
+		description: "sql.Null[string]",
+		value:       sql.Null[string]{V: "it's", Valid: true},
+		wantString:  `'it''s'`,
+	}, {
+		description: "*sql.Null[bool]",
+		dialect:     DialectSQLServer,
+		value:       &sql.Null[bool]{V: true, Valid: true},
+		wantString:  `1`,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			gotString, err := Sprint(tt.dialect, tt.value)
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(gotString, tt.wantString); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
+
+func TestFetchNullGeneric(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME, ACTOR.LAST_UPDATE).
+		Values(1, "PENELOPE", "GUINESS", sql.Null[time.Time]{V: time.Unix(1, 0).UTC(), Valid: true}).
+		Values(2, sql.Null[string]{V: "NICK", Valid: true}, "WAHLBERG", time.Unix(2, 0).UTC()),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	type result struct {
+		ActorID    sql.Null[int]
+		FirstName  sql.Null[string]
+		LastUpdate sql.Null[time.Time]
+		Score      sql.Null[float64]
+		Rank       sql.Null[uint8]
+		OddID      sql.Null[int64]
+	}
+	results, err := FetchAll(db, SQLite.From(ACTOR).OrderBy(ACTOR.ACTOR_ID), func(row *Row) result {
+		var r result
+		row.ScanField(&r.ActorID, ACTOR.ACTOR_ID)
+		row.ScanField(&r.FirstName, ACTOR.FIRST_NAME)
+		row.ScanField(&r.LastUpdate, ACTOR.LAST_UPDATE)
+		row.Scan(&r.Score, "NULL")
+		row.Scan(&r.Rank, "3")
+		row.Scan(&r.OddID, "NULLIF({}, 2)", ACTOR.ACTOR_ID)
+		return r
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	want := []result{{
+		ActorID:    sql.Null[int]{V: 1, Valid: true},
+		FirstName:  sql.Null[string]{V: "PENELOPE", Valid: true},
+		LastUpdate: sql.Null[time.Time]{V: time.Unix(1, 0).UTC(), Valid: true},
+		Rank:       sql.Null[uint8]{V: 3, Valid: true},
+		OddID:      sql.Null[int64]{V: 1, Valid: true},
+	}, {
+		ActorID:    sql.Null[int]{V: 2, Valid: true},
+		FirstName:  sql.Null[string]{V: "NICK", Valid: true},
+		LastUpdate: sql.Null[time.Time]{V: time.Unix(2, 0).UTC(), Valid: true},
+		Rank:       sql.Null[uint8]{V: 3, Valid: true},
+	}}
+	if diff := testutil.Diff(results, want); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
@@ -0,0 +1,109 @@
+package sq
+
+import (
+	"context"
+	"fmt"
+)
+
+// Outer marks a field as belonging to the outer query of a correlated
+// subquery. The field is written exactly as it would be in the outer query,
+// but if a table in the subquery has the same alias (or the same name, if
+// neither table has an alias) the query fails with an error instead of
+// silently referring to the subquery's table.
+//
+//	a := sq.New[ACTOR]("")
+//	fa := sq.New[FILM_ACTOR]("")
+//	// SELECT actor.first_name FROM actor WHERE EXISTS (SELECT 1 FROM film_actor WHERE film_actor.actor_id = actor.actor_id)
+//	query := sq.
+//	    Select(a.FIRST_NAME).
+//	    From(a).
+//	    Where(sq.Exists(sq.
+//	        SelectOne().
+//	        From(fa).
+//	        Where(fa.ACTOR_ID.Eq(sq.Outer(a.ACTOR_ID))),
+//	    ))
+//
+// Outer only affects the field types of this package (including Col). Any
+// other Field is returned as-is.
+func Outer[F Field](field F) F {
+	if f, ok := any(field).(interface{ outer() Field }); ok {
+		if f, ok := f.outer().(F); ok {
+			return f
+		}
+	}
+	return field
+}
+
+func (field AnyField) outer() Field     { field.table.outer = true; return field }
+func (field ArrayField) outer() Field   { field.table.outer = true; return field }
+func (field BinaryField) outer() Field  { field.table.outer = true; return field }
+func (field BitField) outer() Field     { field.table.outer = true; return field }
+func (field BooleanField) outer() Field { field.table.outer = true; return field }
+func (field EnumField) outer() Field    { field.table.outer = true; return field }
+func (field JSONField) outer() Field    { field.table.outer = true; return field }
+func (field NumberField) outer() Field  { field.table.outer = true; return field }
+func (field StringField) outer() Field  { field.table.outer = true; return field }
+func (field TimeField) outer() Field    { field.table.outer = true; return field }
+func (field UUIDField) outer() Field    { field.table.outer = true; return field }
+func (field XMLField) outer() Field     { field.table.outer = true; return field }
+func (col Col[T]) outer() Field         { col.table.outer = true; return col }
+
+// pushScope records the tables of a query that is about to be written, so
+// that outer fields inside it can be checked against them. It must be
+// followed by a call to popScope once the query has been written.
+func (state *writeState) pushScope(joinTables []JoinTable, tables ...Table) {
+	scope := make([]Table, 0, len(tables)+len(joinTables))
+	for _, table := range tables {
+		if table != nil {
+			scope = append(scope, table)
+		}
+	}
+	for _, joinTable := range joinTables {
+		if joinTable.Table != nil {
+			scope = append(scope, joinTable.Table)
+		}
+	}
+	state.scopes = append(state.scopes, scope)
+}
+
+// popScope removes the tables recorded by the last call to pushScope.
+func (state *writeState) popScope() {
+	state.scopes = state.scopes[:len(state.scopes)-1]
+}
+
+// checkOuterField checks that the table of a field marked with Outer is not
+// also a table of the innermost query being written.
+func checkOuterField(ctx context.Context, table TableStruct, fieldName string) error {
+	state := getWriteState(ctx)
+	if state == nil || len(state.scopes) == 0 {
+		return nil
+	}
+	tableQualifier := scopeQualifier(table)
+	for _, scopeTable := range state.scopes[len(state.scopes)-1] {
+		if scopeQualifier(scopeTable) != tableQualifier {
+			continue
+		}
+		tableQualifier, err := resolveAutoAlias(ctx, tableQualifier)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("outer field %s.%s refers to a table of the subquery, give the table in the outer query or the one in the subquery a different alias", tableQualifier, fieldName)
+	}
+	return nil
+}
+
+// scopeQualifier returns the name that fields of the table are qualified
+// with: its alias, or its name if it has no alias.
+func scopeQualifier(table Table) string {
+	if alias := getAlias(table); alias != "" {
+		return alias
+	}
+	if cte, ok := table.(CTE); ok {
+		return cte.name
+	}
+	tableStruct, _, err := tableColumns(table)
+	if err != nil {
+		return ""
+	}
+	return tableStruct.name
+}
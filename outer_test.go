@@ -0,0 +1,93 @@
+package sq
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestOuter(t *testing.T) {
+	type TT struct {
+		description string
+		item        SQLWriter
+		wantQuery   string
+		wantErr     string
+	}
+
+	a := New[AUTOALIAS_ACTOR]("")
+	a2 := New[AUTOALIAS_ACTOR]("a2")
+	fa := New[FILM_ACTOR]("")
+
+	tests := []TT{{
+		description: "correlated subquery",
+		item: Postgres.
+			Select(a.FIRST_NAME).
+			From(a).
+			Where(Exists(Postgres.
+				SelectOne().
+				From(fa).
+				Where(fa.ACTOR_ID.Eq(Outer(a.ACTOR_ID))),
+			)),
+		wantQuery: "SELECT actor.first_name FROM actor" +
+			" WHERE EXISTS (SELECT 1 FROM film_actor WHERE film_actor.actor_id = actor.actor_id)",
+	}, {
+		description: "same table with different aliases",
+		item: Postgres.
+			Select(a.FIRST_NAME).
+			From(a).
+			Where(a.ACTOR_ID.In(Postgres.
+				Select(a2.ACTOR_ID).
+				From(a2).
+				Where(a2.LAST_NAME.Eq(Outer(a.LAST_NAME))),
+			)),
+		wantQuery: "SELECT actor.first_name FROM actor" +
+			" WHERE actor.actor_id IN (SELECT a2.actor_id FROM actor AS a2 WHERE a2.last_name = actor.last_name)",
+	}, {
+		description: "shadowed by the subquery",
+		item: Postgres.
+			Select(a.FIRST_NAME).
+			From(a).
+			Where(a.ACTOR_ID.In(Postgres.
+				Select(a.ACTOR_ID).
+				From(a).
+				Where(a.LAST_NAME.Eq(Outer(a.LAST_NAME))),
+			)),
+		wantErr: "outer field actor.last_name refers to a table of the subquery",
+	}, {
+		description: "shadowed by a joined table",
+		item: Postgres.
+			Select(a2.FIRST_NAME).
+			From(a2).
+			Where(Exists(Postgres.
+				SelectOne().
+				From(fa).
+				Join(a2, a2.ACTOR_ID.Eq(fa.ACTOR_ID)).
+				Where(fa.FILM_ID.Eq(Outer(a2.ACTOR_ID))),
+			)),
+		wantErr: "outer field a2.actor_id refers to a table of the subquery",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			gotQuery, _, err := ToSQL(DialectPostgres, tt.item, nil)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatal(testutil.Callers(), "expected error but got nil")
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Error(testutil.Callers(), err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(gotQuery, tt.wantQuery); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
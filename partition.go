@@ -0,0 +1,450 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PartitionedTableQuery represents a postgres CREATE TABLE ... PARTITION BY
+// statement, which creates a partitioned table.
+//
+//	CREATE TABLE [IF NOT EXISTS] table (<columns>) PARTITION BY RANGE (key)
+type PartitionedTableQuery struct {
+	Dialect           string
+	Table             Table
+	CheckNotExists    bool
+	ColumnDefinitions []string
+	// Strategy is the partitioning strategy: RANGE, LIST or HASH.
+	Strategy     string
+	PartitionKey []Field
+}
+
+var _ Query = (*PartitionedTableQuery)(nil)
+
+// CreatePartitionedTable returns a new PartitionedTableQuery. Since sq does
+// not know the types of a table's columns, the column definitions are passed
+// in as raw SQL with Columns.
+//
+//	e := sq.New[EVENT]("")
+//	q := sq.CreatePartitionedTable(e, "RANGE", e.CREATED_AT).Columns(
+//	    "event_id BIGINT NOT NULL",
+//	    "payload JSONB",
+//	    "created_at TIMESTAMPTZ NOT NULL",
+//	)
+func CreatePartitionedTable(table Table, strategy string, partitionKey ...Field) PartitionedTableQuery {
+	return PartitionedTableQuery{
+		Dialect:      DialectPostgres,
+		Table:        table,
+		Strategy:     strategy,
+		PartitionKey: partitionKey,
+	}
+}
+
+// IfNotExists adds IF NOT EXISTS to the query.
+func (q PartitionedTableQuery) IfNotExists() PartitionedTableQuery {
+	q.CheckNotExists = true
+	return q
+}
+
+// Columns sets the column definitions (and table constraints) of the table.
+func (q PartitionedTableQuery) Columns(definitions ...string) PartitionedTableQuery {
+	q.ColumnDefinitions = definitions
+	return q
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (q PartitionedTableQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if dialect != DialectPostgres {
+		return fmt.Errorf("CreatePartitionedTable: dialect %q not supported, only postgres is supported", dialect)
+	}
+	if q.Table == nil {
+		return fmt.Errorf("CreatePartitionedTable: table is nil")
+	}
+	switch strings.ToUpper(q.Strategy) {
+	case "RANGE", "LIST", "HASH":
+	default:
+		return fmt.Errorf("CreatePartitionedTable: invalid partitioning strategy %q (must be RANGE, LIST or HASH)", q.Strategy)
+	}
+	if len(q.PartitionKey) == 0 {
+		return fmt.Errorf("CreatePartitionedTable: no partition key provided")
+	}
+	if len(q.ColumnDefinitions) == 0 {
+		return fmt.Errorf("CreatePartitionedTable: no columns provided")
+	}
+	buf.WriteString("CREATE TABLE ")
+	if q.CheckNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	err := q.Table.WriteSQL(ctx, dialect, buf, args, params)
+	if err != nil {
+		return fmt.Errorf("CreatePartitionedTable: table: %w", err)
+	}
+	buf.WriteString(" (\n    " + strings.Join(q.ColumnDefinitions, "\n    ,") + "\n) PARTITION BY " + strings.ToUpper(q.Strategy) + " (")
+	err = writeFieldsWithPrefix(ctx, dialect, buf, args, params, q.PartitionKey, "", false)
+	if err != nil {
+		return fmt.Errorf("CreatePartitionedTable: partition key: %w", err)
+	}
+	buf.WriteString(")")
+	return nil
+}
+
+// SetFetchableFields implements the Query interface. It always returns false
+// as the second result.
+func (q PartitionedTableQuery) SetFetchableFields([]Field) (query Query, ok bool) {
+	return q, false
+}
+
+// GetDialect implements the Query interface.
+func (q PartitionedTableQuery) GetDialect() string { return q.Dialect }
+
+// PartitionQuery represents a postgres CREATE TABLE ... PARTITION OF
+// statement, which creates a partition of a partitioned table.
+//
+//	CREATE TABLE [IF NOT EXISTS] partition PARTITION OF parent FOR VALUES FROM (from) TO (to)
+//	CREATE TABLE [IF NOT EXISTS] partition PARTITION OF parent FOR VALUES IN (values...)
+//	CREATE TABLE [IF NOT EXISTS] partition PARTITION OF parent FOR VALUES WITH (MODULUS m, REMAINDER r)
+//	CREATE TABLE [IF NOT EXISTS] partition PARTITION OF parent DEFAULT
+//
+// Partition bounds are written into the query as literals, because postgres
+// does not accept bind parameters in DDL. A bound may also be an SQLWriter
+// such as sq.Expr("MINVALUE").
+type PartitionQuery struct {
+	Dialect        string
+	Table          Table
+	Parent         Table
+	CheckNotExists bool
+	// FOR VALUES FROM (From) TO (To)
+	From []any
+	To   []any
+	// FOR VALUES IN (In)
+	In []any
+	// FOR VALUES WITH (MODULUS Modulus, REMAINDER Remainder)
+	Modulus   int
+	Remainder int
+	// DEFAULT
+	IsDefault bool
+}
+
+var _ Query = (*PartitionQuery)(nil)
+
+// CreatePartition returns a new PartitionQuery.
+//
+//	q := sq.CreatePartition(sq.NewTableStruct("", "event_2024", ""), e).
+//	    ForValuesFrom("2024-01-01", "2025-01-01")
+func CreatePartition(table Table, parent Table) PartitionQuery {
+	return PartitionQuery{Dialect: DialectPostgres, Table: table, Parent: parent}
+}
+
+// IfNotExists adds IF NOT EXISTS to the query.
+func (q PartitionQuery) IfNotExists() PartitionQuery {
+	q.CheckNotExists = true
+	return q
+}
+
+// ForValuesFrom sets the bounds of a range partition. The lower bound is
+// inclusive and the upper bound is exclusive.
+func (q PartitionQuery) ForValuesFrom(from, to any) PartitionQuery {
+	q.From, q.To = []any{from}, []any{to}
+	return q
+}
+
+// ForValuesIn sets the values of a list partition.
+func (q PartitionQuery) ForValuesIn(values ...any) PartitionQuery {
+	q.In = values
+	return q
+}
+
+// ForValuesWith sets the modulus and remainder of a hash partition.
+func (q PartitionQuery) ForValuesWith(modulus, remainder int) PartitionQuery {
+	q.Modulus, q.Remainder = modulus, remainder
+	return q
+}
+
+// Default makes the partition the default partition.
+func (q PartitionQuery) Default() PartitionQuery {
+	q.IsDefault = true
+	return q
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (q PartitionQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if dialect != DialectPostgres {
+		return fmt.Errorf("CreatePartition: dialect %q not supported, only postgres is supported", dialect)
+	}
+	if q.Table == nil {
+		return fmt.Errorf("CreatePartition: table is nil")
+	}
+	if q.Parent == nil {
+		return fmt.Errorf("CreatePartition: parent is nil")
+	}
+	buf.WriteString("CREATE TABLE ")
+	if q.CheckNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	err := q.Table.WriteSQL(ctx, dialect, buf, args, params)
+	if err != nil {
+		return fmt.Errorf("CreatePartition: table: %w", err)
+	}
+	buf.WriteString(" PARTITION OF ")
+	err = q.Parent.WriteSQL(ctx, dialect, buf, args, params)
+	if err != nil {
+		return fmt.Errorf("CreatePartition: parent: %w", err)
+	}
+	switch {
+	case q.IsDefault:
+		buf.WriteString(" DEFAULT")
+	case len(q.From) > 0 || len(q.To) > 0:
+		if len(q.From) != len(q.To) {
+			return fmt.Errorf("CreatePartition: got %d FROM values but %d TO values", len(q.From), len(q.To))
+		}
+		buf.WriteString(" FOR VALUES FROM (")
+		err = writeLiterals(ctx, dialect, buf, q.From)
+		if err != nil {
+			return fmt.Errorf("CreatePartition: FROM: %w", err)
+		}
+		buf.WriteString(") TO (")
+		err = writeLiterals(ctx, dialect, buf, q.To)
+		if err != nil {
+			return fmt.Errorf("CreatePartition: TO: %w", err)
+		}
+		buf.WriteString(")")
+	case len(q.In) > 0:
+		buf.WriteString(" FOR VALUES IN (")
+		err = writeLiterals(ctx, dialect, buf, q.In)
+		if err != nil {
+			return fmt.Errorf("CreatePartition: IN: %w", err)
+		}
+		buf.WriteString(")")
+	case q.Modulus > 0:
+		if q.Remainder < 0 || q.Remainder >= q.Modulus {
+			return fmt.Errorf("CreatePartition: remainder %d must be between 0 and modulus %d", q.Remainder, q.Modulus)
+		}
+		buf.WriteString(fmt.Sprintf(" FOR VALUES WITH (MODULUS %d, REMAINDER %d)", q.Modulus, q.Remainder))
+	default:
+		return fmt.Errorf("CreatePartition: no partition bounds provided")
+	}
+	return nil
+}
+
+// SetFetchableFields implements the Query interface. It always returns false
+// as the second result.
+func (q PartitionQuery) SetFetchableFields([]Field) (query Query, ok bool) {
+	return q, false
+}
+
+// GetDialect implements the Query interface.
+func (q PartitionQuery) GetDialect() string { return q.Dialect }
+
+// writeLiterals writes a comma separated list of values as SQL literals.
+func writeLiterals(ctx context.Context, dialect string, buf *bytes.Buffer, values []any) error {
+	for i, value := range values {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if w, ok := value.(SQLWriter); ok {
+			var literalArgs []any
+			err := w.WriteSQL(ctx, dialect, buf, &literalArgs, nil)
+			if err != nil {
+				return fmt.Errorf("value #%d: %w", i+1, err)
+			}
+			if len(literalArgs) > 0 {
+				return fmt.Errorf("value #%d: cannot contain args", i+1)
+			}
+			continue
+		}
+		s, err := Sprint(dialect, value)
+		if err != nil {
+			return fmt.Errorf("value #%d: %w", i+1, err)
+		}
+		buf.WriteString(s)
+	}
+	return nil
+}
+
+// PartitionInterval is the length of time covered by each partition created
+// by TimePartitions.
+type PartitionInterval int
+
+const (
+	PartitionByDay PartitionInterval = iota + 1
+	PartitionByWeek
+	PartitionByMonth
+	PartitionByYear
+)
+
+// start returns the start of the interval containing t (in UTC). Weeks start
+// on Monday.
+func (interval PartitionInterval) start(t time.Time) time.Time {
+	t = t.UTC()
+	switch interval {
+	case PartitionByDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case PartitionByWeek:
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case PartitionByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// next returns the start of the interval after the one starting at t.
+func (interval PartitionInterval) next(t time.Time) time.Time {
+	switch interval {
+	case PartitionByDay:
+		return t.AddDate(0, 0, 1)
+	case PartitionByWeek:
+		return t.AddDate(0, 0, 7)
+	case PartitionByMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(1, 0, 0)
+	}
+}
+
+// layout returns the time layout used for partition name suffixes.
+func (interval PartitionInterval) layout() string {
+	switch interval {
+	case PartitionByDay, PartitionByWeek:
+		return "20060102"
+	case PartitionByMonth:
+		return "200601"
+	default:
+		return "2006"
+	}
+}
+
+func (interval PartitionInterval) valid() bool {
+	return interval >= PartitionByDay && interval <= PartitionByYear
+}
+
+// TimePartitions returns the CREATE TABLE IF NOT EXISTS ... PARTITION OF
+// queries for the partitions of a range partitioned table covering the time
+// range from from to to. Each partition covers one interval and is named
+// after the parent table and the start of its interval, e.g. event_20240101
+// (day or week), event_202401 (month) or event_2024 (year). Interval
+// boundaries are computed in UTC.
+func TimePartitions(parent Table, interval PartitionInterval, from, to time.Time) ([]PartitionQuery, error) {
+	if !interval.valid() {
+		return nil, fmt.Errorf("invalid partition interval %d", interval)
+	}
+	parentStruct, _, err := tableColumns(parent)
+	if err != nil {
+		return nil, err
+	}
+	var queries []PartitionQuery
+	for start := interval.start(from); !start.After(interval.start(to)); start = interval.next(start) {
+		end := interval.next(start)
+		name := parentStruct.name + "_" + start.Format(interval.layout())
+		if err := ValidateIdentifier(DialectPostgres, name); err != nil {
+			return nil, err
+		}
+		queries = append(queries, CreatePartition(TableStruct{schema: parentStruct.schema, name: name}, parentStruct).
+			IfNotExists().
+			ForValuesFrom(start, end),
+		)
+	}
+	return queries, nil
+}
+
+// EnsureTimePartitions creates any missing partitions of a range partitioned
+// table covering the time range from from to to (see TimePartitions). It is
+// meant to be called on a schedule (e.g. daily) to create partitions ahead of
+// time.
+//
+//	// Make sure the partitions for this month and the next three months exist.
+//	err := sq.EnsureTimePartitions(db, e, sq.PartitionByMonth, now, now.AddDate(0, 3, 0))
+func EnsureTimePartitions(db DB, parent Table, interval PartitionInterval, from, to time.Time) error {
+	return ensureTimePartitions(context.Background(), db, parent, interval, from, to, 1)
+}
+
+// EnsureTimePartitionsContext is like EnsureTimePartitions but additionally
+// requires a context.Context.
+func EnsureTimePartitionsContext(ctx context.Context, db DB, parent Table, interval PartitionInterval, from, to time.Time) error {
+	return ensureTimePartitions(ctx, db, parent, interval, from, to, 1)
+}
+
+func ensureTimePartitions(ctx context.Context, db DB, parent Table, interval PartitionInterval, from, to time.Time, skip int) error {
+	if db == nil {
+		return fmt.Errorf("db is nil")
+	}
+	queries, err := TimePartitions(parent, interval, from, to)
+	if err != nil {
+		return err
+	}
+	for _, query := range queries {
+		_, err = exec(ctx, db, query, skip+1)
+		if err != nil {
+			return fmt.Errorf("%s: %w", query.Table.(TableStruct).name, err)
+		}
+	}
+	return nil
+}
+
+// DropTimePartitions drops the partitions of a range partitioned table (as
+// created by TimePartitions) that only contain times before the cutoff. It
+// returns the names of the dropped partitions. Partitions are identified by
+// their names, partitions not following the naming scheme of TimePartitions
+// are left alone.
+//
+//	// Keep 90 days of data.
+//	dropped, err := sq.DropTimePartitions(db, e, sq.PartitionByDay, time.Now().AddDate(0, 0, -90))
+func DropTimePartitions(db DB, parent Table, interval PartitionInterval, cutoff time.Time) (dropped []string, err error) {
+	return dropTimePartitions(context.Background(), db, parent, interval, cutoff, 1)
+}
+
+// DropTimePartitionsContext is like DropTimePartitions but additionally
+// requires a context.Context.
+func DropTimePartitionsContext(ctx context.Context, db DB, parent Table, interval PartitionInterval, cutoff time.Time) (dropped []string, err error) {
+	return dropTimePartitions(ctx, db, parent, interval, cutoff, 1)
+}
+
+func dropTimePartitions(ctx context.Context, db DB, parent Table, interval PartitionInterval, cutoff time.Time, skip int) (dropped []string, err error) {
+	if db == nil {
+		return nil, fmt.Errorf("db is nil")
+	}
+	if !interval.valid() {
+		return nil, fmt.Errorf("invalid partition interval %d", interval)
+	}
+	parentStruct, _, err := tableColumns(parent)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := fetchCursor(ctx, db, Postgres.Queryf(
+		"SELECT {*} FROM pg_inherits JOIN pg_class ON pg_class.oid = pg_inherits.inhrelid"+
+			" WHERE pg_inherits.inhparent = CAST({} AS regclass) ORDER BY pg_class.relname",
+		toString(DialectPostgres, parentStruct),
+	), func(row *Row) string {
+		return row.String("pg_class.relname")
+	}, skip+1)
+	if err != nil {
+		return nil, err
+	}
+	names, err := cursorResults(cursor)
+	cursor.Close()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, parentStruct.name+"_") {
+			continue
+		}
+		start, err := time.Parse(interval.layout(), strings.TrimPrefix(name, parentStruct.name+"_"))
+		if err != nil || !start.Equal(interval.start(start)) {
+			continue
+		}
+		if interval.next(start).After(cutoff) {
+			continue
+		}
+		_, err = exec(ctx, db, Postgres.Queryf("DROP TABLE IF EXISTS {}", TableStruct{schema: parentStruct.schema, name: name}), skip+1)
+		if err != nil {
+			return dropped, fmt.Errorf("%s: %w", name, err)
+		}
+		dropped = append(dropped, name)
+	}
+	return dropped, nil
+}
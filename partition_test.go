@@ -0,0 +1,159 @@
+package sq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+type EVENT struct {
+	TableStruct
+	EVENT_ID   NumberField
+	KIND       StringField
+	CREATED_AT TimeField
+}
+
+func TestCreatePartitionedTable(t *testing.T) {
+	e := New[EVENT]("")
+
+	tests := []TestTable{{
+		description: "range",
+		item: CreatePartitionedTable(e, "range", e.CREATED_AT).IfNotExists().Columns(
+			"event_id BIGINT NOT NULL",
+			"kind TEXT NOT NULL",
+			"created_at TIMESTAMPTZ NOT NULL",
+		),
+		wantQuery: "CREATE TABLE IF NOT EXISTS event (" +
+			"\n    event_id BIGINT NOT NULL" +
+			"\n    ,kind TEXT NOT NULL" +
+			"\n    ,created_at TIMESTAMPTZ NOT NULL" +
+			"\n) PARTITION BY RANGE (created_at)",
+	}, {
+		description: "range partition",
+		item: CreatePartition(NewTableStruct("", "event_2024", ""), e).
+			ForValuesFrom(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		wantQuery: "CREATE TABLE event_2024 PARTITION OF event" +
+			" FOR VALUES FROM ('2024-01-01 00:00:00+00:00') TO ('2025-01-01 00:00:00+00:00')",
+	}, {
+		description: "MINVALUE",
+		item:        CreatePartition(NewTableStruct("", "event_old", ""), e).ForValuesFrom(Expr("MINVALUE"), "2024-01-01"),
+		wantQuery:   "CREATE TABLE event_old PARTITION OF event FOR VALUES FROM (MINVALUE) TO ('2024-01-01')",
+	}, {
+		description: "list partition",
+		item:        CreatePartition(NewTableStruct("", "event_click", ""), e).IfNotExists().ForValuesIn("click", "tap"),
+		wantQuery:   "CREATE TABLE IF NOT EXISTS event_click PARTITION OF event FOR VALUES IN ('click', 'tap')",
+	}, {
+		description: "hash partition",
+		item:        CreatePartition(NewTableStruct("", "event_0", ""), e).ForValuesWith(4, 0),
+		wantQuery:   "CREATE TABLE event_0 PARTITION OF event FOR VALUES WITH (MODULUS 4, REMAINDER 0)",
+	}, {
+		description: "default partition",
+		item:        CreatePartition(NewTableStruct("", "event_default", ""), e).Default(),
+		wantQuery:   "CREATE TABLE event_default PARTITION OF event DEFAULT",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.dialect = DialectPostgres
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "unsupported dialect",
+		dialect:     DialectSQLite,
+		item:        CreatePartition(NewTableStruct("", "event_default", ""), e).Default(),
+	}, {
+		description: "invalid strategy",
+		dialect:     DialectPostgres,
+		item:        CreatePartitionedTable(e, "RANDOM", e.CREATED_AT).Columns("created_at TIMESTAMPTZ"),
+	}, {
+		description: "no bounds",
+		dialect:     DialectPostgres,
+		item:        CreatePartition(NewTableStruct("", "event_2024", ""), e),
+	}, {
+		description: "args in bounds",
+		dialect:     DialectPostgres,
+		item:        CreatePartition(NewTableStruct("", "event_2024", ""), e).ForValuesIn(Value("click")),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+}
+
+func TestTimePartitions(t *testing.T) {
+	e := New[EVENT]("")
+	type TestTable struct {
+		description string
+		interval    PartitionInterval
+		from, to    time.Time
+		wantQueries []string
+	}
+
+	tests := []TestTable{{
+		description: "day",
+		interval:    PartitionByDay,
+		from:        time.Date(2024, 2, 28, 13, 0, 0, 0, time.UTC),
+		to:          time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		wantQueries: []string{
+			"CREATE TABLE IF NOT EXISTS event_20240228 PARTITION OF event FOR VALUES FROM ('2024-02-28 00:00:00+00:00') TO ('2024-02-29 00:00:00+00:00')",
+			"CREATE TABLE IF NOT EXISTS event_20240229 PARTITION OF event FOR VALUES FROM ('2024-02-29 00:00:00+00:00') TO ('2024-03-01 00:00:00+00:00')",
+			"CREATE TABLE IF NOT EXISTS event_20240301 PARTITION OF event FOR VALUES FROM ('2024-03-01 00:00:00+00:00') TO ('2024-03-02 00:00:00+00:00')",
+		},
+	}, {
+		description: "week",
+		interval:    PartitionByWeek,
+		from:        time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), // Wednesday
+		to:          time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		wantQueries: []string{
+			"CREATE TABLE IF NOT EXISTS event_20240101 PARTITION OF event FOR VALUES FROM ('2024-01-01 00:00:00+00:00') TO ('2024-01-08 00:00:00+00:00')",
+		},
+	}, {
+		description: "month",
+		interval:    PartitionByMonth,
+		from:        time.Date(2024, 12, 15, 0, 0, 0, 0, time.UTC),
+		to:          time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		wantQueries: []string{
+			"CREATE TABLE IF NOT EXISTS event_202412 PARTITION OF event FOR VALUES FROM ('2024-12-01 00:00:00+00:00') TO ('2025-01-01 00:00:00+00:00')",
+			"CREATE TABLE IF NOT EXISTS event_202501 PARTITION OF event FOR VALUES FROM ('2025-01-01 00:00:00+00:00') TO ('2025-02-01 00:00:00+00:00')",
+		},
+	}, {
+		description: "year",
+		interval:    PartitionByYear,
+		from:        time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		to:          time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		wantQueries: []string{
+			"CREATE TABLE IF NOT EXISTS event_2024 PARTITION OF event FOR VALUES FROM ('2024-01-01 00:00:00+00:00') TO ('2025-01-01 00:00:00+00:00')",
+		},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			queries, err := TimePartitions(e, tt.interval, tt.from, tt.to)
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			var gotQueries []string
+			for _, query := range queries {
+				gotQuery, _, err := ToSQL(DialectPostgres, query, nil)
+				if err != nil {
+					t.Fatal(testutil.Callers(), err)
+				}
+				gotQueries = append(gotQueries, gotQuery)
+			}
+			if diff := testutil.Diff(gotQueries, tt.wantQueries); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// PivotQuery represents a pivot (crosstab) query. It turns the distinct
+// values of the PivotField into columns: for every one of the PivotValues, a
+// column of the same name is selected that aggregates the ValueField over the
+// rows whose PivotField is equal to that value. The rows of the pivot are
+// identified by the GROUP BY fields of the BaseQuery.
+//
+// It is rendered as a SELECT with one aggregate(CASE WHEN ...) column per
+// pivot value, or as a native PIVOT on SQL Server.
+//
+// A PivotQuery is a static query (like Queryf) because its columns are only
+// known at runtime, so its rows are accessed by column name e.g.
+// row.Float64("Q1"). PivotRowMapper maps each row to a map of column names to
+// values.
+type PivotQuery struct {
+	// BaseQuery is the query whose rows are pivoted. It must be a
+	// SelectQuery (or a dialect-specific SelectQuery) with GROUP BY fields.
+	// Its SELECT fields are ignored.
+	BaseQuery Query
+	// PivotField is the field whose values become columns.
+	PivotField Field
+	// ValueField is the field that is aggregated in each column.
+	ValueField Field
+	// AggregateFunction is the aggregate function applied to the ValueField.
+	// Defaults to SUM.
+	AggregateFunction string
+	// PivotValues are the values of the PivotField that become columns.
+	PivotValues []any
+}
+
+var _ Query = (*PivotQuery)(nil)
+
+// Pivot returns a new PivotQuery.
+//
+//	// SELECT sales.region, SUM(CASE WHEN sales.quarter = 'Q1' THEN sales.amount END) AS Q1, ...
+//	// FROM sales
+//	// GROUP BY sales.region
+//	sq.Pivot(sq.From(s).GroupBy(s.REGION), s.QUARTER, s.AMOUNT, "Q1", "Q2", "Q3", "Q4")
+func Pivot(baseQuery Query, pivotField, valueField Field, pivotValues ...any) PivotQuery {
+	return PivotQuery{
+		BaseQuery:   baseQuery,
+		PivotField:  pivotField,
+		ValueField:  valueField,
+		PivotValues: pivotValues,
+	}
+}
+
+// Aggregate sets the aggregate function (e.g. SUM, COUNT, MAX) applied to the
+// ValueField.
+func (q PivotQuery) Aggregate(function string) PivotQuery {
+	q.AggregateFunction = function
+	return q
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (q PivotQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	var baseQuery SelectQuery
+	switch query := q.BaseQuery.(type) {
+	case SelectQuery:
+		baseQuery = query
+	case SQLiteSelectQuery:
+		baseQuery = SelectQuery(query)
+	case PostgresSelectQuery:
+		baseQuery = SelectQuery(query)
+	case MySQLSelectQuery:
+		baseQuery = SelectQuery(query)
+	case SQLServerSelectQuery:
+		baseQuery = SelectQuery(query)
+	case nil:
+		return fmt.Errorf("pivot: base query is nil")
+	default:
+		return fmt.Errorf("pivot: base query (%T) is not a SELECT query", q.BaseQuery)
+	}
+	if len(baseQuery.GroupByFields) == 0 {
+		return fmt.Errorf("pivot: base query has no GROUP BY fields")
+	}
+	if q.PivotField == nil {
+		return fmt.Errorf("pivot: pivot field is nil")
+	}
+	if q.ValueField == nil {
+		return fmt.Errorf("pivot: value field is nil")
+	}
+	if len(q.PivotValues) == 0 {
+		return fmt.Errorf("pivot: no pivot values provided")
+	}
+	function := q.AggregateFunction
+	if function == "" {
+		function = "SUM"
+	}
+	for _, char := range function {
+		if char != '_' && (char < 'a' || char > 'z') && (char < 'A' || char > 'Z') {
+			return fmt.Errorf("pivot: invalid aggregate function %q", function)
+		}
+	}
+	columns := make([]string, len(q.PivotValues))
+	for i, value := range q.PivotValues {
+		columns[i] = fmt.Sprint(value)
+	}
+	// SQL Server's PIVOT can't be combined with HAVING, TOP or OFFSET, so
+	// those queries fall back to the CASE-based pivot.
+	if dialect == DialectSQLServer && baseQuery.HavingPredicate == nil &&
+		baseQuery.LimitTop == nil && baseQuery.LimitTopPercent == nil &&
+		baseQuery.OffsetRows == nil && baseQuery.FetchNextRows == nil {
+		return q.writeSQLServerPivot(ctx, dialect, buf, args, params, baseQuery, function, columns)
+	}
+	baseQuery.SelectFields = append(make([]Field, 0, len(baseQuery.GroupByFields)+len(columns)), baseQuery.GroupByFields...)
+	for i, value := range q.PivotValues {
+		baseQuery.SelectFields = append(baseQuery.SelectFields, Expr(function+"(CASE WHEN {} = {} THEN {} END)", q.PivotField, value, q.ValueField).As(columns[i]))
+	}
+	return baseQuery.WriteSQL(ctx, dialect, buf, args, params)
+}
+
+// writeSQLServerPivot writes a native SQL Server PIVOT:
+//
+//	SELECT * FROM (SELECT <group by fields>, <pivot field> AS pivot_column, <value field> AS pivot_value FROM ...) AS pivot_source
+//	PIVOT (SUM(pivot_value) FOR pivot_column IN ([value1], [value2], ...)) AS pivot_table
+//	ORDER BY ...
+func (q PivotQuery) writeSQLServerPivot(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int, baseQuery SelectQuery, function string, columns []string) error {
+	orderByFields := baseQuery.OrderByFields
+	baseQuery.OrderByFields = nil
+	baseQuery.SelectFields = append(make([]Field, 0, len(baseQuery.GroupByFields)+2), baseQuery.GroupByFields...)
+	baseQuery.SelectFields = append(baseQuery.SelectFields, Expr("{}", q.PivotField).As("pivot_column"), Expr("{}", q.ValueField).As("pivot_value"))
+	baseQuery.GroupByFields = nil
+	buf.WriteString("SELECT * FROM (")
+	err := baseQuery.WriteSQL(ctx, dialect, buf, args, params)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(") AS pivot_source PIVOT (" + function + "(pivot_value) FOR pivot_column IN (")
+	for i, column := range columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(quoteIdentifier(dialect, column))
+	}
+	buf.WriteString(")) AS pivot_table")
+	if len(orderByFields) > 0 {
+		buf.WriteString(" ORDER BY ")
+		err = writeFieldsWithPrefix(ctx, dialect, buf, args, params, orderByFields, "pivot_table", false)
+		if err != nil {
+			return fmt.Errorf("ORDER BY: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetFetchableFields implements the Query interface. It always returns false
+// as the columns of a PivotQuery are fixed.
+func (q PivotQuery) SetFetchableFields(fields []Field) (query Query, ok bool) {
+	return q, false
+}
+
+// GetDialect implements the Query interface.
+func (q PivotQuery) GetDialect() string {
+	if q.BaseQuery == nil {
+		return ""
+	}
+	return q.BaseQuery.GetDialect()
+}
+
+// PivotRowMapper maps a row of a PivotQuery (or any other static query) to a
+// map of column names to values. The underlying type of each value is
+// determined by the database driver.
+//
+//	rows, err := sq.FetchAll(db, pivotQuery, sq.PivotRowMapper)
+func PivotRowMapper(row *Row) map[string]any {
+	columns, values := row.Columns(), row.Values()
+	m := make(map[string]any, len(columns))
+	for i, column := range columns {
+		m[column] = values[i]
+	}
+	return m
+}
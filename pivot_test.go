@@ -0,0 +1,140 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+var SALES = New[struct {
+	TableStruct `sq:"sales"`
+	REGION      StringField
+	QUARTER     StringField
+	AMOUNT      NumberField
+}]("")
+
+func TestPivot(t *testing.T) {
+	tests := []TestTable{{
+		description: "basic",
+		dialect:     DialectPostgres,
+		item: Pivot(
+			From(SALES).Where(SALES.AMOUNT.GtInt(0)).GroupBy(SALES.REGION).OrderBy(SALES.REGION),
+			SALES.QUARTER, SALES.AMOUNT, "Q1", "Q2",
+		),
+		wantQuery: "SELECT sales.region" +
+			", SUM(CASE WHEN sales.quarter = $1 THEN sales.amount END) AS \"Q1\"" +
+			", SUM(CASE WHEN sales.quarter = $2 THEN sales.amount END) AS \"Q2\"" +
+			" FROM sales WHERE sales.amount > $3 GROUP BY sales.region ORDER BY sales.region",
+		wantArgs: []any{"Q1", "Q2", 0},
+	}, {
+		description: "Aggregate",
+		dialect:     DialectSQLite,
+		item:        Pivot(SQLite.From(SALES).GroupBy(SALES.REGION), SALES.QUARTER, SALES.AMOUNT, "q1").Aggregate("MAX"),
+		wantQuery:   "SELECT sales.region, MAX(CASE WHEN sales.quarter = $1 THEN sales.amount END) AS q1 FROM sales GROUP BY sales.region",
+		wantArgs:    []any{"q1"},
+	}, {
+		description: "sqlserver",
+		dialect:     DialectSQLServer,
+		item: Pivot(
+			SQLServer.From(SALES).Where(SALES.AMOUNT.GtInt(0)).GroupBy(SALES.REGION).OrderBy(SALES.REGION),
+			SALES.QUARTER, SALES.AMOUNT, "Q1", "Q2",
+		),
+		wantQuery: "SELECT * FROM (SELECT sales.region, sales.quarter AS pivot_column, sales.amount AS pivot_value" +
+			" FROM sales WHERE sales.amount > @p1) AS pivot_source" +
+			" PIVOT (SUM(pivot_value) FOR pivot_column IN ([Q1], [Q2])) AS pivot_table" +
+			" ORDER BY pivot_table.region",
+		wantArgs: []any{0},
+	}, {
+		description: "sqlserver HAVING",
+		dialect:     DialectSQLServer,
+		item: Pivot(
+			From(SALES).GroupBy(SALES.REGION).Having(Expr("COUNT(*) > 1")),
+			SALES.QUARTER, SALES.AMOUNT, "Q1",
+		),
+		wantQuery: "SELECT sales.region, SUM(CASE WHEN sales.quarter = @p1 THEN sales.amount END) AS [Q1]" +
+			" FROM sales GROUP BY sales.region HAVING COUNT(*) > 1",
+		wantArgs: []any{"Q1"},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "nil base query",
+		item:        Pivot(nil, SALES.QUARTER, SALES.AMOUNT, "Q1"),
+	}, {
+		description: "non-SELECT base query",
+		item:        Pivot(DeleteFrom(SALES), SALES.QUARTER, SALES.AMOUNT, "Q1"),
+	}, {
+		description: "no GROUP BY",
+		item:        Pivot(From(SALES), SALES.QUARTER, SALES.AMOUNT, "Q1"),
+	}, {
+		description: "no pivot values",
+		item:        Pivot(From(SALES).GroupBy(SALES.REGION), SALES.QUARTER, SALES.AMOUNT),
+	}, {
+		description: "invalid aggregate function",
+		item:        Pivot(From(SALES).GroupBy(SALES.REGION), SALES.QUARTER, SALES.AMOUNT, "Q1").Aggregate("SUM(1); --"),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := db.Exec("CREATE TABLE sales (region TEXT, quarter TEXT, amount INT)")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = Exec(db, SQLite.
+			InsertInto(SALES).
+			Columns(SALES.REGION, SALES.QUARTER, SALES.AMOUNT).
+			Values("east", "Q1", 10).
+			Values("east", "Q1", 5).
+			Values("east", "Q2", 7).
+			Values("west", "Q2", 3),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		query := Pivot(SQLite.From(SALES).GroupBy(SALES.REGION).OrderBy(SALES.REGION), SALES.QUARTER, SALES.AMOUNT, "Q1", "Q2")
+		rows, err := FetchAll(db, query, PivotRowMapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		wantRows := []map[string]any{
+			{"region": "east", "Q1": int64(15), "Q2": int64(7)},
+			{"region": "west", "Q1": nil, "Q2": int64(3)},
+		}
+		if diff := testutil.Diff(rows, wantRows); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		type Sales struct {
+			Region string
+			Q1, Q2 int
+		}
+		sales, err := FetchAll(db, query, func(row *Row) Sales {
+			return Sales{
+				Region: row.String("region"),
+				Q1:     row.Int("Q1"),
+				Q2:     row.Int("Q2"),
+			}
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(sales, []Sales{{"east", 15, 7}, {"west", 0, 3}}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
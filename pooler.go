@@ -0,0 +1,51 @@
+package sq
+
+import (
+	"errors"
+	"strings"
+)
+
+// WithoutPreparedStatements wraps a DB such that CompiledFetch.Prepare,
+// CompiledExec.Prepare, PrepareFetch and PrepareExec don't create prepared
+// statements on it. Instead, the PreparedFetch or PreparedExec runs its query
+// directly on the DB every time, which drivers send as an unnamed statement
+// that lives only as long as the query. Use it for databases behind a
+// connection pooler running in transaction pooling mode (e.g. pgbouncer),
+// where a named prepared statement created on one server connection is
+// missing from the next.
+//
+// PreparedFetch and PreparedExec also detect such poolers on their own (the
+// statement goes missing right after being prepared, or Postgres reports that
+// it already exists) and fall back to running their query directly, but
+// wrapping the DB avoids the failed attempts.
+//
+//	db = sq.WithoutPreparedStatements(db)
+//	preparedFetch, err := compiledFetch.Prepare(db)
+func WithoutPreparedStatements(db DB) DB {
+	return withOption(db, unprepared{})
+}
+
+// unprepared is the option of a DB wrapped by WithoutPreparedStatements.
+type unprepared struct{}
+
+// preparedStatementsDisabled reports whether a DB is wrapped by
+// WithoutPreparedStatements.
+func preparedStatementsDisabled(db DB) bool {
+	_, ok := dbOption[unprepared](db)
+	return ok
+}
+
+// isPoolerError reports whether an error is one that only happens when
+// prepared statements are used behind a transaction pooler: Postgres
+// complaining that a freshly prepared statement already exists because
+// another client prepared a statement with the same name on the same server
+// connection.
+func isPoolerError(err error) bool {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if getDriverErrorCode(e).sqlState == "42P05" { // postgres duplicate_prepared_statement
+			return true
+		}
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "prepared statement") && strings.Contains(msg, "already exists")
+}
@@ -0,0 +1,141 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+// poolerDB is a DB that simulates a transaction pooler: every statement is
+// prepared on a connection that is gone by the time the statement is used.
+type poolerDB struct {
+	*sql.DB
+	prepareCount int
+}
+
+func (db *poolerDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	db.prepareCount++
+	conn, err := db.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.PrepareContext(ctx, query)
+}
+
+func TestWithoutPreparedStatements(t *testing.T) {
+	t.Parallel()
+	query := SQLite.From(ACTOR).Where(ACTOR.ACTOR_ID.Eq(IntParam("actor_id", 0)))
+	rowmapper := func(row *Row) string { return row.StringField(ACTOR.FIRST_NAME) }
+
+	t.Run("wrapped", func(t *testing.T) {
+		t.Parallel()
+		recorder := &queryStatsRecorder{}
+		pooler := &poolerDB{DB: newDB(t)}
+		db := WithoutPreparedStatements(struct {
+			DB
+			SqLogger
+		}{DB: pooler, SqLogger: recorder})
+		if _, ok := db.(SqLogger); !ok {
+			t.Fatal(testutil.Callers(), "expected the DB to remain a SqLogger")
+		}
+		preparedExec, err := PrepareExec(db, SQLite.
+			InsertInto(ACTOR).
+			Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME, ACTOR.LAST_UPDATE).
+			Values(IntParam("actor_id", 0), "PENELOPE", "GUINESS", time.Unix(1, 0).UTC()),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = preparedExec.Exec(Params{"actor_id": 1})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		preparedFetch, err := PrepareFetch(db, query, rowmapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		firstName, err := preparedFetch.FetchOne(Params{"actor_id": 1})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(firstName, "PENELOPE"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(pooler.prepareCount, 0); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(len(recorder.queryStats), 2); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("detected", func(t *testing.T) {
+		t.Parallel()
+		pooler := &poolerDB{DB: newDB(t)}
+		_, err := Exec(pooler, InsertInto(ACTOR).
+			Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME, ACTOR.LAST_UPDATE).
+			Values(1, "PENELOPE", "GUINESS", time.Unix(1, 0).UTC()),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		preparedFetch, err := PrepareFetch(pooler, query, rowmapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		for i := 0; i < 2; i++ {
+			firstName, err := preparedFetch.FetchOne(Params{"actor_id": 1})
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(firstName, "PENELOPE"); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		}
+		// Prepared once by PrepareFetch and once more when the statement
+		// went missing, after which the query is no longer prepared.
+		if diff := testutil.Diff(pooler.prepareCount, 2); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
+
+func TestIsPoolerError(t *testing.T) {
+	type TestTable struct {
+		description string
+		err         error
+		want        bool
+	}
+
+	tests := []TestTable{{
+		description: "postgres duplicate prepared statement",
+		err:         &fakePQError{Code: "42P05"},
+		want:        true,
+	}, {
+		description: "pgx duplicate prepared statement",
+		err:         &fakePgxError{code: "42P05"},
+		want:        true,
+	}, {
+		description: "message",
+		err:         errors.New(`ERROR: prepared statement "stmtcache_1" already exists`),
+		want:        true,
+	}, {
+		description: "unique violation",
+		err:         &fakePQError{Code: "23505"},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			if diff := testutil.Diff(isPoolerError(tt.err), tt.want); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
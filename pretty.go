@@ -0,0 +1,246 @@
+package sq
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// prettyIndent is the indentation of each level of subquery.
+const prettyIndent = "    "
+
+// SprintfPretty is like Sprintf but additionally formats the query with every
+// clause (SELECT, FROM, JOIN, WHERE, GROUP BY, ORDER BY, etc) on its own line
+// and subqueries indented inside their parentheses. It is meant for making
+// long queries readable in logs and test diffs, the formatted query is
+// equivalent to the original query.
+//
+//	query, args, _ := sq.ToSQL(sq.DialectPostgres, q, nil)
+//	s, err := sq.SprintfPretty(sq.DialectPostgres, query, args)
+//	// SELECT a.actor_id
+//	// FROM actor AS a
+//	// JOIN film_actor AS fa ON fa.actor_id = a.actor_id
+//	// WHERE a.actor_id IN (
+//	//     SELECT actor_id
+//	//     FROM banned_actor
+//	// )
+func SprintfPretty(dialect string, query string, args []any) (string, error) {
+	query, err := Sprintf(dialect, query, args)
+	if err != nil {
+		return "", err
+	}
+	return prettySQL(query), nil
+}
+
+type prettyTokenKind int
+
+const (
+	prettySpace prettyTokenKind = iota
+	prettyWord
+	prettyOpen
+	prettyClose
+	prettyLineComment
+	prettyOther
+)
+
+type prettyToken struct {
+	kind prettyTokenKind
+	text string
+}
+
+// prettyTokenize splits a query into words, parentheses, whitespace and
+// everything else. String literals, quoted identifiers and comments are kept
+// whole so that their contents are never mistaken for keywords.
+func prettyTokenize(query string) []prettyToken {
+	var tokens []prettyToken
+	for len(query) > 0 {
+		char, size := utf8.DecodeRuneInString(query)
+		n := size
+		kind := prettyOther
+		switch {
+		case unicode.IsSpace(char):
+			kind = prettySpace
+			for n < len(query) {
+				char, size := utf8.DecodeRuneInString(query[n:])
+				if !unicode.IsSpace(char) {
+					break
+				}
+				n += size
+			}
+		case char == '_' || unicode.IsLetter(char) || unicode.IsDigit(char):
+			kind = prettyWord
+			for n < len(query) {
+				char, size := utf8.DecodeRuneInString(query[n:])
+				if char != '_' && !unicode.IsLetter(char) && !unicode.IsDigit(char) {
+					break
+				}
+				n += size
+			}
+		case char == '(':
+			kind = prettyOpen
+		case char == ')':
+			kind = prettyClose
+		case char == '\'' || char == '"' || char == '`' || char == '[':
+			end := byte(char)
+			if char == '[' {
+				end = ']'
+			}
+			for n < len(query) {
+				if query[n] != end {
+					n++
+					continue
+				}
+				n++
+				// A doubled quote is an escaped quote.
+				if n < len(query) && query[n] == end && end != ']' {
+					n++
+					continue
+				}
+				break
+			}
+		case strings.HasPrefix(query, "--"):
+			kind = prettyLineComment
+			n = strings.IndexByte(query, '\n')
+			if n < 0 {
+				n = len(query)
+			}
+		case strings.HasPrefix(query, "/*"):
+			n = strings.Index(query[2:], "*/")
+			if n < 0 {
+				n = len(query)
+			} else {
+				n += 4
+			}
+		}
+		tokens = append(tokens, prettyToken{kind: kind, text: query[:n]})
+		query = query[n:]
+	}
+	return tokens
+}
+
+// prettySQL puts every clause of the query on its own line and indents
+// subqueries. Clauses are only recognized at the top level of the query or of
+// a subquery, so that e.g. the FROM of EXTRACT(YEAR FROM t) or the ORDER BY of
+// a window definition stay where they are.
+func prettySQL(query string) string {
+	tokens := prettyTokenize(strings.TrimSpace(query))
+	var buf strings.Builder
+	buf.Grow(len(query) + len(query)/8)
+	var depth int
+	// subquery records, for each open parenthesis, whether it encloses a
+	// subquery.
+	var subquery []bool
+	atLineStart, pendingSpace := true, false
+	newline := func() {
+		buf.WriteString("\n" + strings.Repeat(prettyIndent, depth))
+		atLineStart, pendingSpace = true, false
+	}
+	// nextWord returns the uppercased word after the token at index i, or
+	// an empty string if the next non-space token is not a word.
+	nextWord := func(i int) string {
+		for i++; i < len(tokens) && tokens[i].kind == prettySpace; i++ {
+		}
+		if i >= len(tokens) || tokens[i].kind != prettyWord {
+			return ""
+		}
+		return strings.ToUpper(tokens[i].text)
+	}
+	var prevWord string
+	for i, token := range tokens {
+		switch token.kind {
+		case prettySpace:
+			pendingSpace = !atLineStart
+			continue
+		case prettyOpen:
+			word := nextWord(i)
+			isSubquery := word == "SELECT" || word == "WITH" || word == "INSERT" || word == "UPDATE" || word == "DELETE"
+			subquery = append(subquery, isSubquery)
+			if pendingSpace {
+				buf.WriteByte(' ')
+			}
+			buf.WriteByte('(')
+			atLineStart, pendingSpace = false, false
+			if isSubquery {
+				depth++
+				newline()
+			}
+			prevWord = ""
+			continue
+		case prettyClose:
+			if len(subquery) > 0 {
+				if subquery[len(subquery)-1] {
+					depth--
+					newline()
+				}
+				subquery = subquery[:len(subquery)-1]
+			}
+			if pendingSpace {
+				buf.WriteByte(' ')
+			}
+			buf.WriteByte(')')
+			atLineStart, pendingSpace = false, false
+			prevWord = ""
+			continue
+		case prettyWord:
+			word := strings.ToUpper(token.text)
+			next := nextWord(i)
+			call := i+1 < len(tokens) && tokens[i+1].kind == prettyOpen
+			topLevel := len(subquery) == 0 || subquery[len(subquery)-1]
+			if topLevel && !atLineStart && prettyClause(word, prevWord, next, call) {
+				newline()
+			}
+			prevWord = word
+		default:
+			prevWord = ""
+		}
+		if pendingSpace {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(token.text)
+		atLineStart, pendingSpace = false, false
+		// Comments run until the end of the line and every statement starts
+		// on a new line.
+		if token.kind == prettyLineComment || (token.text == ";" && len(subquery) == 0) {
+			newline()
+		}
+	}
+	// A trailing semicolon leaves behind an empty line.
+	return strings.TrimRightFunc(buf.String(), unicode.IsSpace)
+}
+
+// prettyClause reports whether the (uppercased) word starts a clause, given
+// the word before it and the word after it. call is true if the word is
+// immediately followed by an open parenthesis.
+func prettyClause(word, prev, next string, call bool) bool {
+	switch word {
+	case "SELECT", "WHERE", "HAVING", "WINDOW", "LIMIT", "OFFSET", "FETCH", "UNION", "INTERSECT", "EXCEPT", "RETURNING", "SET":
+		return true
+	case "FROM":
+		// IS DISTINCT FROM, DELETE FROM
+		return prev != "DISTINCT" && prev != "DELETE"
+	case "GROUP", "ORDER":
+		return next == "BY"
+	case "JOIN":
+		switch prev {
+		case "LEFT", "RIGHT", "FULL", "INNER", "CROSS", "NATURAL", "OUTER":
+			return false
+		}
+		return true
+	case "LEFT", "RIGHT", "FULL", "INNER", "CROSS", "NATURAL":
+		// LEFT and RIGHT are also string functions.
+		if prev == "NATURAL" {
+			return false
+		}
+		switch next {
+		case "JOIN", "OUTER", "APPLY", "LEFT", "RIGHT", "FULL", "INNER":
+			return true
+		}
+		return false
+	case "VALUES":
+		// MySQL's VALUES(column) in ON DUPLICATE KEY UPDATE.
+		return !call
+	case "ON":
+		return next == "CONFLICT" || next == "DUPLICATE"
+	}
+	return false
+}
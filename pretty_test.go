@@ -0,0 +1,95 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestSprintfPretty(t *testing.T) {
+	type TT struct {
+		description string
+		dialect     string
+		query       string
+		args        []any
+		wantString  string
+	}
+
+	tests := []TT{{
+		description: "clauses",
+		dialect:     DialectPostgres,
+		query: "SELECT a.actor_id, a.first_name FROM actor AS a" +
+			" LEFT JOIN film_actor AS fa ON fa.actor_id = a.actor_id JOIN film AS f ON f.film_id = fa.film_id" +
+			" WHERE a.actor_id > $1 GROUP BY a.actor_id, a.first_name HAVING COUNT(*) > $2 ORDER BY a.first_name LIMIT $3",
+		args: []any{10, 2, 5},
+		wantString: "SELECT a.actor_id, a.first_name" +
+			"\nFROM actor AS a" +
+			"\nLEFT JOIN film_actor AS fa ON fa.actor_id = a.actor_id" +
+			"\nJOIN film AS f ON f.film_id = fa.film_id" +
+			"\nWHERE a.actor_id > 10" +
+			"\nGROUP BY a.actor_id, a.first_name" +
+			"\nHAVING COUNT(*) > 2" +
+			"\nORDER BY a.first_name" +
+			"\nLIMIT 5",
+	}, {
+		description: "subqueries",
+		dialect:     DialectSQLite,
+		query: "WITH banned AS (SELECT actor_id FROM banned_actor) SELECT actor_id FROM actor" +
+			" WHERE actor_id IN (SELECT actor_id FROM banned WHERE EXISTS (SELECT 1 FROM film)) UNION ALL SELECT 1",
+		wantString: "WITH banned AS (" +
+			"\n    SELECT actor_id" +
+			"\n    FROM banned_actor" +
+			"\n)" +
+			"\nSELECT actor_id" +
+			"\nFROM actor" +
+			"\nWHERE actor_id IN (" +
+			"\n    SELECT actor_id" +
+			"\n    FROM banned" +
+			"\n    WHERE EXISTS (" +
+			"\n        SELECT 1" +
+			"\n        FROM film" +
+			"\n    )" +
+			"\n)" +
+			"\nUNION ALL" +
+			"\nSELECT 1",
+	}, {
+		description: "keywords inside functions and literals are left alone",
+		dialect:     DialectPostgres,
+		query: "SELECT EXTRACT(YEAR FROM f.release_date), LEFT(f.title, 3), 'select from where'," +
+			" \"from\", rank() OVER (PARTITION BY f.rating ORDER BY f.length) FROM film AS f" +
+			" WHERE f.rating IS DISTINCT FROM $1",
+		args: []any{"it's"},
+		wantString: "SELECT EXTRACT(YEAR FROM f.release_date), LEFT(f.title, 3), 'select from where'," +
+			" \"from\", rank() OVER (PARTITION BY f.rating ORDER BY f.length)" +
+			"\nFROM film AS f" +
+			"\nWHERE f.rating IS DISTINCT FROM 'it''s'",
+	}, {
+		description: "DML",
+		dialect:     DialectMySQL,
+		query: "INSERT INTO actor (actor_id, first_name) VALUES (?, ?) ON DUPLICATE KEY UPDATE first_name = VALUES(first_name);" +
+			" DELETE FROM actor WHERE actor_id = ?; UPDATE actor SET first_name = ? -- comment\nWHERE actor_id = ?",
+		args: []any{1, "bob", 2, "alice", 3},
+		wantString: "INSERT INTO actor (actor_id, first_name)" +
+			"\nVALUES (1, 'bob')" +
+			"\nON DUPLICATE KEY UPDATE first_name = VALUES(first_name);" +
+			"\nDELETE FROM actor" +
+			"\nWHERE actor_id = 2;" +
+			"\nUPDATE actor" +
+			"\nSET first_name = 'alice' -- comment" +
+			"\nWHERE actor_id = 3",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			gotString, err := SprintfPretty(tt.dialect, tt.query, tt.args)
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(gotString, tt.wantString); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ResultSet maps the rows of one result set returned by a stored procedure.
+// Create one with Results.
+type ResultSet struct {
+	mapRow func(*Row)
+	err    error
+}
+
+// Results returns a ResultSet that maps each row of a result set with the
+// rowmapper and appends the result to dest. The columns of the result set are
+// read the same way as the columns of a static query: by name with the Row
+// methods (e.g. row.Int("actor_id")) or by position with the At methods (e.g.
+// row.IntAt(0)).
+func Results[T any](dest *[]T, rowmapper func(*Row) T) ResultSet {
+	if dest == nil {
+		return ResultSet{err: fmt.Errorf("Results: dest is nil")}
+	}
+	if rowmapper == nil {
+		return ResultSet{err: fmt.Errorf("Results: rowmapper is nil")}
+	}
+	if err := checkRowMapperType[T](); err != nil {
+		return ResultSet{err: fmt.Errorf("Results: %w", err)}
+	}
+	return ResultSet{mapRow: func(row *Row) {
+		*dest = append(*dest, rowmapper(row))
+	}}
+}
+
+// CallProc calls a SQL Server stored procedure. The args are classified by
+// their type:
+//
+//   - A ResultSet maps the rows of the next result set returned by the
+//     procedure. Result sets without a corresponding ResultSet are skipped.
+//   - An sql.NamedArg is passed as a named parameter. If its value is an
+//     sql.Out, the parameter is passed as an OUTPUT parameter and the value
+//     it points to is set once CallProc returns.
+//   - Any other value is passed as a positional parameter.
+//
+// Positional parameters must come before named parameters, as SQL Server
+// requires.
+//
+//	var actors []Actor
+//	var films []Film
+//	var total int
+//	// EXEC dbo.actor_films @p1, @total = @total OUTPUT
+//	err := sq.CallProc(db, "dbo.actor_films",
+//	    actorID,
+//	    sql.Named("total", sql.Out{Dest: &total}),
+//	    sq.Results(&actors, func(row *sq.Row) Actor {
+//	        return Actor{
+//	            ActorID:   row.Int("actor_id"),
+//	            FirstName: row.String("first_name"),
+//	        }
+//	    }),
+//	    sq.Results(&films, func(row *sq.Row) Film {
+//	        return Film{
+//	            FilmID: row.Int("film_id"),
+//	            Title:  row.String("title"),
+//	        }
+//	    }),
+//	)
+func CallProc(db DB, name string, args ...any) error {
+	return callProc(context.Background(), db, name, args, 1)
+}
+
+// CallProcContext is like CallProc but additionally requires a
+// context.Context.
+func CallProcContext(ctx context.Context, db DB, name string, args ...any) error {
+	return callProc(ctx, db, name, args, 1)
+}
+
+func callProc(ctx context.Context, db DB, name string, args []any, skip int) (err error) {
+	if db == nil {
+		return fmt.Errorf("db is nil")
+	}
+	if dialect := dbDialect(db); dialect != "" && dialect != DialectSQLServer {
+		return fmt.Errorf("CallProc: dialect %q not supported, only sqlserver is supported", dialect)
+	}
+	query, resultSets, err := procQuery(name, args)
+	if err != nil {
+		return err
+	}
+
+	// The cursor's rowmapper forwards each row to the ResultSet of the
+	// result set currently being read.
+	var mapRow func(*Row)
+	cursor, err := fetchCursor(ctx, db, query, func(row *Row) struct{} {
+		mapRow(row)
+		return struct{}{}
+	}, skip+1)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := cursor.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+	for i := 0; ; i++ {
+		mapRow = nil
+		if i < len(resultSets) {
+			mapRow = resultSets[i].mapRow
+		}
+		for cursor.row.sqlRows.Next() {
+			if mapRow == nil {
+				continue
+			}
+			cursor.queryStats.RowCount.Int64++
+			_, err = cursor.Result()
+			if err != nil {
+				return fmt.Errorf("CallProc %s: result set %d: %w", name, i+1, err)
+			}
+		}
+		if err = cursor.row.sqlRows.Err(); err != nil {
+			cursor.queryStats.Err = err
+			return nil // Close returns the error.
+		}
+		if !cursor.row.sqlRows.NextResultSet() {
+			return nil
+		}
+		err = cursor.row.initStaticColumns()
+		if err != nil {
+			return err
+		}
+		cursor.fieldNames = nil
+	}
+}
+
+// procQuery builds the EXEC statement of a stored procedure call and returns
+// it together with the ResultSets found in args.
+func procQuery(name string, args []any) (query CustomQuery, resultSets []ResultSet, err error) {
+	var schema string
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		schema, name = name[:i], name[i+1:]
+		for _, part := range strings.Split(schema, ".") {
+			if err := ValidateIdentifier(DialectSQLServer, part); err != nil {
+				return query, nil, fmt.Errorf("CallProc: %w", err)
+			}
+		}
+	}
+	if err := ValidateIdentifier(DialectSQLServer, name); err != nil {
+		return query, nil, fmt.Errorf("CallProc: %w", err)
+	}
+	var format strings.Builder
+	format.WriteString("EXEC {}")
+	values := []any{NewTableStruct(schema, name, "")}
+	var named bool
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case ResultSet:
+			if arg.err != nil {
+				return query, nil, arg.err
+			}
+			resultSets = append(resultSets, arg)
+			continue
+		case sql.NamedArg:
+			if err := ValidateIdentifier(DialectSQLServer, arg.Name); err != nil {
+				return query, nil, fmt.Errorf("CallProc: parameter: %w", err)
+			}
+			named = true
+		default:
+			if named {
+				return query, nil, fmt.Errorf("CallProc: positional parameter %#v comes after a named parameter", arg)
+			}
+		}
+		if len(values) == 1 {
+			format.WriteString(" ")
+		} else {
+			format.WriteString(", ")
+		}
+		if namedArg, ok := arg.(sql.NamedArg); ok {
+			format.WriteString("@" + namedArg.Name + " = {}")
+			if _, ok := namedArg.Value.(sql.Out); ok {
+				format.WriteString(" OUTPUT")
+			}
+		} else {
+			format.WriteString("{}")
+		}
+		values = append(values, arg)
+	}
+	query = CustomQuery{
+		Dialect: DialectSQLServer,
+		Format:  format.String(),
+		Values:  values,
+	}
+	return query, resultSets, nil
+}
@@ -0,0 +1,195 @@
+package sq
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestCallProc(t *testing.T) {
+	type TT struct {
+		description    string
+		name           string
+		args           []any
+		wantQuery      string
+		wantArgs       []any
+		wantResultSets int
+		wantErr        string
+	}
+
+	var total int
+	var actorIDs []int
+	resultSet := Results(&actorIDs, func(row *Row) int { return row.Int("actor_id") })
+
+	tests := []TT{{
+		description: "no args",
+		name:        "refresh_stats",
+		wantQuery:   "EXEC refresh_stats",
+	}, {
+		description: "positional args",
+		name:        "dbo.actor_films",
+		args:        []any{1, "PENELOPE"},
+		wantQuery:   "EXEC dbo.actor_films @p1, @p2",
+		wantArgs:    []any{1, "PENELOPE"},
+	}, {
+		description: "named and output args",
+		name:        "dbo.actor_films",
+		args: []any{
+			1,
+			sql.Named("rating", "PG"),
+			sql.Named("total", sql.Out{Dest: &total}),
+		},
+		wantQuery: "EXEC dbo.actor_films @p1, @rating = @rating, @total = @total OUTPUT",
+		wantArgs: []any{
+			1,
+			sql.Named("rating", "PG"),
+			sql.Named("total", sql.Out{Dest: &total}),
+		},
+	}, {
+		description:    "result sets are not parameters",
+		name:           "sakila.dbo.actor_films",
+		args:           []any{resultSet, 1, resultSet},
+		wantQuery:      "EXEC sakila.dbo.actor_films @p1",
+		wantArgs:       []any{1},
+		wantResultSets: 2,
+	}, {
+		description: "quoted name",
+		name:        "dbo.Actor Films",
+		wantQuery:   "EXEC dbo.[Actor Films]",
+	}, {
+		description: "invalid name",
+		name:        "dbo.actor_films]; DROP TABLE actor; --",
+		wantErr:     "contains",
+	}, {
+		description: "positional after named",
+		name:        "actor_films",
+		args:        []any{sql.Named("rating", "PG"), 1},
+		wantErr:     "positional parameter 1 comes after a named parameter",
+	}, {
+		description: "nil rowmapper",
+		name:        "actor_films",
+		args:        []any{Results[int](&actorIDs, nil)},
+		wantErr:     "rowmapper is nil",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			query, resultSets, err := procQuery(tt.name, tt.args)
+			if err == nil {
+				var gotQuery string
+				var gotArgs []any
+				gotQuery, gotArgs, err = ToSQL("", query, nil)
+				if err == nil {
+					if diff := testutil.Diff(gotQuery, tt.wantQuery); diff != "" {
+						t.Error(testutil.Callers(), diff)
+					}
+					if diff := testutil.Diff(gotArgs, tt.wantArgs); diff != "" {
+						t.Error(testutil.Callers(), diff)
+					}
+					if diff := testutil.Diff(len(resultSets), tt.wantResultSets); diff != "" {
+						t.Error(testutil.Callers(), diff)
+					}
+				}
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(testutil.Callers(), err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal(testutil.Callers(), "expected error but got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Error(testutil.Callers(), err)
+			}
+		})
+	}
+
+	t.Run("unsupported dialect", func(t *testing.T) {
+		t.Parallel()
+		db, err := sql.Open("sqlite3", ":memory:")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer db.Close()
+		err = CallProc(db, "actor_films")
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+	})
+
+	t.Run(DialectSQLServer, func(t *testing.T) {
+		if *sqlserverDSN == "" {
+			return
+		}
+		t.Parallel()
+		db, err := sql.Open("sqlserver", preprocessDSN(DialectSQLServer, *sqlserverDSN))
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer db.Close()
+		_, err = db.Exec("DROP PROCEDURE IF EXISTS sq_call_proc_test;")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = db.Exec("CREATE PROCEDURE sq_call_proc_test @n INT, @name NVARCHAR(255), @total INT OUTPUT AS" +
+			"\nBEGIN" +
+			"\n    SET NOCOUNT ON;" +
+			"\n    SELECT 'hello' AS greeting;" +
+			"\n    SELECT value AS num FROM (VALUES (1), (2), (3)) AS t (value) WHERE value <= @n ORDER BY value;" +
+			"\n    SELECT @name AS name, LEN(@name) AS length;" +
+			"\n    SET @total = @n * 10;" +
+			"\nEND;")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer func() {
+			db.Exec("DROP PROCEDURE IF EXISTS sq_call_proc_test;")
+		}()
+		type Name struct {
+			Name   string
+			Length int
+		}
+		var greetings []string
+		var nums []int
+		var names []Name
+		var total int
+		err = CallProc(Log(db), "dbo.sq_call_proc_test",
+			2,
+			sql.Named("name", "PENELOPE"),
+			sql.Named("total", sql.Out{Dest: &total}),
+			Results(&greetings, func(row *Row) string {
+				return row.StringAt(0)
+			}),
+			Results(&nums, func(row *Row) int {
+				return row.Int("num")
+			}),
+			Results(&names, func(row *Row) Name {
+				return Name{
+					Name:   row.String("name"),
+					Length: row.Int("length"),
+				}
+			}),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(greetings, []string{"hello"}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(nums, []int{1, 2}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(names, []Name{{Name: "PENELOPE", Length: 8}}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(total, 20); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// JobQueue describes a table that is used as a job queue, for use with
+// DequeueJobs.
+type JobQueue struct {
+	Dialect string
+
+	// Table is the job queue table.
+	Table Table
+
+	// KeyField is the primary key of the Table.
+	KeyField Field
+
+	// Available is the predicate that matches the jobs that are ready to be
+	// dequeued e.g. status = 'pending'. If nil, every job is available.
+	Available Predicate
+
+	// OrderBy is the order in which jobs are dequeued. If empty, jobs are
+	// dequeued in KeyField order.
+	OrderBy []Field
+
+	// Claim are the assignments that mark a dequeued job as claimed e.g.
+	// status = 'running'. Claim must make a job no longer match Available,
+	// otherwise the same job will be dequeued again.
+	Claim []Assignment
+}
+
+// DequeueJobs claims up to limit available jobs from a job queue and returns
+// them. Concurrent callers never claim the same job: rows locked by another
+// caller are skipped over (FOR UPDATE SKIP LOCKED) instead of waited on.
+//
+// For postgres and sqlite, the jobs are claimed and returned in a single
+// UPDATE ... RETURNING query. sqlite does not support row locks, but it
+// already serializes writes so no locking is needed.
+//
+//	UPDATE jobs SET status = 'running'
+//	WHERE id IN (
+//	    SELECT id FROM jobs WHERE status = 'pending'
+//	    ORDER BY id LIMIT 10
+//	    FOR UPDATE SKIP LOCKED
+//	)
+//	RETURNING ...
+//
+// For mysql, the jobs are selected with FOR UPDATE SKIP LOCKED then claimed
+// with an UPDATE. The row locks only last until the end of the transaction,
+// so db should be a transaction. If db is (or wraps) an *sql.DB, DequeueJobs
+// runs inside its own transaction. Note that the rowmapper sees the jobs as they
+// were before they were claimed.
+//
+// sqlserver is not supported.
+func DequeueJobs[T any](db DB, queue JobQueue, limit int, rowmapper func(*Row) T) ([]T, error) {
+	return dequeueJobs(context.Background(), db, queue, limit, rowmapper, 1)
+}
+
+// DequeueJobsContext is like DequeueJobs but additionally requires a
+// context.Context.
+func DequeueJobsContext[T any](ctx context.Context, db DB, queue JobQueue, limit int, rowmapper func(*Row) T) ([]T, error) {
+	return dequeueJobs(ctx, db, queue, limit, rowmapper, 1)
+}
+
+func dequeueJobs[T any](ctx context.Context, db DB, queue JobQueue, limit int, rowmapper func(*Row) T, skip int) (jobs []T, err error) {
+	if db == nil {
+		return nil, fmt.Errorf("db is nil")
+	}
+	if queue.Table == nil {
+		return nil, fmt.Errorf("DequeueJobs: JobQueue.Table is nil")
+	}
+	if queue.KeyField == nil {
+		return nil, fmt.Errorf("DequeueJobs: JobQueue.KeyField is nil")
+	}
+	if len(queue.Claim) == 0 {
+		return nil, fmt.Errorf("DequeueJobs: JobQueue.Claim is empty")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("DequeueJobs: limit must be greater than zero, got %d", limit)
+	}
+	if rowmapper == nil {
+		return nil, fmt.Errorf("rowmapper is nil")
+	}
+	dialect := queue.Dialect
+	if dialect == "" {
+		dialect = dbDialect(db)
+	}
+	selectQuery := SelectQuery{
+		Dialect:        dialect,
+		FromTable:      queue.Table,
+		WherePredicate: queue.Available,
+		OrderByFields:  queue.OrderBy,
+		LimitRows:      limit,
+	}
+	if len(selectQuery.OrderByFields) == 0 {
+		selectQuery.OrderByFields = []Field{queue.KeyField}
+	}
+	switch dialect {
+	case DialectPostgres, DialectSQLite:
+		selectQuery.SelectFields = []Field{queue.KeyField}
+		if dialect == DialectPostgres {
+			selectQuery.LockStrength = "UPDATE"
+			selectQuery.LockSkipLocked = true
+		}
+		updateQuery := UpdateQuery{
+			Dialect:        dialect,
+			UpdateTable:    queue.Table,
+			Assignments:    queue.Claim,
+			WherePredicate: In(queue.KeyField, selectQuery),
+		}
+		cursor, err := fetchCursor(ctx, db, updateQuery, rowmapper, skip+1)
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close()
+		return cursorResults(cursor)
+	case DialectMySQL:
+		if sqlDB, ok := unwrapSQLDB(db); ok {
+			var tx *sql.Tx
+			tx, err = sqlDB.BeginTx(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			defer func() {
+				if err != nil {
+					_ = tx.Rollback()
+					return
+				}
+				err = tx.Commit()
+			}()
+			db = rewrapDB(db, tx)
+		}
+		selectQuery.LockStrength = "UPDATE"
+		selectQuery.LockSkipLocked = true
+		var keys []any
+		cursor, err := fetchCursor(ctx, db, selectQuery, func(row *Row) T {
+			key := row.Value("{}", queue.KeyField)
+			job := rowmapper(row)
+			if row.sqlRows != nil {
+				keys = append(keys, key)
+			}
+			return job
+		}, skip+1)
+		if err != nil {
+			return nil, err
+		}
+		jobs, err = cursorResults(cursor)
+		cursor.Close()
+		if err != nil || len(jobs) == 0 {
+			return jobs, err
+		}
+		updateQuery := UpdateQuery{
+			Dialect:        dialect,
+			UpdateTable:    queue.Table,
+			Assignments:    queue.Claim,
+			WherePredicate: In(queue.KeyField, keys),
+		}
+		_, err = exec(ctx, db, updateQuery, skip+1)
+		if err != nil {
+			return nil, err
+		}
+		return jobs, nil
+	default:
+		return nil, fmt.Errorf("DequeueJobs: %s does not support SKIP LOCKED", dialect)
+	}
+}
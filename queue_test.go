@@ -0,0 +1,146 @@
+package sq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestDequeueJobs(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "job1", "pending").
+		Values(2, "job2", "pending").
+		Values(3, "job3", "done").
+		Values(4, "job4", "pending"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	queue := JobQueue{
+		Dialect:   DialectSQLite,
+		Table:     ACTOR,
+		KeyField:  ACTOR.ACTOR_ID,
+		Available: ACTOR.LAST_NAME.EqString("pending"),
+		Claim:     []Assignment{ACTOR.LAST_NAME.SetString("running")},
+	}
+	type job struct {
+		id     int
+		status string
+	}
+	dequeue := func() []job {
+		jobs, err := DequeueJobs(db, queue, 2, func(row *Row) job {
+			return job{
+				id:     row.IntField(ACTOR.ACTOR_ID),
+				status: row.StringField(ACTOR.LAST_NAME),
+			}
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		return jobs
+	}
+	if diff := testutil.Diff(dequeue(), []job{{1, "running"}, {2, "running"}}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if diff := testutil.Diff(dequeue(), []job{{4, "running"}}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	if diff := testutil.Diff(dequeue(), []job(nil)); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	t.Run("mysql with a wrapped *sql.DB", func(t *testing.T) {
+		t.Parallel()
+		sqlDB, counts := newFileDB(t)
+		_, err := sqlDB.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'job1', 'pending'), (2, 'job2', 'pending')")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		// Strip the MySQL-only syntax that SQLite doesn't understand.
+		mysqlOnly := strings.NewReplacer(" FOR UPDATE SKIP LOCKED", "", "SET actor.", "SET ")
+		db := WithQueryHooks(NewDB(sqlDB, DialectMySQL), func(ctx context.Context, dialect string, query string, args []any) (string, []any, error) {
+			return mysqlOnly.Replace(query), args, nil
+		})
+		queue := queue
+		queue.Dialect = DialectMySQL
+		gotIDs, err := DequeueJobs(db, queue, 1, func(row *Row) int { return row.IntField(ACTOR.ACTOR_ID) })
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(gotIDs, []int{1}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(counts.commits.Load(), int32(1)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("mysql with a logged *sql.DB", func(t *testing.T) {
+		t.Parallel()
+		sqlDB, counts := newFileDB(t)
+		_, err := sqlDB.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'job1', 'pending')")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		mysqlOnly := strings.NewReplacer(" FOR UPDATE SKIP LOCKED", "", "SET actor.", "SET ")
+		db := WithQueryHooks(Log(NewDB(sqlDB, DialectMySQL)), func(ctx context.Context, dialect string, query string, args []any) (string, []any, error) {
+			return mysqlOnly.Replace(query), args, nil
+		})
+		queue := queue
+		queue.Dialect = DialectMySQL
+		gotIDs, err := DequeueJobs(db, queue, 1, func(row *Row) int { return row.IntField(ACTOR.ACTOR_ID) })
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(gotIDs, []int{1}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(counts.commits.Load(), int32(1)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("mysql claim fails", func(t *testing.T) {
+		t.Parallel()
+		sqlDB, counts := newFileDB(t)
+		_, err := sqlDB.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'job1', 'pending')")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		db := WithQueryHooks(NewDB(sqlDB, DialectMySQL), func(ctx context.Context, dialect string, query string, args []any) (string, []any, error) {
+			if strings.HasPrefix(query, "UPDATE") {
+				return query, args, fmt.Errorf("claim failed")
+			}
+			return strings.TrimSuffix(query, " FOR UPDATE SKIP LOCKED"), args, nil
+		})
+		queue := queue
+		queue.Dialect = DialectMySQL
+		_, err = DequeueJobs(db, queue, 1, func(row *Row) int { return row.IntField(ACTOR.ACTOR_ID) })
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error, got nil")
+		}
+		if diff := testutil.Diff(counts.commits.Load(), int32(0)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+		if diff := testutil.Diff(counts.rollbacks.Load(), int32(1)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("sqlserver", func(t *testing.T) {
+		t.Parallel()
+		queue := queue
+		queue.Dialect = DialectSQLServer
+		_, err := DequeueJobs(db, queue, 2, func(row *Row) int { return row.IntField(ACTOR.ACTOR_ID) })
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,327 @@
+package sq
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how queries executed through a DB wrapped by
+// WithRetry are retried. The zero value is a valid RetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a query is attempted
+	// (including the first attempt). Defaults to 3.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. The delay doubles on
+	// every subsequent retry (with jitter). Defaults to 50ms.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between retries. Defaults to 1s.
+	MaxDelay time.Duration
+
+	// IsRetryable reports whether a query error should be retried. Defaults
+	// to IsTransientError.
+	IsRetryable func(err error) bool
+}
+
+// WithRetry wraps a DB such that idempotent queries executed through it are
+// retried according to the RetryPolicy when they fail with a transient error
+// e.g. a connection reset, deadlock or serialization failure. SELECT queries
+// (including WITH ... SELECT) are considered idempotent unless they are
+// SELECT ... INTO, take row locks (FOR UPDATE, FOR SHARE) or have a CTE that
+// modifies data, other queries are only retried if their context has been
+// marked with WithIdempotent. Queries are not retried if the context deadline
+// would be exceeded while waiting for the next attempt. The number of
+// attempts is recorded in QueryStats.Attempts.
+//
+// Only the execution of a query is retried. Errors that happen while
+// iterating over the results of a fetch are not retried. Transactions started
+// from a DB wrapped by WithRetry do not retry queries, because a deadlock or
+// serialization failure aborts the whole transaction (retry the transaction
+// instead).
+//
+//	db = sq.WithRetry(db, sq.RetryPolicy{MaxAttempts: 5})
+func WithRetry(db DB, policy RetryPolicy) DB {
+	return withOption(db, &policy)
+}
+
+// dbRetryPolicy returns the outermost RetryPolicy of a DB, or nil if it is not
+// wrapped by WithRetry.
+func dbRetryPolicy(db DB) *RetryPolicy {
+	policy, _ := dbOption[*RetryPolicy](db)
+	return policy
+}
+
+type idempotentKey struct{}
+
+// WithIdempotent marks the queries run with the returned context as
+// idempotent i.e. safe to be retried by a DB wrapped by WithRetry.
+//
+//	// The upsert can be safely retried.
+//	_, err := sq.ExecContext(sq.WithIdempotent(ctx), db, sq.InsertInto(a).
+//	    Columns(a.ACTOR_ID, a.FIRST_NAME).
+//	    Values(1, "PENELOPE").
+//	    OnConflict(a.ACTOR_ID).DoNothing(),
+//	)
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// isIdempotent reports whether a query can be safely retried.
+func isIdempotent(ctx context.Context, query string) bool {
+	if idempotent, _ := ctx.Value(idempotentKey{}).(bool); idempotent {
+		return true
+	}
+	// Skip leading whitespace and comments.
+	for {
+		query = strings.TrimLeft(query, " \t\r\n")
+		if strings.HasPrefix(query, "--") {
+			_, query, _ = strings.Cut(query, "\n")
+			continue
+		}
+		if strings.HasPrefix(query, "/*") {
+			_, query, _ = strings.Cut(query, "*/")
+			continue
+		}
+		break
+	}
+	// WITH ... SELECT is classified by its main statement.
+	if len(query) > len("WITH") && strings.EqualFold(query[:len("WITH")], "WITH") && !isIdentifierChar(query[len("WITH")]) {
+		var ok bool
+		query, ok = skipCTEs(query)
+		if !ok {
+			return false
+		}
+	}
+	if len(query) < len("SELECT") || !strings.EqualFold(query[:len("SELECT")], "SELECT") {
+		return false
+	}
+	// SELECT ... INTO creates a table (or assigns variables) and SELECT ...
+	// FOR UPDATE/FOR SHARE/LOCK IN SHARE MODE takes row locks, neither of
+	// which should be repeated blindly. Words inside string literals are not
+	// told apart, which errs on the side of not retrying.
+	var prevWord string
+	for i := len("SELECT"); i < len(query); {
+		if !isIdentifierChar(query[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(query) && isIdentifierChar(query[i]) {
+			i++
+		}
+		word := strings.ToUpper(query[start:i])
+		switch {
+		case word == "INTO":
+			return false
+		case prevWord == "FOR" && (word == "UPDATE" || word == "SHARE" || word == "NO" || word == "KEY"):
+			return false
+		case prevWord == "LOCK" && word == "IN":
+			return false
+		}
+		prevWord = word
+	}
+	return true
+}
+
+// skipCTEs skips the WITH clause of a query and returns the main statement
+// that follows it. ok is false if the main statement is not a SELECT or if
+// any CTE contains an INSERT, UPDATE, DELETE or MERGE (e.g. WITH deleted AS
+// (DELETE ... RETURNING ...) SELECT ...).
+func skipCTEs(query string) (mainStatement string, ok bool) {
+	depth := 0
+	for i := len("WITH"); i < len(query); {
+		if end := skipLiteral("", query, i); end > i {
+			i = end
+			continue
+		}
+		switch char := query[i]; {
+		case char == '(':
+			depth++
+			i++
+		case char == ')':
+			depth--
+			i++
+		case isIdentifierChar(char):
+			start := i
+			for i < len(query) && isIdentifierChar(query[i]) {
+				i++
+			}
+			switch strings.ToUpper(query[start:i]) {
+			case "INSERT", "UPDATE", "DELETE", "MERGE":
+				return "", false
+			case "SELECT":
+				if depth == 0 {
+					return query[start:], true
+				}
+			}
+		default:
+			i++
+		}
+	}
+	return "", false
+}
+
+// run calls fn, which executes a query, and retries it according to the
+// RetryPolicy. A nil RetryPolicy calls fn once. The number of attempts is
+// stored in attempts.
+func (policy *RetryPolicy) run(ctx context.Context, query string, attempts *int, fn func() error) error {
+	*attempts = 1
+	err := fn()
+	if policy == nil || err == nil || !isIdempotent(ctx, query) {
+		return err
+	}
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	isRetryable := policy.IsRetryable
+	if isRetryable == nil {
+		isRetryable = IsTransientError
+	}
+	for *attempts < maxAttempts && isRetryable(err) {
+		delay := policy.delay(*attempts)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		*attempts++
+		err = fn()
+	}
+	return err
+}
+
+// delay returns the delay before the next attempt, given the number of
+// attempts so far. The delay grows exponentially and is jittered to between
+// half and the whole of the exponential delay.
+func (policy *RetryPolicy) delay(attempts int) time.Duration {
+	baseDelay := policy.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = 50 * time.Millisecond
+	}
+	maxDelay := policy.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = time.Second
+	}
+	delay := baseDelay
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// IsTransientError reports whether an error is likely to go away if the query
+// is retried: connection resets, deadlocks, serialization failures and (for
+// SQLite) busy or locked databases. Like IsUniqueViolation, it understands the
+// error codes of the common database drivers and falls back on the error
+// message for other drivers.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		code := getDriverErrorCode(e)
+		switch {
+		case code.sqlState == "40001", // serialization_failure
+			code.sqlState == "40P01",               // deadlock_detected
+			code.sqlState == "57P01",               // admin_shutdown
+			strings.HasPrefix(code.sqlState, "08"): // connection_exception
+			return true
+		}
+		switch code.number {
+		case 1205, // mysql ER_LOCK_WAIT_TIMEOUT, sqlserver deadlock victim
+			1213, // mysql ER_LOCK_DEADLOCK
+			2006, // mysql CR_SERVER_GONE_ERROR
+			2013: // mysql CR_SERVER_LOST
+			return true
+		}
+		switch code.sqliteCode & 0xff {
+		case 5, 6: // SQLITE_BUSY, SQLITE_LOCKED
+			return true
+		}
+	}
+	msg := err.Error()
+	for _, s := range []string{
+		"connection reset by peer",
+		"broken pipe",
+		"bad connection",
+		"invalid connection",
+		"deadlock",
+		"could not serialize access",
+		"database is locked",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// driverErrorCode holds the error codes of a driver error. The drivers are not
+// imported, instead their error codes are obtained through methods or (via
+// reflection) struct fields:
+//
+//   - lib/pq: Code field (SQLSTATE)
+//   - pgx: SQLState method
+//   - go-sql-driver/mysql: Number field
+//   - mattn/go-sqlite3: ExtendedCode field
+//   - modernc.org/sqlite: Code method (extended result code)
+//   - go-mssqldb: Number field
+type driverErrorCode struct {
+	sqlState   string
+	number     int64
+	sqliteCode int64
+}
+
+// getDriverErrorCode returns the error codes of a driver error. It does not
+// look at the errors wrapped by err.
+func getDriverErrorCode(err error) driverErrorCode {
+	var code driverErrorCode
+	if e, ok := err.(interface{ SQLState() string }); ok {
+		code.sqlState = e.SQLState()
+	}
+	if e, ok := err.(interface{ Code() int }); ok {
+		code.sqliteCode = int64(e.Code())
+	}
+	value := reflect.Indirect(reflect.ValueOf(err))
+	if value.Kind() != reflect.Struct {
+		return code
+	}
+	if field := value.FieldByName("Code"); field.IsValid() && field.Kind() == reflect.String && code.sqlState == "" {
+		code.sqlState = field.String()
+	}
+	if field := value.FieldByName("ExtendedCode"); field.IsValid() && field.CanInt() {
+		code.sqliteCode = field.Int()
+	}
+	if field := value.FieldByName("Number"); field.IsValid() {
+		switch {
+		case field.CanInt():
+			code.number = field.Int()
+		case field.CanUint():
+			code.number = int64(field.Uint())
+		}
+	}
+	return code
+}
@@ -0,0 +1,212 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func Test_isIdempotent(t *testing.T) {
+	type TestTable struct {
+		description string
+		ctx         context.Context
+		query       string
+		want        bool
+	}
+
+	tests := []TestTable{{
+		description: "select",
+		query:       "SELECT 1",
+		want:        true,
+	}, {
+		description: "lowercase select",
+		query:       "  select 1",
+		want:        true,
+	}, {
+		description: "select with comments",
+		query:       "-- fetch actors\n/* app=test */ SELECT actor_id FROM actor",
+		want:        true,
+	}, {
+		description: "select into",
+		query:       "SELECT * INTO actor_backup FROM actor",
+	}, {
+		description: "select for update",
+		query:       "SELECT actor_id FROM actor WHERE actor_id = 1 FOR UPDATE SKIP LOCKED",
+	}, {
+		description: "select for no key update",
+		query:       "select actor_id from actor for no key update",
+	}, {
+		description: "select lock in share mode",
+		query:       "SELECT actor_id FROM actor LOCK IN SHARE MODE",
+	}, {
+		description: "select with update in a name",
+		query:       "SELECT last_update FROM actor",
+		want:        true,
+	}, {
+		description: "with select",
+		query:       "WITH RECURSIVE n (i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 10), m AS NOT MATERIALIZED (SELECT ')') SELECT i FROM n",
+		want:        true,
+	}, {
+		description: "with select for update",
+		query:       "WITH n AS (SELECT 1) SELECT actor_id FROM actor FOR UPDATE",
+	}, {
+		description: "with delete",
+		query:       "WITH deleted AS (DELETE FROM actor RETURNING actor_id) SELECT actor_id FROM deleted",
+	}, {
+		description: "with insert",
+		query:       "WITH n AS (SELECT 1 AS i) INSERT INTO actor (actor_id) SELECT i FROM n",
+	}, {
+		description: "insert",
+		query:       "INSERT INTO actor (actor_id) VALUES (1)",
+	}, {
+		description: "idempotent insert",
+		ctx:         WithIdempotent(context.Background()),
+		query:       "INSERT INTO actor (actor_id) VALUES (1)",
+		want:        true,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			if tt.ctx == nil {
+				tt.ctx = context.Background()
+			}
+			if diff := testutil.Diff(isIdempotent(tt.ctx, tt.query), tt.want); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	type TestTable struct {
+		description string
+		err         error
+		want        bool
+	}
+
+	tests := []TestTable{{
+		description: "nil",
+	}, {
+		description: "bad connection",
+		err:         fmt.Errorf("query: %w", driver.ErrBadConn),
+		want:        true,
+	}, {
+		description: "postgres serialization failure",
+		err:         &fakePQError{Code: "40001"},
+		want:        true,
+	}, {
+		description: "mysql deadlock",
+		err:         &fakeMySQLError{Number: 1213},
+		want:        true,
+	}, {
+		description: "sqlite busy",
+		err:         fakeSQLiteError{Code: 5, ExtendedCode: 517},
+		want:        true,
+	}, {
+		description: "unique violation",
+		err:         &fakePQError{Code: "23505"},
+	}, {
+		description: "syntax error",
+		err:         errors.New(`pq: syntax error at or near "SELEC"`),
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			if diff := testutil.Diff(IsTransientError(tt.err), tt.want); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
+
+// flakyDB is a DB whose first few queries fail with the given error.
+type flakyDB struct {
+	DB
+	failures int
+	err      error
+}
+
+func (db *flakyDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if db.failures > 0 {
+		db.failures--
+		return nil, db.err
+	}
+	return db.DB.QueryContext(ctx, query, args...)
+}
+
+func (db *flakyDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if db.failures > 0 {
+		db.failures--
+		return nil, db.err
+	}
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Parallel()
+	flaky := &flakyDB{DB: newDB(t), err: driver.ErrBadConn}
+	recorder := &queryStatsRecorder{}
+	db := WithRetry(struct {
+		DB
+		SqLogger
+	}{DB: flaky, SqLogger: recorder}, RetryPolicy{BaseDelay: time.Millisecond})
+	insertActor := InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS")
+	fetchActorIDs := func() error {
+		_, err := FetchAll(db, From(ACTOR), func(row *Row) int {
+			return row.IntField(ACTOR.ACTOR_ID)
+		})
+		return err
+	}
+
+	// Non-idempotent queries are not retried.
+	flaky.failures = 1
+	_, err := Exec(db, insertActor)
+	if !errors.Is(err, driver.ErrBadConn) {
+		t.Fatalf(testutil.Callers()+" expected driver.ErrBadConn, got %v", err)
+	}
+	// Unless marked as idempotent.
+	flaky.failures = 1
+	_, err = ExecContext(WithIdempotent(context.Background()), db, insertActor)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	// SELECT queries are retried.
+	flaky.failures = 2
+	err = fetchActorIDs()
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	// Up to MaxAttempts.
+	flaky.failures = 3
+	err = fetchActorIDs()
+	if !errors.Is(err, driver.ErrBadConn) {
+		t.Fatalf(testutil.Callers()+" expected driver.ErrBadConn, got %v", err)
+	}
+	// Errors that are not transient are not retried.
+	flaky.failures, flaky.err = 2, errors.New("syntax error")
+	err = fetchActorIDs()
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error but got nil")
+	}
+
+	var gotAttempts []int
+	for _, queryStats := range recorder.queryStats {
+		gotAttempts = append(gotAttempts, queryStats.Attempts)
+	}
+	wantAttempts := []int{1, 2, 3, 3, 1}
+	if diff := testutil.Diff(gotAttempts, wantAttempts); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
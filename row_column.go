@@ -23,6 +23,7 @@ type Row struct {
 	runningIndex  int
 	fields        []Field
 	scanDest      []any
+	scanDestBuf   *[]any
 	queryIsStatic bool
 	columns       []string
 	columnTypes   []*sql.ColumnType
@@ -30,6 +31,56 @@ type Row struct {
 	columnIndex   map[string]int
 }
 
+// initScanDest points row.scanDest at a slice of n nil elements taken from
+// the slicepool.
+func (row *Row) initScanDest(n int) {
+	row.scanDestBuf = getSlice()
+	row.scanDest = append(*row.scanDestBuf, make([]any, n)...)
+}
+
+// releaseScanDest returns row.scanDest to the slicepool, if it was taken from
+// there.
+func (row *Row) releaseScanDest() {
+	if row.scanDestBuf == nil {
+		return
+	}
+	*row.scanDestBuf = row.scanDest
+	putSlice(row.scanDestBuf)
+	row.scanDestBuf, row.scanDest = nil, nil
+}
+
+// initStaticColumns fetches the columns of row.sqlRows and points
+// row.scanDest at row.values, so that the rows of a static query can be
+// scanned. It is called again for every result set of a query that returns
+// more than one.
+func (row *Row) initStaticColumns() error {
+	var err error
+	row.columns, err = row.sqlRows.Columns()
+	if err != nil {
+		return err
+	}
+	row.columnTypes, err = row.sqlRows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	row.columnIndex = make(map[string]int)
+	for index, column := range row.columns {
+		if _, ok := row.columnIndex[column]; ok {
+			// Duplicate column names can only be accessed by index.
+			row.columnIndex[column] = -1
+			continue
+		}
+		row.columnIndex[column] = index
+	}
+	row.values = make([]any, len(row.columns))
+	row.releaseScanDest()
+	row.initScanDest(len(row.columns))
+	for index := range row.values {
+		row.scanDest[index] = &row.values[index]
+	}
+	return nil
+}
+
 // Column returns the names of the columns returned by the query. This method
 // can only be called in a rowmapper if it is paired with a raw SQL query e.g.
 // Queryf("SELECT * FROM my_table"). Otherwise, an error will be returned.
@@ -98,11 +149,7 @@ func (row *Row) Values() []any {
 // are using.
 func (row *Row) Value(format string, values ...any) any {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s is not present in query (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		return row.values[index]
+		return row.staticColumnValue(format, 1).value
 	}
 	if row.sqlRows == nil {
 		var value any
@@ -117,38 +164,155 @@ func (row *Row) Value(format string, values ...any) any {
 	return *scanDest
 }
 
-// Scan scans the expression into destPtr.
+// ValueAt returns the value of the column at the given index. Unlike Value,
+// it works even if multiple columns share the same name. It can only be
+// called for static queries e.g. Queryf("SELECT * FROM my_table").
+func (row *Row) ValueAt(index int) any {
+	return row.staticColumnValueAt("ValueAt", index, 1).value
+}
+
+// columnValue is the value of a column in a static query, together with the
+// name of the column so that type mismatches can be reported.
+type columnValue struct {
+	column string
+	value  any
+}
+
+// staticColumnValue returns the value of the column with the given name. It
+// can only be called for static queries.
+func (row *Row) staticColumnValue(column string, skip int) columnValue {
+	index, ok := row.columnIndex[column]
+	if !ok {
+		panic(fmt.Errorf(callsite(skip+1)+"column %s does not exist (available columns: %s)", column, strings.Join(row.columns, ", ")))
+	}
+	if index < 0 {
+		panic(fmt.Errorf(callsite(skip+1)+"column %s is ambiguous because it appears more than once in the query (available columns: %s), access it by index instead", column, strings.Join(row.columns, ", ")))
+	}
+	return columnValue{column: column, value: row.values[index]}
+}
+
+// staticColumnName returns the name that a field is expected to have in the
+// columns of a static query: its alias if it has one, otherwise its
+// unqualified and unquoted name.
+func staticColumnName(dialect string, field Field) string {
+	if alias := getAlias(field); alias != "" {
+		return alias
+	}
+	name := toString(dialect, withPrefix(field, ""))
+	if len(name) < 2 {
+		return name
+	}
+	switch first, last := name[0], name[len(name)-1]; {
+	case first == '"' && last == '"', first == '`' && last == '`':
+		return strings.ReplaceAll(name[1:len(name)-1], string(first)+string(first), string(first))
+	case first == '[' && last == ']':
+		return strings.ReplaceAll(name[1:len(name)-1], "]]", "]")
+	}
+	return name
+}
+
+// staticColumnValueAt returns the value of the column at the given index. It
+// panics if the query is not static.
+func (row *Row) staticColumnValueAt(method string, index int, skip int) columnValue {
+	if !row.queryIsStatic {
+		panic(fmt.Errorf(callsite(skip+1)+"cannot call %s for non-static queries", method))
+	}
+	if index < 0 || index >= len(row.values) {
+		panic(fmt.Errorf(callsite(skip+1)+"column index %d out of range (query returned %d columns)", index, len(row.values)))
+	}
+	return columnValue{column: row.columns[index], value: row.values[index]}
+}
+
+// staticRawBytes returns the raw bytes of a column value, so that the JSON,
+// UUID, Array and Enum accessors can decode it the same way they decode a
+// dynamic query's values. It returns false if the value is NULL.
+func staticRawBytes(v columnValue, expected string, skip int) ([]byte, bool) {
+	switch value := v.value.(type) {
+	case []byte:
+		return value, true
+	case string:
+		return []byte(value), true
+	case nil:
+		return nil, false
+	default:
+		panic(newMappingError(v, expected, skip+1))
+	}
+}
+
+// Scan scans the expression into destPtr. If destPtr is a pointer to a
+// pointer (e.g. **int, **string), it is set to nil if the value is NULL.
 func (row *Row) Scan(destPtr any, format string, values ...any) {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call Scan for static queries"))
+		if scanner, ok := destPtr.(sql.Scanner); ok {
+			row.staticScan(scanner, format, 1)
+			return
+		}
+		panic(fmt.Errorf(callsite(1) + "cannot call Scan for static queries (unless destPtr implements sql.Scanner)"))
 	}
 	row.scan(destPtr, Expr(format, values...), 1)
 }
 
-// ScanField scans the field into destPtr.
+// ScanField scans the field into destPtr. If destPtr is a pointer to a
+// pointer (e.g. **int, **string), it is set to nil if the value is NULL.
 func (row *Row) ScanField(destPtr any, field Field) {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call ScanField for static queries"))
+		if scanner, ok := destPtr.(sql.Scanner); ok {
+			row.staticScan(scanner, staticColumnName(row.dialect, field), 1)
+			return
+		}
+		panic(fmt.Errorf(callsite(1) + "cannot call ScanField for static queries (unless destPtr implements sql.Scanner)"))
 	}
 	row.scan(destPtr, field, 1)
 }
 
+// staticScan scans the value of the column with the given name into a
+// sql.Scanner. It can only be called for static queries.
+func (row *Row) staticScan(scanner sql.Scanner, column string, skip int) {
+	v := row.staticColumnValue(column, skip+1)
+	err := scanner.Scan(v.value)
+	if err != nil {
+		panic(fmt.Errorf(callsite(skip+1)+"scanning column %s into %T: %w", column, scanner, err))
+	}
+}
+
 func (row *Row) scan(destPtr any, field Field, skip int) {
+	// An sql.Null[T] is scanned like a *T, which is nil if the column is
+	// NULL.
+	if typ := reflect.TypeOf(destPtr); typ != nil && typ.Kind() == reflect.Pointer && isNullGeneric(typ.Elem()) {
+		valueType, _ := typ.Elem().FieldByName("V")
+		ptrPtr := reflect.New(reflect.PointerTo(valueType.Type))
+		row.scan(ptrPtr.Interface(), field, skip+1)
+		if row.sqlRows == nil {
+			return
+		}
+		dest := reflect.ValueOf(destPtr).Elem()
+		dest.Set(reflect.Zero(dest.Type()))
+		if ptr := ptrPtr.Elem(); !ptr.IsNil() {
+			dest.FieldByName("V").Set(ptr.Elem())
+			dest.FieldByName("Valid").SetBool(true)
+		}
+		return
+	}
 	if row.sqlRows == nil {
 		row.fields = append(row.fields, field)
 		switch destPtr.(type) {
-		case *bool, *sql.NullBool:
+		case *bool, **bool, *sql.NullBool:
 			row.scanDest = append(row.scanDest, &sql.NullBool{})
-		case *float64, *sql.NullFloat64:
+		case *float64, **float64, *sql.NullFloat64:
 			row.scanDest = append(row.scanDest, &sql.NullFloat64{})
-		case *int32, *sql.NullInt32:
+		case *int32, **int32, *sql.NullInt32:
 			row.scanDest = append(row.scanDest, &sql.NullInt32{})
-		case *int, *int64, *sql.NullInt64:
+		case *int, **int, *int64, **int64, *sql.NullInt64:
 			row.scanDest = append(row.scanDest, &sql.NullInt64{})
-		case *string, *sql.NullString:
+		case *string, **string, *sql.NullString:
 			row.scanDest = append(row.scanDest, &sql.NullString{})
-		case *time.Time, *sql.NullTime:
+		case *time.Time, **time.Time, *sql.NullTime:
 			row.scanDest = append(row.scanDest, &sql.NullTime{})
+		case sql.Scanner:
+			// Scan the raw driver value, the destPtr's Scan method will
+			// be called on it in the rowmapper.
+			var value any
+			row.scanDest = append(row.scanDest, &value)
 		default:
 			if reflect.TypeOf(destPtr).Kind() != reflect.Ptr {
 				panic(fmt.Errorf(callsite(skip+1)+"cannot pass in non pointer value (%#v) as destPtr", destPtr))
@@ -164,42 +328,101 @@ func (row *Row) scan(destPtr any, field Field, skip int) {
 	case *bool:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullBool)
 		*destPtr = scanDest.Bool
+	case **bool:
+		scanDest := row.scanDest[row.runningIndex].(*sql.NullBool)
+		*destPtr = nil
+		if scanDest.Valid {
+			b := scanDest.Bool
+			*destPtr = &b
+		}
 	case *sql.NullBool:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullBool)
 		*destPtr = *scanDest
 	case *float64:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullFloat64)
 		*destPtr = scanDest.Float64
+	case **float64:
+		scanDest := row.scanDest[row.runningIndex].(*sql.NullFloat64)
+		*destPtr = nil
+		if scanDest.Valid {
+			f := scanDest.Float64
+			*destPtr = &f
+		}
 	case *sql.NullFloat64:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullFloat64)
 		*destPtr = *scanDest
 	case *int:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullInt64)
 		*destPtr = int(scanDest.Int64)
+	case **int:
+		scanDest := row.scanDest[row.runningIndex].(*sql.NullInt64)
+		*destPtr = nil
+		if scanDest.Valid {
+			n := int(scanDest.Int64)
+			*destPtr = &n
+		}
 	case *int32:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullInt32)
 		*destPtr = scanDest.Int32
+	case **int32:
+		scanDest := row.scanDest[row.runningIndex].(*sql.NullInt32)
+		*destPtr = nil
+		if scanDest.Valid {
+			n := scanDest.Int32
+			*destPtr = &n
+		}
 	case *sql.NullInt32:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullInt32)
 		*destPtr = *scanDest
 	case *int64:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullInt64)
 		*destPtr = scanDest.Int64
+	case **int64:
+		scanDest := row.scanDest[row.runningIndex].(*sql.NullInt64)
+		*destPtr = nil
+		if scanDest.Valid {
+			n := scanDest.Int64
+			*destPtr = &n
+		}
 	case *sql.NullInt64:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullInt64)
 		*destPtr = *scanDest
 	case *string:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullString)
 		*destPtr = scanDest.String
+	case **string:
+		scanDest := row.scanDest[row.runningIndex].(*sql.NullString)
+		*destPtr = nil
+		if scanDest.Valid {
+			str := scanDest.String
+			*destPtr = &str
+		}
 	case *sql.NullString:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullString)
 		*destPtr = *scanDest
 	case *time.Time:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullTime)
 		*destPtr = scanDest.Time
+	case **time.Time:
+		scanDest := row.scanDest[row.runningIndex].(*sql.NullTime)
+		*destPtr = nil
+		if scanDest.Valid {
+			t := scanDest.Time
+			*destPtr = &t
+		}
 	case *sql.NullTime:
 		scanDest := row.scanDest[row.runningIndex].(*sql.NullTime)
 		*destPtr = *scanDest
+	case sql.Scanner:
+		scanDest := row.scanDest[row.runningIndex].(*any)
+		err := destPtr.Scan(*scanDest)
+		if err != nil {
+			var name string
+			if row.runningIndex < len(row.fields) {
+				name = toString(row.dialect, row.fields[row.runningIndex])
+			}
+			panic(fmt.Errorf(callsite(skip+1)+"scanning %s into %T: %w", name, destPtr, err))
+		}
 	default:
 		destValue := reflect.ValueOf(destPtr).Elem()
 		srcValue := reflect.ValueOf(row.scanDest[row.runningIndex]).Elem()
@@ -207,37 +430,160 @@ func (row *Row) scan(destPtr any, field Field, skip int) {
 	}
 }
 
+// StructRowMapper returns a rowmapper that maps the fields of a table struct
+// (see StarOf) into a struct T. A field of T matches a table field if its sq
+// struct tag is the column name or if its name is the column name ignoring
+// case and underscores (e.g. FirstName matches first_name). Table fields
+// without a matching field in T are not fetched. StructRowMapper panics if T
+// is not a struct.
+//
+//	type Actor struct {
+//	    ActorID    int
+//	    FirstName  string
+//	    LastName   string
+//	    LastUpdate time.Time
+//	}
+//
+//	a := sq.New[ACTOR]("a")
+//	actors, err := sq.FetchAll(db, sq.From(a), sq.StructRowMapper[Actor](a))
+func StructRowMapper[T any](table Table) func(*Row) T {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Errorf(callsite(1)+"StructRowMapper: %s is not a struct", typ))
+	}
+	type mapping struct {
+		index int
+		field Field
+	}
+	var mappings []mapping
+	matchField := structFieldMatcher(typ)
+	fields, names := tableFields(table)
+	for i, field := range fields {
+		if index, ok := matchField(names[i]); ok {
+			mappings = append(mappings, mapping{index: index, field: field})
+		}
+	}
+	return func(row *Row) T {
+		var result T
+		value := reflect.ValueOf(&result).Elem()
+		for _, m := range mappings {
+			destPtr := value.Field(m.index).Addr().Interface()
+			switch field := m.field.(type) {
+			case ArrayField:
+				row.array(destPtr, field, 1)
+			case JSONField:
+				row.json(destPtr, field, 1)
+			case UUIDField:
+				row.uuid(destPtr, field, 1)
+			case BitField:
+				switch destPtr := destPtr.(type) {
+				case *uint64:
+					*destPtr = bitsUint64(row.bits(field), 1)
+				case *[]bool:
+					*destPtr = row.bits(field)
+				default:
+					row.scan(destPtr, field, 1)
+				}
+			case EnumField:
+				if enum, ok := destPtr.(Enumeration); ok {
+					row.enum(enum, field, 1)
+				} else {
+					row.scan(destPtr, field, 1)
+				}
+			default:
+				row.scan(destPtr, field, 1)
+			}
+		}
+		return result
+	}
+}
+
+// structFieldMatcher returns a function that looks up the index of the field
+// of the struct type typ matching a column name, using the rules described in
+// StructRowMapper.
+func structFieldMatcher(typ reflect.Type) func(column string) (index int, ok bool) {
+	normalize := func(name string) string {
+		return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	}
+	indexes := make(map[string]int)
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		name := structField.Tag.Get("sq")
+		if name == "-" {
+			continue
+		}
+		if name != "" {
+			indexes[name] = i
+			continue
+		}
+		if _, ok := indexes[normalize(structField.Name)]; !ok {
+			indexes[normalize(structField.Name)] = i
+		}
+	}
+	return func(column string) (index int, ok bool) {
+		index, ok = indexes[column]
+		if !ok {
+			index, ok = indexes[normalize(column)]
+		}
+		return index, ok
+	}
+}
+
 // Array scans the array expression into destPtr. The destPtr must be a pointer
 // to a []string, []int, []int64, []int32, []float64, []float32 or []bool.
 func (row *Row) Array(destPtr any, format string, values ...any) {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call Array for static queries"))
+		row.staticArray(destPtr, row.staticColumnValue(format, 1), 1)
+		return
 	}
 	row.array(destPtr, Expr(format, values...), 1)
 }
 
+// ArrayAt scans the array column at the given index into destPtr. It can only
+// be called for static queries.
+func (row *Row) ArrayAt(destPtr any, index int) {
+	row.staticArray(destPtr, row.staticColumnValueAt("ArrayAt", index, 1), 1)
+}
+
+func (row *Row) staticArray(destPtr any, v columnValue, skip int) {
+	checkArrayDestPtr(destPtr, row.dialect, skip+1)
+	b, ok := staticRawBytes(v, fmt.Sprintf("%T", destPtr), skip+1)
+	if !ok {
+		return
+	}
+	scanArray(destPtr, row.dialect, b, skip+1)
+}
+
 // ArrayField scans the array field into destPtr. The destPtr must be a pointer
 // to a []string, []int, []int64, []int32, []float64, []float32 or []bool.
 func (row *Row) ArrayField(destPtr any, field Array) {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call ArrayField for static queries"))
+		row.staticArray(destPtr, row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
+		return
 	}
 	row.array(destPtr, field, 1)
 }
 
+func checkArrayDestPtr(destPtr any, dialect string, skip int) {
+	if reflect.TypeOf(destPtr).Kind() != reflect.Ptr {
+		panic(fmt.Errorf(callsite(skip+1)+"cannot pass in non pointer value (%#v) as destPtr", destPtr))
+	}
+	if dialect == DialectPostgres {
+		switch destPtr.(type) {
+		case *[]string, *[]int, *[]int64, *[]int32, *[]float64, *[]float32, *[]bool:
+			break
+		default:
+			panic(fmt.Errorf(callsite(skip+1)+"destptr (%T) must be either a pointer to a []string, []int, []int64, []int32, []float64, []float32 or []bool", destPtr))
+		}
+	}
+}
+
 func (row *Row) array(destPtr any, field Array, skip int) {
 	if row.sqlRows == nil {
-		if reflect.TypeOf(destPtr).Kind() != reflect.Ptr {
-			panic(fmt.Errorf(callsite(skip+1)+"cannot pass in non pointer value (%#v) as destPtr", destPtr))
-		}
-		if row.dialect == DialectPostgres {
-			switch destPtr.(type) {
-			case *[]string, *[]int, *[]int64, *[]int32, *[]float64, *[]float32, *[]bool:
-				break
-			default:
-				panic(fmt.Errorf(callsite(skip+1)+"destptr (%T) must be either a pointer to a []string, []int, []int64, []int32, []float64, []float32 or []bool", destPtr))
-			}
-		}
+		checkArrayDestPtr(destPtr, row.dialect, skip+1)
 		row.fields = append(row.fields, field)
 		row.scanDest = append(row.scanDest, &nullBytes{
 			dialect:     row.dialect,
@@ -252,26 +598,33 @@ func (row *Row) array(destPtr any, field Array, skip int) {
 	if !scanDest.valid {
 		return
 	}
-	if row.dialect != DialectPostgres {
-		err := json.Unmarshal(scanDest.bytes, destPtr)
+	scanArray(destPtr, row.dialect, scanDest.bytes, skip+1)
+}
+
+// scanArray decodes the raw bytes of an array into destPtr. Postgres arrays
+// are decoded from their text representation, while the other dialects store
+// arrays as JSON.
+func scanArray(destPtr any, dialect string, b []byte, skip int) {
+	if dialect != DialectPostgres {
+		err := json.Unmarshal(b, destPtr)
 		if err != nil {
-			panic(fmt.Errorf(callsite(skip+1)+"unmarshaling json %q into %T: %w", string(scanDest.bytes), destPtr, err))
+			panic(fmt.Errorf(callsite(skip+1)+"unmarshaling json %q into %T: %w", string(b), destPtr, err))
 		}
 		return
 	}
 	switch destPtr := destPtr.(type) {
 	case *[]string:
 		var array pqarray.StringArray
-		err := array.Scan(scanDest.bytes)
+		err := array.Scan(b)
 		if err != nil {
-			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to string array: %w", string(scanDest.bytes), err))
+			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to string array: %w", string(b), err))
 		}
 		*destPtr = array
 	case *[]int:
 		var array pqarray.Int64Array
-		err := array.Scan(scanDest.bytes)
+		err := array.Scan(b)
 		if err != nil {
-			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to int64 array: %w", string(scanDest.bytes), err))
+			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to int64 array: %w", string(b), err))
 		}
 		*destPtr = (*destPtr)[:cap(*destPtr)]
 		if len(*destPtr) < len(array) {
@@ -283,37 +636,37 @@ func (row *Row) array(destPtr any, field Array, skip int) {
 		}
 	case *[]int64:
 		var array pqarray.Int64Array
-		err := array.Scan(scanDest.bytes)
+		err := array.Scan(b)
 		if err != nil {
-			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to int64 array: %w", string(scanDest.bytes), err))
+			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to int64 array: %w", string(b), err))
 		}
 		*destPtr = array
 	case *[]int32:
 		var array pqarray.Int32Array
-		err := array.Scan(scanDest.bytes)
+		err := array.Scan(b)
 		if err != nil {
-			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to int32 array: %w", string(scanDest.bytes), err))
+			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to int32 array: %w", string(b), err))
 		}
 		*destPtr = array
 	case *[]float64:
 		var array pqarray.Float64Array
-		err := array.Scan(scanDest.bytes)
+		err := array.Scan(b)
 		if err != nil {
-			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to float64 array: %w", string(scanDest.bytes), err))
+			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to float64 array: %w", string(b), err))
 		}
 		*destPtr = array
 	case *[]float32:
 		var array pqarray.Float32Array
-		err := array.Scan(scanDest.bytes)
+		err := array.Scan(b)
 		if err != nil {
-			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to float32 array: %w", string(scanDest.bytes), err))
+			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to float32 array: %w", string(b), err))
 		}
 		*destPtr = array
 	case *[]bool:
 		var array pqarray.BoolArray
-		err := array.Scan(scanDest.bytes)
+		err := array.Scan(b)
 		if err != nil {
-			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to bool array: %w", string(scanDest.bytes), err))
+			panic(fmt.Errorf(callsite(skip+1)+"unable to convert %q to bool array: %w", string(b), err))
 		}
 		*destPtr = array
 	default:
@@ -324,29 +677,7 @@ func (row *Row) array(destPtr any, field Array, skip int) {
 // Bytes returns the []byte value of the expression.
 func (row *Row) Bytes(format string, values ...any) []byte {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			panic(fmt.Errorf(callsite(1)+"%d is int64, not []byte", value))
-		case float64:
-			panic(fmt.Errorf(callsite(1)+"%d is float64, not []byte", value))
-		case bool:
-			panic(fmt.Errorf(callsite(1)+"%v is bool, not []byte", value))
-		case []byte:
-			return value
-		case string:
-			return []byte(value)
-		case time.Time:
-			panic(fmt.Errorf(callsite(1)+"%v is time.Time, not []byte", value))
-		case nil:
-			return nil
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not []byte", value))
-		}
+		return staticBytes(row.staticColumnValue(format, 1), 1)
 	}
 	if row.sqlRows == nil {
 		row.fields = append(row.fields, Expr(format, values...))
@@ -367,10 +698,37 @@ func (row *Row) Bytes(format string, values ...any) []byte {
 	return b
 }
 
+// BytesAt returns the []byte value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) BytesAt(index int) []byte {
+	return staticBytes(row.staticColumnValueAt("BytesAt", index, 1), 1)
+}
+
+func staticBytes(v columnValue, skip int) []byte {
+	switch value := v.value.(type) {
+	case int64:
+		panic(newMappingError(v, "[]byte", skip+1))
+	case float64:
+		panic(newMappingError(v, "[]byte", skip+1))
+	case bool:
+		panic(newMappingError(v, "[]byte", skip+1))
+	case []byte:
+		return value
+	case string:
+		return []byte(value)
+	case time.Time:
+		panic(newMappingError(v, "[]byte", skip+1))
+	case nil:
+		return nil
+	default:
+		panic(newMappingError(v, "[]byte", skip+1))
+	}
+}
+
 // BytesField returns the []byte value of the field.
 func (row *Row) BytesField(field Binary) []byte {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call BytesField for static queries"))
+		return staticBytes(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	if row.sqlRows == nil {
 		row.fields = append(row.fields, field)
@@ -391,55 +749,141 @@ func (row *Row) BytesField(field Binary) []byte {
 	return b
 }
 
+// == Bits == //
+
+// Bits returns the bits of the BIT or VARBIT expression, from the most
+// significant bit to the least significant bit.
+func (row *Row) Bits(format string, values ...any) []bool {
+	if row.queryIsStatic {
+		return staticBits(row.dialect, row.staticColumnValue(format, 1), 1)
+	}
+	return row.bits(Expr(format, values...))
+}
+
+// BitsAt returns the bits of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) BitsAt(index int) []bool {
+	return staticBits(row.dialect, row.staticColumnValueAt("BitsAt", index, 1), 1)
+}
+
+func staticBits(dialect string, v columnValue, skip int) []bool {
+	bits, err := parseBits(dialect, v.value)
+	if err != nil {
+		panic(newMappingError(v, "bits", skip+1))
+	}
+	return bits
+}
+
+// BitsField returns the bits of the field, from the most significant bit to
+// the least significant bit.
+func (row *Row) BitsField(field BitField) []bool {
+	if row.queryIsStatic {
+		return staticBits(row.dialect, row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
+	}
+	return row.bits(field)
+}
+
+func (row *Row) bits(field Field) []bool {
+	if row.sqlRows == nil {
+		row.fields = append(row.fields, field)
+		row.scanDest = append(row.scanDest, &nullBits{
+			dialect: row.dialect,
+		})
+		return nil
+	}
+	defer func() {
+		row.runningIndex++
+	}()
+	scanDest := row.scanDest[row.runningIndex].(*nullBits)
+	return scanDest.bits
+}
+
+// Uint64Bits returns the uint64 value of the BIT or VARBIT expression. It
+// panics if the expression has more than 64 significant bits.
+func (row *Row) Uint64Bits(format string, values ...any) uint64 {
+	if row.queryIsStatic {
+		return bitsUint64(staticBits(row.dialect, row.staticColumnValue(format, 1), 1), 1)
+	}
+	return bitsUint64(row.bits(Expr(format, values...)), 1)
+}
+
+// Uint64BitsAt returns the uint64 value of the BIT or VARBIT column at the
+// given index. It can only be called for static queries.
+func (row *Row) Uint64BitsAt(index int) uint64 {
+	return bitsUint64(staticBits(row.dialect, row.staticColumnValueAt("Uint64BitsAt", index, 1), 1), 1)
+}
+
+// Uint64BitsField returns the uint64 value of the field. It panics if the
+// field has more than 64 significant bits.
+func (row *Row) Uint64BitsField(field BitField) uint64 {
+	if row.queryIsStatic {
+		return bitsUint64(staticBits(row.dialect, row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1), 1)
+	}
+	return bitsUint64(row.bits(field), 1)
+}
+
+func bitsUint64(bits []bool, skip int) uint64 {
+	n, err := bitsToUint64(bits)
+	if err != nil {
+		panic(fmt.Errorf(callsite(skip+1)+"%w", err))
+	}
+	return n
+}
+
 // == Bool == //
 
 // Bool returns the bool value of the expression.
 func (row *Row) Bool(format string, values ...any) bool {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
+		return staticBool(row.staticColumnValue(format, 1), 1)
+	}
+	return row.NullBoolField(Expr(format, values...)).Bool
+}
+
+// BoolAt returns the bool value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) BoolAt(index int) bool {
+	return staticBool(row.staticColumnValueAt("BoolAt", index, 1), 1)
+}
+
+func staticBool(v columnValue, skip int) bool {
+	switch value := v.value.(type) {
+	case int64:
+		if value == 1 {
+			return true
 		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			if value == 1 {
-				return true
-			}
-			if value == 0 {
-				return false
-			}
-			panic(fmt.Errorf(callsite(1)+"%d is int64, not bool", value))
-		case float64:
-			panic(fmt.Errorf(callsite(1)+"%d is float64, not bool", value))
-		case bool:
-			return value
-		case []byte:
-			// Special case: go-mysql-driver returns everything as []byte.
-			if string(value) == "1" {
-				return true
-			}
-			if string(value) == "0" {
-				return false
-			}
-			panic(fmt.Errorf(callsite(1)+"%#v is []byte, not bool", value))
-		case string:
-			panic(fmt.Errorf(callsite(1)+"%q is string, not bool", value))
-		case time.Time:
-			panic(fmt.Errorf(callsite(1)+"%v is time.Time, not bool", value))
-		case nil:
+		if value == 0 {
 			return false
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not bool", value))
 		}
+		panic(newMappingError(v, "bool", skip+1))
+	case float64:
+		panic(newMappingError(v, "bool", skip+1))
+	case bool:
+		return value
+	case []byte:
+		// Special case: go-mysql-driver returns everything as []byte.
+		if string(value) == "1" {
+			return true
+		}
+		if string(value) == "0" {
+			return false
+		}
+		panic(newMappingError(v, "bool", skip+1))
+	case string:
+		panic(newMappingError(v, "bool", skip+1))
+	case time.Time:
+		panic(newMappingError(v, "bool", skip+1))
+	case nil:
+		return false
+	default:
+		panic(newMappingError(v, "bool", skip+1))
 	}
-	return row.NullBoolField(Expr(format, values...)).Bool
 }
 
 // BoolField returns the bool value of the field.
 func (row *Row) BoolField(field Boolean) bool {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call BoolField for static queries"))
+		return staticBool(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	return row.NullBoolField(field).Bool
 }
@@ -447,50 +891,55 @@ func (row *Row) BoolField(field Boolean) bool {
 // NullBool returns the sql.NullBool value of the expression.
 func (row *Row) NullBool(format string, values ...any) sql.NullBool {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
+		return staticNullBool(row.staticColumnValue(format, 1), 1)
+	}
+	return row.NullBoolField(Expr(format, values...))
+}
+
+// NullBoolAt returns the sql.NullBool value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) NullBoolAt(index int) sql.NullBool {
+	return staticNullBool(row.staticColumnValueAt("NullBoolAt", index, 1), 1)
+}
+
+func staticNullBool(v columnValue, skip int) sql.NullBool {
+	switch value := v.value.(type) {
+	case int64:
+		if value == 1 {
+			return sql.NullBool{Bool: true, Valid: true}
 		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			if value == 1 {
-				return sql.NullBool{Bool: true, Valid: true}
-			}
-			if value == 0 {
-				return sql.NullBool{Bool: false, Valid: true}
-			}
-			panic(fmt.Errorf(callsite(1)+"%d is int64, not bool", value))
-		case float64:
-			panic(fmt.Errorf(callsite(1)+"%d is float64, not bool", value))
-		case bool:
-			return sql.NullBool{Bool: value, Valid: true}
-		case []byte:
-			// Special case: go-mysql-driver returns everything as []byte.
-			if string(value) == "1" {
-				return sql.NullBool{Bool: true, Valid: true}
-			}
-			if string(value) == "0" {
-				return sql.NullBool{Bool: false, Valid: true}
-			}
-			panic(fmt.Errorf(callsite(1)+"%d is []byte, not bool", value))
-		case string:
-			panic(fmt.Errorf(callsite(1)+"%q is string, not bool", value))
-		case time.Time:
-			panic(fmt.Errorf(callsite(1)+"%v is time.Time, not bool", value))
-		case nil:
-			return sql.NullBool{}
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not bool", value))
+		if value == 0 {
+			return sql.NullBool{Bool: false, Valid: true}
+		}
+		panic(newMappingError(v, "bool", skip+1))
+	case float64:
+		panic(newMappingError(v, "bool", skip+1))
+	case bool:
+		return sql.NullBool{Bool: value, Valid: true}
+	case []byte:
+		// Special case: go-mysql-driver returns everything as []byte.
+		if string(value) == "1" {
+			return sql.NullBool{Bool: true, Valid: true}
+		}
+		if string(value) == "0" {
+			return sql.NullBool{Bool: false, Valid: true}
 		}
+		panic(newMappingError(v, "bool", skip+1))
+	case string:
+		panic(newMappingError(v, "bool", skip+1))
+	case time.Time:
+		panic(newMappingError(v, "bool", skip+1))
+	case nil:
+		return sql.NullBool{}
+	default:
+		panic(newMappingError(v, "bool", skip+1))
 	}
-	return row.NullBoolField(Expr(format, values...))
 }
 
 // NullBoolField returns the sql.NullBool value of the field.
 func (row *Row) NullBoolField(field Boolean) sql.NullBool {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call NullBoolField for static queries"))
+		return staticNullBool(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	if row.sqlRows == nil {
 		row.fields = append(row.fields, field)
@@ -507,48 +956,71 @@ func (row *Row) NullBoolField(field Boolean) sql.NullBool {
 // Enum scans the enum expression into destPtr.
 func (row *Row) Enum(destPtr Enumeration, format string, values ...any) {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call Enum for static queries"))
+		staticEnum(destPtr, row.staticColumnValue(format, 1), 1)
+		return
 	}
 	row.enum(destPtr, Expr(format, values...), 1)
 }
 
+// EnumAt scans the enum column at the given index into destPtr. It can only be
+// called for static queries.
+func (row *Row) EnumAt(destPtr Enumeration, index int) {
+	staticEnum(destPtr, row.staticColumnValueAt("EnumAt", index, 1), 1)
+}
+
+func staticEnum(destPtr Enumeration, v columnValue, skip int) {
+	checkEnumDestPtr(destPtr, skip+1)
+	b, ok := staticRawBytes(v, fmt.Sprintf("%T", destPtr), skip+1)
+	scanEnum(destPtr, sql.NullString{String: string(b), Valid: ok}, skip+1)
+}
+
 // EnumField scans the enum field into destPtr.
 func (row *Row) EnumField(destPtr Enumeration, field Enum) {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call EnumField for static queries"))
+		staticEnum(destPtr, row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
+		return
 	}
 	row.enum(destPtr, field, 1)
 }
 
+func checkEnumDestPtr(destPtr Enumeration, skip int) {
+	destType := reflect.TypeOf(destPtr)
+	if destType.Kind() != reflect.Ptr {
+		panic(fmt.Errorf(callsite(skip+1)+"cannot pass in non pointer value (%#v) as destPtr", destPtr))
+	}
+	switch destType.Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.String:
+		break
+	default:
+		panic(fmt.Errorf(callsite(skip+1)+"underlying type of %[1]v is neither an integer or string (%[1]T)", destPtr))
+	}
+}
+
 func (row *Row) enum(destPtr Enumeration, field Enum, skip int) {
 	if row.sqlRows == nil {
-		destType := reflect.TypeOf(destPtr)
-		if destType.Kind() != reflect.Ptr {
-			panic(fmt.Errorf(callsite(skip+1)+"cannot pass in non pointer value (%#v) as destPtr", destPtr))
-		}
+		checkEnumDestPtr(destPtr, skip+1)
 		row.fields = append(row.fields, field)
-		switch destType.Elem().Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-			reflect.String:
-			row.scanDest = append(row.scanDest, &sql.NullString{})
-		default:
-			panic(fmt.Errorf(callsite(skip+1)+"underlying type of %[1]v is neither an integer or string (%[1]T)", destPtr))
-		}
+		row.scanDest = append(row.scanDest, &sql.NullString{})
 		return
 	}
 	defer func() {
 		row.runningIndex++
 	}()
-	scanDest := row.scanDest[row.runningIndex].(*sql.NullString)
+	scanEnum(destPtr, *row.scanDest[row.runningIndex].(*sql.NullString), skip+1)
+}
+
+// scanEnum sets destPtr to the enum value named by the string.
+func scanEnum(destPtr Enumeration, value sql.NullString, skip int) {
 	names := destPtr.Enumerate()
 	enumIndex := 0
 	destValue := reflect.ValueOf(destPtr).Elem()
-	if scanDest.Valid {
-		enumIndex = getEnumIndex(scanDest.String, names, destValue.Type())
+	if value.Valid {
+		enumIndex = getEnumIndex(value.String, names, destValue.Type())
 	}
 	if enumIndex < 0 {
-		panic(fmt.Errorf(callsite(skip+1)+"%q is not a valid %T", scanDest.String, destPtr))
+		panic(fmt.Errorf(callsite(skip+1)+"%q is not a valid %T", value.String, destPtr))
 	}
 	switch destValue.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -556,92 +1028,102 @@ func (row *Row) enum(destPtr Enumeration, field Enum, skip int) {
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		destValue.SetUint(uint64(enumIndex))
 	case reflect.String:
-		destValue.SetString(scanDest.String)
+		destValue.SetString(value.String)
 	}
 }
 
 // Float64 returns the float64 value of the expression.
 func (row *Row) Float64(format string, values ...any) float64 {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			return float64(value)
-		case float64:
-			return value
-		case bool:
-			panic(fmt.Errorf(callsite(1)+"%v is bool, not float64", value))
-		case []byte:
-			// Special case: go-mysql-driver returns everything as []byte.
-			n, err := strconv.ParseFloat(string(value), 64)
-			if err != nil {
-				panic(fmt.Errorf(callsite(1)+"%d is []byte, not float64", value))
-			}
-			return n
-		case string:
-			panic(fmt.Errorf(callsite(1)+"%q is string, not float64", value))
-		case time.Time:
-			panic(fmt.Errorf(callsite(1)+"%v is time.Time, not float64", value))
-		case nil:
-			return 0
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not float64", value))
-		}
+		return staticFloat64(row.staticColumnValue(format, 1), 1)
 	}
 	return row.NullFloat64Field(Expr(format, values...)).Float64
 }
 
+// Float64At returns the float64 value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) Float64At(index int) float64 {
+	return staticFloat64(row.staticColumnValueAt("Float64At", index, 1), 1)
+}
+
+func staticFloat64(v columnValue, skip int) float64 {
+	switch value := v.value.(type) {
+	case int64:
+		return float64(value)
+	case float64:
+		return value
+	case bool:
+		panic(newMappingError(v, "float64", skip+1))
+	case []byte:
+		// Special case: go-mysql-driver returns everything as []byte.
+		n, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			panic(newMappingError(v, "float64", skip+1))
+		}
+		return n
+	case string:
+		panic(newMappingError(v, "float64", skip+1))
+	case time.Time:
+		panic(newMappingError(v, "float64", skip+1))
+	case nil:
+		return 0
+	default:
+		panic(newMappingError(v, "float64", skip+1))
+	}
+}
+
 // Float64Field returns the float64 value of the field.
 func (row *Row) Float64Field(field Number) float64 {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call Float64Field for static queries"))
+		return staticFloat64(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	return row.NullFloat64Field(field).Float64
 }
 
-// NullFloat64 returns the sql.NullFloat64 valye of the expression.
+// NullFloat64 returns the sql.NullFloat64 value of the expression.
 func (row *Row) NullFloat64(format string, values ...any) sql.NullFloat64 {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			return sql.NullFloat64{Float64: float64(value), Valid: true}
-		case float64:
-			return sql.NullFloat64{Float64: value, Valid: true}
-		case bool:
-			panic(fmt.Errorf(callsite(1)+"%v is bool, not float64", value))
-		case []byte:
-			// Special case: go-mysql-driver returns everything as []byte.
-			n, err := strconv.ParseFloat(string(value), 64)
-			if err != nil {
-				panic(fmt.Errorf(callsite(1)+"%d is []byte, not float64", value))
-			}
-			return sql.NullFloat64{Float64: n, Valid: true}
-		case string:
-			panic(fmt.Errorf(callsite(1)+"%q is string, not float64", value))
-		case time.Time:
-			panic(fmt.Errorf(callsite(1)+"%v is time.Time, not float64", value))
-		case nil:
-			return sql.NullFloat64{}
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not float64", value))
-		}
+		return staticNullFloat64(row.staticColumnValue(format, 1), 1)
 	}
 	return row.NullFloat64Field(Expr(format, values...))
 }
 
+// NullFloat64At returns the sql.NullFloat64 value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) NullFloat64At(index int) sql.NullFloat64 {
+	return staticNullFloat64(row.staticColumnValueAt("NullFloat64At", index, 1), 1)
+}
+
+func staticNullFloat64(v columnValue, skip int) sql.NullFloat64 {
+	switch value := v.value.(type) {
+	case int64:
+		return sql.NullFloat64{Float64: float64(value), Valid: true}
+	case float64:
+		return sql.NullFloat64{Float64: value, Valid: true}
+	case bool:
+		panic(newMappingError(v, "float64", skip+1))
+	case []byte:
+		// Special case: go-mysql-driver returns everything as []byte.
+		n, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			panic(newMappingError(v, "float64", skip+1))
+		}
+		return sql.NullFloat64{Float64: n, Valid: true}
+	case string:
+		panic(newMappingError(v, "float64", skip+1))
+	case time.Time:
+		panic(newMappingError(v, "float64", skip+1))
+	case nil:
+		return sql.NullFloat64{}
+	default:
+		panic(newMappingError(v, "float64", skip+1))
+	}
+}
+
 // NullFloat64Field returns the sql.NullFloat64 value of the field.
 func (row *Row) NullFloat64Field(field Number) sql.NullFloat64 {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call NullFloat64Field for static queries"))
+		return staticNullFloat64(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	if row.sqlRows == nil {
 		row.fields = append(row.fields, field)
@@ -658,42 +1140,47 @@ func (row *Row) NullFloat64Field(field Number) sql.NullFloat64 {
 // Int returns the int value of the expression.
 func (row *Row) Int(format string, values ...any) int {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			return int(value)
-		case float64:
-			return int(value)
-		case bool:
-			panic(fmt.Errorf(callsite(1)+"%v is bool, not int", value))
-		case []byte:
-			// Special case: go-mysql-driver returns everything as []byte.
-			n, err := strconv.Atoi(string(value))
-			if err != nil {
-				panic(fmt.Errorf(callsite(1)+"%d is []byte, not int", value))
-			}
-			return n
-		case string:
-			panic(fmt.Errorf(callsite(1)+"%q is string, not int", value))
-		case time.Time:
-			panic(fmt.Errorf(callsite(1)+"%v is time.Time, not int", value))
-		case nil:
-			return 0
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not int", value))
-		}
+		return staticInt(row.staticColumnValue(format, 1), 1)
 	}
 	return int(row.NullInt64Field(Expr(format, values...)).Int64)
 }
 
+// IntAt returns the int value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) IntAt(index int) int {
+	return staticInt(row.staticColumnValueAt("IntAt", index, 1), 1)
+}
+
+func staticInt(v columnValue, skip int) int {
+	switch value := v.value.(type) {
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	case bool:
+		panic(newMappingError(v, "int", skip+1))
+	case []byte:
+		// Special case: go-mysql-driver returns everything as []byte.
+		n, err := strconv.Atoi(string(value))
+		if err != nil {
+			panic(newMappingError(v, "int", skip+1))
+		}
+		return n
+	case string:
+		panic(newMappingError(v, "int", skip+1))
+	case time.Time:
+		panic(newMappingError(v, "int", skip+1))
+	case nil:
+		return 0
+	default:
+		panic(newMappingError(v, "int", skip+1))
+	}
+}
+
 // IntField returns the int value of the field.
 func (row *Row) IntField(field Number) int {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call IntField for static queries"))
+		return staticInt(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	return int(row.NullInt64Field(field).Int64)
 }
@@ -701,42 +1188,47 @@ func (row *Row) IntField(field Number) int {
 // Int64 returns the int64 value of the expression.
 func (row *Row) Int64(format string, values ...any) int64 {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			return int64(value)
-		case float64:
-			return int64(value)
-		case bool:
-			panic(fmt.Errorf(callsite(1)+"%v is bool, not int64", value))
-		case []byte:
-			// Special case: go-mysql-driver returns everything as []byte.
-			n, err := strconv.ParseInt(string(value), 10, 64)
-			if err != nil {
-				panic(fmt.Errorf(callsite(1)+"%d is []byte, not int64", value))
-			}
-			return n
-		case string:
-			panic(fmt.Errorf(callsite(1)+"%q is string, not int64", value))
-		case time.Time:
-			panic(fmt.Errorf(callsite(1)+"%v is time.Time, not int64", value))
-		case nil:
-			return 0
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not int64", value))
-		}
+		return staticInt64(row.staticColumnValue(format, 1), 1)
 	}
 	return row.NullInt64Field(Expr(format, values...)).Int64
 }
 
+// Int64At returns the int64 value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) Int64At(index int) int64 {
+	return staticInt64(row.staticColumnValueAt("Int64At", index, 1), 1)
+}
+
+func staticInt64(v columnValue, skip int) int64 {
+	switch value := v.value.(type) {
+	case int64:
+		return int64(value)
+	case float64:
+		return int64(value)
+	case bool:
+		panic(newMappingError(v, "int64", skip+1))
+	case []byte:
+		// Special case: go-mysql-driver returns everything as []byte.
+		n, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			panic(newMappingError(v, "int64", skip+1))
+		}
+		return n
+	case string:
+		panic(newMappingError(v, "int64", skip+1))
+	case time.Time:
+		panic(newMappingError(v, "int64", skip+1))
+	case nil:
+		return 0
+	default:
+		panic(newMappingError(v, "int64", skip+1))
+	}
+}
+
 // Int64Field returns the int64 value of the field.
 func (row *Row) Int64Field(field Number) int64 {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call Int64Field for static queries"))
+		return staticInt64(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	return row.NullInt64Field(field).Int64
 }
@@ -744,42 +1236,47 @@ func (row *Row) Int64Field(field Number) int64 {
 // NullInt64 returns the sql.NullInt64 value of the expression.
 func (row *Row) NullInt64(format string, values ...any) sql.NullInt64 {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			return sql.NullInt64{Int64: value, Valid: true}
-		case float64:
-			return sql.NullInt64{Int64: int64(value), Valid: true}
-		case bool:
-			panic(fmt.Errorf(callsite(1)+"%v is bool, not int64", value))
-		case []byte:
-			// Special case: go-mysql-driver returns everything as []byte.
-			n, err := strconv.ParseInt(string(value), 10, 64)
-			if err != nil {
-				panic(fmt.Errorf(callsite(1)+"%d is []byte, not int64", value))
-			}
-			return sql.NullInt64{Int64: n, Valid: true}
-		case string:
-			panic(fmt.Errorf(callsite(1)+"%q is string, not int64", value))
-		case time.Time:
-			panic(fmt.Errorf(callsite(1)+"%v is time.Time, not int64", value))
-		case nil:
-			return sql.NullInt64{}
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not int64", value))
-		}
+		return staticNullInt64(row.staticColumnValue(format, 1), 1)
 	}
 	return row.NullInt64Field(Expr(format, values...))
 }
 
+// NullInt64At returns the sql.NullInt64 value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) NullInt64At(index int) sql.NullInt64 {
+	return staticNullInt64(row.staticColumnValueAt("NullInt64At", index, 1), 1)
+}
+
+func staticNullInt64(v columnValue, skip int) sql.NullInt64 {
+	switch value := v.value.(type) {
+	case int64:
+		return sql.NullInt64{Int64: value, Valid: true}
+	case float64:
+		return sql.NullInt64{Int64: int64(value), Valid: true}
+	case bool:
+		panic(newMappingError(v, "int64", skip+1))
+	case []byte:
+		// Special case: go-mysql-driver returns everything as []byte.
+		n, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			panic(newMappingError(v, "int64", skip+1))
+		}
+		return sql.NullInt64{Int64: n, Valid: true}
+	case string:
+		panic(newMappingError(v, "int64", skip+1))
+	case time.Time:
+		panic(newMappingError(v, "int64", skip+1))
+	case nil:
+		return sql.NullInt64{}
+	default:
+		panic(newMappingError(v, "int64", skip+1))
+	}
+}
+
 // NullInt64Field returns the sql.NullInt64 value of the field.
 func (row *Row) NullInt64Field(field Number) sql.NullInt64 {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call NullInt64Field for static queries"))
+		return staticNullInt64(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	if row.sqlRows == nil {
 		row.fields = append(row.fields, field)
@@ -796,24 +1293,45 @@ func (row *Row) NullInt64Field(field Number) sql.NullInt64 {
 // JSON scans the JSON expression into destPtr.
 func (row *Row) JSON(destPtr any, format string, values ...any) {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call JSON for static queries"))
+		staticJSON(destPtr, row.staticColumnValue(format, 1), 1)
+		return
 	}
 	row.json(destPtr, Expr(format, values...), 1)
 }
 
+// JSONAt scans the JSON column at the given index into destPtr. It can only be
+// called for static queries.
+func (row *Row) JSONAt(destPtr any, index int) {
+	staticJSON(destPtr, row.staticColumnValueAt("JSONAt", index, 1), 1)
+}
+
+func staticJSON(destPtr any, v columnValue, skip int) {
+	checkJSONDestPtr(destPtr, skip+1)
+	b, ok := staticRawBytes(v, fmt.Sprintf("%T", destPtr), skip+1)
+	if !ok {
+		return
+	}
+	scanJSON(destPtr, b, skip+1)
+}
+
 // JSONField scans the JSON field into destPtr.
 func (row *Row) JSONField(destPtr any, field JSON) {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call JSONField for static queries"))
+		staticJSON(destPtr, row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
+		return
 	}
 	row.json(destPtr, field, 1)
 }
 
+func checkJSONDestPtr(destPtr any, skip int) {
+	if reflect.TypeOf(destPtr).Kind() != reflect.Ptr {
+		panic(fmt.Errorf(callsite(skip+1)+"cannot pass in non pointer value (%#v) as destPtr", destPtr))
+	}
+}
+
 func (row *Row) json(destPtr any, field JSON, skip int) {
 	if row.sqlRows == nil {
-		if reflect.TypeOf(destPtr).Kind() != reflect.Ptr {
-			panic(fmt.Errorf(callsite(skip+1)+"cannot pass in non pointer value (%#v) as destPtr", destPtr))
-		}
+		checkJSONDestPtr(destPtr, skip+1)
 		row.fields = append(row.fields, field)
 		row.scanDest = append(row.scanDest, &nullBytes{
 			dialect:     row.dialect,
@@ -826,86 +1344,212 @@ func (row *Row) json(destPtr any, field JSON, skip int) {
 	}()
 	scanDest := row.scanDest[row.runningIndex].(*nullBytes)
 	if scanDest.valid {
-		err := json.Unmarshal(scanDest.bytes, destPtr)
-		if err != nil {
-			_, file, line, _ := runtime.Caller(skip + 1)
-			panic(fmt.Errorf(callsite(skip+1)+"unmarshaling json %q into %T: %w", file, line, string(scanDest.bytes), destPtr, err))
-		}
+		scanJSON(destPtr, scanDest.bytes, skip+1)
+	}
+}
+
+// scanJSON unmarshals the raw bytes of a JSON value into destPtr.
+func scanJSON(destPtr any, b []byte, skip int) {
+	err := json.Unmarshal(b, destPtr)
+	if err != nil {
+		panic(fmt.Errorf(callsite(skip+1)+"unmarshaling json %q into %T: %w", string(b), destPtr, err))
+	}
+}
+
+// JSONRaw returns the raw JSON value of the expression, or nil if it is NULL.
+// Unlike JSON, it doesn't need a destination whose type matches the JSON,
+// which makes it suitable for schemaless payloads.
+func (row *Row) JSONRaw(format string, values ...any) json.RawMessage {
+	if row.queryIsStatic {
+		return staticJSONRaw(row.staticColumnValue(format, 1), 1)
+	}
+	return row.jsonRaw(Expr(format, values...))
+}
+
+// JSONRawAt returns the raw JSON value of the column at the given index, or
+// nil if it is NULL. It can only be called for static queries.
+func (row *Row) JSONRawAt(index int) json.RawMessage {
+	return staticJSONRaw(row.staticColumnValueAt("JSONRawAt", index, 1), 1)
+}
+
+func staticJSONRaw(v columnValue, skip int) json.RawMessage {
+	b, ok := staticRawBytes(v, "json.RawMessage", skip+1)
+	if !ok {
+		return nil
 	}
+	if !json.Valid(b) {
+		panic(newMappingError(v, "json.RawMessage", skip+1))
+	}
+	return json.RawMessage(b)
+}
+
+// JSONRawField returns the raw JSON value of the field, or nil if it is NULL.
+func (row *Row) JSONRawField(field JSON) json.RawMessage {
+	if row.queryIsStatic {
+		return staticJSONRaw(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
+	}
+	return row.jsonRaw(field)
+}
+
+func (row *Row) jsonRaw(field JSON) json.RawMessage {
+	if row.sqlRows == nil {
+		row.fields = append(row.fields, field)
+		row.scanDest = append(row.scanDest, &nullBytes{
+			dialect:     row.dialect,
+			displayType: displayTypeString,
+		})
+		return nil
+	}
+	defer func() {
+		row.runningIndex++
+	}()
+	scanDest := row.scanDest[row.runningIndex].(*nullBytes)
+	if !scanDest.valid {
+		return nil
+	}
+	b := make(json.RawMessage, len(scanDest.bytes))
+	copy(b, scanDest.bytes)
+	return b
+}
+
+// JSONMap returns the JSON object of the expression unmarshaled into a
+// map[string]any, or nil if it is NULL. JSON numbers are unmarshaled as
+// float64, like json.Unmarshal does. It panics if the value is not a JSON
+// object.
+func (row *Row) JSONMap(format string, values ...any) map[string]any {
+	if row.queryIsStatic {
+		return jsonMap(staticJSONRaw(row.staticColumnValue(format, 1), 1), 1)
+	}
+	return jsonMap(row.jsonRaw(Expr(format, values...)), 1)
+}
+
+// JSONMapAt returns the JSON object of the column at the given index
+// unmarshaled into a map[string]any, or nil if it is NULL. It can only be
+// called for static queries.
+func (row *Row) JSONMapAt(index int) map[string]any {
+	return jsonMap(staticJSONRaw(row.staticColumnValueAt("JSONMapAt", index, 1), 1), 1)
+}
+
+// JSONMapField returns the JSON object of the field unmarshaled into a
+// map[string]any, or nil if it is NULL. JSON numbers are unmarshaled as
+// float64, like json.Unmarshal does. It panics if the value is not a JSON
+// object.
+func (row *Row) JSONMapField(field JSON) map[string]any {
+	if row.queryIsStatic {
+		return jsonMap(staticJSONRaw(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1), 1)
+	}
+	return jsonMap(row.jsonRaw(field), 1)
+}
+
+func jsonMap(b json.RawMessage, skip int) map[string]any {
+	if b == nil {
+		return nil
+	}
+	var m map[string]any
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		panic(fmt.Errorf(callsite(skip+1)+"unmarshaling json %q into map[string]any: %w", string(b), err))
+	}
+	return m
 }
 
 // String returns the string value of the expression.
 func (row *Row) String(format string, values ...any) string {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			panic(fmt.Errorf(callsite(1)+"%d is int64, not string", value))
-		case float64:
-			panic(fmt.Errorf(callsite(1)+"%d is float64, not string", value))
-		case bool:
-			panic(fmt.Errorf(callsite(1)+"%v is bool, not string", value))
-		case []byte:
-			return string(value)
-		case string:
-			return value
-		case time.Time:
-			panic(fmt.Errorf(callsite(1)+"%v is time.Time, not string", value))
-		case nil:
-			return ""
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not string", value))
-		}
+		return staticString(row.staticColumnValue(format, 1), 1)
 	}
 	return row.NullStringField(Expr(format, values...)).String
 }
 
+// StringAt returns the string value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) StringAt(index int) string {
+	return staticString(row.staticColumnValueAt("StringAt", index, 1), 1)
+}
+
+func staticString(v columnValue, skip int) string {
+	switch value := v.value.(type) {
+	case int64:
+		panic(newMappingError(v, "string", skip+1))
+	case float64:
+		panic(newMappingError(v, "string", skip+1))
+	case bool:
+		panic(newMappingError(v, "string", skip+1))
+	case []byte:
+		return string(value)
+	case string:
+		return value
+	case time.Time:
+		panic(newMappingError(v, "string", skip+1))
+	case nil:
+		return ""
+	default:
+		panic(newMappingError(v, "string", skip+1))
+	}
+}
+
 // String returns the string value of the field.
 func (row *Row) StringField(field String) string {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call StringField for static queries"))
+		return staticString(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	return row.NullStringField(field).String
 }
 
+// MoneyField returns the Money value of the amount and currency fields of the
+// MoneyField. A NULL amount or currency is returned as zero.
+func (row *Row) MoneyField(field MoneyField) Money {
+	if row.queryIsStatic {
+		return Money{
+			Amount:   staticNullInt64(row.staticColumnValue(staticColumnName(row.dialect, field.amount), 1), 1).Int64,
+			Currency: staticNullString(row.staticColumnValue(staticColumnName(row.dialect, field.currency), 1), 1).String,
+		}
+	}
+	return Money{
+		Amount:   row.NullInt64Field(field.amount).Int64,
+		Currency: row.NullStringField(field.currency).String,
+	}
+}
+
 // NullString returns the sql.NullString value of the expression.
 func (row *Row) NullString(format string, values ...any) sql.NullString {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			panic(fmt.Errorf(callsite(1)+"%d is int64, not string", value))
-		case float64:
-			panic(fmt.Errorf(callsite(1)+"%d is float64, not string", value))
-		case bool:
-			panic(fmt.Errorf(callsite(1)+"%v is bool, not string", value))
-		case []byte:
-			return sql.NullString{String: string(value), Valid: true}
-		case string:
-			return sql.NullString{String: value, Valid: true}
-		case time.Time:
-			panic(fmt.Errorf(callsite(1)+"%v is time.Time, not string", value))
-		case nil:
-			return sql.NullString{}
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not string", value))
-		}
+		return staticNullString(row.staticColumnValue(format, 1), 1)
 	}
 	return row.NullStringField(Expr(format, values...))
 }
 
+// NullStringAt returns the sql.NullString value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) NullStringAt(index int) sql.NullString {
+	return staticNullString(row.staticColumnValueAt("NullStringAt", index, 1), 1)
+}
+
+func staticNullString(v columnValue, skip int) sql.NullString {
+	switch value := v.value.(type) {
+	case int64:
+		panic(newMappingError(v, "string", skip+1))
+	case float64:
+		panic(newMappingError(v, "string", skip+1))
+	case bool:
+		panic(newMappingError(v, "string", skip+1))
+	case []byte:
+		return sql.NullString{String: string(value), Valid: true}
+	case string:
+		return sql.NullString{String: value, Valid: true}
+	case time.Time:
+		panic(newMappingError(v, "string", skip+1))
+	case nil:
+		return sql.NullString{}
+	default:
+		panic(newMappingError(v, "string", skip+1))
+	}
+}
+
 // NullStringField returns the sql.NullString value of the field.
 func (row *Row) NullStringField(field String) sql.NullString {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call NullStringField for static queries"))
+		return staticNullString(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	if row.sqlRows == nil {
 		row.fields = append(row.fields, field)
@@ -935,44 +1579,49 @@ var sqliteTimestampFormats = []string{
 // Time returns the time.Time value of the expression.
 func (row *Row) Time(format string, values ...any) time.Time {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			panic(fmt.Errorf(callsite(1)+"%d is int64, not time.Time", value))
-		case float64:
-			panic(fmt.Errorf(callsite(1)+"%d is float64, not time.Time", value))
-		case bool:
-			panic(fmt.Errorf(callsite(1)+"%v is bool, not time.Time", value))
-		case []byte:
-			// Special case: go-mysql-driver returns everything as []byte.
-			s := strings.TrimSuffix(string(value), "Z")
-			for _, format := range sqliteTimestampFormats {
-				if t, err := time.ParseInLocation(format, s, time.UTC); err == nil {
-					return t
-				}
+		return staticTime(row.staticColumnValue(format, 1), 1)
+	}
+	return row.NullTimeField(Expr(format, values...)).Time
+}
+
+// TimeAt returns the time.Time value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) TimeAt(index int) time.Time {
+	return staticTime(row.staticColumnValueAt("TimeAt", index, 1), 1)
+}
+
+func staticTime(v columnValue, skip int) time.Time {
+	switch value := v.value.(type) {
+	case int64:
+		panic(newMappingError(v, "time.Time", skip+1))
+	case float64:
+		panic(newMappingError(v, "time.Time", skip+1))
+	case bool:
+		panic(newMappingError(v, "time.Time", skip+1))
+	case []byte:
+		// Special case: go-mysql-driver returns everything as []byte.
+		s := strings.TrimSuffix(string(value), "Z")
+		for _, format := range sqliteTimestampFormats {
+			if t, err := time.ParseInLocation(format, s, time.UTC); err == nil {
+				return t
 			}
-			panic(fmt.Errorf(callsite(1)+"%d is []byte, not time.Time", value))
-		case string:
-			panic(fmt.Errorf(callsite(1)+"%q is string, not time.Time", value))
-		case time.Time:
-			return value
-		case nil:
-			return time.Time{}
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not time.Time", value))
 		}
+		panic(newMappingError(v, "time.Time", skip+1))
+	case string:
+		panic(newMappingError(v, "time.Time", skip+1))
+	case time.Time:
+		return value
+	case nil:
+		return time.Time{}
+	default:
+		panic(newMappingError(v, "time.Time", skip+1))
 	}
-	return row.NullTimeField(Expr(format, values...)).Time
 }
 
 // Time returns the time.Time value of the field.
 func (row *Row) TimeField(field Time) time.Time {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call TimeField for static queries"))
+		return staticTime(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	return row.NullTimeField(field).Time
 }
@@ -980,44 +1629,49 @@ func (row *Row) TimeField(field Time) time.Time {
 // NullTime returns the sql.NullTime value of the expression.
 func (row *Row) NullTime(format string, values ...any) sql.NullTime {
 	if row.queryIsStatic {
-		index, ok := row.columnIndex[format]
-		if !ok {
-			panic(fmt.Errorf(callsite(1)+"column %s does not exist (available columns: %s)", format, strings.Join(row.columns, ", ")))
-		}
-		value := row.values[index]
-		switch value := value.(type) {
-		case int64:
-			panic(fmt.Errorf(callsite(1)+"%d is int64, not time.Time", value))
-		case float64:
-			panic(fmt.Errorf(callsite(1)+"%d is float64, not time.Time", value))
-		case bool:
-			panic(fmt.Errorf(callsite(1)+"%v is bool, not time.Time", value))
-		case []byte:
-			// Special case: go-mysql-driver returns everything as []byte.
-			s := strings.TrimSuffix(string(value), "Z")
-			for _, format := range sqliteTimestampFormats {
-				if t, err := time.ParseInLocation(format, s, time.UTC); err == nil {
-					return sql.NullTime{Time: t, Valid: true}
-				}
+		return staticNullTime(row.staticColumnValue(format, 1), 1)
+	}
+	return row.NullTimeField(Expr(format, values...))
+}
+
+// NullTimeAt returns the sql.NullTime value of the column at the given index.
+// It can only be called for static queries.
+func (row *Row) NullTimeAt(index int) sql.NullTime {
+	return staticNullTime(row.staticColumnValueAt("NullTimeAt", index, 1), 1)
+}
+
+func staticNullTime(v columnValue, skip int) sql.NullTime {
+	switch value := v.value.(type) {
+	case int64:
+		panic(newMappingError(v, "time.Time", skip+1))
+	case float64:
+		panic(newMappingError(v, "time.Time", skip+1))
+	case bool:
+		panic(newMappingError(v, "time.Time", skip+1))
+	case []byte:
+		// Special case: go-mysql-driver returns everything as []byte.
+		s := strings.TrimSuffix(string(value), "Z")
+		for _, format := range sqliteTimestampFormats {
+			if t, err := time.ParseInLocation(format, s, time.UTC); err == nil {
+				return sql.NullTime{Time: t, Valid: true}
 			}
-			panic(fmt.Errorf(callsite(1)+"%d is []byte, not time.Time", value))
-		case string:
-			panic(fmt.Errorf(callsite(1)+"%q is string, not time.Time", value))
-		case time.Time:
-			return sql.NullTime{Time: value, Valid: true}
-		case nil:
-			return sql.NullTime{}
-		default:
-			panic(fmt.Errorf(callsite(1)+"%[1]v is %[1]T, not time.Time", value))
 		}
+		panic(newMappingError(v, "time.Time", skip+1))
+	case string:
+		panic(newMappingError(v, "time.Time", skip+1))
+	case time.Time:
+		return sql.NullTime{Time: value, Valid: true}
+	case nil:
+		return sql.NullTime{}
+	default:
+		panic(newMappingError(v, "time.Time", skip+1))
 	}
-	return row.NullTimeField(Expr(format, values...))
 }
 
 // NullTimeField returns the sql.NullTime value of the field.
 func (row *Row) NullTimeField(field Time) sql.NullTime {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call NullTimeField for static queries"))
+		return staticNullTime(row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
 	}
 	if row.sqlRows == nil {
 		row.fields = append(row.fields, field)
@@ -1034,30 +1688,49 @@ func (row *Row) NullTimeField(field Time) sql.NullTime {
 // UUID scans the UUID expression into destPtr.
 func (row *Row) UUID(destPtr any, format string, values ...any) {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call UUID for static queries"))
+		staticUUID(destPtr, row.staticColumnValue(format, 1), 1)
+		return
 	}
 	row.uuid(destPtr, Expr(format, values...), 1)
 }
 
+// UUIDAt scans the UUID column at the given index into destPtr. It can only be
+// called for static queries.
+func (row *Row) UUIDAt(destPtr any, index int) {
+	staticUUID(destPtr, row.staticColumnValueAt("UUIDAt", index, 1), 1)
+}
+
+func staticUUID(destPtr any, v columnValue, skip int) {
+	checkUUIDDestPtr(destPtr, skip+1)
+	b, _ := staticRawBytes(v, fmt.Sprintf("%T", destPtr), skip+1)
+	scanUUID(destPtr, b, skip+1)
+}
+
 // UUIDField scans the UUID field into destPtr.
 func (row *Row) UUIDField(destPtr any, field UUID) {
 	if row.queryIsStatic {
-		panic(fmt.Errorf(callsite(1) + "cannot call UUIDField for static queries"))
+		staticUUID(destPtr, row.staticColumnValue(staticColumnName(row.dialect, field), 1), 1)
+		return
 	}
 	row.uuid(destPtr, field, 1)
 }
 
+func checkUUIDDestPtr(destPtr any, skip int) {
+	if _, ok := destPtr.(*[16]byte); ok {
+		return
+	}
+	if reflect.TypeOf(destPtr).Kind() != reflect.Ptr {
+		panic(fmt.Errorf(callsite(skip+1)+"cannot pass in non pointer value (%#v) as destPtr", destPtr))
+	}
+	destValue := reflect.ValueOf(destPtr).Elem()
+	if destValue.Kind() != reflect.Array || destValue.Len() != 16 || destValue.Type().Elem().Kind() != reflect.Uint8 {
+		panic(fmt.Errorf(callsite(skip+1)+"%T is not a pointer to a [16]byte", destPtr))
+	}
+}
+
 func (row *Row) uuid(destPtr any, field UUID, skip int) {
 	if row.sqlRows == nil {
-		if _, ok := destPtr.(*[16]byte); !ok {
-			if reflect.TypeOf(destPtr).Kind() != reflect.Ptr {
-				panic(fmt.Errorf(callsite(skip+1)+"cannot pass in non pointer value (%#v) as destPtr", destPtr))
-			}
-			destValue := reflect.ValueOf(destPtr).Elem()
-			if destValue.Kind() != reflect.Array || destValue.Len() != 16 || destValue.Type().Elem().Kind() != reflect.Uint8 {
-				panic(fmt.Errorf(callsite(skip+1)+"%T is not a pointer to a [16]byte", destPtr))
-			}
-		}
+		checkUUIDDestPtr(destPtr, skip+1)
 		row.fields = append(row.fields, field)
 		row.scanDest = append(row.scanDest, &nullBytes{
 			dialect:     row.dialect,
@@ -1068,15 +1741,21 @@ func (row *Row) uuid(destPtr any, field UUID, skip int) {
 	defer func() {
 		row.runningIndex++
 	}()
-	scanDest := row.scanDest[row.runningIndex].(*nullBytes)
+	scanUUID(destPtr, row.scanDest[row.runningIndex].(*nullBytes).bytes, skip+1)
+}
+
+// scanUUID decodes the raw bytes of a UUID into destPtr. The bytes are either
+// the 16 bytes of the UUID or its string representation. A NULL UUID is
+// decoded as the zero UUID.
+func scanUUID(destPtr any, b []byte, skip int) {
 	var err error
 	var uuid [16]byte
-	if len(scanDest.bytes) == 16 {
-		copy(uuid[:], scanDest.bytes)
-	} else if len(scanDest.bytes) > 0 {
-		uuid, err = googleuuid.ParseBytes(scanDest.bytes)
+	if len(b) == 16 {
+		copy(uuid[:], b)
+	} else if len(b) > 0 {
+		uuid, err = googleuuid.ParseBytes(b)
 		if err != nil {
-			panic(fmt.Errorf(callsite(skip+1)+"parsing %q as UUID string: %w", string(scanDest.bytes), err))
+			panic(fmt.Errorf(callsite(skip+1)+"parsing %q as UUID string: %w", string(b), err))
 		}
 	}
 	if destArrayPtr, ok := destPtr.(*[16]byte); ok {
@@ -1179,6 +1858,64 @@ func (col *Column) SetJSON(field JSON, value any) { col.Set(field, JSONValue(val
 // type should be [16]byte.
 func (col *Column) SetUUID(field UUID, value any) { col.Set(field, UUIDValue(value)) }
 
+// SetMoney maps the amount and currency of the Money value to the fields of
+// the MoneyField.
+func (col *Column) SetMoney(field MoneyField, value Money) {
+	col.Set(field.amount, value.Amount)
+	col.Set(field.currency, value.Currency)
+}
+
+// MappingError is returned by FetchOne, FetchAll and Cursor.Result when a
+// rowmapper reads a column of a static query as the wrong type, e.g. calling
+// row.Int("first_name") when first_name is a string.
+//
+//	var mappingErr *sq.MappingError
+//	if errors.As(err, &mappingErr) {
+//	    log.Printf("column %s: expected %s, got %s", mappingErr.Column, mappingErr.Expected, mappingErr.Actual)
+//	}
+type MappingError struct {
+	// Column is the name of the result set column.
+	Column string
+	// Expected is the type requested by the rowmapper.
+	Expected string
+	// Actual is the type of the value returned by the database driver.
+	Actual string
+	// Value is the value returned by the database driver.
+	Value any
+	// Callsite is the file:line of the rowmapper call that read the
+	// column.
+	Callsite string
+}
+
+func newMappingError(v columnValue, expected string, skip int) *MappingError {
+	_, file, line, _ := runtime.Caller(skip + 1)
+	return &MappingError{
+		Column:   v.column,
+		Expected: expected,
+		Actual:   fmt.Sprintf("%T", v.value),
+		Value:    v.value,
+		Callsite: filepath.Base(file) + ":" + strconv.Itoa(line),
+	}
+}
+
+// Error implements the error interface.
+func (e *MappingError) Error() string {
+	var value string
+	switch v := e.Value.(type) {
+	case string:
+		value = strconv.Quote(v)
+	case []byte:
+		value = strconv.Quote(string(v))
+	default:
+		value = fmt.Sprint(v)
+	}
+	var prefix string
+	if e.Callsite != "" {
+		prefix = e.Callsite + ": "
+	}
+	return prefix + "column " + e.Column + ": " + value + " is " + e.Actual + ", not " + e.Expected
+}
+
 func callsite(skip int) string {
 	_, file, line, ok := runtime.Caller(skip + 1)
 	if !ok {
@@ -1245,3 +1982,24 @@ func (n *nullBytes) Value() (driver.Value, error) {
 		return n.bytes, nil
 	}
 }
+
+type nullBits struct {
+	bits    []bool
+	dialect string
+}
+
+func (n *nullBits) Scan(value any) error {
+	bits, err := parseBits(n.dialect, value)
+	if err != nil {
+		return err
+	}
+	n.bits = bits
+	return nil
+}
+
+func (n *nullBits) Value() (driver.Value, error) {
+	if n.bits == nil {
+		return nil, nil
+	}
+	return bitsToString(n.bits), nil
+}
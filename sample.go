@@ -0,0 +1,88 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Random returns an expression that evaluates to a random value for every
+// row: random() (Postgres, SQLite), RAND() (MySQL) or NEWID() (SQL Server).
+// Ordering by it shuffles the rows.
+//
+//	// SELECT ... FROM film ORDER BY random()
+//	q := sq.From(f).OrderBy(sq.Random())
+func Random() Expression {
+	return Expr("{}", random{})
+}
+
+type random struct{}
+
+// WriteSQL implements the SQLWriter interface.
+func (random) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	switch dialect {
+	case DialectMySQL:
+		buf.WriteString("RAND()")
+	case DialectSQLServer:
+		buf.WriteString("NEWID()")
+	case DialectPostgres, DialectSQLite:
+		buf.WriteString("random()")
+	default:
+		buf.WriteString("RANDOM()")
+	}
+	return nil
+}
+
+// sample rewrites the SampleRows and SamplePercent of the SelectQuery into
+// the clauses that implement them for the dialect. tableSample is the
+// TABLESAMPLE clause to be written after the FROM table, if any.
+func (q SelectQuery) sample(dialect string) (query SelectQuery, tableSample string, err error) {
+	if q.SampleRows != nil {
+		if len(q.OrderByFields) > 0 {
+			return q, "", fmt.Errorf("Sample cannot be used with ORDER BY")
+		}
+		if q.LimitRows != nil || q.LimitTop != nil || q.LimitTopPercent != nil || q.FetchNextRows != nil {
+			return q, "", fmt.Errorf("Sample cannot be used with LIMIT, TOP or FETCH NEXT")
+		}
+		q.OrderByFields = []Field{Random()}
+		if dialect == DialectSQLServer {
+			q.LimitTop = q.SampleRows
+		} else {
+			q.LimitRows = q.SampleRows
+		}
+	}
+	if q.SampleRowsPercent == 0 {
+		return q, "", nil
+	}
+	if !(q.SampleRowsPercent > 0 && q.SampleRowsPercent <= 100) {
+		return q, "", fmt.Errorf("SamplePercent: percent must be greater than 0 and at most 100, got %v", q.SampleRowsPercent)
+	}
+	percent := strconv.FormatFloat(q.SampleRowsPercent, 'f', -1, 64)
+	// TABLESAMPLE can only be used on a table, not a subquery.
+	_, fromTableIsQuery := q.FromTable.(Query)
+	if q.FromTable != nil && !fromTableIsQuery {
+		switch dialect {
+		case DialectPostgres:
+			return q, " TABLESAMPLE SYSTEM (" + percent + ")", nil
+		case DialectSQLServer:
+			return q, " TABLESAMPLE (" + percent + " PERCENT)", nil
+		}
+	}
+	// Otherwise each row is picked with a probability of SampleRowsPercent.
+	threshold := strconv.FormatInt(int64(q.SampleRowsPercent*10000), 10)
+	var predicate Predicate
+	switch dialect {
+	case DialectSQLite:
+		// random() returns a signed 64-bit integer.
+		predicate = Expr("(random() % 1000000 + 1000000) % 1000000 < " + threshold)
+	case DialectSQLServer:
+		// RAND() is only evaluated once per query, so the checksum of a
+		// NEWID() is used instead.
+		predicate = Expr("(CHECKSUM(NEWID()) % 1000000 + 1000000) % 1000000 < " + threshold)
+	default:
+		predicate = Expr("{} < "+strconv.FormatFloat(q.SampleRowsPercent/100, 'f', -1, 64), Random())
+	}
+	q.WherePredicate = appendPredicates(q.WherePredicate, []Predicate{predicate})
+	return q, "", nil
+}
@@ -0,0 +1,118 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestSample(t *testing.T) {
+	type FILM struct {
+		TableStruct
+		FILM_ID NumberField
+		TITLE   StringField
+	}
+	f := New[FILM]("f")
+
+	tests := []TestTable{{
+		description: "Random",
+		dialect:     DialectMySQL,
+		item:        MySQL.Select(f.TITLE).From(f).OrderBy(Random()),
+		wantQuery:   "SELECT f.title FROM film AS f ORDER BY RAND()",
+	}, {
+		description: "postgres Sample",
+		dialect:     DialectPostgres,
+		item:        Postgres.Select(f.TITLE).From(f).Where(f.FILM_ID.GtInt(10)).Sample(5),
+		wantQuery:   "SELECT f.title FROM film AS f WHERE f.film_id > $1 ORDER BY random() LIMIT $2",
+		wantArgs:    []any{10, 5},
+	}, {
+		description: "sqlserver Sample",
+		dialect:     DialectSQLServer,
+		item:        SQLServer.Select(f.TITLE).From(f).Sample(5),
+		wantQuery:   "SELECT TOP (@p1) f.title FROM film AS f ORDER BY NEWID()",
+		wantArgs:    []any{5},
+	}, {
+		description: "postgres SamplePercent",
+		dialect:     DialectPostgres,
+		item:        Postgres.Select(f.TITLE).From(f).Where(f.FILM_ID.GtInt(10)).SamplePercent(2.5),
+		wantQuery:   "SELECT f.title FROM film AS f TABLESAMPLE SYSTEM (2.5) WHERE f.film_id > $1",
+		wantArgs:    []any{10},
+	}, {
+		description: "sqlserver SamplePercent",
+		dialect:     DialectSQLServer,
+		item:        SQLServer.Select(f.TITLE).From(f).SamplePercent(10),
+		wantQuery:   "SELECT f.title FROM film AS f TABLESAMPLE (10 PERCENT)",
+	}, {
+		description: "sqlserver SamplePercent subquery",
+		dialect:     DialectSQLServer,
+		item:        SQLServer.Select(Expr("x.title")).From(SQLServer.Select(f.TITLE).From(f).As("x")).SamplePercent(10),
+		wantQuery: "SELECT x.title FROM (SELECT f.title FROM film AS f) AS x" +
+			" WHERE (CHECKSUM(NEWID()) % 1000000 + 1000000) % 1000000 < 100000",
+	}, {
+		description: "mysql SamplePercent",
+		dialect:     DialectMySQL,
+		item:        MySQL.Select(f.TITLE).From(f).Where(f.FILM_ID.GtInt(10)).SamplePercent(10),
+		wantQuery:   "SELECT f.title FROM film AS f WHERE f.film_id > ? AND RAND() < 0.1",
+		wantArgs:    []any{10},
+	}, {
+		description: "sqlite SamplePercent",
+		dialect:     DialectSQLite,
+		item:        SQLite.Select(f.TITLE).From(f).SamplePercent(10),
+		wantQuery:   "SELECT f.title FROM film AS f WHERE (random() % 1000000 + 1000000) % 1000000 < 100000",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "Sample with ORDER BY",
+		item:        Select(f.TITLE).From(f).OrderBy(f.TITLE).Sample(5),
+	}, {
+		description: "Sample with LIMIT",
+		item:        Select(f.TITLE).From(f).Limit(10).Sample(5),
+	}, {
+		description: "SamplePercent out of range",
+		item:        Select(f.TITLE).From(f).SamplePercent(120),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := db.Exec("WITH RECURSIVE n (i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 100)" +
+			" INSERT INTO actor (actor_id, first_name, last_name) SELECT i, 'FIRST', 'LAST' FROM n")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		actorIDs, err := FetchAll(db, SQLite.From(ACTOR).Sample(10), func(row *Row) int {
+			return row.IntField(ACTOR.ACTOR_ID)
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if len(actorIDs) != 10 {
+			t.Errorf(testutil.Callers()+" expected 10 rows, got %d", len(actorIDs))
+		}
+		actorIDs, err = FetchAll(db, SQLite.From(ACTOR).SamplePercent(100), func(row *Row) int {
+			return row.IntField(ACTOR.ACTOR_ID)
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if len(actorIDs) != 100 {
+			t.Errorf(testutil.Callers()+" expected 100 rows, got %d", len(actorIDs))
+		}
+	})
+}
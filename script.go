@@ -0,0 +1,169 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ExecScript splits an SQL script (e.g. a schema file) into statements and
+// executes them one by one. If db is (or wraps) an *sql.DB, the statements
+// are executed inside a transaction which is rolled back if any statement
+// fails (note that MySQL implicitly commits after most DDL statements). Otherwise the
+// statements are executed on db as-is, so a transaction can be passed in
+// instead.
+//
+// Statements are separated by semicolons. Semicolons inside strings, quoted
+// identifiers, comments and the BEGIN ... END body of a CREATE TRIGGER are not
+// treated as separators. Strings and identifiers are quoted the way the
+// dialect of db (see NewDB and DefaultDialect) quotes them: postgres also has
+// dollar-quoted strings (e.g. function bodies) and E'...' strings with
+// backslash escapes, mysql has `identifiers`, '#' line comments and backslash
+// escapes in strings and sqlserver has [identifiers].
+func ExecScript(db DB, script string) error {
+	return execScript(context.Background(), db, script, 1)
+}
+
+// ExecScriptContext is like ExecScript but additionally requires a
+// context.Context.
+func ExecScriptContext(ctx context.Context, db DB, script string) error {
+	return execScript(ctx, db, script, 1)
+}
+
+func execScript(ctx context.Context, db DB, script string, skip int) (err error) {
+	if db == nil {
+		return fmt.Errorf("db is nil")
+	}
+	statements := splitStatements(dbDialect(db), script)
+	if len(statements) == 0 {
+		return nil
+	}
+	if sqlDB, ok := unwrapSQLDB(db); ok {
+		var tx *sql.Tx
+		tx, err = sqlDB.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				_ = tx.Rollback()
+				return
+			}
+			err = tx.Commit()
+		}()
+		db = rewrapDB(db, tx)
+	}
+	for i, statement := range statements {
+		// Escape '{' so that the statement is not treated as a format string.
+		_, err = exec(ctx, db, Queryf(strings.ReplaceAll(statement, "{", "{{")), skip+1)
+		if err != nil {
+			return fmt.Errorf("statement #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// splitStatements splits an SQL script into its statements, dropping empty
+// statements and the separating semicolons.
+func splitStatements(dialect string, script string) []string {
+	var statements []string
+	var words []string // the first few words of the current statement
+	var lastWord string
+	var depth int // BEGIN/CASE ... END nesting depth
+	var word strings.Builder
+	start := 0
+	flushWord := func() {
+		if word.Len() == 0 {
+			return
+		}
+		lastWord = strings.ToUpper(word.String())
+		switch lastWord {
+		case "BEGIN", "CASE":
+			depth++
+		case "END":
+			depth--
+		}
+		if len(words) < 4 {
+			words = append(words, lastWord)
+		}
+		word.Reset()
+	}
+	for i := 0; i < len(script); i++ {
+		// is the current char the start of a string, quoted identifier,
+		// comment or dollar-quoted string?
+		if end := skipLiteral(dialect, script, i); end > i {
+			flushWord()
+			i = end - 1
+			continue
+		}
+		char := script[i]
+		switch {
+		case char == ';':
+			flushWord()
+			// Semicolons inside a trigger body only terminate the
+			// statement after the final END.
+			if isCreateTrigger(words) && (lastWord != "END" || depth > 0) {
+				continue
+			}
+			if statement := strings.TrimSpace(script[start:i]); statement != "" && !isOnlyComments(dialect, statement) {
+				statements = append(statements, statement)
+			}
+			start = i + 1
+			words = words[:0]
+			lastWord = ""
+			depth = 0
+		case char == '_' || isASCIILetterOrDigit(char):
+			word.WriteByte(char)
+		default:
+			flushWord()
+		}
+	}
+	if start < len(script) {
+		if statement := strings.TrimSpace(script[start:]); statement != "" && !isOnlyComments(dialect, statement) {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+func isASCIILetterOrDigit(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+}
+
+// isCreateTrigger reports whether the first words of a statement are CREATE
+// [TEMP | TEMPORARY] TRIGGER.
+func isCreateTrigger(words []string) bool {
+	if len(words) < 2 || words[0] != "CREATE" {
+		return false
+	}
+	if words[1] == "TEMP" || words[1] == "TEMPORARY" {
+		return len(words) > 2 && words[2] == "TRIGGER"
+	}
+	return words[1] == "TRIGGER"
+}
+
+// isOnlyComments reports whether a trailing statement consists only of
+// comments.
+func isOnlyComments(dialect string, statement string) bool {
+	for len(statement) > 0 {
+		statement = strings.TrimSpace(statement)
+		switch {
+		case strings.HasPrefix(statement, "--") || (dialect == DialectMySQL && strings.HasPrefix(statement, "#")):
+			end := strings.IndexByte(statement, '\n')
+			if end < 0 {
+				return true
+			}
+			statement = statement[end+1:]
+		case strings.HasPrefix(statement, "/*"):
+			end := strings.Index(statement, "*/")
+			if end < 0 {
+				return true
+			}
+			statement = statement[end+2:]
+		default:
+			return statement == ""
+		}
+	}
+	return true
+}
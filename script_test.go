@@ -0,0 +1,169 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func Test_splitStatements(t *testing.T) {
+	type TestTable struct {
+		description    string
+		dialect        string
+		script         string
+		wantStatements []string
+	}
+
+	tests := []TestTable{{
+		description: "empty",
+		script:      " \n-- nothing here\n; /* or here */ ",
+	}, {
+		description:    "strings and identifiers",
+		script:         `INSERT INTO "a;b" VALUES ('x;''y');SELECT 1`,
+		wantStatements: []string{`INSERT INTO "a;b" VALUES ('x;''y')`, "SELECT 1"},
+	}, {
+		description:    "sqlserver identifiers",
+		dialect:        DialectSQLServer,
+		script:         "SELECT [c;d]; SELECT `e;f`",
+		wantStatements: []string{"SELECT [c;d]", "SELECT `e", "f`"},
+	}, {
+		description:    "mysql identifiers",
+		dialect:        DialectMySQL,
+		script:         "SELECT `e;f`; SELECT ARRAY[1;2]",
+		wantStatements: []string{"SELECT `e;f`", "SELECT ARRAY[1", "2]"},
+	}, {
+		description:    "postgres escape strings",
+		dialect:        DialectPostgres,
+		script:         `SELECT E'it\'s;', 'a\'; SELECT e'\\'; SELECT 3`,
+		wantStatements: []string{`SELECT E'it\'s;', 'a\'`, `SELECT e'\\'`, "SELECT 3"},
+	}, {
+		description: "comments",
+		script:      "-- first; statement\nSELECT 1; /* second;\n statement */ SELECT 2;\n-- trailing;",
+		wantStatements: []string{
+			"-- first; statement\nSELECT 1",
+			"/* second;\n statement */ SELECT 2",
+		},
+	}, {
+		description: "mysql hash comments and backslash escapes",
+		dialect:     DialectMySQL,
+		script:      "# first; statement\nSELECT 'it\\'s;', \"a\\\";b\"; SELECT 2; # trailing;",
+		wantStatements: []string{
+			"# first; statement\nSELECT 'it\\'s;', \"a\\\";b\"",
+			"SELECT 2",
+		},
+	}, {
+		description:    "hash is not a comment outside mysql",
+		dialect:        DialectPostgres,
+		script:         "SELECT '{a,b}'::jsonb #> '{a}'; SELECT 2",
+		wantStatements: []string{"SELECT '{a,b}'::jsonb #> '{a}'", "SELECT 2"},
+	}, {
+		description: "dollar quoting",
+		dialect:     DialectPostgres,
+		script: "CREATE FUNCTION f() RETURNS int AS $body$ BEGIN RETURN 1; END; $body$ LANGUAGE plpgsql;\n" +
+			"SELECT $$a;b$$, $1;",
+		wantStatements: []string{
+			"CREATE FUNCTION f() RETURNS int AS $body$ BEGIN RETURN 1; END; $body$ LANGUAGE plpgsql",
+			"SELECT $$a;b$$, $1",
+		},
+	}, {
+		description: "trigger",
+		script: "CREATE TEMP TRIGGER t AFTER INSERT ON actor BEGIN\n" +
+			"    UPDATE actor SET last_name = CASE WHEN NEW.last_name = '' THEN 'x' END WHERE actor_id = NEW.actor_id;\n" +
+			"    SELECT 1;\n" +
+			"END;\n" +
+			"SELECT 2",
+		wantStatements: []string{
+			"CREATE TEMP TRIGGER t AFTER INSERT ON actor BEGIN\n" +
+				"    UPDATE actor SET last_name = CASE WHEN NEW.last_name = '' THEN 'x' END WHERE actor_id = NEW.actor_id;\n" +
+				"    SELECT 1;\n" +
+				"END",
+			"SELECT 2",
+		},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			gotStatements := splitStatements(tt.dialect, tt.script)
+			if diff := testutil.Diff(gotStatements, tt.wantStatements); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
+
+func TestExecScript(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	err := ExecScript(db, `
+CREATE TABLE film (film_id INTEGER PRIMARY KEY, title TEXT, attributes TEXT);
+-- a comment; with a semicolon
+INSERT INTO film (film_id, title, attributes) VALUES (1, 'ACADEMY; DINOSAUR', '{"rating": "PG"}');
+INSERT INTO film (film_id, title) VALUES (2, 'ACE GOLDFINGER');
+`)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM film WHERE title = 'ACADEMY; DINOSAUR' AND attributes = '{\"rating\": \"PG\"}'").Scan(&count)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if count != 1 {
+		t.Errorf(testutil.Callers()+" expected 1 row, got %d", count)
+	}
+
+	// A failing statement rolls back the whole script.
+	err = ExecScript(db, "INSERT INTO film (film_id, title) VALUES (3, 'ADAPTATION HOLES'); INSERT INTO nonexistent VALUES (1);")
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error but got nil")
+	}
+	err = db.QueryRow("SELECT COUNT(*) FROM film").Scan(&count)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if count != 2 {
+		t.Errorf(testutil.Callers()+" expected 2 rows, got %d", count)
+	}
+
+	t.Run("wrapped *sql.DB", func(t *testing.T) {
+		t.Parallel()
+		sqlDB, counts := newFileDB(t)
+		err := ExecScript(NewDB(sqlDB, DialectSQLite), "INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'PENELOPE', 'GUINESS'); INSERT INTO nonexistent VALUES (1);")
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		var count int
+		err = sqlDB.QueryRow("SELECT COUNT(*) FROM actor").Scan(&count)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if count != 0 {
+			t.Errorf(testutil.Callers()+" expected 0 rows, got %d", count)
+		}
+		if diff := testutil.Diff(counts.rollbacks.Load(), int32(1)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("logged *sql.DB", func(t *testing.T) {
+		t.Parallel()
+		sqlDB, counts := newFileDB(t)
+		err := ExecScript(Log(sqlDB), "INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'PENELOPE', 'GUINESS'); INSERT INTO nonexistent VALUES (1);")
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		var count int
+		err = sqlDB.QueryRow("SELECT COUNT(*) FROM actor").Scan(&count)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if count != 0 {
+			t.Errorf(testutil.Callers()+" expected 0 rows, got %d", count)
+		}
+		if diff := testutil.Diff(counts.rollbacks.Load(), int32(1)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
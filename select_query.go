@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 )
 
 // SelectQuery represents an SQL SELECT query.
@@ -39,12 +40,22 @@ type SelectQuery struct {
 	// FETCH NEXT
 	FetchNextRows any
 	FetchWithTies bool
+	// SAMPLE
+	SampleRows        any
+	SampleRowsPercent float64
 	// FOR UPDATE | FOR SHARE
-	LockClause string
-	LockValues []any
+	LockClause     string
+	LockValues     []any
+	LockStrength   string
+	LockOfTables   []Table
+	LockNoWait     bool
+	LockSkipLocked bool
 	// AS
 	Alias   string
 	Columns []string
+	// DefaultFilterTable and DefaultOrderTable
+	NoDefaultFilter  bool
+	NoDefaultOrderBy bool
 }
 
 var _ interface {
@@ -56,6 +67,10 @@ var _ interface {
 
 // WriteSQL implements the SQLWriter interface.
 func (q SelectQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	ctx = withWriteState(ctx)
+	state := getWriteState(ctx)
+	state.pushScope(q.JoinTables, q.FromTable)
+	defer state.popScope()
 	var err error
 	if len(q.SelectFields) == 0 {
 		return fmt.Errorf("SELECT: no fields provided")
@@ -72,12 +87,43 @@ func (q SelectQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 			return fmt.Errorf("%s %s Policy: %w", joinTable.JoinOperator, joinTable.Table, err)
 		}
 	}
+	// Default Filters
+	if !q.NoDefaultFilter {
+		policies, err = appendDefaultFilter(ctx, dialect, policies, q.FromTable)
+		if err != nil {
+			return fmt.Errorf("FROM %s DefaultFilter: %w", toString(q.Dialect, q.FromTable), err)
+		}
+		q.JoinTables, err = joinDefaultFilters(ctx, dialect, &policies, q.JoinTables)
+		if err != nil {
+			return err
+		}
+	}
 	if len(policies) > 0 {
 		if q.WherePredicate != nil {
 			policies = append(policies, q.WherePredicate)
 		}
 		q.WherePredicate = And(policies...)
 	}
+	// Default Ordering
+	if !q.NoDefaultOrderBy && len(q.OrderByFields) == 0 && len(state.scopes) == 1 &&
+		q.SampleRows == nil && q.SampleRowsPercent == 0 &&
+		len(q.GroupByFields) == 0 && q.HavingPredicate == nil && !q.Distinct && len(q.DistinctOnFields) == 0 &&
+		!hasAggregate(q.SelectFields) {
+		if orderTable, ok := q.FromTable.(DefaultOrderTable); ok {
+			q.OrderByFields, err = orderTable.DefaultOrderBy(ctx, dialect)
+			if err != nil {
+				return fmt.Errorf("FROM %s DefaultOrderBy: %w", toString(q.Dialect, q.FromTable), err)
+			}
+		}
+	}
+	// SAMPLE
+	var tableSample string
+	if q.SampleRows != nil || q.SampleRowsPercent != 0 {
+		q, tableSample, err = q.sample(dialect)
+		if err != nil {
+			return err
+		}
+	}
 	// WITH
 	if len(q.CTEs) > 0 {
 		err = writeCTEs(ctx, dialect, buf, args, params, q.CTEs)
@@ -134,10 +180,15 @@ func (q SelectQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 			buf.WriteString(")")
 		}
 		if alias := getAlias(q.FromTable); alias != "" {
-			buf.WriteString(" AS " + QuoteIdentifier(dialect, alias) + quoteTableColumns(dialect, q.FromTable))
+			quotedAlias, err := quoteAlias(ctx, dialect, alias)
+			if err != nil {
+				return fmt.Errorf("FROM: %w", err)
+			}
+			buf.WriteString(" AS " + quotedAlias + quoteTableColumns(dialect, q.FromTable))
 		} else if isQuery && dialect != DialectSQLite {
 			return fmt.Errorf("%s FROM subquery must have alias", dialect)
 		}
+		buf.WriteString(tableSample)
 	}
 	// JOIN
 	if len(q.JoinTables) > 0 {
@@ -214,7 +265,7 @@ func (q SelectQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 			return fmt.Errorf("sqlserver does not support LIMIT")
 		}
 		buf.WriteString(" LIMIT ")
-		err = WriteValue(ctx, dialect, buf, args, params, q.LimitRows)
+		err = writeRowCount(ctx, dialect, buf, args, params, q.LimitRows)
 		if err != nil {
 			return fmt.Errorf("LIMIT: %w", err)
 		}
@@ -230,7 +281,7 @@ func (q SelectQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 			}
 		}
 		buf.WriteString(" OFFSET ")
-		err = WriteValue(ctx, dialect, buf, args, params, q.OffsetRows)
+		err = writeRowCount(ctx, dialect, buf, args, params, q.OffsetRows)
 		if err != nil {
 			return fmt.Errorf("OFFSET: %w", err)
 		}
@@ -253,7 +304,7 @@ func (q SelectQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 			return fmt.Errorf("%s does not support FETCH NEXT", dialect)
 		}
 		buf.WriteString(" FETCH NEXT ")
-		err = WriteValue(ctx, dialect, buf, args, params, q.FetchNextRows)
+		err = writeRowCount(ctx, dialect, buf, args, params, q.FetchNextRows)
 		if err != nil {
 			return fmt.Errorf("FETCH NEXT: %w", err)
 		}
@@ -271,6 +322,68 @@ func (q SelectQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 		}
 	}
 	// FOR UPDATE | FOR SHARE
+	if q.LockStrength != "" {
+		if q.LockClause != "" {
+			return fmt.Errorf("cannot use both LockRows and FOR %s", q.LockStrength)
+		}
+		switch dialect {
+		case DialectPostgres:
+			if q.Distinct || len(q.DistinctOnFields) > 0 {
+				return fmt.Errorf("postgres FOR %s is not allowed with DISTINCT", q.LockStrength)
+			}
+			if len(q.GroupByFields) > 0 {
+				return fmt.Errorf("postgres FOR %s is not allowed with GROUP BY", q.LockStrength)
+			}
+			if q.HavingPredicate != nil {
+				return fmt.Errorf("postgres FOR %s is not allowed with HAVING", q.LockStrength)
+			}
+		case DialectMySQL:
+			if q.LockStrength != "UPDATE" && q.LockStrength != "SHARE" {
+				return fmt.Errorf("mysql does not support FOR %s", q.LockStrength)
+			}
+		default:
+			return fmt.Errorf("%s does not support FOR %s", dialect, q.LockStrength)
+		}
+		if q.LockNoWait && q.LockSkipLocked {
+			return fmt.Errorf("cannot use both NOWAIT and SKIP LOCKED")
+		}
+		buf.WriteString(" FOR " + q.LockStrength)
+		if len(q.LockOfTables) > 0 {
+			buf.WriteString(" OF ")
+			for i, table := range q.LockOfTables {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				if table == nil {
+					return fmt.Errorf("OF: table #%d is nil", i+1)
+				}
+				alias := getAlias(table)
+				if alias == "" {
+					err = table.WriteSQL(ctx, dialect, buf, args, params)
+					if err != nil {
+						return fmt.Errorf("OF: table #%d: %w", i+1, err)
+					}
+					continue
+				}
+				if !hasTableAlias(alias, q.FromTable, q.JoinTables) {
+					return fmt.Errorf("OF: table alias %q is not present in the FROM clause", alias)
+				}
+				quotedAlias, err := quoteAlias(ctx, dialect, alias)
+				if err != nil {
+					return fmt.Errorf("OF: %w", err)
+				}
+				buf.WriteString(quotedAlias)
+			}
+		}
+		if q.LockNoWait {
+			buf.WriteString(" NOWAIT")
+		}
+		if q.LockSkipLocked {
+			buf.WriteString(" SKIP LOCKED")
+		}
+	} else if len(q.LockOfTables) > 0 || q.LockNoWait || q.LockSkipLocked {
+		return fmt.Errorf("OF, NOWAIT and SKIP LOCKED require FOR UPDATE or FOR SHARE")
+	}
 	if q.LockClause != "" {
 		buf.WriteString(" ")
 		err = Writef(ctx, dialect, buf, args, params, q.LockClause, q.LockValues)
@@ -281,6 +394,152 @@ func (q SelectQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 	return nil
 }
 
+// joinDefaultFilters adds the default filters of the join tables (see
+// DefaultFilterTable) to their ON predicates, so that the filter of a table in
+// an outer join doesn't filter out the rows of the other tables. The filters of
+// join tables without an ON predicate (e.g. CROSS JOIN or JOIN USING) are
+// appended to predicates instead, to be added to the WHERE clause. The
+// joinTables slice itself is never modified.
+func joinDefaultFilters(ctx context.Context, dialect string, predicates *[]Predicate, joinTables []JoinTable) ([]JoinTable, error) {
+	var newJoinTables []JoinTable
+	for i, joinTable := range joinTables {
+		filters, err := appendDefaultFilter(ctx, dialect, nil, joinTable.Table)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s DefaultFilter: %w", joinTable.JoinOperator, toString(dialect, joinTable.Table), err)
+		}
+		if len(filters) == 0 {
+			continue
+		}
+		if joinTable.OnPredicate == nil {
+			*predicates = append(*predicates, filters...)
+			continue
+		}
+		if newJoinTables == nil {
+			newJoinTables = make([]JoinTable, len(joinTables))
+			copy(newJoinTables, joinTables)
+		}
+		onPredicate := joinTable.OnPredicate
+		if p, ok := onPredicate.(VariadicPredicate); ok {
+			// Clip the slice so that appending doesn't overwrite the
+			// predicates of the caller's JoinTable.
+			p.Predicates = p.Predicates[:len(p.Predicates):len(p.Predicates)]
+			onPredicate = p
+		}
+		newJoinTables[i].OnPredicate = appendPredicates(onPredicate, filters)
+	}
+	if newJoinTables == nil {
+		return joinTables, nil
+	}
+	return newJoinTables, nil
+}
+
+// aggregateFunctions are the aggregate functions that hasAggregate looks for.
+var aggregateFunctions = map[string]bool{
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true,
+	"EVERY": true, "BOOL_AND": true, "BOOL_OR": true, "BIT_AND": true,
+	"BIT_OR": true, "BIT_XOR": true, "STRING_AGG": true, "GROUP_CONCAT": true,
+	"ARRAY_AGG": true, "JSON_AGG": true, "JSONB_AGG": true,
+	"JSON_OBJECT_AGG": true, "JSONB_OBJECT_AGG": true, "JSON_ARRAYAGG": true,
+	"JSON_OBJECTAGG": true, "JSON_GROUP_ARRAY": true, "JSON_GROUP_OBJECT": true,
+	"STDDEV": true, "STDDEV_POP": true, "STDDEV_SAMP": true, "STDEV": true,
+	"STDEVP": true, "VARIANCE": true, "VAR_POP": true, "VAR_SAMP": true,
+	"VAR": true, "VARP": true, "CORR": true, "PERCENTILE_CONT": true,
+	"PERCENTILE_DISC": true,
+}
+
+// hasAggregate reports whether any of the fields calls an aggregate function
+// outside of a window function. Without a GROUP BY, an aggregate collapses
+// all rows into one, so ordering them by a column is an error. Only
+// AggregateExpressions and Expressions (e.g. sq.CountStar()) are checked.
+func hasAggregate(fields []Field) bool {
+	for _, field := range fields {
+		if valueHasAggregate(field) {
+			return true
+		}
+	}
+	return false
+}
+
+func valueHasAggregate(value any) bool {
+	switch value := value.(type) {
+	case AggregateExpression:
+		return true
+	case Expression:
+		if formatHasAggregate(value.format) {
+			return true
+		}
+		for _, v := range value.values {
+			if valueHasAggregate(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatHasAggregate reports whether an Expression format calls an aggregate
+// function. Formats containing OVER are treated as window functions.
+func formatHasAggregate(format string) bool {
+	var found bool
+	for i := 0; i < len(format); {
+		if !isIdentifierChar(format[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(format) && isIdentifierChar(format[i]) {
+			i++
+		}
+		word := strings.ToUpper(format[start:i])
+		if word == "OVER" {
+			return false
+		}
+		if aggregateFunctions[word] && strings.HasPrefix(strings.TrimLeft(format[i:], " "), "(") {
+			found = true
+		}
+	}
+	return found
+}
+
+func isIdentifierChar(char byte) bool {
+	return char == '_' || char == '.' || ('0' <= char && char <= '9') || ('a' <= char && char <= 'z') || ('A' <= char && char <= 'Z')
+}
+
+// DedupFields returns the fields with duplicates removed, keeping the first
+// occurrence of each field. Two fields are duplicates if they render to the
+// same SQL with the same args and have the same alias, so a.ACTOR_ID and
+// a.ACTOR_ID.As("id") are not duplicates.
+func DedupFields(dialect string, fields []Field) []Field {
+	if len(fields) < 2 {
+		return fields
+	}
+	buf := bufpool.Get().(*bytes.Buffer)
+	defer bufpool.Put(buf)
+	seen := make(map[string]struct{}, len(fields))
+	dedupedFields := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		if field == nil {
+			dedupedFields = append(dedupedFields, field)
+			continue
+		}
+		buf.Reset()
+		var args []any
+		err := field.WriteSQL(context.Background(), dialect, buf, &args, nil)
+		if err != nil {
+			// Let the error surface when the query is built.
+			dedupedFields = append(dedupedFields, field)
+			continue
+		}
+		key := buf.String() + "\x00" + getAlias(field) + "\x00" + fmt.Sprintf("%#v", args)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		dedupedFields = append(dedupedFields, field)
+	}
+	return dedupedFields
+}
+
 // Select creates a new SelectQuery.
 func Select(fields ...Field) SelectQuery {
 	return SelectQuery{SelectFields: fields}
@@ -323,6 +582,13 @@ func (q SelectQuery) SelectOne(fields ...Field) SelectQuery {
 	return q
 }
 
+// DedupFields removes duplicate fields from the SelectFields in the
+// SelectQuery (see the DedupFields function).
+func (q SelectQuery) DedupFields() SelectQuery {
+	q.SelectFields = DedupFields(q.Dialect, q.SelectFields)
+	return q
+}
+
 // From sets the FromTable field in the SelectQuery.
 func (q SelectQuery) From(table Table) SelectQuery {
 	q.FromTable = table
@@ -383,6 +649,20 @@ func (q SelectQuery) OrderBy(fields ...Field) SelectQuery {
 	return q
 }
 
+// WithoutDefaultFilter stops the default filters of the tables in the
+// SelectQuery (see DefaultFilterTable) from being applied.
+func (q SelectQuery) WithoutDefaultFilter() SelectQuery {
+	q.NoDefaultFilter = true
+	return q
+}
+
+// WithoutDefaultOrderBy stops the default ordering of the FROM table (see
+// DefaultOrderTable) from being applied to the SelectQuery.
+func (q SelectQuery) WithoutDefaultOrderBy() SelectQuery {
+	q.NoDefaultOrderBy = true
+	return q
+}
+
 // Limit sets the LimitRows field in the SelectQuery.
 func (q SelectQuery) Limit(limit any) SelectQuery {
 	q.LimitRows = limit
@@ -395,6 +675,20 @@ func (q SelectQuery) Offset(offset any) SelectQuery {
 	return q
 }
 
+// Sample sets the SampleRows field in the SelectQuery. The query returns n
+// random rows, by ordering the rows randomly and taking the first n.
+func (q SelectQuery) Sample(n any) SelectQuery {
+	q.SampleRows = n
+	return q
+}
+
+// SamplePercent sets the SampleRowsPercent field in the SelectQuery. The query
+// returns roughly the given percentage of rows, picked at random.
+func (q SelectQuery) SamplePercent(percent float64) SelectQuery {
+	q.SampleRowsPercent = percent
+	return q
+}
+
 // As returns a new SelectQuery with the table alias (and optionally column
 // aliases).
 func (q SelectQuery) As(alias string, columns ...string) SelectQuery {
@@ -541,6 +835,13 @@ func (q SQLiteSelectQuery) SelectOne(fields ...Field) SQLiteSelectQuery {
 	return q
 }
 
+// DedupFields removes duplicate fields from the SelectFields in the
+// SQLiteSelectQuery (see the DedupFields function).
+func (q SQLiteSelectQuery) DedupFields() SQLiteSelectQuery {
+	q.SelectFields = DedupFields(q.Dialect, q.SelectFields)
+	return q
+}
+
 // From sets the FromTable field in the SQLiteSelectQuery.
 func (q SQLiteSelectQuery) From(table Table) SQLiteSelectQuery {
 	q.FromTable = table
@@ -602,6 +903,20 @@ func (q SQLiteSelectQuery) OrderBy(fields ...Field) SQLiteSelectQuery {
 	return q
 }
 
+// WithoutDefaultFilter stops the default filters of the tables in the
+// SQLiteSelectQuery (see DefaultFilterTable) from being applied.
+func (q SQLiteSelectQuery) WithoutDefaultFilter() SQLiteSelectQuery {
+	q.NoDefaultFilter = true
+	return q
+}
+
+// WithoutDefaultOrderBy stops the default ordering of the FROM table (see
+// DefaultOrderTable) from being applied to the SQLiteSelectQuery.
+func (q SQLiteSelectQuery) WithoutDefaultOrderBy() SQLiteSelectQuery {
+	q.NoDefaultOrderBy = true
+	return q
+}
+
 // Limit sets the LimitRows field in the SQLiteSelectQuery.
 func (q SQLiteSelectQuery) Limit(limit any) SQLiteSelectQuery {
 	q.LimitRows = limit
@@ -614,6 +929,20 @@ func (q SQLiteSelectQuery) Offset(offset any) SQLiteSelectQuery {
 	return q
 }
 
+// Sample sets the SampleRows field in the SQLiteSelectQuery. The query returns
+// n random rows, by ordering the rows randomly and taking the first n.
+func (q SQLiteSelectQuery) Sample(n any) SQLiteSelectQuery {
+	q.SampleRows = n
+	return q
+}
+
+// SamplePercent sets the SampleRowsPercent field in the SQLiteSelectQuery. The
+// query returns roughly the given percentage of rows, picked at random.
+func (q SQLiteSelectQuery) SamplePercent(percent float64) SQLiteSelectQuery {
+	q.SampleRowsPercent = percent
+	return q
+}
+
 // As returns a new SQLiteSelectQuery with the table alias (and optionally
 // column aliases).
 func (q SQLiteSelectQuery) As(alias string, columns ...string) SQLiteSelectQuery {
@@ -775,6 +1104,13 @@ func (q PostgresSelectQuery) SelectOne(fields ...Field) PostgresSelectQuery {
 	return q
 }
 
+// DedupFields removes duplicate fields from the SelectFields in the
+// PostgresSelectQuery (see the DedupFields function).
+func (q PostgresSelectQuery) DedupFields() PostgresSelectQuery {
+	q.SelectFields = DedupFields(q.Dialect, q.SelectFields)
+	return q
+}
+
 // From sets the FromTable field in the PostgresSelectQuery.
 func (q PostgresSelectQuery) From(table Table) PostgresSelectQuery {
 	q.FromTable = table
@@ -842,6 +1178,20 @@ func (q PostgresSelectQuery) OrderBy(fields ...Field) PostgresSelectQuery {
 	return q
 }
 
+// WithoutDefaultFilter stops the default filters of the tables in the
+// PostgresSelectQuery (see DefaultFilterTable) from being applied.
+func (q PostgresSelectQuery) WithoutDefaultFilter() PostgresSelectQuery {
+	q.NoDefaultFilter = true
+	return q
+}
+
+// WithoutDefaultOrderBy stops the default ordering of the FROM table (see
+// DefaultOrderTable) from being applied to the PostgresSelectQuery.
+func (q PostgresSelectQuery) WithoutDefaultOrderBy() PostgresSelectQuery {
+	q.NoDefaultOrderBy = true
+	return q
+}
+
 // Limit sets the LimitRows field in the PostgresSelectQuery.
 func (q PostgresSelectQuery) Limit(limit any) PostgresSelectQuery {
 	q.LimitRows = limit
@@ -854,6 +1204,20 @@ func (q PostgresSelectQuery) Offset(offset any) PostgresSelectQuery {
 	return q
 }
 
+// Sample sets the SampleRows field in the PostgresSelectQuery. The query
+// returns n random rows, by ordering the rows randomly and taking the first n.
+func (q PostgresSelectQuery) Sample(n any) PostgresSelectQuery {
+	q.SampleRows = n
+	return q
+}
+
+// SamplePercent sets the SampleRowsPercent field in the PostgresSelectQuery.
+// The query returns roughly the given percentage of rows, picked at random.
+func (q PostgresSelectQuery) SamplePercent(percent float64) PostgresSelectQuery {
+	q.SampleRowsPercent = percent
+	return q
+}
+
 // FetchNext sets the FetchNextRows field in the PostgresSelectQuery.
 func (q PostgresSelectQuery) FetchNext(n any) PostgresSelectQuery {
 	q.FetchNextRows = n
@@ -873,6 +1237,51 @@ func (q PostgresSelectQuery) LockRows(lockClause string, lockValues ...any) Post
 	return q
 }
 
+// ForUpdate locks the selected rows with FOR UPDATE.
+func (q PostgresSelectQuery) ForUpdate() PostgresSelectQuery {
+	q.LockStrength = "UPDATE"
+	return q
+}
+
+// ForNoKeyUpdate locks the selected rows with FOR NO KEY UPDATE.
+func (q PostgresSelectQuery) ForNoKeyUpdate() PostgresSelectQuery {
+	q.LockStrength = "NO KEY UPDATE"
+	return q
+}
+
+// ForShare locks the selected rows with FOR SHARE.
+func (q PostgresSelectQuery) ForShare() PostgresSelectQuery {
+	q.LockStrength = "SHARE"
+	return q
+}
+
+// ForKeyShare locks the selected rows with FOR KEY SHARE.
+func (q PostgresSelectQuery) ForKeyShare() PostgresSelectQuery {
+	q.LockStrength = "KEY SHARE"
+	return q
+}
+
+// Of restricts the lock clause of the PostgresSelectQuery to the given
+// tables. It must be used together with ForUpdate or ForShare.
+func (q PostgresSelectQuery) Of(tables ...Table) PostgresSelectQuery {
+	q.LockOfTables = append(q.LockOfTables, tables...)
+	return q
+}
+
+// NoWait makes the lock clause of the PostgresSelectQuery fail immediately
+// instead of waiting if a selected row is already locked.
+func (q PostgresSelectQuery) NoWait() PostgresSelectQuery {
+	q.LockNoWait = true
+	return q
+}
+
+// SkipLocked makes the lock clause of the PostgresSelectQuery skip over
+// rows that are already locked instead of waiting for them.
+func (q PostgresSelectQuery) SkipLocked() PostgresSelectQuery {
+	q.LockSkipLocked = true
+	return q
+}
+
 // As returns a new PostgresSelectQuery with the table alias (and optionally
 // column aliases).
 func (q PostgresSelectQuery) As(alias string, columns ...string) PostgresSelectQuery {
@@ -1028,6 +1437,13 @@ func (q MySQLSelectQuery) SelectOne(fields ...Field) MySQLSelectQuery {
 	return q
 }
 
+// DedupFields removes duplicate fields from the SelectFields in the
+// MySQLSelectQuery (see the DedupFields function).
+func (q MySQLSelectQuery) DedupFields() MySQLSelectQuery {
+	q.SelectFields = DedupFields(q.Dialect, q.SelectFields)
+	return q
+}
+
 // From sets the FromTable field in the MySQLSelectQuery.
 func (q MySQLSelectQuery) From(table Table) MySQLSelectQuery {
 	q.FromTable = table
@@ -1095,6 +1511,20 @@ func (q MySQLSelectQuery) OrderBy(fields ...Field) MySQLSelectQuery {
 	return q
 }
 
+// WithoutDefaultFilter stops the default filters of the tables in the
+// MySQLSelectQuery (see DefaultFilterTable) from being applied.
+func (q MySQLSelectQuery) WithoutDefaultFilter() MySQLSelectQuery {
+	q.NoDefaultFilter = true
+	return q
+}
+
+// WithoutDefaultOrderBy stops the default ordering of the FROM table (see
+// DefaultOrderTable) from being applied to the MySQLSelectQuery.
+func (q MySQLSelectQuery) WithoutDefaultOrderBy() MySQLSelectQuery {
+	q.NoDefaultOrderBy = true
+	return q
+}
+
 // Limit sets the LimitRows field in the MySQLSelectQuery.
 func (q MySQLSelectQuery) Limit(limit any) MySQLSelectQuery {
 	q.LimitRows = limit
@@ -1107,6 +1537,20 @@ func (q MySQLSelectQuery) Offset(offset any) MySQLSelectQuery {
 	return q
 }
 
+// Sample sets the SampleRows field in the MySQLSelectQuery. The query returns
+// n random rows, by ordering the rows randomly and taking the first n.
+func (q MySQLSelectQuery) Sample(n any) MySQLSelectQuery {
+	q.SampleRows = n
+	return q
+}
+
+// SamplePercent sets the SampleRowsPercent field in the MySQLSelectQuery. The
+// query returns roughly the given percentage of rows, picked at random.
+func (q MySQLSelectQuery) SamplePercent(percent float64) MySQLSelectQuery {
+	q.SampleRowsPercent = percent
+	return q
+}
+
 // LockRows sets the lock clause of the MySQLSelectQuery.
 func (q MySQLSelectQuery) LockRows(lockClause string, lockValues ...any) MySQLSelectQuery {
 	q.LockClause = lockClause
@@ -1114,6 +1558,39 @@ func (q MySQLSelectQuery) LockRows(lockClause string, lockValues ...any) MySQLSe
 	return q
 }
 
+// ForUpdate locks the selected rows with FOR UPDATE.
+func (q MySQLSelectQuery) ForUpdate() MySQLSelectQuery {
+	q.LockStrength = "UPDATE"
+	return q
+}
+
+// ForShare locks the selected rows with FOR SHARE.
+func (q MySQLSelectQuery) ForShare() MySQLSelectQuery {
+	q.LockStrength = "SHARE"
+	return q
+}
+
+// Of restricts the lock clause of the MySQLSelectQuery to the given
+// tables. It must be used together with ForUpdate or ForShare.
+func (q MySQLSelectQuery) Of(tables ...Table) MySQLSelectQuery {
+	q.LockOfTables = append(q.LockOfTables, tables...)
+	return q
+}
+
+// NoWait makes the lock clause of the MySQLSelectQuery fail immediately
+// instead of waiting if a selected row is already locked.
+func (q MySQLSelectQuery) NoWait() MySQLSelectQuery {
+	q.LockNoWait = true
+	return q
+}
+
+// SkipLocked makes the lock clause of the MySQLSelectQuery skip over
+// rows that are already locked instead of waiting for them.
+func (q MySQLSelectQuery) SkipLocked() MySQLSelectQuery {
+	q.LockSkipLocked = true
+	return q
+}
+
 // As returns a new MySQLSelectQuery with the table alias (and optionally
 // column aliases).
 func (q MySQLSelectQuery) As(alias string, columns ...string) MySQLSelectQuery {
@@ -1269,6 +1746,13 @@ func (q SQLServerSelectQuery) SelectOne(fields ...Field) SQLServerSelectQuery {
 	return q
 }
 
+// DedupFields removes duplicate fields from the SelectFields in the
+// SQLServerSelectQuery (see the DedupFields function).
+func (q SQLServerSelectQuery) DedupFields() SQLServerSelectQuery {
+	q.SelectFields = DedupFields(q.Dialect, q.SelectFields)
+	return q
+}
+
 // Top sets the LimitTop field of the SQLServerSelectQuery.
 func (q SQLServerSelectQuery) Top(limit any) SQLServerSelectQuery {
 	q.LimitTop = limit
@@ -1342,12 +1826,40 @@ func (q SQLServerSelectQuery) OrderBy(fields ...Field) SQLServerSelectQuery {
 	return q
 }
 
+// WithoutDefaultFilter stops the default filters of the tables in the
+// SQLServerSelectQuery (see DefaultFilterTable) from being applied.
+func (q SQLServerSelectQuery) WithoutDefaultFilter() SQLServerSelectQuery {
+	q.NoDefaultFilter = true
+	return q
+}
+
+// WithoutDefaultOrderBy stops the default ordering of the FROM table (see
+// DefaultOrderTable) from being applied to the SQLServerSelectQuery.
+func (q SQLServerSelectQuery) WithoutDefaultOrderBy() SQLServerSelectQuery {
+	q.NoDefaultOrderBy = true
+	return q
+}
+
 // Offset sets the OffsetRows field in the SQLServerSelectQuery.
 func (q SQLServerSelectQuery) Offset(offset any) SQLServerSelectQuery {
 	q.OffsetRows = offset
 	return q
 }
 
+// Sample sets the SampleRows field in the SQLServerSelectQuery. The query
+// returns n random rows, by ordering the rows randomly and taking the first n.
+func (q SQLServerSelectQuery) Sample(n any) SQLServerSelectQuery {
+	q.SampleRows = n
+	return q
+}
+
+// SamplePercent sets the SampleRowsPercent field in the SQLServerSelectQuery.
+// The query returns roughly the given percentage of rows, picked at random.
+func (q SQLServerSelectQuery) SamplePercent(percent float64) SQLServerSelectQuery {
+	q.SampleRowsPercent = percent
+	return q
+}
+
 // FetchNext sets the FetchNextRows field in the SQLServerSelectQuery.
 func (q SQLServerSelectQuery) FetchNext(n any) SQLServerSelectQuery {
 	q.FetchNextRows = n
@@ -1,6 +1,8 @@
 package sq
 
 import (
+	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/bokwoon95/sq/internal/testutil"
@@ -201,6 +203,44 @@ func TestPostgresSelectQuery(t *testing.T) {
 		tt.wantArgs = []any{10, 20}
 		tt.assert(t)
 	})
+
+	t.Run("ForUpdate, Of, SkipLocked", func(t *testing.T) {
+		t.Parallel()
+		f := New[ACTOR]("f")
+		var tt TestTable
+		tt.item = Postgres.
+			Select(a.ACTOR_ID).
+			From(a).
+			Join(f, a.ACTOR_ID.Eq(f.ACTOR_ID)).
+			Limit(10).
+			ForUpdate().Of(a, f).SkipLocked()
+		tt.wantQuery = "SELECT a.actor_id" +
+			" FROM actor AS a" +
+			" JOIN actor AS f ON a.actor_id = f.actor_id" +
+			" LIMIT $1" +
+			" FOR UPDATE OF a, f SKIP LOCKED"
+		tt.wantArgs = []any{10}
+		tt.assert(t)
+	})
+
+	t.Run("ForNoKeyUpdate, NoWait", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = Postgres.Select(a.ACTOR_ID).From(a).ForNoKeyUpdate().NoWait()
+		tt.wantQuery = "SELECT a.actor_id FROM actor AS a FOR NO KEY UPDATE NOWAIT"
+		tt.assert(t)
+	})
+
+	t.Run("ForShare, ForKeyShare", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = Postgres.Select(a.ACTOR_ID).From(a).ForShare()
+		tt.wantQuery = "SELECT a.actor_id FROM actor AS a FOR SHARE"
+		tt.assert(t)
+		tt.item = Postgres.Select(a.ACTOR_ID).From(a).ForKeyShare()
+		tt.wantQuery = "SELECT a.actor_id FROM actor AS a FOR KEY SHARE"
+		tt.assert(t)
+	})
 }
 
 func TestMySQLSelectQuery(t *testing.T) {
@@ -279,9 +319,9 @@ func TestMySQLSelectQuery(t *testing.T) {
 			" GROUP BY a.first_name" +
 			" HAVING a.first_name IS NOT NULL" +
 			" ORDER BY a.last_name" +
-			" LIMIT ?" +
-			" OFFSET ?"
-		tt.wantArgs = []any{5, 10, 20}
+			" LIMIT 10" +
+			" OFFSET 20"
+		tt.wantArgs = []any{5}
 		tt.assert(t)
 	})
 
@@ -297,9 +337,24 @@ func TestMySQLSelectQuery(t *testing.T) {
 		tt.wantQuery = "SELECT a.actor_id, a.first_name, a.last_name" +
 			" FROM actor AS a" +
 			" ORDER BY a.actor_id" +
-			" OFFSET ?" +
+			" OFFSET 10" +
 			" FOR UPDATE"
-		tt.wantArgs = []any{10}
+		tt.assert(t)
+	})
+
+	t.Run("ForShare, Of, NoWait", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = MySQL.Select(a.ACTOR_ID).From(a).ForShare().Of(a).NoWait()
+		tt.wantQuery = "SELECT a.actor_id FROM actor AS a FOR SHARE OF a NOWAIT"
+		tt.assert(t)
+	})
+
+	t.Run("ForUpdate, SkipLocked", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = MySQL.Select(a.ACTOR_ID).From(a).ForUpdate().SkipLocked()
+		tt.wantQuery = "SELECT a.actor_id FROM actor AS a FOR UPDATE SKIP LOCKED"
 		tt.assert(t)
 	})
 }
@@ -590,6 +645,84 @@ func TestSelectQuery(t *testing.T) {
 			FetchNextRows: 20,
 			FetchWithTies: true,
 		},
+	}, {
+		description: "sqlite does not support FOR UPDATE",
+		item: SelectQuery{
+			Dialect:      DialectSQLite,
+			SelectFields: Fields{Expr("f1")},
+			FromTable:    Expr("tbl"),
+			LockStrength: "UPDATE",
+		},
+	}, {
+		description: "sqlserver does not support FOR UPDATE",
+		item: SelectQuery{
+			Dialect:      DialectSQLServer,
+			SelectFields: Fields{Expr("f1")},
+			FromTable:    Expr("tbl"),
+			LockStrength: "UPDATE",
+		},
+	}, {
+		description: "mysql does not support FOR NO KEY UPDATE",
+		item: SelectQuery{
+			Dialect:      DialectMySQL,
+			SelectFields: Fields{Expr("f1")},
+			FromTable:    Expr("tbl"),
+			LockStrength: "NO KEY UPDATE",
+		},
+	}, {
+		description: "postgres FOR UPDATE is not allowed with GROUP BY",
+		item: SelectQuery{
+			Dialect:       DialectPostgres,
+			SelectFields:  Fields{Expr("f1")},
+			FromTable:     Expr("tbl"),
+			GroupByFields: Fields{Expr("f1")},
+			LockStrength:  "UPDATE",
+		},
+	}, {
+		description: "postgres FOR UPDATE is not allowed with DISTINCT",
+		item: SelectQuery{
+			Dialect:      DialectPostgres,
+			Distinct:     true,
+			SelectFields: Fields{Expr("f1")},
+			FromTable:    Expr("tbl"),
+			LockStrength: "UPDATE",
+		},
+	}, {
+		description: "NOWAIT and SKIP LOCKED",
+		item: SelectQuery{
+			Dialect:        DialectPostgres,
+			SelectFields:   Fields{Expr("f1")},
+			FromTable:      Expr("tbl"),
+			LockStrength:   "UPDATE",
+			LockNoWait:     true,
+			LockSkipLocked: true,
+		},
+	}, {
+		description: "SKIP LOCKED without FOR UPDATE",
+		item: SelectQuery{
+			Dialect:        DialectPostgres,
+			SelectFields:   Fields{Expr("f1")},
+			FromTable:      Expr("tbl"),
+			LockSkipLocked: true,
+		},
+	}, {
+		description: "LockRows and FOR UPDATE",
+		item: SelectQuery{
+			Dialect:      DialectPostgres,
+			SelectFields: Fields{Expr("f1")},
+			FromTable:    Expr("tbl"),
+			LockClause:   "FOR UPDATE",
+			LockStrength: "UPDATE",
+		},
+	}, {
+		description: "OF table not in FROM clause",
+		item: SelectQuery{
+			Dialect:      DialectPostgres,
+			SelectFields: Fields{Expr("f1")},
+			FromTable:    Expr("tbl"),
+			LockStrength: "UPDATE",
+			LockOfTables: []Table{New[struct{ TableStruct }]("t")},
+		},
 	}}
 
 	for _, tt := range notOKTests {
@@ -757,3 +890,246 @@ func TestSelectQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestDedupFields(t *testing.T) {
+	type ACTOR struct {
+		TableStruct
+		ACTOR_ID   NumberField
+		FIRST_NAME StringField
+		LAST_NAME  StringField
+	}
+	a := New[ACTOR]("a")
+	tests := []TestTable{{
+		description: "duplicates",
+		item:        Select(a.ACTOR_ID, a.FIRST_NAME, a.ACTOR_ID, a.ACTOR_ID.As("id"), Value(1), Value(2), Value(1)).From(a).DedupFields(),
+		wantQuery:   "SELECT a.actor_id, a.first_name, a.actor_id AS id, ?, ? FROM actor AS a",
+		wantArgs:    []any{1, 2},
+	}, {
+		description: "dialect",
+		item:        Postgres.Select(a.FIRST_NAME, a.LAST_NAME, a.FIRST_NAME).From(a).DedupFields(),
+		wantQuery:   "SELECT a.first_name, a.last_name FROM actor AS a",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+}
+
+type DEFAULTS_FILM struct {
+	TableStruct `sq:"film"`
+	FILM_ID     NumberField
+	TITLE       StringField
+	STATUS      StringField
+}
+
+func (tbl DEFAULTS_FILM) DefaultFilter(ctx context.Context, dialect string) (Predicate, error) {
+	return tbl.STATUS.NeString("archived"), nil
+}
+
+func (tbl DEFAULTS_FILM) DefaultOrderBy(ctx context.Context, dialect string) ([]Field, error) {
+	return []Field{tbl.TITLE, tbl.FILM_ID}, nil
+}
+
+func TestDefaultFilterAndOrder(t *testing.T) {
+	f := New[DEFAULTS_FILM]("f")
+	f2 := New[DEFAULTS_FILM]("f2")
+	f3 := New[DEFAULTS_FILM]("f3")
+	onPredicates := make([]Predicate, 1, 2)
+	onPredicates[0] = f3.TITLE.Eq(f.TITLE)
+	leftJoin := JoinTable{JoinOperator: JoinLeft, Table: f3, OnPredicate: VariadicPredicate{Predicates: onPredicates}}
+
+	tests := []TestTable{{
+		description: "defaults",
+		item:        Postgres.Select(f.TITLE).From(f).Where(f.FILM_ID.GtInt(10)),
+		wantQuery:   "SELECT f.title FROM film AS f WHERE f.status <> $1 AND f.film_id > $2 ORDER BY f.title, f.film_id",
+		wantArgs:    []any{"archived", 10},
+	}, {
+		description: "explicit ORDER BY",
+		item:        Postgres.Select(f.TITLE).From(f).OrderBy(f.FILM_ID.Desc()),
+		wantQuery:   "SELECT f.title FROM film AS f WHERE f.status <> $1 ORDER BY f.film_id DESC",
+		wantArgs:    []any{"archived"},
+	}, {
+		description: "cleared",
+		item:        Postgres.Select(f.TITLE).From(f).WithoutDefaultFilter().WithoutDefaultOrderBy(),
+		wantQuery:   "SELECT f.title FROM film AS f",
+	}, {
+		description: "joins",
+		item: SelectQuery{
+			Dialect:          DialectPostgres,
+			SelectFields:     Fields{f.TITLE},
+			FromTable:        f,
+			JoinTables:       []JoinTable{JoinUsing(f2, f2.FILM_ID), leftJoin},
+			NoDefaultOrderBy: true,
+		},
+		wantQuery: "SELECT f.title" +
+			" FROM film AS f" +
+			" JOIN film AS f2 USING (film_id)" +
+			" LEFT JOIN film AS f3 ON f3.title = f.title AND f3.status <> $1" +
+			" WHERE f.status <> $2 AND f2.status <> $3",
+		wantArgs: []any{"archived", "archived", "archived"},
+	}, {
+		description: "no default ORDER BY for GROUP BY",
+		item:        Postgres.Select(f.STATUS, CountStar()).From(f).GroupBy(f.STATUS).WithoutDefaultFilter(),
+		wantQuery:   "SELECT f.status, COUNT(*) FROM film AS f GROUP BY f.status",
+	}, {
+		description: "no default ORDER BY for aggregates",
+		item:        Postgres.Select(CountStar()).From(f).WithoutDefaultFilter(),
+		wantQuery:   "SELECT COUNT(*) FROM film AS f",
+	}, {
+		description: "no default ORDER BY for nested aggregates",
+		item:        Postgres.Select(Expr("COALESCE({}, 0)", Max(f.FILM_ID)).As("max_id")).From(f).WithoutDefaultFilter(),
+		wantQuery:   "SELECT COALESCE(MAX(f.film_id), 0) AS max_id FROM film AS f",
+	}, {
+		description: "default ORDER BY for window functions",
+		item:        Postgres.Select(f.TITLE, CountStarOver(nil)).From(f).WithoutDefaultFilter(),
+		wantQuery:   "SELECT f.title, COUNT(*) OVER () FROM film AS f ORDER BY f.title, f.film_id",
+	}, {
+		description: "no default ORDER BY for subqueries",
+		item: Postgres.
+			Select(f.TITLE).
+			From(f).
+			Where(f.FILM_ID.In(Postgres.Select(f2.FILM_ID).From(f2))).
+			WithoutDefaultFilter(),
+		wantQuery: "SELECT f.title FROM film AS f" +
+			" WHERE f.film_id IN (SELECT f2.film_id FROM film AS f2 WHERE f2.status <> $1)" +
+			" ORDER BY f.title, f.film_id",
+		wantArgs: []any{"archived"},
+	}, {
+		description: "no default ORDER BY for Sample",
+		item:        SQLite.Select(f.TITLE).From(f).Sample(2),
+		wantQuery:   "SELECT f.title FROM film AS f WHERE f.status <> $1 ORDER BY random() LIMIT $2",
+		wantArgs:    []any{"archived", 2},
+	}, {
+		description: "no default ORDER BY for SamplePercent",
+		item:        Postgres.Select(f.TITLE).From(f).SamplePercent(10).WithoutDefaultFilter(),
+		wantQuery:   "SELECT f.title FROM film AS f TABLESAMPLE SYSTEM (10)",
+	}, {
+		description: "no default ORDER BY for UNION",
+		item: Union(
+			Postgres.Select(f.TITLE).From(f).WithoutDefaultFilter(),
+			Postgres.Select(f2.TITLE).From(f2).WithoutDefaultFilter(),
+		),
+		wantQuery: "(SELECT f.title FROM film AS f UNION SELECT f2.title FROM film AS f2)",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	t.Run("join predicates are not modified", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := ToSQL(DialectPostgres, Postgres.Select(f.TITLE).From(f).LeftJoin(f3, leftJoin.OnPredicate), nil)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(onPredicates[:cap(onPredicates)][1], nil); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
+
+func TestRowCount(t *testing.T) {
+	type ACTOR struct {
+		TableStruct
+		ACTOR_ID NumberField
+	}
+	a := New[ACTOR]("a")
+	limit, offset := 10, 20
+	tests := []TestTable{{
+		description: "mysql integers are inlined",
+		item:        MySQL.Select(a.ACTOR_ID).From(a).Limit(uint8(10)).Offset(int64(20)),
+		wantQuery:   "SELECT a.actor_id FROM actor AS a LIMIT 10 OFFSET 20",
+	}, {
+		description: "mysql params are bound",
+		item:        MySQL.Select(a.ACTOR_ID).From(a).Limit(IntParam("limit", 10)).Offset(sql.Named("offset", 20)),
+		wantQuery:   "SELECT a.actor_id FROM actor AS a LIMIT ? OFFSET ?",
+		wantArgs:    []any{10, 20},
+		wantParams:  map[string][]int{"limit": {0}, "offset": {1}},
+	}, {
+		description: "mysql expression",
+		item:        MySQL.Select(a.ACTOR_ID).From(a).Limit(Expr("{} * {}", 2, 5)),
+		wantQuery:   "SELECT a.actor_id FROM actor AS a LIMIT ? * ?",
+		wantArgs:    []any{2, 5},
+	}, {
+		description: "mysql integer pointers are inlined",
+		item:        MySQL.Select(a.ACTOR_ID).From(a).Limit(&limit).Offset(&offset),
+		wantQuery:   "SELECT a.actor_id FROM actor AS a LIMIT 10 OFFSET 20",
+	}, {
+		description: "sqlite no limit",
+		item:        SQLite.Select(a.ACTOR_ID).From(a).Limit(-1).Offset(5),
+		wantQuery:   "SELECT a.actor_id FROM actor AS a LIMIT $1 OFFSET $2",
+		wantArgs:    []any{-1, 5},
+	}, {
+		description: "postgres integer pointers are bound",
+		item:        Postgres.Select(a.ACTOR_ID).From(a).Limit(&limit),
+		wantQuery:   "SELECT a.actor_id FROM actor AS a LIMIT $1",
+		wantArgs:    []any{&limit},
+	}, {
+		description: "postgres integers are bound",
+		item:        Postgres.Select(a.ACTOR_ID).From(a).Limit(sql.NullInt64{Int64: 5, Valid: true}).Offset(10),
+		wantQuery:   "SELECT a.actor_id FROM actor AS a LIMIT $1 OFFSET $2",
+		wantArgs:    []any{int64(5), 10},
+	}, {
+		description: "sqlserver TOP and FETCH NEXT",
+		item:        SQLServer.Select(a.ACTOR_ID).From(a).OrderBy(a.ACTOR_ID).Offset(5).FetchNext(IntParam("n", 10)),
+		wantQuery:   "SELECT a.actor_id FROM actor AS a ORDER BY a.actor_id OFFSET @p1 ROWS FETCH NEXT @n ROWS ONLY",
+		wantArgs:    []any{5, sql.Named("n", 10)},
+		wantParams:  map[string][]int{"n": {1}},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "string",
+		item:        MySQL.Select(a.ACTOR_ID).From(a).Limit("10"),
+	}, {
+		description: "negative",
+		item:        Postgres.Select(a.ACTOR_ID).From(a).Offset(-1),
+	}, {
+		description: "sqlite negative",
+		item:        SQLite.Select(a.ACTOR_ID).From(a).Limit(-2),
+	}, {
+		description: "nil pointer",
+		item:        Postgres.Select(a.ACTOR_ID).From(a).Limit((*int)(nil)),
+	}, {
+		description: "float",
+		item:        SQLite.Select(a.ACTOR_ID).From(a).Limit(2.5),
+	}, {
+		description: "param with a string value",
+		item:        MySQL.Select(a.ACTOR_ID).From(a).Limit(sql.Named("limit", "10")),
+	}, {
+		description: "NULL",
+		item:        Postgres.Select(a.ACTOR_ID).From(a).Limit(sql.NullInt64{}),
+	}, {
+		description: "TOP",
+		item:        SQLServer.Select(a.ACTOR_ID).From(a).Top("10"),
+	}, {
+		description: "DELETE LIMIT",
+		item:        MySQL.DeleteFrom(a).Limit(-5),
+	}, {
+		description: "UPDATE LIMIT",
+		item:        MySQL.Update(a).Set(a.ACTOR_ID.SetInt(1)).Limit("5"),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package sq
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FetchAllSharded runs a query on every shard concurrently and returns the
+// results of all shards, in shard order. queryFor is called with the index of
+// each DB in dbs and returns the query to run on that shard. If any shard
+// fails, the queries still running on the other shards are cancelled and the
+// error of the failed shard is returned.
+//
+//	users, err := sq.FetchAllSharded(dbs, func(shard int) sq.Query {
+//	    return sq.From(u).Where(u.CREATED_AT.GtTime(since))
+//	}, userRowMapper)
+func FetchAllSharded[T any](dbs []DB, queryFor func(shard int) Query, rowmapper func(*Row) T) ([]T, error) {
+	return fetchAllSharded(context.Background(), dbs, queryFor, rowmapper, nil)
+}
+
+// FetchAllShardedContext is like FetchAllSharded but additionally requires a
+// context.Context.
+func FetchAllShardedContext[T any](ctx context.Context, dbs []DB, queryFor func(shard int) Query, rowmapper func(*Row) T) ([]T, error) {
+	return fetchAllSharded(ctx, dbs, queryFor, rowmapper, nil)
+}
+
+// FetchAllShardedSorted is like FetchAllSharded, but merges the results of
+// every shard in the order given by less (which reports whether a sorts
+// before b). The results of each shard must already be sorted in that order,
+// usually with an ORDER BY on the same key. Results that are equal are
+// returned in shard order.
+//
+//	users, err := sq.FetchAllShardedSorted(dbs, func(shard int) sq.Query {
+//	    return sq.From(u).OrderBy(u.USER_ID)
+//	}, userRowMapper, func(a, b User) bool {
+//	    return a.UserID < b.UserID
+//	})
+func FetchAllShardedSorted[T any](dbs []DB, queryFor func(shard int) Query, rowmapper func(*Row) T, less func(a, b T) bool) ([]T, error) {
+	if less == nil {
+		return nil, fmt.Errorf("less is nil")
+	}
+	return fetchAllSharded(context.Background(), dbs, queryFor, rowmapper, less)
+}
+
+// FetchAllShardedSortedContext is like FetchAllShardedSorted but additionally
+// requires a context.Context.
+func FetchAllShardedSortedContext[T any](ctx context.Context, dbs []DB, queryFor func(shard int) Query, rowmapper func(*Row) T, less func(a, b T) bool) ([]T, error) {
+	if less == nil {
+		return nil, fmt.Errorf("less is nil")
+	}
+	return fetchAllSharded(ctx, dbs, queryFor, rowmapper, less)
+}
+
+func fetchAllSharded[T any](ctx context.Context, dbs []DB, queryFor func(shard int) Query, rowmapper func(*Row) T, less func(a, b T) bool) ([]T, error) {
+	if queryFor == nil {
+		return nil, fmt.Errorf("queryFor is nil")
+	}
+	if rowmapper == nil {
+		return nil, fmt.Errorf("rowmapper is nil")
+	}
+	queries := make([]Query, len(dbs))
+	for shard, db := range dbs {
+		if db == nil {
+			return nil, fmt.Errorf("shard %d: db is nil", shard)
+		}
+		queries[shard] = queryFor(shard)
+		if queries[shard] == nil {
+			return nil, fmt.Errorf("shard %d: query is nil", shard)
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	shardResults := make([][]T, len(dbs))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for shard := range dbs {
+		shard := shard
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cursor, err := fetchCursor(ctx, dbs[shard], queries[shard], rowmapper, 1)
+			if err == nil {
+				shardResults[shard], err = cursorResults(cursor)
+				cursor.Close()
+			}
+			if err != nil {
+				// Only the first error is kept, the errors of the other
+				// shards are likely caused by their cancellation.
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("shard %d: %w", shard, err)
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if less == nil {
+		var n int
+		for _, results := range shardResults {
+			n += len(results)
+		}
+		merged := make([]T, 0, n)
+		for _, results := range shardResults {
+			merged = append(merged, results...)
+		}
+		return merged, nil
+	}
+	return mergeSorted(shardResults, less), nil
+}
+
+// mergeSorted merges already sorted slices into one sorted slice. Equal
+// elements are ordered by the index of the slice they came from.
+func mergeSorted[T any](slices [][]T, less func(a, b T) bool) []T {
+	var n int
+	h := &mergeHeap[T]{less: less}
+	for i, s := range slices {
+		n += len(s)
+		if len(s) > 0 {
+			h.cursors = append(h.cursors, mergeCursor[T]{slice: i, items: s})
+		}
+	}
+	heap.Init(h)
+	merged := make([]T, 0, n)
+	for h.Len() > 0 {
+		cursor := &h.cursors[0]
+		merged = append(merged, cursor.items[0])
+		cursor.items = cursor.items[1:]
+		if len(cursor.items) == 0 {
+			heap.Pop(h)
+		} else {
+			heap.Fix(h, 0)
+		}
+	}
+	return merged
+}
+
+type mergeCursor[T any] struct {
+	slice int
+	items []T
+}
+
+// mergeHeap is a min-heap of the remaining items of each slice being merged,
+// ordered by the first remaining item.
+type mergeHeap[T any] struct {
+	cursors []mergeCursor[T]
+	less    func(a, b T) bool
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.cursors) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	a, b := h.cursors[i], h.cursors[j]
+	if h.less(a.items[0], b.items[0]) {
+		return true
+	}
+	if h.less(b.items[0], a.items[0]) {
+		return false
+	}
+	return a.slice < b.slice
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.cursors = append(h.cursors, x.(mergeCursor[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	n := len(h.cursors)
+	cursor := h.cursors[n-1]
+	h.cursors = h.cursors[:n-1]
+	return cursor
+}
@@ -0,0 +1,86 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestFetchAllSharded(t *testing.T) {
+	t.Parallel()
+	// Shard 0 has the odd actor_ids, shard 1 has the even actor_ids.
+	dbs := []DB{newDB(t), newDB(t)}
+	for shard, db := range dbs {
+		_, err := Exec(db, SQLite.
+			InsertInto(ACTOR).
+			ColumnValues(func(col *Column) {
+				for i := shard + 1; i <= 6; i += 2 {
+					col.SetInt(ACTOR.ACTOR_ID, i)
+					col.SetString(ACTOR.FIRST_NAME, "bob")
+					col.SetString(ACTOR.LAST_NAME, "the builder")
+				}
+			}),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	}
+	queryFor := func(shard int) Query {
+		return SQLite.From(ACTOR).OrderBy(ACTOR.ACTOR_ID)
+	}
+	rowmapper := func(row *Row) int {
+		return row.IntField(ACTOR.ACTOR_ID)
+	}
+
+	t.Run("shard order", func(t *testing.T) {
+		actorIDs, err := FetchAllSharded(dbs, queryFor, rowmapper)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(actorIDs, []int{1, 3, 5, 2, 4, 6}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("sorted", func(t *testing.T) {
+		actorIDs, err := FetchAllShardedSorted(dbs, queryFor, rowmapper, func(a, b int) bool {
+			return a < b
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(actorIDs, []int{1, 2, 3, 4, 5, 6}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+
+	t.Run("shard error", func(t *testing.T) {
+		_, err := FetchAllSharded(dbs, func(shard int) Query {
+			if shard == 1 {
+				return SQLite.Queryf("SELECT {*} FROM no_such_table")
+			}
+			return queryFor(shard)
+		}, rowmapper)
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		if diff := testutil.Diff(err.Error()[:len("shard 1: ")], "shard 1: "); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
+
+func Test_mergeSorted(t *testing.T) {
+	// An item is a key and the index of the slice it came from.
+	type item [2]int
+	got := mergeSorted([][]item{
+		{{1, 0}, {3, 0}, {3, 0}, {7, 0}},
+		{},
+		{{2, 2}, {3, 2}, {8, 2}},
+		{{0, 3}},
+	}, func(a, b item) bool { return a[0] < b[0] })
+	want := []item{{0, 3}, {1, 0}, {2, 2}, {3, 0}, {3, 0}, {3, 2}, {7, 0}, {8, 2}}
+	if diff := testutil.Diff(got, want); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"unicode"
 
 	"github.com/bokwoon95/sq/internal/googleuuid"
 	"github.com/bokwoon95/sq/internal/pqarray"
@@ -19,6 +21,36 @@ var bufpool = &sync.Pool{
 	New: func() any { return &bytes.Buffer{} },
 }
 
+// slicepool pools the args slices and Row.scanDest slices of Fetch and Exec
+// calls. Slices that have grown beyond maxPooledSliceCap are left to the
+// garbage collector so that one unusually large query does not pin its memory
+// in the pool forever.
+var slicepool = &sync.Pool{
+	New: func() any { return new([]any) },
+}
+
+const maxPooledSliceCap = 1024
+
+// getSlice returns an empty slice from the slicepool.
+func getSlice() *[]any {
+	s := slicepool.Get().(*[]any)
+	*s = (*s)[:0]
+	return s
+}
+
+// putSlice clears the slice and returns it to the slicepool. The caller must
+// not use the slice afterwards.
+func putSlice(s *[]any) {
+	if s == nil || cap(*s) > maxPooledSliceCap {
+		return
+	}
+	for i := range *s {
+		(*s)[i] = nil
+	}
+	*s = (*s)[:0]
+	slicepool.Put(s)
+}
+
 // Dialects supported.
 const (
 	DialectSQLite    = "sqlite"
@@ -78,6 +110,69 @@ type PolicyTable interface {
 	Policy(ctx context.Context, dialect string) (Predicate, error)
 }
 
+// DefaultFilterTable is a table that produces a default filter (i.e. a
+// predicate) to be applied whenever it is selected from or joined in a SELECT
+// query, such as excluding archived rows. Unlike a PolicyTable's policy, the
+// default filter can be cleared for a query with WithoutDefaultFilter.
+type DefaultFilterTable interface {
+	Table
+	DefaultFilter(ctx context.Context, dialect string) (Predicate, error)
+}
+
+// DefaultOrderTable is a table that produces the fields a SELECT query
+// selecting from it is ordered by when the query has no ORDER BY of its own.
+// The default ordering can be cleared for a query with WithoutDefaultOrderBy.
+type DefaultOrderTable interface {
+	Table
+	DefaultOrderBy(ctx context.Context, dialect string) ([]Field, error)
+}
+
+type (
+	tenantIDKey  struct{}
+	userIDKey    struct{}
+	requestIDKey struct{}
+)
+
+// WithTenantID returns a copy of ctx that carries a tenant ID. The tenant ID
+// can be read back with TenantID (e.g. inside a PolicyTable's Policy method)
+// and is included in the QueryStats of every query run with the context.
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantIDKey{}, tenantID)
+}
+
+// TenantID returns the tenant ID stored in ctx by WithTenantID.
+func TenantID(ctx context.Context) (tenantID string, ok bool) {
+	tenantID, ok = ctx.Value(tenantIDKey{}).(string)
+	return tenantID, ok
+}
+
+// WithUserID returns a copy of ctx that carries a user ID. The user ID can be
+// read back with UserID and is included in the QueryStats of every query run
+// with the context.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserID returns the user ID stored in ctx by WithUserID.
+func UserID(ctx context.Context) (userID string, ok bool) {
+	userID, ok = ctx.Value(userIDKey{}).(string)
+	return userID, ok
+}
+
+// WithRequestID returns a copy of ctx that carries a request ID. The request
+// ID can be read back with RequestID and is included in the QueryStats of
+// every query run with the context, so that queries can be correlated with
+// the request that ran them.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in ctx by WithRequestID.
+func RequestID(ctx context.Context) (requestID string, ok bool) {
+	requestID, ok = ctx.Value(requestIDKey{}).(string)
+	return requestID, ok
+}
+
 // Window is a window used in SQL window functions.
 type Window interface {
 	SQLWriter
@@ -194,6 +289,24 @@ type TableStruct struct {
 	schema string
 	name   string
 	alias  string
+	groups *columnGroups
+	quoted *quotedIdentifiers
+	// outer is set on the tables of fields marked with Outer.
+	outer bool
+}
+
+// columnGroups are the named column groups of a table struct, declared with
+// the groups option in the struct tags of its fields.
+type columnGroups struct {
+	fields map[string][]Field
+}
+
+// quotedIdentifiers are the identifiers of a table struct that are always
+// quoted, declared with the quoted option in its struct tags. If table is
+// true, the table name and all of its column names are always quoted.
+type quotedIdentifiers struct {
+	table   bool
+	columns map[string]bool
 }
 
 // ViewStruct is just an alias for TableStruct.
@@ -201,26 +314,229 @@ type ViewStruct = TableStruct
 
 var _ Table = (*TableStruct)(nil)
 
-// NewTableStruct creates a new TableStruct.
+// NewTableStruct creates a new TableStruct. The schema may be qualified with
+// a database and a (linked) server for SQL Server three-part and four-part
+// names, e.g. "database.schema" or "server.database.schema". The schema part
+// can be left empty to use the default schema e.g. "database." becomes
+// database..name. It panics if the schema or name is not a valid identifier
+// (see ValidateIdentifier).
 func NewTableStruct(schema, name, alias string) TableStruct {
+	if schema != "" {
+		parts := strings.Split(schema, ".")
+		if len(parts) > 3 {
+			panic(fmt.Errorf("sq: invalid table schema %q: a table name can have at most 4 parts (server.database.schema.table)", schema))
+		}
+		for i, part := range parts {
+			if part == "" && i > 0 {
+				continue
+			}
+			if err := ValidateIdentifier("", part); err != nil {
+				panic(fmt.Errorf("sq: invalid table schema: %w", err))
+			}
+		}
+	}
+	if name != "" {
+		if err := ValidateIdentifier("", name); err != nil {
+			panic(fmt.Errorf("sq: invalid table name: %w", err))
+		}
+	}
 	return TableStruct{schema: schema, name: name, alias: alias}
 }
 
 // WriteSQL implements the SQLWriter interface.
 func (ts TableStruct) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
-	if ts.schema != "" {
-		buf.WriteString(QuoteIdentifier(dialect, ts.schema) + ".")
+	schema := ts.schema
+	if ctx != nil {
+		if resolveSchema, ok := ctx.Value(schemaResolverKey{}).(func(schema, table string) string); ok {
+			schema = resolveSchema(schema, ts.name)
+		}
 	}
-	buf.WriteString(QuoteIdentifier(dialect, ts.name))
+	alwaysQuote := ts.quoted != nil && ts.quoted.table
+	if schema != "" {
+		// The schema may be a dotted database.schema or
+		// server.database.schema, each part is quoted separately.
+		for {
+			part, rest, found := strings.Cut(schema, ".")
+			if part != "" {
+				if err := checkIdentifierLength(dialect, part); err != nil {
+					return err
+				}
+				writeIdentifier(buf, dialect, part, alwaysQuote)
+			}
+			buf.WriteString(".")
+			if !found {
+				break
+			}
+			schema = rest
+		}
+	}
+	if err := checkIdentifierLength(dialect, ts.name); err != nil {
+		return err
+	}
+	writeIdentifier(buf, dialect, ts.name, alwaysQuote)
 	return nil
 }
 
+type schemaResolverKey struct{}
+
+// WithSchemaResolver returns a copy of ctx in which the schema of every
+// TableStruct written with the context is replaced by resolveSchema(schema,
+// table), where schema is the TableStruct's own schema (possibly empty). It is
+// meant for schema-per-tenant deployments, where the same table structs are
+// used for every tenant and the schema is decided per request.
+//
+//	ctx = sq.WithSchemaResolver(ctx, func(schema, table string) string {
+//	    if schema != "" {
+//	        return schema // shared tables e.g. public.tenants
+//	    }
+//	    return "tenant_" + tenantID
+//	})
+//	// SELECT orders.order_id FROM tenant_42.orders
+//	orderIDs, err := sq.FetchAllContext(ctx, db, sq.From(o), orderIDRowMapper)
+func WithSchemaResolver(ctx context.Context, resolveSchema func(schema, table string) string) context.Context {
+	return context.WithValue(ctx, schemaResolverKey{}, resolveSchema)
+}
+
+// WithDefaultSchema returns a copy of ctx in which every TableStruct without
+// a schema is written with the given schema. TableStructs that have their own
+// schema are left as-is. It is a shortcut for the most common use of
+// WithSchemaResolver.
+func WithDefaultSchema(ctx context.Context, schema string) context.Context {
+	return WithSchemaResolver(ctx, func(tableSchema, table string) string {
+		if tableSchema != "" {
+			return tableSchema
+		}
+		return schema
+	})
+}
+
+// Fields returns the fields of the table struct that belong to the named
+// column group, in struct field order. A field is put into one or more column
+// groups with the groups option of its struct tag (groups are separated by
+// |). Fields returns nil if the group does not exist or if the table struct
+// was not instantiated with New.
+//
+//	type FILM struct {
+//	    sq.TableStruct
+//	    FILM_ID     sq.NumberField `sq:"film_id,groups=summary|detail"`
+//	    TITLE       sq.StringField `sq:"title,groups=summary|detail"`
+//	    DESCRIPTION sq.StringField `sq:"description,groups=detail"`
+//	}
+//
+//	f := sq.New[FILM]("f")
+//	// SELECT f.film_id, f.title FROM film AS f
+//	q := sq.Select(f.Fields("summary")...).From(f)
+func (ts TableStruct) Fields(group string) []Field {
+	if ts.groups == nil {
+		return nil
+	}
+	fields := ts.groups.fields[group]
+	if fields == nil {
+		return nil
+	}
+	return append(make([]Field, 0, len(fields)), fields...)
+}
+
 // GetAlias returns the alias of the TableStruct.
 func (ts TableStruct) GetAlias() string { return ts.alias }
 
 // IsTable implements the Table interface.
 func (ts TableStruct) IsTable() {}
 
+// NamingStrategy translates the name of a table struct or one of its fields
+// into a table or column name. It is only used when the name is not given in
+// the `sq` struct tag.
+type NamingStrategy func(name string) string
+
+// DefaultNamingStrategy is the NamingStrategy used by New (and for matching
+// struct fields to named parameters). If it is not set, names are lowercased
+// (LowerCase).
+//
+//	strategy := sq.NamingStrategy(sq.SnakeCase)
+//	sq.DefaultNamingStrategy.Store(&strategy)
+var DefaultNamingStrategy atomic.Pointer[NamingStrategy]
+
+// translateName translates a struct or field name using the
+// DefaultNamingStrategy.
+func translateName(name string) string {
+	strategy := DefaultNamingStrategy.Load()
+	if strategy == nil || *strategy == nil {
+		return strings.ToLower(name)
+	}
+	return (*strategy)(name)
+}
+
+// LowerCase is a NamingStrategy that lowercases names e.g. ACTOR_ID becomes
+// actor_id and FirstName becomes firstname. It is the default.
+func LowerCase(name string) string {
+	return strings.ToLower(name)
+}
+
+// SnakeCase is a NamingStrategy that converts names to snake_case e.g.
+// ACTOR_ID becomes actor_id, FirstName becomes first_name and HTTPStatus
+// becomes http_status.
+func SnakeCase(name string) string {
+	words := splitWords(name)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// PascalCase is a NamingStrategy that converts names to PascalCase e.g.
+// ACTOR_ID becomes ActorId, first_name becomes FirstName and ActorID stays
+// ActorID. The casing of words in mixed-case names is kept as-is.
+func PascalCase(name string) string {
+	hasLower := strings.IndexFunc(name, unicode.IsLower) >= 0
+	hasUpper := strings.IndexFunc(name, unicode.IsUpper) >= 0
+	var b strings.Builder
+	for _, word := range splitWords(name) {
+		runes := []rune(word)
+		if !hasLower || !hasUpper {
+			// All upper case (ACTOR_ID) or all lower case (actor_id):
+			// capitalize only the first letter of each word.
+			runes = []rune(strings.ToLower(word))
+		}
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// splitWords splits a name into words on underscores and on changes in case
+// e.g. HTTPStatusCode becomes [HTTP Status Code] and ACTOR_ID becomes
+// [ACTOR ID].
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		prev := runes[i-1]
+		// A word boundary is before an upper case letter that follows a
+		// lower case letter or digit (actorId), or before the last upper
+		// case letter of an acronym that is followed by a lower case letter
+		// (HTTPStatus).
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
 func withPrefix(w SQLWriter, prefix string) SQLWriter {
 	if field, ok := w.(interface {
 		SQLWriter
@@ -231,6 +547,41 @@ func withPrefix(w SQLWriter, prefix string) SQLWriter {
 	return w
 }
 
+type writeStateKey struct{}
+
+// writeState is the state shared by a query and all of its subqueries while
+// they are being written.
+type writeState struct {
+	// autoAliases maps the aliases returned by AutoAlias to their generated
+	// aliases.
+	autoAliases map[string]string
+	// scopes holds the tables of every query currently being written, from
+	// the outermost query to the innermost subquery.
+	scopes [][]Table
+}
+
+// withWriteState returns a copy of ctx containing a new writeState, unless
+// ctx already has one. Queries call it before writing themselves so that a
+// query and all of its subqueries share the same writeState.
+func withWriteState(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ctx.Value(writeStateKey{}) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, writeStateKey{}, &writeState{})
+}
+
+// getWriteState returns the writeState of ctx, or nil if there is none.
+func getWriteState(ctx context.Context) *writeState {
+	if ctx == nil {
+		return nil
+	}
+	state, _ := ctx.Value(writeStateKey{}).(*writeState)
+	return state
+}
+
 func getAlias(w SQLWriter) string {
 	if w, ok := w.(interface{ GetAlias() string }); ok {
 		return w.GetAlias()
@@ -263,6 +614,10 @@ func writeFieldsWithPrefix(ctx context.Context, dialect string, buf *bytes.Buffe
 		}
 		if includeAlias {
 			if alias = getAlias(field); alias != "" {
+				alias, err = checkAlias(ctx, dialect, alias)
+				if err != nil {
+					return fmt.Errorf("field #%d: %w", i+1, err)
+				}
 				buf.WriteString(" AS " + QuoteIdentifier(dialect, alias))
 			}
 		}
@@ -293,6 +648,10 @@ func writeFields(ctx context.Context, dialect string, buf *bytes.Buffer, args *[
 		}
 		if includeAlias {
 			if alias = getAlias(field); alias != "" {
+				alias, err = checkAlias(ctx, dialect, alias)
+				if err != nil {
+					return fmt.Errorf("field #%d: %w", i+1, err)
+				}
 				buf.WriteString(" AS " + QuoteIdentifier(dialect, alias))
 			}
 		}
@@ -507,6 +866,167 @@ func (v *uuidValue) DialectValuer(dialect string) (driver.Valuer, error) {
 	return v, nil
 }
 
+// BitValue takes in a uint64 (or any other integer type) or a []bool and
+// returns a driver.Valuer for a BIT or VARBIT column. The []bool holds the bits
+// from the most significant bit to the least significant bit.
+//
+// For Postgres the value is passed in as a bit string e.g. '1010'. A uint64 is
+// written with as few bits as possible, which suits VARBIT columns; use a
+// []bool for BIT(n) columns since Postgres requires the number of bits to
+// match exactly. For MySQL the bits are passed in as a big-endian binary
+// string, for SQL Server (which only has single-bit BIT columns) as a bool and
+// for SQLite as an integer.
+func BitValue(value any) driver.Valuer {
+	return &bitValue{value: value}
+}
+
+type bitValue struct {
+	dialect string
+	value   any
+}
+
+// Value implements the driver.Valuer interface.
+func (v *bitValue) Value() (driver.Value, error) {
+	if v.value == nil {
+		return nil, nil
+	}
+	var bits []bool
+	switch value := v.value.(type) {
+	case []bool:
+		bits = value
+	default:
+		rv := reflect.ValueOf(v.value)
+		var n uint64
+		switch {
+		case rv.CanUint():
+			n = rv.Uint()
+		case rv.CanInt():
+			n = uint64(rv.Int())
+		default:
+			return nil, fmt.Errorf("%[1]v %[1]T is not an integer or []bool", v.value)
+		}
+		bits = uint64ToBits(n)
+	}
+	switch v.dialect {
+	case DialectPostgres:
+		return bitsToString(bits), nil
+	case DialectMySQL:
+		b := make([]byte, (len(bits)+7)/8)
+		// Right-align the bits so that the last bit is the least significant
+		// bit of the last byte.
+		offset := len(b)*8 - len(bits)
+		for i, bit := range bits {
+			if bit {
+				b[(offset+i)/8] |= 1 << (7 - (offset+i)%8)
+			}
+		}
+		return b, nil
+	case DialectSQLServer:
+		for _, bit := range bits {
+			if bit {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		n, err := bitsToUint64(bits)
+		if err != nil {
+			return nil, err
+		}
+		return int64(n), nil
+	}
+}
+
+// DialectValuer implements the DialectValuer interface.
+func (v *bitValue) DialectValuer(dialect string) (driver.Valuer, error) {
+	v.dialect = dialect
+	return v, nil
+}
+
+// uint64ToBits converts n into bits with no leading zeros. Zero is converted
+// into a single 0 bit.
+func uint64ToBits(n uint64) []bool {
+	if n == 0 {
+		return []bool{false}
+	}
+	var bits []bool
+	for ; n > 0; n >>= 1 {
+		bits = append(bits, n&1 == 1)
+	}
+	for i, j := 0, len(bits)-1; i < j; i, j = i+1, j-1 {
+		bits[i], bits[j] = bits[j], bits[i]
+	}
+	return bits
+}
+
+func bitsToUint64(bits []bool) (uint64, error) {
+	var n uint64
+	for i, bit := range bits {
+		if i < len(bits)-64 {
+			if bit {
+				return 0, fmt.Errorf("bit string of length %d overflows uint64", len(bits))
+			}
+			continue
+		}
+		n <<= 1
+		if bit {
+			n |= 1
+		}
+	}
+	return n, nil
+}
+
+func bitsToString(bits []bool) string {
+	b := make([]byte, len(bits))
+	for i, bit := range bits {
+		if bit {
+			b[i] = '1'
+		} else {
+			b[i] = '0'
+		}
+	}
+	return string(b)
+}
+
+// parseBits parses a bit value returned by a database driver. Postgres and
+// SQL Server return bit strings (or bools), MySQL returns big-endian binary
+// strings and SQLite returns integers.
+func parseBits(dialect string, value any) ([]bool, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return []bool{value}, nil
+	case int64:
+		return uint64ToBits(uint64(value)), nil
+	case []byte:
+		if dialect == DialectMySQL {
+			bits := make([]bool, 0, len(value)*8)
+			for _, b := range value {
+				for i := 7; i >= 0; i-- {
+					bits = append(bits, b&(1<<i) != 0)
+				}
+			}
+			return bits, nil
+		}
+		return parseBits(dialect, string(value))
+	case string:
+		bits := make([]bool, len(value))
+		for i, char := range value {
+			switch char {
+			case '0':
+			case '1':
+				bits[i] = true
+			default:
+				return nil, fmt.Errorf("invalid bit string %q", value)
+			}
+		}
+		return bits, nil
+	default:
+		return nil, fmt.Errorf("unable to convert %#v to bits", value)
+	}
+}
+
 func preprocessValue(dialect string, value any) (any, error) {
 	if dialectValuer, ok := value.(DialectValuer); ok {
 		driverValuer, err := dialectValuer.DialectValuer(dialect)
@@ -515,6 +1035,11 @@ func preprocessValue(dialect string, value any) (any, error) {
 		}
 		value = driverValuer
 	}
+	// sql.Null[T] is unwrapped so that its value is preprocessed like any
+	// other value (e.g. an sql.Null[[16]byte] is converted like a UUID).
+	if v, ok := nullGenericValue(value); ok {
+		return preprocessValue(dialect, v)
+	}
 	switch value := value.(type) {
 	case nil:
 		return nil, nil
@@ -534,6 +1059,9 @@ func preprocessValue(dialect string, value any) (any, error) {
 		}
 		return driverValue, nil
 	case driver.Valuer:
+		if isNilValuer(value) {
+			return nil, nil
+		}
 		driverValue, err := value.Value()
 		if err != nil {
 			return nil, fmt.Errorf("calling Value on %#v: %w", value, err)
@@ -542,3 +1070,34 @@ func preprocessValue(dialect string, value any) (any, error) {
 	}
 	return value, nil
 }
+
+// isNilValuer reports whether a driver.Valuer is a nil pointer to a type
+// whose Value method has a value receiver, which would panic if called. Like
+// database/sql, such a nil pointer is treated as NULL.
+func isNilValuer(valuer driver.Valuer) bool {
+	rv := reflect.ValueOf(valuer)
+	return rv.Kind() == reflect.Pointer && rv.IsNil() && rv.Type().Elem().Implements(driverValuerType)
+}
+
+// isNullGeneric reports whether typ is an instantiation of the generic
+// sql.Null[T] type (Go 1.22+). It is detected by reflection so that sq
+// does not require Go 1.22.
+func isNullGeneric(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.PkgPath() == "database/sql" && strings.HasPrefix(typ.Name(), "Null[")
+}
+
+// nullGenericValue returns the value of an sql.Null[T], which is nil if it
+// is not valid. ok is false if value is not an sql.Null[T].
+func nullGenericValue(value any) (v any, ok bool) {
+	if value == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(value)
+	if !isNullGeneric(rv.Type()) {
+		return nil, false
+	}
+	if !rv.FieldByName("Valid").Bool() {
+		return nil, true
+	}
+	return rv.FieldByName("V").Interface(), true
+}
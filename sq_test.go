@@ -1,6 +1,7 @@
 package sq
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
@@ -101,3 +102,258 @@ func Test_preprocessValue(t *testing.T) {
 		})
 	}
 }
+
+func TestNamingStrategy(t *testing.T) {
+	type TestTable struct {
+		name           string
+		wantLowerCase  string
+		wantSnakeCase  string
+		wantPascalCase string
+	}
+
+	tests := []TestTable{
+		{"ACTOR_ID", "actor_id", "actor_id", "ActorId"},
+		{"FirstName", "firstname", "first_name", "FirstName"},
+		{"ActorID", "actorid", "actor_id", "ActorID"},
+		{"HTTPStatusCode", "httpstatuscode", "http_status_code", "HTTPStatusCode"},
+		{"address2", "address2", "address2", "Address2"},
+		{"last_update", "last_update", "last_update", "LastUpdate"},
+		{"Address2Line", "address2line", "address2_line", "Address2Line"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := testutil.Diff(LowerCase(tt.name), tt.wantLowerCase); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			if diff := testutil.Diff(SnakeCase(tt.name), tt.wantSnakeCase); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			if diff := testutil.Diff(PascalCase(tt.name), tt.wantPascalCase); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
+
+// TestDefaultNamingStrategy is not parallel because it modifies the global
+// DefaultNamingStrategy.
+func TestDefaultNamingStrategy(t *testing.T) {
+	type FilmActor struct {
+		TableStruct
+		FilmID     NumberField
+		ActorID    NumberField `sq:"actorid"`
+		LastUpdate TimeField
+	}
+	strategy := NamingStrategy(SnakeCase)
+	DefaultNamingStrategy.Store(&strategy)
+	defer DefaultNamingStrategy.Store(nil)
+	fa := New[FilmActor]("")
+	TestTable{
+		item:       Queryf("SELECT {}, {}, {} FROM {} WHERE {} = {film_id}", fa.FilmID, fa.ActorID, fa.LastUpdate, fa, fa.FilmID, struct{ FilmID int }{FilmID: 1}),
+		wantQuery:  "SELECT film_actor.film_id, film_actor.actorid, film_actor.last_update FROM film_actor WHERE film_actor.film_id = ?",
+		wantArgs:   []any{1},
+		wantParams: map[string][]int{"film_id": {0}},
+	}.assert(t)
+}
+
+func TestSchemaResolver(t *testing.T) {
+	type ORDERS struct {
+		TableStruct
+		ORDER_ID  NumberField
+		TENANT_ID NumberField
+	}
+	type TENANTS struct {
+		TableStruct `sq:"public.tenants"`
+		TENANT_ID   NumberField
+	}
+	o, tn := New[ORDERS](""), New[TENANTS]("")
+	query := Postgres.
+		Select(o.ORDER_ID).
+		From(o).
+		Join(tn, tn.TENANT_ID.Eq(o.TENANT_ID))
+
+	tests := []TestTable{{
+		description: "default schema",
+		ctx:         WithDefaultSchema(context.Background(), "tenant_42"),
+		item:        query,
+		wantQuery:   "SELECT orders.order_id FROM tenant_42.orders JOIN public.tenants ON tenants.tenant_id = orders.tenant_id",
+	}, {
+		description: "schema resolver",
+		ctx: WithSchemaResolver(context.Background(), func(schema, table string) string {
+			return "archive"
+		}),
+		item:      query,
+		wantQuery: "SELECT orders.order_id FROM archive.orders JOIN archive.tenants ON tenants.tenant_id = orders.tenant_id",
+	}, {
+		description: "no resolver",
+		item:        query,
+		wantQuery:   "SELECT orders.order_id FROM orders JOIN public.tenants ON tenants.tenant_id = orders.tenant_id",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+}
+
+func TestBitValue(t *testing.T) {
+	type TestTable struct {
+		description string
+		dialect     string
+		input       any
+		wantOutput  any
+	}
+
+	tests := []TestTable{{
+		description: "nil",
+		input:       nil,
+		wantOutput:  nil,
+	}, {
+		description: "postgres uint64",
+		dialect:     DialectPostgres,
+		input:       uint64(5),
+		wantOutput:  "101",
+	}, {
+		description: "postgres zero",
+		dialect:     DialectPostgres,
+		input:       0,
+		wantOutput:  "0",
+	}, {
+		description: "postgres []bool",
+		dialect:     DialectPostgres,
+		input:       []bool{false, false, true, false},
+		wantOutput:  "0010",
+	}, {
+		description: "mysql uint64",
+		dialect:     DialectMySQL,
+		input:       uint64(0x1ff),
+		wantOutput:  []byte{0x01, 0xff},
+	}, {
+		description: "mysql []bool",
+		dialect:     DialectMySQL,
+		input:       []bool{true, false, true},
+		wantOutput:  []byte{0x05},
+	}, {
+		description: "sqlserver",
+		dialect:     DialectSQLServer,
+		input:       []bool{true},
+		wantOutput:  true,
+	}, {
+		description: "sqlite",
+		dialect:     DialectSQLite,
+		input:       []bool{true, true, false},
+		wantOutput:  int64(6),
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			gotOutput, err := preprocessValue(tt.dialect, BitValue(tt.input))
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(gotOutput, tt.wantOutput); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+
+	t.Run("invalid type", func(t *testing.T) {
+		t.Parallel()
+		_, err := preprocessValue(DialectPostgres, BitValue("1010"))
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+	})
+}
+
+func Test_parseBits(t *testing.T) {
+	type TestTable struct {
+		description string
+		dialect     string
+		input       any
+		wantBits    []bool
+		wantUint64  uint64
+	}
+
+	tests := []TestTable{{
+		description: "postgres bit string",
+		dialect:     DialectPostgres,
+		input:       []byte("00000101"),
+		wantBits:    []bool{false, false, false, false, false, true, false, true},
+		wantUint64:  5,
+	}, {
+		description: "mysql binary string",
+		dialect:     DialectMySQL,
+		input:       []byte{0x01, 0x02},
+		wantBits:    []bool{false, false, false, false, false, false, false, true, false, false, false, false, false, false, true, false},
+		wantUint64:  0x102,
+	}, {
+		description: "sqlserver bool",
+		dialect:     DialectSQLServer,
+		input:       true,
+		wantBits:    []bool{true},
+		wantUint64:  1,
+	}, {
+		description: "sqlite integer",
+		dialect:     DialectSQLite,
+		input:       int64(6),
+		wantBits:    []bool{true, true, false},
+		wantUint64:  6,
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			gotBits, err := parseBits(tt.dialect, tt.input)
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(gotBits, tt.wantBits); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			gotUint64, err := bitsToUint64(gotBits)
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(gotUint64, tt.wantUint64); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+
+	t.Run("invalid bit string", func(t *testing.T) {
+		t.Parallel()
+		_, err := parseBits(DialectPostgres, "0102")
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+	})
+
+	t.Run("uint64 overflow", func(t *testing.T) {
+		t.Parallel()
+		bits := make([]bool, 65)
+		bits[0] = true
+		_, err := bitsToUint64(bits)
+		if err == nil {
+			t.Error(testutil.Callers(), "expected error but got nil")
+		}
+		bits[0] = false
+		bits[64] = true
+		n, err := bitsToUint64(bits)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(n, uint64(1)); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
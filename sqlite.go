@@ -0,0 +1,325 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PragmaQuery represents an SQLite PRAGMA statement.
+type PragmaQuery struct {
+	// Schema (optional) is the attached database the pragma applies to e.g.
+	// main, temp.
+	Schema string
+	// Name is the name of the pragma.
+	Name string
+	// Value (optional) is the value the pragma is set to. If nil, the pragma
+	// is queried instead.
+	Value any
+}
+
+var _ Query = (*PragmaQuery)(nil)
+
+// Pragma returns a new PragmaQuery that queries the given pragma. Use Set to
+// set the pragma instead. A PragmaQuery is a static query, its result can be
+// read with the At methods of the Row.
+//
+//	// PRAGMA journal_mode
+//	journalMode, err := sq.FetchOne(db, sq.Pragma("journal_mode"), func(row *sq.Row) string {
+//	    return row.StringAt(0)
+//	})
+//
+//	// PRAGMA foreign_keys = ON
+//	_, err := sq.Exec(db, sq.Pragma("foreign_keys").Set(true))
+func Pragma(name string) PragmaQuery {
+	return PragmaQuery{Name: name}
+}
+
+// Set returns a new PragmaQuery that sets the pragma to the given value.
+// Booleans are written as ON or OFF, numbers as-is and strings as keywords
+// (e.g. WAL) if they consist of only letters, digits and underscores or as
+// string literals otherwise. Pragmas do not support bind parameters so the
+// value is always written into the query.
+func (q PragmaQuery) Set(value any) PragmaQuery {
+	q.Value = value
+	return q
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (q PragmaQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if dialect != DialectSQLite && dialect != "" {
+		return fmt.Errorf("PRAGMA: %s does not support pragmas", dialect)
+	}
+	if q.Name == "" {
+		return fmt.Errorf("PRAGMA: name is empty")
+	}
+	if !isSQLiteKeyword(q.Name) || q.Name[0] == '-' {
+		return fmt.Errorf("PRAGMA: invalid pragma name %q", q.Name)
+	}
+	buf.WriteString("PRAGMA ")
+	if q.Schema != "" {
+		buf.WriteString(QuoteIdentifier(DialectSQLite, q.Schema) + ".")
+	}
+	buf.WriteString(q.Name)
+	if q.Value == nil {
+		return nil
+	}
+	buf.WriteString(" = ")
+	switch value := q.Value.(type) {
+	case bool:
+		if value {
+			buf.WriteString("ON")
+		} else {
+			buf.WriteString("OFF")
+		}
+	case int:
+		buf.WriteString(strconv.Itoa(value))
+	case int64:
+		buf.WriteString(strconv.FormatInt(value, 10))
+	case string:
+		if isSQLiteKeyword(value) {
+			buf.WriteString(value)
+		} else {
+			buf.WriteString("'" + EscapeQuote(value, '\'') + "'")
+		}
+	default:
+		return fmt.Errorf("PRAGMA %s: unsupported value %#v", q.Name, q.Value)
+	}
+	return nil
+}
+
+// isSQLiteKeyword reports whether s consists of only letters, digits and
+// underscores (and optionally a leading minus sign for negative numbers).
+func isSQLiteKeyword(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	if s == "" {
+		return false
+	}
+	for _, char := range s {
+		if char != '_' && (char < 'a' || char > 'z') && (char < 'A' || char > 'Z') && (char < '0' || char > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// SetFetchableFields implements the Query interface. It always returns false
+// as the result of a pragma can only be read as a static query.
+func (q PragmaQuery) SetFetchableFields(fields []Field) (query Query, ok bool) {
+	return q, false
+}
+
+// GetDialect implements the Query interface.
+func (q PragmaQuery) GetDialect() string { return DialectSQLite }
+
+// SQLiteOptions are the options used by SQLiteInit.
+type SQLiteOptions struct {
+	// WAL switches the database to write-ahead logging (journal_mode = WAL),
+	// which lets readers and a writer run concurrently. The journal mode is
+	// stored in the database file so it only needs to be set once.
+	WAL bool
+
+	// BusyTimeout is how long a query waits for a lock held by another
+	// connection before failing with SQLITE_BUSY (busy_timeout).
+	BusyTimeout time.Duration
+
+	// ForeignKeys turns on foreign key enforcement (foreign_keys = ON),
+	// which SQLite turns off by default.
+	ForeignKeys bool
+
+	// JournalSizeLimit is the size in bytes that the WAL file (or rollback
+	// journal) is truncated to after a checkpoint (journal_size_limit).
+	// Zero leaves the default, -1 means no limit.
+	JournalSizeLimit int64
+
+	// Synchronous (optional) sets the synchronous pragma e.g. NORMAL, which
+	// is safe (and faster than the default FULL) in WAL mode.
+	Synchronous string
+}
+
+// SQLiteInit runs the PRAGMAs for the given options on db. Since it is only
+// meant to be called when a service starts, it fails if any pragma fails,
+// or if the database could not be switched to WAL mode (e.g. an in-memory
+// database).
+//
+// Note that every pragma except journal_mode only applies to the connection
+// it is run on. If db is an *sql.DB, which is a pool of connections, those
+// pragmas only apply to whichever connection runs them. Either limit the pool
+// to a single connection (db.SetMaxOpenConns(1), common for SQLite writers),
+// set the pragmas in the driver's connection string instead or call
+// SQLiteInit on every *sql.Conn.
+//
+//	err := sq.SQLiteInit(db, sq.SQLiteOptions{
+//	    WAL:         true,
+//	    BusyTimeout: 5 * time.Second,
+//	    ForeignKeys: true,
+//	    Synchronous: "NORMAL",
+//	})
+func SQLiteInit(db DB, opts SQLiteOptions) error {
+	return sqliteInit(context.Background(), db, opts, 1)
+}
+
+// SQLiteInitContext is like SQLiteInit but additionally requires a
+// context.Context.
+func SQLiteInitContext(ctx context.Context, db DB, opts SQLiteOptions) error {
+	return sqliteInit(ctx, db, opts, 1)
+}
+
+func sqliteInit(ctx context.Context, db DB, opts SQLiteOptions, skip int) error {
+	if db == nil {
+		return fmt.Errorf("db is nil")
+	}
+	if opts.BusyTimeout < 0 {
+		return fmt.Errorf("SQLiteInit: negative busy timeout %s", opts.BusyTimeout)
+	}
+	// The busy timeout is set first, so that switching to WAL mode waits for
+	// other connections.
+	var pragmas []PragmaQuery
+	if opts.BusyTimeout > 0 {
+		pragmas = append(pragmas, Pragma("busy_timeout").Set(opts.BusyTimeout.Milliseconds()))
+	}
+	if opts.ForeignKeys {
+		pragmas = append(pragmas, Pragma("foreign_keys").Set(true))
+	}
+	if opts.JournalSizeLimit != 0 {
+		pragmas = append(pragmas, Pragma("journal_size_limit").Set(opts.JournalSizeLimit))
+	}
+	for _, pragma := range pragmas {
+		_, err := exec(ctx, db, pragma, skip+1)
+		if err != nil {
+			return fmt.Errorf("SQLiteInit: %w", err)
+		}
+	}
+	if opts.WAL {
+		// Setting the journal mode returns the new journal mode, which is
+		// left unchanged if it could not be switched.
+		cursor, err := fetchCursor(ctx, db, Pragma("journal_mode").Set("WAL"), func(row *Row) string {
+			return row.StringAt(0)
+		}, skip+1)
+		if err != nil {
+			return fmt.Errorf("SQLiteInit: %w", err)
+		}
+		defer cursor.Close()
+		journalMode, err := cursorResult(cursor)
+		if err != nil {
+			return fmt.Errorf("SQLiteInit: %w", err)
+		}
+		if !strings.EqualFold(journalMode, "wal") {
+			return fmt.Errorf("SQLiteInit: could not switch to WAL mode, journal_mode is %s", journalMode)
+		}
+	}
+	// synchronous is set after the journal mode because its recommended
+	// value depends on it.
+	if opts.Synchronous != "" {
+		_, err := exec(ctx, db, Pragma("synchronous").Set(opts.Synchronous), skip+1)
+		if err != nil {
+			return fmt.Errorf("SQLiteInit: %w", err)
+		}
+	}
+	return nil
+}
+
+// JSONExtract returns the value at the given path of a JSON document, using
+// json_extract (SQLite) or JSON_EXTRACT (MySQL). The path is written into the
+// query as a string literal so that the expression can match an index on
+// json_extract.
+//
+//	// json_extract(film.metadata, '$.rating')
+//	sq.JSONExtract(f.METADATA, "$.rating")
+func JSONExtract(json any, path string) Expression {
+	return Expr("{}", jsonPathFunction{name: "json_extract", json: json, path: path})
+}
+
+// JSONSet returns a copy of a JSON document with the value at the given path
+// replaced (or inserted if it doesn't exist), using json_set (SQLite) or
+// JSON_SET (MySQL). It is usually used in an UPDATE.
+//
+//	// UPDATE film SET metadata = json_set(film.metadata, '$.rating', $1)
+//	sq.Update(f).Set(f.METADATA.Set(sq.JSONSet(f.METADATA, "$.rating", "PG")))
+func JSONSet(json any, path string, value any) Expression {
+	return Expr("{}", jsonPathFunction{name: "json_set", json: json, path: path, value: value, hasValue: true})
+}
+
+type jsonPathFunction struct {
+	name     string
+	json     any
+	path     string
+	value    any
+	hasValue bool
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (f jsonPathFunction) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	switch dialect {
+	case DialectSQLite, "":
+		buf.WriteString(f.name + "(")
+	case DialectMySQL:
+		buf.WriteString(strings.ToUpper(f.name) + "(")
+	default:
+		return fmt.Errorf("%s does not support %s", dialect, f.name)
+	}
+	if !strings.HasPrefix(f.path, "$") {
+		return fmt.Errorf("%s: path %q does not start with $", f.name, f.path)
+	}
+	err := WriteValue(ctx, dialect, buf, args, params, f.json)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.name, err)
+	}
+	buf.WriteString(", '" + EscapeQuote(f.path, '\'') + "'")
+	if f.hasValue {
+		buf.WriteString(", ")
+		err = WriteValue(ctx, dialect, buf, args, params, f.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	buf.WriteString(")")
+	return nil
+}
+
+// Match returns an SQLite FTS5 full-text search predicate on the table, which
+// must be an FTS5 virtual table. The query uses the FTS5 query syntax.
+//
+//	e := sq.New[EMAIL]("")
+//	// SELECT email.subject FROM email WHERE email MATCH $1 ORDER BY email.rank
+//	q := sq.SQLite.From(e).Where(e.Match("sqlite AND fts5")).OrderBy(e.Rank())
+func (ts TableStruct) Match(query any) Predicate {
+	return Expr("{}", ftsMatch{table: ts, query: query})
+}
+
+// Rank returns the rank column of an SQLite FTS5 table, which is the bm25
+// score of each row that matches a MATCH predicate. Better matches have a
+// lower rank, so ordering by it puts the best matches first.
+func (ts TableStruct) Rank() NumberField {
+	return NewNumberField("rank", ts)
+}
+
+type ftsMatch struct {
+	table TableStruct
+	query any
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (m ftsMatch) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if dialect != DialectSQLite && dialect != "" {
+		return fmt.Errorf("MATCH: %s does not support FTS5", dialect)
+	}
+	if m.table.name == "" {
+		return fmt.Errorf("MATCH: table has no name")
+	}
+	// The hidden column of an FTS5 table has the same name as the table. If
+	// the table is aliased, the column is qualified with the alias.
+	if m.table.alias != "" {
+		err := NewAnyField(m.table.name, m.table).WriteSQL(ctx, dialect, buf, args, params)
+		if err != nil {
+			return err
+		}
+	} else {
+		buf.WriteString(QuoteIdentifier(dialect, m.table.name))
+	}
+	buf.WriteString(" MATCH ")
+	return WriteValue(ctx, dialect, buf, args, params, m.query)
+}
@@ -0,0 +1,285 @@
+package sq
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestPragmaQuery(t *testing.T) {
+	tests := []TestTable{{
+		description: "query",
+		item:        Pragma("journal_mode"),
+		wantQuery:   "PRAGMA journal_mode",
+	}, {
+		description: "schema",
+		item:        PragmaQuery{Schema: "main", Name: "user_version"},
+		wantQuery:   "PRAGMA main.user_version",
+	}, {
+		description: "bool",
+		item:        Pragma("foreign_keys").Set(false),
+		wantQuery:   "PRAGMA foreign_keys = OFF",
+	}, {
+		description: "number",
+		item:        Pragma("journal_size_limit").Set(int64(-1)),
+		wantQuery:   "PRAGMA journal_size_limit = -1",
+	}, {
+		description: "keyword",
+		item:        Pragma("journal_mode").Set("WAL"),
+		wantQuery:   "PRAGMA journal_mode = WAL",
+	}, {
+		description: "string",
+		item:        Pragma("encoding").Set("UTF-8"),
+		wantQuery:   "PRAGMA encoding = 'UTF-8'",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "postgres",
+		dialect:     DialectPostgres,
+		item:        Pragma("foreign_keys"),
+	}, {
+		description: "invalid name",
+		item:        Pragma("foreign_keys; DROP TABLE actor"),
+	}, {
+		description: "unsupported value",
+		item:        Pragma("cache_size").Set(1.5),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+}
+
+func TestSQLiteInit(t *testing.T) {
+	t.Parallel()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	defer db.Close()
+	// The pragmas other than journal_mode only apply to the connection they
+	// are run on.
+	db.SetMaxOpenConns(1)
+	err = SQLiteInit(db, SQLiteOptions{
+		WAL:              true,
+		BusyTimeout:      2 * time.Second,
+		ForeignKeys:      true,
+		JournalSizeLimit: 1 << 20,
+		Synchronous:      "NORMAL",
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	for pragma, want := range map[string]string{
+		"journal_mode":       "wal",
+		"busy_timeout":       "2000",
+		"foreign_keys":       "1",
+		"journal_size_limit": "1048576",
+		"synchronous":        "1",
+	} {
+		got, err := FetchOne(db, Pragma(pragma), func(row *Row) string {
+			return fmt.Sprint(row.ValueAt(0))
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, want); diff != "" {
+			t.Error(testutil.Callers(), pragma, diff)
+		}
+	}
+
+	// An in-memory database can't be switched to WAL mode.
+	err = SQLiteInit(newDB(t), SQLiteOptions{WAL: true})
+	if err == nil {
+		t.Error(testutil.Callers(), "expected error but got nil")
+	}
+}
+
+func TestSQLiteJSON(t *testing.T) {
+	type FILM struct {
+		TableStruct
+		FILM_ID  NumberField
+		METADATA JSONField
+	}
+	f := New[FILM]("")
+
+	tests := []TestTable{{
+		description: "JSONExtract",
+		dialect:     DialectSQLite,
+		item:        Select(JSONExtract(f.METADATA, "$.rating").As("rating")).From(f),
+		wantQuery:   "SELECT json_extract(film.metadata, '$.rating') AS rating FROM film",
+	}, {
+		description: "mysql JSONExtract",
+		dialect:     DialectMySQL,
+		item:        Select(f.FILM_ID).From(f).Where(JSONExtract(f.METADATA, "$.rating").Eq("PG")),
+		wantQuery:   "SELECT film.film_id FROM film WHERE JSON_EXTRACT(film.metadata, '$.rating') = ?",
+		wantArgs:    []any{"PG"},
+	}, {
+		description: "JSONSet",
+		dialect:     DialectSQLite,
+		item:        Update(f).Set(f.METADATA.Set(JSONSet(f.METADATA, "$.rating", "PG"))).Where(f.FILM_ID.EqInt(1)),
+		wantQuery:   "UPDATE film SET metadata = json_set(film.metadata, '$.rating', $1) WHERE film.film_id = $2",
+		wantArgs:    []any{"PG", 1},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "postgres",
+		dialect:     DialectPostgres,
+		item:        JSONExtract(f.METADATA, "$.rating"),
+	}, {
+		description: "invalid path",
+		item:        JSONSet(f.METADATA, "rating", "PG"),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := db.Exec("CREATE TABLE film (film_id INT PRIMARY KEY, metadata JSON)")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = Exec(db, SQLite.InsertInto(f).Columns(f.FILM_ID, f.METADATA).Values(1, `{"rating":"G"}`))
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = Exec(db, SQLite.Update(f).Set(f.METADATA.Set(JSONSet(f.METADATA, "$.rating", "PG"))))
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		rating, err := FetchOne(db, SQLite.From(f).Where(f.FILM_ID.EqInt(1)), func(row *Row) string {
+			return row.String("{}", JSONExtract(f.METADATA, "$.rating"))
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(rating, "PG"); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
+
+func TestFTS5(t *testing.T) {
+	type EMAIL struct {
+		TableStruct
+		SUBJECT StringField
+	}
+	e1, e2 := New[EMAIL](""), New[EMAIL]("e")
+
+	tests := []TestTable{{
+		description: "Match",
+		dialect:     DialectSQLite,
+		item:        Select(e1.SUBJECT).From(e1).Where(e1.Match("sqlite AND fts5")).OrderBy(e1.Rank()),
+		wantQuery:   "SELECT email.subject FROM email WHERE email MATCH $1 ORDER BY email.rank",
+		wantArgs:    []any{"sqlite AND fts5"},
+	}, {
+		description: "alias",
+		dialect:     DialectSQLite,
+		item:        Select(e2.SUBJECT, e2.Rank()).From(e2).Where(e2.Match("fts5")),
+		wantQuery:   "SELECT e.subject, e.rank FROM email AS e WHERE e.email MATCH $1",
+		wantArgs:    []any{"fts5"},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "postgres",
+		dialect:     DialectPostgres,
+		item:        e1.Match("fts5"),
+	}, {
+		description: "no table name",
+		item:        TableStruct{}.Match("fts5"),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+}
+
+func TestSQLiteUpsertSelect(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := db.Exec("CREATE TABLE actor_copy (actor_id INT PRIMARY KEY, first_name TEXT, last_name TEXT)")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	_, err = Exec(db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS").
+		Values(2, "NICK", "WAHLBERG"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	ac := New[struct {
+		TableStruct `sq:"actor_copy"`
+		ACTOR_ID    NumberField
+		FIRST_NAME  StringField
+		LAST_NAME   StringField
+	}]("")
+	// Without the WHERE true added by the query builder, SQLite fails to
+	// parse the ON CONFLICT clause.
+	for i := 0; i < 2; i++ {
+		_, err = Exec(db, SQLite.
+			InsertInto(ac).
+			Columns(ac.ACTOR_ID, ac.FIRST_NAME, ac.LAST_NAME).
+			Select(SQLite.Select(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).From(ACTOR)).
+			OnConflict(ac.ACTOR_ID).
+			DoUpdateSet(ac.LAST_NAME.Set(ac.LAST_NAME.WithPrefix("EXCLUDED"))),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	}
+	count, err := FetchOne(db, SQLite.From(ac), func(row *Row) int {
+		return row.Int("COUNT(*)")
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(count, 2); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+}
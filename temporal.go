@@ -0,0 +1,150 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// AsOfTable is a table as it was at a point in time. It is created with
+// TableStruct.AsOf.
+type AsOfTable struct {
+	table           TableStruct
+	asOf            any
+	historyTable    string
+	validFromColumn string
+	validToColumn   string
+}
+
+var _ Table = (*AsOfTable)(nil)
+
+// AsOf returns the table as it was at the given point in time (usually a
+// time.Time), for point-in-time reads of temporal tables. It is rendered as:
+//
+//   - SQL Server (system-versioned tables): table FOR SYSTEM_TIME AS OF @p1
+//   - MySQL (MariaDB system-versioned tables): table FOR SYSTEM_TIME AS OF TIMESTAMP ?
+//   - Postgres (CockroachDB): table AS OF SYSTEM TIME $1
+//
+// CockroachDB applies AS OF SYSTEM TIME to the entire query, so it should
+// only be used on the FROM table. Databases without temporal tables (or
+// temporal tables maintained by triggers) can use WithHistory to read from a
+// history table instead.
+//
+//	a := sq.New[ACTOR]("a")
+//	// SELECT a.first_name FROM actor FOR SYSTEM_TIME AS OF @p1 AS a WHERE a.actor_id = @p2
+//	q := sq.SQLServer.From(a.AsOf(yesterday)).Where(a.ACTOR_ID.EqInt(1))
+func (ts TableStruct) AsOf(t any) AsOfTable {
+	return AsOfTable{table: ts, asOf: t}
+}
+
+// WithHistory returns a new AsOfTable that reads from a history table instead
+// of using the database's temporal table syntax. The history table must have
+// the same columns (in the same order) as the table, including the
+// validFromColumn and validToColumn recording the period in which each row is
+// valid. Rows of the current table are valid from validFromColumn onwards, so
+// their validToColumn is ignored. The history table is assumed to be in the
+// same schema as the table. It is rendered as
+//
+//	(SELECT * FROM table WHERE valid_from <= $1
+//	UNION ALL
+//	SELECT * FROM table_history WHERE valid_from <= $2 AND valid_to > $3) AS table
+func (t AsOfTable) WithHistory(historyTable, validFromColumn, validToColumn string) AsOfTable {
+	t.historyTable = historyTable
+	t.validFromColumn = validFromColumn
+	t.validToColumn = validToColumn
+	return t
+}
+
+// WriteSQL implements the SQLWriter interface. The table alias is written by
+// the AsOfTable itself since its position depends on the dialect.
+func (t AsOfTable) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if t.historyTable != "" {
+		return t.writeHistory(ctx, dialect, buf, args, params)
+	}
+	err := t.table.WriteSQL(ctx, dialect, buf, args, params)
+	if err != nil {
+		return err
+	}
+	switch dialect {
+	case DialectSQLServer:
+		buf.WriteString(" FOR SYSTEM_TIME AS OF ")
+	case DialectMySQL:
+		buf.WriteString(" FOR SYSTEM_TIME AS OF TIMESTAMP ")
+	case DialectPostgres:
+		// CockroachDB puts the alias before AS OF SYSTEM TIME.
+		err = t.writeAlias(ctx, dialect, buf)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(" AS OF SYSTEM TIME ")
+		err = WriteValue(ctx, dialect, buf, args, params, t.asOf)
+		if err != nil {
+			return fmt.Errorf("AS OF SYSTEM TIME: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s does not support AS OF queries, use WithHistory to read from a history table instead", dialect)
+	}
+	err = WriteValue(ctx, dialect, buf, args, params, t.asOf)
+	if err != nil {
+		return fmt.Errorf("FOR SYSTEM_TIME AS OF: %w", err)
+	}
+	return t.writeAlias(ctx, dialect, buf)
+}
+
+// writeHistory writes the UNION ALL of the current table and the history
+// table.
+func (t AsOfTable) writeHistory(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if t.validFromColumn == "" || t.validToColumn == "" {
+		return fmt.Errorf("history table %s: valid from and valid to columns are required", t.historyTable)
+	}
+	validFrom := QuoteIdentifier(dialect, t.validFromColumn)
+	validTo := QuoteIdentifier(dialect, t.validToColumn)
+	buf.WriteString("(SELECT * FROM ")
+	err := t.table.WriteSQL(ctx, dialect, buf, args, params)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(" WHERE " + validFrom + " <= ")
+	err = WriteValue(ctx, dialect, buf, args, params, t.asOf)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(" UNION ALL SELECT * FROM ")
+	err = TableStruct{schema: t.table.schema, name: t.historyTable}.WriteSQL(ctx, dialect, buf, args, params)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(" WHERE " + validFrom + " <= ")
+	err = WriteValue(ctx, dialect, buf, args, params, t.asOf)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(" AND " + validTo + " > ")
+	err = WriteValue(ctx, dialect, buf, args, params, t.asOf)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(")")
+	// The subquery takes the place of the table, so it is aliased as the
+	// table name if the table has no alias.
+	if t.table.alias == "" {
+		buf.WriteString(" AS " + QuoteIdentifier(dialect, t.table.name))
+		return nil
+	}
+	return t.writeAlias(ctx, dialect, buf)
+}
+
+func (t AsOfTable) writeAlias(ctx context.Context, dialect string, buf *bytes.Buffer) error {
+	if t.table.alias != "" {
+		quotedAlias, err := quoteAlias(ctx, dialect, t.table.alias)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(" AS " + quotedAlias)
+	}
+	return nil
+}
+
+// IsTable implements the Table interface.
+func (t AsOfTable) IsTable() {}
@@ -0,0 +1,127 @@
+package sq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestAsOfTable(t *testing.T) {
+	asOf := time.Unix(1, 0).UTC()
+	a := New[struct {
+		TableStruct `sq:"actor"`
+		ACTOR_ID    NumberField
+		FIRST_NAME  StringField
+	}]("a")
+
+	tests := []TestTable{{
+		description: "sqlserver",
+		dialect:     DialectSQLServer,
+		item:        SQLServer.Select(a.FIRST_NAME).From(a.AsOf(asOf)).Where(a.ACTOR_ID.EqInt(1)),
+		wantQuery:   "SELECT a.first_name FROM actor FOR SYSTEM_TIME AS OF @p1 AS a WHERE a.actor_id = @p2",
+		wantArgs:    []any{asOf, 1},
+	}, {
+		description: "mysql",
+		dialect:     DialectMySQL,
+		item:        MySQL.Select(a.FIRST_NAME).From(a.AsOf(asOf)),
+		wantQuery:   "SELECT a.first_name FROM actor FOR SYSTEM_TIME AS OF TIMESTAMP ? AS a",
+		wantArgs:    []any{asOf},
+	}, {
+		description: "postgres",
+		dialect:     DialectPostgres,
+		item:        Postgres.Select(a.FIRST_NAME).From(a.AsOf(Expr("follower_read_timestamp()"))),
+		wantQuery:   "SELECT a.first_name FROM actor AS a AS OF SYSTEM TIME follower_read_timestamp()",
+	}, {
+		description: "JOIN",
+		dialect:     DialectSQLServer,
+		item:        SQLServer.Select(ACTOR.FIRST_NAME).From(ACTOR).Join(a.AsOf(asOf), a.ACTOR_ID.Eq(ACTOR.ACTOR_ID)),
+		wantQuery:   "SELECT actor.first_name FROM actor JOIN actor FOR SYSTEM_TIME AS OF @p1 AS a ON a.actor_id = actor.actor_id",
+		wantArgs:    []any{asOf},
+	}, {
+		description: "WithHistory",
+		dialect:     DialectSQLite,
+		item:        SQLite.Select(ACTOR.FIRST_NAME).From(ACTOR.AsOf(asOf).WithHistory("actor_history", "valid_from", "valid_to")),
+		wantQuery: "SELECT actor.first_name FROM (SELECT * FROM actor WHERE valid_from <= $1" +
+			" UNION ALL SELECT * FROM actor_history WHERE valid_from <= $2 AND valid_to > $3) AS actor",
+		wantArgs: []any{asOf, asOf, asOf},
+	}, {
+		description: "WithHistory alias",
+		dialect:     DialectPostgres,
+		item:        Postgres.Select(a.FIRST_NAME).From(a.AsOf(asOf).WithHistory("actor_history", "valid_from", "valid_to")),
+		wantQuery: "SELECT a.first_name FROM (SELECT * FROM actor WHERE valid_from <= $1" +
+			" UNION ALL SELECT * FROM actor_history WHERE valid_from <= $2 AND valid_to > $3) AS a",
+		wantArgs: []any{asOf, asOf, asOf},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "sqlite without history",
+		dialect:     DialectSQLite,
+		item:        SQLite.Select(a.FIRST_NAME).From(a.AsOf(asOf)),
+	}, {
+		description: "missing history columns",
+		dialect:     DialectPostgres,
+		item:        Postgres.Select(a.FIRST_NAME).From(a.AsOf(asOf).WithHistory("actor_history", "valid_from", "")),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+
+	t.Run("fetch WithHistory", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := db.Exec("ALTER TABLE actor ADD COLUMN valid_from INT NOT NULL DEFAULT 0")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = db.Exec("ALTER TABLE actor ADD COLUMN valid_to INT")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = db.Exec("CREATE TABLE actor_history AS SELECT * FROM actor WHERE 1 = 0")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		// PENELOPE was renamed to PENNY at 10.
+		_, err = db.Exec("INSERT INTO actor (actor_id, first_name, last_name, valid_from) VALUES (1, 'PENNY', 'GUINESS', 10)")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		_, err = db.Exec("INSERT INTO actor_history (actor_id, first_name, last_name, last_update, valid_from, valid_to) VALUES (1, 'PENELOPE', 'GUINESS', '', 0, 10)")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		for _, tt := range []struct {
+			asOf      int
+			wantNames []string
+		}{
+			{5, []string{"PENELOPE"}},
+			{10, []string{"PENNY"}},
+			{20, []string{"PENNY"}},
+		} {
+			actor := ACTOR.AsOf(tt.asOf)
+			names, err := FetchAll(db, SQLite.From(actor.WithHistory("actor_history", "valid_from", "valid_to")), func(row *Row) string {
+				return row.StringField(ACTOR.FIRST_NAME)
+			})
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+			if diff := testutil.Diff(names, tt.wantNames); diff != "" {
+				t.Error(testutil.Callers(), tt.asOf, diff)
+			}
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// WithTempTable creates a temporary table with the given name from the
+// results of a query, calls fn with the temporary table and drops the
+// temporary table once fn returns.
+//
+//	postgres:  CREATE TEMPORARY TABLE name AS <query>
+//	sqlite:    CREATE TEMP TABLE name AS <query>
+//	mysql:     CREATE TEMPORARY TABLE name AS <query>
+//	sqlserver: SELECT * INTO #name FROM (<query>) AS tmp
+//
+// Temporary tables are only visible to the database connection that created
+// them, so fn must run its queries on the DB passed to it instead of the
+// original db. If db is (or wraps) an *sql.DB, a single connection is taken
+// from the pool for the duration of WithTempTable. If db is an *sql.Tx or
+// *sql.Conn, it is used as-is.
+//
+// Columns of the temporary table can be referenced by creating fields on the
+// table e.g. sq.NewNumberField("actor_id", tmp). For sqlserver, the table
+// name is automatically prefixed with '#'.
+func WithTempTable(db DB, name string, query Query, fn func(db DB, tmp TableStruct) error) error {
+	return withTempTable(context.Background(), db, name, query, fn, 1)
+}
+
+// WithTempTableContext is like WithTempTable but additionally requires a
+// context.Context. The temporary table is still dropped if the context is done
+// by the time fn returns.
+func WithTempTableContext(ctx context.Context, db DB, name string, query Query, fn func(db DB, tmp TableStruct) error) error {
+	return withTempTable(ctx, db, name, query, fn, 1)
+}
+
+func withTempTable(ctx context.Context, db DB, name string, query Query, fn func(db DB, tmp TableStruct) error, skip int) (err error) {
+	if db == nil {
+		return fmt.Errorf("db is nil")
+	}
+	if query == nil {
+		return fmt.Errorf("query is nil")
+	}
+	if fn == nil {
+		return fmt.Errorf("WithTempTable: fn is nil")
+	}
+	dialect := query.GetDialect()
+	if dialect == "" {
+		dialect = dbDialect(db)
+	}
+	if dialect == DialectSQLServer && len(name) > 0 && name[0] != '#' {
+		name = "#" + name
+	}
+	if err := ValidateIdentifier(dialect, name); err != nil {
+		return fmt.Errorf("WithTempTable: %w", err)
+	}
+	tmp := NewTableStruct("", name, "")
+	createQuery, dropQuery, err := tempTableQueries(dialect, tmp, query)
+	if err != nil {
+		return fmt.Errorf("WithTempTable: %w", err)
+	}
+	if sqlDB, ok := unwrapSQLDB(db); ok {
+		var conn *sql.Conn
+		conn, err = sqlDB.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		db = rewrapDB(db, conn)
+	}
+	_, err = exec(ctx, db, createQuery, skip+1)
+	if err != nil {
+		return fmt.Errorf("WithTempTable: creating %s: %w", name, err)
+	}
+	defer func() {
+		// The temporary table is dropped even if ctx is done, otherwise it
+		// would linger on the connection after it is returned to the pool.
+		dropCtx, cancel := context.WithTimeout(uncanceledContext{ctx}, tempTableDropTimeout)
+		defer cancel()
+		_, dropErr := exec(dropCtx, db, dropQuery, skip+2)
+		if err == nil && dropErr != nil {
+			err = fmt.Errorf("WithTempTable: dropping %s: %w", name, dropErr)
+		}
+	}()
+	return fn(db, tmp)
+}
+
+// tempTableDropTimeout is how long WithTempTable waits for its temporary table
+// to be dropped.
+const tempTableDropTimeout = 5 * time.Second
+
+// uncanceledContext keeps the values of a context but not its deadline or
+// cancellation, like context.WithoutCancel (which needs go1.21).
+type uncanceledContext struct{ context.Context }
+
+func (uncanceledContext) Deadline() (deadline time.Time, ok bool) { return deadline, false }
+
+func (uncanceledContext) Done() <-chan struct{} { return nil }
+
+func (uncanceledContext) Err() error { return nil }
+
+// tempTableQueries returns the queries that create the temporary table tmp
+// from the results of query and drop it.
+func tempTableQueries(dialect string, tmp TableStruct, query Query) (createQuery, dropQuery CustomQuery, err error) {
+	switch dialect {
+	case DialectSQLite:
+		createQuery = Queryf("CREATE TEMP TABLE {} AS {}", tmp, query)
+		dropQuery = Queryf("DROP TABLE temp.{}", tmp)
+	case DialectPostgres:
+		createQuery = Queryf("CREATE TEMPORARY TABLE {} AS {}", tmp, query)
+		dropQuery = Queryf("DROP TABLE pg_temp.{}", tmp)
+	case DialectMySQL:
+		createQuery = Queryf("CREATE TEMPORARY TABLE {} AS {}", tmp, query)
+		dropQuery = Queryf("DROP TEMPORARY TABLE {}", tmp)
+	case DialectSQLServer:
+		createQuery = Queryf("SELECT * INTO {} FROM ({}) AS tmp", tmp, query)
+		dropQuery = Queryf("DROP TABLE {}", tmp)
+	default:
+		return createQuery, dropQuery, fmt.Errorf("unsupported dialect %q", dialect)
+	}
+	return createQuery.SetDialect(dialect), dropQuery.SetDialect(dialect), nil
+}
+
+var materializeSeq atomic.Uint64
+
+// MaterializedTable is a temporary table holding the results of a query,
+// created by Materialize. It can be selected from and joined like any other
+// table, and its columns can be referenced by creating fields on its
+// TableStruct e.g. sq.NewNumberField("actor_id", tmp.TableStruct).
+type MaterializedTable struct {
+	TableStruct
+	dialect string
+}
+
+// Materialize creates a temporary table from the results of a query and
+// returns it, so that an expensive intermediate result can be computed once
+// and then joined by several queries.
+//
+// Temporary tables are only visible to the database connection that created
+// them, so db must be an *sql.Tx or *sql.Conn and the queries that use the
+// MaterializedTable must run on the same db. Passing an *sql.DB is an error
+// because the following queries could run on a different connection from its
+// pool. The temporary table is named sq_materialized_<n> (prefixed with '#'
+// for sqlserver) and lives until it is dropped with Drop or the connection is
+// closed.
+//
+//	tx, err := db.BeginTx(ctx, nil)
+//	defer tx.Rollback()
+//	tmp, err := sq.Materialize(tx, expensiveQuery)
+//	defer tmp.Drop(tx)
+//	actorID := sq.NewNumberField("actor_id", tmp.TableStruct)
+//	films, err := sq.FetchAll(tx, sq.From(f).Join(tmp, actorID.Eq(f.ACTOR_ID)), filmRowMapper)
+//	actors, err := sq.FetchAll(tx, sq.From(a).Join(tmp, actorID.Eq(a.ACTOR_ID)), actorRowMapper)
+func Materialize(db DB, query Query) (MaterializedTable, error) {
+	return materialize(context.Background(), db, query, 1)
+}
+
+// MaterializeContext is like Materialize but additionally requires a
+// context.Context.
+func MaterializeContext(ctx context.Context, db DB, query Query) (MaterializedTable, error) {
+	return materialize(ctx, db, query, 1)
+}
+
+func materialize(ctx context.Context, db DB, query Query, skip int) (tbl MaterializedTable, err error) {
+	if db == nil {
+		return tbl, fmt.Errorf("db is nil")
+	}
+	if query == nil {
+		return tbl, fmt.Errorf("query is nil")
+	}
+	if _, ok := unwrapSQLDB(db); ok {
+		return tbl, fmt.Errorf("Materialize: db must be an *sql.Tx or *sql.Conn because temporary tables are only visible to the connection that created them")
+	}
+	dialect := query.GetDialect()
+	if dialect == "" {
+		dialect = dbDialect(db)
+	}
+	name := "sq_materialized_" + strconv.FormatUint(materializeSeq.Add(1), 10)
+	if dialect == DialectSQLServer {
+		name = "#" + name
+	}
+	tbl = MaterializedTable{TableStruct: NewTableStruct("", name, ""), dialect: dialect}
+	createQuery, _, err := tempTableQueries(dialect, tbl.TableStruct, query)
+	if err != nil {
+		return MaterializedTable{}, fmt.Errorf("Materialize: %w", err)
+	}
+	_, err = exec(ctx, db, createQuery, skip+1)
+	if err != nil {
+		return MaterializedTable{}, fmt.Errorf("Materialize: creating %s: %w", name, err)
+	}
+	return tbl, nil
+}
+
+// As returns a copy of the MaterializedTable with the given alias, so that it
+// can be joined more than once in the same query.
+func (tbl MaterializedTable) As(alias string) MaterializedTable {
+	tbl.TableStruct = NewTableStruct("", tbl.TableStruct.name, alias)
+	return tbl
+}
+
+// Drop drops the temporary table. It must be called with the same db that was
+// passed to Materialize.
+func (tbl MaterializedTable) Drop(db DB) error {
+	return tbl.drop(context.Background(), db, 1)
+}
+
+// DropContext is like Drop but additionally requires a context.Context.
+func (tbl MaterializedTable) DropContext(ctx context.Context, db DB) error {
+	return tbl.drop(ctx, db, 1)
+}
+
+func (tbl MaterializedTable) drop(ctx context.Context, db DB, skip int) error {
+	if db == nil {
+		return fmt.Errorf("db is nil")
+	}
+	_, dropQuery, err := tempTableQueries(tbl.dialect, NewTableStruct("", tbl.TableStruct.name, ""), nil)
+	if err != nil {
+		return fmt.Errorf("Materialize: %w", err)
+	}
+	_, err = exec(ctx, db, dropQuery, skip+1)
+	if err != nil {
+		return fmt.Errorf("Materialize: dropping %s: %w", tbl.TableStruct.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package sq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestWithTempTable(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := db.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'PENELOPE', 'GUINESS'), (2, 'NICK', 'WAHLBERG'), (3, 'ED', 'CHASE')")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	var gotNames []string
+	err = WithTempTable(db, "tmp_actor", SQLite.
+		Select(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME).
+		From(ACTOR).
+		Where(ACTOR.ACTOR_ID.GtInt(1)),
+		func(db DB, tmp TableStruct) error {
+			firstName := NewStringField("first_name", tmp)
+			var err error
+			gotNames, err = FetchAll(db, SQLite.
+				From(tmp).
+				OrderBy(NewNumberField("actor_id", tmp)),
+				func(row *Row) string {
+					return row.StringField(firstName)
+				},
+			)
+			return err
+		},
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(gotNames, []string{"NICK", "ED"}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_temp_master WHERE name = 'tmp_actor'").Scan(&count)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if count != 0 {
+		t.Errorf(testutil.Callers() + " temp table was not dropped")
+	}
+
+	err = WithTempTable(db, `tmp"actor`, SQLite.Select(ACTOR.ACTOR_ID).From(ACTOR), func(db DB, tmp TableStruct) error {
+		return nil
+	})
+	if err == nil {
+		t.Error(testutil.Callers(), "expected error but got nil")
+	}
+
+	t.Run("wrapped *sql.DB", func(t *testing.T) {
+		t.Parallel()
+		sqlDB, _ := newFileDB(t)
+		db := NewDB(sqlDB, DialectSQLite)
+		_, err := Exec(db, Queryf("INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'PENELOPE', 'GUINESS')"))
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		err = WithTempTable(db, "tmp_actor", Select(ACTOR.ACTOR_ID).From(ACTOR), func(db DB, tmp TableStruct) error {
+			if diff := testutil.Diff(dbDialect(db), DialectSQLite); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			gotIDs, err := FetchAll(db, From(tmp), func(row *Row) int {
+				return row.IntField(NewNumberField("actor_id", tmp))
+			})
+			if err != nil {
+				return err
+			}
+			if diff := testutil.Diff(gotIDs, []int{1}); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	})
+
+	t.Run("logged *sql.DB", func(t *testing.T) {
+		t.Parallel()
+		sqlDB, _ := newFileDB(t)
+		_, err := sqlDB.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'PENELOPE', 'GUINESS'), (2, 'NICK', 'WAHLBERG')")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		db := WithRetry(Limited(Log(NewDB(sqlDB, DialectSQLite)), 2), RetryPolicy{})
+		err = WithTempTable(db, "tmp_actor", Select(ACTOR.ACTOR_ID).From(ACTOR), func(db DB, tmp TableStruct) error {
+			if dbRetryPolicy(db) == nil {
+				t.Error(testutil.Callers(), "WithRetry was not re-applied")
+			}
+			if dbSemaphore(db) == nil {
+				t.Error(testutil.Callers(), "Limited was not re-applied")
+			}
+			// A second query while a cursor is open must still run on the
+			// connection that has the temporary table.
+			cursor, err := FetchCursor(db, From(tmp), func(row *Row) int {
+				return row.IntField(NewNumberField("actor_id", tmp))
+			})
+			if err != nil {
+				return err
+			}
+			defer cursor.Close()
+			count, err := FetchCount(db, From(tmp))
+			if err != nil {
+				return err
+			}
+			if diff := testutil.Diff(count, int64(2)); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			return cursor.Close()
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		conn, err := newDB(t).Conn(context.Background())
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer conn.Close()
+		// The sqlite driver doesn't check if the context is done before
+		// running a query, so a hook does it instead.
+		db := WithQueryHooks(conn, func(ctx context.Context, dialect string, query string, args []any) (string, []any, error) {
+			return query, args, ctx.Err()
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		err = WithTempTableContext(ctx, db, "tmp_actor", SQLite.Select(ACTOR.ACTOR_ID).From(ACTOR), func(db DB, tmp TableStruct) error {
+			cancel()
+			return ctx.Err()
+		})
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error but got nil")
+		}
+		var count int
+		err = conn.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM sqlite_temp_master WHERE name = 'tmp_actor'").Scan(&count)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if count != 0 {
+			t.Errorf(testutil.Callers() + " temp table was not dropped")
+		}
+	})
+}
+
+func TestMaterialize(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := db.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'PENELOPE', 'GUINESS'), (2, 'NICK', 'WAHLBERG'), (3, 'ED', 'CHASE')")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	query := SQLite.Select(ACTOR.ACTOR_ID).From(ACTOR).Where(ACTOR.ACTOR_ID.GtInt(1))
+
+	_, err = Materialize(db, query)
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error for *sql.DB but got nil")
+	}
+	_, err = Materialize(Log(db), query)
+	if err == nil {
+		t.Fatal(testutil.Callers(), "expected error for a logged *sql.DB but got nil")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	defer tx.Rollback()
+	tmp, err := Materialize(tx, query)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	actorID := NewNumberField("actor_id", tmp.TableStruct)
+	gotNames, err := FetchAll(tx, SQLite.
+		From(ACTOR).
+		Join(tmp, actorID.Eq(ACTOR.ACTOR_ID)).
+		OrderBy(ACTOR.ACTOR_ID),
+		func(row *Row) string {
+			return row.StringField(ACTOR.FIRST_NAME)
+		},
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(gotNames, []string{"NICK", "ED"}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	t1, t2 := tmp.As("t1"), tmp.As("t2")
+	gotCount, err := FetchOne(tx, SQLite.
+		From(t1).
+		CrossJoin(t2),
+		func(row *Row) int {
+			return row.Int("COUNT(*)")
+		},
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(gotCount, 4); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	err = tmp.Drop(tx)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	var count int
+	err = tx.QueryRow("SELECT COUNT(*) FROM sqlite_temp_master WHERE name = ?", tmp.TableStruct.name).Scan(&count)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if count != 0 {
+		t.Errorf(testutil.Callers() + " temp table was not dropped")
+	}
+}
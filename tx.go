@@ -0,0 +1,326 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Savepoint creates a savepoint with the given name inside a transaction.
+// Changes made after the savepoint can be undone with RollbackTo without
+// rolling back the entire transaction. The dialect of tx (see NewDB) or
+// DefaultDialect is used.
+//
+//	postgres, sqlite, mysql: SAVEPOINT name
+//	sqlserver:               SAVE TRANSACTION name
+func Savepoint(tx DB, name string) error {
+	return savepoint(context.Background(), tx, dbDialect(tx), "SAVEPOINT", name, 1)
+}
+
+// SavepointContext is like Savepoint but additionally requires a
+// context.Context.
+func SavepointContext(ctx context.Context, tx DB, name string) error {
+	return savepoint(ctx, tx, dbDialect(tx), "SAVEPOINT", name, 1)
+}
+
+// RollbackTo rolls back a transaction to the savepoint with the given name.
+// The savepoint remains valid and can be rolled back to again. The dialect of
+// tx (see NewDB) or DefaultDialect is used.
+//
+//	postgres, sqlite, mysql: ROLLBACK TO SAVEPOINT name
+//	sqlserver:               ROLLBACK TRANSACTION name
+func RollbackTo(tx DB, name string) error {
+	return savepoint(context.Background(), tx, dbDialect(tx), "ROLLBACK TO SAVEPOINT", name, 1)
+}
+
+// RollbackToContext is like RollbackTo but additionally requires a
+// context.Context.
+func RollbackToContext(ctx context.Context, tx DB, name string) error {
+	return savepoint(ctx, tx, dbDialect(tx), "ROLLBACK TO SAVEPOINT", name, 1)
+}
+
+// Release releases the savepoint with the given name, keeping the changes
+// made after it. SQL Server has no equivalent (savepoints last until the
+// transaction ends) so Release is a no-op for sqlserver. The dialect of tx
+// (see NewDB) or DefaultDialect is used.
+//
+//	postgres, sqlite, mysql: RELEASE SAVEPOINT name
+func Release(tx DB, name string) error {
+	return savepoint(context.Background(), tx, dbDialect(tx), "RELEASE SAVEPOINT", name, 1)
+}
+
+// ReleaseContext is like Release but additionally requires a
+// context.Context.
+func ReleaseContext(ctx context.Context, tx DB, name string) error {
+	return savepoint(ctx, tx, dbDialect(tx), "RELEASE SAVEPOINT", name, 1)
+}
+
+func savepoint(ctx context.Context, tx DB, dialect string, command string, name string, skip int) error {
+	if tx == nil {
+		return fmt.Errorf("tx is nil")
+	}
+	if name == "" {
+		return fmt.Errorf("savepoint name is empty")
+	}
+	for i, char := range name {
+		if char != '_' && !unicode.IsLetter(char) && !(unicode.IsDigit(char) && i > 0) {
+			return fmt.Errorf("invalid savepoint name %q: only letters, digits and underscores are allowed", name)
+		}
+	}
+	if dialect == DialectSQLServer {
+		switch command {
+		case "SAVEPOINT":
+			command = "SAVE TRANSACTION"
+		case "ROLLBACK TO SAVEPOINT":
+			command = "ROLLBACK TRANSACTION"
+		case "RELEASE SAVEPOINT":
+			return nil
+		}
+	}
+	_, err := exec(ctx, tx, Queryf(command+" "+name).SetDialect(dialect), skip+1)
+	return err
+}
+
+// readOnlyTxOptions returns the options of a read-only transaction for a
+// dialect. The isolation level is REPEATABLE READ so that every query in the
+// transaction sees the same snapshot of the database. SQL Server does not
+// support read-only transactions so only the isolation level is set, sqlite
+// transactions are always serializable.
+func readOnlyTxOptions(dialect string) *sql.TxOptions {
+	switch dialect {
+	case DialectSQLite:
+		return &sql.TxOptions{ReadOnly: true}
+	case DialectSQLServer:
+		return &sql.TxOptions{Isolation: sql.LevelRepeatableRead}
+	default:
+		return &sql.TxOptions{ReadOnly: true, Isolation: sql.LevelRepeatableRead}
+	}
+}
+
+// ReadOnlyTx runs fn inside a read-only transaction, so that all queries run
+// by fn on the tx passed to it see a consistent view of the database.
+// Read-only transactions can also be routed to read replicas by proxies that
+// support it. The transaction is committed if fn returns nil and rolled back
+// otherwise.
+//
+// The db must be able to begin transactions (e.g. an *sql.DB or *sql.Conn,
+// optionally wrapped with NewDB). The dialect of the db (see NewDB) or
+// DefaultDialect decides the transaction options.
+func ReadOnlyTx(db DB, fn func(tx DB) error) error {
+	return ReadOnlyTxContext(context.Background(), db, fn)
+}
+
+// ReadOnlyTxContext is like ReadOnlyTx but additionally requires a
+// context.Context.
+func ReadOnlyTxContext(ctx context.Context, db DB, fn func(tx DB) error) error {
+	if db == nil {
+		return fmt.Errorf("db is nil")
+	}
+	if fn == nil {
+		return fmt.Errorf("ReadOnlyTx: fn is nil")
+	}
+	return withTx(ctx, db, readOnlyTxOptions(dbDialect(db)), fn)
+}
+
+// FetchAllReadOnly is like FetchAll but runs the query inside a read-only
+// transaction (see ReadOnlyTx).
+func FetchAllReadOnly[T any](db DB, query Query, rowmapper func(*Row) T) ([]T, error) {
+	return fetchAllReadOnly(context.Background(), db, query, rowmapper, 1)
+}
+
+// FetchAllReadOnlyContext is like FetchAllReadOnly but additionally requires
+// a context.Context.
+func FetchAllReadOnlyContext[T any](ctx context.Context, db DB, query Query, rowmapper func(*Row) T) ([]T, error) {
+	return fetchAllReadOnly(ctx, db, query, rowmapper, 1)
+}
+
+func fetchAllReadOnly[T any](ctx context.Context, db DB, query Query, rowmapper func(*Row) T, skip int) (results []T, err error) {
+	if db == nil {
+		return nil, fmt.Errorf("db is nil")
+	}
+	if query == nil {
+		return nil, fmt.Errorf("query is nil")
+	}
+	dialect := query.GetDialect()
+	if dialect == "" {
+		dialect = dbDialect(db)
+	}
+	err = withTx(ctx, db, readOnlyTxOptions(dialect), func(tx DB) error {
+		cursor, err := fetchCursor(ctx, tx, query, rowmapper, skip+3)
+		if err != nil {
+			return err
+		}
+		defer cursor.Close()
+		results, err = cursorResults(cursor)
+		return err
+	})
+	return results, err
+}
+
+// TxOptions are the options of a transaction started by Tx.
+type TxOptions struct {
+	// Isolation is the isolation level of the transaction. If zero, the
+	// database's default isolation level is used.
+	Isolation sql.IsolationLevel
+
+	// ReadOnly makes the transaction read-only. SQL Server does not support
+	// read-only transactions.
+	ReadOnly bool
+
+	// Deferrable makes a SERIALIZABLE READ ONLY transaction wait until it can
+	// run without the possibility of a serialization failure (postgres only).
+	Deferrable bool
+}
+
+// Tx runs fn inside a transaction with the given options. The transaction is
+// committed if fn returns nil and rolled back otherwise.
+//
+// For postgres, the options are applied with a SET TRANSACTION statement at
+// the start of the transaction so that they work with every driver and
+// include DEFERRABLE, which sql.TxOptions cannot express. For other dialects,
+// the options are passed to the driver as sql.TxOptions. The dialect of the
+// db (see NewDB) or DefaultDialect is used.
+//
+//	// postgres: SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, READ ONLY, DEFERRABLE
+//	err := sq.Tx(db, sq.TxOptions{
+//	    Isolation:  sql.LevelSerializable,
+//	    ReadOnly:   true,
+//	    Deferrable: true,
+//	}, func(tx sq.DB) error {
+//	    // ...
+//	})
+func Tx(db DB, opts TxOptions, fn func(tx DB) error) error {
+	return runTx(context.Background(), db, opts, fn, 1)
+}
+
+// TxContext is like Tx but additionally requires a context.Context.
+func TxContext(ctx context.Context, db DB, opts TxOptions, fn func(tx DB) error) error {
+	return runTx(ctx, db, opts, fn, 1)
+}
+
+func runTx(ctx context.Context, db DB, opts TxOptions, fn func(tx DB) error, skip int) error {
+	if db == nil {
+		return fmt.Errorf("db is nil")
+	}
+	if fn == nil {
+		return fmt.Errorf("Tx: fn is nil")
+	}
+	dialect := dbDialect(db)
+	if opts.Deferrable && dialect != DialectPostgres {
+		return fmt.Errorf("Tx: %s does not support DEFERRABLE transactions", dialect)
+	}
+	if opts.ReadOnly && dialect == DialectSQLServer {
+		return fmt.Errorf("Tx: %s does not support read-only transactions", dialect)
+	}
+	if dialect != DialectPostgres {
+		return withTx(ctx, db, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly}, fn)
+	}
+	setTransaction, err := setTransactionStatement(opts)
+	if err != nil {
+		return fmt.Errorf("Tx: %w", err)
+	}
+	return withTx(ctx, db, &sql.TxOptions{}, func(tx DB) error {
+		if setTransaction != "" {
+			_, err := exec(ctx, tx, Queryf(setTransaction).SetDialect(dialect), skip+3)
+			if err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+// setTransactionStatement returns the postgres SET TRANSACTION statement for
+// the TxOptions, or an empty string if all the options are the defaults.
+func setTransactionStatement(opts TxOptions) (string, error) {
+	var modes []string
+	switch opts.Isolation {
+	case sql.LevelDefault:
+	case sql.LevelReadUncommitted, sql.LevelReadCommitted, sql.LevelRepeatableRead, sql.LevelSerializable:
+		modes = append(modes, "ISOLATION LEVEL "+strings.ToUpper(opts.Isolation.String()))
+	default:
+		return "", fmt.Errorf("postgres does not support the %s isolation level", opts.Isolation)
+	}
+	if opts.ReadOnly {
+		modes = append(modes, "READ ONLY")
+	}
+	if opts.Deferrable {
+		modes = append(modes, "DEFERRABLE")
+	}
+	if len(modes) == 0 {
+		return "", nil
+	}
+	return "SET TRANSACTION " + strings.Join(modes, ", "), nil
+}
+
+// rewrapDB wraps a connection or transaction taken from db with the logger,
+// Limited, WithRetry, WithoutPreparedStatements, query hooks, interceptors and
+// dialect of db, so that queries run on it behave like queries run on db. The
+// RetryPolicy is not applied to a transaction, because a failed query aborts
+// the whole transaction.
+func rewrapDB(db DB, inner DB) DB {
+	_, isTx := inner.(*sql.Tx)
+	if logger, ok := db.(SqLogger); ok {
+		inner = &loggerDB{DB: inner, SqLogger: logger}
+	}
+	if preparedStatementsDisabled(db) {
+		inner = WithoutPreparedStatements(inner)
+	}
+	if policy := dbRetryPolicy(db); policy != nil && !isTx {
+		inner = withOption(inner, policy)
+	}
+	if sem := dbSemaphore(db); sem != nil {
+		inner = withOption(inner, sem)
+	}
+	if hooks := queryHooks(db); len(hooks) > 0 {
+		inner = WithQueryHooks(inner, hooks...)
+	}
+	if interceptors := queryInterceptors(db); len(interceptors) > 0 {
+		inner = WithInterceptors(inner, interceptors...)
+	}
+	if db, ok := db.(interface{ SqDialect() string }); ok {
+		inner = NewDB(inner, db.SqDialect())
+	}
+	return inner
+}
+
+// withTx runs fn inside a transaction begun on db with the given options. The
+// tx passed to fn keeps the logger and dialect of db. The transaction is
+// committed if fn returns nil and rolled back otherwise.
+func withTx(ctx context.Context, db DB, opts *sql.TxOptions, fn func(tx DB) error) (err error) {
+	type beginner interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	}
+	unwrapped := db
+	for {
+		if _, ok := unwrapped.(beginner); ok {
+			break
+		}
+		wrapper, ok := unwrapped.(interface{ unwrap() DB })
+		if !ok {
+			return fmt.Errorf("%T cannot begin transactions", db)
+		}
+		unwrapped = wrapper.unwrap()
+	}
+	tx, err := unwrapped.(beginner).BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	txDB := rewrapDB(db, tx)
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	err = fn(txDB)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,231 @@
+package sq
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestSavepoint(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	defer tx.Rollback()
+	sqTx := NewDB(tx, DialectSQLite)
+	insertActor := func(actorID int) {
+		_, err := Exec(sqTx, SQLite.
+			InsertInto(ACTOR).
+			Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+			Values(actorID, "bob", "the builder"),
+		)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	}
+	insertActor(1)
+	err = Savepoint(sqTx, "sp1")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	insertActor(2)
+	err = RollbackTo(sqTx, "sp1")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	insertActor(3)
+	err = Release(sqTx, "sp1")
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	actorIDs, err := FetchAll(db, SQLite.From(ACTOR).OrderBy(ACTOR.ACTOR_ID), func(row *Row) int {
+		return row.IntField(ACTOR.ACTOR_ID)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(actorIDs, []int{1, 3}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	t.Run("invalid name", func(t *testing.T) {
+		for _, name := range []string{"", "1sp", "sp; DROP TABLE actor", "sp{}"} {
+			err := Savepoint(db, name)
+			if err == nil {
+				t.Errorf("%s expected error for savepoint name %q", testutil.Callers(), name)
+			}
+		}
+	})
+
+	t.Run("sqlserver Release is a no-op", func(t *testing.T) {
+		err := Release(NewDB(db, DialectSQLServer), "sp1")
+		if err != nil {
+			t.Error(testutil.Callers(), err)
+		}
+	})
+}
+
+func TestReadOnlyTx(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	_, err := Exec(db, SQLite.
+		InsertInto(ACTOR).
+		Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+		Values(1, "PENELOPE", "GUINESS").
+		Values(2, "NICK", "WAHLBERG"),
+	)
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	sqDB := NewDB(db, DialectSQLite)
+	actorIDs, err := FetchAllReadOnly(sqDB, From(ACTOR).OrderBy(ACTOR.ACTOR_ID), func(row *Row) int {
+		return row.IntField(ACTOR.ACTOR_ID)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(actorIDs, []int{1, 2}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+	var count int
+	err = ReadOnlyTx(sqDB, func(tx DB) error {
+		if _, ok := tx.(interface{ SqDialect() string }); !ok {
+			t.Error(testutil.Callers(), "tx did not keep the dialect of the db")
+		}
+		count, err = FetchOne(tx, From(ACTOR), func(row *Row) int {
+			return row.Int("COUNT(*)")
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(count, 2); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	t.Run("cannot begin", func(t *testing.T) {
+		t.Parallel()
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		defer tx.Rollback()
+		err = ReadOnlyTx(tx, func(tx DB) error { return nil })
+		if err == nil {
+			t.Fatal(testutil.Callers(), "expected error, got nil")
+		}
+	})
+}
+
+func TestTx(t *testing.T) {
+	t.Parallel()
+	db := newDB(t)
+	insertActor := func(tx DB, actorID int) error {
+		_, err := Exec(tx, SQLite.
+			InsertInto(ACTOR).
+			Columns(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME, ACTOR.LAST_NAME).
+			Values(actorID, "bob", "the builder"),
+		)
+		return err
+	}
+	err := Tx(db, TxOptions{Isolation: sql.LevelSerializable}, func(tx DB) error {
+		return insertActor(tx, 1)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	errRollback := errors.New("rollback")
+	err = Tx(db, TxOptions{}, func(tx DB) error {
+		if err := insertActor(tx, 2); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf(testutil.Callers()+" expected errRollback, got %v", err)
+	}
+	actorIDs, err := FetchAll(db, SQLite.From(ACTOR).OrderBy(ACTOR.ACTOR_ID), func(row *Row) int {
+		return row.IntField(ACTOR.ACTOR_ID)
+	})
+	if err != nil {
+		t.Fatal(testutil.Callers(), err)
+	}
+	if diff := testutil.Diff(actorIDs, []int{1}); diff != "" {
+		t.Error(testutil.Callers(), diff)
+	}
+
+	t.Run("logged db", func(t *testing.T) {
+		t.Parallel()
+		db := Limited(WithRetry(WithoutPreparedStatements(Log(NewDB(db, DialectSQLite))), RetryPolicy{}), 1)
+		err := Tx(db, TxOptions{}, func(tx DB) error {
+			if diff := testutil.Diff(dbDialect(tx), DialectSQLite); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+			if _, ok := tx.(SqLogger); !ok {
+				t.Error(testutil.Callers(), "tx did not keep the logger of the db")
+			}
+			if dbSemaphore(tx) == nil {
+				t.Error(testutil.Callers(), "Limited was not re-applied")
+			}
+			if dbRetryPolicy(tx) != nil {
+				t.Error(testutil.Callers(), "queries in a transaction must not be retried")
+			}
+			if !preparedStatementsDisabled(tx) {
+				t.Error(testutil.Callers(), "WithoutPreparedStatements was not re-applied")
+			}
+			return insertActor(tx, 3)
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		err = ReadOnlyTx(Log(db), func(tx DB) error { return nil })
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+	})
+
+	t.Run("unsupported options", func(t *testing.T) {
+		t.Parallel()
+		noop := func(tx DB) error { return nil }
+		if err := Tx(NewDB(db, DialectSQLite), TxOptions{Deferrable: true}, noop); err == nil {
+			t.Error(testutil.Callers(), "expected error for DEFERRABLE on sqlite")
+		}
+		if err := Tx(NewDB(db, DialectSQLServer), TxOptions{ReadOnly: true}, noop); err == nil {
+			t.Error(testutil.Callers(), "expected error for READ ONLY on sqlserver")
+		}
+	})
+}
+
+func Test_setTransactionStatement(t *testing.T) {
+	tests := []struct {
+		opts TxOptions
+		want string
+	}{
+		{TxOptions{}, ""},
+		{TxOptions{ReadOnly: true}, "SET TRANSACTION READ ONLY"},
+		{TxOptions{Isolation: sql.LevelRepeatableRead}, "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ"},
+		{TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true, Deferrable: true}, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, READ ONLY, DEFERRABLE"},
+	}
+	for _, tt := range tests {
+		got, err := setTransactionStatement(tt.opts)
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(got, tt.want); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	}
+	_, err := setTransactionStatement(TxOptions{Isolation: sql.LevelSnapshot})
+	if err == nil {
+		t.Error(testutil.Callers(), "expected error for LevelSnapshot")
+	}
+}
@@ -25,6 +25,8 @@ type UpdateQuery struct {
 	OrderByFields []Field
 	// LIMIT
 	LimitRows any
+	// OFFSET
+	OffsetRows any
 	// RETURNING
 	ReturningFields []Field
 }
@@ -33,6 +35,10 @@ var _ Query = (*UpdateQuery)(nil)
 
 // WriteSQL implements the SQLWriter interface.
 func (q UpdateQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) (err error) {
+	ctx = withWriteState(ctx)
+	state := getWriteState(ctx)
+	state.pushScope(q.JoinTables, q.UpdateTable, q.FromTable)
+	defer state.popScope()
 	if q.ColumnMapper != nil {
 		col := &Column{
 			dialect:  q.Dialect,
@@ -79,13 +85,29 @@ func (q UpdateQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 	if q.UpdateTable == nil {
 		return fmt.Errorf("no table provided to UPDATE")
 	}
-	err = q.UpdateTable.WriteSQL(ctx, dialect, buf, args, params)
-	if err != nil {
-		return fmt.Errorf("UPDATE: %w", err)
-	}
-	if dialect != DialectSQLServer {
-		if alias := getAlias(q.UpdateTable); alias != "" {
-			buf.WriteString(" AS " + QuoteIdentifier(dialect, alias))
+	if alias := getAlias(q.UpdateTable); alias != "" && dialect == DialectSQLServer {
+		// sqlserver does not allow aliasing the UPDATE table directly.
+		// Instead the alias must be declared in the FROM clause and the
+		// UPDATE clause refers to the alias.
+		if !hasTableAlias(alias, q.FromTable, q.JoinTables) {
+			return fmt.Errorf("sqlserver UPDATE table alias %q must be declared in the FROM clause", alias)
+		}
+		quotedAlias, err := quoteAlias(ctx, dialect, alias)
+		if err != nil {
+			return fmt.Errorf("UPDATE: %w", err)
+		}
+		buf.WriteString(quotedAlias)
+	} else {
+		err = q.UpdateTable.WriteSQL(ctx, dialect, buf, args, params)
+		if err != nil {
+			return fmt.Errorf("UPDATE: %w", err)
+		}
+		if alias != "" {
+			quotedAlias, err := quoteAlias(ctx, dialect, alias)
+			if err != nil {
+				return fmt.Errorf("UPDATE: %w", err)
+			}
+			buf.WriteString(" AS " + quotedAlias)
 		}
 	}
 	if len(q.Assignments) == 0 {
@@ -118,7 +140,11 @@ func (q UpdateQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 			return fmt.Errorf("FROM: %w", err)
 		}
 		if alias := getAlias(q.FromTable); alias != "" {
-			buf.WriteString(" AS " + QuoteIdentifier(dialect, alias) + quoteTableColumns(dialect, q.FromTable))
+			quotedAlias, err := quoteAlias(ctx, dialect, alias)
+			if err != nil {
+				return fmt.Errorf("FROM: %w", err)
+			}
+			buf.WriteString(" AS " + quotedAlias + quoteTableColumns(dialect, q.FromTable))
 		}
 	}
 	// JOIN
@@ -157,11 +183,26 @@ func (q UpdateQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 			}
 		}
 	}
+	// RETURNING (sqlite)
+	if len(q.ReturningFields) > 0 && dialect == DialectSQLite {
+		// sqlite's RETURNING clause comes before ORDER BY and LIMIT.
+		buf.WriteString(" RETURNING ")
+		err = writeFields(ctx, dialect, buf, args, params, q.ReturningFields, true)
+		if err != nil {
+			return fmt.Errorf("RETURNING: %w", err)
+		}
+	}
 	// ORDER BY
 	if len(q.OrderByFields) > 0 {
-		if dialect != DialectMySQL {
+		if dialect != DialectMySQL && dialect != DialectSQLite {
 			return fmt.Errorf("%s UPDATE does not support ORDER BY", dialect)
 		}
+		if dialect == DialectMySQL && len(q.JoinTables) > 0 {
+			return fmt.Errorf("mysql multi-table UPDATE does not support ORDER BY")
+		}
+		if dialect == DialectSQLite && q.LimitRows == nil {
+			return fmt.Errorf("sqlite UPDATE does not support ORDER BY without LIMIT")
+		}
 		buf.WriteString(" ORDER BY ")
 		err = writeFields(ctx, dialect, buf, args, params, q.OrderByFields, false)
 		if err != nil {
@@ -170,18 +211,35 @@ func (q UpdateQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 	}
 	// LIMIT
 	if q.LimitRows != nil {
-		if dialect != DialectMySQL {
+		if dialect != DialectMySQL && dialect != DialectSQLite {
 			return fmt.Errorf("%s UPDATE does not support LIMIT", dialect)
 		}
+		if dialect == DialectMySQL && len(q.JoinTables) > 0 {
+			return fmt.Errorf("mysql multi-table UPDATE does not support LIMIT")
+		}
 		buf.WriteString(" LIMIT ")
-		err = WriteValue(ctx, dialect, buf, args, params, q.LimitRows)
+		err = writeRowCount(ctx, dialect, buf, args, params, q.LimitRows)
 		if err != nil {
 			return fmt.Errorf("LIMIT: %w", err)
 		}
 	}
+	// OFFSET
+	if q.OffsetRows != nil {
+		if dialect != DialectSQLite {
+			return fmt.Errorf("%s UPDATE does not support OFFSET", dialect)
+		}
+		if q.LimitRows == nil {
+			return fmt.Errorf("sqlite UPDATE does not support OFFSET without LIMIT")
+		}
+		buf.WriteString(" OFFSET ")
+		err = writeRowCount(ctx, dialect, buf, args, params, q.OffsetRows)
+		if err != nil {
+			return fmt.Errorf("OFFSET: %w", err)
+		}
+	}
 	// RETURNING
-	if len(q.ReturningFields) > 0 && dialect != DialectSQLServer {
-		if dialect != DialectPostgres && dialect != DialectSQLite {
+	if len(q.ReturningFields) > 0 && dialect != DialectSQLServer && dialect != DialectSQLite {
+		if dialect != DialectPostgres {
 			return fmt.Errorf("%s UPDATE does not support RETURNING", dialect)
 		}
 		buf.WriteString(" RETURNING ")
@@ -193,6 +251,20 @@ func (q UpdateQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Bu
 	return nil
 }
 
+// hasTableAlias reports whether the FROM table or any of the JOIN tables is
+// aliased with the given alias.
+func hasTableAlias(alias string, fromTable Table, joinTables []JoinTable) bool {
+	if fromTable != nil && getAlias(fromTable) == alias {
+		return true
+	}
+	for _, joinTable := range joinTables {
+		if joinTable.Table != nil && getAlias(joinTable.Table) == alias {
+			return true
+		}
+	}
+	return false
+}
+
 // Update returns a new UpdateQuery.
 func Update(table Table) UpdateQuery {
 	return UpdateQuery{UpdateTable: table}
@@ -219,7 +291,7 @@ func (q UpdateQuery) Where(predicates ...Predicate) UpdateQuery {
 // SetFetchableFields implements the Query interface.
 func (q UpdateQuery) SetFetchableFields(fields []Field) (query Query, ok bool) {
 	switch q.Dialect {
-	case DialectPostgres, DialectSQLite:
+	case DialectPostgres, DialectSQLite, DialectSQLServer:
 		if len(q.ReturningFields) == 0 {
 			q.ReturningFields = fields
 			return q, true
@@ -233,7 +305,7 @@ func (q UpdateQuery) SetFetchableFields(fields []Field) (query Query, ok bool) {
 // GetFetchableFields returns the fetchable fields of the query.
 func (q UpdateQuery) GetFetchableFields() []Field {
 	switch q.Dialect {
-	case DialectPostgres, DialectSQLite:
+	case DialectPostgres, DialectSQLite, DialectSQLServer:
 		return q.ReturningFields
 	default:
 		return nil
@@ -323,6 +395,28 @@ func (q SQLiteUpdateQuery) Where(predicates ...Predicate) SQLiteUpdateQuery {
 	return q
 }
 
+// OrderBy sets the OrderByFields of the SQLiteUpdateQuery. Note that sqlite
+// only supports ORDER BY in an UPDATE if it was compiled with
+// SQLITE_ENABLE_UPDATE_DELETE_LIMIT, and only together with LIMIT.
+func (q SQLiteUpdateQuery) OrderBy(fields ...Field) SQLiteUpdateQuery {
+	q.OrderByFields = append(q.OrderByFields, fields...)
+	return q
+}
+
+// Limit sets the LimitRows field of the SQLiteUpdateQuery. Note that sqlite
+// only supports LIMIT in an UPDATE if it was compiled with
+// SQLITE_ENABLE_UPDATE_DELETE_LIMIT.
+func (q SQLiteUpdateQuery) Limit(limit any) SQLiteUpdateQuery {
+	q.LimitRows = limit
+	return q
+}
+
+// Offset sets the OffsetRows field of the SQLiteUpdateQuery.
+func (q SQLiteUpdateQuery) Offset(offset any) SQLiteUpdateQuery {
+	q.OffsetRows = offset
+	return q
+}
+
 // Returning sets the ReturningFields field of the SQLiteUpdateQuery.
 func (q SQLiteUpdateQuery) Returning(fields ...Field) SQLiteUpdateQuery {
 	q.ReturningFields = append(q.ReturningFields, fields...)
@@ -569,7 +663,9 @@ func (q SQLServerUpdateQuery) WriteSQL(ctx context.Context, dialect string, buf
 	return UpdateQuery(q).WriteSQL(ctx, dialect, buf, args, params)
 }
 
-// Update returns a new SQLServerUpdateQuery.
+// Update returns a new SQLServerUpdateQuery. If the table has an alias, the
+// same table must also be passed to From because SQL Server does not allow
+// aliasing the UPDATE table directly.
 func (b sqlserverQueryBuilder) Update(table Table) SQLServerUpdateQuery {
 	return SQLServerUpdateQuery{
 		Dialect:     DialectSQLServer,
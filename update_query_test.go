@@ -78,6 +78,28 @@ func TestSQLiteUpdateQuery(t *testing.T) {
 		tt.assert(t)
 	})
 
+	t.Run("UPDATE with ORDER BY, LIMIT, OFFSET", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = SQLite.
+			Update(a).
+			Set(a.FIRST_NAME.SetString("bob")).
+			Where(a.LAST_NAME.EqString("the builder")).
+			Returning(a.ACTOR_ID).
+			OrderBy(a.ACTOR_ID).
+			Limit(5).
+			Offset(10)
+		tt.wantQuery = "UPDATE actor AS a" +
+			" SET first_name = $1" +
+			" WHERE a.last_name = $2" +
+			" RETURNING a.actor_id" +
+			" ORDER BY a.actor_id" +
+			" LIMIT $3" +
+			" OFFSET $4"
+		tt.wantArgs = []any{"bob", "the builder", 5, 10}
+		tt.assert(t)
+	})
+
 	t.Run("UPDATE with JOIN", func(t *testing.T) {
 		t.Parallel()
 		var tt TestTable
@@ -282,7 +304,7 @@ func TestMySQLUpdateQuery(t *testing.T) {
 		tt.assert(t)
 	})
 
-	t.Run("UPDATE with JOIN, ORDER BY, LIMIT", func(t *testing.T) {
+	t.Run("UPDATE with JOIN", func(t *testing.T) {
 		t.Parallel()
 		var tt TestTable
 		tt.item = MySQL.
@@ -297,9 +319,7 @@ func TestMySQLUpdateQuery(t *testing.T) {
 				a.FIRST_NAME.SetString("bob"),
 				a.LAST_NAME.SetString("the builder"),
 			).
-			Where(a.ACTOR_ID.EqInt(1)).
-			OrderBy(a.ACTOR_ID).
-			Limit(5)
+			Where(a.ACTOR_ID.EqInt(1))
 		tt.wantQuery = "UPDATE actor AS a" +
 			" JOIN actor AS a ON a.actor_id = a.actor_id" +
 			" LEFT JOIN actor AS a ON a.actor_id = a.actor_id" +
@@ -307,11 +327,30 @@ func TestMySQLUpdateQuery(t *testing.T) {
 			" CROSS JOIN actor AS a" +
 			" , actor AS a" +
 			" JOIN actor AS a USING (first_name, last_name)" +
+			" SET a.first_name = ?, a.last_name = ?" +
+			" WHERE a.actor_id = ?"
+		tt.wantArgs = []any{"bob", "the builder", 1}
+		tt.assert(t)
+	})
+
+	t.Run("UPDATE with ORDER BY, LIMIT", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = MySQL.
+			Update(a).
+			Set(
+				a.FIRST_NAME.SetString("bob"),
+				a.LAST_NAME.SetString("the builder"),
+			).
+			Where(a.ACTOR_ID.EqInt(1)).
+			OrderBy(a.ACTOR_ID).
+			Limit(5)
+		tt.wantQuery = "UPDATE actor AS a" +
 			" SET a.first_name = ?, a.last_name = ?" +
 			" WHERE a.actor_id = ?" +
 			" ORDER BY a.actor_id" +
-			" LIMIT ?"
-		tt.wantArgs = []any{"bob", "the builder", 1, 5}
+			" LIMIT 5"
+		tt.wantArgs = []any{"bob", "the builder", 1}
 		tt.assert(t)
 	})
 }
@@ -338,10 +377,13 @@ func TestSQLServerUpdateQuery(t *testing.T) {
 			t.Error(testutil.Callers(), "expected 0 fields but got %v", fields)
 		}
 		_, ok := q1.SetFetchableFields([]Field{a.LAST_NAME})
-		if ok {
-			t.Fatal(testutil.Callers(), "field should not have been set")
+		if !ok {
+			t.Fatal(testutil.Callers(), "field should have been set")
+		}
+		q1.ReturningFields = []Field{a.FIRST_NAME}
+		if diff := testutil.Diff(q1.GetFetchableFields(), []Field{a.FIRST_NAME}); diff != "" {
+			t.Error(testutil.Callers(), diff)
 		}
-		q1.ReturningFields = q1.ReturningFields[:0]
 		_, ok = q1.SetFetchableFields([]Field{a.LAST_NAME})
 		if ok {
 			t.Fatal(testutil.Callers(), "field should not have been set")
@@ -416,6 +458,43 @@ func TestSQLServerUpdateQuery(t *testing.T) {
 	})
 }
 
+func TestSQLServerUpdateQueryAlias(t *testing.T) {
+	type ACTOR struct {
+		TableStruct
+		ACTOR_ID   NumberField
+		FIRST_NAME StringField
+	}
+	a, a2 := New[ACTOR]("a"), New[ACTOR]("a2")
+
+	t.Run("UPDATE alias declared in FROM", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = SQLServer.
+			Update(a).
+			Set(a.FIRST_NAME.Set(a2.FIRST_NAME)).
+			From(a).
+			Join(a2, a2.ACTOR_ID.Eq(Expr("{} + 1", a.ACTOR_ID))).
+			Where(a.ACTOR_ID.EqInt(1))
+		tt.wantQuery = "UPDATE a" +
+			" SET first_name = a2.first_name" +
+			" FROM actor AS a" +
+			" JOIN actor AS a2 ON a2.actor_id = a.actor_id + 1" +
+			" WHERE a.actor_id = @p1"
+		tt.wantArgs = []any{1}
+		tt.assert(t)
+	})
+
+	t.Run("UPDATE alias not declared in FROM", func(t *testing.T) {
+		t.Parallel()
+		var tt TestTable
+		tt.item = SQLServer.
+			Update(a).
+			Set(a.FIRST_NAME.SetString("bob")).
+			Where(a.ACTOR_ID.EqInt(1))
+		tt.assertNotOK(t)
+	})
+}
+
 func TestUpdateQuery(t *testing.T) {
 	t.Run("basic", func(t *testing.T) {
 		t.Parallel()
@@ -476,6 +555,36 @@ func TestUpdateQuery(t *testing.T) {
 			},
 			ColumnMapper: colmapper,
 		},
+	}, {
+		description: "mysql multi-table UPDATE does not support ORDER BY",
+		item: UpdateQuery{
+			Dialect:     DialectMySQL,
+			UpdateTable: Expr("tbl"),
+			JoinTables: []JoinTable{
+				Join(Expr("tbl2"), Expr("1 = 1")),
+			},
+			ColumnMapper:  colmapper,
+			OrderByFields: Fields{Expr("f1")},
+		},
+	}, {
+		description: "mysql multi-table UPDATE does not support LIMIT",
+		item: UpdateQuery{
+			Dialect:     DialectMySQL,
+			UpdateTable: Expr("tbl"),
+			JoinTables: []JoinTable{
+				Join(Expr("tbl2"), Expr("1 = 1")),
+			},
+			ColumnMapper: colmapper,
+			LimitRows:    5,
+		},
+	}, {
+		description: "sqlite does not support ORDER BY without LIMIT",
+		item: UpdateQuery{
+			Dialect:       DialectSQLite,
+			UpdateTable:   Expr("tbl"),
+			ColumnMapper:  colmapper,
+			OrderByFields: Fields{f1},
+		},
 	}, {
 		description: "dialect does not support ORDER BY",
 		item: UpdateQuery{
@@ -492,6 +601,23 @@ func TestUpdateQuery(t *testing.T) {
 			ColumnMapper: colmapper,
 			LimitRows:    5,
 		},
+	}, {
+		description: "sqlite does not support OFFSET without LIMIT",
+		item: UpdateQuery{
+			Dialect:      DialectSQLite,
+			UpdateTable:  Expr("tbl"),
+			ColumnMapper: colmapper,
+			OffsetRows:   10,
+		},
+	}, {
+		description: "dialect does not support OFFSET",
+		item: UpdateQuery{
+			Dialect:      DialectMySQL,
+			UpdateTable:  Expr("tbl"),
+			ColumnMapper: colmapper,
+			LimitRows:    5,
+			OffsetRows:   10,
+		},
 	}, {
 		description: "dialect does not support RETURNING",
 		item: UpdateQuery{
@@ -0,0 +1,220 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ValidationError is the error returned by Validate. It contains every
+// problem found in a query.
+type ValidationError struct {
+	Dialect  string
+	Problems []string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0]
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%d problems found:", len(e.Problems)))
+	for i, problem := range e.Problems {
+		b.WriteString(fmt.Sprintf("\n %02d. %s", i+1, problem))
+	}
+	return b.String()
+}
+
+// Validate builds the query into a throwaway buffer and checks it for
+// structural problems: empty IN lists, subqueries without an alias and
+// unbalanced parentheses. Instead of stopping at the first problem, it
+// reports every problem found in a single *ValidationError (a query that
+// fails to build because of an empty IN list is still checked for the other
+// problems). If dialect is
+// empty, the query's own dialect (or DefaultDialect) is used.
+func Validate(query Query, dialect string) error {
+	if query == nil {
+		return fmt.Errorf("query is nil")
+	}
+	if dialect == "" {
+		dialect = query.GetDialect()
+	}
+	if dialect == "" {
+		defaultDialect := DefaultDialect.Load()
+		if defaultDialect != nil {
+			dialect = *defaultDialect
+		}
+	}
+	validationErr := &ValidationError{Dialect: dialect}
+	validateSubqueries(validationErr, "", query)
+	buf := bufpool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufpool.Put(buf)
+	args := make([]any, 0)
+	params := make(map[string][]int)
+	// In and NotIn would otherwise silently rewrite an empty list as
+	// '1 = 0' or '1 = 1', hiding the problem from validateQueryString.
+	ctx := WithStrictEmptyIn(context.Background())
+	err := query.WriteSQL(ctx, dialect, buf, &args, params)
+	if err != nil {
+		validationErr.Problems = append(validationErr.Problems, "failed to build query: "+err.Error())
+		// The build stops at the first empty IN list, build the query again
+		// without WithStrictEmptyIn so that the rest of it is still checked.
+		buf.Reset()
+		args = args[:0]
+		params = make(map[string][]int)
+		err = query.WriteSQL(context.Background(), dialect, buf, &args, params)
+	}
+	if err == nil {
+		validateQueryString(validationErr, dialect, buf.String())
+	}
+	if len(validationErr.Problems) > 0 {
+		return validationErr
+	}
+	return nil
+}
+
+// validateSubqueries records every FROM, USING or JOIN subquery that has no
+// alias. It descends into subqueries and CTEs.
+func validateSubqueries(validationErr *ValidationError, prefix string, query Query) {
+	var ctes []CTE
+	var tables []Table
+	var clauses []string
+	switch q := query.(type) {
+	case SelectQuery:
+		ctes = q.CTEs
+		tables = append(tables, q.FromTable)
+		clauses = append(clauses, "FROM")
+		for i, joinTable := range q.JoinTables {
+			tables = append(tables, joinTable.Table)
+			clauses = append(clauses, fmt.Sprintf("join #%d", i+1))
+		}
+	case SQLiteSelectQuery:
+		validateSubqueries(validationErr, prefix, SelectQuery(q))
+		return
+	case PostgresSelectQuery:
+		validateSubqueries(validationErr, prefix, SelectQuery(q))
+		return
+	case MySQLSelectQuery:
+		validateSubqueries(validationErr, prefix, SelectQuery(q))
+		return
+	case SQLServerSelectQuery:
+		validateSubqueries(validationErr, prefix, SelectQuery(q))
+		return
+	case UpdateQuery:
+		ctes = q.CTEs
+		tables = append(tables, q.FromTable)
+		clauses = append(clauses, "FROM")
+		for i, joinTable := range q.JoinTables {
+			tables = append(tables, joinTable.Table)
+			clauses = append(clauses, fmt.Sprintf("join #%d", i+1))
+		}
+	case SQLiteUpdateQuery:
+		validateSubqueries(validationErr, prefix, UpdateQuery(q))
+		return
+	case PostgresUpdateQuery:
+		validateSubqueries(validationErr, prefix, UpdateQuery(q))
+		return
+	case MySQLUpdateQuery:
+		validateSubqueries(validationErr, prefix, UpdateQuery(q))
+		return
+	case SQLServerUpdateQuery:
+		validateSubqueries(validationErr, prefix, UpdateQuery(q))
+		return
+	case DeleteQuery:
+		ctes = q.CTEs
+		tables = append(tables, q.UsingTable)
+		clauses = append(clauses, "USING")
+		for i, joinTable := range q.JoinTables {
+			tables = append(tables, joinTable.Table)
+			clauses = append(clauses, fmt.Sprintf("join #%d", i+1))
+		}
+	case SQLiteDeleteQuery:
+		validateSubqueries(validationErr, prefix, DeleteQuery(q))
+		return
+	case PostgresDeleteQuery:
+		validateSubqueries(validationErr, prefix, DeleteQuery(q))
+		return
+	case MySQLDeleteQuery:
+		validateSubqueries(validationErr, prefix, DeleteQuery(q))
+		return
+	case SQLServerDeleteQuery:
+		validateSubqueries(validationErr, prefix, DeleteQuery(q))
+		return
+	case VariadicQuery:
+		for i, subquery := range q.Queries {
+			if subquery != nil {
+				validateSubqueries(validationErr, fmt.Sprintf("%squery #%d: ", prefix, i+1), subquery)
+			}
+		}
+		return
+	default:
+		return
+	}
+	for i, cte := range ctes {
+		if cte.query != nil {
+			validateSubqueries(validationErr, fmt.Sprintf("%sCTE #%d: ", prefix, i+1), cte.query)
+		}
+	}
+	for i, table := range tables {
+		subquery, ok := table.(Query)
+		if !ok {
+			continue
+		}
+		if getAlias(table) == "" {
+			validationErr.Problems = append(validationErr.Problems, prefix+clauses[i]+": subquery has no alias")
+		}
+		validateSubqueries(validationErr, prefix+clauses[i]+": ", subquery)
+	}
+}
+
+// validateQueryString records empty IN lists and unbalanced parentheses in
+// the query string. Parentheses inside strings, quoted identifiers, comments
+// or dollar-quoted strings are ignored.
+func validateQueryString(validationErr *ValidationError, dialect string, query string) {
+	var openingIndices []int
+	for i := 0; i < len(query); i++ {
+		if end, closed, ok := quotedEnd(dialect, query, i); ok {
+			if !closed {
+				validationErr.Problems = append(validationErr.Problems, "unclosed string or identifier")
+			}
+			i = end - 1
+			continue
+		}
+		if end := skipLiteral(dialect, query, i); end > i {
+			i = end - 1
+			continue
+		}
+		switch query[i] {
+		case '(':
+			openingIndices = append(openingIndices, i)
+		case ')':
+			if len(openingIndices) == 0 {
+				validationErr.Problems = append(validationErr.Problems, fmt.Sprintf("unbalanced parentheses: unexpected ')' at position %d", i))
+				continue
+			}
+			openingIndex := openingIndices[len(openingIndices)-1]
+			openingIndices = openingIndices[:len(openingIndices)-1]
+			if strings.TrimSpace(query[openingIndex+1:i]) != "" {
+				continue
+			}
+			before := strings.TrimRightFunc(query[:openingIndex], unicode.IsSpace)
+			if len(before) < 2 || !strings.EqualFold(before[len(before)-2:], "IN") {
+				continue
+			}
+			if len(before) > 2 {
+				c := rune(before[len(before)-3])
+				if c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) {
+					continue
+				}
+			}
+			validationErr.Problems = append(validationErr.Problems, fmt.Sprintf("empty IN list at position %d", openingIndex))
+		}
+	}
+	for _, openingIndex := range openingIndices {
+		validationErr.Problems = append(validationErr.Problems, fmt.Sprintf("unbalanced parentheses: unclosed '(' at position %d", openingIndex))
+	}
+}
@@ -0,0 +1,112 @@
+package sq
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+func TestValidate(t *testing.T) {
+	type ACTOR struct {
+		TableStruct
+		ACTOR_ID   NumberField
+		FIRST_NAME StringField
+	}
+	a := New[ACTOR]("a")
+
+	type TestTable struct {
+		description  string
+		dialect      string
+		query        Query
+		wantProblems []string
+	}
+
+	tests := []TestTable{{
+		description: "valid",
+		dialect:     DialectPostgres,
+		query: Postgres.
+			SelectOne().
+			From(a).
+			Where(a.ACTOR_ID.In([]int{1, 2, 3}), Expr("{} = '(('", a.FIRST_NAME)),
+	}, {
+		description: "empty IN list",
+		dialect:     DialectPostgres,
+		query:       Postgres.SelectOne().From(a).Where(a.ACTOR_ID.In([]int{})),
+		wantProblems: []string{
+			"failed to build query: WHERE: IN: list is empty",
+		},
+	}, {
+		description: "explicit empty IN list",
+		dialect:     DialectPostgres,
+		query:       Postgres.SelectOne().From(a).Where(Expr("{} IN ()", a.ACTOR_ID)),
+		wantProblems: []string{
+			"empty IN list at position 45",
+		},
+	}, {
+		description: "checks continue after an empty IN list",
+		dialect:     DialectPostgres,
+		query:       Postgres.SelectOne().From(a).Where(a.ACTOR_ID.In([]int{}), Expr("{} IN ()", a.ACTOR_ID)),
+		wantProblems: []string{
+			"failed to build query: WHERE: predicate #1: IN: list is empty",
+			"empty IN list at position 55",
+		},
+	}, {
+		description: "parentheses inside comments and dollar-quoted strings",
+		dialect:     DialectPostgres,
+		query:       Postgres.SelectOne().From(a).Where(Expr("/* ( */ {} = $tag$)$tag$ -- (\n", a.FIRST_NAME)),
+	}, {
+		description: "function call with no arguments is not an empty IN list",
+		dialect:     DialectPostgres,
+		query:       Postgres.SelectOne().From(a).Where(Expr("MIN() = 1")),
+	}, {
+		description: "unbalanced parentheses",
+		dialect:     DialectSQLite,
+		query:       SQLite.SelectOne().From(a).Where(Expr("a.actor_id = 1)"), Expr("(a.first_name = ')'")),
+		wantProblems: []string{
+			"unbalanced parentheses: unexpected ')' at position 45",
+			"unbalanced parentheses: unclosed '(' at position 51",
+		},
+	}, {
+		description: "subquery without alias",
+		dialect:     DialectSQLite,
+		query: SQLite.
+			SelectOne().
+			From(SQLite.SelectOne().From(a).Where(a.ACTOR_ID.In([]int{}))).
+			Join(SQLite.SelectOne(), Expr("TRUE")),
+		wantProblems: []string{
+			"FROM: subquery has no alias",
+			"join #1: subquery has no alias",
+			"failed to build query: FROM: WHERE: IN: list is empty",
+		},
+	}, {
+		description: "build error",
+		dialect:     DialectPostgres,
+		query:       Postgres.SelectOne().From(Postgres.SelectOne()),
+		wantProblems: []string{
+			"FROM: subquery has no alias",
+			"failed to build query: postgres FROM subquery must have alias",
+		},
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			err := Validate(tt.query, tt.dialect)
+			if len(tt.wantProblems) == 0 {
+				if err != nil {
+					t.Fatal(testutil.Callers(), err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatal(testutil.Callers(), "expected *ValidationError, got", err)
+			}
+			if diff := testutil.Diff(validationErr.Problems, tt.wantProblems); diff != "" {
+				t.Error(testutil.Callers(), diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package sq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// CreateViewQuery represents an SQL CREATE OR REPLACE VIEW statement. The view
+// is a view struct (a struct embedding ViewStruct created with New) and its
+// columns are taken from the fields of the view struct, so that the view
+// definition and the queries that select from the view use the same column
+// names.
+//
+//	postgres:  CREATE OR REPLACE VIEW name (col1, col2) AS <query>
+//	mysql:     CREATE OR REPLACE VIEW name (col1, col2) AS <query>
+//	sqlserver: CREATE OR ALTER VIEW name (col1, col2) AS <query>
+//	sqlite:    DROP VIEW IF EXISTS name; CREATE VIEW name (col1, col2) AS <query>
+type CreateViewQuery struct {
+	Dialect string
+	View    Table
+	Query   Query
+}
+
+var _ Query = (*CreateViewQuery)(nil)
+
+// CreateView returns a new CreateViewQuery.
+//
+//	type ACTOR_NAMES struct {
+//	    sq.ViewStruct `sq:"actor_names"`
+//	    ACTOR_ID      sq.NumberField
+//	    FULL_NAME     sq.StringField
+//	}
+//
+//	v := sq.New[ACTOR_NAMES]("")
+//	a := sq.New[ACTOR]("")
+//	_, err := sq.Exec(db, sq.CreateView(v, sq.Postgres.
+//	    Select(a.ACTOR_ID, sq.Expr("{} || ' ' || {}", a.FIRST_NAME, a.LAST_NAME)).
+//	    From(a),
+//	))
+func CreateView(view Table, query Query) CreateViewQuery {
+	return CreateViewQuery{View: view, Query: query}
+}
+
+// WriteSQL implements the SQLWriter interface.
+func (q CreateViewQuery) WriteSQL(ctx context.Context, dialect string, buf *bytes.Buffer, args *[]any, params map[string][]int) error {
+	if q.View == nil {
+		return fmt.Errorf("CreateView: view is nil")
+	}
+	if q.Query == nil {
+		return fmt.Errorf("CreateView: query is nil")
+	}
+	viewStruct, columns, err := tableColumns(q.View)
+	if err != nil {
+		return fmt.Errorf("CreateView: %w", err)
+	}
+	if viewStruct.name == "" {
+		return fmt.Errorf("CreateView: view has no name")
+	}
+	if query, ok := q.Query.(interface{ GetFetchableFields() []Field }); ok && len(columns) > 0 {
+		if fields := query.GetFetchableFields(); len(fields) > 0 && len(fields) != len(columns) {
+			return fmt.Errorf("CreateView: %s has %d columns but the query selects %d fields", viewStruct.name, len(columns), len(fields))
+		}
+	}
+	switch dialect {
+	case DialectSQLite:
+		buf.WriteString("DROP VIEW IF EXISTS ")
+		_ = viewStruct.WriteSQL(ctx, dialect, buf, args, params)
+		buf.WriteString("; CREATE VIEW ")
+	case DialectSQLServer:
+		buf.WriteString("CREATE OR ALTER VIEW ")
+	default:
+		buf.WriteString("CREATE OR REPLACE VIEW ")
+	}
+	_ = viewStruct.WriteSQL(ctx, dialect, buf, args, params)
+	if len(columns) > 0 {
+		buf.WriteString(" (")
+		for i, column := range columns {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(QuoteIdentifier(dialect, column))
+		}
+		buf.WriteString(")")
+	}
+	buf.WriteString(" AS ")
+	// Views are stored in the database so they cannot contain bind
+	// parameters, any values must be written literally.
+	var viewArgs []any
+	err = q.Query.WriteSQL(ctx, dialect, buf, &viewArgs, nil)
+	if err != nil {
+		return fmt.Errorf("CreateView: %w", err)
+	}
+	if len(viewArgs) > 0 {
+		return fmt.Errorf("CreateView: view query cannot contain args (found %d), use sq.Literal for literal values", len(viewArgs))
+	}
+	return nil
+}
+
+// SetFetchableFields implements the Query interface. It always returns false
+// as the second result.
+func (q CreateViewQuery) SetFetchableFields([]Field) (query Query, ok bool) {
+	return q, false
+}
+
+// GetDialect implements the Query interface. If the CreateViewQuery has no
+// dialect, the dialect of the view query is used.
+func (q CreateViewQuery) GetDialect() string {
+	if q.Dialect == "" && q.Query != nil {
+		return q.Query.GetDialect()
+	}
+	return q.Dialect
+}
+
+// SetDialect sets the dialect of the query.
+func (q CreateViewQuery) SetDialect(dialect string) CreateViewQuery {
+	q.Dialect = dialect
+	return q
+}
+
+// tableColumns returns the TableStruct and column names of a table (or view)
+// struct. If the table is a plain TableStruct, there are no column names.
+func tableColumns(table Table) (tableStruct TableStruct, columns []string, err error) {
+	if tableStruct, ok := table.(TableStruct); ok {
+		return tableStruct, nil, nil
+	}
+	value := reflect.Indirect(reflect.ValueOf(table))
+	if value.Kind() != reflect.Struct || value.NumField() == 0 {
+		return tableStruct, nil, fmt.Errorf("%T is not a table struct", table)
+	}
+	var ok bool
+	if value.Field(0).CanInterface() {
+		tableStruct, ok = value.Field(0).Interface().(TableStruct)
+	}
+	if !ok {
+		return tableStruct, nil, fmt.Errorf("%T is not a table struct: the first field must be a TableStruct", table)
+	}
+	for i := 1; i < value.NumField(); i++ {
+		if !value.Type().Field(i).IsExported() {
+			continue
+		}
+		switch field := value.Field(i).Interface().(type) {
+		case AnyField:
+			columns = append(columns, field.name)
+		case ArrayField:
+			columns = append(columns, field.name)
+		case BinaryField:
+			columns = append(columns, field.name)
+		case BitField:
+			columns = append(columns, field.name)
+		case BooleanField:
+			columns = append(columns, field.name)
+		case EnumField:
+			columns = append(columns, field.name)
+		case JSONField:
+			columns = append(columns, field.name)
+		case NumberField:
+			columns = append(columns, field.name)
+		case StringField:
+			columns = append(columns, field.name)
+		case TimeField:
+			columns = append(columns, field.name)
+		case UUIDField:
+			columns = append(columns, field.name)
+		case XMLField:
+			columns = append(columns, field.name)
+		case genericColumn:
+			columns = append(columns, field.columnName())
+		}
+	}
+	for i, column := range columns {
+		if column == "" {
+			return tableStruct, nil, fmt.Errorf("%T: column #%d has no name (was the struct created with sq.New?)", table, i+1)
+		}
+	}
+	return tableStruct, columns, nil
+}
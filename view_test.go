@@ -0,0 +1,103 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/bokwoon95/sq/internal/testutil"
+)
+
+type ACTOR_NAMES struct {
+	ViewStruct `sq:"actor_names"`
+	ACTOR_ID   NumberField
+	FULL_NAME  StringField
+}
+
+func TestCreateView(t *testing.T) {
+	v := New[ACTOR_NAMES]("")
+	query := Select(ACTOR.ACTOR_ID, Expr("{} || ' ' || {}", ACTOR.FIRST_NAME, ACTOR.LAST_NAME)).From(ACTOR)
+
+	tests := []TestTable{{
+		description: "sqlite",
+		dialect:     DialectSQLite,
+		item:        CreateView(v, query),
+		wantQuery: "DROP VIEW IF EXISTS actor_names; CREATE VIEW actor_names (actor_id, full_name) AS" +
+			" SELECT actor.actor_id, actor.first_name || ' ' || actor.last_name FROM actor",
+	}, {
+		description: "postgres",
+		dialect:     DialectPostgres,
+		item:        CreateView(v, query),
+		wantQuery: "CREATE OR REPLACE VIEW actor_names (actor_id, full_name) AS" +
+			" SELECT actor.actor_id, actor.first_name || ' ' || actor.last_name FROM actor",
+	}, {
+		description: "sqlserver",
+		dialect:     DialectSQLServer,
+		item:        CreateView(v, query),
+		wantQuery: "CREATE OR ALTER VIEW actor_names (actor_id, full_name) AS" +
+			" SELECT actor.actor_id, actor.first_name || ' ' || actor.last_name FROM actor",
+	}, {
+		description: "ViewStruct",
+		dialect:     DialectMySQL,
+		item:        CreateView(NewTableStruct("app", "actor_ids", ""), Select(ACTOR.ACTOR_ID).From(ACTOR)),
+		wantQuery:   "CREATE OR REPLACE VIEW app.actor_ids AS SELECT actor.actor_id FROM actor",
+	}, {
+		description: "literal values",
+		dialect:     DialectPostgres,
+		item:        CreateView(v, Select(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME).From(ACTOR).Where(ACTOR.LAST_NAME.Eq(Literal("CHASE")))),
+		wantQuery: "CREATE OR REPLACE VIEW actor_names (actor_id, full_name) AS" +
+			" SELECT actor.actor_id, actor.first_name FROM actor WHERE actor.last_name = 'CHASE'",
+	}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assert(t)
+		})
+	}
+
+	notOKTests := []TestTable{{
+		description: "column count mismatch",
+		item:        CreateView(v, Select(ACTOR.ACTOR_ID).From(ACTOR)),
+	}, {
+		description: "args",
+		item:        CreateView(v, Select(ACTOR.ACTOR_ID, ACTOR.FIRST_NAME).From(ACTOR).Where(ACTOR.LAST_NAME.EqString("CHASE"))),
+	}, {
+		description: "not a view struct",
+		item:        CreateView(Expr("actor_names"), query),
+	}}
+
+	for _, tt := range notOKTests {
+		tt := tt
+		t.Run(tt.description, func(t *testing.T) {
+			t.Parallel()
+			tt.assertNotOK(t)
+		})
+	}
+
+	t.Run("sqlite", func(t *testing.T) {
+		t.Parallel()
+		db := newDB(t)
+		_, err := db.Exec("INSERT INTO actor (actor_id, first_name, last_name) VALUES (1, 'PENELOPE', 'GUINESS'), (2, 'NICK', 'WAHLBERG')")
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		for i := 0; i < 2; i++ {
+			_, err = Exec(db, CreateView(v, SQLite.
+				Select(ACTOR.ACTOR_ID, Expr("{} || ' ' || {}", ACTOR.FIRST_NAME, ACTOR.LAST_NAME)).
+				From(ACTOR),
+			))
+			if err != nil {
+				t.Fatal(testutil.Callers(), err)
+			}
+		}
+		gotNames, err := FetchAll(db, SQLite.From(v).OrderBy(v.ACTOR_ID), func(row *Row) string {
+			return row.StringField(v.FULL_NAME)
+		})
+		if err != nil {
+			t.Fatal(testutil.Callers(), err)
+		}
+		if diff := testutil.Diff(gotNames, []string{"PENELOPE GUINESS", "NICK WAHLBERG"}); diff != "" {
+			t.Error(testutil.Callers(), diff)
+		}
+	})
+}
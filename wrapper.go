@@ -0,0 +1,61 @@
+package sq
+
+// optionDB wraps a DB with an option (e.g. the RetryPolicy of WithRetry) that
+// is looked up with dbOption. The type of the option identifies the wrapper,
+// so each wrapper must use its own type.
+type optionDB[T any] struct {
+	DB
+	option T
+}
+
+func (db *optionDB[T]) SqDialect() string {
+	if db, ok := db.DB.(interface{ SqDialect() string }); ok {
+		return db.SqDialect()
+	}
+	return ""
+}
+
+func (db *optionDB[T]) unwrap() DB { return db.DB }
+
+type optionLoggerDB[T any] struct {
+	DB
+	SqLogger
+	option T
+}
+
+func (db *optionLoggerDB[T]) SqDialect() string {
+	if db, ok := db.DB.(interface{ SqDialect() string }); ok {
+		return db.SqDialect()
+	}
+	return ""
+}
+
+func (db *optionLoggerDB[T]) unwrap() DB { return db.DB }
+
+// withOption wraps a DB with an option. If the DB is also a SqLogger, the
+// returned DB is too.
+func withOption[T any](db DB, option T) DB {
+	if logger, ok := db.(SqLogger); ok {
+		return &optionLoggerDB[T]{DB: db, SqLogger: logger, option: option}
+	}
+	return &optionDB[T]{DB: db, option: option}
+}
+
+// dbOption returns the option of type T of the outermost wrapper created by
+// withOption, or ok=false if the DB is not wrapped with such an option.
+func dbOption[T any](db DB) (option T, ok bool) {
+	for db != nil {
+		switch db := db.(type) {
+		case *optionDB[T]:
+			return db.option, true
+		case *optionLoggerDB[T]:
+			return db.option, true
+		}
+		wrapper, ok := db.(interface{ unwrap() DB })
+		if !ok {
+			break
+		}
+		db = wrapper.unwrap()
+	}
+	return option, false
+}